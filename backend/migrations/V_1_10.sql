@@ -0,0 +1,11 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- A NULL language means the subscriber has not picked one yet.
+ALTER TABLE [dbo].[TB_TRN_Subscribers] ADD
+	[Language] [nvarchar](10) NULL,
+	[Frequency] [nvarchar](20) NOT NULL CONSTRAINT [DF_TB_TRN_Subscribers_Frequency]  DEFAULT (N'immediate')
+GO
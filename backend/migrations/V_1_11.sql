@@ -0,0 +1,63 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_MAS_CustomFields](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Key] [nvarchar](64) NOT NULL,
+	[Label] [nvarchar](255) NOT NULL,
+	[Type] [nvarchar](10) NOT NULL,
+	[Options] [nvarchar](1000) NOT NULL,
+	[IsRequired] [bit] NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_MAS_CustomFields] PRIMARY KEY CLUSTERED
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_MAS_CustomFields] ADD  CONSTRAINT [DF_TB_MAS_CustomFields_Options]  DEFAULT (N'') FOR [Options]
+GO
+ALTER TABLE [dbo].[TB_MAS_CustomFields] ADD  CONSTRAINT [DF_TB_MAS_CustomFields_IsRequired]  DEFAULT ((0)) FOR [IsRequired]
+GO
+ALTER TABLE [dbo].[TB_MAS_CustomFields] ADD  CONSTRAINT [DF_TB_MAS_CustomFields_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_MAS_CustomFields] ADD  CONSTRAINT [DF_TB_MAS_CustomFields_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+-- A key stays taken while its field is live; a deleted field frees it.
+CREATE UNIQUE NONCLUSTERED INDEX [UX_TB_MAS_CustomFields_Key] ON [dbo].[TB_MAS_CustomFields]
+(
+	[Key] ASC
+) WHERE [Delflag] = 0 ON [PRIMARY]
+GO
+-- Each value is kept in the column for its field type, so segments can
+-- compare numbers and dates as numbers and dates.
+CREATE TABLE [dbo].[TB_TRN_SubscriberFieldValues](
+	[SubscriberId] [bigint] NOT NULL,
+	[FieldId] [bigint] NOT NULL,
+	[StringValue] [nvarchar](255) NULL,
+	[NumberValue] [float] NULL,
+	[DateValue] [date] NULL,
+	[BoolValue] [bit] NULL,
+	[UpdatedDate] [datetime] NOT NULL,
+ CONSTRAINT [PK_TB_TRN_SubscriberFieldValues] PRIMARY KEY CLUSTERED
+(
+	[SubscriberId] ASC,
+	[FieldId] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_SubscriberFieldValues] ADD  CONSTRAINT [DF_TB_TRN_SubscriberFieldValues_UpdatedDate]  DEFAULT (getdate()) FOR [UpdatedDate]
+GO
+-- Hard-deleting a subscriber also removes their values.
+ALTER TABLE [dbo].[TB_TRN_SubscriberFieldValues] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_SubscriberFieldValues_Subscribers] FOREIGN KEY([SubscriberId])
+REFERENCES [dbo].[TB_TRN_Subscribers] ([Id])
+ON DELETE CASCADE
+GO
+ALTER TABLE [dbo].[TB_TRN_SubscriberFieldValues] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_SubscriberFieldValues_CustomFields] FOREIGN KEY([FieldId])
+REFERENCES [dbo].[TB_MAS_CustomFields] ([Id])
+GO
@@ -0,0 +1,41 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- Rules holds the rule tree as JSON. It is compiled to SQL each time the
+-- segment is used, so it always matches the current subscribers.
+CREATE TABLE [dbo].[TB_MAS_Segments](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Name] [nvarchar](255) NOT NULL,
+	[Description] [nvarchar](1000) NOT NULL,
+	[Rules] [nvarchar](max) NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_MAS_Segments] PRIMARY KEY CLUSTERED
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_MAS_Segments] ADD  CONSTRAINT [DF_TB_MAS_Segments_Description]  DEFAULT (N'') FOR [Description]
+GO
+ALTER TABLE [dbo].[TB_MAS_Segments] ADD  CONSTRAINT [DF_TB_MAS_Segments_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_MAS_Segments] ADD  CONSTRAINT [DF_TB_MAS_Segments_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+-- Segment rules look up values of one field and deliveries of one
+-- subscriber at a time.
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_SubscriberFieldValues_Field] ON [dbo].[TB_TRN_SubscriberFieldValues]
+(
+	[FieldId] ASC
+) INCLUDE ([StringValue], [NumberValue], [DateValue], [BoolValue]) ON [PRIMARY]
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Deliveries_Subscriber] ON [dbo].[TB_TRN_Deliveries]
+(
+	[SubscriberId] ASC,
+	[Status] ASC
+) INCLUDE ([CampaignId], [SentDate]) ON [PRIMARY]
+GO
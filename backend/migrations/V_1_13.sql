@@ -0,0 +1,45 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- Sends cmstool runs at SendAt. A running job is claimed by one instance,
+-- named in ClaimedBy. SendAt keeps its offset so it means the same instant
+-- whatever zone the database server runs in.
+CREATE TABLE [dbo].[TB_TRN_ScheduledJobs](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Subject] [nvarchar](255) NOT NULL,
+	[Body] [nvarchar](max) NOT NULL,
+	[ListId] [bigint] NULL,
+	[SegmentId] [bigint] NULL,
+	[SendAt] [datetimeoffset] NOT NULL,
+	[Status] [nvarchar](20) NOT NULL,
+	[ClaimedBy] [nvarchar](255) NULL,
+	[ClaimedDate] [datetime] NULL,
+	[FinishedDate] [datetime] NULL,
+	[ErrorCode] [nvarchar](64) NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_TRN_ScheduledJobs] PRIMARY KEY CLUSTERED
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_ScheduledJobs] ADD  CONSTRAINT [DF_TB_TRN_ScheduledJobs_Status]  DEFAULT (N'pending') FOR [Status]
+GO
+ALTER TABLE [dbo].[TB_TRN_ScheduledJobs] ADD  CONSTRAINT [DF_TB_TRN_ScheduledJobs_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_TRN_ScheduledJobs] ADD  CONSTRAINT [DF_TB_TRN_ScheduledJobs_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+ALTER TABLE [dbo].[TB_TRN_ScheduledJobs] ADD  CONSTRAINT [CK_TB_TRN_ScheduledJobs_Status]  CHECK ([Status] IN (N'pending', N'running', N'done', N'failed'))
+GO
+ALTER TABLE [dbo].[TB_TRN_ScheduledJobs] ADD  CONSTRAINT [CK_TB_TRN_ScheduledJobs_Target]  CHECK ([ListId] IS NULL OR [SegmentId] IS NULL)
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_ScheduledJobs_Due] ON [dbo].[TB_TRN_ScheduledJobs]
+(
+	[Status] ASC,
+	[SendAt] ASC
+) WHERE [Delflag] = 0 ON [PRIMARY]
+GO
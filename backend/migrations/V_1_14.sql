@@ -0,0 +1,10 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- An IANA zone name like Asia/Bangkok. NULL means the zone is not known.
+ALTER TABLE [dbo].[TB_TRN_Subscribers] ADD
+	[TimeZone] [nvarchar](64) NULL
+GO
@@ -0,0 +1,37 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- Files sent with a campaign. The content lives in the blob store under
+-- StorageKey; a row with a ContentId is an inline image the HTML body refers
+-- to as cid:<ContentId>, the others are plain attachments.
+CREATE TABLE [dbo].[TB_TRN_CampaignAttachments](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[CampaignId] [bigint] NOT NULL,
+	[FileName] [nvarchar](255) NOT NULL,
+	[ContentType] [nvarchar](255) NOT NULL,
+	[Size] [bigint] NOT NULL,
+	[StorageKey] [nvarchar](255) NOT NULL,
+	[ContentId] [nvarchar](255) NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_TRN_CampaignAttachments] PRIMARY KEY CLUSTERED
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_CampaignAttachments] ADD  CONSTRAINT [DF_TB_TRN_CampaignAttachments_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_TRN_CampaignAttachments] ADD  CONSTRAINT [DF_TB_TRN_CampaignAttachments_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+ALTER TABLE [dbo].[TB_TRN_CampaignAttachments] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_CampaignAttachments_Campaigns] FOREIGN KEY([CampaignId])
+REFERENCES [dbo].[TB_TRN_Campaigns] ([Id])
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_CampaignAttachments_CampaignId] ON [dbo].[TB_TRN_CampaignAttachments]
+(
+	[CampaignId] ASC
+) WHERE [Delflag] = 0 ON [PRIMARY]
+GO
@@ -0,0 +1,16 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- A campaign goes to a list or a segment, never both. With neither it keeps
+-- going to every subscriber.
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD
+	[SegmentId] [bigint] NULL
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Campaigns_Segments] FOREIGN KEY([SegmentId])
+REFERENCES [dbo].[TB_MAS_Segments] ([Id])
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [CK_TB_TRN_Campaigns_Target]  CHECK ([ListId] IS NULL OR [SegmentId] IS NULL)
+GO
@@ -0,0 +1,21 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+-- Campaigns are sent by cmstool only. The backend queues them, cmstool claims
+-- them in ClaimedBy, and a campaign some emails failed for ends failed.
+ALTER TABLE [dbo].[TB_TRN_Campaigns] DROP CONSTRAINT [CK_TB_TRN_Campaigns_Status]
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [CK_TB_TRN_Campaigns_Status]  CHECK ([Status] IN (N'draft', N'scheduled', N'queued', N'sending', N'sent', N'failed'))
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD
+	[ClaimedBy] [nvarchar](255) NULL,
+	[ClaimedDate] [datetime] NULL
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Campaigns_Status] ON [dbo].[TB_TRN_Campaigns]
+(
+	[Status] ASC
+) ON [PRIMARY]
+GO
@@ -0,0 +1,19 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+ALTER TABLE [dbo].[TB_TRN_Subscribers] ADD
+	[IsPending] [bit] NOT NULL CONSTRAINT [DF_TB_TRN_Subscribers_IsPending] DEFAULT ((0)),
+	[ConfirmToken] [nvarchar](512) NULL,
+	[ConfirmExpiredDate] [datetime] NULL,
+	[ConfirmedDate] [datetime] NULL
+GO
+UPDATE [dbo].[TB_TRN_Subscribers] SET [ConfirmedDate] = [SubscribedDate]
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Subscribers_ConfirmToken] ON [dbo].[TB_TRN_Subscribers]
+(
+	[ConfirmToken] ASC
+) ON [PRIMARY]
+GO
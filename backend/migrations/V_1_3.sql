@@ -0,0 +1,31 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_TRN_Campaigns](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Subject] [nvarchar](255) NOT NULL,
+	[HTMLBody] [nvarchar](max) NOT NULL,
+	[TextBody] [nvarchar](max) NOT NULL,
+	[Sender] [nvarchar](255) NOT NULL,
+	[Status] [nvarchar](20) NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+	[SentDate] [datetime] NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_TRN_Campaigns] PRIMARY KEY CLUSTERED 
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY] TEXTIMAGE_ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [DF_TB_TRN_Campaigns_Status]  DEFAULT (N'draft') FOR [Status]
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [DF_TB_TRN_Campaigns_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [DF_TB_TRN_Campaigns_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD  CONSTRAINT [CK_TB_TRN_Campaigns_Status]  CHECK ([Status] IN (N'draft', N'scheduled', N'sending', N'sent'))
+GO
@@ -0,0 +1,41 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_TRN_Deliveries](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[CampaignId] [bigint] NOT NULL,
+	[SubscriberId] [bigint] NOT NULL,
+	[Email] [nvarchar](255) NOT NULL,
+	[Status] [nvarchar](20) NOT NULL,
+	[Attempts] [int] NOT NULL,
+	[LastError] [nvarchar](1000) NULL,
+	[SentDate] [datetime] NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+ CONSTRAINT [PK_TB_TRN_Deliveries] PRIMARY KEY CLUSTERED 
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] ADD  CONSTRAINT [DF_TB_TRN_Deliveries_Attempts]  DEFAULT ((0)) FOR [Attempts]
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] ADD  CONSTRAINT [DF_TB_TRN_Deliveries_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] ADD  CONSTRAINT [CK_TB_TRN_Deliveries_Status]  CHECK ([Status] IN (N'sent', N'failed'))
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Deliveries_Campaigns] FOREIGN KEY([CampaignId])
+REFERENCES [dbo].[TB_TRN_Campaigns] ([Id])
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Deliveries_Subscribers] FOREIGN KEY([SubscriberId])
+REFERENCES [dbo].[TB_TRN_Subscribers] ([Id])
+GO
+CREATE UNIQUE NONCLUSTERED INDEX [UX_TB_TRN_Deliveries_Campaign_Subscriber] ON [dbo].[TB_TRN_Deliveries]
+(
+	[CampaignId] ASC,
+	[SubscriberId] ASC
+) INCLUDE ([Status]) ON [PRIMARY]
+GO
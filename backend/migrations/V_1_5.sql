@@ -0,0 +1,19 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] ADD
+	[ReplyCode] [int] NULL
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] DROP CONSTRAINT [CK_TB_TRN_Deliveries_Status]
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] ADD  CONSTRAINT [CK_TB_TRN_Deliveries_Status]  CHECK ([Status] IN (N'sent', N'failed', N'dead'))
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Deliveries_Status] ON [dbo].[TB_TRN_Deliveries]
+(
+	[Status] ASC,
+	[CampaignId] ASC
+) ON [PRIMARY]
+GO
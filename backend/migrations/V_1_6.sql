@@ -0,0 +1,18 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Subscribers_SubscribedDate] ON [dbo].[TB_TRN_Subscribers]
+(
+	[Delflag] ASC,
+	[SubscribedDate] ASC
+) ON [PRIMARY]
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_Subscribers_Name] ON [dbo].[TB_TRN_Subscribers]
+(
+	[Delflag] ASC,
+	[Name] ASC
+) ON [PRIMARY]
+GO
@@ -0,0 +1,37 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_MAS_Users](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Username] [nvarchar](255) NOT NULL,
+	[Role] [nvarchar](20) NOT NULL,
+	[IsActive] [bit] NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+ CONSTRAINT [PK_TB_MAS_Users] PRIMARY KEY CLUSTERED 
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_MAS_Users] ADD  CONSTRAINT [DF_TB_MAS_Users_IsActive]  DEFAULT ((1)) FOR [IsActive]
+GO
+ALTER TABLE [dbo].[TB_MAS_Users] ADD  CONSTRAINT [DF_TB_MAS_Users_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_MAS_Users] ADD  CONSTRAINT [CK_TB_MAS_Users_Role]  CHECK ([Role] IN (N'support', N'editor', N'admin'))
+GO
+CREATE UNIQUE NONCLUSTERED INDEX [UX_TB_MAS_Users_Username] ON [dbo].[TB_MAS_Users]
+(
+	[Username] ASC
+) ON [PRIMARY]
+GO
+-- Hard-deleting a subscriber also removes their delivery log.
+ALTER TABLE [dbo].[TB_TRN_Deliveries] DROP CONSTRAINT [FK_TB_TRN_Deliveries_Subscribers]
+GO
+ALTER TABLE [dbo].[TB_TRN_Deliveries] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Deliveries_Subscribers] FOREIGN KEY([SubscriberId])
+REFERENCES [dbo].[TB_TRN_Subscribers] ([Id])
+ON DELETE CASCADE
+GO
@@ -0,0 +1,38 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_MAS_ApiKeys](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Name] [nvarchar](255) NOT NULL,
+	[Prefix] [nvarchar](20) NOT NULL,
+	[KeyHash] [char](64) NOT NULL,
+	[Scopes] [nvarchar](255) NOT NULL,
+	[UsageCount] [bigint] NOT NULL,
+	[LastUsedDate] [datetime] NULL,
+	[CreatedBy] [nvarchar](255) NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[RevokedDate] [datetime] NULL,
+ CONSTRAINT [PK_TB_MAS_ApiKeys] PRIMARY KEY CLUSTERED 
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_MAS_ApiKeys] ADD  CONSTRAINT [DF_TB_MAS_ApiKeys_UsageCount]  DEFAULT ((0)) FOR [UsageCount]
+GO
+ALTER TABLE [dbo].[TB_MAS_ApiKeys] ADD  CONSTRAINT [DF_TB_MAS_ApiKeys_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+CREATE UNIQUE NONCLUSTERED INDEX [UX_TB_MAS_ApiKeys_KeyHash] ON [dbo].[TB_MAS_ApiKeys]
+(
+	[KeyHash] ASC
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_Subscribers] ADD
+	[SourceApiKeyId] [bigint] NULL
+GO
+ALTER TABLE [dbo].[TB_TRN_Subscribers] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Subscribers_ApiKeys] FOREIGN KEY([SourceApiKeyId])
+REFERENCES [dbo].[TB_MAS_ApiKeys] ([Id])
+GO
@@ -0,0 +1,61 @@
+USE [subscribeproject]
+GO
+SET ANSI_NULLS ON
+GO
+SET QUOTED_IDENTIFIER ON
+GO
+CREATE TABLE [dbo].[TB_MAS_Lists](
+	[Id] [bigint] IDENTITY(1,1) NOT NULL,
+	[Name] [nvarchar](255) NOT NULL,
+	[Description] [nvarchar](1000) NOT NULL,
+	[CreatedDate] [datetime] NOT NULL,
+	[UpdatedDate] [datetime] NULL,
+	[Delflag] [bit] NOT NULL,
+ CONSTRAINT [PK_TB_MAS_Lists] PRIMARY KEY CLUSTERED 
+(
+	[Id] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_MAS_Lists] ADD  CONSTRAINT [DF_TB_MAS_Lists_Description]  DEFAULT (N'') FOR [Description]
+GO
+ALTER TABLE [dbo].[TB_MAS_Lists] ADD  CONSTRAINT [DF_TB_MAS_Lists_CreatedDate]  DEFAULT (getdate()) FOR [CreatedDate]
+GO
+ALTER TABLE [dbo].[TB_MAS_Lists] ADD  CONSTRAINT [DF_TB_MAS_Lists_Delflag]  DEFAULT ((0)) FOR [Delflag]
+GO
+CREATE TABLE [dbo].[TB_TRN_SubscriberLists](
+	[SubscriberId] [bigint] NOT NULL,
+	[ListId] [bigint] NOT NULL,
+	[IsSubscribed] [bit] NOT NULL,
+	[SubscribedDate] [datetime] NOT NULL,
+	[UnsubscribedDate] [datetime] NULL,
+ CONSTRAINT [PK_TB_TRN_SubscriberLists] PRIMARY KEY CLUSTERED 
+(
+	[SubscriberId] ASC,
+	[ListId] ASC
+)WITH (PAD_INDEX = OFF, STATISTICS_NORECOMPUTE = OFF, IGNORE_DUP_KEY = OFF, ALLOW_ROW_LOCKS = ON, ALLOW_PAGE_LOCKS = ON) ON [PRIMARY]
+) ON [PRIMARY]
+GO
+ALTER TABLE [dbo].[TB_TRN_SubscriberLists] ADD  CONSTRAINT [DF_TB_TRN_SubscriberLists_SubscribedDate]  DEFAULT (getdate()) FOR [SubscribedDate]
+GO
+-- Hard-deleting a subscriber also removes their memberships.
+ALTER TABLE [dbo].[TB_TRN_SubscriberLists] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_SubscriberLists_Subscribers] FOREIGN KEY([SubscriberId])
+REFERENCES [dbo].[TB_TRN_Subscribers] ([Id])
+ON DELETE CASCADE
+GO
+ALTER TABLE [dbo].[TB_TRN_SubscriberLists] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_SubscriberLists_Lists] FOREIGN KEY([ListId])
+REFERENCES [dbo].[TB_MAS_Lists] ([Id])
+GO
+CREATE NONCLUSTERED INDEX [IX_TB_TRN_SubscriberLists_List] ON [dbo].[TB_TRN_SubscriberLists]
+(
+	[ListId] ASC,
+	[IsSubscribed] ASC
+) INCLUDE ([SubscriberId]) ON [PRIMARY]
+GO
+-- Campaigns without a list keep going to every subscriber.
+ALTER TABLE [dbo].[TB_TRN_Campaigns] ADD
+	[ListId] [bigint] NULL
+GO
+ALTER TABLE [dbo].[TB_TRN_Campaigns] WITH CHECK ADD  CONSTRAINT [FK_TB_TRN_Campaigns_Lists] FOREIGN KEY([ListId])
+REFERENCES [dbo].[TB_MAS_Lists] ([Id])
+GO
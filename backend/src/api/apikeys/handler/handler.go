@@ -0,0 +1,100 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type APIKeysHandler struct {
+	Service apikeys.UseCase
+	Logs    logger.Logger
+}
+
+func MakeAPIKeysHandler(handlerParam HandlerParam) *APIKeysHandler {
+	return &APIKeysHandler{
+		Service: handlerParam.Service,
+		Logs:    handlerParam.Logs,
+	}
+}
+
+func (handler *APIKeysHandler) GetAllAPIKeys(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	res, err := handler.Service.GetAll()
+	if err != nil {
+		handler.responseError(response, request, "apikeys_handler_getAllAPIKeys", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// IssueAPIKey responds with the new key in plain text. It is not stored, so
+// the caller must keep it from this response.
+func (handler *APIKeysHandler) IssueAPIKey(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.APIKeys
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "apikeys_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	body.CreatedBy = ""
+	if claims, ok := middleware.ClaimsFromContext(request.Context()); ok {
+		body.CreatedBy = claims.Subject
+	}
+
+	res, err := handler.Service.Issue(body)
+	if err != nil {
+		handler.responseError(response, request, "apikeys_handler_issueAPIKey", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *APIKeysHandler) RevokeAPIKey(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "apikeys_handler_revokeAPIKey", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Revoke(id)
+	if err != nil {
+		handler.responseError(response, request, "apikeys_handler_revokeAPIKey", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "revoke api key success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *APIKeysHandler) responseError(response http.ResponseWriter, request *http.Request, actionName string, err newsletterError.ErrorCode) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(err), nil, err)
+	statusCode, errMsg := newsletterError.MapMessageError(err, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
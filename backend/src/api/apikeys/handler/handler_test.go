@@ -0,0 +1,216 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/apikeys/handler"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/apikeys/mocks"
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	uri           string
+	service       *mocks.UseCase
+	apiKeyHandler *handler.APIKeysHandler
+	logs          *loggerMocks.Logger
+	recorder      *httptest.ResponseRecorder
+	request       *http.Request
+	router        *mux.Router
+
+	mockServiceGetAll *mocker.MockCall
+	mockServiceIssue  *mocker.MockCall
+	mockServiceRevoke *mocker.MockCall
+)
+
+func callServiceGetAll() *mock.Call {
+	return service.On("GetAll")
+}
+
+func callServiceIssue() *mock.Call {
+	return service.On("Issue", mock.Anything)
+}
+
+func callServiceRevoke() *mock.Call {
+	return service.On("Revoke", mock.Anything)
+}
+
+func beforeEach() {
+	uri = "/api-keys"
+	service = &mocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	apiKeyHandler = &handler.APIKeysHandler{
+		Service: service,
+		Logs:    logs,
+	}
+	router = mux.NewRouter()
+	recorder = httptest.NewRecorder()
+}
+
+func assertResponseError(t *testing.T, code newsletterError.ErrorCode) {
+	expectedStatusCode, expectedError := newsletterError.MapMessageError(code, "en")
+	var body newsletterError.Error
+	json.NewDecoder(recorder.Body).Decode(&body)
+	assert.Equal(t, expectedError, body)
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+}
+
+func TestHandler_MakeAPIKeysHandler(t *testing.T) {
+	t.Run("should return struct api keys handler when call make api keys handler", func(t *testing.T) {
+		beforeEach()
+		handlerParam := handler.HandlerParam{
+			Service: service,
+			Logs:    logs,
+		}
+
+		handlerMakeAPIKeys := handler.MakeAPIKeysHandler(handlerParam)
+
+		expectedResult := &handler.APIKeysHandler{
+			Service: handlerParam.Service,
+			Logs:    handlerParam.Logs,
+		}
+		assert.Equal(t, expectedResult, handlerMakeAPIKeys)
+	})
+}
+
+func TestHandler_GetAllAPIKeys(t *testing.T) {
+	beforeEachGetAllAPIKeys := func() {
+		beforeEach()
+		router.HandleFunc(uri, apiKeyHandler.GetAllAPIKeys)
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
+
+		mockServiceGetAll = mocker.NewMockCall(callServiceGetAll)
+		mockServiceGetAll.Return(nil, nil)
+	}
+
+	t.Run("should response data not found error when service get all failed", func(t *testing.T) {
+		beforeEachGetAllAPIKeys()
+		mockServiceGetAll.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+
+	t.Run("should response keys without hash when service get all success", func(t *testing.T) {
+		beforeEachGetAllAPIKeys()
+		mockServiceGetAll.Return([]entity.APIKeys{{ID: 1, Name: "marketing", KeyHash: "hash", UsageCount: 4}}, nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.NotContains(t, recorder.Body.String(), "hash")
+		var body []entity.APIKeys
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, []entity.APIKeys{{ID: 1, Name: "marketing", UsageCount: 4}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_IssueAPIKey(t *testing.T) {
+	beforeEachIssueAPIKey := func() {
+		beforeEach()
+		router.HandleFunc(uri, apiKeyHandler.IssueAPIKey)
+
+		mockServiceIssue = mocker.NewMockCall(callServiceIssue)
+		mockServiceIssue.Return(&apikeys.IssuedKey{APIKeys: entity.APIKeys{ID: 5, Name: "marketing"}, Key: "nlk_secret"}, nil)
+	}
+	withClaims := func(request *http.Request) *http.Request {
+		claims := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "admin"}}
+		return request.WithContext(context.WithValue(request.Context(), middleware.JWTClaimsContextKey, claims))
+	}
+
+	t.Run("should response bad request when request body format is invalid", func(t *testing.T) {
+		beforeEachIssueAPIKey()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(``)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should issue key created by token subject", func(t *testing.T) {
+		beforeEachIssueAPIKey()
+		request = withClaims(httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"name":"marketing","scopes":"subscribers:subscribe","createdBy":"someone"}`))))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Issue", entity.APIKeys{Name: "marketing", Scopes: "subscribers:subscribe", CreatedBy: "admin"})
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+		var body map[string]interface{}
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, "nlk_secret", body["key"])
+		assert.Equal(t, float64(5), body["id"])
+	})
+
+	t.Run("should response error when service issue failed", func(t *testing.T) {
+		beforeEachIssueAPIKey()
+		mockServiceIssue.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InvalidAPIKeyScope))
+		request = withClaims(httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"name":"marketing","scopes":"apikeys:manage"}`))))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.InvalidAPIKeyScope)
+	})
+}
+
+func TestHandler_RevokeAPIKey(t *testing.T) {
+	beforeEachRevokeAPIKey := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", apiKeyHandler.RevokeAPIKey)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/5", nil)
+
+		mockServiceRevoke = mocker.NewMockCall(callServiceRevoke)
+		mockServiceRevoke.Return(nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachRevokeAPIKey()
+		request = httptest.NewRequest(http.MethodDelete, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+		service.AssertNotCalled(t, "Revoke", mock.Anything)
+	})
+
+	t.Run("should response data not found when key is missing", func(t *testing.T) {
+		beforeEachRevokeAPIKey()
+		mockServiceRevoke.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+
+	t.Run("should response success when key was revoked", func(t *testing.T) {
+		beforeEachRevokeAPIKey()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Revoke", int64(5))
+		assert.Equal(t, "revoke api key success", body.Body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/utils/logger"
+)
+
+type HandlerParam struct {
+	Service apikeys.UseCase
+	Logs    logger.Logger
+}
+
+type ResponseSucess struct {
+	Body string `json:"body"`
+}
@@ -0,0 +1,367 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/attachments"
+	campaigns "newsletter/src/pkg/campaigns"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type CampaignsHandler struct {
+	Service           campaigns.UseCase
+	MaxAttachmentSize int64
+	Logs              logger.Logger
+}
+
+func MakeCampaignsHandler(handlerParam HandlerParam) *CampaignsHandler {
+	return &CampaignsHandler{
+		Service:           handlerParam.Service,
+		MaxAttachmentSize: handlerParam.MaxAttachmentSize,
+		Logs:              handlerParam.Logs,
+	}
+}
+
+func (handler *CampaignsHandler) GetAllCampaigns(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	res, err := handler.Service.GetAllCampaigns()
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_getAllCampaigns", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) GetCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_getCampaign", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.FindByID(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_getCampaign", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) CreateCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.Campaigns
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "campaigns_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.Create(body)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_createCampaign", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) UpdateCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_updateCampaign", newsletterError.BadRequest)
+		return
+	}
+
+	var body entity.Campaigns
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "campaigns_handler_decode", newsletterError.BadRequest)
+		return
+	}
+	body.ID = id
+
+	err := handler.Service.Update(body)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_updateCampaign", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "update campaign success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) DeleteCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_deleteCampaign", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Delete(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_deleteCampaign", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete campaign success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) SendCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_sendCampaign", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Send(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_sendCampaign", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "campaign queued",
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) ResumeCampaign(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_resumeCampaign", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Resume(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_resumeCampaign", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "campaign queued",
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) GetDeliveries(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_getDeliveries", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.GetDeliveries(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_getDeliveries", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) GetDeadLetters(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_getDeadLetters", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.GetDeadLetters(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_getDeadLetters", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) RedriveDeadLetters(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_redriveDeadLetters", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Redrive(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_redriveDeadLetters", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "campaign queued",
+	}
+
+	response.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) GetAttachments(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_getAttachments", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.GetAttachments(id)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_getAttachments", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// UploadAttachment takes one file as multipart/form-data. Bodies bigger than
+// the attachment limit are refused before the file is read.
+func (handler *CampaignsHandler) UploadAttachment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_uploadAttachment", newsletterError.BadRequest)
+		return
+	}
+
+	if handler.MaxAttachmentSize > 0 {
+		if request.ContentLength > handler.MaxAttachmentSize+uploadOverhead {
+			handler.responseError(response, request, "campaigns_handler_uploadAttachment", newsletterError.AttachmentTooLarge)
+			return
+		}
+		request.Body = http.MaxBytesReader(response, request.Body, handler.MaxAttachmentSize+uploadOverhead)
+	}
+
+	errForm := request.ParseMultipartForm(uploadMemory)
+	if errForm != nil {
+		handler.responseError(response, request, "campaigns_handler_parseForm", newsletterError.BadRequest)
+		return
+	}
+	defer request.MultipartForm.RemoveAll()
+
+	file, fileHeader, errFile := request.FormFile(AttachmentFileField)
+	if errFile != nil {
+		handler.responseError(response, request, "campaigns_handler_formFile", newsletterError.BadRequest)
+		return
+	}
+	defer file.Close()
+
+	data, errRead := io.ReadAll(file)
+	if errRead != nil {
+		handler.responseError(response, request, "campaigns_handler_readFile", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.AddAttachment(attachments.Upload{
+		CampaignID:  id,
+		FileName:    fileHeader.Filename,
+		ContentType: fileHeader.Header.Get(requestHeader.ContentType),
+		ContentID:   request.FormValue(AttachmentContentIDField),
+		Data:        data,
+	})
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_uploadAttachment", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) DeleteAttachment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "campaigns_handler_deleteAttachment", newsletterError.BadRequest)
+		return
+	}
+
+	attachmentID, errAttachmentID := strconv.ParseInt(mux.Vars(request)["attachmentId"], 10, 64)
+	if errAttachmentID != nil {
+		handler.responseError(response, request, "campaigns_handler_deleteAttachment", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.DeleteAttachment(id, attachmentID)
+	if err != nil {
+		handler.responseError(response, request, "campaigns_handler_deleteAttachment", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete attachment success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CampaignsHandler) responseError(response http.ResponseWriter, request *http.Request, actionName string, err newsletterError.ErrorCode) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(err), nil, err)
+	statusCode, errMsg := newsletterError.MapMessageError(err, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
@@ -0,0 +1,655 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/campaigns/handler"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/attachments"
+	"newsletter/src/pkg/campaigns/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	uri             string
+	service         *mocks.UseCase
+	campaignHandler *handler.CampaignsHandler
+	logs            *loggerMocks.Logger
+	recorder        *httptest.ResponseRecorder
+	request         *http.Request
+	router          *mux.Router
+
+	mockServiceGetAllCampaigns  *mocker.MockCall
+	mockServiceFindByID         *mocker.MockCall
+	mockServiceCreate           *mocker.MockCall
+	mockServiceUpdate           *mocker.MockCall
+	mockServiceDelete           *mocker.MockCall
+	mockServiceSend             *mocker.MockCall
+	mockServiceResume           *mocker.MockCall
+	mockServiceGetDeliveries    *mocker.MockCall
+	mockServiceGetDeadLetters   *mocker.MockCall
+	mockServiceRedrive          *mocker.MockCall
+	mockServiceGetAttachments   *mocker.MockCall
+	mockServiceAddAttachment    *mocker.MockCall
+	mockServiceDeleteAttachment *mocker.MockCall
+)
+
+func callServiceGetAllCampaigns() *mock.Call {
+	return service.On("GetAllCampaigns")
+}
+
+func callServiceFindByID() *mock.Call {
+	return service.On("FindByID", mock.Anything)
+}
+
+func callServiceCreate() *mock.Call {
+	return service.On("Create", mock.Anything)
+}
+
+func callServiceUpdate() *mock.Call {
+	return service.On("Update", mock.Anything)
+}
+
+func callServiceDelete() *mock.Call {
+	return service.On("Delete", mock.Anything)
+}
+
+func callServiceSend() *mock.Call {
+	return service.On("Send", mock.Anything)
+}
+
+func callServiceResume() *mock.Call {
+	return service.On("Resume", mock.Anything)
+}
+
+func callServiceGetDeliveries() *mock.Call {
+	return service.On("GetDeliveries", mock.Anything)
+}
+
+func callServiceGetDeadLetters() *mock.Call {
+	return service.On("GetDeadLetters", mock.Anything)
+}
+
+func callServiceRedrive() *mock.Call {
+	return service.On("Redrive", mock.Anything)
+}
+
+func callServiceGetAttachments() *mock.Call {
+	return service.On("GetAttachments", mock.Anything)
+}
+
+func callServiceAddAttachment() *mock.Call {
+	return service.On("AddAttachment", mock.Anything)
+}
+
+func callServiceDeleteAttachment() *mock.Call {
+	return service.On("DeleteAttachment", mock.Anything, mock.Anything)
+}
+
+func beforeEach() {
+	uri = "/campaigns"
+	service = &mocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	campaignHandler = &handler.CampaignsHandler{
+		Service: service,
+		Logs:    logs,
+	}
+	router = mux.NewRouter()
+	recorder = httptest.NewRecorder()
+}
+
+func assertResponseError(t *testing.T, code newsletterError.ErrorCode) {
+	expectedStatusCode, expectedError := newsletterError.MapMessageError(code, "en")
+	var body newsletterError.Error
+	json.NewDecoder(recorder.Body).Decode(&body)
+	assert.Equal(t, expectedError, body)
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+}
+
+func TestHandler_MakeCampaignsHandler(t *testing.T) {
+	t.Run("should return struct campaigns handler when call make campaigns handler", func(t *testing.T) {
+		beforeEach()
+		handlerParam := handler.HandlerParam{
+			Service:           service,
+			MaxAttachmentSize: 1024,
+			Logs:              logs,
+		}
+
+		handlerMakeCampaigns := handler.MakeCampaignsHandler(handlerParam)
+
+		expectedResult := &handler.CampaignsHandler{
+			Service:           handlerParam.Service,
+			MaxAttachmentSize: 1024,
+			Logs:              handlerParam.Logs,
+		}
+		assert.Equal(t, expectedResult, handlerMakeCampaigns)
+	})
+}
+
+func TestHandler_GetAllCampaigns(t *testing.T) {
+	beforeEachGetAllCampaigns := func() {
+		beforeEach()
+		router.HandleFunc(uri, campaignHandler.GetAllCampaigns)
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
+
+		mockServiceGetAllCampaigns = mocker.NewMockCall(callServiceGetAllCampaigns)
+		mockServiceGetAllCampaigns.Return(nil, nil)
+	}
+
+	t.Run("should response data not found error when service get all campaigns failed", func(t *testing.T) {
+		beforeEachGetAllCampaigns()
+		mockServiceGetAllCampaigns.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+
+	t.Run("should response campaigns when service get all campaigns success", func(t *testing.T) {
+		beforeEachGetAllCampaigns()
+		resCampaigns := []entity.Campaigns{{ID: 1, Subject: "test"}}
+		mockServiceGetAllCampaigns.Return(resCampaigns, nil)
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Campaigns
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, resCampaigns, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_GetCampaign(t *testing.T) {
+	beforeEachGetCampaign := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", campaignHandler.GetCampaign)
+		request = httptest.NewRequest(http.MethodGet, uri+"/1", nil)
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetCampaign()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+		service.AssertNotCalled(t, "FindByID", mock.Anything)
+	})
+
+	t.Run("should response data not found when campaign not found", func(t *testing.T) {
+		beforeEachGetCampaign()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+
+	t.Run("should response error in request language", func(t *testing.T) {
+		beforeEachGetCampaign()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		request = request.WithContext(context.WithValue(request.Context(), middleware.LanguageContextKey, "th"))
+
+		router.ServeHTTP(recorder, request)
+
+		_, expectedError := newsletterError.MapMessageError(newsletterError.DataNotFound, "th")
+		var body newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, expectedError, body)
+	})
+
+	t.Run("should response campaign when found", func(t *testing.T) {
+		beforeEachGetCampaign()
+
+		router.ServeHTTP(recorder, request)
+
+		var body entity.Campaigns
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "FindByID", int64(1))
+		assert.Equal(t, entity.Campaigns{ID: 1}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_CreateCampaign(t *testing.T) {
+	beforeEachCreateCampaign := func() {
+		beforeEach()
+		router.HandleFunc(uri, campaignHandler.CreateCampaign)
+
+		mockServiceCreate = mocker.NewMockCall(callServiceCreate)
+		mockServiceCreate.Return(&entity.Campaigns{ID: 1, Subject: "test"}, nil)
+	}
+
+	t.Run("should response bad request when request body format is invalid", func(t *testing.T) {
+		beforeEachCreateCampaign()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(``)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response error when service create failed", func(t *testing.T) {
+		beforeEachCreateCampaign()
+		mockServiceCreate.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus))
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"subject":"test","status":"sent"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.InvalidCampaignStatus)
+	})
+
+	t.Run("should response created campaign when service create success", func(t *testing.T) {
+		beforeEachCreateCampaign()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"subject":"test","htmlBody":"<p>test</p>"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		var body entity.Campaigns
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Create", entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>"})
+		assert.Equal(t, entity.Campaigns{ID: 1, Subject: "test"}, body)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+}
+
+func TestHandler_UpdateCampaign(t *testing.T) {
+	beforeEachUpdateCampaign := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", campaignHandler.UpdateCampaign)
+
+		mockServiceUpdate = mocker.NewMockCall(callServiceUpdate)
+		mockServiceUpdate.Return(nil)
+	}
+
+	t.Run("should response campaign already sent when service update failed", func(t *testing.T) {
+		beforeEachUpdateCampaign()
+		mockServiceUpdate.Return(convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent))
+		request = httptest.NewRequest(http.MethodPut, uri+"/1", bytes.NewBuffer([]byte(`{"subject":"test"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.CampaignAlreadySent)
+	})
+
+	t.Run("should update campaign with id from path", func(t *testing.T) {
+		beforeEachUpdateCampaign()
+		request = httptest.NewRequest(http.MethodPut, uri+"/1", bytes.NewBuffer([]byte(`{"id":99,"subject":"test"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Update", entity.Campaigns{ID: 1, Subject: "test"})
+		assert.Equal(t, handler.ResponseSucess{Body: "update campaign success"}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_DeleteCampaign(t *testing.T) {
+	t.Run("should response delete success when service delete success", func(t *testing.T) {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", campaignHandler.DeleteCampaign)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/1", nil)
+		mockServiceDelete = mocker.NewMockCall(callServiceDelete)
+		mockServiceDelete.Return(nil)
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Delete", int64(1))
+		assert.Equal(t, handler.ResponseSucess{Body: "delete campaign success"}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_SendCampaign(t *testing.T) {
+	beforeEachSendCampaign := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/send", campaignHandler.SendCampaign)
+		request = httptest.NewRequest(http.MethodPost, uri+"/1/send", nil)
+
+		mockServiceSend = mocker.NewMockCall(callServiceSend)
+		mockServiceSend.Return(nil)
+	}
+
+	t.Run("should response campaign already sent when service send failed", func(t *testing.T) {
+		beforeEachSendCampaign()
+		mockServiceSend.Return(convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.CampaignAlreadySent)
+	})
+
+	t.Run("should response accepted when service send success", func(t *testing.T) {
+		beforeEachSendCampaign()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Send", int64(1))
+		assert.Equal(t, handler.ResponseSucess{Body: "campaign queued"}, body)
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+	})
+}
+
+func TestHandler_ResumeCampaign(t *testing.T) {
+	beforeEachResumeCampaign := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/resume", campaignHandler.ResumeCampaign)
+		request = httptest.NewRequest(http.MethodPost, uri+"/1/resume", nil)
+
+		mockServiceResume = mocker.NewMockCall(callServiceResume)
+		mockServiceResume.Return(nil)
+	}
+
+	t.Run("should response delivery in progress when campaign is delivering", func(t *testing.T) {
+		beforeEachResumeCampaign()
+		mockServiceResume.Return(convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.CampaignDeliveryInProgress)
+	})
+
+	t.Run("should response accepted when service resume success", func(t *testing.T) {
+		beforeEachResumeCampaign()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Resume", int64(1))
+		assert.Equal(t, handler.ResponseSucess{Body: "campaign queued"}, body)
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+	})
+}
+
+func TestHandler_GetDeliveries(t *testing.T) {
+	beforeEachGetDeliveries := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/deliveries", campaignHandler.GetDeliveries)
+		request = httptest.NewRequest(http.MethodGet, uri+"/1/deliveries", nil)
+
+		mockServiceGetDeliveries = mocker.NewMockCall(callServiceGetDeliveries)
+		mockServiceGetDeliveries.Return([]entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusSent}}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetDeliveries()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc/deliveries", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response deliveries when service get deliveries success", func(t *testing.T) {
+		beforeEachGetDeliveries()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Deliveries
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "GetDeliveries", int64(1))
+		assert.Equal(t, []entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusSent}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_GetDeadLetters(t *testing.T) {
+	beforeEachGetDeadLetters := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/dead-letters", campaignHandler.GetDeadLetters)
+		request = httptest.NewRequest(http.MethodGet, uri+"/1/dead-letters", nil)
+
+		mockServiceGetDeadLetters = mocker.NewMockCall(callServiceGetDeadLetters)
+		mockServiceGetDeadLetters.Return([]entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusDead, ReplyCode: convert.ValueToIntPointer(550)}}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc/dead-letters", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response data not found when campaign has no dead letter", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		mockServiceGetDeadLetters.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+
+	t.Run("should response dead letters when service get dead letters success", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Deliveries
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "GetDeadLetters", int64(1))
+		assert.Equal(t, []entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusDead, ReplyCode: convert.ValueToIntPointer(550)}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_RedriveDeadLetters(t *testing.T) {
+	beforeEachRedrive := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/dead-letters/redrive", campaignHandler.RedriveDeadLetters)
+		request = httptest.NewRequest(http.MethodPost, uri+"/1/dead-letters/redrive", nil)
+
+		mockServiceRedrive = mocker.NewMockCall(callServiceRedrive)
+		mockServiceRedrive.Return(nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachRedrive()
+		request = httptest.NewRequest(http.MethodPost, uri+"/abc/dead-letters/redrive", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response delivery in progress when campaign is delivering", func(t *testing.T) {
+		beforeEachRedrive()
+		mockServiceRedrive.Return(convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.CampaignDeliveryInProgress)
+	})
+
+	t.Run("should response accepted when service redrive success", func(t *testing.T) {
+		beforeEachRedrive()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Redrive", int64(1))
+		assert.Equal(t, handler.ResponseSucess{Body: "campaign queued"}, body)
+		assert.Equal(t, http.StatusAccepted, recorder.Code)
+	})
+}
+
+func TestHandler_GetAttachments(t *testing.T) {
+	beforeEachGetAttachments := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/attachments", campaignHandler.GetAttachments)
+		request = httptest.NewRequest(http.MethodGet, uri+"/1/attachments", nil)
+
+		mockServiceGetAttachments = mocker.NewMockCall(callServiceGetAttachments)
+		mockServiceGetAttachments.Return([]entity.Attachments{{ID: 4, CampaignID: 1, FileName: "report.pdf"}}, nil)
+	}
+
+	t.Run("should response attachments of campaign", func(t *testing.T) {
+		beforeEachGetAttachments()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Attachments
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "GetAttachments", int64(1))
+		assert.Equal(t, []entity.Attachments{{ID: 4, CampaignID: 1, FileName: "report.pdf"}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response data not found when campaign has no attachment", func(t *testing.T) {
+		beforeEachGetAttachments()
+		mockServiceGetAttachments.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_UploadAttachment(t *testing.T) {
+	newUploadRequest := func(fileName string, data string, contentID string) *http.Request {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		if fileName != "" {
+			part, _ := writer.CreateFormFile(handler.AttachmentFileField, fileName)
+			part.Write([]byte(data))
+		}
+		if contentID != "" {
+			writer.WriteField(handler.AttachmentContentIDField, contentID)
+		}
+		writer.Close()
+
+		uploadRequest := httptest.NewRequest(http.MethodPost, uri+"/1/attachments", &body)
+		uploadRequest.Header.Set(requestHeader.ContentType, writer.FormDataContentType())
+		return uploadRequest
+	}
+
+	beforeEachUpload := func() {
+		beforeEach()
+		campaignHandler.MaxAttachmentSize = 1024
+		router.HandleFunc(uri+"/{id}/attachments", campaignHandler.UploadAttachment)
+		request = newUploadRequest("logo.png", "png", "logo")
+
+		mockServiceAddAttachment = mocker.NewMockCall(callServiceAddAttachment)
+		mockServiceAddAttachment.Return(&entity.Attachments{ID: 4, CampaignID: 1, FileName: "logo.png"}, nil)
+	}
+
+	t.Run("should pass uploaded file and content id to service", func(t *testing.T) {
+		beforeEachUpload()
+
+		router.ServeHTTP(recorder, request)
+
+		var body entity.Attachments
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "AddAttachment", attachments.Upload{
+			CampaignID:  1,
+			FileName:    "logo.png",
+			ContentType: "application/octet-stream",
+			ContentID:   "logo",
+			Data:        []byte("png"),
+		})
+		assert.Equal(t, entity.Attachments{ID: 4, CampaignID: 1, FileName: "logo.png"}, body)
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("should response bad request when form has no file", func(t *testing.T) {
+		beforeEachUpload()
+		request = newUploadRequest("", "", "logo")
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+		service.AssertNotCalled(t, "AddAttachment", mock.Anything)
+	})
+
+	t.Run("should response bad request when body is not multipart", func(t *testing.T) {
+		beforeEachUpload()
+		request = httptest.NewRequest(http.MethodPost, uri+"/1/attachments", bytes.NewBufferString("{}"))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response attachment too large before reading body over limit", func(t *testing.T) {
+		beforeEachUpload()
+		campaignHandler.MaxAttachmentSize = 1
+		request = newUploadRequest("report.pdf", strings.Repeat("a", 128<<10), "")
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.AttachmentTooLarge)
+		service.AssertNotCalled(t, "AddAttachment", mock.Anything)
+	})
+
+	t.Run("should response error from service", func(t *testing.T) {
+		beforeEachUpload()
+		mockServiceAddAttachment.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DuplicateContentID))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DuplicateContentID)
+	})
+}
+
+func TestHandler_DeleteAttachment(t *testing.T) {
+	beforeEachDeleteAttachment := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/attachments/{attachmentId}", campaignHandler.DeleteAttachment)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/1/attachments/4", nil)
+
+		mockServiceDeleteAttachment = mocker.NewMockCall(callServiceDeleteAttachment)
+		mockServiceDeleteAttachment.Return(nil)
+	}
+
+	t.Run("should response bad request when attachment id is not a number", func(t *testing.T) {
+		beforeEachDeleteAttachment()
+		request = httptest.NewRequest(http.MethodDelete, uri+"/1/attachments/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response success when service delete attachment success", func(t *testing.T) {
+		beforeEachDeleteAttachment()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "DeleteAttachment", int64(1), int64(4))
+		assert.Equal(t, handler.ResponseSucess{Body: "delete attachment success"}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
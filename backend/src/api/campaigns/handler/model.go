@@ -0,0 +1,33 @@
+package handler
+
+import (
+	campaigns "newsletter/src/pkg/campaigns"
+	"newsletter/src/pkg/utils/logger"
+)
+
+const (
+	// AttachmentFileField and AttachmentContentIDField are the form fields of
+	// an attachment upload. Sending a content ID makes the file an inline
+	// image.
+	AttachmentFileField      = "file"
+	AttachmentContentIDField = "contentId"
+
+	// uploadMemory is how much of an upload is held in memory before the
+	// rest spills to a temporary file.
+	uploadMemory = 1 << 20
+	// uploadOverhead allows for the multipart framing around the file.
+	uploadOverhead = 64 << 10
+)
+
+// HandlerParam carries the campaigns service. MaxAttachmentSize turns away
+// uploads whose body is plainly too big before they are read; zero leaves
+// the check to the service.
+type HandlerParam struct {
+	Service           campaigns.UseCase
+	MaxAttachmentSize int64
+	Logs              logger.Logger
+}
+
+type ResponseSucess struct {
+	Body string `json:"body"`
+}
@@ -0,0 +1,145 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type CustomFieldsHandler struct {
+	Service customfields.UseCase
+	Logs    logger.Logger
+}
+
+func MakeCustomFieldsHandler(handlerParam HandlerParam) *CustomFieldsHandler {
+	return &CustomFieldsHandler{
+		Service: handlerParam.Service,
+		Logs:    handlerParam.Logs,
+	}
+}
+
+func (handler *CustomFieldsHandler) GetAllFields(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	res, err := handler.Service.GetAllFields()
+	if err != nil {
+		handler.responseError(response, request, "customfields_handler_getAllFields", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CustomFieldsHandler) GetField(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "customfields_handler_getField", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.FindByID(id)
+	if err != nil {
+		handler.responseError(response, request, "customfields_handler_getField", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CustomFieldsHandler) CreateField(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.CustomFields
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "customfields_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.Create(body)
+	if err != nil {
+		handler.responseError(response, request, "customfields_handler_createField", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CustomFieldsHandler) UpdateField(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "customfields_handler_updateField", newsletterError.BadRequest)
+		return
+	}
+
+	var body entity.CustomFields
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "customfields_handler_decode", newsletterError.BadRequest)
+		return
+	}
+	body.ID = id
+
+	err := handler.Service.Update(body)
+	if err != nil {
+		handler.responseError(response, request, "customfields_handler_updateField", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "update custom field success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CustomFieldsHandler) DeleteField(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "customfields_handler_deleteField", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Delete(id)
+	if err != nil {
+		handler.responseError(response, request, "customfields_handler_deleteField", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete custom field success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *CustomFieldsHandler) responseError(response http.ResponseWriter, request *http.Request, actionName string, err newsletterError.ErrorCode) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(err), nil, err)
+	statusCode, errMsg := newsletterError.MapMessageError(err, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
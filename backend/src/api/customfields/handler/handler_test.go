@@ -0,0 +1,250 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/customfields/handler"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/customfields/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	uri          string
+	service      *mocks.UseCase
+	fieldHandler *handler.CustomFieldsHandler
+	logs         *loggerMocks.Logger
+	recorder     *httptest.ResponseRecorder
+	request      *http.Request
+	router       *mux.Router
+
+	mockServiceGetAllFields *mocker.MockCall
+	mockServiceFindByID     *mocker.MockCall
+	mockServiceCreate       *mocker.MockCall
+	mockServiceUpdate       *mocker.MockCall
+	mockServiceDelete       *mocker.MockCall
+)
+
+func callServiceGetAllFields() *mock.Call {
+	return service.On("GetAllFields")
+}
+
+func callServiceFindByID() *mock.Call {
+	return service.On("FindByID", mock.Anything)
+}
+
+func callServiceCreate() *mock.Call {
+	return service.On("Create", mock.Anything)
+}
+
+func callServiceUpdate() *mock.Call {
+	return service.On("Update", mock.Anything)
+}
+
+func callServiceDelete() *mock.Call {
+	return service.On("Delete", mock.Anything)
+}
+
+func beforeEach() {
+	uri = "/fields"
+	service = &mocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	fieldHandler = &handler.CustomFieldsHandler{
+		Service: service,
+		Logs:    logs,
+	}
+	router = mux.NewRouter()
+	recorder = httptest.NewRecorder()
+}
+
+func assertResponseError(t *testing.T, code newsletterError.ErrorCode) {
+	expectedStatusCode, expectedError := newsletterError.MapMessageError(code, "en")
+	var body newsletterError.Error
+	json.NewDecoder(recorder.Body).Decode(&body)
+	assert.Equal(t, expectedError, body)
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+}
+
+func TestHandler_MakeCustomFieldsHandler(t *testing.T) {
+	t.Run("should return struct custom fields handler when call make custom fields handler", func(t *testing.T) {
+		beforeEach()
+		handlerParam := handler.HandlerParam{
+			Service: service,
+			Logs:    logs,
+		}
+
+		handlerMakeFields := handler.MakeCustomFieldsHandler(handlerParam)
+
+		expectedResult := &handler.CustomFieldsHandler{
+			Service: handlerParam.Service,
+			Logs:    handlerParam.Logs,
+		}
+		assert.Equal(t, expectedResult, handlerMakeFields)
+	})
+}
+
+func TestHandler_GetAllFields(t *testing.T) {
+	beforeEachGetAllFields := func() {
+		beforeEach()
+		router.HandleFunc(uri, fieldHandler.GetAllFields)
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
+
+		mockServiceGetAllFields = mocker.NewMockCall(callServiceGetAllFields)
+		mockServiceGetAllFields.Return([]entity.CustomFields{{ID: 1, Key: "plan"}}, nil)
+	}
+
+	t.Run("should response fields when service get all fields success", func(t *testing.T) {
+		beforeEachGetAllFields()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.CustomFields
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, []entity.CustomFields{{ID: 1, Key: "plan"}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response data not found when there is no field", func(t *testing.T) {
+		beforeEachGetAllFields()
+		mockServiceGetAllFields.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_GetField(t *testing.T) {
+	beforeEachGetField := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", fieldHandler.GetField)
+		request = httptest.NewRequest(http.MethodGet, uri+"/1", nil)
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.CustomFields{ID: 1}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetField()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response field when found", func(t *testing.T) {
+		beforeEachGetField()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "FindByID", int64(1))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_CreateField(t *testing.T) {
+	beforeEachCreateField := func() {
+		beforeEach()
+		router.HandleFunc(uri, fieldHandler.CreateField)
+
+		mockServiceCreate = mocker.NewMockCall(callServiceCreate)
+		mockServiceCreate.Return(&entity.CustomFields{ID: 1, Key: "plan"}, nil)
+	}
+
+	t.Run("should response bad request when request body format is invalid", func(t *testing.T) {
+		beforeEachCreateField()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(``)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response created field", func(t *testing.T) {
+		beforeEachCreateField()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"key":"plan","label":"Plan","type":"enum","options":"free,pro"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Create", entity.CustomFields{Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,pro"})
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("should response duplicate field key when key is taken", func(t *testing.T) {
+		beforeEachCreateField()
+		mockServiceCreate.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DuplicateFieldKey))
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"key":"plan","label":"Plan","type":"string"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DuplicateFieldKey)
+	})
+}
+
+func TestHandler_UpdateField(t *testing.T) {
+	beforeEachUpdateField := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", fieldHandler.UpdateField)
+
+		mockServiceUpdate = mocker.NewMockCall(callServiceUpdate)
+		mockServiceUpdate.Return(nil)
+	}
+
+	t.Run("should update field of path id", func(t *testing.T) {
+		beforeEachUpdateField()
+		request = httptest.NewRequest(http.MethodPut, uri+"/1", bytes.NewBuffer([]byte(`{"id":9,"label":"Tier"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Update", entity.CustomFields{ID: 1, Label: "Tier"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response error when service update failed", func(t *testing.T) {
+		beforeEachUpdateField()
+		mockServiceUpdate.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		request = httptest.NewRequest(http.MethodPut, uri+"/1", bytes.NewBuffer([]byte(`{"label":"Tier"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_DeleteField(t *testing.T) {
+	beforeEachDeleteField := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", fieldHandler.DeleteField)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/1", nil)
+
+		mockServiceDelete = mocker.NewMockCall(callServiceDelete)
+		mockServiceDelete.Return(nil)
+	}
+
+	t.Run("should response success when field was deleted", func(t *testing.T) {
+		beforeEachDeleteField()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Delete", int64(1))
+		assert.Equal(t, "delete custom field success", body.Body)
+	})
+}
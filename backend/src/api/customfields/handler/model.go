@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/utils/logger"
+)
+
+type HandlerParam struct {
+	Service customfields.UseCase
+	Logs    logger.Logger
+}
+
+type ResponseSucess struct {
+	Body string `json:"body"`
+}
@@ -0,0 +1,286 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/lists"
+	subscribers "newsletter/src/pkg/subscribers"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/verification"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type ListsHandler struct {
+	Service  lists.UseCase
+	Fields   customfields.UseCase
+	Verifier verification.Verifier
+	Logs     logger.Logger
+}
+
+func MakeListsHandler(handlerParam HandlerParam) *ListsHandler {
+	return &ListsHandler{
+		Service:  handlerParam.Service,
+		Fields:   handlerParam.Fields,
+		Verifier: handlerParam.Verifier,
+		Logs:     handlerParam.Logs,
+	}
+}
+
+func (handler *ListsHandler) GetAllLists(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	res, err := handler.Service.GetAllLists()
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_getAllLists", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) GetList(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "lists_handler_getList", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.FindByID(id)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_getList", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) CreateList(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.Lists
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "lists_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.Create(body)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_createList", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) UpdateList(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "lists_handler_updateList", newsletterError.BadRequest)
+		return
+	}
+
+	var body entity.Lists
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "lists_handler_decode", newsletterError.BadRequest)
+		return
+	}
+	body.ID = id
+
+	err := handler.Service.Update(body)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_updateList", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "update list success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) DeleteList(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "lists_handler_deleteList", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Delete(id)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_deleteList", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete list success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) Subscribe(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "lists_handler_subscribe", newsletterError.BadRequest)
+		return
+	}
+
+	var subscribeRequest SubscribeRequest
+	errorBody := json.NewDecoder(request.Body).Decode(&subscribeRequest)
+	if errorBody != nil {
+		handler.responseError(response, request, "lists_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	_, hasAPIKey := middleware.APIKeyFromContext(request.Context())
+	if handler.Verifier != nil && !hasAPIKey {
+		if errVerify := handler.Verifier.Verify(subscribeRequest.Submission); errVerify != nil {
+			handler.responseError(response, request, "lists_handler_subscribe", *errVerify)
+			return
+		}
+	}
+
+	body, fieldErrors := subscribers.ValidateSubscribe(subscribeRequest.Subscribers)
+	fields, fieldValueErrors, errFields := handler.Fields.ValidateValues(body.Fields)
+	if errFields != nil {
+		handler.responseError(response, request, "lists_handler_subscribe", *errFields)
+		return
+	}
+	body.Fields = fields
+	fieldErrors = append(fieldErrors, fieldValueErrors...)
+	if len(fieldErrors) > 0 {
+		handler.responseValidationError(response, request, "lists_handler_subscribe", fieldErrors)
+		return
+	}
+
+	body.SourceAPIKeyID = nil
+	if apiKey, ok := middleware.APIKeyFromContext(request.Context()); ok {
+		body.SourceAPIKeyID = &apiKey.ID
+	}
+
+	err := handler.Service.Subscribe(id, body)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_subscribe", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "subscribe success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// Confirm puts whoever the list confirmation token was sent to on the list.
+func (handler *ListsHandler) Confirm(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	confirmToken := request.URL.Query().Get("token")
+	if confirmToken == "" {
+		handler.responseError(response, request, "lists_handler_confirm", newsletterError.EmptyToken)
+		return
+	}
+
+	err := handler.Service.Confirm(confirmToken)
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_confirm", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "confirm success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// UnsubscribeByToken takes whoever the unsubscribe token in the path was
+// issued to off the list, so nobody can unsubscribe an address they do not
+// own.
+func (handler *ListsHandler) UnsubscribeByToken(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "lists_handler_unsubscribeByToken", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.UnsubscribeByToken(id, mux.Vars(request)["token"])
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_unsubscribeByToken", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "unsubscribe success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) UnsubscribeAllByToken(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	err := handler.Service.UnsubscribeAllByToken(mux.Vars(request)["token"])
+	if err != nil {
+		handler.responseError(response, request, "lists_handler_unsubscribeAllByToken", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "unsubscribe all success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *ListsHandler) responseError(response http.ResponseWriter, request *http.Request, actionName string, err newsletterError.ErrorCode) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(err), nil, err)
+	statusCode, errMsg := newsletterError.MapMessageError(err, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
+
+func (handler *ListsHandler) responseValidationError(response http.ResponseWriter, request *http.Request, actionName string, fieldErrors []newsletterError.FieldError) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(newsletterError.ValidationFailed), nil, fieldErrors)
+	statusCode, errMsg := newsletterError.MapValidationError(fieldErrors, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
@@ -0,0 +1,499 @@
+package handler_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/lists/handler"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	customFieldsMocks "newsletter/src/pkg/customfields/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/lists/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"newsletter/src/pkg/verification"
+	verificationMocks "newsletter/src/pkg/verification/mocks"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	uri         string
+	service     *mocks.UseCase
+	fields      *customFieldsMocks.UseCase
+	verifier    *verificationMocks.Verifier
+	listHandler *handler.ListsHandler
+	logs        *loggerMocks.Logger
+	recorder    *httptest.ResponseRecorder
+	request     *http.Request
+	router      *mux.Router
+
+	mockServiceGetAllLists    *mocker.MockCall
+	mockServiceFindByID       *mocker.MockCall
+	mockServiceCreate         *mocker.MockCall
+	mockServiceUpdate         *mocker.MockCall
+	mockServiceDelete         *mocker.MockCall
+	mockServiceSubscribe      *mocker.MockCall
+	mockServiceConfirm        *mocker.MockCall
+	mockServiceUnsubscribe    *mocker.MockCall
+	mockServiceUnsubscribeAll *mocker.MockCall
+	mockVerifierVerify        *mocker.MockCall
+	mockFieldsValidateValues  *mocker.MockCall
+)
+
+func callServiceGetAllLists() *mock.Call {
+	return service.On("GetAllLists")
+}
+
+func callServiceFindByID() *mock.Call {
+	return service.On("FindByID", mock.Anything)
+}
+
+func callServiceCreate() *mock.Call {
+	return service.On("Create", mock.Anything)
+}
+
+func callServiceUpdate() *mock.Call {
+	return service.On("Update", mock.Anything)
+}
+
+func callServiceDelete() *mock.Call {
+	return service.On("Delete", mock.Anything)
+}
+
+func callServiceSubscribe() *mock.Call {
+	return service.On("Subscribe", mock.Anything, mock.Anything)
+}
+
+func callServiceConfirm() *mock.Call {
+	return service.On("Confirm", mock.Anything)
+}
+
+func callServiceUnsubscribe() *mock.Call {
+	return service.On("UnsubscribeByToken", mock.Anything, mock.Anything)
+}
+
+func callServiceUnsubscribeAll() *mock.Call {
+	return service.On("UnsubscribeAllByToken", mock.Anything)
+}
+
+func callFieldsValidateValues() *mock.Call {
+	return fields.On("ValidateValues", mock.Anything)
+}
+
+func callVerifierVerify() *mock.Call {
+	return verifier.On("Verify", mock.Anything)
+}
+
+func beforeEach() {
+	uri = "/lists"
+	service = &mocks.UseCase{}
+	fields = &customFieldsMocks.UseCase{}
+	verifier = &verificationMocks.Verifier{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	listHandler = &handler.ListsHandler{
+		Service: service,
+		Fields:  fields,
+		Logs:    logs,
+	}
+	router = mux.NewRouter()
+	recorder = httptest.NewRecorder()
+}
+
+func assertResponseError(t *testing.T, code newsletterError.ErrorCode) {
+	expectedStatusCode, expectedError := newsletterError.MapMessageError(code, "en")
+	var body newsletterError.Error
+	json.NewDecoder(recorder.Body).Decode(&body)
+	assert.Equal(t, expectedError, body)
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+}
+
+func TestHandler_MakeListsHandler(t *testing.T) {
+	t.Run("should return struct lists handler when call make lists handler", func(t *testing.T) {
+		beforeEach()
+		handlerParam := handler.HandlerParam{
+			Service:  service,
+			Fields:   fields,
+			Verifier: verifier,
+			Logs:     logs,
+		}
+
+		handlerMakeLists := handler.MakeListsHandler(handlerParam)
+
+		expectedResult := &handler.ListsHandler{
+			Service:  handlerParam.Service,
+			Fields:   handlerParam.Fields,
+			Verifier: handlerParam.Verifier,
+			Logs:     handlerParam.Logs,
+		}
+		assert.Equal(t, expectedResult, handlerMakeLists)
+	})
+}
+
+func TestHandler_GetAllLists(t *testing.T) {
+	beforeEachGetAllLists := func() {
+		beforeEach()
+		router.HandleFunc(uri, listHandler.GetAllLists)
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
+
+		mockServiceGetAllLists = mocker.NewMockCall(callServiceGetAllLists)
+		mockServiceGetAllLists.Return([]entity.Lists{{ID: 3, Name: "weekly digest"}}, nil)
+	}
+
+	t.Run("should response lists when service get all lists success", func(t *testing.T) {
+		beforeEachGetAllLists()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Lists
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, []entity.Lists{{ID: 3, Name: "weekly digest"}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response data not found when there is no list", func(t *testing.T) {
+		beforeEachGetAllLists()
+		mockServiceGetAllLists.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_GetList(t *testing.T) {
+	beforeEachGetList := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", listHandler.GetList)
+		request = httptest.NewRequest(http.MethodGet, uri+"/3", nil)
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Lists{ID: 3}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetList()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response list when found", func(t *testing.T) {
+		beforeEachGetList()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "FindByID", int64(3))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_CreateList(t *testing.T) {
+	beforeEachCreateList := func() {
+		beforeEach()
+		router.HandleFunc(uri, listHandler.CreateList)
+
+		mockServiceCreate = mocker.NewMockCall(callServiceCreate)
+		mockServiceCreate.Return(&entity.Lists{ID: 3, Name: "weekly digest"}, nil)
+	}
+
+	t.Run("should response bad request when request body format is invalid", func(t *testing.T) {
+		beforeEachCreateList()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(``)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response created list", func(t *testing.T) {
+		beforeEachCreateList()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"name":"weekly digest"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Create", entity.Lists{Name: "weekly digest"})
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+}
+
+func TestHandler_UpdateList(t *testing.T) {
+	beforeEachUpdateList := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", listHandler.UpdateList)
+
+		mockServiceUpdate = mocker.NewMockCall(callServiceUpdate)
+		mockServiceUpdate.Return(nil)
+	}
+
+	t.Run("should update list of path id", func(t *testing.T) {
+		beforeEachUpdateList()
+		request = httptest.NewRequest(http.MethodPut, uri+"/3", bytes.NewBuffer([]byte(`{"id":9,"name":"promotions"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Update", entity.Lists{ID: 3, Name: "promotions"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response error when service update failed", func(t *testing.T) {
+		beforeEachUpdateList()
+		mockServiceUpdate.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		request = httptest.NewRequest(http.MethodPut, uri+"/3", bytes.NewBuffer([]byte(`{"name":"promotions"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_DeleteList(t *testing.T) {
+	beforeEachDeleteList := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", listHandler.DeleteList)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/3", nil)
+
+		mockServiceDelete = mocker.NewMockCall(callServiceDelete)
+		mockServiceDelete.Return(nil)
+	}
+
+	t.Run("should response success when list was deleted", func(t *testing.T) {
+		beforeEachDeleteList()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Delete", int64(3))
+		assert.Equal(t, "delete list success", body.Body)
+	})
+}
+
+func TestHandler_Subscribe(t *testing.T) {
+	beforeEachSubscribe := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/subscribe", listHandler.Subscribe)
+
+		mockServiceSubscribe = mocker.NewMockCall(callServiceSubscribe)
+		mockServiceSubscribe.Return(nil)
+		mockVerifierVerify = mocker.NewMockCall(callVerifierVerify)
+		mockVerifierVerify.Return(nil)
+		mockFieldsValidateValues = mocker.NewMockCall(callFieldsValidateValues)
+		mockFieldsValidateValues.Return(nil, nil, nil)
+	}
+	subscribeRequest := func(body string) *http.Request {
+		return httptest.NewRequest(http.MethodPost, uri+"/3/subscribe", bytes.NewBuffer([]byte(body)))
+	}
+
+	t.Run("should call service subscribe with list id and normalized subscriber", func(t *testing.T) {
+		beforeEachSubscribe()
+		request = subscribeRequest(`{"email":" AjisTestMail@Gmail.COM ","name":" ajis ","sourceApiKeyId":99}`)
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", int64(3), entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "ajis"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response validation failed when payload is invalid", func(t *testing.T) {
+		beforeEachSubscribe()
+		request = subscribeRequest(`{"email":"not an email","name":"ajis"}`)
+
+		router.ServeHTTP(recorder, request)
+
+		var body newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, newsletterError.ValidationFailed, body.Code)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should pass normalized custom fields to service", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockFieldsValidateValues.Return(map[string]interface{}{"plan": "pro"}, []newsletterError.FieldError{}, nil)
+		request = subscribeRequest(`{"email":"ajistestmail@gmail.com","name":"ajis","fields":{"plan":" pro "}}`)
+
+		router.ServeHTTP(recorder, request)
+
+		fields.AssertCalled(t, "ValidateValues", map[string]interface{}{"plan": " pro "})
+		service.AssertCalled(t, "Subscribe", int64(3), entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "ajis", Fields: map[string]interface{}{"plan": "pro"}})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response validation failed when custom field is invalid", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockFieldsValidateValues.Return(map[string]interface{}{}, []newsletterError.FieldError{{Field: "fields.plan", Code: newsletterError.FieldInvalidOption}}, nil)
+		request = subscribeRequest(`{"email":"ajistestmail@gmail.com","name":"ajis","fields":{"plan":"gold"}}`)
+
+		router.ServeHTTP(recorder, request)
+
+		var body newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, newsletterError.ValidationFailed, body.Code)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should response verification failed when verifier rejects", func(t *testing.T) {
+		beforeEachSubscribe()
+		listHandler.Verifier = verifier
+		mockVerifierVerify.Return(convert.ValueToErrorCodePointer(newsletterError.VerificationFailed))
+		request = subscribeRequest(`{"email":"ajistestmail@gmail.com","name":"ajis","website":"spam"}`)
+
+		router.ServeHTTP(recorder, request)
+
+		verifier.AssertCalled(t, "Verify", verification.Submission{Website: "spam"})
+		assertResponseError(t, newsletterError.VerificationFailed)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should skip verification and record source when request has api key", func(t *testing.T) {
+		beforeEachSubscribe()
+		listHandler.Verifier = verifier
+		request = subscribeRequest(`{"email":"ajistestmail@gmail.com","name":"ajis"}`)
+		request = request.WithContext(context.WithValue(request.Context(), middleware.APIKeyContextKey, &entity.APIKeys{ID: 7}))
+
+		router.ServeHTTP(recorder, request)
+
+		verifier.AssertNotCalled(t, "Verify", mock.Anything)
+		service.AssertCalled(t, "Subscribe", int64(3), entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "ajis", SourceAPIKeyID: convert.ValueToInt64Pointer(7)})
+	})
+
+	t.Run("should response data not found when list is missing", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockServiceSubscribe.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		request = subscribeRequest(`{"email":"ajistestmail@gmail.com","name":"ajis"}`)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_Confirm(t *testing.T) {
+	beforeEachConfirm := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/confirm", listHandler.Confirm)
+
+		mockServiceConfirm = mocker.NewMockCall(callServiceConfirm)
+		mockServiceConfirm.Return(nil)
+	}
+
+	t.Run("should call service confirm with token", func(t *testing.T) {
+		beforeEachConfirm()
+		request = httptest.NewRequest(http.MethodGet, uri+"/confirm?token=token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Confirm", "token")
+		assert.Equal(t, "confirm success", body.Body)
+	})
+
+	t.Run("should response empty token when token is missing", func(t *testing.T) {
+		beforeEachConfirm()
+		request = httptest.NewRequest(http.MethodGet, uri+"/confirm", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.EmptyToken)
+		service.AssertNotCalled(t, "Confirm", mock.Anything)
+	})
+
+	t.Run("should response expired token when token expired", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirm.Return(convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+		request = httptest.NewRequest(http.MethodGet, uri+"/confirm?token=token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.ExpiredToken)
+	})
+}
+
+func TestHandler_UnsubscribeByToken(t *testing.T) {
+	beforeEachUnsubscribe := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}/unsubscribe/{token}", listHandler.UnsubscribeByToken)
+
+		mockServiceUnsubscribe = mocker.NewMockCall(callServiceUnsubscribe)
+		mockServiceUnsubscribe.Return(nil)
+	}
+
+	t.Run("should call service unsubscribe with list id and token", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		request = httptest.NewRequest(http.MethodPost, uri+"/3/unsubscribe/token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "UnsubscribeByToken", int64(3), "token")
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response invalid token when token does not verify", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		mockServiceUnsubscribe.Return(convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+		request = httptest.NewRequest(http.MethodPost, uri+"/3/unsubscribe/token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.InvalidToken)
+	})
+
+	t.Run("should response bad request when list id is invalid", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		request = httptest.NewRequest(http.MethodPost, uri+"/abc/unsubscribe/token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "UnsubscribeByToken", mock.Anything, mock.Anything)
+	})
+}
+
+func TestHandler_UnsubscribeAllByToken(t *testing.T) {
+	beforeEachUnsubscribeAll := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/unsubscribe-all/{token}", listHandler.UnsubscribeAllByToken)
+
+		mockServiceUnsubscribeAll = mocker.NewMockCall(callServiceUnsubscribeAll)
+		mockServiceUnsubscribeAll.Return(nil)
+	}
+
+	t.Run("should call service unsubscribe all with token", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+		request = httptest.NewRequest(http.MethodPost, uri+"/unsubscribe-all/token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "UnsubscribeAllByToken", "token")
+		assert.Equal(t, "unsubscribe all success", body.Body)
+	})
+
+	t.Run("should response expired token when token expired", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+		mockServiceUnsubscribeAll.Return(convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+		request = httptest.NewRequest(http.MethodPost, uri+"/unsubscribe-all/token", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.ExpiredToken)
+	})
+}
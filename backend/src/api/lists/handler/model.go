@@ -0,0 +1,27 @@
+package handler
+
+import (
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/lists"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/verification"
+)
+
+type HandlerParam struct {
+	Service  lists.UseCase
+	Fields   customfields.UseCase
+	Verifier verification.Verifier
+	Logs     logger.Logger
+}
+
+// SubscribeRequest is the list subscribe payload, with the same verification
+// fields as the newsletter subscribe form.
+type SubscribeRequest struct {
+	entity.Subscribers
+	verification.Submission
+}
+
+type ResponseSucess struct {
+	Body string `json:"body"`
+}
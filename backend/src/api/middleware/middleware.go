@@ -1,12 +1,17 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"net/http"
 	"newsletter/src/api/requestheader"
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/entity"
+	"strings"
 
-	// "newsletter/src/pkg/auth"
 	newslettererror "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/i18n"
 	"newsletter/src/pkg/utils/logger"
 	"newsletter/src/pkg/utils/recover"
 )
@@ -15,20 +20,25 @@ type contextKey string
 
 const (
 	JWTClaimsContextKey contextKey = "JWTClaims"
+	LanguageContextKey  contextKey = "Language"
+	APIKeyContextKey    contextKey = "APIKey"
 )
 
 type Middleware struct {
-	// AuthService auth.UseCase
-	Logs logger.Logger
+	AuthService   auth.UseCase
+	APIKeyService apikeys.UseCase
+	Logs          logger.Logger
 }
 
 func NewMiddleware(
-	// authService auth.UseCase,
+	authService auth.UseCase,
+	apiKeyService apikeys.UseCase,
 	logs logger.Logger,
 ) *Middleware {
 	return &Middleware{
-		// AuthService: authService,
-		Logs: logs,
+		AuthService:   authService,
+		APIKeyService: apiKeyService,
+		Logs:          logs,
 	}
 }
 
@@ -37,10 +47,170 @@ func (m Middleware) Recover(next http.Handler) http.Handler {
 		defer func() {
 			if recover.Recover(m.Logs) {
 				w.Header().Set(requestheader.ContentType, requestheader.ApplicationJson)
-				w.WriteHeader(http.StatusInternalServerError)
-				json.NewEncoder(w).Encode(newslettererror.NewError(newslettererror.InternalServerError, newslettererror.InternalServerErrorMessage))
+				statusCode, errMsg := newslettererror.MapMessageError(newslettererror.InternalServerError, LanguageFromContext(r.Context()))
+				w.WriteHeader(statusCode)
+				json.NewEncoder(w).Encode(&errMsg)
 			}
 		}()
 		next.ServeHTTP(w, r)
 	})
 }
+
+// Language negotiates the response language from the Accept-Language header
+// among the built-in and loaded catalogs, falling back to English, and stores
+// it in the request context.
+func (m Middleware) Language(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language := i18n.Negotiate(r.Header.Get(requestheader.AcceptLanguage), newslettererror.Languages(), newslettererror.EN)
+		w.Header().Set(requestheader.ContentLanguage, language)
+		w.Header().Add(requestheader.Vary, requestheader.AcceptLanguage)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), LanguageContextKey, language)))
+	})
+}
+
+// LanguageFromContext returns the language chosen by the Language middleware,
+// or English when the request did not pass through it.
+func LanguageFromContext(ctx context.Context) string {
+	if language, ok := ctx.Value(LanguageContextKey).(string); ok && language != "" {
+		return language
+	}
+	return newslettererror.EN
+}
+
+// Authenticate rejects requests without a valid bearer token in the
+// Authorization header and stores the token claims in the request context.
+// A request sending X-API-Key is authenticated by that key instead.
+func (m Middleware) Authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(requestheader.XAPIKey) != "" {
+			m.authenticateAPIKey(w, r, "", next)
+			return
+		}
+
+		authorization := r.Header.Get(requestheader.Authorization)
+		if len(authorization) <= len(requestheader.Bearer) || !strings.EqualFold(authorization[:len(requestheader.Bearer)], requestheader.Bearer) {
+			go m.Logs.Error(r.URL.Path, "middleware_Authenticate_MissingBearer", nil, nil)
+			m.unauthorized(w, r)
+			return
+		}
+
+		claims, err := m.AuthService.VerifyToken(authorization[len(requestheader.Bearer):])
+		if err != nil {
+			go m.Logs.Error(r.URL.Path, "middleware_Authenticate_InvalidToken", nil, err)
+			m.unauthorized(w, r)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), JWTClaimsContextKey, claims)))
+	})
+}
+
+// APIKey returns a middleware for public endpoints that backends may also
+// call with a key. A request without X-API-Key passes through anonymously,
+// while one with a key is rejected unless the key is active and was issued
+// with permission.
+func (m Middleware) APIKey(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get(requestheader.XAPIKey) == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			m.authenticateAPIKey(w, r, permission, next)
+		})
+	}
+}
+
+// authenticateAPIKey stores the key sent in X-API-Key in the request context.
+// When permission is set the key must also hold it as a scope.
+func (m Middleware) authenticateAPIKey(w http.ResponseWriter, r *http.Request, permission auth.Permission, next http.Handler) {
+	apiKey, err := m.APIKeyService.Authenticate(r.Header.Get(requestheader.XAPIKey))
+	if err != nil {
+		go m.Logs.Error(r.URL.Path, "middleware_APIKey_"+string(*err), nil, err)
+		if *err == newslettererror.Unauthorized {
+			m.unauthorized(w, r)
+			return
+		}
+		m.responseError(w, r, *err)
+		return
+	}
+
+	if permission != "" && !apikeys.HasScope(*apiKey, permission) {
+		go m.Logs.Error(r.URL.Path, "middleware_APIKey_MissingScope", map[string]interface{}{
+			"apiKeyId":   apiKey.ID,
+			"permission": permission,
+		}, nil)
+		m.responseError(w, r, newslettererror.Forbidden)
+		return
+	}
+
+	next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), APIKeyContextKey, apiKey)))
+}
+
+// APIKeyFromContext returns the key stored by Authenticate or APIKey.
+func APIKeyFromContext(ctx context.Context) (*entity.APIKeys, bool) {
+	apiKey, ok := ctx.Value(APIKeyContextKey).(*entity.APIKeys)
+	return apiKey, ok
+}
+
+func (m Middleware) responseError(w http.ResponseWriter, r *http.Request, code newslettererror.ErrorCode) {
+	w.Header().Set(requestheader.ContentType, requestheader.ApplicationJson)
+	statusCode, errMsg := newslettererror.MapMessageError(code, LanguageFromContext(r.Context()))
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(&errMsg)
+}
+
+func (m Middleware) unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set(requestheader.ContentType, requestheader.ApplicationJson)
+	w.Header().Set(requestheader.WWWAuthenticate, strings.TrimSpace(requestheader.Bearer))
+	statusCode, errMsg := newslettererror.MapMessageError(newslettererror.Unauthorized, LanguageFromContext(r.Context()))
+	w.WriteHeader(statusCode)
+	json.NewEncoder(w).Encode(&errMsg)
+}
+
+// ClaimsFromContext returns the claims stored by Authenticate.
+func ClaimsFromContext(ctx context.Context) (*auth.Claims, bool) {
+	claims, ok := ctx.Value(JWTClaimsContextKey).(*auth.Claims)
+	return claims, ok
+}
+
+// RequirePermission returns a middleware that lets a request through only
+// when the authenticated user's role grants permission, or the API key it
+// was sent with holds permission as a scope. It must run after Authenticate,
+// and can be attached to a route or a whole subrouter.
+func (m Middleware) RequirePermission(permission auth.Permission) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if apiKey, ok := APIKeyFromContext(r.Context()); ok {
+				if !apikeys.HasScope(*apiKey, permission) {
+					go m.Logs.Error(r.URL.Path, "middleware_RequirePermission_MissingScope", map[string]interface{}{
+						"apiKeyId":   apiKey.ID,
+						"permission": permission,
+					}, nil)
+					m.responseError(w, r, newslettererror.Forbidden)
+					return
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			claims, ok := ClaimsFromContext(r.Context())
+			if !ok {
+				go m.Logs.Error(r.URL.Path, "middleware_RequirePermission_MissingClaims", permission, nil)
+				m.unauthorized(w, r)
+				return
+			}
+
+			if err := m.AuthService.Authorize(claims.Subject, permission); err != nil {
+				go m.Logs.Error(r.URL.Path, "middleware_RequirePermission_"+string(*err), map[string]interface{}{
+					"subject":    claims.Subject,
+					"permission": permission,
+				}, err)
+				m.responseError(w, r, *err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,419 @@
+package middleware_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/middleware"
+	apiKeysMocks "newsletter/src/pkg/apikeys/mocks"
+	"newsletter/src/pkg/auth"
+	authMocks "newsletter/src/pkg/auth/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/i18n"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/golang-jwt/jwt/v4"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	authService   *authMocks.UseCase
+	apiKeyService *apiKeysMocks.UseCase
+	logs          *loggerMocks.Logger
+	middleWare    *middleware.Middleware
+
+	mockAuthVerifyToken    *mocker.MockCall
+	mockAuthAuthorize      *mocker.MockCall
+	mockAPIKeyAuthenticate *mocker.MockCall
+)
+
+func callAuthVerifyToken() *mock.Call {
+	return authService.On("VerifyToken", mock.Anything)
+}
+
+func callAuthAuthorize() *mock.Call {
+	return authService.On("Authorize", mock.Anything, mock.Anything)
+}
+
+func callAPIKeyAuthenticate() *mock.Call {
+	return apiKeyService.On("Authenticate", mock.Anything)
+}
+
+func beforeEach() {
+	i18n.Reset()
+	authService = &authMocks.UseCase{}
+	apiKeyService = &apiKeysMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	middleWare = middleware.NewMiddleware(authService, apiKeyService, logs)
+}
+
+func TestMiddleware_NewMiddleware(t *testing.T) {
+	t.Run("should return struct middleware when call new middleware", func(t *testing.T) {
+		beforeEach()
+
+		expectedMiddleware := &middleware.Middleware{
+			AuthService:   authService,
+			APIKeyService: apiKeyService,
+			Logs:          logs,
+		}
+
+		assert.Equal(t, expectedMiddleware, middleWare)
+	})
+}
+
+func serveLanguage(acceptLanguage string) (string, *httptest.ResponseRecorder) {
+	language := ""
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		language = middleware.LanguageFromContext(r.Context())
+	})
+
+	request := httptest.NewRequest(http.MethodGet, "/subscribers", nil)
+	if acceptLanguage != "" {
+		request.Header.Set("Accept-Language", acceptLanguage)
+	}
+	response := httptest.NewRecorder()
+	middleWare.Language(next).ServeHTTP(response, request)
+
+	return language, response
+}
+
+func TestMiddleware_Language(t *testing.T) {
+	t.Cleanup(i18n.Reset)
+
+	t.Run("should store en in context when header is missing", func(t *testing.T) {
+		beforeEach()
+
+		language, response := serveLanguage("")
+
+		assert.Equal(t, "en", language)
+		assert.Equal(t, "en", response.Header().Get("Content-Language"))
+		assert.Equal(t, "accept-language", response.Header().Get("Vary"))
+	})
+
+	t.Run("should store th in context when th has highest quality", func(t *testing.T) {
+		beforeEach()
+
+		language, response := serveLanguage("en;q=0.5, th-TH;q=0.9")
+
+		assert.Equal(t, "th", language)
+		assert.Equal(t, "th", response.Header().Get("Content-Language"))
+	})
+
+	t.Run("should fall back to en when no language is supported", func(t *testing.T) {
+		beforeEach()
+
+		language, _ := serveLanguage("ja, fr;q=0.8")
+
+		assert.Equal(t, "en", language)
+	})
+
+	t.Run("should store language loaded from catalog", func(t *testing.T) {
+		beforeEach()
+		i18n.Register("ja", i18n.Catalog{})
+
+		language, _ := serveLanguage("ja, th;q=0.8")
+
+		assert.Equal(t, "ja", language)
+	})
+}
+
+func TestMiddleware_LanguageFromContext(t *testing.T) {
+	t.Run("should return en when context has no language", func(t *testing.T) {
+		assert.Equal(t, "en", middleware.LanguageFromContext(context.Background()))
+	})
+}
+
+func TestMiddleware_Authenticate(t *testing.T) {
+	var (
+		nextCalled bool
+		claims     *auth.Claims
+	)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		claims, _ = middleware.ClaimsFromContext(r.Context())
+	})
+
+	beforeEachAuthenticate := func() {
+		beforeEach()
+		nextCalled = false
+		claims = nil
+
+		mockAuthVerifyToken = mocker.NewMockCall(callAuthVerifyToken)
+		mockAuthVerifyToken.Return(&auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "admin"}}, nil)
+	}
+
+	serve := func(authorization string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/subscribers", nil)
+		if authorization != "" {
+			request.Header.Set("Authorization", authorization)
+		}
+		response := httptest.NewRecorder()
+		middleWare.Authenticate(next).ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("should call next with claims in context when bearer token is valid", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		response := serve("Bearer token")
+
+		authService.AssertCalled(t, "VerifyToken", "token")
+		assert.True(t, nextCalled)
+		assert.Equal(t, "admin", claims.Subject)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should accept bearer scheme case insensitive", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		serve("bearer token")
+
+		authService.AssertCalled(t, "VerifyToken", "token")
+		assert.True(t, nextCalled)
+	})
+
+	t.Run("should response unauthorized when authorization header is missing", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		response := serve("")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		assert.Equal(t, "Bearer", response.Header().Get("WWW-Authenticate"))
+		assert.JSONEq(t, `{"code":"UNAUTHORIZED","message":"Unauthorized","data":null}`, response.Body.String())
+		authService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+	})
+
+	t.Run("should response unauthorized when scheme is not bearer", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		response := serve("Basic YWRtaW46YWRtaW4=")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		authService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+	})
+
+	t.Run("should response unauthorized when token is invalid", func(t *testing.T) {
+		beforeEachAuthenticate()
+		mockAuthVerifyToken.Return(nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized))
+
+		response := serve("Bearer token")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("should authenticate by api key instead of bearer token when x-api-key is sent", func(t *testing.T) {
+		beforeEachAuthenticate()
+		var apiKey *entity.APIKeys
+		mockAPIKeyAuthenticate = mocker.NewMockCall(callAPIKeyAuthenticate)
+		mockAPIKeyAuthenticate.Return(&entity.APIKeys{ID: 7}, nil)
+		request := httptest.NewRequest(http.MethodGet, "/subscribers", nil)
+		request.Header.Set("X-API-Key", "nlk_key")
+		response := httptest.NewRecorder()
+
+		middleWare.Authenticate(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiKey, _ = middleware.APIKeyFromContext(r.Context())
+		})).ServeHTTP(response, request)
+
+		apiKeyService.AssertCalled(t, "Authenticate", "nlk_key")
+		authService.AssertNotCalled(t, "VerifyToken", mock.Anything)
+		assert.Equal(t, int64(7), apiKey.ID)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+func TestMiddleware_ClaimsFromContext(t *testing.T) {
+	t.Run("should return false when context has no claims", func(t *testing.T) {
+		claims, ok := middleware.ClaimsFromContext(context.Background())
+
+		assert.False(t, ok)
+		assert.Nil(t, claims)
+	})
+}
+
+func TestMiddleware_RequirePermission(t *testing.T) {
+	var nextCalled bool
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+	})
+
+	beforeEachRequirePermission := func() {
+		beforeEach()
+		nextCalled = false
+
+		mockAuthAuthorize = mocker.NewMockCall(callAuthAuthorize)
+		mockAuthAuthorize.Return(nil)
+	}
+
+	serve := func(claims *auth.Claims) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/campaigns/1/send", nil)
+		if claims != nil {
+			request = request.WithContext(context.WithValue(request.Context(), middleware.JWTClaimsContextKey, claims))
+		}
+		response := httptest.NewRecorder()
+		middleWare.RequirePermission(auth.PermissionCampaignsSend)(next).ServeHTTP(response, request)
+		return response
+	}
+	editor := &auth.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "editor"}}
+
+	t.Run("should call next when user has permission", func(t *testing.T) {
+		beforeEachRequirePermission()
+
+		response := serve(editor)
+
+		authService.AssertCalled(t, "Authorize", "editor", auth.PermissionCampaignsSend)
+		assert.True(t, nextCalled)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should response forbidden when user lacks permission", func(t *testing.T) {
+		beforeEachRequirePermission()
+		mockAuthAuthorize.Return(convert.ValueToErrorCodePointer(newsletterError.Forbidden))
+
+		response := serve(editor)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.JSONEq(t, `{"code":"FORBIDDEN","message":"Forbidden","data":null}`, response.Body.String())
+	})
+
+	t.Run("should response internal server error when authorize failed", func(t *testing.T) {
+		beforeEachRequirePermission()
+		mockAuthAuthorize.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		response := serve(editor)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+
+	t.Run("should response unauthorized when request was not authenticated", func(t *testing.T) {
+		beforeEachRequirePermission()
+
+		response := serve(nil)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+		authService.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+	})
+
+	serveAPIKey := func(apiKey *entity.APIKeys) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/campaigns/1/send", nil)
+		request = request.WithContext(context.WithValue(request.Context(), middleware.APIKeyContextKey, apiKey))
+		response := httptest.NewRecorder()
+		middleWare.RequirePermission(auth.PermissionSubscribersRead)(next).ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("should call next when api key has scope", func(t *testing.T) {
+		beforeEachRequirePermission()
+
+		response := serveAPIKey(&entity.APIKeys{ID: 1, Scopes: "subscribers:subscribe subscribers:read"})
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, http.StatusOK, response.Code)
+		authService.AssertNotCalled(t, "Authorize", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should response forbidden when api key lacks scope", func(t *testing.T) {
+		beforeEachRequirePermission()
+
+		response := serveAPIKey(&entity.APIKeys{ID: 1, Scopes: "subscribers:subscribe"})
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+	})
+}
+
+func TestMiddleware_APIKey(t *testing.T) {
+	var (
+		nextCalled bool
+		apiKey     *entity.APIKeys
+	)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		apiKey, _ = middleware.APIKeyFromContext(r.Context())
+	})
+
+	beforeEachAPIKey := func() {
+		beforeEach()
+		nextCalled = false
+		apiKey = nil
+
+		mockAPIKeyAuthenticate = mocker.NewMockCall(callAPIKeyAuthenticate)
+		mockAPIKeyAuthenticate.Return(&entity.APIKeys{ID: 3, Scopes: "subscribers:subscribe"}, nil)
+	}
+
+	serve := func(key string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/subscribers/subscribe", nil)
+		if key != "" {
+			request.Header.Set("X-API-Key", key)
+		}
+		response := httptest.NewRecorder()
+		middleWare.APIKey(auth.PermissionSubscribersSubscribe)(next).ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("should call next anonymously when x-api-key is missing", func(t *testing.T) {
+		beforeEachAPIKey()
+
+		response := serve("")
+
+		assert.True(t, nextCalled)
+		assert.Nil(t, apiKey)
+		assert.Equal(t, http.StatusOK, response.Code)
+		apiKeyService.AssertNotCalled(t, "Authenticate", mock.Anything)
+	})
+
+	t.Run("should call next with api key in context when key has scope", func(t *testing.T) {
+		beforeEachAPIKey()
+
+		response := serve("nlk_key")
+
+		apiKeyService.AssertCalled(t, "Authenticate", "nlk_key")
+		assert.True(t, nextCalled)
+		assert.Equal(t, int64(3), apiKey.ID)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should response unauthorized when api key is invalid", func(t *testing.T) {
+		beforeEachAPIKey()
+		mockAPIKeyAuthenticate.Return(nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized))
+
+		response := serve("nlk_revoked")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+
+	t.Run("should response forbidden when api key lacks scope", func(t *testing.T) {
+		beforeEachAPIKey()
+		mockAPIKeyAuthenticate.Return(&entity.APIKeys{ID: 3, Scopes: "subscribers:read"}, nil)
+
+		response := serve("nlk_key")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusForbidden, response.Code)
+		assert.JSONEq(t, `{"code":"FORBIDDEN","message":"Forbidden","data":null}`, response.Body.String())
+	})
+
+	t.Run("should response internal server error when authenticate failed", func(t *testing.T) {
+		beforeEachAPIKey()
+		mockAPIKeyAuthenticate.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		response := serve("nlk_key")
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusInternalServerError, response.Code)
+	})
+}
@@ -0,0 +1,159 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"newsletter/src/api/requestheader"
+	"strconv"
+	"strings"
+	"time"
+
+	newslettererror "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/ratelimit"
+	"newsletter/src/pkg/utils/validation"
+
+	"github.com/gorilla/mux"
+)
+
+// maxRateLimitBody caps how much of the body is read to find the email. The
+// subscribe payloads are far smaller.
+const maxRateLimitBody = 1 << 20
+
+type RateLimitConfig struct {
+	ByIP    *ratelimit.Limiter
+	ByEmail *ratelimit.Limiter
+
+	// TrustForwardedFor takes the client IP from the last X-Forwarded-For
+	// entry, which is the one added by our own proxy. Only enable it behind a
+	// proxy, or clients can pick their own IP.
+	TrustForwardedFor bool
+
+	// Subject, when set, returns the email to limit by instead of the one in
+	// the JSON body, for requests that carry their email some other way. ""
+	// skips the email limit.
+	Subject func(r *http.Request) string
+}
+
+// RateLimit returns a middleware that limits requests per client IP and per
+// email in the JSON body, or the one config.Subject returns, responding 429
+// with Retry-After once either bucket
+// is empty. Requests made with an API key come from a partner server shared
+// by many users, so only their email is limited. A failing store lets the
+// request through rather than take the endpoint down.
+func (m Middleware) RateLimit(config RateLimitConfig) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if _, ok := APIKeyFromContext(r.Context()); !ok && config.ByIP != nil {
+				if !m.allow(w, r, config.ByIP, clientIP(r, config.TrustForwardedFor)) {
+					return
+				}
+			}
+
+			if config.ByEmail != nil {
+				email := ""
+				if config.Subject != nil {
+					email = config.Subject(r)
+				} else {
+					email, r.Body = readEmail(r)
+				}
+				if email != "" && !m.allow(w, r, config.ByEmail, email) {
+					return
+				}
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// allow takes a token for key and writes the 429 response when there is none.
+func (m Middleware) allow(w http.ResponseWriter, r *http.Request, limiter *ratelimit.Limiter, key string) bool {
+	allowed, retryAfter, err := limiter.Allow(key)
+	if err != nil {
+		go m.Logs.Error(r.URL.Path, "middleware_RateLimit_Store", limiter.Prefix, newslettererror.NewError(newslettererror.TechnicalError, err.Error()))
+		return true
+	}
+
+	if allowed {
+		return true
+	}
+
+	go m.Logs.Error(r.URL.Path, "middleware_RateLimit_"+string(newslettererror.TooManyRequests), limiter.Prefix, nil)
+	w.Header().Set(requestheader.RetryAfter, strconv.Itoa(retryAfterSeconds(retryAfter)))
+	m.responseError(w, r, newslettererror.TooManyRequests)
+	return false
+}
+
+// retryAfterSeconds rounds up, as Retry-After only carries whole seconds and
+// retrying early would be rejected again.
+func retryAfterSeconds(retryAfter time.Duration) int {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// TokenSubject returns a RateLimitConfig.Subject for routes with a {token}
+// path variable, such as unsubscribe links, that limits by the normalized
+// email verify finds in the token. Invalid tokens are only limited by IP.
+func TokenSubject(verify func(token string) (string, *newslettererror.ErrorCode)) func(r *http.Request) string {
+	return func(r *http.Request) string {
+		subject, errToken := verify(mux.Vars(r)["token"])
+		if errToken != nil {
+			return ""
+		}
+
+		email, errEmail := validation.Email(subject)
+		if errEmail != nil {
+			return ""
+		}
+		return email
+	}
+}
+
+func clientIP(r *http.Request, trustForwardedFor bool) string {
+	if trustForwardedFor {
+		forwarded := strings.Split(r.Header.Get(requestheader.XForwardedFor), ",")
+		if last := strings.TrimSpace(forwarded[len(forwarded)-1]); last != "" {
+			return last
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// readEmail returns the normalized email of a JSON body, or "" when there is
+// none to limit by, along with a body the handler can still read in full.
+func readEmail(r *http.Request) (string, io.ReadCloser) {
+	if r.Body == nil {
+		return "", r.Body
+	}
+
+	content, err := io.ReadAll(io.LimitReader(r.Body, maxRateLimitBody))
+	body := io.NopCloser(io.MultiReader(bytes.NewReader(content), r.Body))
+	if err != nil {
+		return "", body
+	}
+
+	var payload struct {
+		Email string `json:"email"`
+	}
+	if json.Unmarshal(content, &payload) != nil {
+		return "", body
+	}
+
+	email, errEmail := validation.Email(payload.Email)
+	if errEmail != nil {
+		return "", body
+	}
+	return email, body
+}
@@ -0,0 +1,214 @@
+package middleware_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"newsletter/src/api/middleware"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newslettererror "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/ratelimit"
+	"newsletter/src/pkg/utils/ratelimit/mocks"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestMiddleware_RateLimit(t *testing.T) {
+	var (
+		nextCalled bool
+		nextBody   string
+		now        time.Time
+		config     middleware.RateLimitConfig
+	)
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		nextCalled = true
+		body, _ := io.ReadAll(r.Body)
+		nextBody = string(body)
+	})
+
+	beforeEachRateLimit := func() {
+		beforeEach()
+		nextCalled = false
+		nextBody = ""
+		now = time.Date(2022, 12, 15, 0, 0, 0, 0, time.UTC)
+
+		store := ratelimit.NewMemoryStore()
+		config = middleware.RateLimitConfig{
+			ByIP:    ratelimit.NewLimiter(store, "subscribe:ip", ratelimit.Rate{Requests: 2, Period: time.Minute}),
+			ByEmail: ratelimit.NewLimiter(store, "subscribe:email", ratelimit.Rate{Requests: 1, Period: time.Hour}),
+		}
+		config.ByIP.Now = func() time.Time { return now }
+		config.ByEmail.Now = func() time.Time { return now }
+	}
+
+	serve := func(remoteAddr string, body string, modify ...func(*http.Request) *http.Request) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/subscribers/subscribe", bytes.NewBufferString(body))
+		request.RemoteAddr = remoteAddr
+		for _, apply := range modify {
+			request = apply(request)
+		}
+		response := httptest.NewRecorder()
+		nextCalled = false
+		middleWare.RateLimit(config)(next).ServeHTTP(response, request)
+		return response
+	}
+
+	t.Run("should call next with untouched body when under limit", func(t *testing.T) {
+		beforeEachRateLimit()
+
+		response := serve("10.0.0.1:5000", `{"email":"test@gmail.com","name":"test"}`)
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, `{"email":"test@gmail.com","name":"test"}`, nextBody)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should response too many requests with retry after when ip is over limit", func(t *testing.T) {
+		beforeEachRateLimit()
+		serve("10.0.0.1:5000", `{"email":"a@gmail.com"}`)
+		serve("10.0.0.1:5001", `{"email":"b@gmail.com"}`)
+
+		response := serve("10.0.0.1:5002", `{"email":"c@gmail.com"}`)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		assert.Equal(t, "30", response.Header().Get("Retry-After"))
+		assert.JSONEq(t, `{"code":"TOO_MANY_REQUESTS","message":"Too many requests, please try again later","data":null}`, response.Body.String())
+	})
+
+	t.Run("should limit same email across ips ignoring case", func(t *testing.T) {
+		beforeEachRateLimit()
+		serve("10.0.0.1:5000", `{"email":"test@gmail.com"}`)
+
+		response := serve("10.0.0.2:5000", `{"email":" TEST@gmail.com "}`)
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+		assert.Equal(t, "3600", response.Header().Get("Retry-After"))
+	})
+
+	t.Run("should allow again once bucket refills", func(t *testing.T) {
+		beforeEachRateLimit()
+		serve("10.0.0.1:5000", `{"email":"test@gmail.com"}`)
+		serve("10.0.0.2:5000", `{"email":"test@gmail.com"}`)
+
+		now = now.Add(time.Hour)
+		serve("10.0.0.2:5000", `{"email":"test@gmail.com"}`)
+
+		assert.True(t, nextCalled)
+	})
+
+	t.Run("should only limit by ip when body has no valid email", func(t *testing.T) {
+		beforeEachRateLimit()
+		serve("10.0.0.1:5000", `not json`)
+
+		response := serve("10.0.0.2:5000", `not json`)
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, `not json`, nextBody)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should use last forwarded for entry when proxy is trusted", func(t *testing.T) {
+		beforeEachRateLimit()
+		config.TrustForwardedFor = true
+		forwardedFor := func(value string) func(*http.Request) *http.Request {
+			return func(r *http.Request) *http.Request {
+				r.Header.Set("X-Forwarded-For", value)
+				return r
+			}
+		}
+		serve("10.0.0.9:5000", ``, forwardedFor("1.1.1.1, 203.0.113.7"))
+		serve("10.0.0.9:5000", ``, forwardedFor("2.2.2.2, 203.0.113.7"))
+
+		response := serve("10.0.0.9:5000", ``, forwardedFor("198.51.100.1"))
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		response = serve("10.0.0.9:5000", ``, forwardedFor("3.3.3.3, 203.0.113.7"))
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+	})
+
+	t.Run("should not limit api key callers by ip", func(t *testing.T) {
+		beforeEachRateLimit()
+		withAPIKey := func(r *http.Request) *http.Request {
+			return r.WithContext(context.WithValue(r.Context(), middleware.APIKeyContextKey, &entity.APIKeys{ID: 1}))
+		}
+		serve("10.0.0.1:5000", `{"email":"a@gmail.com"}`, withAPIKey)
+		serve("10.0.0.1:5000", `{"email":"b@gmail.com"}`, withAPIKey)
+
+		response := serve("10.0.0.1:5000", `{"email":"c@gmail.com"}`, withAPIKey)
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should limit by subject instead of body when subject is set", func(t *testing.T) {
+		beforeEachRateLimit()
+		config.Subject = func(r *http.Request) string { return r.URL.Query().Get("subject") }
+		withSubject := func(subject string) func(*http.Request) *http.Request {
+			return func(r *http.Request) *http.Request {
+				r.URL.RawQuery = "subject=" + subject
+				return r
+			}
+		}
+		serve("10.0.0.1:5000", `{"email":"other@gmail.com"}`, withSubject("test@gmail.com"))
+
+		response := serve("10.0.0.2:5000", `{"email":"other@gmail.com"}`, withSubject("test@gmail.com"))
+
+		assert.False(t, nextCalled)
+		assert.Equal(t, http.StatusTooManyRequests, response.Code)
+
+		response = serve("10.0.0.3:5000", `{"email":"other@gmail.com"}`, withSubject(""))
+		assert.True(t, nextCalled)
+		assert.Equal(t, `{"email":"other@gmail.com"}`, nextBody)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+
+	t.Run("should let request through when store failed", func(t *testing.T) {
+		beforeEachRateLimit()
+		store := &mocks.Store{}
+		store.On("Take", mock.Anything, mock.Anything, mock.Anything).Return(false, time.Duration(0), errors.New("Error"))
+		config.ByIP.Store = store
+		config.ByEmail.Store = store
+
+		response := serve("10.0.0.1:5000", `{"email":"test@gmail.com"}`)
+
+		assert.True(t, nextCalled)
+		assert.Equal(t, http.StatusOK, response.Code)
+	})
+}
+
+func TestMiddleware_TokenSubject(t *testing.T) {
+	verify := func(token string) (string, *newslettererror.ErrorCode) {
+		if token != "valid" {
+			return "", convert.ValueToErrorCodePointer(newslettererror.InvalidToken)
+		}
+		return " Test@Gmail.com ", nil
+	}
+	request := func(token string) *http.Request {
+		r := httptest.NewRequest(http.MethodPost, "/subscribers/unsubscribe/"+token, nil)
+		return mux.SetURLVars(r, map[string]string{"token": token})
+	}
+
+	t.Run("should return normalized email of token in path", func(t *testing.T) {
+		subject := middleware.TokenSubject(verify)(request("valid"))
+
+		assert.Equal(t, "test@gmail.com", subject)
+	})
+
+	t.Run("should return empty subject when token is invalid", func(t *testing.T) {
+		subject := middleware.TokenSubject(verify)(request("forged"))
+
+		assert.Equal(t, "", subject)
+	})
+}
@@ -1,11 +1,22 @@
 package requestheader
 
 const (
-	ContentType     string = "Content-Type"
-	ApplicationJson string = "application/json"
-	AcceptLanguage  string = "accept-language"
-	TextEventStream string = "text/event-stream"
-	TheTimeZoneIana string = "The-Timezone-IANA"
-	Authorization   string = "Authorization"
-	Bearer          string = "Bearer "
+	ContentType        string = "Content-Type"
+	ApplicationJson    string = "application/json"
+	TextHtml           string = "text/html; charset=utf-8"
+	TextCsv            string = "text/csv; charset=utf-8"
+	AcceptLanguage     string = "accept-language"
+	ContentLanguage    string = "Content-Language"
+	Vary               string = "Vary"
+	ContentDisposition string = "Content-Disposition"
+	TextEventStream    string = "text/event-stream"
+	TheTimeZoneIana    string = "The-Timezone-IANA"
+	Authorization      string = "Authorization"
+	WWWAuthenticate    string = "WWW-Authenticate"
+	Bearer             string = "Bearer "
+	XAPIKey            string = "X-API-Key"
+	XForwardedFor      string = "X-Forwarded-For"
+	RetryAfter         string = "Retry-After"
+	CacheControl       string = "Cache-Control"
+	NoStore            string = "no-store"
 )
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"newsletter/src/api/middleware"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/segments"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+type SegmentsHandler struct {
+	Service segments.UseCase
+	Logs    logger.Logger
+}
+
+func MakeSegmentsHandler(handlerParam HandlerParam) *SegmentsHandler {
+	return &SegmentsHandler{
+		Service: handlerParam.Service,
+		Logs:    handlerParam.Logs,
+	}
+}
+
+func (handler *SegmentsHandler) GetAllSegments(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	res, err := handler.Service.GetAllSegments()
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_getAllSegments", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *SegmentsHandler) GetSegment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "segments_handler_getSegment", newsletterError.BadRequest)
+		return
+	}
+
+	res, err := handler.Service.FindByID(id)
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_getSegment", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *SegmentsHandler) CreateSegment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.Segments
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "segments_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	if !handler.validateRules(response, request, "segments_handler_createSegment", body.Rules) {
+		return
+	}
+
+	res, err := handler.Service.Create(body)
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_createSegment", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusCreated)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *SegmentsHandler) UpdateSegment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "segments_handler_updateSegment", newsletterError.BadRequest)
+		return
+	}
+
+	var body entity.Segments
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "segments_handler_decode", newsletterError.BadRequest)
+		return
+	}
+	body.ID = id
+
+	if !handler.validateRules(response, request, "segments_handler_updateSegment", body.Rules) {
+		return
+	}
+
+	err := handler.Service.Update(body)
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_updateSegment", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "update segment success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *SegmentsHandler) DeleteSegment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		handler.responseError(response, request, "segments_handler_deleteSegment", newsletterError.BadRequest)
+		return
+	}
+
+	err := handler.Service.Delete(id)
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_deleteSegment", *err)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete segment success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// PreviewSegment shows who the rules in the body match, so a segment can be
+// checked before it is saved.
+func (handler *SegmentsHandler) PreviewSegment(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	var body entity.Segments
+	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	if errorBody != nil {
+		handler.responseError(response, request, "segments_handler_decode", newsletterError.BadRequest)
+		return
+	}
+
+	if !handler.validateRules(response, request, "segments_handler_previewSegment", body.Rules) {
+		return
+	}
+
+	res, err := handler.Service.Preview(body.Rules)
+	if err != nil {
+		handler.responseError(response, request, "segments_handler_previewSegment", *err)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+// validateRules responds with what is wrong with rule and reports whether
+// the request can go on.
+func (handler *SegmentsHandler) validateRules(response http.ResponseWriter, request *http.Request, actionName string, rule entity.SegmentRule) bool {
+	fieldErrors, err := handler.Service.ValidateRules(rule)
+	if err != nil {
+		handler.responseError(response, request, actionName, *err)
+		return false
+	}
+
+	if len(fieldErrors) > 0 {
+		handler.responseValidationError(response, request, actionName, fieldErrors)
+		return false
+	}
+
+	return true
+}
+
+func (handler *SegmentsHandler) responseError(response http.ResponseWriter, request *http.Request, actionName string, err newsletterError.ErrorCode) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(err), nil, err)
+	statusCode, errMsg := newsletterError.MapMessageError(err, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
+
+func (handler *SegmentsHandler) responseValidationError(response http.ResponseWriter, request *http.Request, actionName string, fieldErrors []newsletterError.FieldError) {
+	go handler.Logs.Error(request.URL.Path, actionName+"_"+string(newsletterError.ValidationFailed), nil, fieldErrors)
+	statusCode, errMsg := newsletterError.MapValidationError(fieldErrors, middleware.LanguageFromContext(request.Context()))
+	response.WriteHeader(statusCode)
+	json.NewEncoder(response).Encode(&errMsg)
+}
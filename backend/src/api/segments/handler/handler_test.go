@@ -0,0 +1,311 @@
+package handler_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	requestHeader "newsletter/src/api/requestheader"
+	"newsletter/src/api/segments/handler"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/segments"
+	"newsletter/src/pkg/segments/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	uri            string
+	service        *mocks.UseCase
+	segmentHandler *handler.SegmentsHandler
+	logs           *loggerMocks.Logger
+	recorder       *httptest.ResponseRecorder
+	request        *http.Request
+	router         *mux.Router
+
+	mockServiceGetAllSegments *mocker.MockCall
+	mockServiceFindByID       *mocker.MockCall
+	mockServiceCreate         *mocker.MockCall
+	mockServiceUpdate         *mocker.MockCall
+	mockServiceDelete         *mocker.MockCall
+	mockServiceValidateRules  *mocker.MockCall
+	mockServicePreview        *mocker.MockCall
+)
+
+var countryRule = entity.SegmentRule{Field: "fields.country", Op: entity.SegmentOpEq, Value: "TH"}
+
+func callServiceGetAllSegments() *mock.Call {
+	return service.On("GetAllSegments")
+}
+
+func callServiceFindByID() *mock.Call {
+	return service.On("FindByID", mock.Anything)
+}
+
+func callServiceCreate() *mock.Call {
+	return service.On("Create", mock.Anything)
+}
+
+func callServiceUpdate() *mock.Call {
+	return service.On("Update", mock.Anything)
+}
+
+func callServiceDelete() *mock.Call {
+	return service.On("Delete", mock.Anything)
+}
+
+func callServiceValidateRules() *mock.Call {
+	return service.On("ValidateRules", mock.Anything)
+}
+
+func callServicePreview() *mock.Call {
+	return service.On("Preview", mock.Anything)
+}
+
+func beforeEach() {
+	uri = "/segments"
+	service = &mocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	segmentHandler = &handler.SegmentsHandler{
+		Service: service,
+		Logs:    logs,
+	}
+	router = mux.NewRouter()
+	recorder = httptest.NewRecorder()
+
+	mockServiceValidateRules = mocker.NewMockCall(callServiceValidateRules)
+	mockServiceValidateRules.Return(nil, nil)
+}
+
+func assertResponseError(t *testing.T, code newsletterError.ErrorCode) {
+	expectedStatusCode, expectedError := newsletterError.MapMessageError(code, "en")
+	var body newsletterError.Error
+	json.NewDecoder(recorder.Body).Decode(&body)
+	assert.Equal(t, expectedError, body)
+	assert.Equal(t, expectedStatusCode, recorder.Code)
+	assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+}
+
+func TestHandler_MakeSegmentsHandler(t *testing.T) {
+	t.Run("should return struct segments handler when call make segments handler", func(t *testing.T) {
+		beforeEach()
+		handlerParam := handler.HandlerParam{
+			Service: service,
+			Logs:    logs,
+		}
+
+		handlerMakeSegments := handler.MakeSegmentsHandler(handlerParam)
+
+		expectedResult := &handler.SegmentsHandler{
+			Service: handlerParam.Service,
+			Logs:    handlerParam.Logs,
+		}
+		assert.Equal(t, expectedResult, handlerMakeSegments)
+	})
+}
+
+func TestHandler_GetAllSegments(t *testing.T) {
+	beforeEachGetAllSegments := func() {
+		beforeEach()
+		router.HandleFunc(uri, segmentHandler.GetAllSegments)
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
+
+		mockServiceGetAllSegments = mocker.NewMockCall(callServiceGetAllSegments)
+		mockServiceGetAllSegments.Return([]entity.Segments{{ID: 3, Name: "thai readers", Rules: countryRule}}, nil)
+	}
+
+	t.Run("should response segments with rules when service get all segments success", func(t *testing.T) {
+		beforeEachGetAllSegments()
+
+		router.ServeHTTP(recorder, request)
+
+		var body []entity.Segments
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, []entity.Segments{{ID: 3, Name: "thai readers", Rules: countryRule}}, body)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response data not found when there is no segment", func(t *testing.T) {
+		beforeEachGetAllSegments()
+		mockServiceGetAllSegments.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.DataNotFound)
+	})
+}
+
+func TestHandler_GetSegment(t *testing.T) {
+	beforeEachGetSegment := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", segmentHandler.GetSegment)
+		request = httptest.NewRequest(http.MethodGet, uri+"/3", nil)
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Segments{ID: 3}, nil)
+	}
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachGetSegment()
+		request = httptest.NewRequest(http.MethodGet, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response segment when found", func(t *testing.T) {
+		beforeEachGetSegment()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "FindByID", int64(3))
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+}
+
+func TestHandler_CreateSegment(t *testing.T) {
+	beforeEachCreateSegment := func() {
+		beforeEach()
+		router.HandleFunc(uri, segmentHandler.CreateSegment)
+
+		mockServiceCreate = mocker.NewMockCall(callServiceCreate)
+		mockServiceCreate.Return(&entity.Segments{ID: 3, Name: "thai readers"}, nil)
+	}
+
+	t.Run("should response bad request when request body format is invalid", func(t *testing.T) {
+		beforeEachCreateSegment()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(``)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.BadRequest)
+	})
+
+	t.Run("should response created segment", func(t *testing.T) {
+		beforeEachCreateSegment()
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"name":"thai readers","rules":{"field":"fields.country","op":"eq","value":"TH"}}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "ValidateRules", countryRule)
+		service.AssertCalled(t, "Create", entity.Segments{Name: "thai readers", Rules: countryRule})
+		assert.Equal(t, http.StatusCreated, recorder.Code)
+	})
+
+	t.Run("should response validation failed with rule paths when rules are invalid", func(t *testing.T) {
+		beforeEachCreateSegment()
+		mockServiceValidateRules.Return([]newsletterError.FieldError{{Field: "rules.and.0.field", Code: newsletterError.FieldUnknown}}, nil)
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(`{"name":"thai readers","rules":{"and":[{"field":"fields.team","op":"isSet"}]}}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		var body struct {
+			Code newsletterError.ErrorCode    `json:"code"`
+			Data []newsletterError.FieldError `json:"data"`
+		}
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, newsletterError.ValidationFailed, body.Code)
+		assert.Equal(t, "rules.and.0.field", body.Data[0].Field)
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "Create", mock.Anything)
+	})
+}
+
+func TestHandler_UpdateSegment(t *testing.T) {
+	beforeEachUpdateSegment := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", segmentHandler.UpdateSegment)
+
+		mockServiceUpdate = mocker.NewMockCall(callServiceUpdate)
+		mockServiceUpdate.Return(nil)
+	}
+
+	t.Run("should update segment of path id", func(t *testing.T) {
+		beforeEachUpdateSegment()
+		request = httptest.NewRequest(http.MethodPut, uri+"/3", bytes.NewBuffer([]byte(`{"id":9,"name":"thai readers","rules":{"field":"fields.country","op":"eq","value":"TH"}}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Update", entity.Segments{ID: 3, Name: "thai readers", Rules: countryRule})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response error when validate rules failed", func(t *testing.T) {
+		beforeEachUpdateSegment()
+		mockServiceValidateRules.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		request = httptest.NewRequest(http.MethodPut, uri+"/3", bytes.NewBuffer([]byte(`{"name":"thai readers"}`)))
+
+		router.ServeHTTP(recorder, request)
+
+		assertResponseError(t, newsletterError.InternalServerError)
+		service.AssertNotCalled(t, "Update", mock.Anything)
+	})
+}
+
+func TestHandler_DeleteSegment(t *testing.T) {
+	beforeEachDeleteSegment := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/{id}", segmentHandler.DeleteSegment)
+		request = httptest.NewRequest(http.MethodDelete, uri+"/3", nil)
+
+		mockServiceDelete = mocker.NewMockCall(callServiceDelete)
+		mockServiceDelete.Return(nil)
+	}
+
+	t.Run("should response success when segment was deleted", func(t *testing.T) {
+		beforeEachDeleteSegment()
+
+		router.ServeHTTP(recorder, request)
+
+		var body handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Delete", int64(3))
+		assert.Equal(t, "delete segment success", body.Body)
+	})
+}
+
+func TestHandler_PreviewSegment(t *testing.T) {
+	beforeEachPreviewSegment := func() {
+		beforeEach()
+		router.HandleFunc(uri+"/preview", segmentHandler.PreviewSegment)
+		request = httptest.NewRequest(http.MethodPost, uri+"/preview", bytes.NewBuffer([]byte(`{"rules":{"field":"fields.country","op":"eq","value":"TH"}}`)))
+
+		mockServicePreview = mocker.NewMockCall(callServicePreview)
+		mockServicePreview.Return(&segments.Preview{Count: 42, Sample: []entity.Subscribers{{ID: 7}}}, nil)
+	}
+
+	t.Run("should response count and sample of matching subscribers", func(t *testing.T) {
+		beforeEachPreviewSegment()
+
+		router.ServeHTTP(recorder, request)
+
+		var body segments.Preview
+		json.NewDecoder(recorder.Body).Decode(&body)
+		service.AssertCalled(t, "Preview", countryRule)
+		assert.Equal(t, int64(42), body.Count)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response validation failed without preview when rules are invalid", func(t *testing.T) {
+		beforeEachPreviewSegment()
+		mockServiceValidateRules.Return([]newsletterError.FieldError{{Field: "rules", Code: newsletterError.FieldInvalidRule}}, nil)
+
+		router.ServeHTTP(recorder, request)
+
+		var body newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&body)
+		assert.Equal(t, newsletterError.ValidationFailed, body.Code)
+		service.AssertNotCalled(t, "Preview", mock.Anything)
+	})
+}
@@ -0,0 +1,15 @@
+package handler
+
+import (
+	"newsletter/src/pkg/segments"
+	"newsletter/src/pkg/utils/logger"
+)
+
+type HandlerParam struct {
+	Service segments.UseCase
+	Logs    logger.Logger
+}
+
+type ResponseSucess struct {
+	Body string `json:"body"`
+}
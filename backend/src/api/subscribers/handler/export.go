@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"encoding/csv"
+	"io"
+	"newsletter/src/pkg/entity"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const exportFileName = "subscribers.csv"
+
+var exportHeader = []string{"id", "email", "name", "isSubscribed", "isPending", "subscribedDate", "unsubscribedDate", "confirmedDate"}
+
+func writeSubscribersCSV(writer io.Writer, list []entity.Subscribers) error {
+	csvWriter := csv.NewWriter(writer)
+	if err := csvWriter.Write(exportHeader); err != nil {
+		return err
+	}
+
+	for _, subscriber := range list {
+		record := []string{
+			strconv.FormatInt(subscriber.ID, 10),
+			csvSafe(subscriber.Email),
+			csvSafe(subscriber.Name),
+			strconv.FormatBool(subscriber.IsSubscribed),
+			strconv.FormatBool(subscriber.IsPending),
+			formatExportDate(subscriber.SubscribedDate),
+			formatExportDate(subscriber.UnsubscribedDate),
+			formatExportDate(subscriber.ConfirmedDate),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// csvSafe stops a spreadsheet from running a value typed by a subscriber as
+// a formula.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsAny(value[:1], "=+-@\t\r") {
+		return "'" + value
+	}
+	return value
+}
+
+func formatExportDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+	return date.Format(time.RFC3339)
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"net/url"
+	subscribers "newsletter/src/pkg/subscribers"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateLayout = "2006-01-02"
+
+// parseFilter reads the list query string. Values are only checked for type
+// here; ranges and combinations are validated by the service.
+func parseFilter(query url.Values) (subscribers.Filter, error) {
+	filter := subscribers.Filter{
+		Email:     strings.TrimSpace(query.Get("email")),
+		Name:      strings.TrimSpace(query.Get("name")),
+		SortBy:    query.Get("sortBy"),
+		SortOrder: subscribers.SortOrder(strings.ToLower(query.Get("sortOrder"))),
+	}
+
+	var err error
+	if value := query.Get("page"); value != "" {
+		if filter.Page, err = strconv.Atoi(value); err != nil {
+			return filter, err
+		}
+	}
+
+	if value := query.Get("pageSize"); value != "" {
+		if filter.PageSize, err = strconv.Atoi(value); err != nil {
+			return filter, err
+		}
+	}
+
+	if value := query.Get("after"); value != "" {
+		after, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return filter, err
+		}
+		filter.After = &after
+	}
+
+	if value := query.Get("isSubscribed"); value != "" {
+		isSubscribed, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, err
+		}
+		filter.IsSubscribed = &isSubscribed
+	}
+
+	if value := query.Get("subscribedFrom"); value != "" {
+		if filter.SubscribedFrom, err = parseDate(value, false); err != nil {
+			return filter, err
+		}
+	}
+
+	if value := query.Get("subscribedTo"); value != "" {
+		if filter.SubscribedTo, err = parseDate(value, true); err != nil {
+			return filter, err
+		}
+	}
+
+	return filter, nil
+}
+
+// parseDate accepts RFC 3339 or a plain date. A plain date used as the end of
+// a range covers that whole day.
+func parseDate(value string, endOfDay bool) (*time.Time, error) {
+	if date, err := time.Parse(time.RFC3339, value); err == nil {
+		return &date, nil
+	}
+
+	date, err := time.Parse(dateLayout, value)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		date = date.Add(24*time.Hour - time.Nanosecond)
+	}
+	return &date, nil
+}
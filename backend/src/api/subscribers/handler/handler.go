@@ -3,22 +3,36 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"newsletter/src/api/middleware"
 	requestHeader "newsletter/src/api/requestheader"
-	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/customfields"
 	subscribers "newsletter/src/pkg/subscribers"
+	"newsletter/src/pkg/utils/convert"
 	newsletterError "newsletter/src/pkg/utils/error"
 	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/validation"
+	"newsletter/src/pkg/verification"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/thoas/go-funk"
 )
 
 type SubscribersHandler struct {
-	Service subscribers.UseCase
-	Logs    logger.Logger
+	Service    subscribers.UseCase
+	Fields     customfields.UseCase
+	Verifier   verification.Verifier
+	Challenges verification.Issuer
+	Logs       logger.Logger
 }
 
 func MakeSubscribersHandler(handlerParam HandlerParam) *SubscribersHandler {
 	return &SubscribersHandler{
-		Service: handlerParam.Service,
-		Logs:    handlerParam.Logs,
+		Service:    handlerParam.Service,
+		Fields:     handlerParam.Fields,
+		Verifier:   handlerParam.Verifier,
+		Challenges: handlerParam.Challenges,
+		Logs:       handlerParam.Logs,
 	}
 }
 
@@ -26,15 +40,24 @@ func (handler *SubscribersHandler) GetAllSubscribers(response http.ResponseWrite
 
 	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
 
-	res, err := handler.Service.GetAllSubscribers()
+	filter, errFilter := parseFilter(request.URL.Query())
+	if errFilter != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_getAllSubscribers_BadRequest", request.URL.RawQuery, errFilter)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	res, err := handler.Service.GetSubscribers(filter)
 	if err != nil {
 		switch *err {
-		case newsletterError.DataNotFound:
-			go handler.Logs.Error(request.URL.Path, "subscribers_handler_getAllSubscribers_DataNotFound", nil, err)
+		case newsletterError.BadRequest:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_getAllSubscribers_BadRequest", filter, err)
 		default:
 			go handler.Logs.Error(request.URL.Path, "subscribers_handler_getAllSubscribers_InternalServerError", nil, err)
 		}
-		statusCode, errMsg := newsletterError.MapMessageError(*err, "en")
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
 		response.WriteHeader(statusCode)
 		json.NewEncoder(response).Encode(&errMsg)
 		return
@@ -44,20 +67,137 @@ func (handler *SubscribersHandler) GetAllSubscribers(response http.ResponseWrite
 	json.NewEncoder(response).Encode(&res)
 }
 
+func (handler *SubscribersHandler) ExportSubscribers(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	filter, errFilter := parseFilter(request.URL.Query())
+	if errFilter != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_exportSubscribers_BadRequest", request.URL.RawQuery, errFilter)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	res, err := handler.Service.ExportSubscribers(filter)
+	if err != nil {
+		switch *err {
+		case newsletterError.BadRequest:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_exportSubscribers_BadRequest", filter, err)
+		default:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_exportSubscribers_InternalServerError", nil, err)
+		}
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.TextCsv)
+	response.Header().Set(requestHeader.ContentDisposition, `attachment; filename="`+exportFileName+`"`)
+	response.WriteHeader(http.StatusOK)
+	if errWrite := writeSubscribersCSV(response, res); errWrite != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_exportSubscribers_WriteFailed", nil, errWrite)
+	}
+}
+
+func (handler *SubscribersHandler) DeleteSubscriber(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	id, errID := strconv.ParseInt(mux.Vars(request)["id"], 10, 64)
+	if errID != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_deleteSubscriber_BadRequest", mux.Vars(request)["id"], errID)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	err := handler.Service.DeleteByID(id)
+	if err != nil {
+		switch *err {
+		case newsletterError.DataNotFound:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_deleteSubscriber_DataNotFound", id, err)
+		default:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_deleteSubscriber_InternalServerError", id, err)
+		}
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	res := ResponseSucess{
+		Body: "delete subscriber success",
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
 func (handler *SubscribersHandler) Subscribe(response http.ResponseWriter, request *http.Request) {
 
 	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
 
-	var body entity.Subscribers
-	errorBody := json.NewDecoder(request.Body).Decode(&body)
+	var subscribeRequest SubscribeRequest
+	errorBody := json.NewDecoder(request.Body).Decode(&subscribeRequest)
 	if errorBody != nil {
-		go handler.Logs.Error(request.URL.Path, "subscribers_handler_decode_BadRequest", body, errorBody)
-		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, "en")
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_decode_BadRequest", subscribeRequest.Subscribers, errorBody)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, middleware.LanguageFromContext(request.Context()))
 		response.WriteHeader(statusCode)
 		json.NewEncoder(response).Encode(&errMsg)
 		return
 	}
 
+	// Backends calling with an API key are trusted to have checked their own
+	// users, so only the public form is verified.
+	_, hasAPIKey := middleware.APIKeyFromContext(request.Context())
+	if handler.Verifier != nil && !hasAPIKey {
+		if errVerify := handler.Verifier.Verify(subscribeRequest.Submission); errVerify != nil {
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_subscribe_"+string(*errVerify), subscribeRequest.Subscribers, errVerify)
+			statusCode, errMsg := newsletterError.MapMessageError(*errVerify, middleware.LanguageFromContext(request.Context()))
+			response.WriteHeader(statusCode)
+			json.NewEncoder(response).Encode(&errMsg)
+			return
+		}
+	}
+
+	body, fieldErrors := subscribers.ValidateSubscribe(subscribeRequest.Subscribers)
+	fields, fieldValueErrors, errFields := handler.Fields.ValidateValues(body.Fields)
+	if errFields != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_subscribe_InternalServerError", nil, errFields)
+		statusCode, errMsg := newsletterError.MapMessageError(*errFields, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+	body.Fields = fields
+	fieldErrors = append(fieldErrors, fieldValueErrors...)
+	if len(fieldErrors) > 0 {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_subscribe_ValidationFailed", body, fieldErrors)
+		statusCode, errMsg := newsletterError.MapValidationError(fieldErrors, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	// Only the key the request was authenticated with is recorded as the
+	// source, never one named in the body.
+	body.SourceAPIKeyID = nil
+	if apiKey, ok := middleware.APIKeyFromContext(request.Context()); ok {
+		body.SourceAPIKeyID = &apiKey.ID
+	}
+
+	// The zone comes from the client's The-Timezone-IANA header. One that is
+	// not a known zone is dropped rather than failing the subscribe, since
+	// the subscriber cannot fix it on the form.
+	body.TimeZone = nil
+	if timeZone, errTimeZone := validation.TimeZone(request.Header.Get(requestHeader.TheTimeZoneIana)); errTimeZone == nil {
+		body.TimeZone = &timeZone
+	}
+
 	err := handler.Service.Subscribe(body)
 	if err != nil {
 		switch *err {
@@ -66,52 +206,233 @@ func (handler *SubscribersHandler) Subscribe(response http.ResponseWriter, reque
 		default:
 			go handler.Logs.Error(request.URL.Path, "subscribers_handler_subscribe_InternalServerError", nil, err)
 		}
-		statusCode, errMsg := newsletterError.MapMessageError(*err, "en")
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
 		response.WriteHeader(statusCode)
 		json.NewEncoder(response).Encode(&errMsg)
 		return
 	}
 
 	res := ResponseSucess{
-		Body: "subscribe success",
+		Body: "please check your email to confirm subscription",
 	}
 
 	response.WriteHeader(http.StatusOK)
 	json.NewEncoder(response).Encode(&res)
 }
 
-func (handler *SubscribersHandler) Unsubscribe(response http.ResponseWriter, request *http.Request) {
+// Challenge issues a proof-of-work challenge for the public subscribe form.
+// It responds DATA_NOT_FOUND when proof-of-work is not enabled.
+func (handler *SubscribersHandler) Challenge(response http.ResponseWriter, request *http.Request) {
 
 	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+	response.Header().Set(requestHeader.CacheControl, requestHeader.NoStore)
 
-	var body entity.Subscribers
-	errorBody := json.NewDecoder(request.Body).Decode(&body)
-	if errorBody != nil {
-		go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_decode_BadRequest", body, errorBody)
-		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.BadRequest, "en")
+	if handler.Challenges == nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_challenge_DataNotFound", nil, nil)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.DataNotFound, middleware.LanguageFromContext(request.Context()))
 		response.WriteHeader(statusCode)
 		json.NewEncoder(response).Encode(&errMsg)
 		return
 	}
 
-	err := handler.Service.Unsubscribe(body)
+	res, err := handler.Challenges.Issue()
+	if err != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_challenge_InternalServerError", nil, err)
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	response.WriteHeader(http.StatusOK)
+	json.NewEncoder(response).Encode(&res)
+}
+
+func (handler *SubscribersHandler) Confirm(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(requestHeader.ContentType, requestHeader.ApplicationJson)
+
+	confirmToken := request.URL.Query().Get("token")
+	if confirmToken == "" {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_confirm_EmptyToken", nil, nil)
+		statusCode, errMsg := newsletterError.MapMessageError(newsletterError.EmptyToken, middleware.LanguageFromContext(request.Context()))
+		response.WriteHeader(statusCode)
+		json.NewEncoder(response).Encode(&errMsg)
+		return
+	}
+
+	err := handler.Service.Confirm(confirmToken)
 	if err != nil {
 		switch *err {
 		case newsletterError.DataNotFound:
-			go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribe_DataNotFound", nil, err)
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_confirm_DataNotFound", nil, err)
+		case newsletterError.InvalidToken, newsletterError.ExpiredToken:
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_confirm_InvalidToken", nil, err)
 		default:
-			go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribe_InternalServerError", nil, err)
+			go handler.Logs.Error(request.URL.Path, "subscribers_handler_confirm_InternalServerError", nil, err)
 		}
-		statusCode, errMsg := newsletterError.MapMessageError(*err, "en")
+		statusCode, errMsg := newsletterError.MapMessageError(*err, middleware.LanguageFromContext(request.Context()))
 		response.WriteHeader(statusCode)
 		json.NewEncoder(response).Encode(&errMsg)
 		return
 	}
 
 	res := ResponseSucess{
-		Body: "unsubscribe success",
+		Body: "confirm success",
 	}
 
 	response.WriteHeader(http.StatusOK)
 	json.NewEncoder(response).Encode(&res)
 }
+
+func (handler *SubscribersHandler) UnsubscribePage(response http.ResponseWriter, request *http.Request) {
+
+	unsubscribeToken := mux.Vars(request)["token"]
+
+	language := middleware.LanguageFromContext(request.Context())
+
+	_, err := handler.Service.VerifyUnsubscribeToken(unsubscribeToken)
+	if err != nil {
+		go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribePage_InvalidToken", nil, err)
+		statusCode, errMsg := newsletterError.MapMessageError(*err, language)
+		renderPage(response, statusCode, PageContent{
+			Language: language,
+			Title:    pageText(language, UnsubscribeTitle),
+			Message:  errMsg.Message,
+		})
+		return
+	}
+
+	renderPage(response, http.StatusOK, PageContent{
+		Language:    language,
+		Title:       pageText(language, UnsubscribeTitle),
+		Message:     pageText(language, UnsubscribePrompt),
+		ActionURL:   request.URL.Path,
+		ActionLabel: pageText(language, UnsubscribeAction),
+	})
+}
+
+func (handler *SubscribersHandler) UnsubscribeByToken(response http.ResponseWriter, request *http.Request) {
+
+	unsubscribeToken := mux.Vars(request)["token"]
+	language := middleware.LanguageFromContext(request.Context())
+
+	err := handler.Service.UnsubscribeByToken(unsubscribeToken)
+	if err != nil {
+		switch *err {
+		case newsletterError.DataNotFound:
+			go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribeByToken_DataNotFound", nil, err)
+		case newsletterError.InvalidToken, newsletterError.ExpiredToken:
+			go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribeByToken_InvalidToken", nil, err)
+		default:
+			go handler.Logs.Error(request.URL.Path, "unsubscribers_handler_unsubscribeByToken_InternalServerError", nil, err)
+		}
+		statusCode, errMsg := newsletterError.MapMessageError(*err, language)
+		renderPage(response, statusCode, PageContent{
+			Language: language,
+			Title:    pageText(language, UnsubscribeTitle),
+			Message:  errMsg.Message,
+		})
+		return
+	}
+
+	renderPage(response, http.StatusOK, PageContent{
+		Language: language,
+		Title:    pageText(language, UnsubscribeTitle),
+		Message:  pageText(language, UnsubscribeSuccess),
+	})
+}
+
+func (handler *SubscribersHandler) PreferencesPage(response http.ResponseWriter, request *http.Request) {
+
+	preferencesToken := mux.Vars(request)["token"]
+	language := middleware.LanguageFromContext(request.Context())
+
+	preferences, err := handler.preferences(preferencesToken)
+	if err != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_preferencesPage", nil, err)
+		statusCode, errMsg := newsletterError.MapMessageError(*err, language)
+		renderPage(response, statusCode, PageContent{
+			Language: language,
+			Title:    pageText(language, PreferencesTitle),
+			Message:  errMsg.Message,
+		})
+		return
+	}
+
+	renderPreferencesPage(response, http.StatusOK, preferencesContent(language, request.URL.Path, *preferences))
+}
+
+func (handler *SubscribersHandler) UpdatePreferences(response http.ResponseWriter, request *http.Request) {
+
+	preferencesToken := mux.Vars(request)["token"]
+	language := middleware.LanguageFromContext(request.Context())
+
+	current, err := handler.preferences(preferencesToken)
+	if err == nil && request.ParseForm() != nil {
+		err = convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	if err != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_updatePreferences", nil, err)
+		statusCode, errMsg := newsletterError.MapMessageError(*err, language)
+		renderPage(response, statusCode, PageContent{
+			Language: language,
+			Title:    pageText(language, PreferencesTitle),
+			Message:  errMsg.Message,
+		})
+		return
+	}
+
+	preferences := subscribers.Preferences{
+		Email:     current.Email,
+		Name:      request.PostForm.Get("name"),
+		Language:  request.PostForm.Get("language"),
+		Frequency: request.PostForm.Get("frequency"),
+		TimeZone:  request.PostForm.Get("timeZone"),
+	}
+	chosen := request.PostForm["list"]
+	for _, list := range current.Lists {
+		list.IsSubscribed = funk.ContainsString(chosen, strconv.FormatInt(list.ID, 10))
+		preferences.Lists = append(preferences.Lists, list)
+	}
+
+	preferences, fieldErrors := subscribers.ValidatePreferences(preferences)
+	if len(fieldErrors) > 0 {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_updatePreferences_ValidationFailed", preferences, fieldErrors)
+		statusCode, errMsg := newsletterError.MapValidationError(fieldErrors, language)
+		content := preferencesContent(language, request.URL.Path, preferences)
+		content.Message = errMsg.Message
+		for _, fieldError := range errMsg.Data.([]newsletterError.FieldError) {
+			content.Errors = append(content.Errors, pageText(language, preferencesFieldLabels[fieldError.Field])+": "+fieldError.Message)
+		}
+		renderPreferencesPage(response, statusCode, content)
+		return
+	}
+
+	errUpdate := handler.Service.UpdatePreferences(preferences)
+	if errUpdate != nil {
+		go handler.Logs.Error(request.URL.Path, "subscribers_handler_updatePreferences_InternalServerError", preferences, errUpdate)
+		statusCode, errMsg := newsletterError.MapMessageError(*errUpdate, language)
+		renderPage(response, statusCode, PageContent{
+			Language: language,
+			Title:    pageText(language, PreferencesTitle),
+			Message:  errMsg.Message,
+		})
+		return
+	}
+
+	content := preferencesContent(language, request.URL.Path, preferences)
+	content.Message = pageText(language, PreferencesSuccess)
+	renderPreferencesPage(response, http.StatusOK, content)
+}
+
+// preferences loads the preferences of the subscriber a preferences token was
+// issued to.
+func (handler *SubscribersHandler) preferences(preferencesToken string) (*subscribers.Preferences, *newsletterError.ErrorCode) {
+	email, err := handler.Service.VerifyPreferencesToken(preferencesToken)
+	if err != nil {
+		return nil, err
+	}
+
+	return handler.Service.GetPreferences(email)
+}
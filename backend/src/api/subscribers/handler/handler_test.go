@@ -2,18 +2,27 @@ package handler_test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
+	"newsletter/src/api/middleware"
 	requestHeader "newsletter/src/api/requestheader"
 	"newsletter/src/api/subscribers/handler"
+	customFieldsMocks "newsletter/src/pkg/customfields/mocks"
 	"newsletter/src/pkg/entity"
+	subscribers "newsletter/src/pkg/subscribers"
 	"newsletter/src/pkg/subscribers/mocks"
 	"newsletter/src/pkg/utils/convert"
 	newsletterError "newsletter/src/pkg/utils/error"
 	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
 	"newsletter/src/pkg/utils/mocker"
+	"newsletter/src/pkg/verification"
+	verificationMocks "newsletter/src/pkg/verification/mocks"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/assert"
@@ -23,19 +32,57 @@ import (
 var (
 	uri               string
 	service           *mocks.UseCase
+	fields            *customFieldsMocks.UseCase
 	subscriberHandler *handler.SubscribersHandler
 	logs              *loggerMocks.Logger
 	recorder          *httptest.ResponseRecorder
 	request           *http.Request
 	router            *mux.Router
 
-	mockServiceGetAllSubscribers *mocker.MockCall
-	mockServiceSubscribe         *mocker.MockCall
-	mockServiceUnsubscribe       *mocker.MockCall
+	mockServiceGetSubscribers *mocker.MockCall
+	mockServiceSubscribe      *mocker.MockCall
+	mockServiceUnsubscribe    *mocker.MockCall
+	mockServiceConfirm        *mocker.MockCall
+	mockFieldsValidateValues  *mocker.MockCall
+
+	mockServiceVerifyUnsubscribeToken *mocker.MockCall
+	mockServiceUnsubscribeByToken     *mocker.MockCall
+	mockServiceExportSubscribers      *mocker.MockCall
+	mockServiceDeleteByID             *mocker.MockCall
+
+	mockServiceVerifyPreferencesToken *mocker.MockCall
+	mockServiceGetPreferences         *mocker.MockCall
+	mockServiceUpdatePreferences      *mocker.MockCall
+
+	verifier   *verificationMocks.Verifier
+	challenges *verificationMocks.Issuer
+
+	mockVerifierVerify  *mocker.MockCall
+	mockChallengesIssue *mocker.MockCall
 )
 
-func callServiceGetAllSubscribers() *mock.Call {
-	return service.On("GetAllSubscribers")
+func callVerifierVerify() *mock.Call {
+	return verifier.On("Verify", mock.Anything)
+}
+
+func callChallengesIssue() *mock.Call {
+	return challenges.On("Issue")
+}
+
+func callFieldsValidateValues() *mock.Call {
+	return fields.On("ValidateValues", mock.Anything)
+}
+
+func callServiceExportSubscribers() *mock.Call {
+	return service.On("ExportSubscribers", mock.Anything)
+}
+
+func callServiceDeleteByID() *mock.Call {
+	return service.On("DeleteByID", mock.Anything)
+}
+
+func callServiceGetSubscribers() *mock.Call {
+	return service.On("GetSubscribers", mock.Anything)
 }
 
 func callServiceSubscribe() *mock.Call {
@@ -46,15 +93,45 @@ func callServiceUnsubscribe() *mock.Call {
 	return service.On("Unsubscribe", mock.Anything)
 }
 
+func callServiceConfirm() *mock.Call {
+	return service.On("Confirm", mock.Anything)
+}
+
+func callServiceVerifyUnsubscribeToken() *mock.Call {
+	return service.On("VerifyUnsubscribeToken", mock.Anything)
+}
+
+func callServiceUnsubscribeByToken() *mock.Call {
+	return service.On("UnsubscribeByToken", mock.Anything)
+}
+
+func callServiceVerifyPreferencesToken() *mock.Call {
+	return service.On("VerifyPreferencesToken", mock.Anything)
+}
+
+func callServiceGetPreferences() *mock.Call {
+	return service.On("GetPreferences", mock.Anything)
+}
+
+func callServiceUpdatePreferences() *mock.Call {
+	return service.On("UpdatePreferences", mock.Anything)
+}
+
+func withLanguage(request *http.Request, language string) *http.Request {
+	return request.WithContext(context.WithValue(request.Context(), middleware.LanguageContextKey, language))
+}
+
 func beforeEach() {
 	uri = "/subscribers"
 	service = &mocks.UseCase{}
+	fields = &customFieldsMocks.UseCase{}
 	logs = &loggerMocks.Logger{}
 
 	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 
 	subscriberHandler = &handler.SubscribersHandler{
 		Service: service,
+		Fields:  fields,
 		Logs:    logs,
 	}
 }
@@ -64,15 +141,21 @@ func TestHandler_MakeSubscribersHandler(t *testing.T) {
 	t.Run("should return struct subscribers handler when call make subscribers handler", func(t *testing.T) {
 		beforeEach()
 		handlerParam := handler.HandlerParam{
-			Service: service,
-			Logs:    logs,
+			Service:    service,
+			Fields:     fields,
+			Verifier:   &verificationMocks.Verifier{},
+			Challenges: &verificationMocks.Issuer{},
+			Logs:       logs,
 		}
 
 		handlerMakeSubscribers := handler.MakeSubscribersHandler(handlerParam)
 
 		expectedResult := &handler.SubscribersHandler{
-			Service: handlerParam.Service,
-			Logs:    handlerParam.Logs,
+			Service:    handlerParam.Service,
+			Fields:     handlerParam.Fields,
+			Verifier:   handlerParam.Verifier,
+			Challenges: handlerParam.Challenges,
+			Logs:       handlerParam.Logs,
 		}
 		assert.Equal(t, expectedResult, handlerMakeSubscribers)
 	})
@@ -86,42 +169,90 @@ func TestHandler_GetAllSubscribers(t *testing.T) {
 		recorder = httptest.NewRecorder()
 		request = httptest.NewRequest(http.MethodGet, uri, nil)
 
-		mockServiceGetAllSubscribers = mocker.NewMockCall(callServiceGetAllSubscribers)
-		mockServiceGetAllSubscribers.Return(nil, nil)
+		mockServiceGetSubscribers = mocker.NewMockCall(callServiceGetSubscribers)
+		mockServiceGetSubscribers.Return(&subscribers.Page{Items: []entity.Subscribers{}}, nil)
 	}
 
-	t.Run("should call service search when request get all subscribers", func(t *testing.T) {
+	t.Run("should call service with empty filter when request has no query", func(t *testing.T) {
 		beforeEachGetAllSubscribers()
-		request = httptest.NewRequest(http.MethodGet, uri, nil)
 
 		router.ServeHTTP(recorder, request)
 
-		service.AssertCalled(t, "GetAllSubscribers")
+		service.AssertCalled(t, "GetSubscribers", subscribers.Filter{})
 		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
 	})
 
-	t.Run("should response internal server error when service get all subscribers failed", func(t *testing.T) {
+	t.Run("should pass paging filters and sort from query to service", func(t *testing.T) {
 		beforeEachGetAllSubscribers()
-		err := newsletterError.InternalServerError
-		mockServiceGetAllSubscribers.Return([]entity.Subscribers{}, &err)
+		request = httptest.NewRequest(http.MethodGet, uri+"?page=2&pageSize=50&isSubscribed=false&email=%20gmail%20&name=ajis&subscribedFrom=2023-01-01&subscribedTo=2023-01-31&sortBy=name&sortOrder=DESC", nil)
 
 		router.ServeHTTP(recorder, request)
 
-		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InternalServerError, "en")
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 1, 31, 23, 59, 59, 999999999, time.UTC)
+		service.AssertCalled(t, "GetSubscribers", subscribers.Filter{
+			Page:           2,
+			PageSize:       50,
+			IsSubscribed:   convert.ValueToBoolPointer(false),
+			Email:          "gmail",
+			Name:           "ajis",
+			SubscribedFrom: &from,
+			SubscribedTo:   &to,
+			SortBy:         "name",
+			SortOrder:      subscribers.SortDesc,
+		})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should pass cursor from query to service", func(t *testing.T) {
+		beforeEachGetAllSubscribers()
+		request = httptest.NewRequest(http.MethodGet, uri+"?after=40&subscribedFrom=2023-01-01T07:00:00%2B07:00", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "GetSubscribers", mock.MatchedBy(func(filter subscribers.Filter) bool {
+			return *filter.After == 40 &&
+				filter.SubscribedFrom.Equal(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		}))
+	})
+
+	for _, query := range []string{"page=abc", "pageSize=1.5", "after=abc", "isSubscribed=maybe", "subscribedFrom=yesterday", "subscribedTo=2023-13-01"} {
+		query := query
+		t.Run("should response bad request when query is invalid "+query, func(t *testing.T) {
+			beforeEachGetAllSubscribers()
+			request = httptest.NewRequest(http.MethodGet, uri+"?"+query, nil)
+
+			router.ServeHTTP(recorder, request)
+
+			expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.BadRequest, "en")
+			var body newsletterError.Error
+			json.NewDecoder(recorder.Body).Decode(&body)
+			assert.Equal(t, expectedError, body)
+			assert.Equal(t, expectedStatusCode, recorder.Code)
+			service.AssertNotCalled(t, "GetSubscribers", mock.Anything)
+		})
+	}
+
+	t.Run("should response bad request when service rejects filter", func(t *testing.T) {
+		beforeEachGetAllSubscribers()
+		mockServiceGetSubscribers.Return(nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.BadRequest, "en")
 		var body newsletterError.Error
 		json.NewDecoder(recorder.Body).Decode(&body)
 		assert.Equal(t, expectedError, body)
 		assert.Equal(t, expectedStatusCode, recorder.Code)
-		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
 	})
 
-	t.Run("should response data not found error when service get all subscribers failed", func(t *testing.T) {
+	t.Run("should response internal server error when service get subscribers failed", func(t *testing.T) {
 		beforeEachGetAllSubscribers()
-		mockServiceGetAllSubscribers.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		mockServiceGetSubscribers.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
 
 		router.ServeHTTP(recorder, request)
 
-		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.DataNotFound, "en")
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InternalServerError, "en")
 		var body newsletterError.Error
 		json.NewDecoder(recorder.Body).Decode(&body)
 		assert.Equal(t, expectedError, body)
@@ -129,27 +260,31 @@ func TestHandler_GetAllSubscribers(t *testing.T) {
 		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
 	})
 
-	t.Run("should response get all subscribers when request service get all subscribers success", func(t *testing.T) {
+	t.Run("should response page envelope when service get subscribers success", func(t *testing.T) {
 		beforeEachGetAllSubscribers()
-		resSubscribers := []entity.Subscribers{
-			{
-				ID:   1,
-				Name: "Test1",
-			},
-			{
-				ID:   2,
-				Name: "Test1",
+		resPage := &subscribers.Page{
+			Items: []entity.Subscribers{
+				{
+					ID:   1,
+					Name: "Test1",
+				},
+				{
+					ID:   2,
+					Name: "Test1",
+				},
 			},
+			Total:      5,
+			Page:       1,
+			PageSize:   2,
+			NextCursor: convert.ValueToInt64Pointer(2),
 		}
-		mockServiceGetAllSubscribers.Return(resSubscribers, nil)
+		mockServiceGetSubscribers.Return(resPage, nil)
 
 		router.ServeHTTP(recorder, request)
 
-		expectedBody := resSubscribers
-
-		var body []entity.Subscribers
+		var body subscribers.Page
 		json.NewDecoder(recorder.Body).Decode(&body)
-		assert.Equal(t, expectedBody, body)
+		assert.Equal(t, *resPage, body)
 		assert.Equal(t, http.StatusOK, recorder.Code)
 		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
 	})
@@ -165,6 +300,8 @@ func TestHandler_Subscribe(t *testing.T) {
 
 		mockServiceSubscribe = mocker.NewMockCall(callServiceSubscribe)
 		mockServiceSubscribe.Return(nil, nil)
+		mockFieldsValidateValues = mocker.NewMockCall(callFieldsValidateValues)
+		mockFieldsValidateValues.Return(nil, nil, nil)
 	}
 
 	t.Run("should response bad request when request handler subscribe format", func(t *testing.T) {
@@ -198,6 +335,186 @@ func TestHandler_Subscribe(t *testing.T) {
 		service.AssertCalled(t, "Subscribe", subscribers)
 	})
 
+	t.Run("should call service subscribe with normalized email and trimmed name", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"  AjisTestMail@Gmail.COM ","name":" TEST "}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST"})
+	})
+
+	t.Run("should record api key of request as source when call service subscribe", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","sourceApiKeyId":99}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+		request = request.WithContext(context.WithValue(request.Context(), middleware.APIKeyContextKey, &entity.APIKeys{ID: 7}))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST", SourceAPIKeyID: convert.ValueToInt64Pointer(7)})
+	})
+
+	t.Run("should ignore source sent in body when request has no api key", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","sourceApiKeyId":99}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST"})
+	})
+
+	t.Run("should record time zone from header when call service subscribe", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","timeZone":"Europe/London"}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+		request.Header.Set(requestHeader.TheTimeZoneIana, "Asia/Bangkok")
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST", TimeZone: convert.ValueToStringPointer("Asia/Bangkok")})
+	})
+
+	t.Run("should subscribe without time zone when header is not a known zone", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST"}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+		request.Header.Set(requestHeader.TheTimeZoneIana, "Asia/Nowhere")
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST"})
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should pass verification fields to verifier and subscriber fields to service", func(t *testing.T) {
+		beforeEachSubscribe()
+		verifier = &verificationMocks.Verifier{}
+		mockVerifierVerify = mocker.NewMockCall(callVerifierVerify)
+		mockVerifierVerify.Return(nil)
+		subscriberHandler.Verifier = verifier
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","challenge":"c","nonce":"42","website":""}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		verifier.AssertCalled(t, "Verify", verification.Submission{Challenge: "c", Nonce: "42"})
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "TEST"})
+	})
+
+	t.Run("should response verification failed without subscribing when verifier rejects", func(t *testing.T) {
+		beforeEachSubscribe()
+		verifier = &verificationMocks.Verifier{}
+		mockVerifierVerify = mocker.NewMockCall(callVerifierVerify)
+		mockVerifierVerify.Return(convert.ValueToErrorCodePointer(newsletterError.VerificationFailed))
+		subscriberHandler.Verifier = verifier
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","website":"http://spam.example"}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		var responseBody newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, newsletterError.VerificationFailed, responseBody.Code)
+		assert.Equal(t, http.StatusForbidden, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything)
+	})
+
+	t.Run("should skip verification when request has api key", func(t *testing.T) {
+		beforeEachSubscribe()
+		verifier = &verificationMocks.Verifier{}
+		subscriberHandler.Verifier = verifier
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST"}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+		request = request.WithContext(context.WithValue(request.Context(), middleware.APIKeyContextKey, &entity.APIKeys{ID: 7}))
+
+		router.ServeHTTP(recorder, request)
+
+		verifier.AssertNotCalled(t, "Verify", mock.Anything)
+		service.AssertCalled(t, "Subscribe", mock.Anything)
+	})
+
+	t.Run("should response validation failed with every invalid field when payload is invalid", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"not an email","name":"   "}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		var responseBody map[string]interface{}
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, string(newsletterError.ValidationFailed), responseBody["code"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"field": "email", "code": "FIELD_INVALID_EMAIL", "message": "Invalid email address"},
+			map[string]interface{}{"field": "name", "code": "FIELD_REQUIRED", "message": "This field is required"},
+		}, responseBody["data"])
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything)
+	})
+
+	t.Run("should response validation failed in request language", func(t *testing.T) {
+		beforeEachSubscribe()
+		requestBody := `{"email":"not an email","name":"TEST"}`
+		request = withLanguage(httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody))), "th")
+
+		router.ServeHTTP(recorder, request)
+
+		var responseBody map[string]interface{}
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, "ข้อมูลไม่ผ่านการตรวจสอบ", responseBody["message"])
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"field": "email", "code": "FIELD_INVALID_EMAIL", "message": "รูปแบบอีเมลไม่ถูกต้อง"},
+		}, responseBody["data"])
+	})
+
+	t.Run("should call service subscribe with normalized custom fields", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockFieldsValidateValues.Return(map[string]interface{}{"plan": "pro", "age": float64(30)}, []newsletterError.FieldError{}, nil)
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST","fields":{"plan":" pro ","age":30}}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		fields.AssertCalled(t, "ValidateValues", map[string]interface{}{"plan": " pro ", "age": float64(30)})
+		service.AssertCalled(t, "Subscribe", entity.Subscribers{
+			Email:  "ajistestmail@gmail.com",
+			Name:   "TEST",
+			Fields: map[string]interface{}{"plan": "pro", "age": float64(30)},
+		})
+	})
+
+	t.Run("should response custom field errors together with other field errors", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockFieldsValidateValues.Return(map[string]interface{}{}, []newsletterError.FieldError{{Field: "fields.age", Code: newsletterError.FieldInvalidType}}, nil)
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"   ","fields":{"age":"thirty"}}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		var responseBody map[string]interface{}
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, []interface{}{
+			map[string]interface{}{"field": "name", "code": "FIELD_REQUIRED", "message": "This field is required"},
+			map[string]interface{}{"field": "fields.age", "code": "FIELD_INVALID_TYPE", "message": "Value has the wrong type for this field"},
+		}, responseBody["data"])
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything)
+	})
+
+	t.Run("should response internal server error when read custom field definitions failed", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockFieldsValidateValues.Return(nil, nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		requestBody := `{"email":"ajistestmail@gmail.com","name":"TEST"}`
+		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		service.AssertNotCalled(t, "Subscribe", mock.Anything)
+	})
+
 	t.Run("should response internal server error when service subscribe failed", func(t *testing.T) {
 		beforeEachSubscribe()
 
@@ -256,7 +573,7 @@ func TestHandler_Subscribe(t *testing.T) {
 		router.ServeHTTP(recorder, request)
 
 		res := handler.ResponseSucess{
-			Body: "subscribe success",
+			Body: "please check your email to confirm subscription",
 		}
 
 		var responseBody handler.ResponseSucess
@@ -267,83 +584,129 @@ func TestHandler_Subscribe(t *testing.T) {
 	})
 }
 
-func TestHandler_Unsubscribe(t *testing.T) {
-	beforeEachSubscribe := func() {
+func TestHandler_Challenge(t *testing.T) {
+	beforeEachChallenge := func() {
 		beforeEach()
+		challenges = &verificationMocks.Issuer{}
+		subscriberHandler.Challenges = challenges
 		router = mux.NewRouter()
-		router.HandleFunc(uri, subscriberHandler.Unsubscribe)
+		router.HandleFunc(uri+"/challenge", subscriberHandler.Challenge)
 		recorder = httptest.NewRecorder()
-		request = httptest.NewRequest(http.MethodGet, uri, nil)
+		request = httptest.NewRequest(http.MethodGet, uri+"/challenge", nil)
 
-		mockServiceUnsubscribe = mocker.NewMockCall(callServiceUnsubscribe)
-		mockServiceUnsubscribe.Return(nil, nil)
+		mockChallengesIssue = mocker.NewMockCall(callChallengesIssue)
+		mockChallengesIssue.Return(&verification.Challenge{Challenge: "signed", Algorithm: verification.AlgorithmSHA256, Difficulty: 18}, nil)
 	}
 
-	t.Run("should response bad request when request handler unsubscribe format", func(t *testing.T) {
-		beforeEachSubscribe()
-		requestBody := ``
-		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(requestBody)))
+	t.Run("should response challenge that is not cached", func(t *testing.T) {
+		beforeEachChallenge()
 
 		router.ServeHTTP(recorder, request)
 
-		var responseBody newsletterError.Error
+		var responseBody verification.Challenge
 		json.NewDecoder(recorder.Body).Decode(&responseBody)
-		err := newsletterError.NewError(newsletterError.BadRequest, newsletterError.BadRequestMessage)
-		assert.Equal(t, *err, responseBody)
-		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
-		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.Equal(t, "signed", responseBody.Challenge)
+		assert.Equal(t, 18, responseBody.Difficulty)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, requestHeader.NoStore, recorder.Header().Get(requestHeader.CacheControl))
 	})
 
-	t.Run("should call service unsubscribe when request unsubscribe", func(t *testing.T) {
-		beforeEachSubscribe()
+	t.Run("should response data not found when proof of work is disabled", func(t *testing.T) {
+		beforeEachChallenge()
+		subscriberHandler.Challenges = nil
 
-		unsubscribers := entity.Subscribers{
-			Name:  "TEST",
-			Email: "ajistestmail@gmail.com",
-		}
+		router.ServeHTTP(recorder, request)
 
-		jsonMock, _ := json.Marshal(unsubscribers)
-		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(jsonMock)))
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+
+	t.Run("should response internal server error when issue failed", func(t *testing.T) {
+		beforeEachChallenge()
+		mockChallengesIssue.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
 
 		router.ServeHTTP(recorder, request)
 
-		service.AssertCalled(t, "Unsubscribe", unsubscribers)
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
 	})
+}
 
-	t.Run("should response internal server error when service unsubscribe failed", func(t *testing.T) {
-		beforeEachSubscribe()
+func TestHandler_Confirm(t *testing.T) {
+	beforeEachConfirm := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc(uri, subscriberHandler.Confirm)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodGet, uri+"?token=token", nil)
 
-		unsubscribers := entity.Subscribers{
-			Name:  "TEST",
-			Email: "ajistestmail@gmail.com",
-		}
+		mockServiceConfirm = mocker.NewMockCall(callServiceConfirm)
+		mockServiceConfirm.Return(nil)
+	}
 
-		jsonMock, _ := json.Marshal(unsubscribers)
-		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(jsonMock)))
-		mockServiceUnsubscribe.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+	t.Run("should response empty token when request confirm without token", func(t *testing.T) {
+		beforeEachConfirm()
+		request = httptest.NewRequest(http.MethodGet, uri, nil)
 
 		router.ServeHTTP(recorder, request)
 
-		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InternalServerError, "en")
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.EmptyToken, "en")
+		var responseBody newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, expectedError, responseBody)
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+		service.AssertNotCalled(t, "Confirm", mock.Anything)
+	})
+
+	t.Run("should call service confirm when request confirm", func(t *testing.T) {
+		beforeEachConfirm()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "Confirm", "token")
+	})
+
+	t.Run("should response invalid token when service confirm failed with invalid token", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirm.Return(convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InvalidToken, "en")
 		var responseBody newsletterError.Error
 		json.NewDecoder(recorder.Body).Decode(&responseBody)
 		assert.Equal(t, expectedError, responseBody)
 		assert.Equal(t, expectedStatusCode, recorder.Code)
 		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+	})
+
+	t.Run("should response expired token when service confirm failed with expired token", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirm.Return(convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+
+		router.ServeHTTP(recorder, request)
 
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.ExpiredToken, "en")
+		var responseBody newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, expectedError, responseBody)
+		assert.Equal(t, expectedStatusCode, recorder.Code)
 	})
 
-	t.Run("should response data not found server error when service unsubscribe failed", func(t *testing.T) {
-		beforeEachSubscribe()
+	t.Run("should response error in request language when service confirm failed", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirm.Return(convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+		request = withLanguage(request, "th")
 
-		unsubscribers := entity.Subscribers{
-			Name:  "TEST",
-			Email: "ajistestmail@gmail.com",
-		}
+		router.ServeHTTP(recorder, request)
 
-		jsonMock, _ := json.Marshal(unsubscribers)
-		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(jsonMock)))
-		mockServiceUnsubscribe.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+		_, expectedError := newsletterError.MapMessageError(newsletterError.ExpiredToken, "th")
+		var responseBody newsletterError.Error
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		assert.Equal(t, expectedError, responseBody)
+	})
+
+	t.Run("should response data not found when service confirm failed with data not found", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirm.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
 
 		router.ServeHTTP(recorder, request)
 
@@ -352,23 +715,15 @@ func TestHandler_Unsubscribe(t *testing.T) {
 		json.NewDecoder(recorder.Body).Decode(&responseBody)
 		assert.Equal(t, expectedError, responseBody)
 		assert.Equal(t, expectedStatusCode, recorder.Code)
-		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
-
 	})
 
-	t.Run("should return ok when request unsubscribe success", func(t *testing.T) {
-		beforeEachSubscribe()
-		unsubscribers := entity.Subscribers{
-			Name:  "TEST",
-			Email: "ajistestmail@gmail.com",
-		}
-		jsonMock, _ := json.Marshal(unsubscribers)
-		request = httptest.NewRequest(http.MethodPost, uri, bytes.NewBuffer([]byte(jsonMock)))
+	t.Run("should return ok when request confirm success", func(t *testing.T) {
+		beforeEachConfirm()
 
 		router.ServeHTTP(recorder, request)
 
 		res := handler.ResponseSucess{
-			Body: "unsubscribe success",
+			Body: "confirm success",
 		}
 
 		var responseBody handler.ResponseSucess
@@ -378,3 +733,446 @@ func TestHandler_Unsubscribe(t *testing.T) {
 		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
 	})
 }
+
+func TestHandler_UnsubscribePage(t *testing.T) {
+	beforeEachUnsubscribePage := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc(uri+"/unsubscribe/{token}", subscriberHandler.UnsubscribePage)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodGet, uri+"/unsubscribe/token", nil)
+
+		mockServiceVerifyUnsubscribeToken = mocker.NewMockCall(callServiceVerifyUnsubscribeToken)
+		mockServiceVerifyUnsubscribeToken.Return("ajistestmail@gmail.com", nil)
+	}
+
+	t.Run("should call service verify unsubscribe token when request unsubscribe page", func(t *testing.T) {
+		beforeEachUnsubscribePage()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "VerifyUnsubscribeToken", "token")
+		service.AssertNotCalled(t, "UnsubscribeByToken", mock.Anything)
+	})
+
+	t.Run("should response html form posting to the same url when token is valid", func(t *testing.T) {
+		beforeEachUnsubscribePage()
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, requestHeader.TextHtml, recorder.Header().Get(requestHeader.ContentType))
+		assert.True(t, strings.Contains(recorder.Body.String(), `action="/subscribers/unsubscribe/token"`))
+	})
+
+	t.Run("should response error page when token is invalid", func(t *testing.T) {
+		beforeEachUnsubscribePage()
+		mockServiceVerifyUnsubscribeToken.Return("", convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InvalidToken, "en")
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+		assert.Equal(t, requestHeader.TextHtml, recorder.Header().Get(requestHeader.ContentType))
+		assert.True(t, strings.Contains(recorder.Body.String(), expectedError.Message))
+		assert.False(t, strings.Contains(recorder.Body.String(), "<form"))
+	})
+
+	t.Run("should response page in request language", func(t *testing.T) {
+		beforeEachUnsubscribePage()
+		request = withLanguage(request, "th")
+
+		router.ServeHTTP(recorder, request)
+
+		assert.True(t, strings.Contains(recorder.Body.String(), `<html lang="th">`))
+		assert.True(t, strings.Contains(recorder.Body.String(), "กดปุ่มด้านล่างเพื่อยกเลิกการรับจดหมายข่าวของเรา"))
+	})
+
+	t.Run("should response error page in request language when token is invalid", func(t *testing.T) {
+		beforeEachUnsubscribePage()
+		mockServiceVerifyUnsubscribeToken.Return("", convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+		request = withLanguage(request, "th")
+
+		router.ServeHTTP(recorder, request)
+
+		_, expectedError := newsletterError.MapMessageError(newsletterError.InvalidToken, "th")
+		assert.True(t, strings.Contains(recorder.Body.String(), expectedError.Message))
+	})
+}
+
+func TestHandler_UnsubscribeByToken(t *testing.T) {
+	beforeEachUnsubscribeByToken := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc(uri+"/unsubscribe/{token}", subscriberHandler.UnsubscribeByToken)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodPost, uri+"/unsubscribe/token", strings.NewReader("List-Unsubscribe=One-Click"))
+
+		mockServiceUnsubscribeByToken = mocker.NewMockCall(callServiceUnsubscribeByToken)
+		mockServiceUnsubscribeByToken.Return(nil)
+	}
+
+	t.Run("should call service unsubscribe by token when one click unsubscribe", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "UnsubscribeByToken", "token")
+	})
+
+	t.Run("should response ok when service unsubscribe by token success", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, requestHeader.TextHtml, recorder.Header().Get(requestHeader.ContentType))
+	})
+
+	t.Run("should response success page in request language", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+		request = withLanguage(request, "th")
+
+		router.ServeHTTP(recorder, request)
+
+		assert.True(t, strings.Contains(recorder.Body.String(), "ยกเลิกการรับข่าวสารเรียบร้อยแล้ว"))
+	})
+
+	t.Run("should response invalid token when service unsubscribe by token failed with invalid token", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+		mockServiceUnsubscribeByToken.Return(convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, _ := newsletterError.MapMessageError(newsletterError.InvalidToken, "en")
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+	})
+
+	t.Run("should response data not found when service unsubscribe by token failed with data not found", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+		mockServiceUnsubscribeByToken.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, _ := newsletterError.MapMessageError(newsletterError.DataNotFound, "en")
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+	})
+}
+
+func TestHandler_ExportSubscribers(t *testing.T) {
+	beforeEachExportSubscribers := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc(uri+"/export", subscriberHandler.ExportSubscribers)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodGet, uri+"/export?isSubscribed=true", nil)
+
+		mockServiceExportSubscribers = mocker.NewMockCall(callServiceExportSubscribers)
+		mockServiceExportSubscribers.Return([]entity.Subscribers{}, nil)
+	}
+
+	t.Run("should call service export subscribers with parsed filter", func(t *testing.T) {
+		beforeEachExportSubscribers()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "ExportSubscribers", mock.MatchedBy(func(filter subscribers.Filter) bool {
+			return filter.IsSubscribed != nil && *filter.IsSubscribed
+		}))
+	})
+
+	t.Run("should response csv attachment with header and one row per subscriber", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		subscribedDate := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+		mockServiceExportSubscribers.Return([]entity.Subscribers{
+			{ID: 1, Email: "ajistestmail@gmail.com", Name: "Som, Chai", IsSubscribed: true, SubscribedDate: &subscribedDate},
+			{ID: 2, Email: "b@example.com", Name: "=HYPERLINK(\"http://evil\")"},
+		}, nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, requestHeader.TextCsv, recorder.Header().Get(requestHeader.ContentType))
+		assert.Equal(t, `attachment; filename="subscribers.csv"`, recorder.Header().Get(requestHeader.ContentDisposition))
+		assert.Equal(t, "id,email,name,isSubscribed,isPending,subscribedDate,unsubscribedDate,confirmedDate\n"+
+			"1,ajistestmail@gmail.com,\"Som, Chai\",true,false,2023-01-02T03:04:05Z,,\n"+
+			"2,b@example.com,\"'=HYPERLINK(\"\"http://evil\"\")\",false,false,,,\n", recorder.Body.String())
+	})
+
+	t.Run("should response bad request when query is invalid", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		request = httptest.NewRequest(http.MethodGet, uri+"/export?isSubscribed=maybe", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "ExportSubscribers", mock.Anything)
+	})
+
+	t.Run("should response internal server error when service export failed", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		mockServiceExportSubscribers.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.Equal(t, requestHeader.ApplicationJson, recorder.Header().Get(requestHeader.ContentType))
+	})
+}
+
+func TestHandler_DeleteSubscriber(t *testing.T) {
+	beforeEachDeleteSubscriber := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc(uri+"/{id}", subscriberHandler.DeleteSubscriber)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodDelete, uri+"/1", nil)
+
+		mockServiceDeleteByID = mocker.NewMockCall(callServiceDeleteByID)
+		mockServiceDeleteByID.Return(nil)
+	}
+
+	t.Run("should call service delete by id and response ok", func(t *testing.T) {
+		beforeEachDeleteSubscriber()
+
+		router.ServeHTTP(recorder, request)
+
+		var responseBody handler.ResponseSucess
+		json.NewDecoder(recorder.Body).Decode(&responseBody)
+		service.AssertCalled(t, "DeleteByID", int64(1))
+		assert.Equal(t, handler.ResponseSucess{Body: "delete subscriber success"}, responseBody)
+		assert.Equal(t, http.StatusOK, recorder.Code)
+	})
+
+	t.Run("should response bad request when id is not a number", func(t *testing.T) {
+		beforeEachDeleteSubscriber()
+		request = httptest.NewRequest(http.MethodDelete, uri+"/abc", nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		service.AssertNotCalled(t, "DeleteByID", mock.Anything)
+	})
+
+	t.Run("should response data not found when subscriber does not exist", func(t *testing.T) {
+		beforeEachDeleteSubscriber()
+		mockServiceDeleteByID.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func mockDataPreferences() *subscribers.Preferences {
+	return &subscribers.Preferences{
+		SubscriberID: 7,
+		Email:        "ajistestmail@gmail.com",
+		Name:         "ajis",
+		Frequency:    subscribers.FrequencyImmediate,
+		Lists: []subscribers.ListPreference{
+			{ID: 3, Name: "Promotions", IsSubscribed: true},
+			{ID: 2, Name: "Weekly digest"},
+		},
+	}
+}
+
+func TestHandler_PreferencesPage(t *testing.T) {
+	beforeEachPreferencesPage := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc("/preferences/{token}", subscriberHandler.PreferencesPage)
+		recorder = httptest.NewRecorder()
+		request = httptest.NewRequest(http.MethodGet, "/preferences/token", nil)
+
+		mockServiceVerifyPreferencesToken = mocker.NewMockCall(callServiceVerifyPreferencesToken)
+		mockServiceVerifyPreferencesToken.Return("ajistestmail@gmail.com", nil)
+		mockServiceGetPreferences = mocker.NewMockCall(callServiceGetPreferences)
+		mockServiceGetPreferences.Return(mockDataPreferences(), nil)
+	}
+
+	t.Run("should load preferences of token email", func(t *testing.T) {
+		beforeEachPreferencesPage()
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "VerifyPreferencesToken", "token")
+		service.AssertCalled(t, "GetPreferences", "ajistestmail@gmail.com")
+	})
+
+	t.Run("should response form with current choices", func(t *testing.T) {
+		beforeEachPreferencesPage()
+
+		router.ServeHTTP(recorder, request)
+
+		body := recorder.Body.String()
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.Equal(t, requestHeader.TextHtml, recorder.Header().Get(requestHeader.ContentType))
+		assert.True(t, strings.Contains(body, `action="/preferences/token"`))
+		assert.True(t, strings.Contains(body, `name="name" value="ajis"`))
+		assert.True(t, strings.Contains(body, `<option value="en" selected>English</option>`))
+		assert.True(t, strings.Contains(body, `value="immediate" checked`))
+		assert.True(t, strings.Contains(body, `value="3" checked> Promotions`))
+		assert.True(t, strings.Contains(body, `value="2"> Weekly digest`))
+	})
+
+	t.Run("should preselect language of subscriber", func(t *testing.T) {
+		beforeEachPreferencesPage()
+		preferences := mockDataPreferences()
+		preferences.Language = "th"
+		mockServiceGetPreferences.Return(preferences, nil)
+
+		router.ServeHTTP(recorder, request)
+
+		assert.True(t, strings.Contains(recorder.Body.String(), `<option value="th" selected>`))
+	})
+
+	t.Run("should response error page when token is invalid", func(t *testing.T) {
+		beforeEachPreferencesPage()
+		mockServiceVerifyPreferencesToken.Return("", convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, expectedError := newsletterError.MapMessageError(newsletterError.InvalidToken, "en")
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+		assert.True(t, strings.Contains(recorder.Body.String(), expectedError.Message))
+		assert.False(t, strings.Contains(recorder.Body.String(), "<form"))
+		service.AssertNotCalled(t, "GetPreferences", mock.Anything)
+	})
+
+	t.Run("should response data not found page when subscriber is gone", func(t *testing.T) {
+		beforeEachPreferencesPage()
+		mockServiceGetPreferences.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusNotFound, recorder.Code)
+	})
+}
+
+func TestHandler_UpdatePreferences(t *testing.T) {
+	beforeEachUpdatePreferences := func() {
+		beforeEach()
+		router = mux.NewRouter()
+		router.HandleFunc("/preferences/{token}", subscriberHandler.UpdatePreferences)
+		recorder = httptest.NewRecorder()
+
+		mockServiceVerifyPreferencesToken = mocker.NewMockCall(callServiceVerifyPreferencesToken)
+		mockServiceVerifyPreferencesToken.Return("ajistestmail@gmail.com", nil)
+		mockServiceGetPreferences = mocker.NewMockCall(callServiceGetPreferences)
+		mockServiceGetPreferences.Return(mockDataPreferences(), nil)
+		mockServiceUpdatePreferences = mocker.NewMockCall(callServiceUpdatePreferences)
+		mockServiceUpdatePreferences.Return(nil)
+	}
+	formRequest := func(form url.Values) *http.Request {
+		formRequest := httptest.NewRequest(http.MethodPost, "/preferences/token", strings.NewReader(form.Encode()))
+		formRequest.Header.Set(requestHeader.ContentType, "application/x-www-form-urlencoded")
+		return formRequest
+	}
+	validForm := func() url.Values {
+		return url.Values{
+			"name":      {" ajis "},
+			"language":  {"th"},
+			"frequency": {"weekly"},
+			"timeZone":  {"Asia/Bangkok"},
+			"list":      {"2"},
+		}
+	}
+
+	t.Run("should call service update preferences with choices of form", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		request = formRequest(validForm())
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "UpdatePreferences", subscribers.Preferences{
+			Email:     "ajistestmail@gmail.com",
+			Name:      "ajis",
+			Language:  "th",
+			Frequency: "weekly",
+			TimeZone:  "Asia/Bangkok",
+			Lists: []subscribers.ListPreference{
+				{ID: 3, Name: "Promotions"},
+				{ID: 2, Name: "Weekly digest", IsSubscribed: true},
+			},
+		})
+	})
+
+	t.Run("should response form with saved message when update success", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		request = formRequest(validForm())
+
+		router.ServeHTTP(recorder, request)
+
+		body := recorder.Body.String()
+		assert.Equal(t, http.StatusOK, recorder.Code)
+		assert.True(t, strings.Contains(body, "Your preferences have been saved."))
+		assert.True(t, strings.Contains(body, `value="2" checked> Weekly digest`))
+	})
+
+	t.Run("should ignore lists that are not offered", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		form := validForm()
+		form["list"] = []string{"2", "99"}
+		request = formRequest(form)
+
+		router.ServeHTTP(recorder, request)
+
+		service.AssertCalled(t, "UpdatePreferences", mock.MatchedBy(func(preferences subscribers.Preferences) bool {
+			return len(preferences.Lists) == 2
+		}))
+	})
+
+	t.Run("should response form with field errors when choices are invalid", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		form := validForm()
+		form.Set("frequency", "hourly")
+		request = formRequest(form)
+
+		router.ServeHTTP(recorder, request)
+
+		body := recorder.Body.String()
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.True(t, strings.Contains(body, "How often: Value is not one of the available options"))
+		assert.True(t, strings.Contains(body, "<form"))
+		service.AssertNotCalled(t, "UpdatePreferences", mock.Anything)
+	})
+
+	t.Run("should response form with field error when time zone is unknown", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		form := validForm()
+		form.Set("timeZone", "Asia/Nowhere")
+		request = formRequest(form)
+
+		router.ServeHTTP(recorder, request)
+
+		body := recorder.Body.String()
+		assert.Equal(t, http.StatusBadRequest, recorder.Code)
+		assert.True(t, strings.Contains(body, "Time zone: Time zone must be an IANA name like Asia/Bangkok"))
+		assert.True(t, strings.Contains(body, `value="Asia/Nowhere"`))
+		service.AssertNotCalled(t, "UpdatePreferences", mock.Anything)
+	})
+
+	t.Run("should response error page when token is invalid", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		mockServiceVerifyPreferencesToken.Return("", convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+		request = formRequest(validForm())
+
+		router.ServeHTTP(recorder, request)
+
+		expectedStatusCode, _ := newsletterError.MapMessageError(newsletterError.ExpiredToken, "en")
+		assert.Equal(t, expectedStatusCode, recorder.Code)
+		service.AssertNotCalled(t, "UpdatePreferences", mock.Anything)
+	})
+
+	t.Run("should response error page when service update preferences failed", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		mockServiceUpdatePreferences.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		request = formRequest(validForm())
+
+		router.ServeHTTP(recorder, request)
+
+		assert.Equal(t, http.StatusInternalServerError, recorder.Code)
+		assert.False(t, strings.Contains(recorder.Body.String(), "<form"))
+	})
+}
@@ -1,13 +1,26 @@
 package handler
 
 import (
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
 	subscribers "newsletter/src/pkg/subscribers"
 	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/verification"
 )
 
 type HandlerParam struct {
-	Service subscribers.UseCase
-	Logs    logger.Logger
+	Service    subscribers.UseCase
+	Fields     customfields.UseCase
+	Verifier   verification.Verifier
+	Challenges verification.Issuer
+	Logs       logger.Logger
+}
+
+// SubscribeRequest is the subscribe payload, with the fields the public form
+// sends to pass verification.
+type SubscribeRequest struct {
+	entity.Subscribers
+	verification.Submission
 }
 
 type ResponseSucess struct {
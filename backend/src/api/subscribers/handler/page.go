@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"html/template"
+	"net/http"
+	requestHeader "newsletter/src/api/requestheader"
+	subscribers "newsletter/src/pkg/subscribers"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/i18n"
+	"strconv"
+	"strings"
+)
+
+const (
+	UnsubscribeTitle   = "PAGE_UNSUBSCRIBE_TITLE"
+	UnsubscribePrompt  = "PAGE_UNSUBSCRIBE_PROMPT"
+	UnsubscribeAction  = "PAGE_UNSUBSCRIBE_ACTION"
+	UnsubscribeSuccess = "PAGE_UNSUBSCRIBE_SUCCESS"
+
+	PreferencesTitle     = "PAGE_PREFERENCES_TITLE"
+	PreferencesPrompt    = "PAGE_PREFERENCES_PROMPT"
+	PreferencesName      = "PAGE_PREFERENCES_NAME"
+	PreferencesLanguage  = "PAGE_PREFERENCES_LANGUAGE"
+	PreferencesFrequency = "PAGE_PREFERENCES_FREQUENCY"
+	PreferencesTimeZone  = "PAGE_PREFERENCES_TIME_ZONE"
+	PreferencesLists     = "PAGE_PREFERENCES_LISTS"
+	PreferencesAction    = "PAGE_PREFERENCES_ACTION"
+	PreferencesSuccess   = "PAGE_PREFERENCES_SUCCESS"
+
+	// LanguageLabelPrefix and FrequencyLabelPrefix are followed by the
+	// uppercased language code or frequency to name an option.
+	LanguageLabelPrefix  = "PAGE_LANGUAGE_"
+	FrequencyLabelPrefix = "PAGE_FREQUENCY_"
+)
+
+// pageMessages holds the built-in page text. A loaded i18n catalog can
+// override any key or add a language.
+var pageMessages = map[string]i18n.Catalog{
+	newsletterError.EN: {
+		UnsubscribeTitle:   "Unsubscribe",
+		UnsubscribePrompt:  "Click the button below to stop receiving our newsletter.",
+		UnsubscribeAction:  "Unsubscribe",
+		UnsubscribeSuccess: "You have been unsubscribed and will no longer receive our newsletter.",
+
+		PreferencesTitle:     "Email preferences",
+		PreferencesPrompt:    "Choose what you would like to hear from us.",
+		PreferencesName:      "Name",
+		PreferencesLanguage:  "Language",
+		PreferencesFrequency: "How often",
+		PreferencesTimeZone:  "Time zone",
+		PreferencesLists:     "Lists",
+		PreferencesAction:    "Save",
+		PreferencesSuccess:   "Your preferences have been saved.",
+
+		LanguageLabelPrefix + "EN": "English",
+		LanguageLabelPrefix + "TH": "ไทย",
+
+		FrequencyLabelPrefix + "IMMEDIATE": "With every newsletter",
+		FrequencyLabelPrefix + "WEEKLY":    "Once a week",
+		FrequencyLabelPrefix + "MONTHLY":   "Once a month",
+	},
+	newsletterError.TH: {
+		UnsubscribeTitle:   "ยกเลิกการรับข่าวสาร",
+		UnsubscribePrompt:  "กดปุ่มด้านล่างเพื่อยกเลิกการรับจดหมายข่าวของเรา",
+		UnsubscribeAction:  "ยกเลิกการรับข่าวสาร",
+		UnsubscribeSuccess: "ยกเลิกการรับข่าวสารเรียบร้อยแล้ว คุณจะไม่ได้รับจดหมายข่าวของเราอีก",
+
+		PreferencesTitle:     "ตั้งค่าการรับข่าวสาร",
+		PreferencesPrompt:    "เลือกข่าวสารที่คุณต้องการรับจากเรา",
+		PreferencesName:      "ชื่อ",
+		PreferencesLanguage:  "ภาษา",
+		PreferencesFrequency: "ความถี่",
+		PreferencesTimeZone:  "เขตเวลา",
+		PreferencesLists:     "รายการข่าวสาร",
+		PreferencesAction:    "บันทึก",
+		PreferencesSuccess:   "บันทึกการตั้งค่าเรียบร้อยแล้ว",
+
+		LanguageLabelPrefix + "EN": "English",
+		LanguageLabelPrefix + "TH": "ไทย",
+
+		FrequencyLabelPrefix + "IMMEDIATE": "ทุกครั้งที่มีจดหมายข่าว",
+		FrequencyLabelPrefix + "WEEKLY":    "สัปดาห์ละครั้ง",
+		FrequencyLabelPrefix + "MONTHLY":   "เดือนละครั้ง",
+	},
+}
+
+type PageContent struct {
+	Language    string
+	Title       string
+	Message     string
+	ActionURL   string
+	ActionLabel string
+}
+
+var pageTemplate = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{.Title}}</title>
+</head>
+<body>
+	<h1>{{.Title}}</h1>
+	<p>{{.Message}}</p>
+	{{if .ActionURL}}
+	<form method="POST" action="{{.ActionURL}}">
+		<input type="hidden" name="List-Unsubscribe" value="One-Click">
+		<button type="submit">{{.ActionLabel}}</button>
+	</form>
+	{{end}}
+</body>
+</html>
+`))
+
+// PreferencesContent fills the preference page form. Errors holds one line
+// per field that failed validation.
+type PreferencesContent struct {
+	Language       string
+	Title          string
+	Message        string
+	Errors         []string
+	ActionURL      string
+	ActionLabel    string
+	NameLabel      string
+	LanguageLabel  string
+	FrequencyLabel string
+	TimeZoneLabel  string
+	ListsLabel     string
+	Name           string
+	TimeZone       string
+	Languages      []PageOption
+	Frequencies    []PageOption
+	Lists          []PageOption
+}
+
+// preferencesFieldLabels maps a validated field to the text of its label.
+var preferencesFieldLabels = map[string]string{
+	subscribers.NameField:      PreferencesName,
+	subscribers.LanguageField:  PreferencesLanguage,
+	subscribers.FrequencyField: PreferencesFrequency,
+	subscribers.TimeZoneField:  PreferencesTimeZone,
+}
+
+type PageOption struct {
+	Value    string
+	Label    string
+	Selected bool
+}
+
+var preferencesTemplate = template.Must(template.New("preferences").Parse(`<!DOCTYPE html>
+<html lang="{{.Language}}">
+<head>
+	<meta charset="utf-8">
+	<meta name="viewport" content="width=device-width, initial-scale=1">
+	<title>{{.Title}}</title>
+</head>
+<body>
+	<h1>{{.Title}}</h1>
+	{{if .Message}}<p>{{.Message}}</p>{{end}}
+	{{range .Errors}}<p role="alert">{{.}}</p>{{end}}
+	<form method="POST" action="{{.ActionURL}}">
+		<p>
+			<label for="name">{{.NameLabel}}</label>
+			<input type="text" id="name" name="name" value="{{.Name}}" maxlength="255" required>
+		</p>
+		<p>
+			<label for="language">{{.LanguageLabel}}</label>
+			<select id="language" name="language">
+				{{range .Languages}}<option value="{{.Value}}"{{if .Selected}} selected{{end}}>{{.Label}}</option>
+				{{end}}
+			</select>
+		</p>
+		<fieldset>
+			<legend>{{.FrequencyLabel}}</legend>
+			{{range .Frequencies}}<label><input type="radio" name="frequency" value="{{.Value}}"{{if .Selected}} checked{{end}}> {{.Label}}</label>
+			{{end}}
+		</fieldset>
+		<p>
+			<label for="timeZone">{{.TimeZoneLabel}}</label>
+			<input type="text" id="timeZone" name="timeZone" value="{{.TimeZone}}" maxlength="64" placeholder="Asia/Bangkok">
+		</p>
+		{{if .Lists}}
+		<fieldset>
+			<legend>{{.ListsLabel}}</legend>
+			{{range .Lists}}<label><input type="checkbox" name="list" value="{{.Value}}"{{if .Selected}} checked{{end}}> {{.Label}}</label>
+			{{end}}
+		</fieldset>
+		{{end}}
+		<button type="submit">{{.ActionLabel}}</button>
+	</form>
+</body>
+</html>
+`))
+
+func renderPreferencesPage(response http.ResponseWriter, statusCode int, content PreferencesContent) {
+	response.Header().Set(requestHeader.ContentType, requestHeader.TextHtml)
+	response.WriteHeader(statusCode)
+	preferencesTemplate.Execute(response, content)
+}
+
+// preferencesContent builds the preference form for preferences, shown in
+// language. A subscriber who has not picked a language yet gets the page
+// language preselected.
+func preferencesContent(language, actionURL string, preferences subscribers.Preferences) PreferencesContent {
+	content := PreferencesContent{
+		Language:       language,
+		Title:          pageText(language, PreferencesTitle),
+		Message:        pageText(language, PreferencesPrompt),
+		ActionURL:      actionURL,
+		ActionLabel:    pageText(language, PreferencesAction),
+		NameLabel:      pageText(language, PreferencesName),
+		LanguageLabel:  pageText(language, PreferencesLanguage),
+		FrequencyLabel: pageText(language, PreferencesFrequency),
+		TimeZoneLabel:  pageText(language, PreferencesTimeZone),
+		ListsLabel:     pageText(language, PreferencesLists),
+		Name:           preferences.Name,
+		TimeZone:       preferences.TimeZone,
+	}
+
+	selectedLanguage := preferences.Language
+	if selectedLanguage == "" {
+		selectedLanguage = language
+	}
+	for _, option := range newsletterError.Languages() {
+		content.Languages = append(content.Languages, PageOption{
+			Value:    option,
+			Label:    optionLabel(language, LanguageLabelPrefix, option),
+			Selected: option == selectedLanguage,
+		})
+	}
+
+	for _, option := range subscribers.Frequencies {
+		content.Frequencies = append(content.Frequencies, PageOption{
+			Value:    option,
+			Label:    optionLabel(language, FrequencyLabelPrefix, option),
+			Selected: option == preferences.Frequency,
+		})
+	}
+
+	for _, list := range preferences.Lists {
+		content.Lists = append(content.Lists, PageOption{
+			Value:    strconv.FormatInt(list.ID, 10),
+			Label:    list.Name,
+			Selected: list.IsSubscribed,
+		})
+	}
+
+	return content
+}
+
+func renderPage(response http.ResponseWriter, statusCode int, content PageContent) {
+	response.Header().Set(requestHeader.ContentType, requestHeader.TextHtml)
+	response.WriteHeader(statusCode)
+	pageTemplate.Execute(response, content)
+}
+
+// pageText returns the page text for key in language, falling back to English.
+func pageText(language, key string) string {
+	if message, ok := i18n.Lookup(language, key); ok {
+		return message
+	}
+	if message, ok := pageMessages[language][key]; ok {
+		return message
+	}
+	return pageMessages[newsletterError.EN][key]
+}
+
+// optionLabel names a language or frequency option, falling back to the
+// value itself when no text is registered for it.
+func optionLabel(language, prefix, value string) string {
+	if label := pageText(language, prefix+strings.ToUpper(value)); label != "" {
+		return label
+	}
+	return value
+}
@@ -6,19 +6,49 @@ import (
 )
 
 type Configuration struct {
-	PORT        string `env:"PORT"`
-	AppVersion  string `env:"APP_VERSION"`
-	DBName      string `env:"DB_NAME"`
-	DBUserName  string `env:"DB_USERNAME"`
-	DBPassword  string `env:"DB_PASSWORD"`
-	DBHost      string `env:"DB_HOST"`
-	DBPort      string `env:"DB_PORT"`
-	ELSURL      string `env:"ELS_URL"`
-	ELSUsername string `env:"ELS_USERNAME"`
-	ELSPassword string `env:"ELS_PASSWORD"`
-	ELSIndex    string `env:"ELS_INDEX"`
-	Stage       string `env:"STAGE"`
-	Origin      string `env:"HOST_WEB"`
+	PORT                   string `env:"PORT"`
+	AppVersion             string `env:"APP_VERSION"`
+	DBName                 string `env:"DB_NAME"`
+	DBUserName             string `env:"DB_USERNAME"`
+	DBPassword             string `env:"DB_PASSWORD"`
+	DBHost                 string `env:"DB_HOST"`
+	DBPort                 string `env:"DB_PORT"`
+	ELSURL                 string `env:"ELS_URL"`
+	ELSUsername            string `env:"ELS_USERNAME"`
+	ELSPassword            string `env:"ELS_PASSWORD"`
+	ELSIndex               string `env:"ELS_INDEX"`
+	Stage                  string `env:"STAGE"`
+	Origin                 string `env:"HOST_WEB"`
+	APIURL                 string `env:"API_URL" default:"http://localhost:8000"`
+	MailHost               string `env:"MAIL_HOST"`
+	MailPort               string `env:"MAIL_PORT" default:"587"`
+	MailUsername           string `env:"MAIL_USERNAME"`
+	MailPassword           string `env:"MAIL_PASSWORD"`
+	MailSender             string `env:"MAIL_SENDER"`
+	MailMaxAttempts        string `env:"MAIL_MAX_ATTEMPTS" default:"3"`
+	MailRetryBaseDelay     string `env:"MAIL_RETRY_BASE_DELAY" default:"1s"`
+	MailRetryMaxDelay      string `env:"MAIL_RETRY_MAX_DELAY" default:"30s"`
+	BlobDir                string `env:"BLOB_DIR" default:"blobs"`
+	AttachmentMaxSize      string `env:"ATTACHMENT_MAX_SIZE" default:"5242880"`
+	AttachmentMaxTotalSize string `env:"ATTACHMENT_MAX_TOTAL_SIZE" default:"10485760"`
+	TokenSecret            string `env:"TOKEN_SECRET"`
+	ConfirmTTL             string `env:"CONFIRM_TTL" default:"24h"`
+	PendingCleanupInterval string `env:"PENDING_CLEANUP_INTERVAL" default:"1h"`
+	TemplateDir            string `env:"TEMPLATE_DIR" default:"templates"`
+	LocaleDir              string `env:"LOCALE_DIR" default:"locales"`
+	JWTAlgorithm           string `env:"JWT_ALGORITHM" default:"HS256"`
+	JWTSecret              string `env:"JWT_SECRET"`
+	JWTPublicKeyFile       string `env:"JWT_PUBLIC_KEY_FILE"`
+	JWTIssuer              string `env:"JWT_ISSUER"`
+	JWTAudience            string `env:"JWT_AUDIENCE"`
+	RateLimitIPRequests    string `env:"RATE_LIMIT_IP_REQUESTS" default:"10"`
+	RateLimitIPPeriod      string `env:"RATE_LIMIT_IP_PERIOD" default:"1m"`
+	RateLimitEmailRequests string `env:"RATE_LIMIT_EMAIL_REQUESTS" default:"3"`
+	RateLimitEmailPeriod   string `env:"RATE_LIMIT_EMAIL_PERIOD" default:"1h"`
+	RateLimitTrustProxy    string `env:"RATE_LIMIT_TRUST_PROXY" default:"false"`
+	VerificationHoneypot   string `env:"VERIFICATION_HONEYPOT" default:"true"`
+	VerificationPoWBits    string `env:"VERIFICATION_POW_DIFFICULTY" default:"0"`
+	VerificationPoWTTL     string `env:"VERIFICATION_POW_TTL" default:"5m"`
 }
 
 func New() Configuration {
@@ -22,6 +22,36 @@ func TestConfig_New(t *testing.T) {
 		t.Setenv("ELS_PASSWORD", "ELS_PASSWORD")
 		t.Setenv("ELS_INDEX", "ELS_INDEX")
 		t.Setenv("HOST_WEB", "HOST_WEB")
+		t.Setenv("API_URL", "API_URL")
+		t.Setenv("MAIL_HOST", "MAIL_HOST")
+		t.Setenv("MAIL_PORT", "MAIL_PORT")
+		t.Setenv("MAIL_USERNAME", "MAIL_USERNAME")
+		t.Setenv("MAIL_PASSWORD", "MAIL_PASSWORD")
+		t.Setenv("MAIL_SENDER", "MAIL_SENDER")
+		t.Setenv("MAIL_MAX_ATTEMPTS", "MAIL_MAX_ATTEMPTS")
+		t.Setenv("MAIL_RETRY_BASE_DELAY", "MAIL_RETRY_BASE_DELAY")
+		t.Setenv("MAIL_RETRY_MAX_DELAY", "MAIL_RETRY_MAX_DELAY")
+		t.Setenv("BLOB_DIR", "BLOB_DIR")
+		t.Setenv("ATTACHMENT_MAX_SIZE", "ATTACHMENT_MAX_SIZE")
+		t.Setenv("ATTACHMENT_MAX_TOTAL_SIZE", "ATTACHMENT_MAX_TOTAL_SIZE")
+		t.Setenv("TOKEN_SECRET", "TOKEN_SECRET")
+		t.Setenv("CONFIRM_TTL", "CONFIRM_TTL")
+		t.Setenv("PENDING_CLEANUP_INTERVAL", "PENDING_CLEANUP_INTERVAL")
+		t.Setenv("TEMPLATE_DIR", "TEMPLATE_DIR")
+		t.Setenv("LOCALE_DIR", "LOCALE_DIR")
+		t.Setenv("JWT_ALGORITHM", "JWT_ALGORITHM")
+		t.Setenv("JWT_SECRET", "JWT_SECRET")
+		t.Setenv("JWT_PUBLIC_KEY_FILE", "JWT_PUBLIC_KEY_FILE")
+		t.Setenv("JWT_ISSUER", "JWT_ISSUER")
+		t.Setenv("JWT_AUDIENCE", "JWT_AUDIENCE")
+		t.Setenv("RATE_LIMIT_IP_REQUESTS", "RATE_LIMIT_IP_REQUESTS")
+		t.Setenv("RATE_LIMIT_IP_PERIOD", "RATE_LIMIT_IP_PERIOD")
+		t.Setenv("RATE_LIMIT_EMAIL_REQUESTS", "RATE_LIMIT_EMAIL_REQUESTS")
+		t.Setenv("RATE_LIMIT_EMAIL_PERIOD", "RATE_LIMIT_EMAIL_PERIOD")
+		t.Setenv("RATE_LIMIT_TRUST_PROXY", "RATE_LIMIT_TRUST_PROXY")
+		t.Setenv("VERIFICATION_HONEYPOT", "VERIFICATION_HONEYPOT")
+		t.Setenv("VERIFICATION_POW_DIFFICULTY", "VERIFICATION_POW_DIFFICULTY")
+		t.Setenv("VERIFICATION_POW_TTL", "VERIFICATION_POW_TTL")
 
 		resNew := config.New()
 
@@ -38,5 +68,61 @@ func TestConfig_New(t *testing.T) {
 		assert.Equal(t, "ELS_INDEX", resNew.ELSIndex)
 		assert.Equal(t, "", resNew.Stage)
 		assert.Equal(t, "HOST_WEB", resNew.Origin)
+		assert.Equal(t, "API_URL", resNew.APIURL)
+		assert.Equal(t, "MAIL_HOST", resNew.MailHost)
+		assert.Equal(t, "MAIL_PORT", resNew.MailPort)
+		assert.Equal(t, "MAIL_USERNAME", resNew.MailUsername)
+		assert.Equal(t, "MAIL_PASSWORD", resNew.MailPassword)
+		assert.Equal(t, "MAIL_SENDER", resNew.MailSender)
+		assert.Equal(t, "MAIL_MAX_ATTEMPTS", resNew.MailMaxAttempts)
+		assert.Equal(t, "MAIL_RETRY_BASE_DELAY", resNew.MailRetryBaseDelay)
+		assert.Equal(t, "MAIL_RETRY_MAX_DELAY", resNew.MailRetryMaxDelay)
+		assert.Equal(t, "BLOB_DIR", resNew.BlobDir)
+		assert.Equal(t, "ATTACHMENT_MAX_SIZE", resNew.AttachmentMaxSize)
+		assert.Equal(t, "ATTACHMENT_MAX_TOTAL_SIZE", resNew.AttachmentMaxTotalSize)
+		assert.Equal(t, "TOKEN_SECRET", resNew.TokenSecret)
+		assert.Equal(t, "CONFIRM_TTL", resNew.ConfirmTTL)
+		assert.Equal(t, "PENDING_CLEANUP_INTERVAL", resNew.PendingCleanupInterval)
+		assert.Equal(t, "TEMPLATE_DIR", resNew.TemplateDir)
+		assert.Equal(t, "LOCALE_DIR", resNew.LocaleDir)
+		assert.Equal(t, "JWT_ALGORITHM", resNew.JWTAlgorithm)
+		assert.Equal(t, "JWT_SECRET", resNew.JWTSecret)
+		assert.Equal(t, "JWT_PUBLIC_KEY_FILE", resNew.JWTPublicKeyFile)
+		assert.Equal(t, "JWT_ISSUER", resNew.JWTIssuer)
+		assert.Equal(t, "JWT_AUDIENCE", resNew.JWTAudience)
+		assert.Equal(t, "RATE_LIMIT_IP_REQUESTS", resNew.RateLimitIPRequests)
+		assert.Equal(t, "RATE_LIMIT_IP_PERIOD", resNew.RateLimitIPPeriod)
+		assert.Equal(t, "RATE_LIMIT_EMAIL_REQUESTS", resNew.RateLimitEmailRequests)
+		assert.Equal(t, "RATE_LIMIT_EMAIL_PERIOD", resNew.RateLimitEmailPeriod)
+		assert.Equal(t, "RATE_LIMIT_TRUST_PROXY", resNew.RateLimitTrustProxy)
+		assert.Equal(t, "VERIFICATION_HONEYPOT", resNew.VerificationHoneypot)
+		assert.Equal(t, "VERIFICATION_POW_DIFFICULTY", resNew.VerificationPoWBits)
+		assert.Equal(t, "VERIFICATION_POW_TTL", resNew.VerificationPoWTTL)
+	})
+
+	t.Run("should return default value when env is not set", func(t *testing.T) {
+		resNew := config.New()
+
+		assert.Equal(t, "http://localhost:8000", resNew.APIURL)
+		assert.Equal(t, "587", resNew.MailPort)
+		assert.Equal(t, "3", resNew.MailMaxAttempts)
+		assert.Equal(t, "1s", resNew.MailRetryBaseDelay)
+		assert.Equal(t, "30s", resNew.MailRetryMaxDelay)
+		assert.Equal(t, "blobs", resNew.BlobDir)
+		assert.Equal(t, "5242880", resNew.AttachmentMaxSize)
+		assert.Equal(t, "10485760", resNew.AttachmentMaxTotalSize)
+		assert.Equal(t, "24h", resNew.ConfirmTTL)
+		assert.Equal(t, "1h", resNew.PendingCleanupInterval)
+		assert.Equal(t, "templates", resNew.TemplateDir)
+		assert.Equal(t, "locales", resNew.LocaleDir)
+		assert.Equal(t, "HS256", resNew.JWTAlgorithm)
+		assert.Equal(t, "10", resNew.RateLimitIPRequests)
+		assert.Equal(t, "1m", resNew.RateLimitIPPeriod)
+		assert.Equal(t, "3", resNew.RateLimitEmailRequests)
+		assert.Equal(t, "1h", resNew.RateLimitEmailPeriod)
+		assert.Equal(t, "false", resNew.RateLimitTrustProxy)
+		assert.Equal(t, "true", resNew.VerificationHoneypot)
+		assert.Equal(t, "0", resNew.VerificationPoWBits)
+		assert.Equal(t, "5m", resNew.VerificationPoWTTL)
 	})
 }
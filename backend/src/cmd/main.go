@@ -61,10 +61,12 @@ func main() {
 		fmt.Println("Connect ELS Success", *logs)
 	}
 
+	stop := make(chan struct{})
 	routerConfig := router.RouterConfig{
 		DB:     dbConnection,
 		Logs:   logs,
 		Config: config,
+		Stop:   stop,
 	}
 
 	routers := router.InitRouter(routerConfig)
@@ -76,6 +78,7 @@ func main() {
 
 	fmt.Println("The service is shutting down...")
 
+	close(stop)
 	forceShutdownAfter(server, time.Second*30)
 
 	fmt.Println("terminated...")
@@ -0,0 +1,147 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	apikeys "newsletter/src/pkg/apikeys"
+	entity "newsletter/src/pkg/entity"
+
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Authenticate provides a mock function with given fields: key
+func (_m *UseCase) Authenticate(key string) (*entity.APIKeys, *error.ErrorCode) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authenticate")
+	}
+
+	var r0 *entity.APIKeys
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (*entity.APIKeys, *error.ErrorCode)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) *entity.APIKeys); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.APIKeys)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(key)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with no fields
+func (_m *UseCase) GetAll() ([]entity.APIKeys, *error.ErrorCode) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAll")
+	}
+
+	var r0 []entity.APIKeys
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() ([]entity.APIKeys, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.APIKeys); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.APIKeys)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Issue provides a mock function with given fields: apiKey
+func (_m *UseCase) Issue(apiKey entity.APIKeys) (*apikeys.IssuedKey, *error.ErrorCode) {
+	ret := _m.Called(apiKey)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Issue")
+	}
+
+	var r0 *apikeys.IssuedKey
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.APIKeys) (*apikeys.IssuedKey, *error.ErrorCode)); ok {
+		return rf(apiKey)
+	}
+	if rf, ok := ret.Get(0).(func(entity.APIKeys) *apikeys.IssuedKey); ok {
+		r0 = rf(apiKey)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*apikeys.IssuedKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.APIKeys) *error.ErrorCode); ok {
+		r1 = rf(apiKey)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Revoke provides a mock function with given fields: id
+func (_m *UseCase) Revoke(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Revoke")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
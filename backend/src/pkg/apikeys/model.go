@@ -0,0 +1,30 @@
+package apikeys
+
+import (
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/entity"
+)
+
+const (
+	// KeyPrefix marks a string as one of our keys, which makes a leaked key
+	// easy to spot in logs and code.
+	KeyPrefix = "nlk_"
+
+	keyBytes        = 32
+	displayedLength = len(KeyPrefix) + 8
+)
+
+// Scopes are the permissions a key may be issued with. Keys act for a
+// backend, never a person, so the staff-only permissions are left out.
+var Scopes = []auth.Permission{
+	auth.PermissionSubscribersSubscribe,
+	auth.PermissionSubscribersRead,
+	auth.PermissionSubscribersExport,
+}
+
+// IssuedKey is returned once, when a key is issued. Key is never stored, so it
+// cannot be shown again.
+type IssuedKey struct {
+	entity.APIKeys
+	Key string `json:"key"`
+}
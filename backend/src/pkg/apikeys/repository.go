@@ -0,0 +1,208 @@
+package apikeys
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	GetAll() ([]entity.APIKeys, error)
+	FindByID(id int64) ([]entity.APIKeys, error)
+	Insert(apiKey entity.APIKeys) (int64, error)
+	RevokeByID(id int64) (int64, error)
+	UseByHash(keyHash string) ([]entity.APIKeys, error)
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) GetAll() ([]entity.APIKeys, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	ORDER BY Id DESC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.APIKeys{}, []string{}),
+		repo.Collection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "apikeys_Repo_GetAll", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows), nil
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.APIKeys, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Id = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.APIKeys{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "apikeys_Repo_FindByID", id, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows), nil
+}
+
+func (repo *SqlRepository) Insert(apiKey entity.APIKeys) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "UsageCount", "LastUsedDate", "CreatedDate", "RevokedDate"}
+	params, args := sqlQuery.GenerateQueryColumnParams(apiKey, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[UsageCount],
+		[CreatedDate]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		0,
+		GETDATE()
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.APIKeys{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		// The hash is left out so a leaked log line cannot be matched to a key.
+		apiKey.KeyHash = ""
+		go repo.Logs.Error("", "apikeys_Repo_Insert", apiKey,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// RevokeByID stamps RevokedDate on a key that is still active and returns the
+// number of rows changed, so revoking twice reports no rows.
+func (repo *SqlRepository) RevokeByID(id int64) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET RevokedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND RevokedDate IS NULL
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	result, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "apikeys_Repo_RevokeByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// UseByHash finds the active key with keyHash and counts the request against
+// it in the same statement, so concurrent requests never lose an increment.
+func (repo *SqlRepository) UseByHash(keyHash string) ([]entity.APIKeys, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		UsageCount = UsageCount + 1,
+		LastUsedDate = GETDATE()
+	OUTPUT %[2]s
+	WHERE KeyHash = %[3]s
+	AND RevokedDate IS NULL
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.APIKeys{}, []string{}, "INSERTED"),
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, keyHash)
+	if err != nil {
+		go repo.Logs.Error("", "apikeys_Repo_UseByHash", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	return scanAPIKeys(rows), nil
+}
+
+func scanAPIKeys(rows *sql.Rows) []entity.APIKeys {
+	list := []entity.APIKeys{}
+	for rows.Next() {
+		var entity entity.APIKeys
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list
+}
@@ -0,0 +1,99 @@
+package apikeys_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/entity"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var apiKeyColumns = []string{"id", "name", "prefix", "scopes", "usageCount"}
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *apikeys.SqlRepository) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	return mockDB, apikeys.NewRepository("TB_MAS_ApiKeys", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should insert hash and return new id", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`INSERT INTO TB_MAS_ApiKeys(.|\s)+OUTPUT INSERTED.Id`).
+			WithArgs("marketing", "nlk_1234abcd", "hash", "subscribers:subscribe", "admin").
+			WillReturnRows(sqlmock.NewRows([]string{"Id"}).AddRow(5))
+
+		id, err := repo.Insert(entity.APIKeys{
+			Name:      "marketing",
+			Prefix:    "nlk_1234abcd",
+			KeyHash:   "hash",
+			Scopes:    "subscribers:subscribe",
+			CreatedBy: "admin",
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_RevokeByID(t *testing.T) {
+	t.Run("should only revoke active key", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`SET RevokedDate = GETDATE\(\)\s+WHERE Id = @p1\s+AND RevokedDate IS NULL`).
+			WithArgs(int64(5)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		rows, err := repo.RevokeByID(5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), rows)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec("RevokedDate").WillReturnError(errors.New("Error"))
+
+		rows, err := repo.RevokeByID(5)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), rows)
+	})
+}
+
+func TestRepository_UseByHash(t *testing.T) {
+	t.Run("should count usage and return active key in one statement", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`UsageCount = UsageCount \+ 1,\s+LastUsedDate = GETDATE\(\)\s+OUTPUT INSERTED.\[id\](.|\s)+WHERE KeyHash = @p1\s+AND RevokedDate IS NULL`).
+			WithArgs("hash").
+			WillReturnRows(sqlmock.NewRows(apiKeyColumns).AddRow(5, "marketing", "nlk_1234abcd", "subscribers:subscribe", 3))
+
+		res, err := repo.UseByHash("hash")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.APIKeys{{ID: 5, Name: "marketing", Prefix: "nlk_1234abcd", Scopes: "subscribers:subscribe", UsageCount: 3}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("KeyHash = @p1").WillReturnError(errors.New("Error"))
+
+		res, err := repo.UseByHash("hash")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
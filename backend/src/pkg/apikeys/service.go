@@ -0,0 +1,185 @@
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/validation"
+	"strings"
+)
+
+type UseCase interface {
+	GetAll() ([]entity.APIKeys, *newsletterError.ErrorCode)
+	Issue(apiKey entity.APIKeys) (*IssuedKey, *newsletterError.ErrorCode)
+	Revoke(id int64) *newsletterError.ErrorCode
+	Authenticate(key string) (*entity.APIKeys, *newsletterError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo Repository
+	Logs logger.Logger
+}
+
+func NewService(repo Repository, logs logger.Logger) *Service {
+	service := &Service{
+		Repo: repo,
+		Logs: logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+// HashKey returns the hex SHA-256 of key. Keys are long random strings, so a
+// fast unsalted hash is enough to make a copy of the table useless.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// HasScope reports whether apiKey was issued with permission.
+func HasScope(apiKey entity.APIKeys, permission auth.Permission) bool {
+	for _, scope := range strings.Fields(apiKey.Scopes) {
+		if auth.Permission(scope) == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func (service *Service) GetAll() ([]entity.APIKeys, *newsletterError.ErrorCode) {
+	res, err := service.Repo.GetAll()
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+// Issue creates a key named apiKey.Name with apiKey.Scopes and returns it with
+// the plain key, which is the only time the key leaves the service.
+func (service *Service) Issue(apiKey entity.APIKeys) (*IssuedKey, *newsletterError.ErrorCode) {
+	name, errName := validation.RequiredText(apiKey.Name, validation.MaxLength)
+	if errName != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	scopes, errScopes := normalizeScopes(apiKey.Scopes)
+	if errScopes != nil {
+		return nil, errScopes
+	}
+
+	key, errKey := generateKey()
+	if errKey != nil {
+		go service.Logs.Error("", "apikeys_Service_Issue_GenerateKey", name, newsletterError.NewError(newsletterError.TechnicalError, errKey.Error()))
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	newKey := entity.APIKeys{
+		Name:      name,
+		Prefix:    key[:displayedLength],
+		KeyHash:   HashKey(key),
+		Scopes:    scopes,
+		CreatedBy: apiKey.CreatedBy,
+	}
+
+	id, err := service.Repo.Insert(newKey)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	res, err := service.Repo.FindByID(id)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &IssuedKey{APIKeys: res[0], Key: key}, nil
+}
+
+func (service *Service) Revoke(id int64) *newsletterError.ErrorCode {
+	rows, err := service.Repo.RevokeByID(id)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if rows == 0 {
+		return convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return nil
+}
+
+// Authenticate returns the active key matching key and counts the request
+// against it. Unknown and revoked keys are both unauthorized.
+func (service *Service) Authenticate(key string) (*entity.APIKeys, *newsletterError.ErrorCode) {
+	if !strings.HasPrefix(key, KeyPrefix) {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized)
+	}
+
+	res, err := service.Repo.UseByHash(HashKey(key))
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized)
+	}
+
+	return &res[0], nil
+}
+
+func generateKey() (string, error) {
+	buffer := make([]byte, keyBytes)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return KeyPrefix + hex.EncodeToString(buffer), nil
+}
+
+// normalizeScopes checks every space separated scope is one a key may hold
+// and returns them deduplicated in the order of Scopes.
+func normalizeScopes(scopes string) (string, *newsletterError.ErrorCode) {
+	requested := strings.Fields(scopes)
+	if len(requested) == 0 {
+		return "", convert.ValueToErrorCodePointer(newsletterError.InvalidAPIKeyScope)
+	}
+
+	for _, scope := range requested {
+		if !isScope(auth.Permission(scope)) {
+			return "", convert.ValueToErrorCodePointer(newsletterError.InvalidAPIKeyScope)
+		}
+	}
+
+	normalized := []string{}
+	for _, scope := range Scopes {
+		for _, wanted := range requested {
+			if auth.Permission(wanted) == scope {
+				normalized = append(normalized, string(scope))
+				break
+			}
+		}
+	}
+	return strings.Join(normalized, " "), nil
+}
+
+func isScope(permission auth.Permission) bool {
+	for _, scope := range Scopes {
+		if scope == permission {
+			return true
+		}
+	}
+	return false
+}
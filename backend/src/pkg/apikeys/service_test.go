@@ -0,0 +1,275 @@
+package apikeys_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/apikeys/mocks"
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository *mocks.Repository
+	service    *apikeys.Service
+	logs       *loggerMocks.Logger
+
+	mockRepoGetAll     *mocker.MockCall
+	mockRepoFindByID   *mocker.MockCall
+	mockRepoInsert     *mocker.MockCall
+	mockRepoRevokeByID *mocker.MockCall
+	mockRepoUseByHash  *mocker.MockCall
+)
+
+func callRepoGetAll() *mock.Call {
+	return repository.On("GetAll")
+}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoRevokeByID() *mock.Call {
+	return repository.On("RevokeByID", mock.Anything)
+}
+
+func callRepoUseByHash() *mock.Call {
+	return repository.On("UseByHash", mock.Anything)
+}
+
+func beforeEach() {
+	repository = &mocks.Repository{}
+	logs = &loggerMocks.Logger{}
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = apikeys.NewService(repository, logs)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct api keys service when call new service", func(t *testing.T) {
+		beforeEach()
+
+		expectedService := &apikeys.Service{
+			Repo: repository,
+			Logs: logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, service)
+	})
+}
+
+func TestService_GetAll(t *testing.T) {
+	t.Run("should return data not found when there is no key", func(t *testing.T) {
+		beforeEach()
+		mockRepoGetAll = mocker.NewMockCall(callRepoGetAll)
+		mockRepoGetAll.Return([]entity.APIKeys{}, nil)
+
+		res, err := service.GetAll()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return keys when found", func(t *testing.T) {
+		beforeEach()
+		mockRepoGetAll = mocker.NewMockCall(callRepoGetAll)
+		mockRepoGetAll.Return([]entity.APIKeys{{ID: 1}}, nil)
+
+		res, err := service.GetAll()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.APIKeys{{ID: 1}}, res)
+	})
+}
+
+func TestService_Issue(t *testing.T) {
+	beforeEachIssue := func() {
+		beforeEach()
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(5), nil)
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.APIKeys{{ID: 5, Name: "marketing"}}, nil)
+	}
+
+	t.Run("should store only hash of generated key and return key once", func(t *testing.T) {
+		beforeEachIssue()
+
+		res, err := service.Issue(entity.APIKeys{Name: " marketing ", Scopes: "subscribers:subscribe", CreatedBy: "admin"})
+
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(res.Key, apikeys.KeyPrefix))
+		assert.Equal(t, int64(5), res.ID)
+		repository.AssertCalled(t, "Insert", entity.APIKeys{
+			Name:      "marketing",
+			Prefix:    res.Key[:12],
+			KeyHash:   apikeys.HashKey(res.Key),
+			Scopes:    "subscribers:subscribe",
+			CreatedBy: "admin",
+		})
+		repository.AssertCalled(t, "FindByID", int64(5))
+	})
+
+	t.Run("should issue a different key every time", func(t *testing.T) {
+		beforeEachIssue()
+
+		first, _ := service.Issue(entity.APIKeys{Name: "marketing", Scopes: "subscribers:subscribe"})
+		second, _ := service.Issue(entity.APIKeys{Name: "marketing", Scopes: "subscribers:subscribe"})
+
+		assert.NotEqual(t, first.Key, second.Key)
+	})
+
+	t.Run("should deduplicate and order scopes", func(t *testing.T) {
+		beforeEachIssue()
+
+		service.Issue(entity.APIKeys{Name: "partner", Scopes: "subscribers:read  subscribers:subscribe subscribers:read"})
+
+		repository.AssertCalled(t, "Insert", mock.MatchedBy(func(apiKey entity.APIKeys) bool {
+			return apiKey.Scopes == "subscribers:subscribe subscribers:read"
+		}))
+	})
+
+	t.Run("should return invalid api key scope when scope is not allowed for keys", func(t *testing.T) {
+		beforeEachIssue()
+
+		res, err := service.Issue(entity.APIKeys{Name: "partner", Scopes: "subscribers:subscribe apikeys:manage"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidAPIKeyScope), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return invalid api key scope when scopes are empty", func(t *testing.T) {
+		beforeEachIssue()
+
+		_, err := service.Issue(entity.APIKeys{Name: "partner"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidAPIKeyScope), err)
+	})
+
+	t.Run("should return bad request when name is empty", func(t *testing.T) {
+		beforeEachIssue()
+
+		_, err := service.Issue(entity.APIKeys{Name: "  ", Scopes: "subscribers:subscribe"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+	})
+
+	t.Run("should return internal server error when insert failed", func(t *testing.T) {
+		beforeEachIssue()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		res, err := service.Issue(entity.APIKeys{Name: "marketing", Scopes: "subscribers:subscribe"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Revoke(t *testing.T) {
+	beforeEachRevoke := func() {
+		beforeEach()
+		mockRepoRevokeByID = mocker.NewMockCall(callRepoRevokeByID)
+		mockRepoRevokeByID.Return(int64(1), nil)
+	}
+
+	t.Run("should return nil when key was revoked", func(t *testing.T) {
+		beforeEachRevoke()
+
+		err := service.Revoke(5)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "RevokeByID", int64(5))
+	})
+
+	t.Run("should return data not found when key is missing or already revoked", func(t *testing.T) {
+		beforeEachRevoke()
+		mockRepoRevokeByID.Return(int64(0), nil)
+
+		err := service.Revoke(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+
+	t.Run("should return internal server error when revoke failed", func(t *testing.T) {
+		beforeEachRevoke()
+		mockRepoRevokeByID.Return(int64(0), errors.New("Error"))
+
+		err := service.Revoke(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Authenticate(t *testing.T) {
+	beforeEachAuthenticate := func() {
+		beforeEach()
+		mockRepoUseByHash = mocker.NewMockCall(callRepoUseByHash)
+		mockRepoUseByHash.Return([]entity.APIKeys{{ID: 5, Scopes: "subscribers:subscribe"}}, nil)
+	}
+
+	t.Run("should look up key by hash", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		res, err := service.Authenticate("nlk_secret")
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(5), res.ID)
+		repository.AssertCalled(t, "UseByHash", apikeys.HashKey("nlk_secret"))
+	})
+
+	t.Run("should return unauthorized without database call when key has no prefix", func(t *testing.T) {
+		beforeEachAuthenticate()
+
+		res, err := service.Authenticate("secret")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.Unauthorized), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "UseByHash", mock.Anything)
+	})
+
+	t.Run("should return unauthorized when key is unknown or revoked", func(t *testing.T) {
+		beforeEachAuthenticate()
+		mockRepoUseByHash.Return([]entity.APIKeys{}, nil)
+
+		res, err := service.Authenticate("nlk_secret")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.Unauthorized), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when lookup failed", func(t *testing.T) {
+		beforeEachAuthenticate()
+		mockRepoUseByHash.Return(nil, errors.New("Error"))
+
+		_, err := service.Authenticate("nlk_secret")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_HasScope(t *testing.T) {
+	apiKey := entity.APIKeys{Scopes: "subscribers:subscribe subscribers:read"}
+
+	t.Run("should grant scopes key was issued with", func(t *testing.T) {
+		assert.True(t, apikeys.HasScope(apiKey, auth.PermissionSubscribersSubscribe))
+		assert.True(t, apikeys.HasScope(apiKey, auth.PermissionSubscribersRead))
+	})
+
+	t.Run("should deny other scopes", func(t *testing.T) {
+		assert.False(t, apikeys.HasScope(apiKey, auth.PermissionSubscribersExport))
+		assert.False(t, apikeys.HasScope(entity.APIKeys{}, auth.PermissionSubscribersSubscribe))
+	})
+}
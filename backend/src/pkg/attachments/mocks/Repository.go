@@ -0,0 +1,134 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByCampaignID provides a mock function with given fields: campaignID
+func (_m *Repository) FindByCampaignID(campaignID int64) ([]entity.Attachments, error) {
+	ret := _m.Called(campaignID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByCampaignID")
+	}
+
+	var r0 []entity.Attachments
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Attachments, error)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Attachments); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(campaignID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.Attachments, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 []entity.Attachments
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Attachments, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Attachments); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: attachment
+func (_m *Repository) Insert(attachment entity.Attachments) (int64, error) {
+	ret := _m.Called(attachment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.Attachments) (int64, error)); ok {
+		return rf(attachment)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Attachments) int64); ok {
+		r0 = rf(attachment)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Attachments) error); ok {
+		r1 = rf(attachment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,181 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	attachments "newsletter/src/pkg/attachments"
+	email "newsletter/src/pkg/email"
+
+	entity "newsletter/src/pkg/entity"
+
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *UseCase) Delete(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.Attachments, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *entity.Attachments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.Attachments, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.Attachments); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetByCampaignID provides a mock function with given fields: campaignID
+func (_m *UseCase) GetByCampaignID(campaignID int64) ([]entity.Attachments, *error.ErrorCode) {
+	ret := _m.Called(campaignID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetByCampaignID")
+	}
+
+	var r0 []entity.Attachments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Attachments, *error.ErrorCode)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Attachments); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(campaignID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Load provides a mock function with given fields: campaignID
+func (_m *UseCase) Load(campaignID int64) ([]email.Attachment, *error.ErrorCode) {
+	ret := _m.Called(campaignID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Load")
+	}
+
+	var r0 []email.Attachment
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]email.Attachment, *error.ErrorCode)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []email.Attachment); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]email.Attachment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(campaignID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Upload provides a mock function with given fields: upload
+func (_m *UseCase) Upload(upload attachments.Upload) (*entity.Attachments, *error.ErrorCode) {
+	ret := _m.Called(upload)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upload")
+	}
+
+	var r0 *entity.Attachments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(attachments.Upload) (*entity.Attachments, *error.ErrorCode)); ok {
+		return rf(upload)
+	}
+	if rf, ok := ret.Get(0).(func(attachments.Upload) *entity.Attachments); ok {
+		r0 = rf(upload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(attachments.Upload) *error.ErrorCode); ok {
+		r1 = rf(upload)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,29 @@
+package attachments
+
+import "regexp"
+
+const (
+	MaxFileNameLength  = 255
+	MaxContentIDLength = 255
+)
+
+// contentIDPattern keeps content IDs usable in a cid: URL without escaping.
+var contentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Limits bound the files of one campaign, in bytes. MaxFileSize applies to
+// each file and MaxTotalSize to all of them together, since every message
+// carries them all. A limit of zero is not enforced.
+type Limits struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
+
+// Upload is a file to add to a campaign. Giving it a ContentID makes it an
+// inline image rather than an attachment.
+type Upload struct {
+	CampaignID  int64
+	FileName    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
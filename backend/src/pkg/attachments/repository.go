@@ -0,0 +1,150 @@
+package attachments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByID(id int64) ([]entity.Attachments, error)
+	FindByCampaignID(campaignID int64) ([]entity.Attachments, error)
+	Insert(attachment entity.Attachments) (int64, error)
+	DeleteByID(id int64) error
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Attachments, error) {
+	return repo.findBy("Id", id, "attachments_Repo_FindByID")
+}
+
+func (repo *SqlRepository) FindByCampaignID(campaignID int64) ([]entity.Attachments, error) {
+	return repo.findBy("CampaignId", campaignID, "attachments_Repo_FindByCampaignID")
+}
+
+func (repo *SqlRepository) findBy(column string, value interface{}, actionName string) ([]entity.Attachments, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND %[3]s = %[4]s
+	ORDER BY Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Attachments{}, []string{}),
+		repo.Collection,
+		column,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, value)
+	if err != nil {
+		go repo.Logs.Error("", actionName, value, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Attachments{}
+	for rows.Next() {
+		var entity entity.Attachments
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Insert(attachment entity.Attachments) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "CreatedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(attachment, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.Attachments{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "attachments_Repo_Insert", attachment.FileName,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (repo *SqlRepository) DeleteByID(id int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Delflag = 1
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "attachments_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package attachments_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/attachments"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *attachments.SqlRepository) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	return mockDB, attachments.NewRepository("TB_TRN_CampaignAttachments", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should insert attachment and return new id", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		contentID := "logo"
+		sqlMock.ExpectQuery(`INSERT INTO TB_TRN_CampaignAttachments(.|\s)+OUTPUT INSERTED.Id`).
+			WithArgs(int64(7), "logo.png", "image/png", int64(3), "key", &contentID).
+			WillReturnRows(sqlmock.NewRows([]string{"Id"}).AddRow(4))
+
+		id, err := repo.Insert(entity.Attachments{CampaignID: 7, FileName: "logo.png", ContentType: "image/png", Size: 3, StorageKey: "key", ContentID: &contentID})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when insert failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("INSERT INTO").WillReturnError(errors.New("Error"))
+
+		_, err := repo.Insert(entity.Attachments{CampaignID: 7})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_FindByCampaignID(t *testing.T) {
+	t.Run("should only find attachments that are not deleted in upload order", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`FROM TB_TRN_CampaignAttachments\s+WHERE Delflag = 0\s+AND CampaignId = @p1\s+ORDER BY Id ASC`).
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "campaignId", "fileName", "contentId"}).AddRow(4, 7, "logo.png", "logo"))
+
+		res, err := repo.FindByCampaignID(7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Attachments{{ID: 4, CampaignID: 7, FileName: "logo.png", ContentID: convert.ValueToStringPointer("logo")}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_DeleteByID(t *testing.T) {
+	t.Run("should flag attachment as deleted", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`UPDATE TB_TRN_CampaignAttachments\s+SET\s+Delflag = 1\s+WHERE Id = @p1`).
+			WithArgs(int64(4)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.DeleteByID(4)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,224 @@
+package attachments
+
+import (
+	"mime"
+	"net/http"
+	"newsletter/src/pkg/email"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/blobstore"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"path"
+	"strings"
+)
+
+type UseCase interface {
+	FindByID(id int64) (*entity.Attachments, *newsletterError.ErrorCode)
+	GetByCampaignID(campaignID int64) ([]entity.Attachments, *newsletterError.ErrorCode)
+	Upload(upload Upload) (*entity.Attachments, *newsletterError.ErrorCode)
+	Delete(id int64) *newsletterError.ErrorCode
+	Load(campaignID int64) ([]email.Attachment, *newsletterError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo   Repository
+	Store  blobstore.Store
+	Limits Limits
+	Logs   logger.Logger
+}
+
+func NewService(repo Repository, store blobstore.Store, limits Limits, logs logger.Logger) *Service {
+	service := &Service{
+		Repo:   repo,
+		Store:  store,
+		Limits: limits,
+		Logs:   logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) FindByID(id int64) (*entity.Attachments, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+func (service *Service) GetByCampaignID(campaignID int64) ([]entity.Attachments, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignID(campaignID)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+// Upload checks upload against the limits, stores its content and records
+// it. Only the base name of FileName is kept, and a missing content type is
+// worked out from the name or the content.
+func (service *Service) Upload(upload Upload) (*entity.Attachments, *newsletterError.ErrorCode) {
+	fileName := path.Base(strings.ReplaceAll(strings.TrimSpace(upload.FileName), `\`, "/"))
+	if fileName == "." || fileName == "/" || len(fileName) > MaxFileNameLength || len(upload.Data) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	size := int64(len(upload.Data))
+	if service.Limits.MaxFileSize > 0 && size > service.Limits.MaxFileSize {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge)
+	}
+
+	contentType, ok := detectContentType(fileName, upload.ContentType, upload.Data)
+	if !ok {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	contentID := strings.TrimSpace(upload.ContentID)
+	if contentID != "" {
+		if len(contentID) > MaxContentIDLength || !contentIDPattern.MatchString(contentID) || !strings.HasPrefix(contentType, "image/") {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+		}
+	}
+
+	resExisting, errExisting := service.Repo.FindByCampaignID(upload.CampaignID)
+	if errExisting != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	total := size
+	for _, existing := range resExisting {
+		total += existing.Size
+		if contentID != "" && existing.ContentID != nil && *existing.ContentID == contentID {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.DuplicateContentID)
+		}
+	}
+	if service.Limits.MaxTotalSize > 0 && total > service.Limits.MaxTotalSize {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge)
+	}
+
+	key, errKey := blobstore.NewKey()
+	if errKey != nil {
+		go service.Logs.Error("", "attachments_Service_Upload_NewKey", fileName, errKey.Error())
+		return nil, convert.ValueToErrorCodePointer(newsletterError.UploadFailed)
+	}
+
+	errPut := service.Store.Put(key, upload.Data)
+	if errPut != nil {
+		go service.Logs.Error("", "attachments_Service_Upload_Put", fileName, errPut.Error())
+		return nil, convert.ValueToErrorCodePointer(newsletterError.UploadFailed)
+	}
+
+	attachment := entity.Attachments{
+		CampaignID:  upload.CampaignID,
+		FileName:    fileName,
+		ContentType: contentType,
+		Size:        size,
+		StorageKey:  key,
+	}
+	if contentID != "" {
+		attachment.ContentID = &contentID
+	}
+
+	id, errInsert := service.Repo.Insert(attachment)
+	if errInsert != nil {
+		service.Store.Delete(key)
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.UseCase.FindByID(id)
+}
+
+// Delete removes the record first, so a blob that fails to delete is only
+// left unreferenced.
+func (service *Service) Delete(id int64) *newsletterError.ErrorCode {
+	resAttachment, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errDelete := service.Repo.DeleteByID(id)
+	if errDelete != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if errStore := service.Store.Delete(resAttachment.StorageKey); errStore != nil {
+		go service.Logs.Error("", "attachments_Service_Delete_Store", id, errStore.Error())
+	}
+
+	return nil
+}
+
+// Load reads the files of a campaign ready to send. The limits are checked
+// again, as they may have been lowered since the files were uploaded. A
+// campaign without files loads none.
+func (service *Service) Load(campaignID int64) ([]email.Attachment, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignID(campaignID)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	total := int64(0)
+	for _, attachment := range res {
+		if service.Limits.MaxFileSize > 0 && attachment.Size > service.Limits.MaxFileSize {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge)
+		}
+		total += attachment.Size
+	}
+	if service.Limits.MaxTotalSize > 0 && total > service.Limits.MaxTotalSize {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge)
+	}
+
+	list := []email.Attachment{}
+	for _, attachment := range res {
+		data, errGet := service.Store.Get(attachment.StorageKey)
+		if errGet != nil {
+			go service.Logs.Error("", "attachments_Service_Load_Get", attachment.ID, errGet.Error())
+			return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		}
+
+		loaded := email.Attachment{
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Data:        data,
+		}
+		if attachment.ContentID != nil {
+			loaded.ContentID = *attachment.ContentID
+		}
+		list = append(list, loaded)
+	}
+
+	return list, nil
+}
+
+// detectContentType returns the media type of a file, without parameters.
+// A type that is missing or generic is taken from the file extension, then
+// from the content itself.
+func detectContentType(fileName string, contentType string, data []byte) (string, bool) {
+	contentType = strings.TrimSpace(contentType)
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = mime.TypeByExtension(path.Ext(fileName))
+	}
+	if contentType == "" {
+		contentType = http.DetectContentType(data)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.Contains(mediaType, "/") {
+		return "", false
+	}
+	return mediaType, true
+}
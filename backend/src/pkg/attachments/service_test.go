@@ -0,0 +1,357 @@
+package attachments_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/attachments"
+	"newsletter/src/pkg/attachments/mocks"
+	"newsletter/src/pkg/email"
+	"newsletter/src/pkg/entity"
+	blobMocks "newsletter/src/pkg/utils/blobstore/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase *mocks.UseCase
+	repository  *mocks.Repository
+	store       *blobMocks.Store
+	service     *attachments.Service
+	logs        *loggerMocks.Logger
+
+	mockRepoFindByID         *mocker.MockCall
+	mockRepoFindByCampaignID *mocker.MockCall
+	mockRepoInsert           *mocker.MockCall
+	mockRepoDeleteByID       *mocker.MockCall
+	mockStorePut             *mocker.MockCall
+	mockStoreGet             *mocker.MockCall
+	mockStoreDelete          *mocker.MockCall
+	mockServiceFindByID      *mocker.MockCall
+)
+
+var limits = attachments.Limits{MaxFileSize: 10, MaxTotalSize: 15}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoFindByCampaignID() *mock.Call {
+	return repository.On("FindByCampaignID", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callStorePut() *mock.Call {
+	return store.On("Put", mock.Anything, mock.Anything)
+}
+
+func callStoreGet() *mock.Call {
+	return store.On("Get", mock.Anything)
+}
+
+func callStoreDelete() *mock.Call {
+	return store.On("Delete", mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	store = &blobMocks.Store{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &attachments.Service{
+		Repo:   repository,
+		Store:  store,
+		Limits: limits,
+		Logs:   logs,
+	}
+	service.UseCase = mockUseCase
+
+	mockRepoFindByCampaignID = mocker.NewMockCall(callRepoFindByCampaignID)
+	mockRepoFindByCampaignID.Return([]entity.Attachments{}, nil)
+	mockStorePut = mocker.NewMockCall(callStorePut)
+	mockStorePut.Return(nil)
+	mockStoreGet = mocker.NewMockCall(callStoreGet)
+	mockStoreGet.Return([]byte("data"), nil)
+	mockStoreDelete = mocker.NewMockCall(callStoreDelete)
+	mockStoreDelete.Return(nil)
+	mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+	mockServiceFindByID.Return(&entity.Attachments{ID: 4, CampaignID: 7, StorageKey: "key"}, nil)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct attachments service when call new service", func(t *testing.T) {
+		beforeEach()
+		resService := attachments.NewService(repository, store, limits, logs)
+
+		expectedService := &attachments.Service{
+			Repo:   repository,
+			Store:  store,
+			Limits: limits,
+			Logs:   logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.Attachments{{ID: 4}}, nil)
+	}
+
+	t.Run("should return attachment when found", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(4)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Attachments{ID: 4}, res)
+	})
+
+	t.Run("should return data not found when there is no attachment", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.Attachments{}, nil)
+
+		_, err := service.FindByID(4)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+}
+
+func TestService_GetByCampaignID(t *testing.T) {
+	t.Run("should return data not found when campaign has no attachment", func(t *testing.T) {
+		beforeEach()
+
+		res, err := service.GetByCampaignID(7)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEach()
+		mockRepoFindByCampaignID.Return(nil, errors.New("Error"))
+
+		_, err := service.GetByCampaignID(7)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Upload(t *testing.T) {
+	beforeEachUpload := func() {
+		beforeEach()
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(4), nil)
+	}
+
+	t.Run("should store content and record base name and detected type", func(t *testing.T) {
+		beforeEachUpload()
+
+		res, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: `C:\docs\report.pdf`, Data: []byte("pdf")})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(4), res.ID)
+		key := store.Calls[0].Arguments.String(0)
+		store.AssertCalled(t, "Put", key, []byte("pdf"))
+		repository.AssertCalled(t, "Insert", entity.Attachments{CampaignID: 7, FileName: "report.pdf", ContentType: "application/pdf", Size: 3, StorageKey: key})
+		mockUseCase.AssertCalled(t, "FindByID", int64(4))
+	})
+
+	t.Run("should record content id of inline image", func(t *testing.T) {
+		beforeEachUpload()
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("png")})
+
+		assert.Nil(t, err)
+		inserted := repository.Calls[1].Arguments.Get(0).(entity.Attachments)
+		assert.Equal(t, convert.ValueToStringPointer("logo"), inserted.ContentID)
+	})
+
+	t.Run("should return bad request when inline file is not an image", func(t *testing.T) {
+		beforeEachUpload()
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf", ContentID: "report", Data: []byte("pdf")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+	})
+
+	t.Run("should return bad request when content id cannot be used in a cid url", func(t *testing.T) {
+		beforeEachUpload()
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "logo.png", ContentID: "my logo", Data: []byte("png")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+	})
+
+	t.Run("should return bad request when file is empty", func(t *testing.T) {
+		beforeEachUpload()
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+	})
+
+	t.Run("should return attachment too large when file is over limit", func(t *testing.T) {
+		beforeEachUpload()
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf", Data: []byte("12345678901")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge), err)
+		store.AssertNotCalled(t, "Put", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return attachment too large when campaign total goes over limit", func(t *testing.T) {
+		beforeEachUpload()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 1, Size: 10}}, nil)
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf", Data: []byte("123456")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge), err)
+	})
+
+	t.Run("should return duplicate content id when campaign already has it", func(t *testing.T) {
+		beforeEachUpload()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 1, Size: 1, ContentID: convert.ValueToStringPointer("logo")}}, nil)
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "logo.png", ContentID: "logo", Data: []byte("png")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DuplicateContentID), err)
+	})
+
+	t.Run("should return upload failed when store failed", func(t *testing.T) {
+		beforeEachUpload()
+		mockStorePut.Return(errors.New("Error"))
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf", Data: []byte("pdf")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.UploadFailed), err)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should remove stored content when insert failed", func(t *testing.T) {
+		beforeEachUpload()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		_, err := service.Upload(attachments.Upload{CampaignID: 7, FileName: "report.pdf", Data: []byte("pdf")})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		store.AssertCalled(t, "Delete", store.Calls[0].Arguments.String(0))
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	beforeEachDelete := func() {
+		beforeEach()
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(nil)
+	}
+
+	t.Run("should delete record then stored content", func(t *testing.T) {
+		beforeEachDelete()
+
+		err := service.Delete(4)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "DeleteByID", int64(4))
+		store.AssertCalled(t, "Delete", "key")
+	})
+
+	t.Run("should not return error when only stored content failed to delete", func(t *testing.T) {
+		beforeEachDelete()
+		mockStoreDelete.Return(errors.New("Error"))
+
+		err := service.Delete(4)
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should keep stored content when delete record failed", func(t *testing.T) {
+		beforeEachDelete()
+		mockRepoDeleteByID.Return(errors.New("Error"))
+
+		err := service.Delete(4)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		store.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("should return data not found when there is no attachment", func(t *testing.T) {
+		beforeEachDelete()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Delete(4)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+}
+
+func TestService_Load(t *testing.T) {
+	t.Run("should return files of campaign with their content", func(t *testing.T) {
+		beforeEach()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{
+			{ID: 1, FileName: "logo.png", ContentType: "image/png", Size: 4, StorageKey: "logo-key", ContentID: convert.ValueToStringPointer("logo")},
+			{ID: 2, FileName: "report.pdf", ContentType: "application/pdf", Size: 4, StorageKey: "report-key"},
+		}, nil)
+
+		res, err := service.Load(7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []email.Attachment{
+			{FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("data")},
+			{FileName: "report.pdf", ContentType: "application/pdf", Data: []byte("data")},
+		}, res)
+		store.AssertCalled(t, "Get", "logo-key")
+		store.AssertCalled(t, "Get", "report-key")
+	})
+
+	t.Run("should return no file when campaign has none", func(t *testing.T) {
+		beforeEach()
+
+		res, err := service.Load(7)
+
+		assert.Nil(t, err)
+		assert.Empty(t, res)
+	})
+
+	t.Run("should return attachment too large when files are over the current limit", func(t *testing.T) {
+		beforeEach()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 1, Size: 8}, {ID: 2, Size: 8}}, nil)
+
+		_, err := service.Load(7)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.AttachmentTooLarge), err)
+		store.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("should return internal server error when stored content is missing", func(t *testing.T) {
+		beforeEach()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 1, Size: 4, StorageKey: "key"}}, nil)
+		mockStoreGet.Return(nil, errors.New("Error"))
+
+		_, err := service.Load(7)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
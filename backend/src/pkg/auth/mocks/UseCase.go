@@ -0,0 +1,81 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	auth "newsletter/src/pkg/auth"
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Authorize provides a mock function with given fields: username, permission
+func (_m *UseCase) Authorize(username string, permission auth.Permission) *error.ErrorCode {
+	ret := _m.Called(username, permission)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Authorize")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string, auth.Permission) *error.ErrorCode); ok {
+		r0 = rf(username, permission)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// VerifyToken provides a mock function with given fields: tokenString
+func (_m *UseCase) VerifyToken(tokenString string) (*auth.Claims, *error.ErrorCode) {
+	ret := _m.Called(tokenString)
+
+	if len(ret) == 0 {
+		panic("no return value specified for VerifyToken")
+	}
+
+	var r0 *auth.Claims
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (*auth.Claims, *error.ErrorCode)); ok {
+		return rf(tokenString)
+	}
+	if rf, ok := ret.Get(0).(func(string) *auth.Claims); ok {
+		r0 = rf(tokenString)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*auth.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(tokenString)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
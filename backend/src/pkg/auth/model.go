@@ -0,0 +1,28 @@
+package auth
+
+import (
+	"crypto/rsa"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+)
+
+// Claims are the claims of a verified bearer token. Subject identifies the
+// staff user the token was issued to.
+type Claims struct {
+	jwt.RegisteredClaims
+}
+
+// ServiceConfig selects how bearer tokens are verified. HS256 uses Secret and
+// RS256 uses PublicKey; Issuer and Audience are only checked when set.
+type ServiceConfig struct {
+	Algorithm string
+	Secret    []byte
+	PublicKey *rsa.PublicKey
+	Issuer    string
+	Audience  string
+}
@@ -0,0 +1,62 @@
+package auth
+
+import "newsletter/src/pkg/entity"
+
+type Permission string
+
+const (
+	PermissionSubscribersSubscribe Permission = "subscribers:subscribe"
+	PermissionSubscribersRead      Permission = "subscribers:read"
+	PermissionSubscribersExport    Permission = "subscribers:export"
+	PermissionSubscribersDelete    Permission = "subscribers:delete"
+	PermissionCampaignsRead        Permission = "campaigns:read"
+	PermissionCampaignsWrite       Permission = "campaigns:write"
+	PermissionCampaignsSend        Permission = "campaigns:send"
+	PermissionListsRead            Permission = "lists:read"
+	PermissionListsWrite           Permission = "lists:write"
+	PermissionFieldsRead           Permission = "fields:read"
+	PermissionFieldsWrite          Permission = "fields:write"
+	PermissionSegmentsRead         Permission = "segments:read"
+	PermissionSegmentsWrite        Permission = "segments:write"
+	PermissionAPIKeysManage        Permission = "apikeys:manage"
+)
+
+var (
+	supportPermissions = []Permission{
+		PermissionSubscribersRead,
+		PermissionCampaignsRead,
+		PermissionListsRead,
+		PermissionFieldsRead,
+		PermissionSegmentsRead,
+	}
+	editorPermissions = append([]Permission{
+		PermissionCampaignsWrite,
+		PermissionCampaignsSend,
+		PermissionListsWrite,
+		PermissionSegmentsWrite,
+	}, supportPermissions...)
+	adminPermissions = append([]Permission{
+		PermissionSubscribersExport,
+		PermissionSubscribersDelete,
+		PermissionAPIKeysManage,
+		PermissionFieldsWrite,
+	}, editorPermissions...)
+)
+
+// RolePermissions lists what each role may do. Every role includes the
+// permissions of the role below it.
+var RolePermissions = map[entity.Role][]Permission{
+	entity.RoleSupport: supportPermissions,
+	entity.RoleEditor:  editorPermissions,
+	entity.RoleAdmin:   adminPermissions,
+}
+
+// HasPermission reports whether role grants permission.
+func HasPermission(role entity.Role, permission Permission) bool {
+	for _, granted := range RolePermissions[role] {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
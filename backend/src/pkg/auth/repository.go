@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByUsername(username string) ([]entity.Users, error)
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) FindByUsername(username string) ([]entity.Users, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Username = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Users{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, username)
+	if err != nil {
+		go repo.Logs.Error("", "auth_Repo_FindByUsername", username, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Users{}
+	for rows.Next() {
+		var entity entity.Users
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
@@ -0,0 +1,50 @@
+package auth_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/auth"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRepository_FindByUsername(t *testing.T) {
+	beforeEachRepository := func(t *testing.T) (sqlmock.Sqlmock, *auth.SqlRepository) {
+		db, mockDB, err := sqlmock.New()
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Close() })
+
+		repoLogs := &loggerMocks.Logger{}
+		repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+		return mockDB, auth.NewRepository("TB_MAS_Users", db, repoLogs)
+	}
+
+	t.Run("should pass username as parameter", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`FROM TB_MAS_Users\s+WHERE Username = @p1`).
+			WithArgs("admin' OR 1=1 --").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "username", "role", "isActive"}).AddRow(1, "admin", "admin", true))
+
+		res, err := repo.FindByUsername("admin' OR 1=1 --")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "admin", res[0].Username)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("Username = @p1").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindByUsername("admin")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,119 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"errors"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"os"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+var (
+	ErrMissingKey           = errors.New("missing verification key")
+	ErrUnsupportedAlgorithm = errors.New("unsupported algorithm")
+	ErrMissingSubject       = errors.New("missing subject")
+	ErrInvalidIssuer        = errors.New("invalid issuer")
+	ErrInvalidAudience      = errors.New("invalid audience")
+)
+
+type UseCase interface {
+	VerifyToken(tokenString string) (*Claims, *newsletterError.ErrorCode)
+	Authorize(username string, permission Permission) *newsletterError.ErrorCode
+}
+
+type Service struct {
+	UseCase
+	Repo   Repository
+	Config ServiceConfig
+	Logs   logger.Logger
+}
+
+func NewService(repo Repository, config ServiceConfig, logs logger.Logger) *Service {
+	service := &Service{
+		Repo:   repo,
+		Config: config,
+		Logs:   logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+// LoadPublicKey reads a PEM encoded RSA public key used to verify RS256 tokens.
+func LoadPublicKey(path string) (*rsa.PublicKey, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return jwt.ParseRSAPublicKeyFromPEM(content)
+}
+
+// VerifyToken checks the signature, algorithm and time claims of a bearer
+// token and returns its claims. Only the configured algorithm is accepted, so
+// an RS256 public key can never be used as an HS256 secret.
+func (service *Service) VerifyToken(tokenString string) (*Claims, *newsletterError.ErrorCode) {
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{service.Config.Algorithm}))
+	if _, err := parser.ParseWithClaims(tokenString, claims, service.verificationKey); err != nil {
+		go service.Logs.Error("", "auth_Service_VerifyToken", nil, newsletterError.NewError(newsletterError.Unauthorized, err.Error()))
+		return nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized)
+	}
+
+	if err := service.verifyClaims(claims); err != nil {
+		go service.Logs.Error("", "auth_Service_VerifyToken", claims.Subject, newsletterError.NewError(newsletterError.Unauthorized, err.Error()))
+		return nil, convert.ValueToErrorCodePointer(newsletterError.Unauthorized)
+	}
+
+	return claims, nil
+}
+
+// Authorize checks that username belongs to an active user whose role grants
+// permission. Unknown and deactivated users are forbidden rather than
+// unauthorized, as their token itself was valid.
+func (service *Service) Authorize(username string, permission Permission) *newsletterError.ErrorCode {
+	res, err := service.Repo.FindByUsername(username)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 || !res[0].IsActive || !HasPermission(res[0].Role, permission) {
+		return convert.ValueToErrorCodePointer(newsletterError.Forbidden)
+	}
+
+	return nil
+}
+
+func (service *Service) verificationKey(token *jwt.Token) (interface{}, error) {
+	switch service.Config.Algorithm {
+	case AlgorithmHS256:
+		if len(service.Config.Secret) == 0 {
+			return nil, ErrMissingKey
+		}
+		return service.Config.Secret, nil
+	case AlgorithmRS256:
+		if service.Config.PublicKey == nil {
+			return nil, ErrMissingKey
+		}
+		return service.Config.PublicKey, nil
+	default:
+		return nil, ErrUnsupportedAlgorithm
+	}
+}
+
+func (service *Service) verifyClaims(claims *Claims) error {
+	if claims.Subject == "" {
+		return ErrMissingSubject
+	}
+
+	if service.Config.Issuer != "" && !claims.VerifyIssuer(service.Config.Issuer, true) {
+		return ErrInvalidIssuer
+	}
+
+	if service.Config.Audience != "" && !claims.VerifyAudience(service.Config.Audience, true) {
+		return ErrInvalidAudience
+	}
+
+	return nil
+}
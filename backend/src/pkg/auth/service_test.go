@@ -0,0 +1,308 @@
+package auth_test
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"newsletter/src/pkg/auth"
+	"newsletter/src/pkg/auth/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository *mocks.Repository
+	service    *auth.Service
+	logs       *loggerMocks.Logger
+
+	mockRepoFindByUsername *mocker.MockCall
+
+	secret     = []byte("secret")
+	privateKey *rsa.PrivateKey
+)
+
+func init() {
+	privateKey, _ = rsa.GenerateKey(rand.Reader, 2048)
+}
+
+func callRepoFindByUsername() *mock.Call {
+	return repository.On("FindByUsername", mock.Anything)
+}
+
+func beforeEach(config auth.ServiceConfig) {
+	repository = &mocks.Repository{}
+	logs = &loggerMocks.Logger{}
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = auth.NewService(repository, config, logs)
+}
+
+func validClaims() jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   "admin@newsletter.local",
+		Issuer:    "newsletter",
+		Audience:  jwt.ClaimStrings{"newsletter-api"},
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func signHS256(claims jwt.Claims, key []byte) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(key)
+	return token
+}
+
+func signRS256(claims jwt.Claims) string {
+	token, _ := jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(privateKey)
+	return token
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct auth service when call new service", func(t *testing.T) {
+		config := auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret}
+		beforeEach(config)
+
+		expectedService := &auth.Service{
+			Repo:   repository,
+			Config: config,
+			Logs:   logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, service)
+	})
+}
+
+func TestService_VerifyToken(t *testing.T) {
+	unauthorized := convert.ValueToErrorCodePointer(newsletterError.Unauthorized)
+
+	t.Run("should return claims when hs256 token is valid", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), secret))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "admin@newsletter.local", claims.Subject)
+	})
+
+	t.Run("should return claims when rs256 token is valid", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmRS256, PublicKey: &privateKey.PublicKey})
+
+		claims, err := service.VerifyToken(signRS256(validClaims()))
+
+		assert.Nil(t, err)
+		assert.Equal(t, "admin@newsletter.local", claims.Subject)
+	})
+
+	t.Run("should return unauthorized when signature does not match", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), []byte("other")))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return unauthorized when token uses another algorithm", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmRS256, PublicKey: &privateKey.PublicKey})
+		publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&privateKey.PublicKey)})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), publicKeyPEM))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return unauthorized when token is expired", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+		claims := validClaims()
+		claims.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+		res, err := service.VerifyToken(signHS256(claims, secret))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return unauthorized when token is malformed", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+
+		claims, err := service.VerifyToken("not.a.token")
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return unauthorized when secret is not configured", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), []byte{}))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return unauthorized when subject is missing", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+		claims := validClaims()
+		claims.Subject = ""
+
+		res, err := service.VerifyToken(signHS256(claims, secret))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return unauthorized when issuer does not match", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret, Issuer: "other"})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), secret))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return unauthorized when audience does not match", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret, Audience: "other"})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), secret))
+
+		assert.Equal(t, unauthorized, err)
+		assert.Nil(t, claims)
+	})
+
+	t.Run("should return claims when issuer and audience match", func(t *testing.T) {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret, Issuer: "newsletter", Audience: "newsletter-api"})
+
+		claims, err := service.VerifyToken(signHS256(validClaims(), secret))
+
+		assert.Nil(t, err)
+		assert.NotNil(t, claims)
+	})
+}
+
+func TestService_LoadPublicKey(t *testing.T) {
+	t.Run("should load pem encoded public key", func(t *testing.T) {
+		der, _ := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+		path := filepath.Join(t.TempDir(), "jwt.pub")
+		os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0644)
+
+		publicKey, err := auth.LoadPublicKey(path)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &privateKey.PublicKey, publicKey)
+	})
+
+	t.Run("should return error when file is not a public key", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "jwt.pub")
+		os.WriteFile(path, []byte("not a key"), 0644)
+
+		publicKey, err := auth.LoadPublicKey(path)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, publicKey)
+	})
+}
+
+func TestService_Authorize(t *testing.T) {
+	beforeEachAuthorize := func() {
+		beforeEach(auth.ServiceConfig{Algorithm: auth.AlgorithmHS256, Secret: secret})
+
+		mockRepoFindByUsername = mocker.NewMockCall(callRepoFindByUsername)
+		mockRepoFindByUsername.Return([]entity.Users{{ID: 1, Username: "editor", Role: entity.RoleEditor, IsActive: true}}, nil)
+	}
+
+	t.Run("should return nil when role grants permission", func(t *testing.T) {
+		beforeEachAuthorize()
+
+		err := service.Authorize("editor", auth.PermissionCampaignsSend)
+
+		repository.AssertCalled(t, "FindByUsername", "editor")
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return forbidden when role does not grant permission", func(t *testing.T) {
+		beforeEachAuthorize()
+
+		err := service.Authorize("editor", auth.PermissionSubscribersDelete)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.Forbidden), err)
+	})
+
+	t.Run("should return forbidden when user does not exist", func(t *testing.T) {
+		beforeEachAuthorize()
+		mockRepoFindByUsername.Return([]entity.Users{}, nil)
+
+		err := service.Authorize("stranger", auth.PermissionSubscribersRead)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.Forbidden), err)
+	})
+
+	t.Run("should return forbidden when user is not active", func(t *testing.T) {
+		beforeEachAuthorize()
+		mockRepoFindByUsername.Return([]entity.Users{{Username: "admin", Role: entity.RoleAdmin, IsActive: false}}, nil)
+
+		err := service.Authorize("admin", auth.PermissionSubscribersRead)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.Forbidden), err)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachAuthorize()
+		mockRepoFindByUsername.Return(nil, errors.New("Error"))
+
+		err := service.Authorize("editor", auth.PermissionCampaignsSend)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_HasPermission(t *testing.T) {
+	t.Run("should let support only read", func(t *testing.T) {
+		assert.True(t, auth.HasPermission(entity.RoleSupport, auth.PermissionSubscribersRead))
+		assert.True(t, auth.HasPermission(entity.RoleSupport, auth.PermissionCampaignsRead))
+		assert.False(t, auth.HasPermission(entity.RoleSupport, auth.PermissionCampaignsWrite))
+		assert.False(t, auth.HasPermission(entity.RoleSupport, auth.PermissionCampaignsSend))
+		assert.True(t, auth.HasPermission(entity.RoleSupport, auth.PermissionListsRead))
+		assert.False(t, auth.HasPermission(entity.RoleSupport, auth.PermissionListsWrite))
+		assert.True(t, auth.HasPermission(entity.RoleSupport, auth.PermissionFieldsRead))
+		assert.False(t, auth.HasPermission(entity.RoleSupport, auth.PermissionFieldsWrite))
+		assert.True(t, auth.HasPermission(entity.RoleSupport, auth.PermissionSegmentsRead))
+		assert.False(t, auth.HasPermission(entity.RoleSupport, auth.PermissionSegmentsWrite))
+	})
+
+	t.Run("should let editor send but not export or delete subscribers", func(t *testing.T) {
+		assert.True(t, auth.HasPermission(entity.RoleEditor, auth.PermissionSubscribersRead))
+		assert.True(t, auth.HasPermission(entity.RoleEditor, auth.PermissionCampaignsSend))
+		assert.True(t, auth.HasPermission(entity.RoleEditor, auth.PermissionListsWrite))
+		assert.True(t, auth.HasPermission(entity.RoleEditor, auth.PermissionSegmentsWrite))
+		assert.False(t, auth.HasPermission(entity.RoleEditor, auth.PermissionSubscribersExport))
+		assert.False(t, auth.HasPermission(entity.RoleEditor, auth.PermissionSubscribersDelete))
+		assert.False(t, auth.HasPermission(entity.RoleEditor, auth.PermissionAPIKeysManage))
+		assert.False(t, auth.HasPermission(entity.RoleEditor, auth.PermissionFieldsWrite))
+	})
+
+	t.Run("should let admin do everything", func(t *testing.T) {
+		assert.True(t, auth.HasPermission(entity.RoleAdmin, auth.PermissionCampaignsSend))
+		assert.True(t, auth.HasPermission(entity.RoleAdmin, auth.PermissionSubscribersExport))
+		assert.True(t, auth.HasPermission(entity.RoleAdmin, auth.PermissionSubscribersDelete))
+		assert.True(t, auth.HasPermission(entity.RoleAdmin, auth.PermissionAPIKeysManage))
+		assert.True(t, auth.HasPermission(entity.RoleAdmin, auth.PermissionFieldsWrite))
+	})
+
+	t.Run("should grant nothing to unknown role", func(t *testing.T) {
+		assert.False(t, auth.HasPermission(entity.Role("guest"), auth.PermissionSubscribersRead))
+	})
+}
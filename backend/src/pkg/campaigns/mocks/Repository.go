@@ -0,0 +1,170 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.Campaigns, error) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Campaigns
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Campaigns, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Campaigns); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllCampaigns provides a mock function with given fields:
+func (_m *Repository) GetAllCampaigns() ([]entity.Campaigns, error) {
+	ret := _m.Called()
+
+	var r0 []entity.Campaigns
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.Campaigns, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Campaigns); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: campaign
+func (_m *Repository) Insert(campaign entity.Campaigns) (int64, error) {
+	ret := _m.Called(campaign)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) (int64, error)); ok {
+		return rf(campaign)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) int64); ok {
+		r0 = rf(campaign)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Campaigns) error); ok {
+		r1 = rf(campaign)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateByID provides a mock function with given fields: campaign
+func (_m *Repository) UpdateByID(campaign entity.Campaigns) error {
+	ret := _m.Called(campaign)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) error); ok {
+		r0 = rf(campaign)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatusByID provides a mock function with given fields: id, status
+func (_m *Repository) UpdateStatusByID(id int64, status entity.CampaignStatus) error {
+	ret := _m.Called(id, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, entity.CampaignStatus) error); ok {
+		r0 = rf(id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatusFromByID provides a mock function with given fields: id, from, to
+func (_m *Repository) UpdateStatusFromByID(id int64, from []entity.CampaignStatus, to entity.CampaignStatus) (bool, error) {
+	ret := _m.Called(id, from, to)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, []entity.CampaignStatus, entity.CampaignStatus) (bool, error)); ok {
+		return rf(id, from, to)
+	}
+	if rf, ok := ret.Get(0).(func(int64, []entity.CampaignStatus, entity.CampaignStatus) bool); ok {
+		r0 = rf(id, from, to)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, []entity.CampaignStatus, entity.CampaignStatus) error); ok {
+		r1 = rf(id, from, to)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,356 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	attachments "newsletter/src/pkg/attachments"
+
+	entity "newsletter/src/pkg/entity"
+
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// AddAttachment provides a mock function with given fields: upload
+func (_m *UseCase) AddAttachment(upload attachments.Upload) (*entity.Attachments, *error.ErrorCode) {
+	ret := _m.Called(upload)
+
+	var r0 *entity.Attachments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(attachments.Upload) (*entity.Attachments, *error.ErrorCode)); ok {
+		return rf(upload)
+	}
+	if rf, ok := ret.Get(0).(func(attachments.Upload) *entity.Attachments); ok {
+		r0 = rf(upload)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(attachments.Upload) *error.ErrorCode); ok {
+		r1 = rf(upload)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Create provides a mock function with given fields: campaign
+func (_m *UseCase) Create(campaign entity.Campaigns) (*entity.Campaigns, *error.ErrorCode) {
+	ret := _m.Called(campaign)
+
+	var r0 *entity.Campaigns
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) (*entity.Campaigns, *error.ErrorCode)); ok {
+		return rf(campaign)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) *entity.Campaigns); ok {
+		r0 = rf(campaign)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Campaigns) *error.ErrorCode); ok {
+		r1 = rf(campaign)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *UseCase) Delete(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// DeleteAttachment provides a mock function with given fields: id, attachmentID
+func (_m *UseCase) DeleteAttachment(id int64, attachmentID int64) *error.ErrorCode {
+	ret := _m.Called(id, attachmentID)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, int64) *error.ErrorCode); ok {
+		r0 = rf(id, attachmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.Campaigns, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 *entity.Campaigns
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.Campaigns, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.Campaigns); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllCampaigns provides a mock function with given fields:
+func (_m *UseCase) GetAllCampaigns() ([]entity.Campaigns, *error.ErrorCode) {
+	ret := _m.Called()
+
+	var r0 []entity.Campaigns
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() ([]entity.Campaigns, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Campaigns); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAttachments provides a mock function with given fields: id
+func (_m *UseCase) GetAttachments(id int64) ([]entity.Attachments, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Attachments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Attachments, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Attachments); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Attachments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: id
+func (_m *UseCase) GetDeadLetters(id int64) ([]entity.Deliveries, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Deliveries
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Deliveries, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Deliveries); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetDeliveries provides a mock function with given fields: id
+func (_m *UseCase) GetDeliveries(id int64) ([]entity.Deliveries, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Deliveries
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Deliveries, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Deliveries); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Queue provides a mock function with given fields: id, from
+func (_m *UseCase) Queue(id int64, from []entity.CampaignStatus) *error.ErrorCode {
+	ret := _m.Called(id, from)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, []entity.CampaignStatus) *error.ErrorCode); ok {
+		r0 = rf(id, from)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Redrive provides a mock function with given fields: id
+func (_m *UseCase) Redrive(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Resume provides a mock function with given fields: id
+func (_m *UseCase) Resume(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Send provides a mock function with given fields: id
+func (_m *UseCase) Send(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: campaign
+func (_m *UseCase) Update(campaign entity.Campaigns) *error.ErrorCode {
+	ret := _m.Called(campaign)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Campaigns) *error.ErrorCode); ok {
+		r0 = rf(campaign)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// UpdateStatusByID provides a mock function with given fields: id, status
+func (_m *UseCase) UpdateStatusByID(id int64, status entity.CampaignStatus) *error.ErrorCode {
+	ret := _m.Called(id, status)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, entity.CampaignStatus) *error.ErrorCode); ok {
+		r0 = rf(id, status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
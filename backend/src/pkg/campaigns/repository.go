@@ -0,0 +1,295 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+	"strings"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	GetAllCampaigns() ([]entity.Campaigns, error)
+	FindByID(id int64) ([]entity.Campaigns, error)
+	Insert(campaign entity.Campaigns) (int64, error)
+	UpdateByID(campaign entity.Campaigns) error
+	UpdateStatusByID(id int64, status entity.CampaignStatus) error
+	UpdateStatusFromByID(id int64, from []entity.CampaignStatus, to entity.CampaignStatus) (bool, error)
+	DeleteByID(id int64) error
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) GetAllCampaigns() ([]entity.Campaigns, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	ORDER BY Id DESC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Campaigns{}, []string{}),
+		repo.Collection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_GetAllCampaigns", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Campaigns{}
+	for rows.Next() {
+		var entity entity.Campaigns
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Campaigns, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND Id = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Campaigns{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_FindByID", id, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Campaigns{}
+	for rows.Next() {
+		var entity entity.Campaigns
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Insert(campaign entity.Campaigns) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "CreatedDate", "UpdatedDate", "SentDate", "ClaimedBy", "ClaimedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(campaign, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.Campaigns{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_Insert", campaign,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (repo *SqlRepository) UpdateByID(campaign entity.Campaigns) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(campaign, []string{"Subject", "HTMLBody", "TextBody", "Sender", "Status", "ListID", "SegmentID"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		%[2]s,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{campaign.ID}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_UpdateByID", campaign,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) UpdateStatusByID(id int64, status entity.CampaignStatus) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	setSentDate := ""
+	if status == entity.CampaignStatusSent {
+		setSentDate = "SentDate = GETDATE(),"
+	}
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[3]s,
+		%[4]s
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		setSentDate,
+	)
+
+	_, err := session.ExecContext(ctx, sql, id, status)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_UpdateStatusByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// UpdateStatusFromByID moves the campaign to status to only while its status
+// is one of from, and reports whether it did.
+func (repo *SqlRepository) UpdateStatusFromByID(id int64, from []entity.CampaignStatus, to entity.CampaignStatus) (bool, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return false, sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{id, to}
+	params := []string{}
+	for _, status := range from {
+		args = append(args, status)
+		params = append(params, sqlQuery.Param(len(args)))
+	}
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[3]s,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	AND Status IN (%[4]s)
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		strings.Join(params, ","),
+	)
+
+	res, err := session.ExecContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_UpdateStatusFromByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (repo *SqlRepository) DeleteByID(id int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Delflag = 1,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,378 @@
+package campaigns
+
+import (
+	"newsletter/src/pkg/attachments"
+	deliveries "newsletter/src/pkg/deliveries"
+	"newsletter/src/pkg/email"
+	"newsletter/src/pkg/entity"
+	lists "newsletter/src/pkg/lists"
+	segments "newsletter/src/pkg/segments"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"strings"
+)
+
+type UseCase interface {
+	GetAllCampaigns() ([]entity.Campaigns, *newsletterError.ErrorCode)
+	FindByID(id int64) (*entity.Campaigns, *newsletterError.ErrorCode)
+	Create(campaign entity.Campaigns) (*entity.Campaigns, *newsletterError.ErrorCode)
+	Update(campaign entity.Campaigns) *newsletterError.ErrorCode
+	Delete(id int64) *newsletterError.ErrorCode
+	UpdateStatusByID(id int64, status entity.CampaignStatus) *newsletterError.ErrorCode
+	Queue(id int64, from []entity.CampaignStatus) *newsletterError.ErrorCode
+	Send(id int64) *newsletterError.ErrorCode
+	Resume(id int64) *newsletterError.ErrorCode
+	GetDeliveries(id int64) ([]entity.Deliveries, *newsletterError.ErrorCode)
+	GetDeadLetters(id int64) ([]entity.Deliveries, *newsletterError.ErrorCode)
+	Redrive(id int64) *newsletterError.ErrorCode
+	GetAttachments(id int64) ([]entity.Attachments, *newsletterError.ErrorCode)
+	AddAttachment(upload attachments.Upload) (*entity.Attachments, *newsletterError.ErrorCode)
+	DeleteAttachment(id int64, attachmentID int64) *newsletterError.ErrorCode
+}
+
+// Service manages campaigns. It never sends them: Send, Resume and Redrive
+// queue a campaign, and cmstool serve claims and delivers it.
+type Service struct {
+	UseCase
+	Repo        Repository
+	Lists       lists.UseCase
+	Segments    segments.UseCase
+	Deliveries  deliveries.UseCase
+	Attachments attachments.UseCase
+	Templates   *email.Renderer
+	Logs        logger.Logger
+}
+
+func NewService(repo Repository, listsService lists.UseCase, segmentsService segments.UseCase, deliveriesService deliveries.UseCase, attachmentsService attachments.UseCase, templates *email.Renderer, logs logger.Logger) *Service {
+	service := &Service{
+		Repo:        repo,
+		Lists:       listsService,
+		Segments:    segmentsService,
+		Deliveries:  deliveriesService,
+		Attachments: attachmentsService,
+		Templates:   templates,
+		Logs:        logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetAllCampaigns() ([]entity.Campaigns, *newsletterError.ErrorCode) {
+	res, err := service.Repo.GetAllCampaigns()
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) FindByID(id int64) (*entity.Campaigns, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+func (service *Service) Create(campaign entity.Campaigns) (*entity.Campaigns, *newsletterError.ErrorCode) {
+	if campaign.Status == "" {
+		campaign.Status = entity.CampaignStatusDraft
+	}
+
+	errValidate := service.validateCampaign(campaign)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	if !isEditable(campaign.Status) {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus)
+	}
+
+	id, err := service.Repo.Insert(campaign)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.UseCase.FindByID(id)
+}
+
+func (service *Service) Update(campaign entity.Campaigns) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(campaign.ID)
+	if err != nil {
+		return err
+	}
+
+	if !isEditable(resCampaign.Status) {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent)
+	}
+
+	if campaign.Status == "" {
+		campaign.Status = resCampaign.Status
+	}
+
+	errValidate := service.validateCampaign(campaign)
+	if errValidate != nil {
+		return errValidate
+	}
+
+	if !isEditable(campaign.Status) {
+		return convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus)
+	}
+
+	errUpdate := service.Repo.UpdateByID(campaign)
+	if errUpdate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) Delete(id int64) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if isInProgress(resCampaign.Status) {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent)
+	}
+
+	errDelete := service.Repo.DeleteByID(id)
+	if errDelete != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) UpdateStatusByID(id int64, status entity.CampaignStatus) *newsletterError.ErrorCode {
+	err := service.Repo.UpdateStatusByID(id, status)
+
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// Queue moves the campaign to queued, for cmstool to send, only while its
+// status is one of from. When another request queued it first, it responds
+// CampaignDeliveryInProgress.
+func (service *Service) Queue(id int64, from []entity.CampaignStatus) *newsletterError.ErrorCode {
+	queued, err := service.Repo.UpdateStatusFromByID(id, from, entity.CampaignStatusQueued)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if !queued {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress)
+	}
+
+	return nil
+}
+
+// Send checks the campaign templates and queues it. cmstool serve delivers
+// queued campaigns, so nothing is sent by the backend itself.
+func (service *Service) Send(id int64) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if !isEditable(resCampaign.Status) {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent)
+	}
+
+	_, errTemplate := service.Templates.Parse(resCampaign.Subject, resCampaign.HTMLBody, resCampaign.TextBody)
+	if errTemplate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InvalidTemplate)
+	}
+
+	return service.UseCase.Queue(id, entity.CampaignSendableStatuses)
+}
+
+// Resume queues a finished campaign again, for example one that failed for
+// some subscribers. cmstool skips the subscribers that were already delivered
+// or dead-lettered.
+func (service *Service) Resume(id int64) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errStatus := checkResumable(resCampaign.Status)
+	if errStatus != nil {
+		return errStatus
+	}
+
+	return service.UseCase.Queue(id, entity.CampaignResumableStatuses)
+}
+
+func (service *Service) GetDeliveries(id int64) ([]entity.Deliveries, *newsletterError.ErrorCode) {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Deliveries.GetByCampaignID(id)
+}
+
+func (service *Service) GetDeadLetters(id int64) ([]entity.Deliveries, *newsletterError.ErrorCode) {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Deliveries.GetDeadLetters(id)
+}
+
+// Redrive puts the dead-lettered deliveries of a finished campaign back in
+// the failed state and queues the campaign again, so cmstool retries only
+// those subscribers.
+func (service *Service) Redrive(id int64) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errStatus := checkResumable(resCampaign.Status)
+	if errStatus != nil {
+		return errStatus
+	}
+
+	_, errRequeue := service.Deliveries.RequeueDeadLetters(id)
+	if errRequeue != nil {
+		return errRequeue
+	}
+
+	return service.UseCase.Queue(id, entity.CampaignResumableStatuses)
+}
+
+func (service *Service) GetAttachments(id int64) ([]entity.Attachments, *newsletterError.ErrorCode) {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Attachments.GetByCampaignID(id)
+}
+
+// AddAttachment adds a file to a campaign that has not been sent yet.
+func (service *Service) AddAttachment(upload attachments.Upload) (*entity.Attachments, *newsletterError.ErrorCode) {
+	resCampaign, err := service.UseCase.FindByID(upload.CampaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isEditable(resCampaign.Status) {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent)
+	}
+
+	return service.Attachments.Upload(upload)
+}
+
+func (service *Service) DeleteAttachment(id int64, attachmentID int64) *newsletterError.ErrorCode {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	if !isEditable(resCampaign.Status) {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent)
+	}
+
+	resAttachment, errAttachment := service.Attachments.FindByID(attachmentID)
+	if errAttachment != nil {
+		return errAttachment
+	}
+
+	if resAttachment.CampaignID != id {
+		return convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return service.Attachments.Delete(attachmentID)
+}
+
+func isEditable(status entity.CampaignStatus) bool {
+	return status == entity.CampaignStatusDraft || status == entity.CampaignStatusScheduled
+}
+
+// isInProgress reports whether a campaign with status is waiting for cmstool
+// or being sent by it.
+func isInProgress(status entity.CampaignStatus) bool {
+	return status == entity.CampaignStatusQueued || status == entity.CampaignStatusSending
+}
+
+// checkResumable tells why a campaign with status cannot be resumed or
+// re-driven yet, if it cannot.
+func checkResumable(status entity.CampaignStatus) *newsletterError.ErrorCode {
+	if isInProgress(status) {
+		return convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress)
+	}
+
+	if isEditable(status) {
+		return convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus)
+	}
+
+	return nil
+}
+
+func (service *Service) validateCampaign(campaign entity.Campaigns) *newsletterError.ErrorCode {
+	if strings.TrimSpace(campaign.Subject) == "" || strings.TrimSpace(campaign.HTMLBody) == "" {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	switch campaign.Status {
+	case entity.CampaignStatusDraft,
+		entity.CampaignStatusScheduled,
+		entity.CampaignStatusQueued,
+		entity.CampaignStatusSending,
+		entity.CampaignStatusSent,
+		entity.CampaignStatusFailed:
+	default:
+		return convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus)
+	}
+
+	_, errTemplate := service.Templates.Parse(campaign.Subject, campaign.HTMLBody, campaign.TextBody)
+	if errTemplate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InvalidTemplate)
+	}
+
+	if campaign.ListID != nil && campaign.SegmentID != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	if campaign.ListID != nil {
+		_, errList := service.Lists.FindByID(*campaign.ListID)
+		if errList != nil && *errList == newsletterError.DataNotFound {
+			return convert.ValueToErrorCodePointer(newsletterError.InvalidList)
+		}
+		if errList != nil {
+			return errList
+		}
+	}
+
+	if campaign.SegmentID != nil {
+		_, errSegment := service.Segments.FindByID(*campaign.SegmentID)
+		if errSegment != nil && *errSegment == newsletterError.DataNotFound {
+			return convert.ValueToErrorCodePointer(newsletterError.InvalidSegment)
+		}
+		if errSegment != nil {
+			return errSegment
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,847 @@
+package campaigns_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/attachments"
+	attachmentsMocks "newsletter/src/pkg/attachments/mocks"
+	campaigns "newsletter/src/pkg/campaigns"
+	"newsletter/src/pkg/campaigns/mocks"
+	deliveriesMocks "newsletter/src/pkg/deliveries/mocks"
+	"newsletter/src/pkg/email"
+	"newsletter/src/pkg/entity"
+	listsMocks "newsletter/src/pkg/lists/mocks"
+	segmentsMocks "newsletter/src/pkg/segments/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase        *mocks.UseCase
+	repository         *mocks.Repository
+	listsService       *listsMocks.UseCase
+	segmentsService    *segmentsMocks.UseCase
+	deliveriesService  *deliveriesMocks.UseCase
+	attachmentsService *attachmentsMocks.UseCase
+	templates          *email.Renderer
+	service            *campaigns.Service
+	logs               *loggerMocks.Logger
+
+	mockRepoGetAllCampaigns      *mocker.MockCall
+	mockRepoFindByID             *mocker.MockCall
+	mockRepoInsert               *mocker.MockCall
+	mockRepoUpdateByID           *mocker.MockCall
+	mockRepoUpdateStatusByID     *mocker.MockCall
+	mockRepoUpdateStatusFromByID *mocker.MockCall
+	mockRepoDeleteByID           *mocker.MockCall
+	mockServiceFindByID          *mocker.MockCall
+	mockServiceUpdateStatusByID  *mocker.MockCall
+	mockServiceQueue             *mocker.MockCall
+	mockListsFindByID            *mocker.MockCall
+	mockSegmentsFindByID         *mocker.MockCall
+
+	mockDeliveriesGetByCampaignID    *mocker.MockCall
+	mockDeliveriesGetDeadLetters     *mocker.MockCall
+	mockDeliveriesRequeueDeadLetters *mocker.MockCall
+	mockAttachmentsGetByCampaignID   *mocker.MockCall
+	mockAttachmentsUpload            *mocker.MockCall
+	mockAttachmentsFindByID          *mocker.MockCall
+	mockAttachmentsDelete            *mocker.MockCall
+)
+
+func callRepoGetAllCampaigns() *mock.Call {
+	return repository.On("GetAllCampaigns")
+}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoUpdateByID() *mock.Call {
+	return repository.On("UpdateByID", mock.Anything)
+}
+
+func callRepoUpdateStatusByID() *mock.Call {
+	return repository.On("UpdateStatusByID", mock.Anything, mock.Anything)
+}
+
+func callRepoUpdateStatusFromByID() *mock.Call {
+	return repository.On("UpdateStatusFromByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func callServiceUpdateStatusByID() *mock.Call {
+	return mockUseCase.On("UpdateStatusByID", mock.Anything, mock.Anything)
+}
+
+func callServiceQueue() *mock.Call {
+	return mockUseCase.On("Queue", mock.Anything, mock.Anything)
+}
+
+func callListsFindByID() *mock.Call {
+	return listsService.On("FindByID", mock.Anything)
+}
+
+func callSegmentsFindByID() *mock.Call {
+	return segmentsService.On("FindByID", mock.Anything)
+}
+
+func callDeliveriesGetByCampaignID() *mock.Call {
+	return deliveriesService.On("GetByCampaignID", mock.Anything)
+}
+
+func callDeliveriesGetDeadLetters() *mock.Call {
+	return deliveriesService.On("GetDeadLetters", mock.Anything)
+}
+
+func callDeliveriesRequeueDeadLetters() *mock.Call {
+	return deliveriesService.On("RequeueDeadLetters", mock.Anything)
+}
+
+func callAttachmentsGetByCampaignID() *mock.Call {
+	return attachmentsService.On("GetByCampaignID", mock.Anything)
+}
+
+func callAttachmentsUpload() *mock.Call {
+	return attachmentsService.On("Upload", mock.Anything)
+}
+
+func callAttachmentsFindByID() *mock.Call {
+	return attachmentsService.On("FindByID", mock.Anything)
+}
+
+func callAttachmentsDelete() *mock.Call {
+	return attachmentsService.On("Delete", mock.Anything)
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	listsService = &listsMocks.UseCase{}
+	segmentsService = &segmentsMocks.UseCase{}
+	deliveriesService = &deliveriesMocks.UseCase{}
+	attachmentsService = &attachmentsMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+	templates, _ = email.NewRenderer("")
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &campaigns.Service{
+		Repo:        repository,
+		Lists:       listsService,
+		Segments:    segmentsService,
+		Deliveries:  deliveriesService,
+		Attachments: attachmentsService,
+		Templates:   templates,
+		Logs:        logs,
+	}
+	service.UseCase = mockUseCase
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct campaigns service when call new service", func(t *testing.T) {
+		beforeEach()
+		resService := campaigns.NewService(repository, listsService, segmentsService, deliveriesService, attachmentsService, templates, logs)
+
+		expectedService := &campaigns.Service{
+			Repo:        repository,
+			Lists:       listsService,
+			Segments:    segmentsService,
+			Deliveries:  deliveriesService,
+			Attachments: attachmentsService,
+			Templates:   templates,
+			Logs:        logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_GetAllCampaigns(t *testing.T) {
+	beforeEachGetAllCampaigns := func() {
+		beforeEach()
+
+		mockRepoGetAllCampaigns = mocker.NewMockCall(callRepoGetAllCampaigns)
+		mockRepoGetAllCampaigns.Return(nil, nil)
+	}
+
+	t.Run("should response internal server error when repository get all campaigns failed", func(t *testing.T) {
+		beforeEachGetAllCampaigns()
+		mockRepoGetAllCampaigns.Return(nil, errors.New("Error"))
+
+		res, err := service.GetAllCampaigns()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data not found when data not founded", func(t *testing.T) {
+		beforeEachGetAllCampaigns()
+		mockRepoGetAllCampaigns.Return([]entity.Campaigns{}, nil)
+
+		res, err := service.GetAllCampaigns()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data when found data", func(t *testing.T) {
+		beforeEachGetAllCampaigns()
+		mockCampaigns := []entity.Campaigns{{ID: 1, Subject: "TEST"}}
+		mockRepoGetAllCampaigns.Return(mockCampaigns, nil)
+
+		res, err := service.GetAllCampaigns()
+
+		assert.Equal(t, mockCampaigns, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return(nil, nil)
+	}
+
+	t.Run("should response internal server error when repository find by id failed", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return(nil, errors.New("Error"))
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data not found when data not founded", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return campaign when found data", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.Campaigns{{ID: 1, Subject: "TEST"}}, nil)
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, &entity.Campaigns{ID: 1, Subject: "TEST"}, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	beforeEachCreate := func() {
+		beforeEach()
+
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(1), nil)
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+	}
+
+	t.Run("should response bad request when subject is empty", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{HTMLBody: "<p>test</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should response invalid campaign status when status is unknown", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", Status: "unknown"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should response invalid campaign status when create campaign as sent", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", Status: entity.CampaignStatusSent})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should response invalid template when body references unknown field", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>{{.Unknown}}</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidTemplate), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should response invalid list when list does not exist", func(t *testing.T) {
+		beforeEachCreate()
+		mockListsFindByID = mocker.NewMockCall(callListsFindByID)
+		mockListsFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", ListID: convert.ValueToInt64Pointer(9)})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidList), err)
+		assert.Nil(t, res)
+		listsService.AssertCalled(t, "FindByID", int64(9))
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should insert campaign with list when list exists", func(t *testing.T) {
+		beforeEachCreate()
+		mockListsFindByID = mocker.NewMockCall(callListsFindByID)
+		mockListsFindByID.Return(&entity.Lists{ID: 9}, nil)
+
+		_, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", ListID: convert.ValueToInt64Pointer(9)})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Insert", entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", Status: entity.CampaignStatusDraft, ListID: convert.ValueToInt64Pointer(9)})
+	})
+
+	t.Run("should response invalid segment when segment does not exist", func(t *testing.T) {
+		beforeEachCreate()
+		mockSegmentsFindByID = mocker.NewMockCall(callSegmentsFindByID)
+		mockSegmentsFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", SegmentID: convert.ValueToInt64Pointer(4)})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidSegment), err)
+		assert.Nil(t, res)
+		segmentsService.AssertCalled(t, "FindByID", int64(4))
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should insert campaign with segment when segment exists", func(t *testing.T) {
+		beforeEachCreate()
+		mockSegmentsFindByID = mocker.NewMockCall(callSegmentsFindByID)
+		mockSegmentsFindByID.Return(&entity.Segments{ID: 4}, nil)
+
+		_, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", SegmentID: convert.ValueToInt64Pointer(4)})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Insert", entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", Status: entity.CampaignStatusDraft, SegmentID: convert.ValueToInt64Pointer(4)})
+	})
+
+	t.Run("should response bad request when both list and segment are set", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", ListID: convert.ValueToInt64Pointer(9), SegmentID: convert.ValueToInt64Pointer(4)})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should insert draft campaign when status is empty", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>"})
+
+		repository.AssertCalled(t, "Insert", entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>", Status: entity.CampaignStatusDraft})
+		mockUseCase.AssertCalled(t, "FindByID", int64(1))
+		assert.Equal(t, &entity.Campaigns{ID: 1}, res)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should response internal server error when repository insert failed", func(t *testing.T) {
+		beforeEachCreate()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		res, err := service.Create(entity.Campaigns{Subject: "test", HTMLBody: "<p>test</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	beforeEachUpdate := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+		mockRepoUpdateByID = mocker.NewMockCall(callRepoUpdateByID)
+		mockRepoUpdateByID.Return(nil)
+	}
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachUpdate()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Update(entity.Campaigns{ID: 1, Subject: "test", HTMLBody: "<p>test</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+
+	t.Run("should response campaign already sent when campaign is sent", func(t *testing.T) {
+		beforeEachUpdate()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSent}, nil)
+
+		err := service.Update(entity.Campaigns{ID: 1, Subject: "test", HTMLBody: "<p>test</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		repository.AssertNotCalled(t, "UpdateByID", mock.Anything)
+	})
+
+	t.Run("should keep current status when status is empty", func(t *testing.T) {
+		beforeEachUpdate()
+
+		err := service.Update(entity.Campaigns{ID: 1, Subject: "test", HTMLBody: "<p>test</p>"})
+
+		repository.AssertCalled(t, "UpdateByID", entity.Campaigns{ID: 1, Subject: "test", HTMLBody: "<p>test</p>", Status: entity.CampaignStatusDraft})
+		assert.Nil(t, err)
+	})
+
+	t.Run("should response internal server error when repository update failed", func(t *testing.T) {
+		beforeEachUpdate()
+		mockRepoUpdateByID.Return(errors.New("Error"))
+
+		err := service.Update(entity.Campaigns{ID: 1, Subject: "test", HTMLBody: "<p>test</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	beforeEachDelete := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(nil)
+	}
+
+	t.Run("should response campaign already sent when campaign is sending", func(t *testing.T) {
+		beforeEachDelete()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSending}, nil)
+
+		err := service.Delete(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		repository.AssertNotCalled(t, "DeleteByID", mock.Anything)
+	})
+
+	t.Run("should response campaign already sent when campaign is queued", func(t *testing.T) {
+		beforeEachDelete()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusQueued}, nil)
+
+		err := service.Delete(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		repository.AssertNotCalled(t, "DeleteByID", mock.Anything)
+	})
+
+	t.Run("should delete campaign when campaign is draft", func(t *testing.T) {
+		beforeEachDelete()
+
+		err := service.Delete(1)
+
+		repository.AssertCalled(t, "DeleteByID", int64(1))
+		assert.Nil(t, err)
+	})
+
+	t.Run("should response internal server error when repository delete failed", func(t *testing.T) {
+		beforeEachDelete()
+		mockRepoDeleteByID.Return(errors.New("Error"))
+
+		err := service.Delete(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_UpdateStatusByID(t *testing.T) {
+	t.Run("should response internal server error when repository update status failed", func(t *testing.T) {
+		beforeEach()
+		mockRepoUpdateStatusByID = mocker.NewMockCall(callRepoUpdateStatusByID)
+		mockRepoUpdateStatusByID.Return(errors.New("Error"))
+
+		err := service.UpdateStatusByID(1, entity.CampaignStatusSent)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Queue(t *testing.T) {
+	beforeEachQueue := func() {
+		beforeEach()
+
+		mockRepoUpdateStatusFromByID = mocker.NewMockCall(callRepoUpdateStatusFromByID)
+		mockRepoUpdateStatusFromByID.Return(true, nil)
+	}
+
+	t.Run("should move campaign to queued only from given statuses", func(t *testing.T) {
+		beforeEachQueue()
+
+		err := service.Queue(1, entity.CampaignSendableStatuses)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpdateStatusFromByID", int64(1), entity.CampaignSendableStatuses, entity.CampaignStatusQueued)
+	})
+
+	t.Run("should response delivery in progress when another request queued it", func(t *testing.T) {
+		beforeEachQueue()
+		mockRepoUpdateStatusFromByID.Return(false, nil)
+
+		err := service.Queue(1, entity.CampaignSendableStatuses)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress), err)
+	})
+
+	t.Run("should response internal server error when repository update status failed", func(t *testing.T) {
+		beforeEachQueue()
+		mockRepoUpdateStatusFromByID.Return(false, errors.New("Error"))
+
+		err := service.Queue(1, entity.CampaignSendableStatuses)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Send(t *testing.T) {
+	beforeEachSend := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+		mockServiceQueue = mocker.NewMockCall(callServiceQueue)
+		mockServiceQueue.Return(nil)
+	}
+
+	t.Run("should response campaign already sent when campaign is not editable", func(t *testing.T) {
+		beforeEachSend()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSent}, nil)
+
+		err := service.Send(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		mockUseCase.AssertNotCalled(t, "Queue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should response invalid template and keep status when template references unknown field", func(t *testing.T) {
+		beforeEachSend()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Subject: "{{.Unknown}}", Status: entity.CampaignStatusDraft}, nil)
+
+		err := service.Send(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidTemplate), err)
+		mockUseCase.AssertNotCalled(t, "Queue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error when queue failed", func(t *testing.T) {
+		beforeEachSend()
+		mockServiceQueue.Return(convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress))
+
+		err := service.Send(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress), err)
+	})
+
+	t.Run("should queue campaign from sendable statuses when send", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(1)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "Queue", int64(1), entity.CampaignSendableStatuses)
+	})
+}
+
+func TestService_Resume(t *testing.T) {
+	beforeEachResume := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusFailed}, nil)
+		mockServiceQueue = mocker.NewMockCall(callServiceQueue)
+		mockServiceQueue.Return(nil)
+	}
+
+	t.Run("should response invalid campaign status when campaign was never sent", func(t *testing.T) {
+		beforeEachResume()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+
+		err := service.Resume(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus), err)
+		mockUseCase.AssertNotCalled(t, "Queue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachResume()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Resume(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+
+	t.Run("should response delivery in progress when campaign is queued or sending", func(t *testing.T) {
+		for _, status := range []entity.CampaignStatus{entity.CampaignStatusQueued, entity.CampaignStatusSending} {
+			beforeEachResume()
+			mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: status}, nil)
+
+			err := service.Resume(1)
+
+			assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress), err)
+			mockUseCase.AssertNotCalled(t, "Queue", mock.Anything, mock.Anything)
+		}
+	})
+
+	t.Run("should queue campaign from resumable statuses when resume", func(t *testing.T) {
+		beforeEachResume()
+
+		err := service.Resume(1)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "Queue", int64(1), entity.CampaignResumableStatuses)
+	})
+}
+
+func TestService_GetDeliveries(t *testing.T) {
+	beforeEachGetDeliveries := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+		mockDeliveriesGetByCampaignID = mocker.NewMockCall(callDeliveriesGetByCampaignID)
+		mockDeliveriesGetByCampaignID.Return([]entity.Deliveries{{ID: 1, CampaignID: 1}}, nil)
+	}
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachGetDeliveries()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.GetDeliveries(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+		deliveriesService.AssertNotCalled(t, "GetByCampaignID", mock.Anything)
+	})
+
+	t.Run("should return deliveries of campaign", func(t *testing.T) {
+		beforeEachGetDeliveries()
+
+		res, err := service.GetDeliveries(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Deliveries{{ID: 1, CampaignID: 1}}, res)
+	})
+}
+
+func TestService_GetDeadLetters(t *testing.T) {
+	beforeEachGetDeadLetters := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+		mockDeliveriesGetDeadLetters = mocker.NewMockCall(callDeliveriesGetDeadLetters)
+		mockDeliveriesGetDeadLetters.Return([]entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusDead}}, nil)
+	}
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+		deliveriesService.AssertNotCalled(t, "GetDeadLetters", mock.Anything)
+	})
+
+	t.Run("should return dead letters of campaign", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Deliveries{{ID: 1, CampaignID: 1, Status: entity.DeliveryStatusDead}}, res)
+	})
+}
+
+func TestService_Redrive(t *testing.T) {
+	beforeEachRedrive := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusFailed}, nil)
+		mockDeliveriesRequeueDeadLetters = mocker.NewMockCall(callDeliveriesRequeueDeadLetters)
+		mockDeliveriesRequeueDeadLetters.Return(int64(2), nil)
+		mockServiceQueue = mocker.NewMockCall(callServiceQueue)
+		mockServiceQueue.Return(nil)
+	}
+
+	t.Run("should response invalid campaign status when campaign was never sent", func(t *testing.T) {
+		beforeEachRedrive()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+
+		err := service.Redrive(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidCampaignStatus), err)
+		deliveriesService.AssertNotCalled(t, "RequeueDeadLetters", mock.Anything)
+	})
+
+	t.Run("should response delivery in progress and keep dead letters when campaign is sending", func(t *testing.T) {
+		beforeEachRedrive()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSending}, nil)
+
+		err := service.Redrive(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignDeliveryInProgress), err)
+		deliveriesService.AssertNotCalled(t, "RequeueDeadLetters", mock.Anything)
+	})
+
+	t.Run("should return data not found when campaign has no dead letter", func(t *testing.T) {
+		beforeEachRedrive()
+		mockDeliveriesRequeueDeadLetters.Return(int64(0), convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Redrive(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		mockUseCase.AssertNotCalled(t, "Queue", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should requeue dead letters and queue campaign when redrive", func(t *testing.T) {
+		beforeEachRedrive()
+
+		err := service.Redrive(1)
+
+		assert.Nil(t, err)
+		deliveriesService.AssertCalled(t, "RequeueDeadLetters", int64(1))
+		mockUseCase.AssertCalled(t, "Queue", int64(1), entity.CampaignResumableStatuses)
+	})
+}
+
+func TestService_GetAttachments(t *testing.T) {
+	beforeEachGetAttachments := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+		mockAttachmentsGetByCampaignID = mocker.NewMockCall(callAttachmentsGetByCampaignID)
+		mockAttachmentsGetByCampaignID.Return([]entity.Attachments{{ID: 4, CampaignID: 1}}, nil)
+	}
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachGetAttachments()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.GetAttachments(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+		attachmentsService.AssertNotCalled(t, "GetByCampaignID", mock.Anything)
+	})
+
+	t.Run("should return attachments of campaign", func(t *testing.T) {
+		beforeEachGetAttachments()
+
+		res, err := service.GetAttachments(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Attachments{{ID: 4, CampaignID: 1}}, res)
+	})
+}
+
+func TestService_AddAttachment(t *testing.T) {
+	upload := attachments.Upload{CampaignID: 1, FileName: "report.pdf", Data: []byte("pdf")}
+
+	beforeEachAddAttachment := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+		mockAttachmentsUpload = mocker.NewMockCall(callAttachmentsUpload)
+		mockAttachmentsUpload.Return(&entity.Attachments{ID: 4, CampaignID: 1}, nil)
+	}
+
+	t.Run("should upload file to campaign that is not sent", func(t *testing.T) {
+		beforeEachAddAttachment()
+
+		res, err := service.AddAttachment(upload)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Attachments{ID: 4, CampaignID: 1}, res)
+		attachmentsService.AssertCalled(t, "Upload", upload)
+	})
+
+	t.Run("should return campaign already sent when campaign is sending", func(t *testing.T) {
+		beforeEachAddAttachment()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSending}, nil)
+
+		_, err := service.AddAttachment(upload)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		attachmentsService.AssertNotCalled(t, "Upload", mock.Anything)
+	})
+}
+
+func TestService_DeleteAttachment(t *testing.T) {
+	beforeEachDeleteAttachment := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+		mockAttachmentsFindByID = mocker.NewMockCall(callAttachmentsFindByID)
+		mockAttachmentsFindByID.Return(&entity.Attachments{ID: 4, CampaignID: 1}, nil)
+		mockAttachmentsDelete = mocker.NewMockCall(callAttachmentsDelete)
+		mockAttachmentsDelete.Return(nil)
+	}
+
+	t.Run("should delete attachment of campaign", func(t *testing.T) {
+		beforeEachDeleteAttachment()
+
+		err := service.DeleteAttachment(1, 4)
+
+		assert.Nil(t, err)
+		attachmentsService.AssertCalled(t, "Delete", int64(4))
+	})
+
+	t.Run("should return data not found when attachment belongs to another campaign", func(t *testing.T) {
+		beforeEachDeleteAttachment()
+		mockAttachmentsFindByID.Return(&entity.Attachments{ID: 4, CampaignID: 2}, nil)
+
+		err := service.DeleteAttachment(1, 4)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		attachmentsService.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+
+	t.Run("should return campaign already sent when campaign is sent", func(t *testing.T) {
+		beforeEachDeleteAttachment()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusSent}, nil)
+
+		err := service.DeleteAttachment(1, 4)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.CampaignAlreadySent), err)
+		attachmentsService.AssertNotCalled(t, "Delete", mock.Anything)
+	})
+}
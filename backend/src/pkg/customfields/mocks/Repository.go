@@ -0,0 +1,230 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.CustomFields, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 []entity.CustomFields
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.CustomFields, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.CustomFields); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByKey provides a mock function with given fields: key
+func (_m *Repository) FindByKey(key string) ([]entity.CustomFields, error) {
+	ret := _m.Called(key)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByKey")
+	}
+
+	var r0 []entity.CustomFields
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]entity.CustomFields, error)); ok {
+		return rf(key)
+	}
+	if rf, ok := ret.Get(0).(func(string) []entity.CustomFields); ok {
+		r0 = rf(key)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(key)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindValues provides a mock function with given fields: subscriberIDs
+func (_m *Repository) FindValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error) {
+	ret := _m.Called(subscriberIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindValues")
+	}
+
+	var r0 []entity.SubscriberFieldValues
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]int64) ([]entity.SubscriberFieldValues, error)); ok {
+		return rf(subscriberIDs)
+	}
+	if rf, ok := ret.Get(0).(func([]int64) []entity.SubscriberFieldValues); ok {
+		r0 = rf(subscriberIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubscriberFieldValues)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]int64) error); ok {
+		r1 = rf(subscriberIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllFields provides a mock function with no fields
+func (_m *Repository) GetAllFields() ([]entity.CustomFields, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllFields")
+	}
+
+	var r0 []entity.CustomFields
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.CustomFields, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.CustomFields); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: field
+func (_m *Repository) Insert(field entity.CustomFields) (int64, error) {
+	ret := _m.Called(field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) (int64, error)); ok {
+		return rf(field)
+	}
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) int64); ok {
+		r0 = rf(field)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.CustomFields) error); ok {
+		r1 = rf(field)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateByID provides a mock function with given fields: field
+func (_m *Repository) UpdateByID(field entity.CustomFields) error {
+	ret := _m.Called(field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) error); ok {
+		r0 = rf(field)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpsertValues provides a mock function with given fields: subscriberID, values
+func (_m *Repository) UpsertValues(subscriberID int64, values []entity.SubscriberFieldValues) error {
+	ret := _m.Called(subscriberID, values)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpsertValues")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []entity.SubscriberFieldValues) error); ok {
+		r0 = rf(subscriberID, values)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,258 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: field
+func (_m *UseCase) Create(field entity.CustomFields) (*entity.CustomFields, *error.ErrorCode) {
+	ret := _m.Called(field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.CustomFields
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) (*entity.CustomFields, *error.ErrorCode)); ok {
+		return rf(field)
+	}
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) *entity.CustomFields); ok {
+		r0 = rf(field)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.CustomFields) *error.ErrorCode); ok {
+		r1 = rf(field)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *UseCase) Delete(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.CustomFields, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *entity.CustomFields
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.CustomFields, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.CustomFields); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllFields provides a mock function with no fields
+func (_m *UseCase) GetAllFields() ([]entity.CustomFields, *error.ErrorCode) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllFields")
+	}
+
+	var r0 []entity.CustomFields
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() ([]entity.CustomFields, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.CustomFields); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.CustomFields)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetValues provides a mock function with given fields: subscriberIDs
+func (_m *UseCase) GetValues(subscriberIDs []int64) (map[int64]map[string]interface{}, *error.ErrorCode) {
+	ret := _m.Called(subscriberIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetValues")
+	}
+
+	var r0 map[int64]map[string]interface{}
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func([]int64) (map[int64]map[string]interface{}, *error.ErrorCode)); ok {
+		return rf(subscriberIDs)
+	}
+	if rf, ok := ret.Get(0).(func([]int64) map[int64]map[string]interface{}); ok {
+		r0 = rf(subscriberIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]int64) *error.ErrorCode); ok {
+		r1 = rf(subscriberIDs)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// SaveValues provides a mock function with given fields: subscriberID, values
+func (_m *UseCase) SaveValues(subscriberID int64, values map[string]interface{}) *error.ErrorCode {
+	ret := _m.Called(subscriberID, values)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveValues")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, map[string]interface{}) *error.ErrorCode); ok {
+		r0 = rf(subscriberID, values)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: field
+func (_m *UseCase) Update(field entity.CustomFields) *error.ErrorCode {
+	ret := _m.Called(field)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.CustomFields) *error.ErrorCode); ok {
+		r0 = rf(field)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// ValidateValues provides a mock function with given fields: values
+func (_m *UseCase) ValidateValues(values map[string]interface{}) (map[string]interface{}, []error.FieldError, *error.ErrorCode) {
+	ret := _m.Called(values)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateValues")
+	}
+
+	var r0 map[string]interface{}
+	var r1 []error.FieldError
+	var r2 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) (map[string]interface{}, []error.FieldError, *error.ErrorCode)); ok {
+		return rf(values)
+	}
+	if rf, ok := ret.Get(0).(func(map[string]interface{}) map[string]interface{}); ok {
+		r0 = rf(values)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[string]interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(map[string]interface{}) []error.FieldError); ok {
+		r1 = rf(values)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]error.FieldError)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(map[string]interface{}) *error.ErrorCode); ok {
+		r2 = rf(values)
+	} else {
+		if ret.Get(2) != nil {
+			r2 = ret.Get(2).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1, r2
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
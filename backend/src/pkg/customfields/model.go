@@ -0,0 +1,36 @@
+package customfields
+
+import (
+	"newsletter/src/pkg/entity"
+	"regexp"
+)
+
+const (
+	// DateLayout is how date values are sent and returned.
+	DateLayout = "2006-01-02"
+
+	OptionSeparator = ","
+
+	MaxKeyLength     = 64
+	MaxOptionsLength = 1000
+
+	// ValuesBatchSize is how many subscribers GetValues reads per query, well
+	// under the parameter limit of a single statement.
+	ValuesBatchSize = 1000
+
+	// FieldsField prefixes the field errors of custom values, as in
+	// "fields.country".
+	FieldsField = "fields"
+)
+
+// FieldTypes lists the types a custom field can have.
+var FieldTypes = []entity.FieldType{
+	entity.FieldTypeString,
+	entity.FieldTypeNumber,
+	entity.FieldTypeDate,
+	entity.FieldTypeBool,
+	entity.FieldTypeEnum,
+}
+
+// keyPattern keeps keys usable as template map keys and in segment rules.
+var keyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
@@ -0,0 +1,337 @@
+package customfields
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+	"strings"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	GetAllFields() ([]entity.CustomFields, error)
+	FindByID(id int64) ([]entity.CustomFields, error)
+	FindByKey(key string) ([]entity.CustomFields, error)
+	Insert(field entity.CustomFields) (int64, error)
+	UpdateByID(field entity.CustomFields) error
+	DeleteByID(id int64) error
+	UpsertValues(subscriberID int64, values []entity.SubscriberFieldValues) error
+	FindValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error)
+}
+
+// SqlRepository keeps field definitions in Collection and the values
+// subscribers have for them in ValuesCollection.
+type SqlRepository struct {
+	Collection       string
+	ValuesCollection string
+	Session          *sql.DB
+	Logs             logger.Logger
+}
+
+func NewRepository(collection string, valuesCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection:       collection,
+		ValuesCollection: valuesCollection,
+		Session:          session,
+		Logs:             logs,
+	}
+}
+
+func (repo *SqlRepository) GetAllFields() ([]entity.CustomFields, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	ORDER BY Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.CustomFields{}, []string{}),
+		repo.Collection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_GetAllFields", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.CustomFields{}
+	for rows.Next() {
+		var entity entity.CustomFields
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.CustomFields, error) {
+	return repo.findBy("Id", id, "customfields_Repo_FindByID")
+}
+
+func (repo *SqlRepository) FindByKey(key string) ([]entity.CustomFields, error) {
+	return repo.findBy("[Key]", key, "customfields_Repo_FindByKey")
+}
+
+func (repo *SqlRepository) findBy(column string, value interface{}, actionName string) ([]entity.CustomFields, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND %[3]s = %[4]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.CustomFields{}, []string{}),
+		repo.Collection,
+		column,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, value)
+	if err != nil {
+		go repo.Logs.Error("", actionName, value, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.CustomFields{}
+	for rows.Next() {
+		var entity entity.CustomFields
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Insert(field entity.CustomFields) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "CreatedDate", "UpdatedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(field, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.CustomFields{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_Insert", field,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// UpdateByID changes how a field is shown and checked. Key and Type are left
+// alone so stored values keep their meaning.
+func (repo *SqlRepository) UpdateByID(field entity.CustomFields) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(field, []string{"Label", "Options", "IsRequired"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		%[2]s,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{field.ID}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_UpdateByID", field,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteByID hides a field. Its values are kept but no longer read, and the
+// key can be used by a new field.
+func (repo *SqlRepository) DeleteByID(id int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Delflag = 1,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// UpsertValues stores the given values for a subscriber in one statement,
+// replacing any value they already had for the same field. Values for other
+// fields are left alone.
+func (repo *SqlRepository) UpsertValues(subscriberID int64, values []entity.SubscriberFieldValues) error {
+	if len(values) == 0 {
+		return nil
+	}
+
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{subscriberID}
+	rows := []string{}
+	for _, value := range values {
+		params := []string{}
+		for _, arg := range []interface{}{value.FieldID, value.StringValue, value.NumberValue, value.DateValue, value.BoolValue} {
+			args = append(args, arg)
+			params = append(params, sqlQuery.Param(len(args)))
+		}
+		rows = append(rows, fmt.Sprintf("(%s)", strings.Join(params, ",")))
+	}
+
+	sql := fmt.Sprintf(`
+	MERGE %[1]s WITH (HOLDLOCK) AS target
+	USING (VALUES %[3]s) AS source ([FieldId], [StringValue], [NumberValue], [DateValue], [BoolValue])
+	ON target.SubscriberId = %[2]s
+	AND target.FieldId = source.FieldId
+	WHEN MATCHED THEN
+		UPDATE SET
+			StringValue = source.StringValue,
+			NumberValue = source.NumberValue,
+			DateValue = source.DateValue,
+			BoolValue = source.BoolValue,
+			UpdatedDate = GETDATE()
+	WHEN NOT MATCHED THEN
+		INSERT ([SubscriberId], [FieldId], [StringValue], [NumberValue], [DateValue], [BoolValue], [UpdatedDate])
+		VALUES (%[2]s, source.FieldId, source.StringValue, source.NumberValue, source.DateValue, source.BoolValue, GETDATE());
+	`,
+		repo.ValuesCollection,
+		sqlQuery.Param(1),
+		strings.Join(rows, ","),
+	)
+
+	_, err := session.ExecContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_UpsertValues", subscriberID,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindValues returns the values the given subscribers have for fields that
+// are not deleted.
+func (repo *SqlRepository) FindValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error) {
+	if len(subscriberIDs) == 0 {
+		return []entity.SubscriberFieldValues{}, nil
+	}
+
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{}
+	params := []string{}
+	for _, subscriberID := range subscriberIDs {
+		args = append(args, subscriberID)
+		params = append(params, sqlQuery.Param(len(args)))
+	}
+
+	sql := fmt.Sprintf(`
+	SELECT v.[SubscriberId],v.[FieldId],f.[Key],f.[Type],v.[StringValue],v.[NumberValue],v.[DateValue],v.[BoolValue]
+	FROM %[1]s v
+	INNER JOIN %[2]s f ON f.Id = v.FieldId
+	WHERE f.Delflag = 0
+	AND v.SubscriberId IN (%[3]s)
+	ORDER BY v.SubscriberId ASC, f.Id ASC
+	`,
+		repo.ValuesCollection,
+		repo.Collection,
+		strings.Join(params, ","),
+	)
+	rows, err := session.QueryContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "customfields_Repo_FindValues", subscriberIDs, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.SubscriberFieldValues{}
+	for rows.Next() {
+		var entity entity.SubscriberFieldValues
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
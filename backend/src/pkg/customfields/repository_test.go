@@ -0,0 +1,120 @@
+package customfields_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *customfields.SqlRepository) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	return mockDB, customfields.NewRepository("TB_MAS_CustomFields", "TB_TRN_SubscriberFieldValues", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should insert field and return new id", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`INSERT INTO TB_MAS_CustomFields(.|\s)+OUTPUT INSERTED.Id`).
+			WithArgs("plan", "Plan", entity.FieldTypeEnum, "free,pro", true).
+			WillReturnRows(sqlmock.NewRows([]string{"Id"}).AddRow(3))
+
+		id, err := repo.Insert(entity.CustomFields{Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,pro", IsRequired: true})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FindByKey(t *testing.T) {
+	t.Run("should only find field that is not deleted", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`FROM TB_MAS_CustomFields\s+WHERE Delflag = 0\s+AND \[Key\] = @p1`).
+			WithArgs("plan").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "key"}).AddRow(3, "plan"))
+
+		res, err := repo.FindByKey("plan")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.CustomFields{{ID: 3, Key: "plan"}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_UpsertValues(t *testing.T) {
+	t.Run("should merge every value in one statement", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		plan := "pro"
+		age := float64(30)
+		sqlMock.ExpectExec(`MERGE TB_TRN_SubscriberFieldValues WITH \(HOLDLOCK\) AS target\s+USING \(VALUES \(@p2,@p3,@p4,@p5,@p6\),\(@p7,@p8,@p9,@p10,@p11\)\)(.|\s)+ON target.SubscriberId = @p1(.|\s)+WHEN NOT MATCHED THEN\s+INSERT`).
+			WithArgs(int64(7), int64(1), &plan, nil, nil, nil, int64(2), nil, &age, nil, nil).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repo.UpsertValues(7, []entity.SubscriberFieldValues{
+			{SubscriberID: 7, FieldID: 1, StringValue: &plan},
+			{SubscriberID: 7, FieldID: 2, NumberValue: &age},
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should not query when there is no value", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+
+		err := repo.UpsertValues(7, []entity.SubscriberFieldValues{})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec("MERGE").WillReturnError(errors.New("Error"))
+
+		err := repo.UpsertValues(7, []entity.SubscriberFieldValues{{FieldID: 1}})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_FindValues(t *testing.T) {
+	t.Run("should return values of fields that are not deleted", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		birthday := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+		sqlMock.ExpectQuery(`FROM TB_TRN_SubscriberFieldValues v\s+INNER JOIN TB_MAS_CustomFields f ON f.Id = v.FieldId\s+WHERE f.Delflag = 0\s+AND v.SubscriberId IN \(@p1,@p2\)`).
+			WithArgs(int64(7), int64(8)).
+			WillReturnRows(sqlmock.NewRows([]string{"SubscriberId", "FieldId", "Key", "Type", "DateValue"}).AddRow(7, 3, "birthday", "date", birthday))
+
+		res, err := repo.FindValues([]int64{7, 8})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.SubscriberFieldValues{{SubscriberID: 7, FieldID: 3, Key: "birthday", Type: entity.FieldTypeDate, DateValue: &birthday}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should not query when there is no subscriber", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+
+		res, err := repo.FindValues([]int64{})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.SubscriberFieldValues{}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
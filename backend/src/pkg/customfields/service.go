@@ -0,0 +1,292 @@
+package customfields
+
+import (
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/validation"
+	"strings"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+type UseCase interface {
+	GetAllFields() ([]entity.CustomFields, *newsletterError.ErrorCode)
+	FindByID(id int64) (*entity.CustomFields, *newsletterError.ErrorCode)
+	Create(field entity.CustomFields) (*entity.CustomFields, *newsletterError.ErrorCode)
+	Update(field entity.CustomFields) *newsletterError.ErrorCode
+	Delete(id int64) *newsletterError.ErrorCode
+	ValidateValues(values map[string]interface{}) (map[string]interface{}, []newsletterError.FieldError, *newsletterError.ErrorCode)
+	SaveValues(subscriberID int64, values map[string]interface{}) *newsletterError.ErrorCode
+	GetValues(subscriberIDs []int64) (map[int64]map[string]interface{}, *newsletterError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo Repository
+	Logs logger.Logger
+}
+
+func NewService(repo Repository, logs logger.Logger) *Service {
+	service := &Service{
+		Repo: repo,
+		Logs: logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetAllFields() ([]entity.CustomFields, *newsletterError.ErrorCode) {
+	res, err := service.Repo.GetAllFields()
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) FindByID(id int64) (*entity.CustomFields, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+func (service *Service) Create(field entity.CustomFields) (*entity.CustomFields, *newsletterError.ErrorCode) {
+	field, errValidate := validateField(field)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	resKey, errKey := service.Repo.FindByKey(field.Key)
+	if errKey != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(resKey) > 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DuplicateFieldKey)
+	}
+
+	id, err := service.Repo.Insert(field)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.UseCase.FindByID(id)
+}
+
+// Update changes the label, options and whether a field is required. The key
+// and type of a field are fixed once it is created.
+func (service *Service) Update(field entity.CustomFields) *newsletterError.ErrorCode {
+	resField, err := service.UseCase.FindByID(field.ID)
+	if err != nil {
+		return err
+	}
+
+	field.Key = resField.Key
+	field.Type = resField.Type
+	field, errValidate := validateField(field)
+	if errValidate != nil {
+		return errValidate
+	}
+
+	errUpdate := service.Repo.UpdateByID(field)
+	if errUpdate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) Delete(id int64) *newsletterError.ErrorCode {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errDelete := service.Repo.DeleteByID(id)
+	if errDelete != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// ValidateValues checks values against the fields currently defined, see
+// the ValidateValues function.
+func (service *Service) ValidateValues(values map[string]interface{}) (map[string]interface{}, []newsletterError.FieldError, *newsletterError.ErrorCode) {
+	definitions, err := service.Repo.GetAllFields()
+	if err != nil {
+		return nil, nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	normalized, fieldErrors := ValidateValues(definitions, values)
+	return normalized, fieldErrors, nil
+}
+
+// SaveValues stores values that went through ValidateValues. Keys that are
+// no longer defined are skipped.
+func (service *Service) SaveValues(subscriberID int64, values map[string]interface{}) *newsletterError.ErrorCode {
+	if len(values) == 0 {
+		return nil
+	}
+
+	definitions, err := service.Repo.GetAllFields()
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	fieldValues := []entity.SubscriberFieldValues{}
+	for _, definition := range definitions {
+		value, ok := values[definition.Key]
+		if !ok {
+			continue
+		}
+
+		fieldValue, ok := toFieldValue(definition, value)
+		if !ok {
+			continue
+		}
+		fieldValue.SubscriberID = subscriberID
+		fieldValues = append(fieldValues, fieldValue)
+	}
+
+	errUpsert := service.Repo.UpsertValues(subscriberID, fieldValues)
+	if errUpsert != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// GetValues returns the values of each subscriber by field key. Subscribers
+// without values are left out of the map.
+func (service *Service) GetValues(subscriberIDs []int64) (map[int64]map[string]interface{}, *newsletterError.ErrorCode) {
+	values := map[int64]map[string]interface{}{}
+
+	for start := 0; start < len(subscriberIDs); start += ValuesBatchSize {
+		end := start + ValuesBatchSize
+		if end > len(subscriberIDs) {
+			end = len(subscriberIDs)
+		}
+
+		res, err := service.Repo.FindValues(subscriberIDs[start:end])
+		if err != nil {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		}
+
+		for _, fieldValue := range res {
+			value := fromFieldValue(fieldValue)
+			if value == nil {
+				continue
+			}
+			if values[fieldValue.SubscriberID] == nil {
+				values[fieldValue.SubscriberID] = map[string]interface{}{}
+			}
+			values[fieldValue.SubscriberID][fieldValue.Key] = value
+		}
+	}
+
+	return values, nil
+}
+
+func toFieldValue(definition entity.CustomFields, value interface{}) (entity.SubscriberFieldValues, bool) {
+	fieldValue := entity.SubscriberFieldValues{FieldID: definition.ID, Key: definition.Key, Type: definition.Type}
+
+	switch definition.Type {
+	case entity.FieldTypeNumber:
+		number, ok := value.(float64)
+		fieldValue.NumberValue = &number
+		return fieldValue, ok
+
+	case entity.FieldTypeBool:
+		boolean, ok := value.(bool)
+		fieldValue.BoolValue = &boolean
+		return fieldValue, ok
+
+	case entity.FieldTypeDate:
+		text, _ := value.(string)
+		date, err := time.Parse(DateLayout, text)
+		fieldValue.DateValue = &date
+		return fieldValue, err == nil
+	}
+
+	text, ok := value.(string)
+	fieldValue.StringValue = &text
+	return fieldValue, ok
+}
+
+func fromFieldValue(fieldValue entity.SubscriberFieldValues) interface{} {
+	switch fieldValue.Type {
+	case entity.FieldTypeNumber:
+		if fieldValue.NumberValue != nil {
+			return *fieldValue.NumberValue
+		}
+
+	case entity.FieldTypeBool:
+		if fieldValue.BoolValue != nil {
+			return *fieldValue.BoolValue
+		}
+
+	case entity.FieldTypeDate:
+		if fieldValue.DateValue != nil {
+			return fieldValue.DateValue.Format(DateLayout)
+		}
+
+	default:
+		if fieldValue.StringValue != nil {
+			return *fieldValue.StringValue
+		}
+	}
+	return nil
+}
+
+func validateField(field entity.CustomFields) (entity.CustomFields, *newsletterError.ErrorCode) {
+	field.Key = strings.TrimSpace(field.Key)
+	if !keyPattern.MatchString(field.Key) || len(field.Key) > MaxKeyLength {
+		return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	label, errLabel := validation.RequiredText(field.Label, validation.MaxLength)
+	if errLabel != nil {
+		return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	field.Label = label
+
+	if !funk.Contains(FieldTypes, field.Type) {
+		return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	options := Options(field)
+	if field.Type != entity.FieldTypeEnum {
+		if len(options) > 0 {
+			return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+		}
+		field.Options = ""
+		return field, nil
+	}
+
+	if len(options) == 0 || len(funk.UniqString(options)) != len(options) {
+		return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	field.Options = strings.Join(options, OptionSeparator)
+	if len([]rune(field.Options)) > MaxOptionsLength {
+		return field, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	return field, nil
+}
@@ -0,0 +1,434 @@
+package customfields_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/customfields/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase *mocks.UseCase
+	repository  *mocks.Repository
+	service     *customfields.Service
+	logs        *loggerMocks.Logger
+
+	mockRepoGetAllFields *mocker.MockCall
+	mockRepoFindByID     *mocker.MockCall
+	mockRepoFindByKey    *mocker.MockCall
+	mockRepoInsert       *mocker.MockCall
+	mockRepoUpdateByID   *mocker.MockCall
+	mockRepoDeleteByID   *mocker.MockCall
+	mockRepoUpsertValues *mocker.MockCall
+	mockRepoFindValues   *mocker.MockCall
+	mockServiceFindByID  *mocker.MockCall
+)
+
+var definitions = []entity.CustomFields{
+	{ID: 1, Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,pro"},
+	{ID: 2, Key: "age", Label: "Age", Type: entity.FieldTypeNumber},
+	{ID: 3, Key: "birthday", Label: "Birthday", Type: entity.FieldTypeDate},
+	{ID: 4, Key: "vip", Label: "VIP", Type: entity.FieldTypeBool},
+	{ID: 5, Key: "company", Label: "Company", Type: entity.FieldTypeString},
+}
+
+func callRepoGetAllFields() *mock.Call {
+	return repository.On("GetAllFields")
+}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoFindByKey() *mock.Call {
+	return repository.On("FindByKey", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoUpdateByID() *mock.Call {
+	return repository.On("UpdateByID", mock.Anything)
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callRepoUpsertValues() *mock.Call {
+	return repository.On("UpsertValues", mock.Anything, mock.Anything)
+}
+
+func callRepoFindValues() *mock.Call {
+	return repository.On("FindValues", mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &customfields.Service{
+		Repo: repository,
+		Logs: logs,
+	}
+	service.UseCase = mockUseCase
+
+	mockRepoGetAllFields = mocker.NewMockCall(callRepoGetAllFields)
+	mockRepoGetAllFields.Return(definitions, nil)
+	mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+	mockServiceFindByID.Return(&entity.CustomFields{ID: 1, Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,pro"}, nil)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct custom fields service when call new service", func(t *testing.T) {
+		beforeEach()
+		resService := customfields.NewService(repository, logs)
+
+		expectedService := &customfields.Service{
+			Repo: repository,
+			Logs: logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_GetAllFields(t *testing.T) {
+	t.Run("should return fields when found", func(t *testing.T) {
+		beforeEach()
+
+		res, err := service.GetAllFields()
+
+		assert.Nil(t, err)
+		assert.Equal(t, definitions, res)
+	})
+
+	t.Run("should return data not found when there is no field", func(t *testing.T) {
+		beforeEach()
+		mockRepoGetAllFields.Return([]entity.CustomFields{}, nil)
+
+		res, err := service.GetAllFields()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEach()
+		mockRepoGetAllFields.Return(nil, errors.New("Error"))
+
+		_, err := service.GetAllFields()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.CustomFields{{ID: 1}}, nil)
+	}
+
+	t.Run("should return first field when found", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.CustomFields{ID: 1}, res)
+	})
+
+	t.Run("should return data not found when field is missing or deleted", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.CustomFields{}, nil)
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	beforeEachCreate := func() {
+		beforeEach()
+		mockRepoFindByKey = mocker.NewMockCall(callRepoFindByKey)
+		mockRepoFindByKey.Return([]entity.CustomFields{}, nil)
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(1), nil)
+	}
+
+	t.Run("should insert trimmed field and return it", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.CustomFields{Key: " plan ", Label: " Plan ", Type: entity.FieldTypeEnum, Options: " free , pro ,"})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Insert", entity.CustomFields{Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,pro"})
+		mockUseCase.AssertCalled(t, "FindByID", int64(1))
+		assert.Equal(t, int64(1), res.ID)
+	})
+
+	t.Run("should clear options of field that is not enum", func(t *testing.T) {
+		beforeEachCreate()
+
+		service.Create(entity.CustomFields{Key: "age", Label: "Age", Type: entity.FieldTypeNumber, Options: " "})
+
+		repository.AssertCalled(t, "Insert", entity.CustomFields{Key: "age", Label: "Age", Type: entity.FieldTypeNumber})
+	})
+
+	invalidFields := map[string]entity.CustomFields{
+		"key starts with digit":          {Key: "1plan", Label: "Plan", Type: entity.FieldTypeString},
+		"key has space":                  {Key: "my plan", Label: "Plan", Type: entity.FieldTypeString},
+		"label is empty":                 {Key: "plan", Label: " ", Type: entity.FieldTypeString},
+		"type is unknown":                {Key: "plan", Label: "Plan", Type: "json"},
+		"enum has no option":             {Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: " , "},
+		"enum has duplicate option":      {Key: "plan", Label: "Plan", Type: entity.FieldTypeEnum, Options: "free,free"},
+		"field that is not enum has one": {Key: "plan", Label: "Plan", Type: entity.FieldTypeString, Options: "free"},
+	}
+	for name, field := range invalidFields {
+		field := field
+		t.Run("should return bad request when "+name, func(t *testing.T) {
+			beforeEachCreate()
+
+			res, err := service.Create(field)
+
+			assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+			assert.Nil(t, res)
+			repository.AssertNotCalled(t, "Insert", mock.Anything)
+		})
+	}
+
+	t.Run("should return duplicate field key when key is taken", func(t *testing.T) {
+		beforeEachCreate()
+		mockRepoFindByKey.Return([]entity.CustomFields{{ID: 9, Key: "plan"}}, nil)
+
+		res, err := service.Create(entity.CustomFields{Key: "plan", Label: "Plan", Type: entity.FieldTypeString})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DuplicateFieldKey), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return internal server error when insert failed", func(t *testing.T) {
+		beforeEachCreate()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		_, err := service.Create(entity.CustomFields{Key: "plan", Label: "Plan", Type: entity.FieldTypeString})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	beforeEachUpdate := func() {
+		beforeEach()
+		mockRepoUpdateByID = mocker.NewMockCall(callRepoUpdateByID)
+		mockRepoUpdateByID.Return(nil)
+	}
+
+	t.Run("should keep key and type of existing field", func(t *testing.T) {
+		beforeEachUpdate()
+
+		err := service.Update(entity.CustomFields{ID: 1, Key: "tier", Label: "Tier", Type: entity.FieldTypeString, Options: "free,pro,team", IsRequired: true})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpdateByID", entity.CustomFields{ID: 1, Key: "plan", Label: "Tier", Type: entity.FieldTypeEnum, Options: "free,pro,team", IsRequired: true})
+	})
+
+	t.Run("should return data not found when field is missing", func(t *testing.T) {
+		beforeEachUpdate()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Update(entity.CustomFields{ID: 1, Label: "Plan"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "UpdateByID", mock.Anything)
+	})
+
+	t.Run("should return bad request when enum loses every option", func(t *testing.T) {
+		beforeEachUpdate()
+
+		err := service.Update(entity.CustomFields{ID: 1, Label: "Plan"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		repository.AssertNotCalled(t, "UpdateByID", mock.Anything)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	beforeEachDelete := func() {
+		beforeEach()
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(nil)
+	}
+
+	t.Run("should delete field when found", func(t *testing.T) {
+		beforeEachDelete()
+
+		err := service.Delete(1)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "DeleteByID", int64(1))
+	})
+
+	t.Run("should return data not found when field is missing", func(t *testing.T) {
+		beforeEachDelete()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Delete(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "DeleteByID", mock.Anything)
+	})
+}
+
+func TestService_ValidateValues(t *testing.T) {
+	t.Run("should validate values against current definitions", func(t *testing.T) {
+		beforeEach()
+
+		res, fieldErrors, err := service.ValidateValues(map[string]interface{}{"plan": "pro", "team": "a"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[string]interface{}{"plan": "pro"}, res)
+		assert.Equal(t, []newsletterError.FieldError{{Field: "fields.team", Code: newsletterError.FieldUnknown}}, fieldErrors)
+	})
+
+	t.Run("should return internal server error when read definitions failed", func(t *testing.T) {
+		beforeEach()
+		mockRepoGetAllFields.Return(nil, errors.New("Error"))
+
+		_, _, err := service.ValidateValues(map[string]interface{}{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_SaveValues(t *testing.T) {
+	beforeEachSaveValues := func() {
+		beforeEach()
+		mockRepoUpsertValues = mocker.NewMockCall(callRepoUpsertValues)
+		mockRepoUpsertValues.Return(nil)
+	}
+
+	t.Run("should store each value in column of its type", func(t *testing.T) {
+		beforeEachSaveValues()
+		plan := "pro"
+		age := float64(30)
+		birthday := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+		vip := true
+
+		err := service.SaveValues(7, map[string]interface{}{"plan": plan, "age": age, "birthday": "1990-01-02", "vip": vip})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpsertValues", int64(7), []entity.SubscriberFieldValues{
+			{SubscriberID: 7, FieldID: 1, Key: "plan", Type: entity.FieldTypeEnum, StringValue: &plan},
+			{SubscriberID: 7, FieldID: 2, Key: "age", Type: entity.FieldTypeNumber, NumberValue: &age},
+			{SubscriberID: 7, FieldID: 3, Key: "birthday", Type: entity.FieldTypeDate, DateValue: &birthday},
+			{SubscriberID: 7, FieldID: 4, Key: "vip", Type: entity.FieldTypeBool, BoolValue: &vip},
+		})
+	})
+
+	t.Run("should skip keys that are no longer defined", func(t *testing.T) {
+		beforeEachSaveValues()
+
+		err := service.SaveValues(7, map[string]interface{}{"team": "a"})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpsertValues", int64(7), []entity.SubscriberFieldValues{})
+	})
+
+	t.Run("should not read definitions when there is no value", func(t *testing.T) {
+		beforeEachSaveValues()
+
+		err := service.SaveValues(7, map[string]interface{}{})
+
+		assert.Nil(t, err)
+		repository.AssertNotCalled(t, "GetAllFields")
+		repository.AssertNotCalled(t, "UpsertValues", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return internal server error when upsert failed", func(t *testing.T) {
+		beforeEachSaveValues()
+		mockRepoUpsertValues.Return(errors.New("Error"))
+
+		err := service.SaveValues(7, map[string]interface{}{"plan": "pro"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_GetValues(t *testing.T) {
+	beforeEachGetValues := func() {
+		beforeEach()
+		mockRepoFindValues = mocker.NewMockCall(callRepoFindValues)
+		mockRepoFindValues.Return([]entity.SubscriberFieldValues{}, nil)
+	}
+
+	t.Run("should group values by subscriber and key", func(t *testing.T) {
+		beforeEachGetValues()
+		plan := "pro"
+		age := float64(30)
+		birthday := time.Date(1990, 1, 2, 0, 0, 0, 0, time.UTC)
+		vip := false
+		mockRepoFindValues.Return([]entity.SubscriberFieldValues{
+			{SubscriberID: 7, Key: "plan", Type: entity.FieldTypeEnum, StringValue: &plan},
+			{SubscriberID: 7, Key: "age", Type: entity.FieldTypeNumber, NumberValue: &age},
+			{SubscriberID: 8, Key: "birthday", Type: entity.FieldTypeDate, DateValue: &birthday},
+			{SubscriberID: 8, Key: "vip", Type: entity.FieldTypeBool, BoolValue: &vip},
+			{SubscriberID: 8, Key: "company", Type: entity.FieldTypeString},
+		}, nil)
+
+		res, err := service.GetValues([]int64{7, 8, 9})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[int64]map[string]interface{}{
+			7: {"plan": "pro", "age": float64(30)},
+			8: {"birthday": "1990-01-02", "vip": false},
+		}, res)
+	})
+
+	t.Run("should read subscribers in batches", func(t *testing.T) {
+		beforeEachGetValues()
+		subscriberIDs := make([]int64, customfields.ValuesBatchSize+1)
+		for i := range subscriberIDs {
+			subscriberIDs[i] = int64(i + 1)
+		}
+
+		_, err := service.GetValues(subscriberIDs)
+
+		assert.Nil(t, err)
+		repository.AssertNumberOfCalls(t, "FindValues", 2)
+		repository.AssertCalled(t, "FindValues", subscriberIDs[:customfields.ValuesBatchSize])
+		repository.AssertCalled(t, "FindValues", subscriberIDs[customfields.ValuesBatchSize:])
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachGetValues()
+		mockRepoFindValues.Return(nil, errors.New("Error"))
+
+		res, err := service.GetValues([]int64{7})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,125 @@
+package customfields
+
+import (
+	"math"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/validation"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+// ValidateValues checks the custom values of a payload against definitions
+// and returns them normalized: text trimmed, numbers as float64, dates as
+// DateLayout strings and bools as bool. Empty values are dropped, so a
+// required field without a value is reported as missing.
+func ValidateValues(definitions []entity.CustomFields, values map[string]interface{}) (map[string]interface{}, []newsletterError.FieldError) {
+	fieldErrors := []newsletterError.FieldError{}
+	normalized := map[string]interface{}{}
+
+	defined := map[string]bool{}
+	for _, definition := range definitions {
+		defined[definition.Key] = true
+
+		value, errValue := normalizeValue(definition, values[definition.Key])
+		if errValue != nil {
+			fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: valueField(definition.Key), Code: *errValue})
+			continue
+		}
+
+		if value == nil {
+			if definition.IsRequired {
+				fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: valueField(definition.Key), Code: newsletterError.FieldRequired})
+			}
+			continue
+		}
+		normalized[definition.Key] = value
+	}
+
+	unknown := []string{}
+	for key := range values {
+		if !defined[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: valueField(key), Code: newsletterError.FieldUnknown})
+	}
+
+	return normalized, fieldErrors
+}
+
+// normalizeValue returns nil without an error when there is no value.
+func normalizeValue(definition entity.CustomFields, value interface{}) (interface{}, *newsletterError.ErrorCode) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch definition.Type {
+	case entity.FieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidType)
+		}
+		return number, nil
+
+	case entity.FieldTypeBool:
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidType)
+		}
+		return boolean, nil
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidType)
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, nil
+	}
+
+	switch definition.Type {
+	case entity.FieldTypeDate:
+		date, err := time.Parse(DateLayout, text)
+		if err != nil {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidDate)
+		}
+		return date.Format(DateLayout), nil
+
+	case entity.FieldTypeEnum:
+		if !funk.ContainsString(Options(definition), text) {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidOption)
+		}
+		return text, nil
+	}
+
+	text, errText := validation.RequiredText(text, validation.MaxLength)
+	if errText != nil {
+		return nil, errText
+	}
+	return text, nil
+}
+
+// Options returns the values an enum field accepts, in the order they were
+// defined.
+func Options(definition entity.CustomFields) []string {
+	options := []string{}
+	for _, option := range strings.Split(definition.Options, OptionSeparator) {
+		option = strings.TrimSpace(option)
+		if option != "" {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+func valueField(key string) string {
+	return FieldsField + "." + key
+}
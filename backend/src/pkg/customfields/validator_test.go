@@ -0,0 +1,77 @@
+package customfields_test
+
+import (
+	"math"
+	"newsletter/src/pkg/customfields"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateValues(t *testing.T) {
+	t.Run("should return values normalized by type", func(t *testing.T) {
+		res, fieldErrors := customfields.ValidateValues(definitions, map[string]interface{}{
+			"plan":     " pro ",
+			"age":      float64(30),
+			"birthday": "1990-01-02",
+			"vip":      false,
+			"company":  " acme ",
+		})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, map[string]interface{}{
+			"plan":     "pro",
+			"age":      float64(30),
+			"birthday": "1990-01-02",
+			"vip":      false,
+			"company":  "acme",
+		}, res)
+	})
+
+	t.Run("should drop empty values", func(t *testing.T) {
+		res, fieldErrors := customfields.ValidateValues(definitions, map[string]interface{}{"company": " ", "plan": nil})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, map[string]interface{}{}, res)
+	})
+
+	t.Run("should return field required when required field has no value", func(t *testing.T) {
+		required := append(definitions[:0:0], definitions...)
+		required[4].IsRequired = true
+
+		_, fieldErrors := customfields.ValidateValues(required, map[string]interface{}{"company": ""})
+
+		assert.Equal(t, []newsletterError.FieldError{{Field: "fields.company", Code: newsletterError.FieldRequired}}, fieldErrors)
+	})
+
+	t.Run("should return error of each invalid value", func(t *testing.T) {
+		res, fieldErrors := customfields.ValidateValues(definitions, map[string]interface{}{
+			"plan":     "team",
+			"age":      "30",
+			"birthday": "02/01/1990",
+			"vip":      "yes",
+			"company":  strings.Repeat("a", 256),
+			"team":     "a",
+			"country":  "th",
+		})
+
+		assert.Equal(t, map[string]interface{}{}, res)
+		assert.Equal(t, []newsletterError.FieldError{
+			{Field: "fields.plan", Code: newsletterError.FieldInvalidOption},
+			{Field: "fields.age", Code: newsletterError.FieldInvalidType},
+			{Field: "fields.birthday", Code: newsletterError.FieldInvalidDate},
+			{Field: "fields.vip", Code: newsletterError.FieldInvalidType},
+			{Field: "fields.company", Code: newsletterError.FieldTooLong},
+			{Field: "fields.country", Code: newsletterError.FieldUnknown},
+			{Field: "fields.team", Code: newsletterError.FieldUnknown},
+		}, fieldErrors)
+	})
+
+	t.Run("should return field invalid type when number is not finite", func(t *testing.T) {
+		_, fieldErrors := customfields.ValidateValues(definitions, map[string]interface{}{"age": math.Inf(1)})
+
+		assert.Equal(t, []newsletterError.FieldError{{Field: "fields.age", Code: newsletterError.FieldInvalidType}}, fieldErrors)
+	})
+}
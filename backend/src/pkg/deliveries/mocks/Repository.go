@@ -0,0 +1,134 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// FindByCampaignID provides a mock function with given fields: campaignID
+func (_m *Repository) FindByCampaignID(campaignID int64) ([]entity.Deliveries, error) {
+	ret := _m.Called(campaignID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByCampaignID")
+	}
+
+	var r0 []entity.Deliveries
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Deliveries, error)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Deliveries); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(campaignID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByCampaignIDAndStatuses provides a mock function with given fields: campaignID, statuses
+func (_m *Repository) FindByCampaignIDAndStatuses(campaignID int64, statuses []entity.DeliveryStatus) ([]entity.Deliveries, error) {
+	ret := _m.Called(campaignID, statuses)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByCampaignIDAndStatuses")
+	}
+
+	var r0 []entity.Deliveries
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, []entity.DeliveryStatus) ([]entity.Deliveries, error)); ok {
+		return rf(campaignID, statuses)
+	}
+	if rf, ok := ret.Get(0).(func(int64, []entity.DeliveryStatus) []entity.Deliveries); ok {
+		r0 = rf(campaignID, statuses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, []entity.DeliveryStatus) error); ok {
+		r1 = rf(campaignID, statuses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateStatusByCampaignID provides a mock function with given fields: campaignID, fromStatus, toStatus
+func (_m *Repository) UpdateStatusByCampaignID(campaignID int64, fromStatus entity.DeliveryStatus, toStatus entity.DeliveryStatus) (int64, error) {
+	ret := _m.Called(campaignID, fromStatus, toStatus)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateStatusByCampaignID")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, entity.DeliveryStatus, entity.DeliveryStatus) (int64, error)); ok {
+		return rf(campaignID, fromStatus, toStatus)
+	}
+	if rf, ok := ret.Get(0).(func(int64, entity.DeliveryStatus, entity.DeliveryStatus) int64); ok {
+		r0 = rf(campaignID, fromStatus, toStatus)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, entity.DeliveryStatus, entity.DeliveryStatus) error); ok {
+		r1 = rf(campaignID, fromStatus, toStatus)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Upsert provides a mock function with given fields: delivery
+func (_m *Repository) Upsert(delivery entity.Deliveries) error {
+	ret := _m.Called(delivery)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Upsert")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Deliveries) error); ok {
+		r0 = rf(delivery)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,115 @@
+package deliveries_test
+
+import (
+	"errors"
+	deliveries "newsletter/src/pkg/deliveries"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	sqlMock  sqlmock.Sqlmock
+	repo     *deliveries.SqlRepository
+	repoLogs *loggerMocks.Logger
+)
+
+func beforeEachRepository(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs = &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sqlMock = mockDB
+	repo = deliveries.NewRepository("TB_TRN_Deliveries", db, repoLogs)
+}
+
+func TestRepository_FindByCampaignIDAndStatuses(t *testing.T) {
+	t.Run("should pass campaign id and every status as parameters", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery(`CampaignId = @p1\s+AND Status IN \(@p2, @p3\)`).
+			WithArgs(int64(1), "sent", "dead").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "campaignId", "subscriberId", "status"}).AddRow(1, 1, 3, "sent"))
+
+		res, err := repo.FindByCampaignIDAndStatuses(1, []entity.DeliveryStatus{entity.DeliveryStatusSent, entity.DeliveryStatusDead})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), res[0].SubscriberID)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("CampaignId = @p1").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindByCampaignIDAndStatuses(1, []entity.DeliveryStatus{entity.DeliveryStatusSent})
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepository_Upsert(t *testing.T) {
+	t.Run("should merge delivery by campaign and subscriber", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec(`MERGE TB_TRN_Deliveries WITH \(HOLDLOCK\)[\s\S]*Attempts = target.Attempts \+ @p7`).
+			WithArgs(int64(1), int64(2), "ajistestmail@gmail.com", "dead", "550 mailbox unavailable", nil, 1, 550).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Upsert(entity.Deliveries{
+			CampaignID:   1,
+			SubscriberID: 2,
+			Email:        "ajistestmail@gmail.com",
+			Status:       entity.DeliveryStatusDead,
+			Attempts:     1,
+			LastError:    convert.ValueToStringPointer("550 mailbox unavailable"),
+			ReplyCode:    convert.ValueToIntPointer(550),
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("MERGE").WillReturnError(errors.New("Error"))
+
+		err := repo.Upsert(entity.Deliveries{CampaignID: 1, SubscriberID: 2})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_UpdateStatusByCampaignID(t *testing.T) {
+	t.Run("should return number of deliveries moved to new status", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec(`SET\s+Status = @p3[\s\S]*CampaignId = @p1\s+AND Status = @p2`).
+			WithArgs(int64(1), "dead", "failed").
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		count, err := repo.UpdateStatusByCampaignID(1, entity.DeliveryStatusDead, entity.DeliveryStatusFailed)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("UPDATE").WillReturnError(errors.New("Error"))
+
+		count, err := repo.UpdateStatusByCampaignID(1, entity.DeliveryStatusDead, entity.DeliveryStatusFailed)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
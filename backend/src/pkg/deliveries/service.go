@@ -0,0 +1,155 @@
+package deliveries
+
+import (
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"time"
+)
+
+const maxLastErrorLength = 1000
+
+type UseCase interface {
+	GetByCampaignID(campaignID int64) ([]entity.Deliveries, *newsletterError.ErrorCode)
+	GetDeadLetters(campaignID int64) ([]entity.Deliveries, *newsletterError.ErrorCode)
+	FindHandledSubscriberIDs(campaignID int64) (map[int64]bool, *newsletterError.ErrorCode)
+	RecordSent(campaignID int64, subscriberID int64, email string, attempts int) *newsletterError.ErrorCode
+	RecordFailed(campaignID int64, subscriberID int64, email string, attempts int, reason string) *newsletterError.ErrorCode
+	RecordDeadLetter(campaignID int64, subscriberID int64, email string, attempts int, replyCode int, reason string) *newsletterError.ErrorCode
+	RequeueDeadLetters(campaignID int64) (int64, *newsletterError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo Repository
+	Logs logger.Logger
+}
+
+func NewService(repo Repository, logs logger.Logger) *Service {
+	service := &Service{
+		Repo: repo,
+		Logs: logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetByCampaignID(campaignID int64) ([]entity.Deliveries, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignID(campaignID)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) GetDeadLetters(campaignID int64) ([]entity.Deliveries, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignIDAndStatuses(campaignID, []entity.DeliveryStatus{entity.DeliveryStatusDead})
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+// FindHandledSubscriberIDs returns the subscribers a send should skip: those
+// already delivered and those dead-lettered, which only come back through
+// RequeueDeadLetters.
+func (service *Service) FindHandledSubscriberIDs(campaignID int64) (map[int64]bool, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignIDAndStatuses(campaignID, []entity.DeliveryStatus{
+		entity.DeliveryStatusSent,
+		entity.DeliveryStatusDead,
+	})
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	handled := map[int64]bool{}
+	for _, delivery := range res {
+		handled[delivery.SubscriberID] = true
+	}
+
+	return handled, nil
+}
+
+func (service *Service) RecordSent(campaignID int64, subscriberID int64, email string, attempts int) *newsletterError.ErrorCode {
+	sentDate := time.Now()
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusSent,
+		Attempts:     attempts,
+		SentDate:     &sentDate,
+	})
+}
+
+func (service *Service) RecordFailed(campaignID int64, subscriberID int64, email string, attempts int, reason string) *newsletterError.ErrorCode {
+	reason = truncateReason(reason)
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusFailed,
+		Attempts:     attempts,
+		LastError:    &reason,
+	})
+}
+
+func (service *Service) RecordDeadLetter(campaignID int64, subscriberID int64, email string, attempts int, replyCode int, reason string) *newsletterError.ErrorCode {
+	reason = truncateReason(reason)
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusDead,
+		Attempts:     attempts,
+		LastError:    &reason,
+		ReplyCode:    &replyCode,
+	})
+}
+
+func (service *Service) RequeueDeadLetters(campaignID int64) (int64, *newsletterError.ErrorCode) {
+	count, err := service.Repo.UpdateStatusByCampaignID(campaignID, entity.DeliveryStatusDead, entity.DeliveryStatusFailed)
+
+	if err != nil {
+		return 0, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if count == 0 {
+		return 0, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return count, nil
+}
+
+func (service *Service) upsert(delivery entity.Deliveries) *newsletterError.ErrorCode {
+	err := service.Repo.Upsert(delivery)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func truncateReason(reason string) string {
+	if runes := []rune(reason); len(runes) > maxLastErrorLength {
+		return string(runes[:maxLastErrorLength])
+	}
+	return reason
+}
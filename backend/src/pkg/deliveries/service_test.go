@@ -0,0 +1,320 @@
+package deliveries_test
+
+import (
+	"errors"
+	deliveries "newsletter/src/pkg/deliveries"
+	"newsletter/src/pkg/deliveries/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository *mocks.Repository
+	service    *deliveries.Service
+	logs       *loggerMocks.Logger
+
+	mockRepoFindByCampaignID            *mocker.MockCall
+	mockRepoFindByCampaignIDAndStatuses *mocker.MockCall
+	mockRepoUpsert                      *mocker.MockCall
+	mockRepoUpdateStatusByCampaignID    *mocker.MockCall
+)
+
+func callRepoFindByCampaignID() *mock.Call {
+	return repository.On("FindByCampaignID", mock.Anything)
+}
+
+func callRepoFindByCampaignIDAndStatuses() *mock.Call {
+	return repository.On("FindByCampaignIDAndStatuses", mock.Anything, mock.Anything)
+}
+
+func callRepoUpsert() *mock.Call {
+	return repository.On("Upsert", mock.Anything)
+}
+
+func callRepoUpdateStatusByCampaignID() *mock.Call {
+	return repository.On("UpdateStatusByCampaignID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func beforeEach() {
+	repository = &mocks.Repository{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = deliveries.NewService(repository, logs)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct deliveries service when call new service", func(t *testing.T) {
+		beforeEach()
+
+		expectedService := &deliveries.Service{
+			Repo: repository,
+			Logs: logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, service)
+	})
+}
+
+func TestService_GetByCampaignID(t *testing.T) {
+	beforeEachGetByCampaignID := func() {
+		beforeEach()
+
+		mockRepoFindByCampaignID = mocker.NewMockCall(callRepoFindByCampaignID)
+		mockRepoFindByCampaignID.Return([]entity.Deliveries{}, nil)
+	}
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachGetByCampaignID()
+		mockRepoFindByCampaignID.Return(nil, errors.New("Error"))
+
+		res, err := service.GetByCampaignID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data not found when campaign has no delivery", func(t *testing.T) {
+		beforeEachGetByCampaignID()
+
+		res, err := service.GetByCampaignID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return deliveries when found", func(t *testing.T) {
+		beforeEachGetByCampaignID()
+		mockRepoFindByCampaignID.Return([]entity.Deliveries{{ID: 1}}, nil)
+
+		res, err := service.GetByCampaignID(1)
+
+		repository.AssertCalled(t, "FindByCampaignID", int64(1))
+		assert.Equal(t, []entity.Deliveries{{ID: 1}}, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_GetDeadLetters(t *testing.T) {
+	beforeEachGetDeadLetters := func() {
+		beforeEach()
+
+		mockRepoFindByCampaignIDAndStatuses = mocker.NewMockCall(callRepoFindByCampaignIDAndStatuses)
+		mockRepoFindByCampaignIDAndStatuses.Return([]entity.Deliveries{}, nil)
+	}
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		mockRepoFindByCampaignIDAndStatuses.Return(nil, errors.New("Error"))
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data not found when campaign has no dead letter", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return dead deliveries of campaign", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		mockRepoFindByCampaignIDAndStatuses.Return([]entity.Deliveries{{ID: 1, Status: entity.DeliveryStatusDead}}, nil)
+
+		res, err := service.GetDeadLetters(1)
+
+		repository.AssertCalled(t, "FindByCampaignIDAndStatuses", int64(1), []entity.DeliveryStatus{entity.DeliveryStatusDead})
+		assert.Equal(t, []entity.Deliveries{{ID: 1, Status: entity.DeliveryStatusDead}}, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_FindHandledSubscriberIDs(t *testing.T) {
+	beforeEachFindHandled := func() {
+		beforeEach()
+
+		mockRepoFindByCampaignIDAndStatuses = mocker.NewMockCall(callRepoFindByCampaignIDAndStatuses)
+		mockRepoFindByCampaignIDAndStatuses.Return([]entity.Deliveries{}, nil)
+	}
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachFindHandled()
+		mockRepoFindByCampaignIDAndStatuses.Return(nil, errors.New("Error"))
+
+		res, err := service.FindHandledSubscriberIDs(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return sent and dead subscriber ids of campaign", func(t *testing.T) {
+		beforeEachFindHandled()
+		mockRepoFindByCampaignIDAndStatuses.Return([]entity.Deliveries{{SubscriberID: 3}, {SubscriberID: 5}}, nil)
+
+		res, err := service.FindHandledSubscriberIDs(1)
+
+		repository.AssertCalled(t, "FindByCampaignIDAndStatuses", int64(1), []entity.DeliveryStatus{entity.DeliveryStatusSent, entity.DeliveryStatusDead})
+		assert.Equal(t, map[int64]bool{3: true, 5: true}, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_RecordSent(t *testing.T) {
+	beforeEachRecordSent := func() {
+		beforeEach()
+
+		mockRepoUpsert = mocker.NewMockCall(callRepoUpsert)
+		mockRepoUpsert.Return(nil)
+	}
+
+	t.Run("should upsert sent delivery with sent date and attempts", func(t *testing.T) {
+		beforeEachRecordSent()
+
+		err := service.RecordSent(1, 2, "ajistestmail@gmail.com", 2)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Upsert", mock.MatchedBy(func(delivery entity.Deliveries) bool {
+			return delivery.CampaignID == 1 &&
+				delivery.SubscriberID == 2 &&
+				delivery.Email == "ajistestmail@gmail.com" &&
+				delivery.Status == entity.DeliveryStatusSent &&
+				delivery.Attempts == 2 &&
+				delivery.SentDate != nil &&
+				delivery.LastError == nil &&
+				delivery.ReplyCode == nil
+		}))
+	})
+
+	t.Run("should response internal server error when upsert failed", func(t *testing.T) {
+		beforeEachRecordSent()
+		mockRepoUpsert.Return(errors.New("Error"))
+
+		err := service.RecordSent(1, 2, "ajistestmail@gmail.com", 1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_RecordFailed(t *testing.T) {
+	beforeEachRecordFailed := func() {
+		beforeEach()
+
+		mockRepoUpsert = mocker.NewMockCall(callRepoUpsert)
+		mockRepoUpsert.Return(nil)
+	}
+
+	t.Run("should upsert failed delivery with last error", func(t *testing.T) {
+		beforeEachRecordFailed()
+
+		err := service.RecordFailed(1, 2, "ajistestmail@gmail.com", 3, "421 service not available")
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Upsert", entity.Deliveries{
+			CampaignID:   1,
+			SubscriberID: 2,
+			Email:        "ajistestmail@gmail.com",
+			Status:       entity.DeliveryStatusFailed,
+			Attempts:     3,
+			LastError:    convert.ValueToStringPointer("421 service not available"),
+		})
+	})
+
+	t.Run("should truncate last error to column size", func(t *testing.T) {
+		beforeEachRecordFailed()
+
+		service.RecordFailed(1, 2, "ajistestmail@gmail.com", 1, strings.Repeat("ข", 1200))
+
+		repository.AssertCalled(t, "Upsert", mock.MatchedBy(func(delivery entity.Deliveries) bool {
+			return utf8.RuneCountInString(*delivery.LastError) == 1000
+		}))
+	})
+}
+
+func TestService_RecordDeadLetter(t *testing.T) {
+	beforeEachRecordDeadLetter := func() {
+		beforeEach()
+
+		mockRepoUpsert = mocker.NewMockCall(callRepoUpsert)
+		mockRepoUpsert.Return(nil)
+	}
+
+	t.Run("should upsert dead delivery with reply code and last error", func(t *testing.T) {
+		beforeEachRecordDeadLetter()
+
+		err := service.RecordDeadLetter(1, 2, "ajistestmail@gmail.com", 1, 550, "550 mailbox unavailable")
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "Upsert", entity.Deliveries{
+			CampaignID:   1,
+			SubscriberID: 2,
+			Email:        "ajistestmail@gmail.com",
+			Status:       entity.DeliveryStatusDead,
+			Attempts:     1,
+			LastError:    convert.ValueToStringPointer("550 mailbox unavailable"),
+			ReplyCode:    convert.ValueToIntPointer(550),
+		})
+	})
+
+	t.Run("should response internal server error when upsert failed", func(t *testing.T) {
+		beforeEachRecordDeadLetter()
+		mockRepoUpsert.Return(errors.New("Error"))
+
+		err := service.RecordDeadLetter(1, 2, "ajistestmail@gmail.com", 1, 550, "550 mailbox unavailable")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_RequeueDeadLetters(t *testing.T) {
+	beforeEachRequeue := func() {
+		beforeEach()
+
+		mockRepoUpdateStatusByCampaignID = mocker.NewMockCall(callRepoUpdateStatusByCampaignID)
+		mockRepoUpdateStatusByCampaignID.Return(int64(2), nil)
+	}
+
+	t.Run("should move dead deliveries back to failed", func(t *testing.T) {
+		beforeEachRequeue()
+
+		count, err := service.RequeueDeadLetters(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+		repository.AssertCalled(t, "UpdateStatusByCampaignID", int64(1), entity.DeliveryStatusDead, entity.DeliveryStatusFailed)
+	})
+
+	t.Run("should return data not found when campaign has no dead letter", func(t *testing.T) {
+		beforeEachRequeue()
+		mockRepoUpdateStatusByCampaignID.Return(int64(0), nil)
+
+		count, err := service.RequeueDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Equal(t, int64(0), count)
+	})
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachRequeue()
+		mockRepoUpdateStatusByCampaignID.Return(int64(0), errors.New("Error"))
+
+		_, err := service.RequeueDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
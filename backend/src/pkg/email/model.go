@@ -0,0 +1,29 @@
+package email
+
+import "gopkg.in/gomail.v2"
+
+// SentMailContent is one message to send. Body is the HTML part and Text the
+// plain-text part; when Text is empty it is derived from Body.
+type SentMailContent struct {
+	Sender         string
+	To             string
+	Supject        string
+	Body           string
+	Text           string
+	UnsubscribeURL string
+	Attachments    []Attachment
+}
+
+// Attachment is a file sent with a message. One with a ContentID is embedded
+// as an inline image the HTML part shows with src="cid:<ContentID>"; the
+// others are attached.
+type Attachment struct {
+	FileName    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+type Dialer interface {
+	Dial() (gomail.SendCloser, error)
+}
@@ -0,0 +1,50 @@
+package email_test
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/textproto"
+	"newsletter/src/pkg/email"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry_NewSendError(t *testing.T) {
+	t.Run("should use reply code of smtp error", func(t *testing.T) {
+		sendError := email.NewSendError(&textproto.Error{Code: 552, Msg: "message too large"})
+
+		assert.Equal(t, 552, sendError.Code)
+		assert.True(t, sendError.Permanent)
+	})
+
+	t.Run("should read reply code from wrapped gomail message", func(t *testing.T) {
+		err := fmt.Errorf("gomail: could not send email 1: %v", &textproto.Error{Code: 421, Msg: "4.7.0 try again later"})
+
+		sendError := email.NewSendError(err)
+
+		assert.Equal(t, 421, sendError.Code)
+		assert.False(t, sendError.Permanent)
+	})
+
+	t.Run("should treat network error as transient", func(t *testing.T) {
+		sendError := email.NewSendError(&net.OpError{Op: "dial", Err: errors.New("connection refused")})
+
+		assert.Equal(t, 0, sendError.Code)
+		assert.False(t, sendError.Permanent)
+	})
+
+	t.Run("should not read port number as reply code", func(t *testing.T) {
+		sendError := email.NewSendError(errors.New("dial tcp 127.0.0.1:587: connect: connection refused"))
+
+		assert.Equal(t, 0, sendError.Code)
+		assert.False(t, sendError.Permanent)
+	})
+
+	t.Run("should return same send error when already classified", func(t *testing.T) {
+		sendError := &email.SendError{Code: 550, Permanent: true, Err: errors.New("550")}
+
+		assert.Same(t, sendError, email.NewSendError(sendError))
+	})
+}
@@ -0,0 +1,152 @@
+package email
+
+import (
+	"io"
+	"mime"
+	"newsletter/src/pkg/utils/logger"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+const (
+	ListUnsubscribe         = "List-Unsubscribe"
+	ListUnsubscribePost     = "List-Unsubscribe-Post"
+	ListUnsubscribeOneClick = "List-Unsubscribe=One-Click"
+)
+
+type UseCase interface {
+	Send(content SentMailContent) error
+}
+
+type Service struct {
+	DialerMailServer Dialer
+	Sender           string
+	Retry            RetryConfig
+	Logs             logger.Logger
+}
+
+func NewService(dialerMailServer Dialer, sender string, retry RetryConfig, logs logger.Logger) *Service {
+	return &Service{
+		DialerMailServer: dialerMailServer,
+		Sender:           sender,
+		Retry:            retry,
+		Logs:             logs,
+	}
+}
+
+// Send delivers a single message on its own connection. Transient failures
+// are retried with backoff; the returned error is a *SendError.
+func (service *Service) Send(content SentMailContent) error {
+	message := service.BuildMessage(content)
+
+	var sendCloser gomail.SendCloser
+	defer func() {
+		if sendCloser != nil {
+			sendCloser.Close()
+		}
+	}()
+
+	_, err := service.sendWithRetry(&sendCloser, message)
+	if err != nil {
+		go service.Logs.Error("", "email_Service_Send", content.To, err.Error())
+		return err
+	}
+
+	return nil
+}
+
+// sendWithRetry sends message over sendCloser, dialing when it is nil, and
+// retries transient failures up to Retry.MaxAttempts. After a failure the
+// session state is unknown, so the connection is dropped and the next attempt
+// starts on a fresh one.
+func (service *Service) sendWithRetry(sendCloser *gomail.SendCloser, message *gomail.Message) (int, *SendError) {
+	maxAttempts := service.Retry.attempts()
+
+	for attempt := 1; ; attempt++ {
+		err := service.sendOnce(sendCloser, message)
+		if err == nil {
+			return attempt, nil
+		}
+
+		sendError := NewSendError(err)
+		if sendError.Permanent || attempt >= maxAttempts {
+			return attempt, sendError
+		}
+
+		time.Sleep(service.Retry.backoff(attempt))
+	}
+}
+
+func (service *Service) sendOnce(sendCloser *gomail.SendCloser, message *gomail.Message) error {
+	if *sendCloser == nil {
+		resSendCloser, err := service.DialerMailServer.Dial()
+		if err != nil {
+			return err
+		}
+		*sendCloser = resSendCloser
+	}
+
+	err := gomail.Send(*sendCloser, message)
+	if err != nil {
+		(*sendCloser).Close()
+		*sendCloser = nil
+	}
+	return err
+}
+
+// BuildMessage builds content as a multipart/alternative message with a
+// plain-text and an HTML part, followed by its inline images and attachments.
+func (service *Service) BuildMessage(content SentMailContent) *gomail.Message {
+	sender := service.Sender
+	if content.Sender != "" {
+		sender = content.Sender
+	}
+
+	message := gomail.NewMessage()
+	message.SetHeader("From", sender)
+	message.SetHeader("To", content.To)
+	message.SetHeader("Subject", content.Supject)
+	if content.UnsubscribeURL != "" {
+		message.SetHeader(ListUnsubscribe, "<"+content.UnsubscribeURL+">")
+		message.SetHeader(ListUnsubscribePost, ListUnsubscribeOneClick)
+	}
+	// Clients show the last alternative they support, so HTML goes last.
+	text := content.Text
+	if text == "" {
+		text = HTMLToText(content.Body)
+	}
+	message.SetBody("text/plain", text)
+	message.AddAlternative("text/html", content.Body)
+
+	for _, attachment := range content.Attachments {
+		if attachment.ContentID != "" {
+			message.Embed(attachment.FileName, attachmentSettings(attachment)...)
+			continue
+		}
+		message.Attach(attachment.FileName, attachmentSettings(attachment)...)
+	}
+
+	return message
+}
+
+// attachmentSettings makes gomail write attachment from memory rather than
+// open a file named FileName.
+func attachmentSettings(attachment Attachment) []gomail.FileSetting {
+	header := map[string][]string{}
+	if attachment.ContentType != "" {
+		header["Content-Type"] = []string{mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.FileName})}
+	}
+	if attachment.ContentID != "" {
+		header["Content-ID"] = []string{"<" + attachment.ContentID + ">"}
+	}
+
+	return []gomail.FileSetting{
+		gomail.Rename(attachment.FileName),
+		gomail.SetHeader(header),
+		gomail.SetCopyFunc(func(writer io.Writer) error {
+			_, err := writer.Write(attachment.Data)
+			return err
+		}),
+	}
+}
@@ -0,0 +1,250 @@
+package email_test
+
+import (
+	"bytes"
+	"io"
+	"net/textproto"
+	"newsletter/src/pkg/email"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"gopkg.in/gomail.v2"
+)
+
+var (
+	dialer  *gomail.Dialer
+	service *email.Service
+	logs    *loggerMocks.Logger
+	retry   email.RetryConfig
+)
+
+type fakeSendCloser struct {
+	dialer *fakeDialer
+}
+
+func (sendCloser *fakeSendCloser) Send(from string, to []string, msg io.WriterTo) error {
+	sendCloser.dialer.mutex.Lock()
+	defer sendCloser.dialer.mutex.Unlock()
+
+	sendCloser.dialer.sent = append(sendCloser.dialer.sent, to...)
+	failures := sendCloser.dialer.failTo[to[0]]
+	if len(failures) == 0 {
+		return nil
+	}
+	sendCloser.dialer.failTo[to[0]] = failures[1:]
+	return failures[0]
+}
+
+func (sendCloser *fakeSendCloser) Close() error {
+	sendCloser.dialer.mutex.Lock()
+	defer sendCloser.dialer.mutex.Unlock()
+
+	sendCloser.dialer.closed++
+	return nil
+}
+
+type fakeDialer struct {
+	mutex   sync.Mutex
+	dialErr error
+	failTo  map[string][]error
+	dialed  int
+	closed  int
+	sent    []string
+}
+
+func (dialer *fakeDialer) Dial() (gomail.SendCloser, error) {
+	dialer.mutex.Lock()
+	defer dialer.mutex.Unlock()
+
+	dialer.dialed++
+	if dialer.dialErr != nil {
+		return nil, dialer.dialErr
+	}
+	return &fakeSendCloser{dialer: dialer}, nil
+}
+
+func beforeEach() {
+	logs = &loggerMocks.Logger{}
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	dialer = gomail.NewDialer("127.0.0.1", 1, "", "")
+	retry = email.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	service = email.NewService(dialer, "noreply@newsletter.com", retry, logs)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct email service when call new service", func(t *testing.T) {
+		beforeEach()
+
+		expectedService := &email.Service{
+			DialerMailServer: dialer,
+			Sender:           "noreply@newsletter.com",
+			Retry:            retry,
+			Logs:             logs,
+		}
+
+		assert.Equal(t, expectedService, service)
+	})
+}
+
+func TestService_Send(t *testing.T) {
+	t.Run("should return error when dial mail server failed", func(t *testing.T) {
+		beforeEach()
+
+		err := service.Send(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    "test",
+		})
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should retry transient failure until send success", func(t *testing.T) {
+		beforeEach()
+		fake := &fakeDialer{failTo: map[string][]error{
+			"ajistestmail@gmail.com": {&textproto.Error{Code: 421, Msg: "try again later"}},
+		}}
+		service.DialerMailServer = fake
+
+		err := service.Send(email.SentMailContent{To: "ajistestmail@gmail.com", Supject: "test", Body: "test"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, fake.dialed)
+		assert.Equal(t, 2, fake.closed)
+	})
+
+	t.Run("should not retry permanent failure", func(t *testing.T) {
+		beforeEach()
+		fake := &fakeDialer{failTo: map[string][]error{
+			"ajistestmail@gmail.com": {&textproto.Error{Code: 550, Msg: "mailbox unavailable"}},
+		}}
+		service.DialerMailServer = fake
+
+		err := service.Send(email.SentMailContent{To: "ajistestmail@gmail.com", Supject: "test", Body: "test"})
+
+		var sendError *email.SendError
+		assert.ErrorAs(t, err, &sendError)
+		assert.Equal(t, 550, sendError.Code)
+		assert.True(t, sendError.Permanent)
+		assert.Equal(t, 1, fake.dialed)
+	})
+}
+
+func TestService_BuildMessage(t *testing.T) {
+	t.Run("should set sender recipient and subject when build message", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    "test",
+		})
+
+		assert.Equal(t, []string{"noreply@newsletter.com"}, message.GetHeader("From"))
+		assert.Equal(t, []string{"ajistestmail@gmail.com"}, message.GetHeader("To"))
+		assert.Equal(t, []string{"test"}, message.GetHeader("Subject"))
+	})
+
+	t.Run("should use content sender when content has sender", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			Sender:  "campaign@newsletter.com",
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    "test",
+		})
+
+		assert.Equal(t, []string{"campaign@newsletter.com"}, message.GetHeader("From"))
+	})
+
+	t.Run("should not set list unsubscribe headers when content has no unsubscribe url", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    "test",
+		})
+
+		assert.Empty(t, message.GetHeader(email.ListUnsubscribe))
+		assert.Empty(t, message.GetHeader(email.ListUnsubscribePost))
+	})
+
+	t.Run("should send text and html as alternatives", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    "<p>html body</p>",
+			Text:    "text body",
+		})
+
+		var raw bytes.Buffer
+		message.WriteTo(&raw)
+		assert.Contains(t, raw.String(), "multipart/alternative")
+		assert.Contains(t, raw.String(), "text body")
+		assert.Contains(t, raw.String(), "<p>html body</p>")
+		assert.Less(t, strings.Index(raw.String(), "text/plain"), strings.Index(raw.String(), "text/html"))
+	})
+
+	t.Run("should derive text from html when content has no text", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    `<p>Read <a href="https://example.com/post">our post</a></p>`,
+		})
+
+		var raw bytes.Buffer
+		message.WriteTo(&raw)
+		assert.Contains(t, raw.String(), "Read our post [1]\r\n\r\n[1] https://example.com/post")
+	})
+
+	t.Run("should embed inline images and attach files from memory", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:      "ajistestmail@gmail.com",
+			Supject: "test",
+			Body:    `<img src="cid:logo">`,
+			Attachments: []email.Attachment{
+				{FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("png")},
+				{FileName: "report.pdf", ContentType: "application/pdf", Data: []byte("pdf")},
+			},
+		})
+
+		var raw bytes.Buffer
+		_, err := message.WriteTo(&raw)
+		assert.Nil(t, err)
+		assert.Contains(t, raw.String(), "multipart/mixed")
+		assert.Contains(t, raw.String(), "multipart/related")
+		assert.Contains(t, raw.String(), "Content-ID: <logo>")
+		assert.Contains(t, raw.String(), "Content-Disposition: inline; filename=\"logo.png\"")
+		assert.Contains(t, raw.String(), "Content-Type: application/pdf; name=report.pdf")
+		assert.Contains(t, raw.String(), "Content-Disposition: attachment; filename=\"report.pdf\"")
+		assert.Contains(t, raw.String(), "cGRm")
+	})
+
+	t.Run("should set one click list unsubscribe headers when content has unsubscribe url", func(t *testing.T) {
+		beforeEach()
+
+		message := service.BuildMessage(email.SentMailContent{
+			To:             "ajistestmail@gmail.com",
+			Supject:        "test",
+			Body:           "test",
+			UnsubscribeURL: "http://localhost:8000/subscribers/unsubscribe/token",
+		})
+
+		assert.Equal(t, []string{"<http://localhost:8000/subscribers/unsubscribe/token>"}, message.GetHeader(email.ListUnsubscribe))
+		assert.Equal(t, []string{"List-Unsubscribe=One-Click"}, message.GetHeader(email.ListUnsubscribePost))
+	})
+}
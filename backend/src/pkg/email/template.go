@@ -0,0 +1,370 @@
+package email
+
+import (
+	"bytes"
+	"fmt"
+	htmlTemplate "html/template"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	textTemplate "text/template"
+	"text/template/parse"
+)
+
+// This file is kept identical, apart from the module name, in
+// backend/src/pkg/email and cmstool/src/pkg/utils/email. Change
+// both copies together; a cmstool test fails when they drift apart.
+
+const (
+	HTMLTemplatePattern = "*.html"
+	TextTemplatePattern = "*.txt"
+
+	missingKeyOption = "missingkey=error"
+)
+
+// TemplateData is the set of fields a newsletter template can reference.
+type TemplateData struct {
+	Name           string
+	Email          string
+	UnsubscribeURL string
+	PreferencesURL string
+	Fields         Fields
+}
+
+// Fields holds the custom field values of a subscriber by key. Templates read
+// them with {{.Fields.Get "key"}}, which renders empty for a subscriber
+// without a value instead of failing the send.
+type Fields map[string]interface{}
+
+func (fields Fields) Get(key string) interface{} {
+	if value, ok := fields[key]; ok {
+		return value
+	}
+	return ""
+}
+
+type RenderedContent struct {
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Renderer holds the shared layouts and partials loaded from the template
+// directory. Every campaign template is parsed on top of a clone of them.
+type Renderer struct {
+	HTML *htmlTemplate.Template
+	Text *textTemplate.Template
+}
+
+type Template struct {
+	Subject  *textTemplate.Template
+	HTMLBody *htmlTemplate.Template
+	TextBody *textTemplate.Template
+}
+
+func NewRenderer(templateDir string) (*Renderer, error) {
+	renderer := &Renderer{
+		HTML: htmlTemplate.New("").Option(missingKeyOption),
+		Text: textTemplate.New("").Option(missingKeyOption),
+	}
+	if templateDir == "" {
+		return renderer, nil
+	}
+
+	if _, err := os.Stat(templateDir); os.IsNotExist(err) {
+		return renderer, nil
+	}
+
+	htmlFiles, err := filepath.Glob(filepath.Join(templateDir, HTMLTemplatePattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(htmlFiles) > 0 {
+		if _, err := renderer.HTML.ParseFiles(htmlFiles...); err != nil {
+			return nil, err
+		}
+	}
+
+	textFiles, err := filepath.Glob(filepath.Join(templateDir, TextTemplatePattern))
+	if err != nil {
+		return nil, err
+	}
+	if len(textFiles) > 0 {
+		if _, err := renderer.Text.ParseFiles(textFiles...); err != nil {
+			return nil, err
+		}
+	}
+
+	return renderer, nil
+}
+
+// Parse compiles the subject and bodies against the shared layouts and checks
+// every field they reference, in every branch, against TemplateData. It then
+// executes them once with empty data to catch what only execution finds, such
+// as html escaping errors. Either way a bad template fails here instead of part
+// way through a send.
+func (renderer *Renderer) Parse(subject string, htmlBody string, textBody string) (*Template, error) {
+	subjectTemplate, err := textTemplate.New("subject").Option(missingKeyOption).Parse(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	htmlClone, err := renderer.HTML.Clone()
+	if err != nil {
+		return nil, err
+	}
+	htmlBodyTemplate, err := htmlClone.New("htmlBody").Parse(htmlBody)
+	if err != nil {
+		return nil, err
+	}
+
+	textClone, err := renderer.Text.Clone()
+	if err != nil {
+		return nil, err
+	}
+	textBodyTemplate, err := textClone.New("textBody").Parse(textBody)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &Template{
+		Subject:  subjectTemplate,
+		HTMLBody: htmlBodyTemplate,
+		TextBody: textBodyTemplate,
+	}
+
+	if err := template.checkFields(); err != nil {
+		return nil, err
+	}
+
+	if err := template.execute(io.Discard, io.Discard, io.Discard, TemplateData{}); err != nil {
+		return nil, err
+	}
+
+	return template, nil
+}
+
+func (template *Template) Render(data TemplateData) (*RenderedContent, error) {
+	var subject, htmlBody, textBody bytes.Buffer
+	if err := template.execute(&subject, &htmlBody, &textBody, data); err != nil {
+		return nil, err
+	}
+
+	return &RenderedContent{
+		Subject:  subject.String(),
+		HTMLBody: htmlBody.String(),
+		TextBody: textBody.String(),
+	}, nil
+}
+
+func (template *Template) execute(subject io.Writer, htmlBody io.Writer, textBody io.Writer, data TemplateData) error {
+	if err := template.Subject.Execute(subject, data); err != nil {
+		return err
+	}
+	if err := template.HTMLBody.Execute(htmlBody, data); err != nil {
+		return err
+	}
+	return template.TextBody.Execute(textBody, data)
+}
+
+func (template *Template) checkFields() error {
+	if err := checkFields(template.Subject.Tree, func(name string) *parse.Tree {
+		if found := template.Subject.Lookup(name); found != nil {
+			return found.Tree
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	if err := checkFields(template.HTMLBody.Tree, func(name string) *parse.Tree {
+		if found := template.HTMLBody.Lookup(name); found != nil {
+			return found.Tree
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	return checkFields(template.TextBody.Tree, func(name string) *parse.Tree {
+		if found := template.TextBody.Lookup(name); found != nil {
+			return found.Tree
+		}
+		return nil
+	})
+}
+
+// fieldChecker walks a parse tree, following {{template}} calls, and keeps
+// track of the type dot holds at each node. Executing with empty data never
+// enters an {{if}} or {{range}} branch, so a typo there is only found this way.
+// Where the type is not known, as after a function call or inside an
+// interface{} value, the fields are not checked.
+type fieldChecker struct {
+	lookup  func(name string) *parse.Tree
+	root    reflect.Type
+	checked map[string]bool
+}
+
+func checkFields(tree *parse.Tree, lookup func(name string) *parse.Tree) error {
+	checker := &fieldChecker{lookup: lookup, checked: map[string]bool{}}
+	return checker.tree(tree, reflect.TypeOf(TemplateData{}))
+}
+
+func (checker *fieldChecker) tree(tree *parse.Tree, dot reflect.Type) error {
+	key := fmt.Sprintf("%s/%v", tree.Name, dot)
+	if checker.checked[key] {
+		return nil
+	}
+	checker.checked[key] = true
+
+	root := checker.root
+	checker.root = dot
+	defer func() { checker.root = root }()
+
+	return checker.node(tree, tree.Root, dot)
+}
+
+func (checker *fieldChecker) node(tree *parse.Tree, node parse.Node, dot reflect.Type) error {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return nil
+		}
+		for _, child := range node.Nodes {
+			if err := checker.node(tree, child, dot); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		_, err := checker.pipe(tree, node.Pipe, dot)
+		return err
+	case *parse.IfNode:
+		if _, err := checker.pipe(tree, node.Pipe, dot); err != nil {
+			return err
+		}
+		if err := checker.node(tree, node.List, dot); err != nil {
+			return err
+		}
+		return checker.node(tree, node.ElseList, dot)
+	case *parse.RangeNode:
+		typ, err := checker.pipe(tree, node.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		if err := checker.node(tree, node.List, elem(typ)); err != nil {
+			return err
+		}
+		return checker.node(tree, node.ElseList, dot)
+	case *parse.WithNode:
+		typ, err := checker.pipe(tree, node.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		if err := checker.node(tree, node.List, typ); err != nil {
+			return err
+		}
+		return checker.node(tree, node.ElseList, dot)
+	case *parse.TemplateNode:
+		typ, err := checker.pipe(tree, node.Pipe, dot)
+		if err != nil {
+			return err
+		}
+		called := checker.lookup(node.Name)
+		if called == nil {
+			location, _ := tree.ErrorContext(node)
+			return fmt.Errorf("template: %s: no such template %q", location, node.Name)
+		}
+		return checker.tree(called, typ)
+	}
+	return nil
+}
+
+func (checker *fieldChecker) pipe(tree *parse.Tree, pipe *parse.PipeNode, dot reflect.Type) (reflect.Type, error) {
+	if pipe == nil {
+		return nil, nil
+	}
+
+	var typ reflect.Type
+	for _, command := range pipe.Cmds {
+		typ = nil
+		for i, arg := range command.Args {
+			argType, err := checker.arg(tree, arg, dot)
+			if err != nil {
+				return nil, err
+			}
+			if i == 0 {
+				typ = argType
+			}
+		}
+	}
+	return typ, nil
+}
+
+func (checker *fieldChecker) arg(tree *parse.Tree, arg parse.Node, dot reflect.Type) (reflect.Type, error) {
+	switch arg := arg.(type) {
+	case *parse.DotNode:
+		return dot, nil
+	case *parse.FieldNode:
+		return field(tree, arg, dot, arg.Ident)
+	case *parse.VariableNode:
+		if arg.Ident[0] == "$" {
+			return field(tree, arg, checker.root, arg.Ident[1:])
+		}
+	case *parse.PipeNode:
+		return checker.pipe(tree, arg, dot)
+	case *parse.ChainNode:
+		_, err := checker.arg(tree, arg.Node, dot)
+		return nil, err
+	}
+	return nil, nil
+}
+
+func field(tree *parse.Tree, node parse.Node, typ reflect.Type, names []string) (reflect.Type, error) {
+	for _, name := range names {
+		for typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		if typ == nil || typ.Kind() == reflect.Interface {
+			return nil, nil
+		}
+
+		if method, ok := reflect.PtrTo(typ).MethodByName(name); ok {
+			if method.Type.NumOut() == 0 {
+				return nil, nil
+			}
+			typ = method.Type.Out(0)
+			continue
+		}
+
+		switch typ.Kind() {
+		case reflect.Struct:
+			if structField, ok := typ.FieldByName(name); ok && structField.PkgPath == "" {
+				typ = structField.Type
+				continue
+			}
+		case reflect.Map:
+			typ = typ.Elem()
+			continue
+		}
+
+		location, _ := tree.ErrorContext(node)
+		return nil, fmt.Errorf("template: %s: can't evaluate field %s in type %s", location, name, typ)
+	}
+	return typ, nil
+}
+
+func elem(typ reflect.Type) reflect.Type {
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ == nil {
+		return nil
+	}
+
+	switch typ.Kind() {
+	case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan:
+		return typ.Elem()
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package email_test
+
+import (
+	"newsletter/src/pkg/email"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var templateData = email.TemplateData{
+	Name:           "ajis",
+	Email:          "ajistestmail@gmail.com",
+	UnsubscribeURL: "http://localhost:8000/subscribers/unsubscribe/token",
+}
+
+func writeTemplateFile(t *testing.T, dir string, name string, content string) {
+	err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRenderer_NewRenderer(t *testing.T) {
+	t.Run("should return empty renderer when template dir does not exist", func(t *testing.T) {
+		renderer, err := email.NewRenderer(filepath.Join(t.TempDir(), "missing"))
+
+		assert.Nil(t, err)
+		assert.NotNil(t, renderer)
+	})
+
+	t.Run("should return error when shared template is invalid", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFile(t, dir, "layout.html", `{{define "layout"}}{{end`)
+
+		renderer, err := email.NewRenderer(dir)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, renderer)
+	})
+}
+
+func TestRenderer_Parse(t *testing.T) {
+	t.Run("should return error when subject references unknown field", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("{{.Unknown}}", "test", "")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, template)
+	})
+
+	t.Run("should return error when html body references unknown field", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("test", "<p>{{.Unknown}}</p>", "")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, template)
+	})
+
+	t.Run("should return error when text body references undefined partial", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("test", "test", `{{template "footer" .}}`)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, template)
+	})
+
+	t.Run("should return error when conditional branch references unknown field", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("test", `{{if .Name}}<p>{{.Nmae}}</p>{{end}}`, "")
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "can't evaluate field Nmae")
+		assert.Nil(t, template)
+	})
+
+	t.Run("should return error when else branch of range references unknown field", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("test", "test", `{{range .Fields}}{{.}}{{else}}{{.UnsubscribeUrl}}{{end}}`)
+
+		assert.NotNil(t, err)
+		assert.Contains(t, err.Error(), "can't evaluate field UnsubscribeUrl")
+		assert.Nil(t, template)
+	})
+
+	t.Run("should return error when partial called inside branch references unknown field", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFile(t, dir, "footer.txt", `{{define "footer"}}{{.Unsubscribe}}{{end}}`)
+		renderer, _ := email.NewRenderer(dir)
+
+		template, err := renderer.Parse("test", "test", `{{if .Email}}{{template "footer" .}}{{end}}`)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, template)
+	})
+
+	t.Run("should parse template referencing known fields inside branches", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse(
+			`{{with .Name}}{{.}}{{end}}`,
+			`{{if .Name}}<p>{{.Name}} {{$.Email}}</p>{{else}}<a href="{{.PreferencesURL}}">x</a>{{end}}`,
+			`{{range $key, $value := .Fields}}{{$key}}={{$value}}{{end}}{{if .Fields.Get "city"}}{{.Fields.Get "city"}}{{end}}`,
+		)
+
+		assert.Nil(t, err)
+		assert.NotNil(t, template)
+	})
+
+	t.Run("should return error when template syntax is invalid", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+
+		template, err := renderer.Parse("test", "{{.Name", "")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, template)
+	})
+}
+
+func TestTemplate_Render(t *testing.T) {
+	t.Run("should render subject and bodies with subscriber data", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+		template, _ := renderer.Parse("Hello {{.Name}}", `<a href="{{.UnsubscribeURL}}">{{.Email}}</a>`, "Hi {{.Name}} <{{.Email}}>")
+
+		content, err := template.Render(templateData)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &email.RenderedContent{
+			Subject:  "Hello ajis",
+			HTMLBody: `<a href="http://localhost:8000/subscribers/unsubscribe/token">ajistestmail@gmail.com</a>`,
+			TextBody: "Hi ajis <ajistestmail@gmail.com>",
+		}, content)
+	})
+
+	t.Run("should escape subscriber data in html body only", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+		template, _ := renderer.Parse("{{.Name}}", "<p>{{.Name}}</p>", "{{.Name}}")
+
+		content, err := template.Render(email.TemplateData{Name: "<b>ajis</b>"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "<b>ajis</b>", content.Subject)
+		assert.Equal(t, "<p>&lt;b&gt;ajis&lt;/b&gt;</p>", content.HTMLBody)
+		assert.Equal(t, "<b>ajis</b>", content.TextBody)
+	})
+
+	t.Run("should render custom fields and leave missing ones empty", func(t *testing.T) {
+		renderer, _ := email.NewRenderer("")
+		template, errParse := renderer.Parse(`{{.Fields.Get "plan"}}`, `<p>{{.Fields.Get "country"}}</p>`, `{{if .Fields.Get "vip"}}vip{{end}}`)
+
+		content, err := template.Render(email.TemplateData{Fields: email.Fields{"plan": "pro", "vip": true}})
+
+		assert.Nil(t, errParse)
+		assert.Nil(t, err)
+		assert.Equal(t, "pro", content.Subject)
+		assert.Equal(t, "<p></p>", content.HTMLBody)
+		assert.Equal(t, "vip", content.TextBody)
+	})
+
+	t.Run("should render body inside shared layout and partials", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFile(t, dir, "layout.html", `{{define "layout"}}<main>{{template "content" .}}</main>{{template "footer" .}}{{end}}`)
+		writeTemplateFile(t, dir, "footer.html", `{{define "footer"}}<footer>{{.Email}}</footer>{{end}}`)
+		writeTemplateFile(t, dir, "layout.txt", `{{define "layout"}}{{template "content" .}} -- {{.Email}}{{end}}`)
+		renderer, errRenderer := email.NewRenderer(dir)
+		template, errParse := renderer.Parse(
+			"test",
+			`{{define "content"}}<p>Hi {{.Name}}</p>{{end}}{{template "layout" .}}`,
+			`{{define "content"}}Hi {{.Name}}{{end}}{{template "layout" .}}`,
+		)
+
+		content, err := template.Render(templateData)
+
+		assert.Nil(t, errRenderer)
+		assert.Nil(t, errParse)
+		assert.Nil(t, err)
+		assert.Equal(t, "<main><p>Hi ajis</p></main><footer>ajistestmail@gmail.com</footer>", content.HTMLBody)
+		assert.Equal(t, "Hi ajis -- ajistestmail@gmail.com", content.TextBody)
+	})
+
+	t.Run("should keep layouts independent between campaigns", func(t *testing.T) {
+		dir := t.TempDir()
+		writeTemplateFile(t, dir, "layout.html", `{{define "layout"}}[{{template "content" .}}]{{end}}`)
+		renderer, _ := email.NewRenderer(dir)
+		first, _ := renderer.Parse("test", `{{define "content"}}first{{end}}{{template "layout" .}}`, "")
+		second, _ := renderer.Parse("test", `{{define "content"}}second{{end}}{{template "layout" .}}`, "")
+
+		firstContent, _ := first.Render(templateData)
+		secondContent, _ := second.Render(templateData)
+
+		assert.Equal(t, "[first]", firstContent.HTMLBody)
+		assert.Equal(t, "[second]", secondContent.HTMLBody)
+	})
+}
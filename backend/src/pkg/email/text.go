@@ -0,0 +1,295 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+// This file is kept identical in backend/src/pkg/email and
+// cmstool/src/pkg/utils/email. The backend and cmstool are separate modules
+// that share no code, so change both copies together; a cmstool test fails
+// when they drift apart.
+
+// maxBlankLines is how many empty lines may separate two blocks of text.
+const maxBlankLines = 1
+
+// blockTags start and end a line. The ones mapped to true are set off by a
+// blank line as well.
+var blockTags = map[string]bool{
+	"p": true, "h1": true, "h2": true, "h3": true, "h4": true, "h5": true, "h6": true,
+	"table": true, "blockquote": true,
+	"div": false, "section": false, "article": false, "header": false, "footer": false,
+	"tr": false, "li": false, "dl": false, "dt": false, "dd": false, "center": false,
+}
+
+// skippedTags hold content that is never shown as text.
+var skippedTags = map[string]bool{
+	"head": true, "title": true, "script": true, "style": true, "noscript": true,
+}
+
+// HTMLToText derives a readable plain-text version of an HTML body for the
+// text part of a message. Block elements start new lines, list items are
+// marked, images are replaced by their alt text and every link is numbered,
+// with its URL listed as a footnote at the end. A link whose text is already
+// its URL is left as it is.
+func HTMLToText(body string) string {
+	converter := &textConverter{footnotes: map[string]int{}}
+
+	tokenizer := html.NewTokenizer(strings.NewReader(body))
+	for {
+		tokenType := tokenizer.Next()
+		switch tokenType {
+		case html.ErrorToken:
+			return converter.String()
+		case html.TextToken:
+			converter.text(string(tokenizer.Text()))
+		case html.StartTagToken, html.SelfClosingTagToken:
+			converter.start(tokenizer.Token(), tokenType == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			converter.end(tokenizer.Token())
+		}
+	}
+}
+
+type textLink struct {
+	href string
+	text strings.Builder
+}
+
+type textList struct {
+	ordered bool
+	items   int
+}
+
+type textConverter struct {
+	out strings.Builder
+
+	// newlines and space hold the separator owed before the next text, so
+	// nothing is written at the very start or end.
+	newlines int
+	space    bool
+	prefix   string
+
+	skip  int
+	pre   int
+	links []*textLink
+	lists []*textList
+
+	urls      []string
+	footnotes map[string]int
+}
+
+func (converter *textConverter) String() string {
+	text := converter.out.String()
+	if len(converter.urls) == 0 {
+		return text
+	}
+
+	lines := []string{}
+	for i, url := range converter.urls {
+		lines = append(lines, fmt.Sprintf("[%d] %s", i+1, url))
+	}
+	if text == "" {
+		return strings.Join(lines, "\n")
+	}
+	return text + "\n\n" + strings.Join(lines, "\n")
+}
+
+func (converter *textConverter) start(token html.Token, selfClosing bool) {
+	if skippedTags[token.Data] {
+		if !selfClosing {
+			converter.skip++
+		}
+		return
+	}
+	if converter.skip > 0 {
+		return
+	}
+
+	switch token.Data {
+	case "br":
+		converter.lineBreak()
+	case "hr":
+		converter.block(true)
+		converter.write("----")
+		converter.block(true)
+	case "pre":
+		converter.block(true)
+		converter.pre++
+	case "ul", "ol":
+		converter.block(len(converter.lists) == 0)
+		converter.lists = append(converter.lists, &textList{ordered: token.Data == "ol"})
+	case "li":
+		converter.block(false)
+		converter.prefix = converter.itemPrefix()
+	case "td", "th":
+		converter.space = true
+	case "img":
+		if alt := attribute(token, "alt"); alt != "" {
+			converter.text(alt)
+		}
+	case "a":
+		if !selfClosing {
+			converter.links = append(converter.links, &textLink{href: strings.TrimSpace(attribute(token, "href"))})
+		}
+	default:
+		if blank, ok := blockTags[token.Data]; ok {
+			converter.block(blank)
+		}
+	}
+}
+
+func (converter *textConverter) end(token html.Token) {
+	if skippedTags[token.Data] {
+		if converter.skip > 0 {
+			converter.skip--
+		}
+		return
+	}
+	if converter.skip > 0 {
+		return
+	}
+
+	switch token.Data {
+	case "pre":
+		if converter.pre > 0 {
+			converter.pre--
+		}
+		converter.block(true)
+	case "ul", "ol":
+		if len(converter.lists) > 0 {
+			converter.lists = converter.lists[:len(converter.lists)-1]
+		}
+		converter.block(len(converter.lists) == 0)
+	case "a":
+		if len(converter.links) == 0 {
+			return
+		}
+		link := converter.links[len(converter.links)-1]
+		converter.links = converter.links[:len(converter.links)-1]
+		converter.footnote(link)
+	default:
+		if blank, ok := blockTags[token.Data]; ok {
+			converter.block(blank)
+		}
+	}
+}
+
+// text writes a run of document text. Outside pre, runs of whitespace count
+// as a single space and are dropped at the start of a line.
+func (converter *textConverter) text(value string) {
+	if converter.skip > 0 || value == "" {
+		return
+	}
+
+	if converter.pre > 0 {
+		converter.write(value)
+		return
+	}
+
+	words := strings.Fields(value)
+	if len(words) == 0 {
+		converter.space = true
+		return
+	}
+	if first, _ := utf8.DecodeRuneInString(value); unicode.IsSpace(first) {
+		converter.space = true
+	}
+	converter.write(strings.Join(words, " "))
+	if last, _ := utf8.DecodeLastRuneInString(value); unicode.IsSpace(last) {
+		converter.space = true
+	}
+}
+
+// write flushes the separator owed and writes value.
+func (converter *textConverter) write(value string) {
+	if converter.out.Len() > 0 {
+		if converter.newlines > 0 {
+			converter.out.WriteString(strings.Repeat("\n", converter.newlines))
+		} else if converter.space {
+			converter.out.WriteString(" ")
+		}
+	}
+	if converter.out.Len() == 0 || converter.newlines > 0 {
+		converter.out.WriteString(converter.prefix)
+		converter.prefix = ""
+	}
+	converter.newlines = 0
+	converter.space = false
+
+	converter.out.WriteString(value)
+	for _, link := range converter.links {
+		link.text.WriteString(value)
+	}
+}
+
+// block ends the current line, leaving a blank line after it when blank.
+func (converter *textConverter) block(blank bool) {
+	newlines := 1
+	if blank {
+		newlines = 1 + maxBlankLines
+	}
+	if converter.newlines < newlines {
+		converter.newlines = newlines
+	}
+	converter.space = false
+}
+
+func (converter *textConverter) lineBreak() {
+	if converter.newlines <= maxBlankLines {
+		converter.newlines++
+	}
+	converter.space = false
+}
+
+func (converter *textConverter) itemPrefix() string {
+	if len(converter.lists) == 0 {
+		return "- "
+	}
+
+	list := converter.lists[len(converter.lists)-1]
+	indent := strings.Repeat("  ", len(converter.lists)-1)
+	if !list.ordered {
+		return indent + "- "
+	}
+	list.items++
+	return fmt.Sprintf("%s%d. ", indent, list.items)
+}
+
+// footnote numbers link after its text. A URL linked twice keeps its first
+// number.
+func (converter *textConverter) footnote(link *textLink) {
+	href := link.href
+	lowerHref := strings.ToLower(href)
+	if href == "" || strings.HasPrefix(href, "#") || strings.HasPrefix(lowerHref, "javascript:") {
+		return
+	}
+
+	text := strings.TrimSpace(link.text.String())
+	if text == href || text == strings.TrimPrefix(href, "mailto:") {
+		return
+	}
+
+	number, ok := converter.footnotes[href]
+	if !ok {
+		converter.urls = append(converter.urls, href)
+		number = len(converter.urls)
+		converter.footnotes[href] = number
+	}
+
+	converter.space = true
+	converter.write(fmt.Sprintf("[%d]", number))
+}
+
+func attribute(token html.Token, name string) string {
+	for _, attr := range token.Attr {
+		if attr.Key == name {
+			return attr.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,68 @@
+package email_test
+
+import (
+	"newsletter/src/pkg/email"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText_HTMLToText(t *testing.T) {
+	t.Run("should return text as it is when body has no html", func(t *testing.T) {
+		assert.Equal(t, "plain text only", email.HTMLToText("plain text only"))
+	})
+
+	t.Run("should collapse whitespace and decode entities", func(t *testing.T) {
+		text := email.HTMLToText("<p>  Hello \n\t <b>ajis</b>,  Tom &amp; Jerry&nbsp;!</p>")
+
+		assert.Equal(t, "Hello ajis, Tom & Jerry !", text)
+	})
+
+	t.Run("should put blocks on their own lines", func(t *testing.T) {
+		text := email.HTMLToText("<h1>Title</h1><p>First<br>line</p><div>Second</div><div>Third</div>")
+
+		assert.Equal(t, "Title\n\nFirst\nline\n\nSecond\nThird", text)
+	})
+
+	t.Run("should drop head script and style", func(t *testing.T) {
+		text := email.HTMLToText("<html><head><title>T</title><style>p{color:red}</style></head><body><script>alert(1)</script><p>Body</p></body></html>")
+
+		assert.Equal(t, "Body", text)
+	})
+
+	t.Run("should mark list items and number ordered ones", func(t *testing.T) {
+		text := email.HTMLToText("<ul><li>One</li><li>Two</li></ul><ol><li>First<ul><li>inner</li></ul></li><li>Second</li></ol>")
+
+		assert.Equal(t, "- One\n- Two\n\n1. First\n  - inner\n2. Second", text)
+	})
+
+	t.Run("should list links as footnotes numbered once per url", func(t *testing.T) {
+		text := email.HTMLToText(`<p>Read <a href="https://example.com/post">our post</a> and <a href="https://example.com/about">about us</a>.</p><p><a href="https://example.com/post">Again</a></p>`)
+
+		assert.Equal(t, "Read our post [1] and about us [2].\n\nAgain [1]\n\n[1] https://example.com/post\n[2] https://example.com/about", text)
+	})
+
+	t.Run("should not footnote link whose text is its url", func(t *testing.T) {
+		text := email.HTMLToText(`<p><a href="https://example.com">https://example.com</a> <a href="mailto:hi@example.com">hi@example.com</a></p>`)
+
+		assert.Equal(t, "https://example.com hi@example.com", text)
+	})
+
+	t.Run("should not footnote anchors and scripts", func(t *testing.T) {
+		text := email.HTMLToText(`<a href="#top">Top</a> <a href="javascript:void(0)">Click</a>`)
+
+		assert.Equal(t, "Top Click", text)
+	})
+
+	t.Run("should use alt text of images", func(t *testing.T) {
+		text := email.HTMLToText(`<a href="https://example.com"><img src="logo.png" alt="Newsletter"></a><img src="spacer.gif">`)
+
+		assert.Equal(t, "Newsletter [1]\n\n[1] https://example.com", text)
+	})
+
+	t.Run("should keep whitespace inside pre", func(t *testing.T) {
+		text := email.HTMLToText("<p>Code:</p><pre>a\n  b</pre><p>End</p>")
+
+		assert.Equal(t, "Code:\n\na\n  b\n\nEnd", text)
+	})
+}
@@ -0,0 +1,19 @@
+package entity
+
+import "time"
+
+// APIKeys identify the backends calling the API on their own behalf. Only the
+// hash of a key is stored; Prefix is kept so staff can tell keys apart.
+// Scopes is a space separated list of permissions.
+type APIKeys struct {
+	ID           int64      `json:"id" sql:"id"`
+	Name         string     `json:"name" sql:"name"`
+	Prefix       string     `json:"prefix" sql:"prefix"`
+	KeyHash      string     `json:"-" sql:"keyHash"`
+	Scopes       string     `json:"scopes" sql:"scopes"`
+	UsageCount   int64      `json:"usageCount" sql:"usageCount"`
+	LastUsedDate *time.Time `json:"lastUsedDate" sql:"lastUsedDate"`
+	CreatedBy    string     `json:"createdBy" sql:"createdBy"`
+	CreatedDate  *time.Time `json:"createdDate" sql:"createdDate"`
+	RevokedDate  *time.Time `json:"revokedDate" sql:"revokedDate"`
+}
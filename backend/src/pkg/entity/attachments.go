@@ -0,0 +1,18 @@
+package entity
+
+import "time"
+
+// Attachments are files sent with a campaign. The content is kept in the blob
+// store under StorageKey. An attachment with a ContentID is an inline image
+// that the HTML body shows with src="cid:<ContentID>".
+type Attachments struct {
+	ID          int64      `json:"id" sql:"id"`
+	CampaignID  int64      `json:"campaignId" sql:"campaignId"`
+	FileName    string     `json:"fileName" sql:"fileName"`
+	ContentType string     `json:"contentType" sql:"contentType"`
+	Size        int64      `json:"size" sql:"size"`
+	StorageKey  string     `json:"-" sql:"storageKey"`
+	ContentID   *string    `json:"contentId" sql:"contentId"`
+	CreatedDate *time.Time `json:"createdDate" sql:"createdDate"`
+	DelFlag     *bool      `json:"delFlag" sql:"delFlag"`
+}
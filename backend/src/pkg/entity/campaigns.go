@@ -0,0 +1,43 @@
+package entity
+
+import "time"
+
+type CampaignStatus string
+
+const (
+	CampaignStatusDraft     CampaignStatus = "draft"
+	CampaignStatusScheduled CampaignStatus = "scheduled"
+	CampaignStatusQueued    CampaignStatus = "queued"
+	CampaignStatusSending   CampaignStatus = "sending"
+	CampaignStatusSent      CampaignStatus = "sent"
+	CampaignStatusFailed    CampaignStatus = "failed"
+)
+
+// CampaignSendableStatuses are the statuses a campaign can be sent from. The
+// backend queues it for cmstool only from one of these, and cmstool claims it
+// only from one of these or queued, so a campaign is never sent twice.
+var CampaignSendableStatuses = []CampaignStatus{CampaignStatusDraft, CampaignStatusScheduled}
+
+// CampaignResumableStatuses are the statuses a finished campaign can be
+// resumed or re-driven from. A campaign ends failed when any of its emails
+// did.
+var CampaignResumableStatuses = []CampaignStatus{CampaignStatusSent, CampaignStatusFailed}
+
+// Campaigns is a newsletter written here and sent by cmstool. ClaimedBy names
+// the cmstool instance sending it.
+type Campaigns struct {
+	ID          int64          `json:"id" sql:"id"`
+	Subject     string         `json:"subject" sql:"subject"`
+	HTMLBody    string         `json:"htmlBody" sql:"htmlBody"`
+	TextBody    string         `json:"textBody" sql:"textBody"`
+	Sender      string         `json:"sender" sql:"sender"`
+	Status      CampaignStatus `json:"status" sql:"status"`
+	ListID      *int64         `json:"listId" sql:"listId"`
+	SegmentID   *int64         `json:"segmentId" sql:"segmentId"`
+	CreatedDate *time.Time     `json:"createdDate" sql:"createdDate"`
+	UpdatedDate *time.Time     `json:"updatedDate" sql:"updatedDate"`
+	SentDate    *time.Time     `json:"sentDate" sql:"sentDate"`
+	ClaimedBy   *string        `json:"claimedBy" sql:"claimedBy"`
+	ClaimedDate *time.Time     `json:"claimedDate" sql:"claimedDate"`
+	DelFlag     *bool          `json:"delFlag" sql:"delFlag"`
+}
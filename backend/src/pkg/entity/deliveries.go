@@ -0,0 +1,25 @@
+package entity
+
+import "time"
+
+type DeliveryStatus string
+
+const (
+	DeliveryStatusSent   DeliveryStatus = "sent"
+	DeliveryStatusFailed DeliveryStatus = "failed"
+	DeliveryStatusDead   DeliveryStatus = "dead"
+)
+
+type Deliveries struct {
+	ID           int64          `json:"id" sql:"id"`
+	CampaignID   int64          `json:"campaignId" sql:"campaignId"`
+	SubscriberID int64          `json:"subscriberId" sql:"subscriberId"`
+	Email        string         `json:"email" sql:"email"`
+	Status       DeliveryStatus `json:"status" sql:"status"`
+	Attempts     int            `json:"attempts" sql:"attempts"`
+	LastError    *string        `json:"lastError" sql:"lastError"`
+	ReplyCode    *int           `json:"replyCode" sql:"replyCode"`
+	SentDate     *time.Time     `json:"sentDate" sql:"sentDate"`
+	CreatedDate  *time.Time     `json:"createdDate" sql:"createdDate"`
+	UpdatedDate  *time.Time     `json:"updatedDate" sql:"updatedDate"`
+}
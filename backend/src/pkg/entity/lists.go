@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+type Lists struct {
+	ID          int64      `json:"id" sql:"id"`
+	Name        string     `json:"name" sql:"name"`
+	Description string     `json:"description" sql:"description"`
+	CreatedDate *time.Time `json:"createdDate" sql:"createdDate"`
+	UpdatedDate *time.Time `json:"updatedDate" sql:"updatedDate"`
+	DelFlag     *bool      `json:"delFlag" sql:"delFlag"`
+}
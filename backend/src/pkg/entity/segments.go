@@ -0,0 +1,55 @@
+package entity
+
+import "time"
+
+type SegmentOp string
+
+const (
+	SegmentOpEq            SegmentOp = "eq"
+	SegmentOpNeq           SegmentOp = "neq"
+	SegmentOpGt            SegmentOp = "gt"
+	SegmentOpGte           SegmentOp = "gte"
+	SegmentOpLt            SegmentOp = "lt"
+	SegmentOpLte           SegmentOp = "lte"
+	SegmentOpIn            SegmentOp = "in"
+	SegmentOpContains      SegmentOp = "contains"
+	SegmentOpStartsWith    SegmentOp = "startsWith"
+	SegmentOpIsSet         SegmentOp = "isSet"
+	SegmentOpNotSet        SegmentOp = "notSet"
+	SegmentOpWithinDays    SegmentOp = "withinDays"
+	SegmentOpOlderThanDays SegmentOp = "olderThanDays"
+)
+
+type EngagementEvent string
+
+const (
+	EngagementReceived EngagementEvent = "received"
+)
+
+// SegmentRule is one node of a segment rule tree. A node sets exactly one of
+// And, Or or Not to group other rules, Field to compare a subscriber field,
+// or Engagement to match on deliveries.
+type SegmentRule struct {
+	And []SegmentRule `json:"and,omitempty"`
+	Or  []SegmentRule `json:"or,omitempty"`
+	Not *SegmentRule  `json:"not,omitempty"`
+
+	Field string      `json:"field,omitempty"`
+	Op    SegmentOp   `json:"op,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+
+	Engagement EngagementEvent `json:"engagement,omitempty"`
+	CampaignID *int64          `json:"campaignId,omitempty"`
+	WithinDays *int            `json:"withinDays,omitempty"`
+}
+
+type Segments struct {
+	ID          int64       `json:"id" sql:"id"`
+	Name        string      `json:"name" sql:"name"`
+	Description string      `json:"description" sql:"description"`
+	Rules       SegmentRule `json:"rules" sql:"-"`
+	RulesJSON   string      `json:"-" sql:"rules"`
+	CreatedDate *time.Time  `json:"createdDate" sql:"createdDate"`
+	UpdatedDate *time.Time  `json:"updatedDate" sql:"updatedDate"`
+	DelFlag     *bool       `json:"delFlag" sql:"delFlag"`
+}
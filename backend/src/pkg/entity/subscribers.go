@@ -3,11 +3,22 @@ package entity
 import "time"
 
 type Subscribers struct {
-	ID               int64      `json:"id" sql:"id"`
-	Email            string     `json:"email" sql:"email"`
-	Name             string     `json:"name" sql:"name"`
-	IsSubscribed     bool       `json:"isSubscribed" sql:"isSubscribed"`
-	SubscribedDate   *time.Time `json:"subscribedDate" sql:"subscribedDate"`
-	UnsubscribedDate *time.Time `json:"unsubscribedDate" sql:"unsubscribedDate"`
-	DelFlag          *bool      `json:"delFlag" sql:"delFlag"`
+	ID                 int64      `json:"id" sql:"id"`
+	Email              string     `json:"email" sql:"email"`
+	Name               string     `json:"name" sql:"name"`
+	IsSubscribed       bool       `json:"isSubscribed" sql:"isSubscribed"`
+	SubscribedDate     *time.Time `json:"subscribedDate" sql:"subscribedDate"`
+	UnsubscribedDate   *time.Time `json:"unsubscribedDate" sql:"unsubscribedDate"`
+	DelFlag            *bool      `json:"delFlag" sql:"delFlag"`
+	IsPending          bool       `json:"isPending" sql:"isPending"`
+	ConfirmToken       *string    `json:"-" sql:"confirmToken"`
+	ConfirmExpiredDate *time.Time `json:"-" sql:"confirmExpiredDate"`
+	ConfirmedDate      *time.Time `json:"confirmedDate" sql:"confirmedDate"`
+	SourceAPIKeyID     *int64     `json:"sourceApiKeyId" sql:"sourceApiKeyId"`
+	Language           *string    `json:"language" sql:"language"`
+	Frequency          string     `json:"frequency" sql:"frequency"`
+	TimeZone           *string    `json:"timeZone" sql:"timeZone"`
+
+	// Fields holds custom field values by key. They live in their own table.
+	Fields map[string]interface{} `json:"fields,omitempty" sql:"-"`
 }
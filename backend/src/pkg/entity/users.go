@@ -0,0 +1,22 @@
+package entity
+
+import "time"
+
+type Role string
+
+const (
+	RoleSupport Role = "support"
+	RoleEditor  Role = "editor"
+	RoleAdmin   Role = "admin"
+)
+
+// Users are the staff allowed into the admin endpoints. Username matches the
+// subject of their bearer token.
+type Users struct {
+	ID          int64      `json:"id" sql:"id"`
+	Username    string     `json:"username" sql:"username"`
+	Role        Role       `json:"role" sql:"role"`
+	IsActive    bool       `json:"isActive" sql:"isActive"`
+	CreatedDate *time.Time `json:"createdDate" sql:"createdDate"`
+	UpdatedDate *time.Time `json:"updatedDate" sql:"updatedDate"`
+}
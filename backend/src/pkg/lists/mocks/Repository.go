@@ -0,0 +1,200 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) error {
+	ret := _m.Called(id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.Lists, error) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Lists
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Lists, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Lists); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Lists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscribers provides a mock function with given fields: listID
+func (_m *Repository) FindSubscribers(listID int64) ([]entity.Subscribers, error) {
+	ret := _m.Called(listID)
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, error)); ok {
+		return rf(listID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(listID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(listID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllLists provides a mock function with given fields:
+func (_m *Repository) GetAllLists() ([]entity.Lists, error) {
+	ret := _m.Called()
+
+	var r0 []entity.Lists
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.Lists, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Lists); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Lists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: list
+func (_m *Repository) Insert(list entity.Lists) (int64, error) {
+	ret := _m.Called(list)
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.Lists) (int64, error)); ok {
+		return rf(list)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Lists) int64); ok {
+		r0 = rf(list)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Lists) error); ok {
+		r1 = rf(list)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SubscribeMember provides a mock function with given fields: listID, subscriberID
+func (_m *Repository) SubscribeMember(listID int64, subscriberID int64) error {
+	ret := _m.Called(listID, subscriberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(listID, subscriberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnsubscribeAllMembers provides a mock function with given fields: subscriberID
+func (_m *Repository) UnsubscribeAllMembers(subscriberID int64) error {
+	ret := _m.Called(subscriberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(subscriberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UnsubscribeMember provides a mock function with given fields: listID, subscriberID
+func (_m *Repository) UnsubscribeMember(listID int64, subscriberID int64) error {
+	ret := _m.Called(listID, subscriberID)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, int64) error); ok {
+		r0 = rf(listID, subscriberID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateByID provides a mock function with given fields: list
+func (_m *Repository) UpdateByID(list entity.Lists) error {
+	ret := _m.Called(list)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Lists) error); ok {
+		r0 = rf(list)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
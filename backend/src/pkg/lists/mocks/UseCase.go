@@ -0,0 +1,237 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Confirm provides a mock function with given fields: confirmToken
+func (_m *UseCase) Confirm(confirmToken string) *error.ErrorCode {
+	ret := _m.Called(confirmToken)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) *error.ErrorCode); ok {
+		r0 = rf(confirmToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: list
+func (_m *UseCase) Create(list entity.Lists) (*entity.Lists, *error.ErrorCode) {
+	ret := _m.Called(list)
+
+	var r0 *entity.Lists
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Lists) (*entity.Lists, *error.ErrorCode)); ok {
+		return rf(list)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Lists) *entity.Lists); ok {
+		r0 = rf(list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Lists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Lists) *error.ErrorCode); ok {
+		r1 = rf(list)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *UseCase) Delete(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.Lists, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 *entity.Lists
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.Lists, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.Lists); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Lists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllLists provides a mock function with given fields:
+func (_m *UseCase) GetAllLists() ([]entity.Lists, *error.ErrorCode) {
+	ret := _m.Called()
+
+	var r0 []entity.Lists
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() ([]entity.Lists, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Lists); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Lists)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSubscribers provides a mock function with given fields: listID
+func (_m *UseCase) GetSubscribers(listID int64) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(listID)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(listID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(listID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(listID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: listID, subscriber
+func (_m *UseCase) Subscribe(listID int64, subscriber entity.Subscribers) *error.ErrorCode {
+	ret := _m.Called(listID, subscriber)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, entity.Subscribers) *error.ErrorCode); ok {
+		r0 = rf(listID, subscriber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// UnsubscribeAllByToken provides a mock function with given fields: unsubscribeToken
+func (_m *UseCase) UnsubscribeAllByToken(unsubscribeToken string) *error.ErrorCode {
+	ret := _m.Called(unsubscribeToken)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) *error.ErrorCode); ok {
+		r0 = rf(unsubscribeToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// UnsubscribeByToken provides a mock function with given fields: listID, unsubscribeToken
+func (_m *UseCase) UnsubscribeByToken(listID int64, unsubscribeToken string) *error.ErrorCode {
+	ret := _m.Called(listID, unsubscribeToken)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, string) *error.ErrorCode); ok {
+		r0 = rf(listID, unsubscribeToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: list
+func (_m *UseCase) Update(list entity.Lists) *error.ErrorCode {
+	ret := _m.Called(list)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Lists) *error.ErrorCode); ok {
+		r0 = rf(list)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
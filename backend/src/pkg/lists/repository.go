@@ -0,0 +1,362 @@
+package lists
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	GetAllLists() ([]entity.Lists, error)
+	FindByID(id int64) ([]entity.Lists, error)
+	Insert(list entity.Lists) (int64, error)
+	UpdateByID(list entity.Lists) error
+	DeleteByID(id int64) error
+	SubscribeMember(listID int64, subscriberID int64) error
+	UnsubscribeMember(listID int64, subscriberID int64) error
+	UnsubscribeAllMembers(subscriberID int64) error
+	FindSubscribers(listID int64) ([]entity.Subscribers, error)
+}
+
+// SqlRepository keeps lists in Collection and who is on them in
+// MembersCollection, joined to SubscribersCollection when reading members.
+type SqlRepository struct {
+	Collection            string
+	MembersCollection     string
+	SubscribersCollection string
+	Session               *sql.DB
+	Logs                  logger.Logger
+}
+
+func NewRepository(collection string, membersCollection string, subscribersCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection:            collection,
+		MembersCollection:     membersCollection,
+		SubscribersCollection: subscribersCollection,
+		Session:               session,
+		Logs:                  logs,
+	}
+}
+
+func (repo *SqlRepository) GetAllLists() ([]entity.Lists, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	ORDER BY Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Lists{}, []string{}),
+		repo.Collection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_GetAllLists", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Lists{}
+	for rows.Next() {
+		var entity entity.Lists
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Lists, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND Id = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Lists{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_FindByID", id, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Lists{}
+	for rows.Next() {
+		var entity entity.Lists
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Insert(list entity.Lists) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "CreatedDate", "UpdatedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(list, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.Lists{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_Insert", list,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (repo *SqlRepository) UpdateByID(list entity.Lists) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(list, []string{"Name", "Description"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		%[2]s,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{list.ID}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_UpdateByID", list,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteByID hides a list. Memberships are kept so past campaigns still
+// point at a real list.
+func (repo *SqlRepository) DeleteByID(id int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Delflag = 1,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// SubscribeMember adds a subscriber to a list, or subscribes them again if
+// they left it. Calling it for a current member changes nothing.
+func (repo *SqlRepository) SubscribeMember(listID int64, subscriberID int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	MERGE %[1]s WITH (HOLDLOCK) AS target
+	USING (SELECT %[2]s AS ListId, %[3]s AS SubscriberId) AS source
+	ON target.ListId = source.ListId
+	AND target.SubscriberId = source.SubscriberId
+	WHEN MATCHED AND target.IsSubscribed = 0 THEN
+		UPDATE SET
+			IsSubscribed = 1,
+			SubscribedDate = GETDATE(),
+			UnsubscribedDate = Null
+	WHEN NOT MATCHED THEN
+		INSERT ([ListId], [SubscriberId], [IsSubscribed], [SubscribedDate])
+		VALUES (source.ListId, source.SubscriberId, 1, GETDATE());
+	`,
+		repo.MembersCollection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+	)
+
+	_, err := session.ExecContext(ctx, sql, listID, subscriberID)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_SubscribeMember", []int64{listID, subscriberID},
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) UnsubscribeMember(listID int64, subscriberID int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		IsSubscribed = 0,
+		UnsubscribedDate = GETDATE()
+	WHERE ListId = %[2]s
+	AND SubscriberId = %[3]s
+	AND IsSubscribed = 1
+	`,
+		repo.MembersCollection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+	)
+
+	_, err := session.ExecContext(ctx, sql, listID, subscriberID)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_UnsubscribeMember", []int64{listID, subscriberID},
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) UnsubscribeAllMembers(subscriberID int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		IsSubscribed = 0,
+		UnsubscribedDate = GETDATE()
+	WHERE SubscriberId = %[2]s
+	AND IsSubscribed = 1
+	`,
+		repo.MembersCollection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, subscriberID)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_UnsubscribeAllMembers", subscriberID,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindSubscribers returns the confirmed subscribers currently on a list,
+// ordered by id.
+func (repo *SqlRepository) FindSubscribers(listID int64) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s s
+	INNER JOIN %[3]s m ON m.SubscriberId = s.Id
+	WHERE m.ListId = %[4]s
+	AND m.IsSubscribed = 1
+	AND s.Delflag = 0
+	AND s.IsSubscribed = 1
+	ORDER BY s.Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Subscribers{}, []string{}, "s"),
+		repo.SubscribersCollection,
+		repo.MembersCollection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, listID)
+	if err != nil {
+		go repo.Logs.Error("", "lists_Repo_FindSubscribers", listID, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
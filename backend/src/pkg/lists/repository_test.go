@@ -0,0 +1,117 @@
+package lists_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/lists"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *lists.SqlRepository) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	return mockDB, lists.NewRepository("TB_MAS_Lists", "TB_TRN_SubscriberLists", "TB_TRN_Subscribers", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should insert list and return new id", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`INSERT INTO TB_MAS_Lists(.|\s)+OUTPUT INSERTED.Id`).
+			WithArgs("weekly digest", "every monday").
+			WillReturnRows(sqlmock.NewRows([]string{"Id"}).AddRow(3))
+
+		id, err := repo.Insert(entity.Lists{Name: "weekly digest", Description: "every monday"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_SubscribeMember(t *testing.T) {
+	t.Run("should upsert membership and only resubscribe members who left", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`MERGE TB_TRN_SubscriberLists WITH \(HOLDLOCK\)(.|\s)+WHEN MATCHED AND target.IsSubscribed = 0 THEN(.|\s)+WHEN NOT MATCHED THEN\s+INSERT`).
+			WithArgs(int64(3), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.SubscribeMember(3, 7)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec("MERGE").WillReturnError(errors.New("Error"))
+
+		err := repo.SubscribeMember(3, 7)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_UnsubscribeMember(t *testing.T) {
+	t.Run("should only leave given list", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`UPDATE TB_TRN_SubscriberLists\s+SET\s+IsSubscribed = 0(.|\s)+WHERE ListId = @p1\s+AND SubscriberId = @p2\s+AND IsSubscribed = 1`).
+			WithArgs(int64(3), int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UnsubscribeMember(3, 7)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_UnsubscribeAllMembers(t *testing.T) {
+	t.Run("should leave every list of subscriber", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`UPDATE TB_TRN_SubscriberLists\s+SET\s+IsSubscribed = 0(.|\s)+WHERE SubscriberId = @p1\s+AND IsSubscribed = 1`).
+			WithArgs(int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repo.UnsubscribeAllMembers(7)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FindSubscribers(t *testing.T) {
+	t.Run("should return confirmed subscribers still on list", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`SELECT s.\[id\](.|\s)+FROM TB_TRN_Subscribers s\s+INNER JOIN TB_TRN_SubscriberLists m ON m.SubscriberId = s.Id\s+WHERE m.ListId = @p1\s+AND m.IsSubscribed = 1\s+AND s.Delflag = 0\s+AND s.IsSubscribed = 1`).
+			WithArgs(int64(3)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "ajistestmail@gmail.com"))
+
+		res, err := repo.FindSubscribers(3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("SELECT").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindSubscribers(3)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,337 @@
+package lists
+
+import (
+	"fmt"
+	"html"
+	"newsletter/src/pkg/email"
+	"newsletter/src/pkg/entity"
+	subscribers "newsletter/src/pkg/subscribers"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/token"
+	"newsletter/src/pkg/utils/validation"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	MaxDescriptionLength = 1000
+
+	ConfirmTokenPurpose = "list-confirm"
+
+	confirmMailSubject = "Please confirm joining %s"
+	confirmMailBody    = `<p>Hi %[1]s,</p>
+<p>Please confirm you want to receive %[2]s by clicking the link below.</p>
+<p><a href="%[3]s">%[3]s</a></p>
+<p>This link expires on %[4]s. If you did not ask for this, you can ignore this email.</p>`
+)
+
+type UseCase interface {
+	GetAllLists() ([]entity.Lists, *newsletterError.ErrorCode)
+	FindByID(id int64) (*entity.Lists, *newsletterError.ErrorCode)
+	Create(list entity.Lists) (*entity.Lists, *newsletterError.ErrorCode)
+	Update(list entity.Lists) *newsletterError.ErrorCode
+	Delete(id int64) *newsletterError.ErrorCode
+	Subscribe(listID int64, subscriber entity.Subscribers) *newsletterError.ErrorCode
+	Confirm(confirmToken string) *newsletterError.ErrorCode
+	UnsubscribeByToken(listID int64, unsubscribeToken string) *newsletterError.ErrorCode
+	UnsubscribeAllByToken(unsubscribeToken string) *newsletterError.ErrorCode
+	GetSubscribers(listID int64) ([]entity.Subscribers, *newsletterError.ErrorCode)
+}
+
+// ServiceConfig is how list confirmation links are signed and where they
+// point. TokenSecret is the one subscribers sign their tokens with.
+type ServiceConfig struct {
+	TokenSecret string
+	ConfirmTTL  time.Duration
+	ConfirmURL  string
+}
+
+type Service struct {
+	UseCase
+	Repo        Repository
+	Subscribers subscribers.UseCase
+	Email       email.UseCase
+	Config      ServiceConfig
+	Logs        logger.Logger
+}
+
+func NewService(repo Repository, subscribersService subscribers.UseCase, emailService email.UseCase, config ServiceConfig, logs logger.Logger) *Service {
+	service := &Service{
+		Repo:        repo,
+		Subscribers: subscribersService,
+		Email:       emailService,
+		Config:      config,
+		Logs:        logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetAllLists() ([]entity.Lists, *newsletterError.ErrorCode) {
+	res, err := service.Repo.GetAllLists()
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) FindByID(id int64) (*entity.Lists, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+func (service *Service) Create(list entity.Lists) (*entity.Lists, *newsletterError.ErrorCode) {
+	list, errValidate := validateList(list)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	id, err := service.Repo.Insert(list)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.UseCase.FindByID(id)
+}
+
+func (service *Service) Update(list entity.Lists) *newsletterError.ErrorCode {
+	_, err := service.UseCase.FindByID(list.ID)
+	if err != nil {
+		return err
+	}
+
+	list, errValidate := validateList(list)
+	if errValidate != nil {
+		return errValidate
+	}
+
+	errUpdate := service.Repo.UpdateByID(list)
+	if errUpdate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) Delete(id int64) *newsletterError.ErrorCode {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errDelete := service.Repo.DeleteByID(id)
+	if errDelete != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// Subscribe asks a subscriber to confirm joining a list. Nobody is put on a
+// list until they follow the link in the email, whether or not they already
+// get the newsletter; people who do not yet are saved as pending, as they are
+// by a plain subscribe.
+func (service *Service) Subscribe(listID int64, subscriber entity.Subscribers) *newsletterError.ErrorCode {
+	resList, err := service.UseCase.FindByID(listID)
+	if err != nil {
+		return err
+	}
+
+	_, errPending := service.Subscribers.SavePending(subscriber)
+	if errPending != nil {
+		return errPending
+	}
+
+	expiredDate := time.Now().Add(service.Config.ConfirmTTL)
+	confirmToken := token.Generate(service.Config.TokenSecret, ConfirmTokenPurpose, confirmSubject(listID, subscriber.Email), &expiredDate)
+	confirmLink := fmt.Sprintf("%s?token=%s", service.Config.ConfirmURL, confirmToken)
+
+	errSend := service.Email.Send(email.SentMailContent{
+		To:      subscriber.Email,
+		Supject: fmt.Sprintf(confirmMailSubject, resList.Name),
+		Body:    fmt.Sprintf(confirmMailBody, html.EscapeString(subscriber.Name), html.EscapeString(resList.Name), confirmLink, expiredDate.Format(time.RFC1123)),
+	})
+	if errSend != nil {
+		go service.Logs.Error("", "lists_Service_Subscribe_SendConfirmation", subscriber.Email, errSend.Error())
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// Confirm puts the subscriber a list confirmation token was issued to on its
+// list. The link proves they own the address, so a subscriber still waiting
+// to confirm the newsletter is confirmed with it.
+func (service *Service) Confirm(confirmToken string) *newsletterError.ErrorCode {
+	listID, email, errToken := service.verifyConfirmToken(confirmToken)
+	if errToken != nil {
+		return errToken
+	}
+
+	_, err := service.UseCase.FindByID(listID)
+	if err != nil {
+		return err
+	}
+
+	resSubscriber, errFind := service.findSubscriber(email)
+	if errFind != nil {
+		return errFind
+	}
+
+	if !resSubscriber.IsSubscribed {
+		errConfirm := service.Subscribers.ConfirmByEmail(email)
+		if errConfirm != nil {
+			return errConfirm
+		}
+	}
+
+	errMember := service.Repo.SubscribeMember(listID, resSubscriber.ID)
+	if errMember != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// UnsubscribeByToken takes the subscriber an unsubscribe token was issued to
+// off one list and leaves the others alone. Leaving a list they are not on is
+// not an error.
+func (service *Service) UnsubscribeByToken(listID int64, unsubscribeToken string) *newsletterError.ErrorCode {
+	email, errToken := service.Subscribers.VerifyUnsubscribeToken(unsubscribeToken)
+	if errToken != nil {
+		return errToken
+	}
+
+	_, err := service.UseCase.FindByID(listID)
+	if err != nil {
+		return err
+	}
+
+	resSubscriber, errFind := service.findSubscriber(email)
+	if errFind != nil {
+		return errFind
+	}
+
+	errMember := service.Repo.UnsubscribeMember(listID, resSubscriber.ID)
+	if errMember != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// UnsubscribeAllByToken unsubscribes the subscriber an unsubscribe token was
+// issued to from the newsletter and every list, so subscribing again later
+// does not bring old lists back.
+func (service *Service) UnsubscribeAllByToken(unsubscribeToken string) *newsletterError.ErrorCode {
+	email, errToken := service.Subscribers.VerifyUnsubscribeToken(unsubscribeToken)
+	if errToken != nil {
+		return errToken
+	}
+
+	resSubscriber, errFind := service.findSubscriber(email)
+	if errFind != nil {
+		return errFind
+	}
+
+	errUnsubscribe := service.Subscribers.Unsubscribe(entity.Subscribers{Email: email})
+	if errUnsubscribe != nil {
+		return errUnsubscribe
+	}
+
+	errMembers := service.Repo.UnsubscribeAllMembers(resSubscriber.ID)
+	if errMembers != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) GetSubscribers(listID int64) ([]entity.Subscribers, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindSubscribers(listID)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) findSubscriber(email string) (*entity.Subscribers, *newsletterError.ErrorCode) {
+	res, err := service.Subscribers.FindByEmail(email)
+	if err != nil && *err != newsletterError.DataNotFound {
+		return nil, err
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+func (service *Service) verifyConfirmToken(confirmToken string) (int64, string, *newsletterError.ErrorCode) {
+	subject, err := token.Verify(service.Config.TokenSecret, ConfirmTokenPurpose, confirmToken)
+	switch err {
+	case nil:
+	case token.ErrExpiredToken:
+		return 0, "", convert.ValueToErrorCodePointer(newsletterError.ExpiredToken)
+	default:
+		return 0, "", convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+	}
+
+	parts := strings.SplitN(subject, ":", 2)
+	if len(parts) != 2 {
+		return 0, "", convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+	}
+
+	listID, errID := strconv.ParseInt(parts[0], 10, 64)
+	if errID != nil {
+		return 0, "", convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+	}
+
+	return listID, parts[1], nil
+}
+
+// confirmSubject ties a confirmation token to one list as well as the email,
+// so it cannot be used to join another list.
+func confirmSubject(listID int64, email string) string {
+	return fmt.Sprintf("%d:%s", listID, email)
+}
+
+func validateList(list entity.Lists) (entity.Lists, *newsletterError.ErrorCode) {
+	name, errName := validation.RequiredText(list.Name, validation.MaxLength)
+	if errName != nil {
+		return list, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	list.Name = name
+
+	list.Description = strings.TrimSpace(list.Description)
+	if len([]rune(list.Description)) > MaxDescriptionLength {
+		return list, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,604 @@
+package lists_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/email"
+	emailMocks "newsletter/src/pkg/email/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/lists"
+	"newsletter/src/pkg/lists/mocks"
+	subscribersMocks "newsletter/src/pkg/subscribers/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"newsletter/src/pkg/utils/token"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase        *mocks.UseCase
+	repository         *mocks.Repository
+	subscribersService *subscribersMocks.UseCase
+	emailService       *emailMocks.UseCase
+	service            *lists.Service
+	logs               *loggerMocks.Logger
+
+	mockRepoGetAllLists           *mocker.MockCall
+	mockRepoFindByID              *mocker.MockCall
+	mockRepoInsert                *mocker.MockCall
+	mockRepoUpdateByID            *mocker.MockCall
+	mockRepoDeleteByID            *mocker.MockCall
+	mockRepoSubscribeMember       *mocker.MockCall
+	mockRepoUnsubscribeMember     *mocker.MockCall
+	mockRepoUnsubscribeAllMembers *mocker.MockCall
+	mockRepoFindSubscribers       *mocker.MockCall
+	mockServiceFindByID           *mocker.MockCall
+	mockSubscribersSavePending    *mocker.MockCall
+	mockSubscribersConfirmByEmail *mocker.MockCall
+	mockEmailSend                 *mocker.MockCall
+	mockSubscribersUnsubscribe    *mocker.MockCall
+	mockSubscribersFindByEmail    *mocker.MockCall
+	mockSubscribersVerifyToken    *mocker.MockCall
+)
+
+func callRepoGetAllLists() *mock.Call {
+	return repository.On("GetAllLists")
+}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoUpdateByID() *mock.Call {
+	return repository.On("UpdateByID", mock.Anything)
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callRepoSubscribeMember() *mock.Call {
+	return repository.On("SubscribeMember", mock.Anything, mock.Anything)
+}
+
+func callRepoUnsubscribeMember() *mock.Call {
+	return repository.On("UnsubscribeMember", mock.Anything, mock.Anything)
+}
+
+func callRepoUnsubscribeAllMembers() *mock.Call {
+	return repository.On("UnsubscribeAllMembers", mock.Anything)
+}
+
+func callRepoFindSubscribers() *mock.Call {
+	return repository.On("FindSubscribers", mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func callSubscribersSavePending() *mock.Call {
+	return subscribersService.On("SavePending", mock.Anything)
+}
+
+func callSubscribersConfirmByEmail() *mock.Call {
+	return subscribersService.On("ConfirmByEmail", mock.Anything)
+}
+
+func callEmailSend() *mock.Call {
+	return emailService.On("Send", mock.Anything)
+}
+
+func callSubscribersUnsubscribe() *mock.Call {
+	return subscribersService.On("Unsubscribe", mock.Anything)
+}
+
+func callSubscribersFindByEmail() *mock.Call {
+	return subscribersService.On("FindByEmail", mock.Anything)
+}
+
+func callSubscribersVerifyUnsubscribeToken() *mock.Call {
+	return subscribersService.On("VerifyUnsubscribeToken", mock.Anything)
+}
+
+var config = lists.ServiceConfig{
+	TokenSecret: "secret",
+	ConfirmTTL:  time.Hour,
+	ConfirmURL:  "http://localhost:8000/lists/confirm",
+}
+
+func confirmToken(subject string, expiredDate time.Time) string {
+	return token.Generate(config.TokenSecret, lists.ConfirmTokenPurpose, subject, &expiredDate)
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	subscribersService = &subscribersMocks.UseCase{}
+	emailService = &emailMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &lists.Service{
+		Repo:        repository,
+		Subscribers: subscribersService,
+		Email:       emailService,
+		Config:      config,
+		Logs:        logs,
+	}
+	service.UseCase = mockUseCase
+
+	mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+	mockServiceFindByID.Return(&entity.Lists{ID: 3, Name: "weekly digest"}, nil)
+	mockSubscribersFindByEmail = mocker.NewMockCall(callSubscribersFindByEmail)
+	mockSubscribersFindByEmail.Return([]entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, nil)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct lists service when call new service", func(t *testing.T) {
+		beforeEach()
+		resService := lists.NewService(repository, subscribersService, emailService, config, logs)
+
+		expectedService := &lists.Service{
+			Repo:        repository,
+			Subscribers: subscribersService,
+			Email:       emailService,
+			Config:      config,
+			Logs:        logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_GetAllLists(t *testing.T) {
+	beforeEachGetAllLists := func() {
+		beforeEach()
+		mockRepoGetAllLists = mocker.NewMockCall(callRepoGetAllLists)
+		mockRepoGetAllLists.Return([]entity.Lists{{ID: 3}}, nil)
+	}
+
+	t.Run("should return lists when found", func(t *testing.T) {
+		beforeEachGetAllLists()
+
+		res, err := service.GetAllLists()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Lists{{ID: 3}}, res)
+	})
+
+	t.Run("should return data not found when there is no list", func(t *testing.T) {
+		beforeEachGetAllLists()
+		mockRepoGetAllLists.Return([]entity.Lists{}, nil)
+
+		res, err := service.GetAllLists()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachGetAllLists()
+		mockRepoGetAllLists.Return(nil, errors.New("Error"))
+
+		_, err := service.GetAllLists()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.Lists{{ID: 3}}, nil)
+	}
+
+	t.Run("should return first list when found", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Lists{ID: 3}, res)
+		repository.AssertCalled(t, "FindByID", int64(3))
+	})
+
+	t.Run("should return data not found when list is missing or deleted", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.Lists{}, nil)
+
+		res, err := service.FindByID(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	beforeEachCreate := func() {
+		beforeEach()
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(3), nil)
+	}
+
+	t.Run("should insert trimmed list and return it", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Lists{Name: " weekly digest ", Description: " every monday "})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Lists{ID: 3, Name: "weekly digest"}, res)
+		repository.AssertCalled(t, "Insert", entity.Lists{Name: "weekly digest", Description: "every monday"})
+		mockUseCase.AssertCalled(t, "FindByID", int64(3))
+	})
+
+	t.Run("should return bad request when name is empty", func(t *testing.T) {
+		beforeEachCreate()
+
+		_, err := service.Create(entity.Lists{Name: "  "})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return bad request when description is too long", func(t *testing.T) {
+		beforeEachCreate()
+
+		_, err := service.Create(entity.Lists{Name: "promotions", Description: strings.Repeat("a", lists.MaxDescriptionLength+1)})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+	})
+
+	t.Run("should return internal server error when insert failed", func(t *testing.T) {
+		beforeEachCreate()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		res, err := service.Create(entity.Lists{Name: "promotions"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	beforeEachUpdate := func() {
+		beforeEach()
+		mockRepoUpdateByID = mocker.NewMockCall(callRepoUpdateByID)
+		mockRepoUpdateByID.Return(nil)
+	}
+
+	t.Run("should update existing list", func(t *testing.T) {
+		beforeEachUpdate()
+
+		err := service.Update(entity.Lists{ID: 3, Name: "product updates"})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpdateByID", entity.Lists{ID: 3, Name: "product updates"})
+	})
+
+	t.Run("should return data not found when list is missing", func(t *testing.T) {
+		beforeEachUpdate()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Update(entity.Lists{ID: 3, Name: "product updates"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "UpdateByID", mock.Anything)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	beforeEachDelete := func() {
+		beforeEach()
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(nil)
+	}
+
+	t.Run("should delete existing list", func(t *testing.T) {
+		beforeEachDelete()
+
+		err := service.Delete(3)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "DeleteByID", int64(3))
+	})
+
+	t.Run("should return internal server error when delete failed", func(t *testing.T) {
+		beforeEachDelete()
+		mockRepoDeleteByID.Return(errors.New("Error"))
+
+		err := service.Delete(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Subscribe(t *testing.T) {
+	subscriber := entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "ajis"}
+
+	beforeEachSubscribe := func() {
+		beforeEach()
+		mockSubscribersSavePending = mocker.NewMockCall(callSubscribersSavePending)
+		mockSubscribersSavePending.Return(&entity.Subscribers{Email: "ajistestmail@gmail.com", IsPending: true}, nil)
+		mockEmailSend = mocker.NewMockCall(callEmailSend)
+		mockEmailSend.Return(nil)
+	}
+
+	t.Run("should save pending subscriber and email a list confirmation link without adding membership", func(t *testing.T) {
+		beforeEachSubscribe()
+
+		err := service.Subscribe(3, subscriber)
+
+		assert.Nil(t, err)
+		subscribersService.AssertCalled(t, "SavePending", subscriber)
+		emailService.AssertCalled(t, "Send", mock.MatchedBy(func(content email.SentMailContent) bool {
+			return content.To == "ajistestmail@gmail.com" &&
+				strings.Contains(content.Supject, "weekly digest") &&
+				strings.Contains(content.Body, config.ConfirmURL+"?token=")
+		}))
+		repository.AssertNotCalled(t, "SubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should ask a confirmed subscriber to confirm the list too", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribersSavePending.Return(nil, nil)
+
+		err := service.Subscribe(3, subscriber)
+
+		assert.Nil(t, err)
+		emailService.AssertNumberOfCalls(t, "Send", 1)
+		repository.AssertNotCalled(t, "SubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return data not found without subscribing when list is missing", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Subscribe(3, subscriber)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		subscribersService.AssertNotCalled(t, "SavePending", mock.Anything)
+	})
+
+	t.Run("should return error without sending when save pending failed", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribersSavePending.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		err := service.Subscribe(3, subscriber)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		emailService.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("should return internal server error when send failed", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockEmailSend.Return(errors.New("Error"))
+
+		err := service.Subscribe(3, subscriber)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Confirm(t *testing.T) {
+	beforeEachConfirm := func() {
+		beforeEach()
+		mockSubscribersConfirmByEmail = mocker.NewMockCall(callSubscribersConfirmByEmail)
+		mockSubscribersConfirmByEmail.Return(nil)
+		mockRepoSubscribeMember = mocker.NewMockCall(callRepoSubscribeMember)
+		mockRepoSubscribeMember.Return(nil)
+	}
+	validToken := confirmToken("3:ajistestmail@gmail.com", time.Now().Add(time.Hour))
+
+	t.Run("should confirm pending subscriber and add membership", func(t *testing.T) {
+		beforeEachConfirm()
+
+		err := service.Confirm(validToken)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "FindByID", int64(3))
+		subscribersService.AssertCalled(t, "ConfirmByEmail", "ajistestmail@gmail.com")
+		repository.AssertCalled(t, "SubscribeMember", int64(3), int64(7))
+	})
+
+	t.Run("should only add membership when subscriber is already confirmed", func(t *testing.T) {
+		beforeEachConfirm()
+		mockSubscribersFindByEmail.Return([]entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com", IsSubscribed: true}}, nil)
+
+		err := service.Confirm(validToken)
+
+		assert.Nil(t, err)
+		subscribersService.AssertNotCalled(t, "ConfirmByEmail", mock.Anything)
+		repository.AssertCalled(t, "SubscribeMember", int64(3), int64(7))
+	})
+
+	t.Run("should return expired token when token expired", func(t *testing.T) {
+		beforeEachConfirm()
+
+		err := service.Confirm(confirmToken("3:ajistestmail@gmail.com", time.Now().Add(-time.Hour)))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.ExpiredToken), err)
+		repository.AssertNotCalled(t, "SubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return invalid token when token is not for a list", func(t *testing.T) {
+		beforeEachConfirm()
+		subscribeToken := token.Generate(config.TokenSecret, "confirm", "ajistestmail@gmail.com", nil)
+
+		err := service.Confirm(subscribeToken)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidToken), err)
+		repository.AssertNotCalled(t, "SubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return invalid token when subject has no list id", func(t *testing.T) {
+		beforeEachConfirm()
+
+		err := service.Confirm(confirmToken("ajistestmail@gmail.com", time.Now().Add(time.Hour)))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidToken), err)
+	})
+
+	t.Run("should return data not found when pending subscriber was removed", func(t *testing.T) {
+		beforeEachConfirm()
+		mockSubscribersFindByEmail.Return([]entity.Subscribers{}, nil)
+
+		err := service.Confirm(validToken)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "SubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return internal server error when add membership failed", func(t *testing.T) {
+		beforeEachConfirm()
+		mockRepoSubscribeMember.Return(errors.New("Error"))
+
+		err := service.Confirm(validToken)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_UnsubscribeByToken(t *testing.T) {
+	beforeEachUnsubscribe := func() {
+		beforeEach()
+		mockSubscribersVerifyToken = mocker.NewMockCall(callSubscribersVerifyUnsubscribeToken)
+		mockSubscribersVerifyToken.Return("ajistestmail@gmail.com", nil)
+		mockRepoUnsubscribeMember = mocker.NewMockCall(callRepoUnsubscribeMember)
+		mockRepoUnsubscribeMember.Return(nil)
+	}
+
+	t.Run("should remove membership of subscriber the token was issued to", func(t *testing.T) {
+		beforeEachUnsubscribe()
+
+		err := service.UnsubscribeByToken(3, "token")
+
+		assert.Nil(t, err)
+		subscribersService.AssertCalled(t, "VerifyUnsubscribeToken", "token")
+		subscribersService.AssertCalled(t, "FindByEmail", "ajistestmail@gmail.com")
+		repository.AssertCalled(t, "UnsubscribeMember", int64(3), int64(7))
+		subscribersService.AssertNotCalled(t, "Unsubscribe", mock.Anything)
+	})
+
+	t.Run("should return data not found when email is unknown", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		mockSubscribersFindByEmail.Return([]entity.Subscribers{}, nil)
+
+		err := service.UnsubscribeByToken(3, "token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "UnsubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return invalid token when token does not verify", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		mockSubscribersVerifyToken.Return("", convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		err := service.UnsubscribeByToken(3, "token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InvalidToken), err)
+		subscribersService.AssertNotCalled(t, "FindByEmail", mock.Anything)
+		repository.AssertNotCalled(t, "UnsubscribeMember", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return data not found when list is missing", func(t *testing.T) {
+		beforeEachUnsubscribe()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.UnsubscribeByToken(3, "token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+}
+
+func TestService_UnsubscribeAllByToken(t *testing.T) {
+	beforeEachUnsubscribeAll := func() {
+		beforeEach()
+		mockSubscribersVerifyToken = mocker.NewMockCall(callSubscribersVerifyUnsubscribeToken)
+		mockSubscribersVerifyToken.Return("ajistestmail@gmail.com", nil)
+		mockSubscribersUnsubscribe = mocker.NewMockCall(callSubscribersUnsubscribe)
+		mockSubscribersUnsubscribe.Return(nil)
+		mockRepoUnsubscribeAllMembers = mocker.NewMockCall(callRepoUnsubscribeAllMembers)
+		mockRepoUnsubscribeAllMembers.Return(nil)
+	}
+
+	t.Run("should unsubscribe subscriber the token was issued to from newsletter and every list", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+
+		err := service.UnsubscribeAllByToken("token")
+
+		assert.Nil(t, err)
+		subscribersService.AssertCalled(t, "Unsubscribe", entity.Subscribers{Email: "ajistestmail@gmail.com"})
+		repository.AssertCalled(t, "UnsubscribeAllMembers", int64(7))
+	})
+
+	t.Run("should return data not found when email is unknown", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+		mockSubscribersFindByEmail.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.UnsubscribeAllByToken("token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		subscribersService.AssertNotCalled(t, "Unsubscribe", mock.Anything)
+	})
+
+	t.Run("should return expired token when token expired", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+		mockSubscribersVerifyToken.Return("", convert.ValueToErrorCodePointer(newsletterError.ExpiredToken))
+
+		err := service.UnsubscribeAllByToken("token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.ExpiredToken), err)
+		subscribersService.AssertNotCalled(t, "Unsubscribe", mock.Anything)
+		repository.AssertNotCalled(t, "UnsubscribeAllMembers", mock.Anything)
+	})
+
+	t.Run("should return internal server error when leaving lists failed", func(t *testing.T) {
+		beforeEachUnsubscribeAll()
+		mockRepoUnsubscribeAllMembers.Return(errors.New("Error"))
+
+		err := service.UnsubscribeAllByToken("token")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_GetSubscribers(t *testing.T) {
+	beforeEachGetSubscribers := func() {
+		beforeEach()
+		mockRepoFindSubscribers = mocker.NewMockCall(callRepoFindSubscribers)
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 7}}, nil)
+	}
+
+	t.Run("should return members of list", func(t *testing.T) {
+		beforeEachGetSubscribers()
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 7}}, res)
+		repository.AssertCalled(t, "FindSubscribers", int64(3))
+	})
+
+	t.Run("should return data not found when list has no member", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindSubscribers.Return([]entity.Subscribers{}, nil)
+
+		_, err := service.GetSubscribers(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+}
@@ -0,0 +1,223 @@
+package segments_test
+
+import (
+	"encoding/json"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/segments"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	compiler    = segments.NewCompiler("TB_TRN_SubscriberFieldValues", "TB_TRN_Deliveries")
+	definitions = []entity.CustomFields{
+		{ID: 4, Key: "country", Type: entity.FieldTypeString},
+		{ID: 5, Key: "age", Type: entity.FieldTypeNumber},
+		{ID: 6, Key: "birthday", Type: entity.FieldTypeDate},
+		{ID: 7, Key: "plan", Type: entity.FieldTypeEnum, Options: "free,pro"},
+	}
+)
+
+func parseRule(t *testing.T, rules string) entity.SegmentRule {
+	var rule entity.SegmentRule
+	if err := json.Unmarshal([]byte(rules), &rule); err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+// sharedCases are rules cmstool compiles from the same file, so both
+// compilers select the same subscribers.
+type sharedCases struct {
+	Definitions []entity.CustomFields `json:"definitions"`
+	Cases       []struct {
+		Name    string          `json:"name"`
+		Rules   json.RawMessage `json:"rules"`
+		Where   string          `json:"where"`
+		Args    json.RawMessage `json:"args"`
+		Invalid []string        `json:"invalid"`
+	} `json:"cases"`
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	t.Run("should compile custom field, built-in field and engagement conditions", func(t *testing.T) {
+		rule := parseRule(t, `{"and":[
+			{"field":"fields.country","op":"eq","value":"TH"},
+			{"field":"subscribedDate","op":"withinDays","value":30},
+			{"engagement":"received"}
+		]}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "("+
+			"EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p1 AND (v.[StringValue] = @p2))"+
+			" AND (s.[SubscribedDate] >= DATEADD(DAY, -@p3, GETDATE()))"+
+			" AND EXISTS (SELECT 1 FROM TB_TRN_Deliveries d WHERE d.[SubscriberId] = s.[Id] AND d.[Status] = @p4)"+
+			")", condition.Where)
+		assert.Equal(t, []interface{}{int64(4), "TH", 30, entity.DeliveryStatusSent}, condition.Args)
+	})
+
+	t.Run("should join or groups and negate not groups", func(t *testing.T) {
+		rule := parseRule(t, `{"or":[{"field":"name","op":"startsWith","value":"aj"},{"not":{"field":"frequency","op":"eq","value":"weekly"}}]}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "((s.[Name] LIKE @p1 ESCAPE '\\') OR NOT (s.[Frequency] = @p2))", condition.Where)
+		assert.Equal(t, []interface{}{"aj%", "weekly"}, condition.Args)
+	})
+
+	t.Run("should match subscribers without value on neq and notSet", func(t *testing.T) {
+		rule := parseRule(t, `{"and":[{"field":"language","op":"neq","value":"th"},{"field":"confirmedDate","op":"notSet"},{"field":"fields.age","op":"neq","value":30}]}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "("+
+			"(s.[Language] IS NULL OR NOT (s.[Language] = @p1))"+
+			" AND (s.[ConfirmedDate] IS NULL)"+
+			" AND NOT EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p2 AND (v.[NumberValue] = @p3))"+
+			")", condition.Where)
+		assert.Equal(t, []interface{}{"th", int64(5), float64(30)}, condition.Args)
+	})
+
+	t.Run("should compare dates by day and pass them as time", func(t *testing.T) {
+		rule := parseRule(t, `{"field":"fields.birthday","op":"lt","value":"2000-01-01"}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p1 AND (CAST(v.[DateValue] AS date) < @p2))", condition.Where)
+		assert.Equal(t, []interface{}{int64(6), time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}, condition.Args)
+	})
+
+	t.Run("should pass in values and like patterns as arguments", func(t *testing.T) {
+		rule := parseRule(t, `{"and":[{"field":"fields.plan","op":"in","value":["free","pro"]},{"field":"email","op":"contains","value":"50%_off' OR 1=1 --"}]}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "("+
+			"EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p1 AND (v.[StringValue] IN (@p2,@p3)))"+
+			" AND (s.[Email] LIKE @p4 ESCAPE '\\')"+
+			")", condition.Where)
+		assert.Equal(t, []interface{}{int64(7), "free", "pro", `%50\%\_off' OR 1=1 --%`}, condition.Args)
+	})
+
+	t.Run("should scope engagement to campaign and window", func(t *testing.T) {
+		rule := parseRule(t, `{"engagement":"received","campaignId":3,"withinDays":7}`)
+
+		condition, fieldErrors := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, fieldErrors)
+		assert.Equal(t, "EXISTS (SELECT 1 FROM TB_TRN_Deliveries d WHERE d.[SubscriberId] = s.[Id] AND d.[Status] = @p1 AND d.[CampaignId] = @p2 AND d.[SentDate] >= DATEADD(DAY, -@p3, GETDATE()))", condition.Where)
+		assert.Equal(t, []interface{}{entity.DeliveryStatusSent, int64(3), 7}, condition.Args)
+	})
+
+	invalidRules := map[string]struct {
+		rules    string
+		expected []newsletterError.FieldError
+	}{
+		"rule sets nothing": {
+			rules:    `{}`,
+			expected: []newsletterError.FieldError{{Field: "rules", Code: newsletterError.FieldInvalidRule}},
+		},
+		"rule sets both group and field": {
+			rules:    `{"and":[{"engagement":"received"}],"field":"email","op":"isSet"}`,
+			expected: []newsletterError.FieldError{{Field: "rules", Code: newsletterError.FieldInvalidRule}},
+		},
+		"group is empty": {
+			rules:    `{"or":[]}`,
+			expected: []newsletterError.FieldError{{Field: "rules.or", Code: newsletterError.FieldInvalidRule}},
+		},
+		"field is unknown": {
+			rules:    `{"and":[{"field":"fields.team","op":"eq","value":"a"},{"field":"Email; DROP TABLE x","op":"isSet"}]}`,
+			expected: []newsletterError.FieldError{{Field: "rules.and.0.field", Code: newsletterError.FieldUnknown}, {Field: "rules.and.1.field", Code: newsletterError.FieldUnknown}},
+		},
+		"op does not fit field type": {
+			rules:    `{"field":"fields.age","op":"contains","value":"3"}`,
+			expected: []newsletterError.FieldError{{Field: "rules.op", Code: newsletterError.FieldInvalidOption}},
+		},
+		"value is missing": {
+			rules:    `{"field":"email","op":"eq"}`,
+			expected: []newsletterError.FieldError{{Field: "rules.value", Code: newsletterError.FieldRequired}},
+		},
+		"value has wrong type": {
+			rules:    `{"field":"fields.age","op":"gt","value":"30"}`,
+			expected: []newsletterError.FieldError{{Field: "rules.value", Code: newsletterError.FieldInvalidType}},
+		},
+		"date is not a day": {
+			rules:    `{"field":"subscribedDate","op":"gte","value":"01/02/2024"}`,
+			expected: []newsletterError.FieldError{{Field: "rules.value", Code: newsletterError.FieldInvalidDate}},
+		},
+		"enum value is not an option": {
+			rules:    `{"field":"fields.plan","op":"in","value":["free","team"]}`,
+			expected: []newsletterError.FieldError{{Field: "rules.value.1", Code: newsletterError.FieldInvalidOption}},
+		},
+		"days are not whole": {
+			rules:    `{"field":"subscribedDate","op":"withinDays","value":1.5}`,
+			expected: []newsletterError.FieldError{{Field: "rules.value", Code: newsletterError.FieldInvalidType}},
+		},
+		"engagement is unknown": {
+			rules:    `{"engagement":"clicked"}`,
+			expected: []newsletterError.FieldError{{Field: "rules.engagement", Code: newsletterError.FieldInvalidOption}},
+		},
+		"rules are nested too deep": {
+			rules:    `{"not":{"not":{"not":{"not":{"not":{"field":"email","op":"isSet"}}}}}}`,
+			expected: []newsletterError.FieldError{{Field: "rules.not.not.not.not.not", Code: newsletterError.FieldTooComplex}},
+		},
+	}
+	for name, invalid := range invalidRules {
+		invalid := invalid
+		t.Run("should return field errors when "+name, func(t *testing.T) {
+			condition, fieldErrors := compiler.Compile(parseRule(t, invalid.rules), definitions)
+
+			assert.Nil(t, condition)
+			assert.Equal(t, invalid.expected, fieldErrors)
+		})
+	}
+
+	t.Run("should return field too complex when there are too many conditions", func(t *testing.T) {
+		conditions := make([]string, segments.MaxConditions+1)
+		for i := range conditions {
+			conditions[i] = `{"field":"email","op":"isSet"}`
+		}
+
+		condition, fieldErrors := compiler.Compile(parseRule(t, `{"or":[`+strings.Join(conditions, ",")+`]}`), definitions)
+
+		assert.Nil(t, condition)
+		assert.Equal(t, []newsletterError.FieldError{{Field: "rules", Code: newsletterError.FieldTooComplex}}, fieldErrors)
+	})
+
+	source, err := os.ReadFile("testdata/compile.json")
+	assert.Nil(t, err)
+	var shared sharedCases
+	assert.Nil(t, json.Unmarshal(source, &shared))
+	for _, sharedCase := range shared.Cases {
+		sharedCase := sharedCase
+		t.Run("should compile shared case "+sharedCase.Name, func(t *testing.T) {
+			condition, fieldErrors := compiler.Compile(parseRule(t, string(sharedCase.Rules)), shared.Definitions)
+
+			if sharedCase.Invalid != nil {
+				assert.Nil(t, condition)
+				fields := []string{}
+				for _, fieldError := range fieldErrors {
+					fields = append(fields, fieldError.Field)
+				}
+				assert.Equal(t, sharedCase.Invalid, fields)
+				return
+			}
+			assert.Nil(t, fieldErrors)
+			assert.Equal(t, sharedCase.Where, condition.Where)
+			args, err := json.Marshal(condition.Args)
+			assert.Nil(t, err)
+			assert.JSONEq(t, string(sharedCase.Args), string(args))
+		})
+	}
+}
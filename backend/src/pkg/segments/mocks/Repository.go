@@ -0,0 +1,242 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	segments "newsletter/src/pkg/segments"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// CountSubscribers provides a mock function with given fields: condition
+func (_m *Repository) CountSubscribers(condition segments.Condition) (int64, error) {
+	ret := _m.Called(condition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSubscribers")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(segments.Condition) (int64, error)); ok {
+		return rf(condition)
+	}
+	if rf, ok := ret.Get(0).(func(segments.Condition) int64); ok {
+		r0 = rf(condition)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(segments.Condition) error); ok {
+		r1 = rf(condition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64) error); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindAllSubscribers provides a mock function with given fields: condition
+func (_m *Repository) FindAllSubscribers(condition segments.Condition) ([]entity.Subscribers, error) {
+	ret := _m.Called(condition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAllSubscribers")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(segments.Condition) ([]entity.Subscribers, error)); ok {
+		return rf(condition)
+	}
+	if rf, ok := ret.Get(0).(func(segments.Condition) []entity.Subscribers); ok {
+		r0 = rf(condition)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(segments.Condition) error); ok {
+		r1 = rf(condition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.Segments, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 []entity.Segments
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Segments, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Segments); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Segments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscribers provides a mock function with given fields: condition, limit
+func (_m *Repository) FindSubscribers(condition segments.Condition, limit int) ([]entity.Subscribers, error) {
+	ret := _m.Called(condition, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSubscribers")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(segments.Condition, int) ([]entity.Subscribers, error)); ok {
+		return rf(condition, limit)
+	}
+	if rf, ok := ret.Get(0).(func(segments.Condition, int) []entity.Subscribers); ok {
+		r0 = rf(condition, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(segments.Condition, int) error); ok {
+		r1 = rf(condition, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllSegments provides a mock function with no fields
+func (_m *Repository) GetAllSegments() ([]entity.Segments, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllSegments")
+	}
+
+	var r0 []entity.Segments
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]entity.Segments, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Segments); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Segments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Insert provides a mock function with given fields: segment
+func (_m *Repository) Insert(segment entity.Segments) (int64, error) {
+	ret := _m.Called(segment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.Segments) (int64, error)); ok {
+		return rf(segment)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Segments) int64); ok {
+		r0 = rf(segment)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Segments) error); ok {
+		r1 = rf(segment)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// UpdateByID provides a mock function with given fields: segment
+func (_m *Repository) UpdateByID(segment entity.Segments) error {
+	ret := _m.Called(segment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateByID")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Segments) error); ok {
+		r0 = rf(segment)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,263 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "newsletter/src/pkg/entity"
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+
+	segments "newsletter/src/pkg/segments"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Create provides a mock function with given fields: segment
+func (_m *UseCase) Create(segment entity.Segments) (*entity.Segments, *error.ErrorCode) {
+	ret := _m.Called(segment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Create")
+	}
+
+	var r0 *entity.Segments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Segments) (*entity.Segments, *error.ErrorCode)); ok {
+		return rf(segment)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Segments) *entity.Segments); ok {
+		r0 = rf(segment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Segments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Segments) *error.ErrorCode); ok {
+		r1 = rf(segment)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Delete provides a mock function with given fields: id
+func (_m *UseCase) Delete(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.Segments, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByID")
+	}
+
+	var r0 *entity.Segments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.Segments, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.Segments); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Segments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetAllSegments provides a mock function with no fields
+func (_m *UseCase) GetAllSegments() ([]entity.Segments, *error.ErrorCode) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllSegments")
+	}
+
+	var r0 []entity.Segments
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() ([]entity.Segments, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []entity.Segments); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Segments)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSubscribers provides a mock function with given fields: id
+func (_m *UseCase) GetSubscribers(id int64) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscribers")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Preview provides a mock function with given fields: rule
+func (_m *UseCase) Preview(rule entity.SegmentRule) (*segments.Preview, *error.ErrorCode) {
+	ret := _m.Called(rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Preview")
+	}
+
+	var r0 *segments.Preview
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.SegmentRule) (*segments.Preview, *error.ErrorCode)); ok {
+		return rf(rule)
+	}
+	if rf, ok := ret.Get(0).(func(entity.SegmentRule) *segments.Preview); ok {
+		r0 = rf(rule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*segments.Preview)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.SegmentRule) *error.ErrorCode); ok {
+		r1 = rf(rule)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Update provides a mock function with given fields: segment
+func (_m *UseCase) Update(segment entity.Segments) *error.ErrorCode {
+	ret := _m.Called(segment)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Update")
+	}
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Segments) *error.ErrorCode); ok {
+		r0 = rf(segment)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// ValidateRules provides a mock function with given fields: rule
+func (_m *UseCase) ValidateRules(rule entity.SegmentRule) ([]error.FieldError, *error.ErrorCode) {
+	ret := _m.Called(rule)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ValidateRules")
+	}
+
+	var r0 []error.FieldError
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.SegmentRule) ([]error.FieldError, *error.ErrorCode)); ok {
+		return rf(rule)
+	}
+	if rf, ok := ret.Get(0).(func(entity.SegmentRule) []error.FieldError); ok {
+		r0 = rf(rule)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]error.FieldError)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.SegmentRule) *error.ErrorCode); ok {
+		r1 = rf(rule)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,74 @@
+package segments
+
+import (
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/subscribers"
+)
+
+const (
+	MaxDescriptionLength = 1000
+
+	// MaxDepth and MaxConditions keep compiled queries small enough for the
+	// database to plan quickly.
+	MaxDepth      = 5
+	MaxConditions = 50
+	MaxInValues   = 100
+	MaxDays       = 36500
+
+	// SampleSize is how many subscribers a preview returns.
+	SampleSize = 10
+
+	RulesField = "rules"
+
+	// CustomFieldPrefix marks a rule field as a custom field key rather than
+	// a built-in one, the same way custom values are named in payloads.
+	CustomFieldPrefix = "fields."
+)
+
+// Condition is a compiled rule tree: a WHERE clause over subscribers aliased
+// s, with its arguments numbered from 1.
+type Condition struct {
+	Where string
+	Args  []interface{}
+}
+
+// Preview is how many subscribers a segment matches right now and the first
+// few of them.
+type Preview struct {
+	Count  int64                `json:"count"`
+	Sample []entity.Subscribers `json:"sample"`
+}
+
+type BuiltInField struct {
+	Column  string
+	Type    entity.FieldType
+	Options []string
+}
+
+// BuiltInFields maps the json name of a subscriber field a rule can compare
+// to its column.
+var BuiltInFields = map[string]BuiltInField{
+	"email":          {Column: "s.[Email]", Type: entity.FieldTypeString},
+	"name":           {Column: "s.[Name]", Type: entity.FieldTypeString},
+	"language":       {Column: "s.[Language]", Type: entity.FieldTypeString},
+	"frequency":      {Column: "s.[Frequency]", Type: entity.FieldTypeEnum, Options: subscribers.Frequencies},
+	"timeZone":       {Column: "s.[TimeZone]", Type: entity.FieldTypeString},
+	"subscribedDate": {Column: "s.[SubscribedDate]", Type: entity.FieldTypeDate},
+	"confirmedDate":  {Column: "s.[ConfirmedDate]", Type: entity.FieldTypeDate},
+	"sourceApiKeyId": {Column: "s.[SourceApiKeyId]", Type: entity.FieldTypeNumber},
+}
+
+// TypeOps lists the comparisons each field type supports.
+var TypeOps = map[entity.FieldType][]entity.SegmentOp{
+	entity.FieldTypeString: {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIn, entity.SegmentOpContains, entity.SegmentOpStartsWith, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeEnum:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIn, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeNumber: {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpGt, entity.SegmentOpGte, entity.SegmentOpLt, entity.SegmentOpLte, entity.SegmentOpIn, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeDate:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpGt, entity.SegmentOpGte, entity.SegmentOpLt, entity.SegmentOpLte, entity.SegmentOpWithinDays, entity.SegmentOpOlderThanDays, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeBool:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+}
+
+// EngagementStatuses maps an engagement event to the delivery status that
+// records it.
+var EngagementStatuses = map[entity.EngagementEvent]entity.DeliveryStatus{
+	entity.EngagementReceived: entity.DeliveryStatusSent,
+}
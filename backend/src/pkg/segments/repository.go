@@ -0,0 +1,328 @@
+package segments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	GetAllSegments() ([]entity.Segments, error)
+	FindByID(id int64) ([]entity.Segments, error)
+	Insert(segment entity.Segments) (int64, error)
+	UpdateByID(segment entity.Segments) error
+	DeleteByID(id int64) error
+	CountSubscribers(condition Condition) (int64, error)
+	FindSubscribers(condition Condition, limit int) ([]entity.Subscribers, error)
+	FindAllSubscribers(condition Condition) ([]entity.Subscribers, error)
+}
+
+// SqlRepository keeps segments in Collection and matches them against
+// SubscribersCollection.
+type SqlRepository struct {
+	Collection            string
+	SubscribersCollection string
+	Session               *sql.DB
+	Logs                  logger.Logger
+}
+
+func NewRepository(collection string, subscribersCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection:            collection,
+		SubscribersCollection: subscribersCollection,
+		Session:               session,
+		Logs:                  logs,
+	}
+}
+
+func (repo *SqlRepository) GetAllSegments() ([]entity.Segments, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	ORDER BY Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Segments{}, []string{}),
+		repo.Collection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_GetAllSegments", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Segments{}
+	for rows.Next() {
+		var entity entity.Segments
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Segments, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND Id = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Segments{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_FindByID", id, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Segments{}
+	for rows.Next() {
+		var entity entity.Segments
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Insert(segment entity.Segments) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	ignoreFields := []string{"ID", "CreatedDate", "UpdatedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(segment, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.Segments{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_Insert", segment,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (repo *SqlRepository) UpdateByID(segment entity.Segments) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(segment, []string{"Name", "Description", "RulesJSON"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		%[2]s,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{segment.ID}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_UpdateByID", segment,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) DeleteByID(id int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Delflag = 1,
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// CountSubscribers counts the confirmed subscribers matching condition.
+func (repo *SqlRepository) CountSubscribers(condition Condition) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT COUNT(*)
+	FROM %[1]s s
+	WHERE s.Delflag = 0
+	AND s.IsSubscribed = 1
+	AND %[2]s
+	`,
+		repo.SubscribersCollection,
+		condition.Where,
+	)
+
+	var count int64
+	err := session.QueryRowContext(ctx, sql, condition.Args...).Scan(&count)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_CountSubscribers", condition.Args, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindSubscribers returns up to limit confirmed subscribers matching
+// condition, ordered by id.
+func (repo *SqlRepository) FindSubscribers(condition Condition, limit int) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	args := append(append([]interface{}{}, condition.Args...), limit)
+
+	sql := fmt.Sprintf(`
+	SELECT TOP (%[4]s) %[1]s
+	FROM %[2]s s
+	WHERE s.Delflag = 0
+	AND s.IsSubscribed = 1
+	AND %[3]s
+	ORDER BY s.Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Subscribers{}, []string{}, "s"),
+		repo.SubscribersCollection,
+		condition.Where,
+		sqlQuery.Param(len(args)),
+	)
+	rows, err := session.QueryContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_FindSubscribers", condition.Args, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// FindAllSubscribers returns every confirmed subscriber matching condition,
+// ordered by id.
+func (repo *SqlRepository) FindAllSubscribers(condition Condition) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s s
+	WHERE s.Delflag = 0
+	AND s.IsSubscribed = 1
+	AND %[3]s
+	ORDER BY s.Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Subscribers{}, []string{}, "s"),
+		repo.SubscribersCollection,
+		condition.Where,
+	)
+	rows, err := session.QueryContext(ctx, sql, condition.Args...)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_FindAllSubscribers", condition.Args, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
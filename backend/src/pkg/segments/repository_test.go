@@ -0,0 +1,106 @@
+package segments_test
+
+import (
+	"errors"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/segments"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *segments.SqlRepository) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	return mockDB, segments.NewRepository("TB_MAS_Segments", "TB_TRN_Subscribers", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should insert segment with stored rules and return new id", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`INSERT INTO TB_MAS_Segments(.|\s)+\[rules\](.|\s)+OUTPUT INSERTED.Id`).
+			WithArgs("thai readers", "by country", countryJSON).
+			WillReturnRows(sqlmock.NewRows([]string{"Id"}).AddRow(3))
+
+		id, err := repo.Insert(entity.Segments{Name: "thai readers", Description: "by country", Rules: countryRule, RulesJSON: countryJSON})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(3), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_CountSubscribers(t *testing.T) {
+	t.Run("should count confirmed subscribers matching condition", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`SELECT COUNT\(\*\)\s+FROM TB_TRN_Subscribers s\s+WHERE s.Delflag = 0\s+AND s.IsSubscribed = 1\s+AND \(s.\[Email\] = @p1\)`).
+			WithArgs("ajistestmail@gmail.com").
+			WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+		count, err := repo.CountSubscribers(segments.Condition{Where: "(s.[Email] = @p1)", Args: []interface{}{"ajistestmail@gmail.com"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FindSubscribers(t *testing.T) {
+	t.Run("should pass limit after condition arguments", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`SELECT TOP \(@p2\) s.\[id\](.|\s)+FROM TB_TRN_Subscribers s\s+WHERE s.Delflag = 0\s+AND s.IsSubscribed = 1\s+AND \(s.\[Name\] = @p1\)\s+ORDER BY s.Id ASC`).
+			WithArgs("ajis", 10).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "ajistestmail@gmail.com"))
+
+		res, err := repo.FindSubscribers(segments.Condition{Where: "(s.[Name] = @p1)", Args: []interface{}{"ajis"}}, 10)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("SELECT").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindSubscribers(segments.Condition{Where: "(s.[Name] = @p1)", Args: []interface{}{"ajis"}}, 10)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepository_FindAllSubscribers(t *testing.T) {
+	t.Run("should return every confirmed subscriber matching condition", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`SELECT s.\[id\](.|\s)+FROM TB_TRN_Subscribers s\s+WHERE s.Delflag = 0\s+AND s.IsSubscribed = 1\s+AND \(s.\[Name\] = @p1\)\s+ORDER BY s.Id ASC`).
+			WithArgs("ajis").
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "ajistestmail@gmail.com"))
+
+		res, err := repo.FindAllSubscribers(segments.Condition{Where: "(s.[Name] = @p1)", Args: []interface{}{"ajis"}})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery("SELECT").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindAllSubscribers(segments.Condition{Where: "(s.[Name] = @p1)", Args: []interface{}{"ajis"}})
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,239 @@
+package segments
+
+import (
+	"encoding/json"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/validation"
+	"strings"
+)
+
+type UseCase interface {
+	GetAllSegments() ([]entity.Segments, *newsletterError.ErrorCode)
+	FindByID(id int64) (*entity.Segments, *newsletterError.ErrorCode)
+	Create(segment entity.Segments) (*entity.Segments, *newsletterError.ErrorCode)
+	Update(segment entity.Segments) *newsletterError.ErrorCode
+	Delete(id int64) *newsletterError.ErrorCode
+	ValidateRules(rule entity.SegmentRule) ([]newsletterError.FieldError, *newsletterError.ErrorCode)
+	Preview(rule entity.SegmentRule) (*Preview, *newsletterError.ErrorCode)
+	GetSubscribers(id int64) ([]entity.Subscribers, *newsletterError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo     Repository
+	Fields   customfields.UseCase
+	Compiler *Compiler
+	Logs     logger.Logger
+}
+
+func NewService(repo Repository, fieldsService customfields.UseCase, compiler *Compiler, logs logger.Logger) *Service {
+	service := &Service{
+		Repo:     repo,
+		Fields:   fieldsService,
+		Compiler: compiler,
+		Logs:     logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetAllSegments() ([]entity.Segments, *newsletterError.ErrorCode) {
+	res, err := service.Repo.GetAllSegments()
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	for i := range res {
+		errRules := readRules(&res[i])
+		if errRules != nil {
+			return nil, errRules
+		}
+	}
+
+	return res, nil
+}
+
+func (service *Service) FindByID(id int64) (*entity.Segments, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	errRules := readRules(&res[0])
+	if errRules != nil {
+		return nil, errRules
+	}
+
+	return &res[0], nil
+}
+
+// Create stores a segment whose rules went through ValidateRules. Rules that
+// no longer compile are rejected with BadRequest.
+func (service *Service) Create(segment entity.Segments) (*entity.Segments, *newsletterError.ErrorCode) {
+	segment, errValidate := service.validateSegment(segment)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	id, err := service.Repo.Insert(segment)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.UseCase.FindByID(id)
+}
+
+func (service *Service) Update(segment entity.Segments) *newsletterError.ErrorCode {
+	_, err := service.UseCase.FindByID(segment.ID)
+	if err != nil {
+		return err
+	}
+
+	segment, errValidate := service.validateSegment(segment)
+	if errValidate != nil {
+		return errValidate
+	}
+
+	errUpdate := service.Repo.UpdateByID(segment)
+	if errUpdate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) Delete(id int64) *newsletterError.ErrorCode {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return err
+	}
+
+	errDelete := service.Repo.DeleteByID(id)
+	if errDelete != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+// ValidateRules compiles rule against the custom fields currently defined
+// and returns what is wrong with it, if anything.
+func (service *Service) ValidateRules(rule entity.SegmentRule) ([]newsletterError.FieldError, *newsletterError.ErrorCode) {
+	_, fieldErrors, err := service.compile(rule)
+	return fieldErrors, err
+}
+
+// Preview counts the subscribers rule matches now and returns the first
+// SampleSize of them.
+func (service *Service) Preview(rule entity.SegmentRule) (*Preview, *newsletterError.ErrorCode) {
+	condition, fieldErrors, err := service.compile(rule)
+	if err != nil {
+		return nil, err
+	}
+	if len(fieldErrors) > 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	count, errCount := service.Repo.CountSubscribers(*condition)
+	if errCount != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	sample, errSample := service.Repo.FindSubscribers(*condition, SampleSize)
+	if errSample != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return &Preview{Count: count, Sample: sample}, nil
+}
+
+// GetSubscribers returns every subscriber the segment matches now. A segment
+// whose rules no longer compile is a bad request rather than a send to the
+// wrong people.
+func (service *Service) GetSubscribers(id int64) ([]entity.Subscribers, *newsletterError.ErrorCode) {
+	resSegment, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	condition, fieldErrors, errCompile := service.compile(resSegment.Rules)
+	if errCompile != nil {
+		return nil, errCompile
+	}
+	if len(fieldErrors) > 0 {
+		go service.Logs.Error("", "segments_Service_GetSubscribers", id, fieldErrors)
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	res, errFind := service.Repo.FindAllSubscribers(*condition)
+	if errFind != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) compile(rule entity.SegmentRule) (*Condition, []newsletterError.FieldError, *newsletterError.ErrorCode) {
+	definitions, err := service.Fields.GetAllFields()
+	if err != nil && *err != newsletterError.DataNotFound {
+		return nil, nil, err
+	}
+
+	condition, fieldErrors := service.Compiler.Compile(rule, definitions)
+	return condition, fieldErrors, nil
+}
+
+func (service *Service) validateSegment(segment entity.Segments) (entity.Segments, *newsletterError.ErrorCode) {
+	name, errName := validation.RequiredText(segment.Name, validation.MaxLength)
+	if errName != nil {
+		return segment, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	segment.Name = name
+
+	segment.Description = strings.TrimSpace(segment.Description)
+	if len([]rune(segment.Description)) > MaxDescriptionLength {
+		return segment, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	_, fieldErrors, err := service.compile(segment.Rules)
+	if err != nil {
+		return segment, err
+	}
+	if len(fieldErrors) > 0 {
+		return segment, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	rules, errRules := json.Marshal(segment.Rules)
+	if errRules != nil {
+		return segment, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+	segment.RulesJSON = string(rules)
+
+	return segment, nil
+}
+
+func readRules(segment *entity.Segments) *newsletterError.ErrorCode {
+	err := json.Unmarshal([]byte(segment.RulesJSON), &segment.Rules)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+	return nil
+}
@@ -0,0 +1,429 @@
+package segments_test
+
+import (
+	"errors"
+	customfieldsMocks "newsletter/src/pkg/customfields/mocks"
+	"newsletter/src/pkg/entity"
+	"newsletter/src/pkg/segments"
+	"newsletter/src/pkg/segments/mocks"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase   *mocks.UseCase
+	repository    *mocks.Repository
+	fieldsService *customfieldsMocks.UseCase
+	service       *segments.Service
+	logs          *loggerMocks.Logger
+
+	mockRepoGetAllSegments     *mocker.MockCall
+	mockRepoFindByID           *mocker.MockCall
+	mockRepoInsert             *mocker.MockCall
+	mockRepoUpdateByID         *mocker.MockCall
+	mockRepoDeleteByID         *mocker.MockCall
+	mockRepoCountSubscribers   *mocker.MockCall
+	mockRepoFindSubscribers    *mocker.MockCall
+	mockRepoFindAllSubscribers *mocker.MockCall
+	mockServiceFindByID        *mocker.MockCall
+	mockFieldsGetAllFields     *mocker.MockCall
+)
+
+var (
+	countryRule = entity.SegmentRule{Field: "fields.country", Op: entity.SegmentOpEq, Value: "TH"}
+	countryJSON = `{"field":"fields.country","op":"eq","value":"TH"}`
+)
+
+func callRepoGetAllSegments() *mock.Call {
+	return repository.On("GetAllSegments")
+}
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoUpdateByID() *mock.Call {
+	return repository.On("UpdateByID", mock.Anything)
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callRepoCountSubscribers() *mock.Call {
+	return repository.On("CountSubscribers", mock.Anything)
+}
+
+func callRepoFindSubscribers() *mock.Call {
+	return repository.On("FindSubscribers", mock.Anything, mock.Anything)
+}
+
+func callRepoFindAllSubscribers() *mock.Call {
+	return repository.On("FindAllSubscribers", mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func callFieldsGetAllFields() *mock.Call {
+	return fieldsService.On("GetAllFields")
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	fieldsService = &customfieldsMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &segments.Service{
+		Repo:     repository,
+		Fields:   fieldsService,
+		Compiler: compiler,
+		Logs:     logs,
+	}
+	service.UseCase = mockUseCase
+
+	mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+	mockServiceFindByID.Return(&entity.Segments{ID: 3, Name: "thai readers"}, nil)
+	mockFieldsGetAllFields = mocker.NewMockCall(callFieldsGetAllFields)
+	mockFieldsGetAllFields.Return(definitions, nil)
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct segments service when call new service", func(t *testing.T) {
+		beforeEach()
+		resService := segments.NewService(repository, fieldsService, compiler, logs)
+
+		expectedService := &segments.Service{
+			Repo:     repository,
+			Fields:   fieldsService,
+			Compiler: compiler,
+			Logs:     logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_GetAllSegments(t *testing.T) {
+	beforeEachGetAllSegments := func() {
+		beforeEach()
+		mockRepoGetAllSegments = mocker.NewMockCall(callRepoGetAllSegments)
+		mockRepoGetAllSegments.Return([]entity.Segments{{ID: 3, RulesJSON: countryJSON}}, nil)
+	}
+
+	t.Run("should return segments with rules when found", func(t *testing.T) {
+		beforeEachGetAllSegments()
+
+		res, err := service.GetAllSegments()
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Segments{{ID: 3, Rules: countryRule, RulesJSON: countryJSON}}, res)
+	})
+
+	t.Run("should return data not found when there is no segment", func(t *testing.T) {
+		beforeEachGetAllSegments()
+		mockRepoGetAllSegments.Return([]entity.Segments{}, nil)
+
+		res, err := service.GetAllSegments()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when stored rules are broken", func(t *testing.T) {
+		beforeEachGetAllSegments()
+		mockRepoGetAllSegments.Return([]entity.Segments{{ID: 3, RulesJSON: "{"}}, nil)
+
+		res, err := service.GetAllSegments()
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.Segments{{ID: 3, RulesJSON: countryJSON}}, nil)
+	}
+
+	t.Run("should return first segment with rules when found", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Segments{ID: 3, Rules: countryRule, RulesJSON: countryJSON}, res)
+		repository.AssertCalled(t, "FindByID", int64(3))
+	})
+
+	t.Run("should return data not found when segment is missing or deleted", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.Segments{}, nil)
+
+		res, err := service.FindByID(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Create(t *testing.T) {
+	beforeEachCreate := func() {
+		beforeEach()
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(3), nil)
+	}
+
+	t.Run("should insert trimmed segment with stored rules and return it", func(t *testing.T) {
+		beforeEachCreate()
+
+		res, err := service.Create(entity.Segments{Name: " thai readers ", Description: " by country ", Rules: countryRule})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Segments{ID: 3, Name: "thai readers"}, res)
+		repository.AssertCalled(t, "Insert", entity.Segments{Name: "thai readers", Description: "by country", Rules: countryRule, RulesJSON: countryJSON})
+		mockUseCase.AssertCalled(t, "FindByID", int64(3))
+	})
+
+	t.Run("should return bad request when name is empty", func(t *testing.T) {
+		beforeEachCreate()
+
+		_, err := service.Create(entity.Segments{Name: "  ", Rules: countryRule})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return bad request when rules do not compile", func(t *testing.T) {
+		beforeEachCreate()
+
+		_, err := service.Create(entity.Segments{Name: "thai readers", Rules: entity.SegmentRule{Field: "fields.team", Op: entity.SegmentOpIsSet}})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return internal server error when insert failed", func(t *testing.T) {
+		beforeEachCreate()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		res, err := service.Create(entity.Segments{Name: "thai readers", Rules: countryRule})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_Update(t *testing.T) {
+	beforeEachUpdate := func() {
+		beforeEach()
+		mockRepoUpdateByID = mocker.NewMockCall(callRepoUpdateByID)
+		mockRepoUpdateByID.Return(nil)
+	}
+
+	t.Run("should update existing segment", func(t *testing.T) {
+		beforeEachUpdate()
+
+		err := service.Update(entity.Segments{ID: 3, Name: "thai readers", Rules: countryRule})
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpdateByID", entity.Segments{ID: 3, Name: "thai readers", Rules: countryRule, RulesJSON: countryJSON})
+	})
+
+	t.Run("should return data not found when segment is missing", func(t *testing.T) {
+		beforeEachUpdate()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Update(entity.Segments{ID: 3, Name: "thai readers", Rules: countryRule})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "UpdateByID", mock.Anything)
+	})
+}
+
+func TestService_Delete(t *testing.T) {
+	beforeEachDelete := func() {
+		beforeEach()
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(nil)
+	}
+
+	t.Run("should delete existing segment", func(t *testing.T) {
+		beforeEachDelete()
+
+		err := service.Delete(3)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "DeleteByID", int64(3))
+	})
+
+	t.Run("should return internal server error when delete failed", func(t *testing.T) {
+		beforeEachDelete()
+		mockRepoDeleteByID.Return(errors.New("Error"))
+
+		err := service.Delete(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_ValidateRules(t *testing.T) {
+	t.Run("should return no field errors when rules compile", func(t *testing.T) {
+		beforeEach()
+
+		fieldErrors, err := service.ValidateRules(countryRule)
+
+		assert.Nil(t, err)
+		assert.Nil(t, fieldErrors)
+	})
+
+	t.Run("should compile built-in fields when no custom field is defined", func(t *testing.T) {
+		beforeEach()
+		mockFieldsGetAllFields.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		fieldErrors, err := service.ValidateRules(entity.SegmentRule{Field: "email", Op: entity.SegmentOpIsSet})
+
+		assert.Nil(t, err)
+		assert.Nil(t, fieldErrors)
+	})
+
+	t.Run("should return field errors when rules reference unknown field", func(t *testing.T) {
+		beforeEach()
+		mockFieldsGetAllFields.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		fieldErrors, err := service.ValidateRules(countryRule)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []newsletterError.FieldError{{Field: "rules.field", Code: newsletterError.FieldUnknown}}, fieldErrors)
+	})
+
+	t.Run("should return error when custom fields failed", func(t *testing.T) {
+		beforeEach()
+		mockFieldsGetAllFields.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		_, err := service.ValidateRules(countryRule)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_Preview(t *testing.T) {
+	beforeEachPreview := func() {
+		beforeEach()
+		mockRepoCountSubscribers = mocker.NewMockCall(callRepoCountSubscribers)
+		mockRepoCountSubscribers.Return(int64(42), nil)
+		mockRepoFindSubscribers = mocker.NewMockCall(callRepoFindSubscribers)
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 7}}, nil)
+	}
+
+	t.Run("should return count and sample of matching subscribers", func(t *testing.T) {
+		beforeEachPreview()
+
+		res, err := service.Preview(countryRule)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &segments.Preview{Count: 42, Sample: []entity.Subscribers{{ID: 7}}}, res)
+		condition, _ := compiler.Compile(countryRule, definitions)
+		repository.AssertCalled(t, "CountSubscribers", *condition)
+		repository.AssertCalled(t, "FindSubscribers", *condition, segments.SampleSize)
+	})
+
+	t.Run("should return bad request when rules do not compile", func(t *testing.T) {
+		beforeEachPreview()
+
+		_, err := service.Preview(entity.SegmentRule{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		repository.AssertNotCalled(t, "CountSubscribers", mock.Anything)
+	})
+
+	t.Run("should return internal server error when count failed", func(t *testing.T) {
+		beforeEachPreview()
+		mockRepoCountSubscribers.Return(int64(0), errors.New("Error"))
+
+		res, err := service.Preview(countryRule)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_GetSubscribers(t *testing.T) {
+	beforeEachGetSubscribers := func() {
+		beforeEach()
+		mockServiceFindByID.Return(&entity.Segments{ID: 3, Rules: countryRule}, nil)
+		mockRepoFindAllSubscribers = mocker.NewMockCall(callRepoFindAllSubscribers)
+		mockRepoFindAllSubscribers.Return([]entity.Subscribers{{ID: 7}}, nil)
+	}
+
+	t.Run("should return every subscriber matching rules of segment", func(t *testing.T) {
+		beforeEachGetSubscribers()
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 7}}, res)
+		mockUseCase.AssertCalled(t, "FindByID", int64(3))
+		condition, _ := compiler.Compile(countryRule, definitions)
+		repository.AssertCalled(t, "FindAllSubscribers", *condition)
+	})
+
+	t.Run("should return data not found when segment is missing", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "FindAllSubscribers", mock.Anything)
+	})
+
+	t.Run("should return bad request when rules no longer compile", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockServiceFindByID.Return(&entity.Segments{ID: 3, Rules: entity.SegmentRule{Field: "fields.removed", Op: entity.SegmentOpEq, Value: "TH"}}, nil)
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "FindAllSubscribers", mock.Anything)
+	})
+
+	t.Run("should return data not found when no subscriber matches", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindAllSubscribers.Return([]entity.Subscribers{}, nil)
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when find subscribers failed", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindAllSubscribers.Return(nil, errors.New("Error"))
+
+		res, err := service.GetSubscribers(3)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,45 @@
+package subscribers
+
+import (
+	"newsletter/src/pkg/utils/logger"
+	"time"
+)
+
+// Cleanup clears expired pending subscribers every Interval, so the public
+// subscribe endpoint never has to run the table-wide delete itself.
+type Cleanup struct {
+	Service  UseCase
+	Interval time.Duration
+	Logs     logger.Logger
+}
+
+func NewCleanup(service UseCase, interval time.Duration, logs logger.Logger) *Cleanup {
+	return &Cleanup{
+		Service:  service,
+		Interval: interval,
+		Logs:     logs,
+	}
+}
+
+// Run clears expired pending subscribers straight away and then every
+// Interval. It returns when stop is closed.
+func (cleanup *Cleanup) Run(stop <-chan struct{}) {
+	for {
+		select {
+		case <-stop:
+			return
+		default:
+		}
+
+		err := cleanup.Service.DeleteExpiredPending()
+		if err != nil {
+			go cleanup.Logs.Error("", "subscribers_Cleanup_Run_DeleteExpiredPending", nil, err)
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(cleanup.Interval):
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package subscribers_test
+
+import (
+	subscribers "newsletter/src/pkg/subscribers"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/mocker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCleanup_NewCleanup(t *testing.T) {
+	t.Run("should return struct cleanup when call new cleanup", func(t *testing.T) {
+		beforeEach()
+
+		resCleanup := subscribers.NewCleanup(mockUseCase, time.Hour, logs)
+
+		assert.Equal(t, &subscribers.Cleanup{Service: mockUseCase, Interval: time.Hour, Logs: logs}, resCleanup)
+	})
+}
+
+func TestCleanup_Run(t *testing.T) {
+	beforeEachRun := func() {
+		beforeEach()
+
+		mockServiceDeleteExpiredPending = mocker.NewMockCall(callServiceDeleteExpiredPending)
+		mockServiceDeleteExpiredPending.Return(nil)
+	}
+
+	notify := func(ran chan struct{}) func(mock.Arguments) {
+		return func(mock.Arguments) {
+			select {
+			case ran <- struct{}{}:
+			default:
+			}
+		}
+	}
+
+	run := func(t *testing.T, cleanup *subscribers.Cleanup, ran chan struct{}, times int) {
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			cleanup.Run(stop)
+			close(done)
+		}()
+
+		for i := 0; i < times; i++ {
+			select {
+			case <-ran:
+			case <-time.After(time.Second):
+				t.Fatal("cleanup did not run")
+			}
+		}
+		close(stop)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("cleanup did not stop")
+		}
+	}
+
+	t.Run("should delete expired pending at start and every interval until stopped", func(t *testing.T) {
+		beforeEachRun()
+		ran := make(chan struct{})
+		mockServiceDeleteExpiredPending.Return(nil).Run(notify(ran))
+
+		run(t, subscribers.NewCleanup(mockUseCase, time.Millisecond, logs), ran, 2)
+
+		assert.GreaterOrEqual(t, len(mockUseCase.Calls), 2)
+	})
+
+	t.Run("should keep running when delete expired pending failed", func(t *testing.T) {
+		beforeEachRun()
+		ran := make(chan struct{})
+		mockServiceDeleteExpiredPending.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError)).Run(notify(ran))
+
+		run(t, subscribers.NewCleanup(mockUseCase, time.Millisecond, logs), ran, 2)
+
+		assert.GreaterOrEqual(t, len(mockUseCase.Calls), 2)
+	})
+
+	t.Run("should return without deleting when already stopped", func(t *testing.T) {
+		beforeEachRun()
+		stop := make(chan struct{})
+		close(stop)
+
+		subscribers.NewCleanup(mockUseCase, time.Hour, logs).Run(stop)
+
+		mockUseCase.AssertNotCalled(t, "DeleteExpiredPending")
+	})
+}
@@ -1,4 +1,4 @@
-// Code generated by mockery v2.32.4. DO NOT EDIT.
+// Code generated by mockery v2.53.7. DO NOT EDIT.
 
 package mocks
 
@@ -6,6 +6,8 @@ import (
 	entity "newsletter/src/pkg/entity"
 
 	mock "github.com/stretchr/testify/mock"
+
+	subscribers "newsletter/src/pkg/subscribers"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -13,10 +15,136 @@ type Repository struct {
 	mock.Mock
 }
 
+// ConfirmByEmail provides a mock function with given fields: email
+func (_m *Repository) ConfirmByEmail(email string) error {
+	ret := _m.Called(email)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConfirmByEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CountSubscribers provides a mock function with given fields: filter
+func (_m *Repository) CountSubscribers(filter subscribers.Filter) (int64, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CountSubscribers")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) (int64, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) int64); ok {
+		r0 = rf(filter)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Filter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *Repository) DeleteByID(id int64) (int64, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteByID")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) (int64, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) int64); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExpiredPending provides a mock function with no fields
+func (_m *Repository) DeleteExpiredPending() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteExpiredPending")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// FindByConfirmToken provides a mock function with given fields: confirmToken
+func (_m *Repository) FindByConfirmToken(confirmToken string) ([]entity.Subscribers, error) {
+	ret := _m.Called(confirmToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindByConfirmToken")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]entity.Subscribers, error)); ok {
+		return rf(confirmToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) []entity.Subscribers); ok {
+		r0 = rf(confirmToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(confirmToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // FindByEmail provides a mock function with given fields: email
 func (_m *Repository) FindByEmail(email string) ([]entity.Subscribers, error) {
 	ret := _m.Called(email)
 
+	if len(ret) == 0 {
+		panic("no return value specified for FindByEmail")
+	}
+
 	var r0 []entity.Subscribers
 	var r1 error
 	if rf, ok := ret.Get(0).(func(string) ([]entity.Subscribers, error)); ok {
@@ -39,10 +167,74 @@ func (_m *Repository) FindByEmail(email string) ([]entity.Subscribers, error) {
 	return r0, r1
 }
 
-// GetAllSubscribers provides a mock function with given fields:
+// FindListPreferences provides a mock function with given fields: subscriberID
+func (_m *Repository) FindListPreferences(subscriberID int64) ([]subscribers.ListPreference, error) {
+	ret := _m.Called(subscriberID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindListPreferences")
+	}
+
+	var r0 []subscribers.ListPreference
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]subscribers.ListPreference, error)); ok {
+		return rf(subscriberID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []subscribers.ListPreference); ok {
+		r0 = rf(subscriberID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]subscribers.ListPreference)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(subscriberID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindSubscribers provides a mock function with given fields: filter
+func (_m *Repository) FindSubscribers(filter subscribers.Filter) ([]entity.Subscribers, error) {
+	ret := _m.Called(filter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindSubscribers")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) ([]entity.Subscribers, error)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) []entity.Subscribers); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Filter) error); ok {
+		r1 = rf(filter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAllSubscribers provides a mock function with no fields
 func (_m *Repository) GetAllSubscribers() ([]entity.Subscribers, error) {
 	ret := _m.Called()
 
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllSubscribers")
+	}
+
 	var r0 []entity.Subscribers
 	var r1 error
 	if rf, ok := ret.Get(0).(func() ([]entity.Subscribers, error)); ok {
@@ -69,6 +261,10 @@ func (_m *Repository) GetAllSubscribers() ([]entity.Subscribers, error) {
 func (_m *Repository) Insert(subscriber entity.Subscribers) error {
 	ret := _m.Called(subscriber)
 
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
 	var r0 error
 	if rf, ok := ret.Get(0).(func(entity.Subscribers) error); ok {
 		r0 = rf(subscriber)
@@ -83,6 +279,64 @@ func (_m *Repository) Insert(subscriber entity.Subscribers) error {
 func (_m *Repository) UpdateByEmail(subscriber entity.Subscribers) error {
 	ret := _m.Called(subscriber)
 
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateByEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) error); ok {
+		r0 = rf(subscriber)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateListMemberships provides a mock function with given fields: subscriberID, listIDs
+func (_m *Repository) UpdateListMemberships(subscriberID int64, listIDs []int64) error {
+	ret := _m.Called(subscriberID, listIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateListMemberships")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, []int64) error); ok {
+		r0 = rf(subscriberID, listIDs)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePendingByEmail provides a mock function with given fields: subscriber
+func (_m *Repository) UpdatePendingByEmail(subscriber entity.Subscribers) error {
+	ret := _m.Called(subscriber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePendingByEmail")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) error); ok {
+		r0 = rf(subscriber)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdatePreferencesByEmail provides a mock function with given fields: subscriber
+func (_m *Repository) UpdatePreferencesByEmail(subscriber entity.Subscribers) error {
+	ret := _m.Called(subscriber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdatePreferencesByEmail")
+	}
+
 	var r0 error
 	if rf, ok := ret.Get(0).(func(entity.Subscribers) error); ok {
 		r0 = rf(subscriber)
@@ -7,6 +7,8 @@ import (
 	error "newsletter/src/pkg/utils/error"
 
 	mock "github.com/stretchr/testify/mock"
+
+	subscribers "newsletter/src/pkg/subscribers"
 )
 
 // UseCase is an autogenerated mock type for the UseCase type
@@ -14,6 +16,126 @@ type UseCase struct {
 	mock.Mock
 }
 
+// Confirm provides a mock function with given fields: confirmToken
+func (_m *UseCase) Confirm(confirmToken string) *error.ErrorCode {
+	ret := _m.Called(confirmToken)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) *error.ErrorCode); ok {
+		r0 = rf(confirmToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// ConfirmByEmail provides a mock function with given fields: email
+func (_m *UseCase) ConfirmByEmail(email string) *error.ErrorCode {
+	ret := _m.Called(email)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) *error.ErrorCode); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// DeleteByID provides a mock function with given fields: id
+func (_m *UseCase) DeleteByID(id int64) *error.ErrorCode {
+	ret := _m.Called(id)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) *error.ErrorCode); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// DeleteExpiredPending provides a mock function with given fields:
+func (_m *UseCase) DeleteExpiredPending() *error.ErrorCode {
+	ret := _m.Called()
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() *error.ErrorCode); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// ExportSubscribers provides a mock function with given fields: filter
+func (_m *UseCase) ExportSubscribers(filter subscribers.Filter) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(filter)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) []entity.Subscribers); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Filter) *error.ErrorCode); ok {
+		r1 = rf(filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// FindByConfirmToken provides a mock function with given fields: confirmToken
+func (_m *UseCase) FindByConfirmToken(confirmToken string) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(confirmToken)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(confirmToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) []entity.Subscribers); ok {
+		r0 = rf(confirmToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(confirmToken)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
 // FindByEmail provides a mock function with given fields: email
 func (_m *UseCase) FindByEmail(email string) ([]entity.Subscribers, *error.ErrorCode) {
 	ret := _m.Called(email)
@@ -42,6 +164,34 @@ func (_m *UseCase) FindByEmail(email string) ([]entity.Subscribers, *error.Error
 	return r0, r1
 }
 
+// GeneratePreferencesURL provides a mock function with given fields: email
+func (_m *UseCase) GeneratePreferencesURL(email string) string {
+	ret := _m.Called(email)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GenerateUnsubscribeURL provides a mock function with given fields: email
+func (_m *UseCase) GenerateUnsubscribeURL(email string) string {
+	ret := _m.Called(email)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(email)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // GetAllSubscribers provides a mock function with given fields:
 func (_m *UseCase) GetAllSubscribers() ([]entity.Subscribers, *error.ErrorCode) {
 	ret := _m.Called()
@@ -70,6 +220,62 @@ func (_m *UseCase) GetAllSubscribers() ([]entity.Subscribers, *error.ErrorCode)
 	return r0, r1
 }
 
+// GetPreferences provides a mock function with given fields: email
+func (_m *UseCase) GetPreferences(email string) (*subscribers.Preferences, *error.ErrorCode) {
+	ret := _m.Called(email)
+
+	var r0 *subscribers.Preferences
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (*subscribers.Preferences, *error.ErrorCode)); ok {
+		return rf(email)
+	}
+	if rf, ok := ret.Get(0).(func(string) *subscribers.Preferences); ok {
+		r0 = rf(email)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Preferences)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(email)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSubscribers provides a mock function with given fields: filter
+func (_m *UseCase) GetSubscribers(filter subscribers.Filter) (*subscribers.Page, *error.ErrorCode) {
+	ret := _m.Called(filter)
+
+	var r0 *subscribers.Page
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) (*subscribers.Page, *error.ErrorCode)); ok {
+		return rf(filter)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Filter) *subscribers.Page); ok {
+		r0 = rf(filter)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Page)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Filter) *error.ErrorCode); ok {
+		r1 = rf(filter)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
 // Insert provides a mock function with given fields: subscriber
 func (_m *UseCase) Insert(subscriber entity.Subscribers) *error.ErrorCode {
 	ret := _m.Called(subscriber)
@@ -86,6 +292,50 @@ func (_m *UseCase) Insert(subscriber entity.Subscribers) *error.ErrorCode {
 	return r0
 }
 
+// SavePending provides a mock function with given fields: subscriber
+func (_m *UseCase) SavePending(subscriber entity.Subscribers) (*entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(subscriber)
+
+	var r0 *entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) (*entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(subscriber)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) *entity.Subscribers); ok {
+		r0 = rf(subscriber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Subscribers) *error.ErrorCode); ok {
+		r1 = rf(subscriber)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// SendConfirmation provides a mock function with given fields: subscriber
+func (_m *UseCase) SendConfirmation(subscriber entity.Subscribers) *error.ErrorCode {
+	ret := _m.Called(subscriber)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) *error.ErrorCode); ok {
+		r0 = rf(subscriber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
 // Subscribe provides a mock function with given fields: subscriber
 func (_m *UseCase) Subscribe(subscriber entity.Subscribers) *error.ErrorCode {
 	ret := _m.Called(subscriber)
@@ -118,6 +368,22 @@ func (_m *UseCase) Unsubscribe(subscriber entity.Subscribers) *error.ErrorCode {
 	return r0
 }
 
+// UnsubscribeByToken provides a mock function with given fields: unsubscribeToken
+func (_m *UseCase) UnsubscribeByToken(unsubscribeToken string) *error.ErrorCode {
+	ret := _m.Called(unsubscribeToken)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) *error.ErrorCode); ok {
+		r0 = rf(unsubscribeToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
 // UpdateByEmail provides a mock function with given fields: subscriber
 func (_m *UseCase) UpdateByEmail(subscriber entity.Subscribers) *error.ErrorCode {
 	ret := _m.Called(subscriber)
@@ -134,6 +400,90 @@ func (_m *UseCase) UpdateByEmail(subscriber entity.Subscribers) *error.ErrorCode
 	return r0
 }
 
+// UpdatePendingByEmail provides a mock function with given fields: subscriber
+func (_m *UseCase) UpdatePendingByEmail(subscriber entity.Subscribers) *error.ErrorCode {
+	ret := _m.Called(subscriber)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Subscribers) *error.ErrorCode); ok {
+		r0 = rf(subscriber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// UpdatePreferences provides a mock function with given fields: preferences
+func (_m *UseCase) UpdatePreferences(preferences subscribers.Preferences) *error.ErrorCode {
+	ret := _m.Called(preferences)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Preferences) *error.ErrorCode); ok {
+		r0 = rf(preferences)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// VerifyPreferencesToken provides a mock function with given fields: preferencesToken
+func (_m *UseCase) VerifyPreferencesToken(preferencesToken string) (string, *error.ErrorCode) {
+	ret := _m.Called(preferencesToken)
+
+	var r0 string
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (string, *error.ErrorCode)); ok {
+		return rf(preferencesToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(preferencesToken)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(preferencesToken)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// VerifyUnsubscribeToken provides a mock function with given fields: unsubscribeToken
+func (_m *UseCase) VerifyUnsubscribeToken(unsubscribeToken string) (string, *error.ErrorCode) {
+	ret := _m.Called(unsubscribeToken)
+
+	var r0 string
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (string, *error.ErrorCode)); ok {
+		return rf(unsubscribeToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(unsubscribeToken)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(unsubscribeToken)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
 // NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUseCase(t interface {
@@ -0,0 +1,89 @@
+package subscribers
+
+import (
+	"newsletter/src/pkg/entity"
+	"time"
+)
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+
+	DefaultSortBy = "id"
+
+	// ExportBatchSize is how many subscribers an export reads per query.
+	ExportBatchSize = 1000
+
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+
+	FrequencyImmediate = "immediate"
+	FrequencyWeekly    = "weekly"
+	FrequencyMonthly   = "monthly"
+)
+
+// Frequencies lists how often a subscriber can choose to hear from us, in the
+// order the preference page offers them.
+var Frequencies = []string{FrequencyImmediate, FrequencyWeekly, FrequencyMonthly}
+
+type SortOrder string
+
+// SortColumns maps the json name a client sorts by to its column.
+var SortColumns = map[string]string{
+	"id":               "Id",
+	"email":            "Email",
+	"name":             "Name",
+	"isSubscribed":     "IsSubscribed",
+	"subscribedDate":   "SubscribedDate",
+	"unsubscribedDate": "UnsubscribedDate",
+	"isPending":        "IsPending",
+	"confirmedDate":    "ConfirmedDate",
+}
+
+// Filter narrows and orders the subscribers listed by GetSubscribers. Paging
+// is either by Page or, when sorting by id, by the After cursor.
+type Filter struct {
+	Page           int
+	PageSize       int
+	After          *int64
+	IsSubscribed   *bool
+	Email          string
+	Name           string
+	SubscribedFrom *time.Time
+	SubscribedTo   *time.Time
+	SortBy         string
+	SortOrder      SortOrder
+}
+
+type Page struct {
+	Items      []entity.Subscribers `json:"items"`
+	Total      int64                `json:"total"`
+	Page       int                  `json:"page"`
+	PageSize   int                  `json:"pageSize"`
+	NextCursor *int64               `json:"nextCursor"`
+}
+
+// Preferences is what a subscriber manages on the preference page. Lists
+// holds every list on offer, with IsSubscribed set on the ones they are on.
+type Preferences struct {
+	SubscriberID int64            `json:"-"`
+	Email        string           `json:"email"`
+	Name         string           `json:"name"`
+	Language     string           `json:"language"`
+	Frequency    string           `json:"frequency"`
+	TimeZone     string           `json:"timeZone"`
+	Lists        []ListPreference `json:"lists"`
+}
+
+type ListPreference struct {
+	ID           int64  `json:"id" sql:"id"`
+	Name         string `json:"name" sql:"name"`
+	IsSubscribed bool   `json:"isSubscribed" sql:"isSubscribed"`
+}
+
+// PreferenceChange records one setting a subscriber changed, for the log.
+type PreferenceChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
@@ -9,28 +9,43 @@ import (
 	newsletterError "newsletter/src/pkg/utils/error"
 	"newsletter/src/pkg/utils/logger"
 	sqlQuery "newsletter/src/pkg/utils/sqlquery"
+	"strings"
 
 	sqlStruct "github.com/kisielk/sqlstruct"
 )
 
 type Repository interface {
 	GetAllSubscribers() ([]entity.Subscribers, error)
+	FindSubscribers(filter Filter) ([]entity.Subscribers, error)
+	CountSubscribers(filter Filter) (int64, error)
 	FindByEmail(email string) ([]entity.Subscribers, error)
 	Insert(subscriber entity.Subscribers) error
 	UpdateByEmail(subscriber entity.Subscribers) error
+	FindByConfirmToken(confirmToken string) ([]entity.Subscribers, error)
+	UpdatePendingByEmail(subscriber entity.Subscribers) error
+	ConfirmByEmail(email string) error
+	DeleteExpiredPending() error
+	DeleteByID(id int64) (int64, error)
+	UpdatePreferencesByEmail(subscriber entity.Subscribers) error
+	FindListPreferences(subscriberID int64) ([]ListPreference, error)
+	UpdateListMemberships(subscriberID int64, listIDs []int64) error
 }
 
 type SqlRepository struct {
-	Collection string
-	Session    *sql.DB
-	Logs       logger.Logger
+	Collection            string
+	ListsCollection       string
+	ListMembersCollection string
+	Session               *sql.DB
+	Logs                  logger.Logger
 }
 
-func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+func NewRepository(collection, listsCollection, listMembersCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
 	return &SqlRepository{
-		Collection: collection,
-		Session:    session,
-		Logs:       logs,
+		Collection:            collection,
+		ListsCollection:       listsCollection,
+		ListMembersCollection: listMembersCollection,
+		Session:               session,
+		Logs:                  logs,
 	}
 }
 
@@ -56,6 +71,7 @@ func (repo *SqlRepository) GetAllSubscribers() ([]entity.Subscribers, error) {
 		go repo.Logs.Error("", "subscribers_Repo_GetAllSubscribers", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
 		return nil, err
 	}
+	defer rows.Close()
 
 	list := []entity.Subscribers{}
 	for rows.Next() {
@@ -68,6 +84,88 @@ func (repo *SqlRepository) GetAllSubscribers() ([]entity.Subscribers, error) {
 	return list, nil
 }
 
+func (repo *SqlRepository) FindSubscribers(filter Filter) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	where, args := filterConditions(filter, true)
+
+	offset := 0
+	if filter.After == nil {
+		offset = (filter.Page - 1) * filter.PageSize
+	}
+
+	orderBy := fmt.Sprintf("[%s] %s", SortColumns[filter.SortBy], strings.ToUpper(string(filter.SortOrder)))
+	if filter.SortBy != DefaultSortBy {
+		orderBy += ", [Id] ASC"
+	}
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE %[3]s
+	ORDER BY %[4]s
+	OFFSET %[5]s ROWS FETCH NEXT %[6]s ROWS ONLY
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Subscribers{}, []string{}),
+		repo.Collection,
+		where,
+		orderBy,
+		sqlQuery.Param(len(args)+1),
+		sqlQuery.Param(len(args)+2),
+	)
+	rows, err := session.QueryContext(ctx, sql, append(args, offset, filter.PageSize)...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_FindSubscribers", filter, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// CountSubscribers counts every subscriber matching the filter, ignoring the
+// page and cursor, so clients can show the total next to one page.
+func (repo *SqlRepository) CountSubscribers(filter Filter) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	where, args := filterConditions(filter, false)
+
+	sql := fmt.Sprintf(`
+	SELECT COUNT(1)
+	FROM %[1]s
+	WHERE %[2]s
+	`,
+		repo.Collection,
+		where,
+	)
+
+	var total int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&total)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_CountSubscribers", filter, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+	return total, nil
+}
+
 func (repo *SqlRepository) FindByEmail(email string) ([]entity.Subscribers, error) {
 	ctx := context.Background()
 	session, sessionErr := repo.Session.Conn(ctx)
@@ -80,17 +178,18 @@ func (repo *SqlRepository) FindByEmail(email string) ([]entity.Subscribers, erro
 	SELECT %[1]s 
 	FROM %[2]s
 	WHERE Delflag = 0
-	AND Email = N'%[3]s'
+	AND Email = %[3]s
 	`,
 		sqlQuery.GenerateQueryColumnNames(entity.Subscribers{}, []string{}),
 		repo.Collection,
-		email,
+		sqlQuery.Param(1),
 	)
-	rows, err := session.QueryContext(ctx, sql)
+	rows, err := session.QueryContext(ctx, sql, email)
 	if err != nil {
 		go repo.Logs.Error("", "subscribers_Repo_FindByEmail", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
 		return nil, err
 	}
+	defer rows.Close()
 
 	list := []entity.Subscribers{}
 	for rows.Next() {
@@ -111,11 +210,13 @@ func (repo *SqlRepository) Insert(subscriber entity.Subscribers) error {
 	}
 	defer session.Close()
 
+	ignoreFields := []string{"ID", "SubscribedDate", "UnsubscribedDate", "DelFlag", "ConfirmedDate", "Language", "Frequency"}
+	params, args := sqlQuery.GenerateQueryColumnParams(subscriber, ignoreFields, 0)
+
 	sql := fmt.Sprintf(`
 	INSERT INTO %[1]s
 	(
 		%[2]s,
-		[IsSubscribed],
 		[SubscribedDate],
 		[UnsubscribedDate],
 		[Delflag]
@@ -123,18 +224,17 @@ func (repo *SqlRepository) Insert(subscriber entity.Subscribers) error {
 	VALUES
 	(
 		%[3]s,
-		1,
 		GETDATE(),
 		Null,
 		0
 	)
 	`,
 		repo.Collection,
-		sqlQuery.GenerateQueryColumnNames(entity.Subscribers{}, []string{"ID", "IsSubscribed", "SubscribedDate", "UnsubscribedDate", "DelFlag"}),
-		sqlQuery.GenerateQueryColumnValues(subscriber, []string{"ID", "IsSubscribed", "SubscribedDate", "UnsubscribedDate", "DelFlag"}),
+		sqlQuery.GenerateQueryColumnNames(entity.Subscribers{}, ignoreFields),
+		params,
 	)
 
-	_, err := session.ExecContext(ctx, sql)
+	_, err := session.ExecContext(ctx, sql, args...)
 	if err != nil {
 		go repo.Logs.Error("", "subscriber_Repo_Insert", subscriber,
 			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
@@ -161,21 +261,361 @@ func (repo *SqlRepository) UpdateByEmail(subscriber entity.Subscribers) error {
 		UnsubscribedDate = GETDATE()`
 	}
 
+	fields, args := sqlQuery.GenerateQueryUpdateFieldParams(subscriber, []string{"ID", "SubscribedDate", "UnsubscribedDate", "IsSubscribed", "DelFlag", "IsPending", "ConfirmToken", "ConfirmExpiredDate", "ConfirmedDate", "SourceAPIKeyID", "Language", "Frequency", "TimeZone"}, 1)
+
 	sql := fmt.Sprintf(`
 	UPDATE %[1]s
 	SET 
 		%[2]s,
 		%[4]s
-	WHERE Email = N'%[3]s'
+	WHERE Email = %[3]s
 	`,
 		repo.Collection,
-		sqlQuery.GenerateQueryUpdateFields(subscriber, []string{"ID", "SubscribedDate", "UnsubscribedDate", "IsSubscribed", "DelFlag"}),
-		subscriber.Email,
+		fields,
+		sqlQuery.Param(1),
 		setDate,
 	)
 
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{subscriber.Email}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_UpdateByEmail", subscriber,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) FindByConfirmToken(confirmToken string) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s 
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND IsPending = 1
+	AND ConfirmToken = %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Subscribers{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, confirmToken)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_FindByConfirmToken", "", newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) UpdatePendingByEmail(subscriber entity.Subscribers) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(subscriber, []string{"IsPending", "ConfirmToken", "ConfirmExpiredDate"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET 
+		%[2]s
+	WHERE Email = %[3]s
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{subscriber.Email}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_UpdatePendingByEmail", subscriber,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) ConfirmByEmail(email string) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET 
+		IsSubscribed = 1,
+		IsPending = 0,
+		ConfirmToken = Null,
+		ConfirmExpiredDate = Null,
+		SubscribedDate = GETDATE(),
+		ConfirmedDate = GETDATE()
+	WHERE Email = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, email)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_ConfirmByEmail", email,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteExpiredPending removes pending rows that were never confirmed and whose
+// confirmation window has passed, and clears the pending state of returning
+// subscribers who did not confirm in time.
+func (repo *SqlRepository) DeleteExpiredPending() error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	DELETE FROM %[1]s
+	WHERE IsPending = 1
+	AND ConfirmedDate IS NULL
+	AND ConfirmExpiredDate < GETDATE();
+
+	UPDATE %[1]s
+	SET 
+		IsPending = 0,
+		ConfirmToken = Null,
+		ConfirmExpiredDate = Null
+	WHERE IsPending = 1
+	AND ConfirmExpiredDate < GETDATE();
+	`,
+		repo.Collection,
+	)
+
 	_, err := session.ExecContext(ctx, sql)
 	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_DeleteExpiredPending", "",
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// DeleteByID removes a subscriber for good, along with their delivery log,
+// and returns the number of rows removed.
+func (repo *SqlRepository) DeleteByID(id int64) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	DELETE FROM %[1]s
+	WHERE Id = %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+
+	res, err := session.ExecContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_DeleteByID", id,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func filterConditions(filter Filter, withCursor bool) (string, []interface{}) {
+	conditions := []string{"Delflag = 0"}
+	args := []interface{}{}
+
+	addCondition := func(condition string, arg interface{}) {
+		args = append(args, arg)
+		conditions = append(conditions, fmt.Sprintf(condition, sqlQuery.Param(len(args))))
+	}
+
+	if filter.IsSubscribed != nil {
+		addCondition("IsSubscribed = %s", *filter.IsSubscribed)
+	}
+	if filter.Email != "" {
+		addCondition(`Email LIKE %s ESCAPE '\'`, containsPattern(filter.Email))
+	}
+	if filter.Name != "" {
+		addCondition(`Name LIKE %s ESCAPE '\'`, containsPattern(filter.Name))
+	}
+	if filter.SubscribedFrom != nil {
+		addCondition("SubscribedDate >= %s", *filter.SubscribedFrom)
+	}
+	if filter.SubscribedTo != nil {
+		addCondition("SubscribedDate <= %s", *filter.SubscribedTo)
+	}
+	if withCursor && filter.After != nil {
+		if filter.SortOrder == SortDesc {
+			addCondition("Id < %s", *filter.After)
+		} else {
+			addCondition("Id > %s", *filter.After)
+		}
+	}
+
+	return strings.Join(conditions, "\n\tAND "), args
+}
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`, "[", `\[`)
+
+func containsPattern(value string) string {
+	return "%" + likeEscaper.Replace(value) + "%"
+}
+
+func (repo *SqlRepository) UpdatePreferencesByEmail(subscriber entity.Subscribers) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	fields, args := sqlQuery.GenerateQueryUpdateFieldIncludeParams(subscriber, []string{"Name", "Language", "Frequency", "TimeZone"}, 1)
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET 
+		%[2]s
+	WHERE Email = %[3]s
+	`,
+		repo.Collection,
+		fields,
+		sqlQuery.Param(1),
+	)
+
+	_, err := session.ExecContext(ctx, sql, append([]interface{}{subscriber.Email}, args...)...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_UpdatePreferencesByEmail", subscriber,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+// FindListPreferences returns every list, marking the ones the subscriber is
+// on.
+func (repo *SqlRepository) FindListPreferences(subscriberID int64) ([]ListPreference, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT l.[Id] AS [id],
+		l.[Name] AS [name],
+		CAST(ISNULL(m.[IsSubscribed], 0) AS bit) AS [isSubscribed]
+	FROM %[1]s l
+	LEFT JOIN %[2]s m ON m.ListId = l.Id AND m.SubscriberId = %[3]s
+	WHERE l.Delflag = 0
+	ORDER BY l.Name, l.Id
+	`,
+		repo.ListsCollection,
+		repo.ListMembersCollection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, subscriberID)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_FindListPreferences", subscriberID, newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []ListPreference{}
+	for rows.Next() {
+		var listPreference ListPreference
+		if err := sqlStruct.Scan(&listPreference, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, listPreference)
+	}
+	return list, nil
+}
+
+// UpdateListMemberships leaves the subscriber on exactly the lists in
+// listIDs. Ids of lists that do not exist are skipped.
+func (repo *SqlRepository) UpdateListMemberships(subscriberID int64, listIDs []int64) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{subscriberID}
+	params := []string{}
+	for _, listID := range listIDs {
+		args = append(args, listID)
+		params = append(params, sqlQuery.Param(len(args)))
+	}
+
+	leave := fmt.Sprintf(`UPDATE %[1]s
+		SET IsSubscribed = 0,
+			UnsubscribedDate = GETDATE()
+		WHERE SubscriberId = %[2]s
+		AND IsSubscribed = 1`,
+		repo.ListMembersCollection,
+		sqlQuery.Param(1),
+	)
+	if len(params) == 0 {
+		leave += ";"
+	} else {
+		leave += fmt.Sprintf(`
+		AND ListId NOT IN (%s);`, strings.Join(params, ","))
+	}
+	queries := []string{leave}
+
+	if len(params) > 0 {
+		queries = append(queries, fmt.Sprintf(`MERGE %[1]s WITH (HOLDLOCK) AS target
+		USING (SELECT Id AS ListId FROM %[2]s WHERE Delflag = 0 AND Id IN (%[4]s)) AS source
+		ON target.SubscriberId = %[3]s AND target.ListId = source.ListId
+		WHEN MATCHED AND target.IsSubscribed = 0 THEN
+			UPDATE SET IsSubscribed = 1,
+				SubscribedDate = GETDATE(),
+				UnsubscribedDate = Null
+		WHEN NOT MATCHED THEN
+			INSERT (SubscriberId, ListId, IsSubscribed, SubscribedDate)
+			VALUES (%[3]s, source.ListId, 1, GETDATE());`,
+			repo.ListMembersCollection,
+			repo.ListsCollection,
+			sqlQuery.Param(1),
+			strings.Join(params, ","),
+		))
+	}
+
+	_, err := session.ExecContext(ctx, sqlQuery.GenerateTransactionAndRollback(queries), args...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_UpdateListMemberships", listIDs,
+			newsletterError.NewError(newsletterError.TechnicalError, err.Error()))
 		return err
 	}
 	return nil
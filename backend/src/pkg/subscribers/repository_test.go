@@ -0,0 +1,436 @@
+package subscribers_test
+
+import (
+	"database/sql/driver"
+	"errors"
+	"newsletter/src/pkg/entity"
+	subscribers "newsletter/src/pkg/subscribers"
+	"newsletter/src/pkg/utils/convert"
+	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const injectionPayload = "x'); DROP TABLE TB_TRN_Subscribers;--"
+
+var (
+	sqlMock    sqlmock.Sqlmock
+	repo       *subscribers.SqlRepository
+	repoLogs   *loggerMocks.Logger
+	subColumns = []string{"id", "email", "name", "isSubscribed"}
+)
+
+// payloadFreeQuery accepts a query only when it is parameterized and does not
+// contain the injection payload inline.
+var payloadFreeQuery = sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	if strings.Contains(actualSQL, injectionPayload) {
+		return errors.New("query contains inlined payload")
+	}
+	if !strings.Contains(actualSQL, expectedSQL) {
+		return errors.New("query does not contain " + expectedSQL)
+	}
+	return nil
+})
+
+func beforeEachRepository(t *testing.T) {
+	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(payloadFreeQuery))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs = &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sqlMock = mockDB
+	repo = subscribers.NewRepository("TB_TRN_Subscribers", "TB_MAS_Lists", "TB_TRN_SubscriberLists", db, repoLogs)
+}
+
+func TestRepository_FindByEmail(t *testing.T) {
+	t.Run("should pass email as parameter when find by email", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("Email = @p1").
+			WithArgs(injectionPayload).
+			WillReturnRows(sqlmock.NewRows(subColumns).AddRow(1, injectionPayload, "test", true))
+
+		res, err := repo.FindByEmail(injectionPayload)
+
+		assert.Nil(t, err)
+		assert.Equal(t, injectionPayload, res[0].Email)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("Email = @p1").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindByEmail("ajistestmail@gmail.com")
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepository_FindByConfirmToken(t *testing.T) {
+	t.Run("should pass confirm token as parameter when find by confirm token", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("ConfirmToken = @p1").
+			WithArgs(injectionPayload).
+			WillReturnRows(sqlmock.NewRows(subColumns))
+
+		res, err := repo.FindByConfirmToken(injectionPayload)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_Insert(t *testing.T) {
+	t.Run("should store injection payload verbatim when insert", func(t *testing.T) {
+		beforeEachRepository(t)
+		expiredDate := time.Date(2022, 12, 15, 0, 0, 0, 0, time.UTC)
+		sqlMock.ExpectExec("VALUES").
+			WithArgs(injectionPayload, injectionPayload, false, true, injectionPayload, expiredDate, int64(7), "Asia/Bangkok").
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Insert(entity.Subscribers{
+			Email:              injectionPayload,
+			Name:               injectionPayload,
+			IsPending:          true,
+			ConfirmToken:       convert.ValueToStringPointer(injectionPayload),
+			ConfirmExpiredDate: &expiredDate,
+			SourceAPIKeyID:     convert.ValueToInt64Pointer(7),
+			TimeZone:           convert.ValueToStringPointer("Asia/Bangkok"),
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should send null when pointer field is nil", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("VALUES").
+			WithArgs("ajistestmail@gmail.com", "test", true, false, nil, nil, nil, nil).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+
+		err := repo.Insert(entity.Subscribers{
+			Email:        "ajistestmail@gmail.com",
+			Name:         "test",
+			IsSubscribed: true,
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("VALUES").WillReturnError(errors.New("Error"))
+
+		err := repo.Insert(entity.Subscribers{Email: "ajistestmail@gmail.com"})
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRepository_UpdateByEmail(t *testing.T) {
+	t.Run("should store injection payload verbatim when update by email", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("WHERE Email = @p1").
+			WithArgs(injectionPayload, injectionPayload, injectionPayload).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdateByEmail(entity.Subscribers{
+			Email: injectionPayload,
+			Name:  injectionPayload,
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_UpdatePendingByEmail(t *testing.T) {
+	t.Run("should store injection payload verbatim when update pending by email", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("WHERE Email = @p1").
+			WithArgs(injectionPayload, true, injectionPayload, nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePendingByEmail(entity.Subscribers{
+			Email:        injectionPayload,
+			Name:         injectionPayload,
+			IsPending:    true,
+			ConfirmToken: convert.ValueToStringPointer(injectionPayload),
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should only update pending state when update pending by email", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("WHERE Email = @p1").
+			WithArgs("ajistestmail@gmail.com", true, "token", nil).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePendingByEmail(entity.Subscribers{
+			Email:          "ajistestmail@gmail.com",
+			Name:           "test",
+			IsPending:      true,
+			ConfirmToken:   convert.ValueToStringPointer("token"),
+			SourceAPIKeyID: convert.ValueToInt64Pointer(7),
+			TimeZone:       convert.ValueToStringPointer("Asia/Bangkok"),
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ConfirmByEmail(t *testing.T) {
+	t.Run("should pass email as parameter when confirm by email", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("WHERE Email = @p1").
+			WithArgs(injectionPayload).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.ConfirmByEmail(injectionPayload)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_DeleteExpiredPending(t *testing.T) {
+	t.Run("should delete expired pending without parameter", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("ConfirmExpiredDate < GETDATE()").
+			WithArgs().
+			WillReturnResult(driver.ResultNoRows)
+
+		err := repo.DeleteExpiredPending()
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FindSubscribers(t *testing.T) {
+	t.Run("should page with offset fetch when filter has page", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("ORDER BY [Id] ASC\n\tOFFSET @p1 ROWS FETCH NEXT @p2 ROWS ONLY").
+			WithArgs(40, 20).
+			WillReturnRows(sqlmock.NewRows(subColumns).AddRow(41, "ajistestmail@gmail.com", "test", true))
+
+		res, err := repo.FindSubscribers(subscribers.Filter{Page: 3, PageSize: 20, SortBy: "id", SortOrder: subscribers.SortAsc})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(41), res[0].ID)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should pass every filter as parameter and escape like pattern", func(t *testing.T) {
+		beforeEachRepository(t)
+		from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 1, 31, 0, 0, 0, 0, time.UTC)
+		sqlMock.ExpectQuery(`WHERE Delflag = 0
+	AND IsSubscribed = @p1
+	AND Email LIKE @p2 ESCAPE '\'
+	AND Name LIKE @p3 ESCAPE '\'
+	AND SubscribedDate >= @p4
+	AND SubscribedDate <= @p5
+	ORDER BY [Name] DESC, [Id] ASC
+	OFFSET @p6 ROWS FETCH NEXT @p7 ROWS ONLY`).
+			WithArgs(true, `%50\%\_off%`, `%x'); DROP TABLE TB\_TRN\_Subscribers;--%`, from, to, 0, 10).
+			WillReturnRows(sqlmock.NewRows(subColumns))
+
+		_, err := repo.FindSubscribers(subscribers.Filter{
+			Page:           1,
+			PageSize:       10,
+			IsSubscribed:   convert.ValueToBoolPointer(true),
+			Email:          "50%_off",
+			Name:           injectionPayload,
+			SubscribedFrom: &from,
+			SubscribedTo:   &to,
+			SortBy:         "name",
+			SortOrder:      subscribers.SortDesc,
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should seek past cursor instead of offset when filter has after", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("AND Id < @p1\n\tORDER BY [Id] DESC\n\tOFFSET @p2 ROWS FETCH NEXT @p3 ROWS ONLY").
+			WithArgs(int64(100), 0, 20).
+			WillReturnRows(sqlmock.NewRows(subColumns))
+
+		_, err := repo.FindSubscribers(subscribers.Filter{
+			Page:      1,
+			PageSize:  20,
+			After:     convert.ValueToInt64Pointer(100),
+			SortBy:    "id",
+			SortOrder: subscribers.SortDesc,
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("OFFSET").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindSubscribers(subscribers.Filter{Page: 1, PageSize: 20, SortBy: "id", SortOrder: subscribers.SortAsc})
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepository_CountSubscribers(t *testing.T) {
+	t.Run("should count with filters but without cursor", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("SELECT COUNT(1)\n\tFROM TB_TRN_Subscribers\n\tWHERE Delflag = 0\n\tAND IsSubscribed = @p1\n").
+			WithArgs(false).
+			WillReturnRows(sqlmock.NewRows([]string{"total"}).AddRow(7))
+
+		total, err := repo.CountSubscribers(subscribers.Filter{
+			After:        convert.ValueToInt64Pointer(100),
+			IsSubscribed: convert.ValueToBoolPointer(false),
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), total)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("COUNT(1)").WillReturnError(errors.New("Error"))
+
+		total, err := repo.CountSubscribers(subscribers.Filter{})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), total)
+	})
+}
+
+func TestRepository_DeleteByID(t *testing.T) {
+	t.Run("should delete subscriber by id parameter", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("DELETE FROM TB_TRN_Subscribers\n\tWHERE Id = @p1").
+			WithArgs(int64(1)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		count, err := repo.DeleteByID(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("DELETE").WillReturnError(errors.New("Error"))
+
+		count, err := repo.DeleteByID(1)
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), count)
+	})
+}
+
+func TestRepository_UpdatePreferencesByEmail(t *testing.T) {
+	t.Run("should only update name language frequency and time zone", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("WHERE Email = @p1").
+			WithArgs("ajistestmail@gmail.com", injectionPayload, "th", "weekly", "Asia/Bangkok").
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.UpdatePreferencesByEmail(entity.Subscribers{
+			Email:        "ajistestmail@gmail.com",
+			Name:         injectionPayload,
+			IsSubscribed: true,
+			Language:     convert.ValueToStringPointer("th"),
+			Frequency:    "weekly",
+			TimeZone:     convert.ValueToStringPointer("Asia/Bangkok"),
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FindListPreferences(t *testing.T) {
+	t.Run("should return every list with membership of subscriber", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("LEFT JOIN TB_TRN_SubscriberLists m ON m.ListId = l.Id AND m.SubscriberId = @p1").
+			WithArgs(int64(7)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "name", "isSubscribed"}).
+				AddRow(3, "Promotions", false).
+				AddRow(2, "Weekly digest", true))
+
+		res, err := repo.FindListPreferences(7)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []subscribers.ListPreference{
+			{ID: 3, Name: "Promotions"},
+			{ID: 2, Name: "Weekly digest", IsSubscribed: true},
+		}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("LEFT JOIN").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindListPreferences(7)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestRepository_UpdateListMemberships(t *testing.T) {
+	t.Run("should leave other lists and join chosen lists in one transaction", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("AND ListId NOT IN (@p2,@p3);").
+			WithArgs(int64(7), int64(2), int64(3)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repo.UpdateListMemberships(7, []int64{2, 3})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should only leave every list when no list is chosen", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("AND IsSubscribed = 1;").
+			WithArgs(int64(7)).
+			WillReturnResult(sqlmock.NewResult(0, 2))
+
+		err := repo.UpdateListMemberships(7, []int64{})
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when exec failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectExec("MERGE").WillReturnError(errors.New("Error"))
+
+		err := repo.UpdateListMemberships(7, []int64{2})
+
+		assert.NotNil(t, err)
+	})
+}
@@ -1,31 +1,95 @@
 package subscribers
 
 import (
+	"errors"
+	"fmt"
+	"newsletter/src/pkg/customfields"
+	"newsletter/src/pkg/email"
 	"newsletter/src/pkg/entity"
 	"newsletter/src/pkg/utils/convert"
 	newsletterError "newsletter/src/pkg/utils/error"
 	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/token"
+	"sort"
+	"time"
+
+	"github.com/thoas/go-funk"
+)
+
+const (
+	ConfirmTokenPurpose     = "confirm"
+	UnsubscribeTokenPurpose = "unsubscribe"
+	PreferencesTokenPurpose = "preferences"
+
+	confirmMailSubject = "Please confirm your subscription"
+	confirmMailBody    = `<p>Hi %[1]s,</p>
+<p>Please confirm your subscription by clicking the link below.</p>
+<p><a href="%[2]s">%[2]s</a></p>
+<p>This link expires on %[3]s. If you did not subscribe, you can ignore this email.</p>`
 )
 
+// ErrMissingTokenSecret is returned by ServiceConfig.Validate when no secret
+// is set to sign confirm, unsubscribe and preferences tokens with.
+var ErrMissingTokenSecret = errors.New("missing token secret")
+
 type UseCase interface {
 	GetAllSubscribers() ([]entity.Subscribers, *newsletterError.ErrorCode)
+	GetSubscribers(filter Filter) (*Page, *newsletterError.ErrorCode)
+	ExportSubscribers(filter Filter) ([]entity.Subscribers, *newsletterError.ErrorCode)
+	DeleteByID(id int64) *newsletterError.ErrorCode
 	FindByEmail(email string) ([]entity.Subscribers, *newsletterError.ErrorCode)
 	Insert(subscriber entity.Subscribers) *newsletterError.ErrorCode
 	UpdateByEmail(subscriber entity.Subscribers) *newsletterError.ErrorCode
 	Subscribe(subscriber entity.Subscribers) *newsletterError.ErrorCode
+	SavePending(subscriber entity.Subscribers) (*entity.Subscribers, *newsletterError.ErrorCode)
 	Unsubscribe(subscriber entity.Subscribers) *newsletterError.ErrorCode
+	FindByConfirmToken(confirmToken string) ([]entity.Subscribers, *newsletterError.ErrorCode)
+	UpdatePendingByEmail(subscriber entity.Subscribers) *newsletterError.ErrorCode
+	ConfirmByEmail(email string) *newsletterError.ErrorCode
+	DeleteExpiredPending() *newsletterError.ErrorCode
+	SendConfirmation(subscriber entity.Subscribers) *newsletterError.ErrorCode
+	Confirm(confirmToken string) *newsletterError.ErrorCode
+	GenerateUnsubscribeURL(email string) string
+	VerifyUnsubscribeToken(unsubscribeToken string) (string, *newsletterError.ErrorCode)
+	UnsubscribeByToken(unsubscribeToken string) *newsletterError.ErrorCode
+	GeneratePreferencesURL(email string) string
+	VerifyPreferencesToken(preferencesToken string) (string, *newsletterError.ErrorCode)
+	GetPreferences(email string) (*Preferences, *newsletterError.ErrorCode)
+	UpdatePreferences(preferences Preferences) *newsletterError.ErrorCode
+}
+
+type ServiceConfig struct {
+	TokenSecret    string
+	ConfirmTTL     time.Duration
+	ConfirmURL     string
+	UnsubscribeURL string
+	PreferencesURL string
+}
+
+// Validate reports a config that would sign tokens anyone can forge.
+func (config ServiceConfig) Validate() error {
+	if config.TokenSecret == "" {
+		return ErrMissingTokenSecret
+	}
+	return nil
 }
 
 type Service struct {
 	UseCase
-	Repo Repository
-	Logs logger.Logger
+	Repo   Repository
+	Fields customfields.UseCase
+	Email  email.UseCase
+	Config ServiceConfig
+	Logs   logger.Logger
 }
 
-func NewService(repo Repository, logs logger.Logger) *Service {
+func NewService(repo Repository, fieldsService customfields.UseCase, emailService email.UseCase, config ServiceConfig, logs logger.Logger) *Service {
 	service := &Service{
-		Repo: repo,
-		Logs: logs,
+		Repo:   repo,
+		Fields: fieldsService,
+		Email:  emailService,
+		Config: config,
+		Logs:   logs,
 	}
 	service.UseCase = service
 	return service
@@ -45,6 +109,91 @@ func (service *Service) GetAllSubscribers() ([]entity.Subscribers, *newsletterEr
 	return res, nil
 }
 
+func (service *Service) GetSubscribers(filter Filter) (*Page, *newsletterError.ErrorCode) {
+	filter = withDefaults(filter)
+
+	errValidate := validateFilter(filter)
+	if errValidate != nil {
+		return nil, errValidate
+	}
+
+	total, err := service.Repo.CountSubscribers(filter)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	res, err := service.Repo.FindSubscribers(filter)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	errFields := service.withFields(res)
+	if errFields != nil {
+		return nil, errFields
+	}
+
+	page := &Page{
+		Items:    res,
+		Total:    total,
+		Page:     filter.Page,
+		PageSize: filter.PageSize,
+	}
+	if filter.SortBy == DefaultSortBy && len(res) == filter.PageSize {
+		page.NextCursor = convert.ValueToInt64Pointer(res[len(res)-1].ID)
+	}
+
+	return page, nil
+}
+
+// ExportSubscribers returns every subscriber matching the conditions of
+// filter, ordered by id. Paging and sorting in filter are ignored; the list
+// is read in batches by id cursor instead.
+func (service *Service) ExportSubscribers(filter Filter) ([]entity.Subscribers, *newsletterError.ErrorCode) {
+	exportFilter := Filter{
+		Page:           1,
+		PageSize:       ExportBatchSize,
+		IsSubscribed:   filter.IsSubscribed,
+		Email:          filter.Email,
+		Name:           filter.Name,
+		SubscribedFrom: filter.SubscribedFrom,
+		SubscribedTo:   filter.SubscribedTo,
+		SortBy:         DefaultSortBy,
+		SortOrder:      SortAsc,
+	}
+	if exportFilter.SubscribedFrom != nil && exportFilter.SubscribedTo != nil && exportFilter.SubscribedFrom.After(*exportFilter.SubscribedTo) {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	list := []entity.Subscribers{}
+	for {
+		res, err := service.Repo.FindSubscribers(exportFilter)
+		if err != nil {
+			return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		}
+
+		list = append(list, res...)
+		if len(res) < ExportBatchSize {
+			return list, nil
+		}
+		exportFilter.After = convert.ValueToInt64Pointer(res[len(res)-1].ID)
+	}
+}
+
+// DeleteByID hard-deletes a subscriber, unlike Unsubscribe which keeps the
+// row. It is meant for erasure requests.
+func (service *Service) DeleteByID(id int64) *newsletterError.ErrorCode {
+	count, err := service.Repo.DeleteByID(id)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if count == 0 {
+		return convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return nil
+}
+
 func (service *Service) FindByEmail(email string) ([]entity.Subscribers, *newsletterError.ErrorCode) {
 	res, err := service.Repo.FindByEmail(email)
 
@@ -80,33 +229,72 @@ func (service *Service) UpdateByEmail(subscriber entity.Subscribers) *newsletter
 }
 
 func (service *Service) Subscribe(subscriber entity.Subscribers) *newsletterError.ErrorCode {
+	pendingSubscribe, err := service.SavePending(subscriber)
+	if err != nil {
+		return err
+	}
+
+	if pendingSubscribe == nil {
+		return nil
+	}
+
+	return service.UseCase.SendConfirmation(*pendingSubscribe)
+}
 
+// SavePending stores subscriber as waiting for confirmation and returns the
+// pending row, without sending the confirmation email. A subscriber who is
+// already confirmed is left alone and nothing is returned. The name, time
+// zone, source and custom fields sent are only stored for a new address.
+func (service *Service) SavePending(subscriber entity.Subscribers) (*entity.Subscribers, *newsletterError.ErrorCode) {
 	resSubscribe, err := service.UseCase.FindByEmail(subscriber.Email)
-	if err != nil {
-		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	if err != nil && *err != newsletterError.DataNotFound {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
 	}
 
-	if len(resSubscribe) == 0 {
-		newSubscribe := entity.Subscribers{
-			Email:        subscriber.Email,
-			Name:         subscriber.Name,
-			IsSubscribed: true,
-		}
+	if len(resSubscribe) > 0 && resSubscribe[0].IsSubscribed {
+		return nil, nil
+	}
 
-		errInsert := service.UseCase.Insert(newSubscribe)
+	expiredDate := time.Now().Add(service.Config.ConfirmTTL)
+	confirmToken := token.Generate(service.Config.TokenSecret, ConfirmTokenPurpose, subscriber.Email, &expiredDate)
+
+	pendingSubscribe := entity.Subscribers{
+		Email:              subscriber.Email,
+		Name:               subscriber.Name,
+		IsSubscribed:       false,
+		IsPending:          true,
+		ConfirmToken:       &confirmToken,
+		ConfirmExpiredDate: &expiredDate,
+		SourceAPIKeyID:     subscriber.SourceAPIKeyID,
+		TimeZone:           subscriber.TimeZone,
+	}
+
+	if len(resSubscribe) == 0 {
+		errInsert := service.UseCase.Insert(pendingSubscribe)
 		if errInsert != nil {
-			return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+			return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
 		}
 
-	} else {
-		subscriber.IsSubscribed = true
-		errUpdate := service.UseCase.UpdateByEmail(subscriber)
-		if errUpdate != nil {
-			return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		errFields := service.saveFields(subscriber)
+		if errFields != nil {
+			return nil, errFields
 		}
+
+		return &pendingSubscribe, nil
 	}
 
-	return nil
+	// Anyone can submit an address, so the details already on file are kept
+	// and only a fresh confirmation is issued for an existing row.
+	pendingSubscribe.Name = resSubscribe[0].Name
+	pendingSubscribe.SourceAPIKeyID = resSubscribe[0].SourceAPIKeyID
+	pendingSubscribe.TimeZone = resSubscribe[0].TimeZone
+
+	errUpdate := service.UseCase.UpdatePendingByEmail(pendingSubscribe)
+	if errUpdate != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return &pendingSubscribe, nil
 }
 
 func (service *Service) Unsubscribe(subscriber entity.Subscribers) *newsletterError.ErrorCode {
@@ -121,7 +309,7 @@ func (service *Service) Unsubscribe(subscriber entity.Subscribers) *newsletterEr
 	} else {
 		newSubscribe := entity.Subscribers{
 			Email:        subscriber.Email,
-			Name:         subscriber.Name,
+			Name:         resSubscribe[0].Name,
 			IsSubscribed: false,
 		}
 		errInsert := service.UseCase.UpdateByEmail(newSubscribe)
@@ -132,3 +320,310 @@ func (service *Service) Unsubscribe(subscriber entity.Subscribers) *newsletterEr
 
 	return nil
 }
+
+func (service *Service) FindByConfirmToken(confirmToken string) ([]entity.Subscribers, *newsletterError.ErrorCode) {
+	res, err := service.Repo.FindByConfirmToken(confirmToken)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+func (service *Service) UpdatePendingByEmail(subscriber entity.Subscribers) *newsletterError.ErrorCode {
+	err := service.Repo.UpdatePendingByEmail(subscriber)
+
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) ConfirmByEmail(email string) *newsletterError.ErrorCode {
+	err := service.Repo.ConfirmByEmail(email)
+
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) DeleteExpiredPending() *newsletterError.ErrorCode {
+	err := service.Repo.DeleteExpiredPending()
+
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) SendConfirmation(subscriber entity.Subscribers) *newsletterError.ErrorCode {
+	if subscriber.ConfirmToken == nil || subscriber.ConfirmExpiredDate == nil {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	confirmLink := fmt.Sprintf("%s?token=%s", service.Config.ConfirmURL, *subscriber.ConfirmToken)
+
+	err := service.Email.Send(email.SentMailContent{
+		To:      subscriber.Email,
+		Supject: confirmMailSubject,
+		Body:    fmt.Sprintf(confirmMailBody, subscriber.Name, confirmLink, subscriber.ConfirmExpiredDate.Format(time.RFC1123)),
+	})
+	if err != nil {
+		go service.Logs.Error("", "subscribers_Service_SendConfirmation", subscriber.Email, err.Error())
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) Confirm(confirmToken string) *newsletterError.ErrorCode {
+	_, errToken := service.verifyToken(ConfirmTokenPurpose, confirmToken)
+	if errToken != nil {
+		return errToken
+	}
+
+	resSubscribe, err := service.UseCase.FindByConfirmToken(confirmToken)
+	if err != nil {
+		return err
+	}
+
+	errConfirm := service.UseCase.ConfirmByEmail(resSubscribe[0].Email)
+	if errConfirm != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return nil
+}
+
+func (service *Service) GenerateUnsubscribeURL(email string) string {
+	unsubscribeToken := token.Generate(service.Config.TokenSecret, UnsubscribeTokenPurpose, email, nil)
+	return fmt.Sprintf("%s/%s", service.Config.UnsubscribeURL, unsubscribeToken)
+}
+
+func (service *Service) VerifyUnsubscribeToken(unsubscribeToken string) (string, *newsletterError.ErrorCode) {
+	return service.verifyToken(UnsubscribeTokenPurpose, unsubscribeToken)
+}
+
+func (service *Service) UnsubscribeByToken(unsubscribeToken string) *newsletterError.ErrorCode {
+	email, errToken := service.UseCase.VerifyUnsubscribeToken(unsubscribeToken)
+	if errToken != nil {
+		return errToken
+	}
+
+	return service.UseCase.Unsubscribe(entity.Subscribers{
+		Email: email,
+	})
+}
+
+func (service *Service) GeneratePreferencesURL(email string) string {
+	preferencesToken := token.Generate(service.Config.TokenSecret, PreferencesTokenPurpose, email, nil)
+	return fmt.Sprintf("%s/%s", service.Config.PreferencesURL, preferencesToken)
+}
+
+func (service *Service) VerifyPreferencesToken(preferencesToken string) (string, *newsletterError.ErrorCode) {
+	return service.verifyToken(PreferencesTokenPurpose, preferencesToken)
+}
+
+func (service *Service) GetPreferences(email string) (*Preferences, *newsletterError.ErrorCode) {
+	resSubscribe, err := service.UseCase.FindByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resSubscribe) == 0 {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+	}
+	subscriber := resSubscribe[0]
+
+	resLists, errLists := service.Repo.FindListPreferences(subscriber.ID)
+	if errLists != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	preferences := &Preferences{
+		SubscriberID: subscriber.ID,
+		Email:        subscriber.Email,
+		Name:         subscriber.Name,
+		Frequency:    subscriber.Frequency,
+		Lists:        resLists,
+	}
+	if subscriber.Language != nil {
+		preferences.Language = *subscriber.Language
+	}
+	if subscriber.TimeZone != nil {
+		preferences.TimeZone = *subscriber.TimeZone
+	}
+
+	return preferences, nil
+}
+
+// UpdatePreferences saves the name, language, frequency, time zone and lists
+// chosen on the preference page and logs what changed. Lists marked IsSubscribed are
+// joined and every other list is left.
+func (service *Service) UpdatePreferences(preferences Preferences) *newsletterError.ErrorCode {
+	current, err := service.UseCase.GetPreferences(preferences.Email)
+	if err != nil {
+		return err
+	}
+
+	subscriber := entity.Subscribers{
+		Email:     current.Email,
+		Name:      preferences.Name,
+		Frequency: preferences.Frequency,
+	}
+	if preferences.Language != "" {
+		subscriber.Language = convert.ValueToStringPointer(preferences.Language)
+	}
+	if preferences.TimeZone != "" {
+		subscriber.TimeZone = convert.ValueToStringPointer(preferences.TimeZone)
+	}
+
+	errUpdate := service.Repo.UpdatePreferencesByEmail(subscriber)
+	if errUpdate != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	errLists := service.Repo.UpdateListMemberships(current.SubscriberID, subscribedListIDs(preferences.Lists))
+	if errLists != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	changes := preferenceChanges(*current, preferences)
+	if len(changes) > 0 {
+		go service.Logs.Info("", "subscribers_Service_UpdatePreferences", current.Email, changes)
+	}
+
+	return nil
+}
+
+func (service *Service) verifyToken(purpose, signedToken string) (string, *newsletterError.ErrorCode) {
+	subject, err := token.Verify(service.Config.TokenSecret, purpose, signedToken)
+	switch err {
+	case nil:
+		return subject, nil
+	case token.ErrExpiredToken:
+		return "", convert.ValueToErrorCodePointer(newsletterError.ExpiredToken)
+	default:
+		return "", convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+	}
+}
+
+// saveFields stores the custom values sent with the subscribe of a new
+// subscriber, read back after insert for its id.
+func (service *Service) saveFields(subscriber entity.Subscribers) *newsletterError.ErrorCode {
+	if len(subscriber.Fields) == 0 {
+		return nil
+	}
+
+	res, err := service.UseCase.FindByEmail(subscriber.Email)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	return service.Fields.SaveValues(res[0].ID, subscriber.Fields)
+}
+
+// withFields fills in the custom values of every subscriber in list.
+func (service *Service) withFields(list []entity.Subscribers) *newsletterError.ErrorCode {
+	subscriberIDs := []int64{}
+	for _, subscriber := range list {
+		subscriberIDs = append(subscriberIDs, subscriber.ID)
+	}
+
+	values, err := service.Fields.GetValues(subscriberIDs)
+	if err != nil {
+		return err
+	}
+
+	for i := range list {
+		list[i].Fields = values[list[i].ID]
+	}
+	return nil
+}
+
+func withDefaults(filter Filter) Filter {
+	if filter.Page == 0 {
+		filter.Page = 1
+	}
+	if filter.PageSize == 0 {
+		filter.PageSize = DefaultPageSize
+	}
+	if filter.SortBy == "" {
+		filter.SortBy = DefaultSortBy
+	}
+	if filter.SortOrder == "" {
+		filter.SortOrder = SortAsc
+	}
+	return filter
+}
+
+func validateFilter(filter Filter) *newsletterError.ErrorCode {
+	if filter.Page < 1 || filter.PageSize < 1 || filter.PageSize > MaxPageSize {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	if _, ok := SortColumns[filter.SortBy]; !ok {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	if filter.SortOrder != SortAsc && filter.SortOrder != SortDesc {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	// The cursor is a subscriber id, so it only pages correctly when the
+	// list is ordered by id, and it replaces the page number.
+	if filter.After != nil && (filter.SortBy != DefaultSortBy || filter.Page != 1) {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	if filter.SubscribedFrom != nil && filter.SubscribedTo != nil && filter.SubscribedFrom.After(*filter.SubscribedTo) {
+		return convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+	}
+
+	return nil
+}
+
+// subscribedListIDs returns the ids of the lists marked IsSubscribed, in
+// ascending order so two choices can be compared.
+func subscribedListIDs(lists []ListPreference) []int64 {
+	listIDs := []int64{}
+	for _, list := range lists {
+		if list.IsSubscribed {
+			listIDs = append(listIDs, list.ID)
+		}
+	}
+	sort.Slice(listIDs, func(i, j int) bool { return listIDs[i] < listIDs[j] })
+	return listIDs
+}
+
+func preferenceChanges(from, to Preferences) []PreferenceChange {
+	changes := []PreferenceChange{}
+	if from.Name != to.Name {
+		changes = append(changes, PreferenceChange{Field: NameField, From: from.Name, To: to.Name})
+	}
+	if from.Language != to.Language {
+		changes = append(changes, PreferenceChange{Field: LanguageField, From: from.Language, To: to.Language})
+	}
+	if from.Frequency != to.Frequency {
+		changes = append(changes, PreferenceChange{Field: FrequencyField, From: from.Frequency, To: to.Frequency})
+	}
+	if from.TimeZone != to.TimeZone {
+		changes = append(changes, PreferenceChange{Field: TimeZoneField, From: from.TimeZone, To: to.TimeZone})
+	}
+
+	fromListIDs, toListIDs := subscribedListIDs(from.Lists), subscribedListIDs(to.Lists)
+	if !funk.Equal(fromListIDs, toListIDs) {
+		changes = append(changes, PreferenceChange{Field: ListsField, From: fromListIDs, To: toListIDs})
+	}
+
+	return changes
+}
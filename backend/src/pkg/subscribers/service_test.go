@@ -2,6 +2,9 @@ package subscribers_test
 
 import (
 	"errors"
+	customFieldsMocks "newsletter/src/pkg/customfields/mocks"
+	"newsletter/src/pkg/email"
+	emailMocks "newsletter/src/pkg/email/mocks"
 	"newsletter/src/pkg/entity"
 	subscribers "newsletter/src/pkg/subscribers"
 	"newsletter/src/pkg/subscribers/mocks"
@@ -9,31 +12,68 @@ import (
 	newsletterError "newsletter/src/pkg/utils/error"
 	loggerMocks "newsletter/src/pkg/utils/logger/mocks"
 	"newsletter/src/pkg/utils/mocker"
+	"newsletter/src/pkg/utils/token"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
 var (
-	mockUseCase *mocks.UseCase
-	repository  *mocks.Repository
-	service     *subscribers.Service
-	logs        *loggerMocks.Logger
+	mockUseCase   *mocks.UseCase
+	repository    *mocks.Repository
+	fieldsService *customFieldsMocks.UseCase
+	emailService  *emailMocks.UseCase
+	service       *subscribers.Service
+	logs          *loggerMocks.Logger
+	config        subscribers.ServiceConfig
 
 	mockRepoGetAllSubscribers *mocker.MockCall
+	mockRepoFindSubscribers   *mocker.MockCall
+	mockRepoCountSubscribers  *mocker.MockCall
 	mockRepoFindByEmail       *mocker.MockCall
 	mockRepoInsert            *mocker.MockCall
 	mockRepoUpdateByEmail     *mocker.MockCall
 	mockServiceFindByEmail    *mocker.MockCall
 	mockServiceInsert         *mocker.MockCall
 	mockServiceUpdateByEmail  *mocker.MockCall
+
+	mockRepoFindByConfirmToken        *mocker.MockCall
+	mockRepoUpdatePendingByEmail      *mocker.MockCall
+	mockRepoConfirmByEmail            *mocker.MockCall
+	mockRepoDeleteExpiredPending      *mocker.MockCall
+	mockRepoDeleteByID                *mocker.MockCall
+	mockServiceFindByConfirmToken     *mocker.MockCall
+	mockServiceUpdatePendingByEmail   *mocker.MockCall
+	mockServiceConfirmByEmail         *mocker.MockCall
+	mockServiceDeleteExpiredPending   *mocker.MockCall
+	mockServiceSendConfirmation       *mocker.MockCall
+	mockEmailSend                     *mocker.MockCall
+	mockServiceVerifyUnsubscribeToken *mocker.MockCall
+	mockServiceUnsubscribe            *mocker.MockCall
+
+	mockRepoFindListPreferences      *mocker.MockCall
+	mockRepoUpdatePreferencesByEmail *mocker.MockCall
+	mockRepoUpdateListMemberships    *mocker.MockCall
+	mockServiceGetPreferences        *mocker.MockCall
+	mockFieldsSaveValues             *mocker.MockCall
+	mockFieldsGetValues              *mocker.MockCall
 )
 
 func callRepoGetAllSubscribers() *mock.Call {
 	return repository.On("GetAllSubscribers")
 }
 
+func callRepoFindSubscribers() *mock.Call {
+	return repository.On("FindSubscribers", mock.Anything)
+}
+
+func callRepoCountSubscribers() *mock.Call {
+	return repository.On("CountSubscribers", mock.Anything)
+}
+
 func callRepoFindByEmail() *mock.Call {
 	return repository.On("FindByEmail", mock.Anything)
 }
@@ -58,28 +98,138 @@ func callServiceUpdateByEmail() *mock.Call {
 	return mockUseCase.On("UpdateByEmail", mock.Anything)
 }
 
+func callRepoFindByConfirmToken() *mock.Call {
+	return repository.On("FindByConfirmToken", mock.Anything)
+}
+
+func callRepoUpdatePendingByEmail() *mock.Call {
+	return repository.On("UpdatePendingByEmail", mock.Anything)
+}
+
+func callRepoConfirmByEmail() *mock.Call {
+	return repository.On("ConfirmByEmail", mock.Anything)
+}
+
+func callRepoDeleteExpiredPending() *mock.Call {
+	return repository.On("DeleteExpiredPending")
+}
+
+func callRepoDeleteByID() *mock.Call {
+	return repository.On("DeleteByID", mock.Anything)
+}
+
+func callServiceFindByConfirmToken() *mock.Call {
+	return mockUseCase.On("FindByConfirmToken", mock.Anything)
+}
+
+func callServiceUpdatePendingByEmail() *mock.Call {
+	return mockUseCase.On("UpdatePendingByEmail", mock.Anything)
+}
+
+func callServiceConfirmByEmail() *mock.Call {
+	return mockUseCase.On("ConfirmByEmail", mock.Anything)
+}
+
+func callServiceDeleteExpiredPending() *mock.Call {
+	return mockUseCase.On("DeleteExpiredPending")
+}
+
+func callServiceSendConfirmation() *mock.Call {
+	return mockUseCase.On("SendConfirmation", mock.Anything)
+}
+
+func callServiceVerifyUnsubscribeToken() *mock.Call {
+	return mockUseCase.On("VerifyUnsubscribeToken", mock.Anything)
+}
+
+func callServiceUnsubscribe() *mock.Call {
+	return mockUseCase.On("Unsubscribe", mock.Anything)
+}
+
+func callRepoFindListPreferences() *mock.Call {
+	return repository.On("FindListPreferences", mock.Anything)
+}
+
+func callRepoUpdatePreferencesByEmail() *mock.Call {
+	return repository.On("UpdatePreferencesByEmail", mock.Anything)
+}
+
+func callRepoUpdateListMemberships() *mock.Call {
+	return repository.On("UpdateListMemberships", mock.Anything, mock.Anything)
+}
+
+func callServiceGetPreferences() *mock.Call {
+	return mockUseCase.On("GetPreferences", mock.Anything)
+}
+
+func callFieldsSaveValues() *mock.Call {
+	return fieldsService.On("SaveValues", mock.Anything, mock.Anything)
+}
+
+func callFieldsGetValues() *mock.Call {
+	return fieldsService.On("GetValues", mock.Anything)
+}
+
+func callEmailSend() *mock.Call {
+	return emailService.On("Send", mock.Anything)
+}
+
 func beforeEach() {
 	mockUseCase = &mocks.UseCase{}
 	repository = &mocks.Repository{}
+	fieldsService = &customFieldsMocks.UseCase{}
+	emailService = &emailMocks.UseCase{}
 	logs = &loggerMocks.Logger{}
+	config = subscribers.ServiceConfig{
+		TokenSecret:    "secret",
+		ConfirmTTL:     time.Hour,
+		ConfirmURL:     "http://localhost:8000/subscribers/confirm",
+		UnsubscribeURL: "http://localhost:8000/subscribers/unsubscribe",
+		PreferencesURL: "http://localhost:8000/preferences",
+	}
 
 	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 
 	service = &subscribers.Service{
-		Repo: repository,
-		Logs: logs,
+		Repo:   repository,
+		Fields: fieldsService,
+		Email:  emailService,
+		Config: config,
+		Logs:   logs,
 	}
 	service.UseCase = mockUseCase
 }
 
+func TestServiceConfig_Validate(t *testing.T) {
+	t.Run("should return missing token secret when token secret is empty", func(t *testing.T) {
+		beforeEach()
+		config.TokenSecret = ""
+
+		err := config.Validate()
+
+		assert.Equal(t, subscribers.ErrMissingTokenSecret, err)
+	})
+
+	t.Run("should return nil when token secret is set", func(t *testing.T) {
+		beforeEach()
+
+		err := config.Validate()
+
+		assert.Nil(t, err)
+	})
+}
+
 func TestService_NewService(t *testing.T) {
 	t.Run("should return struct subscribers service when call new service", func(t *testing.T) {
 		beforeEach()
-		resService := subscribers.NewService(repository, logs)
+		resService := subscribers.NewService(repository, fieldsService, emailService, config, logs)
 
 		expectedService := &subscribers.Service{
-			Repo: repository,
-			Logs: logs,
+			Repo:   repository,
+			Fields: fieldsService,
+			Email:  emailService,
+			Config: config,
+			Logs:   logs,
 		}
 		expectedService.UseCase = expectedService
 
@@ -143,6 +293,130 @@ func TestService_GetAllSubscribers(t *testing.T) {
 
 }
 
+func TestService_GetSubscribers(t *testing.T) {
+	beforeEachGetSubscribers := func() {
+		beforeEach()
+
+		mockRepoCountSubscribers = mocker.NewMockCall(callRepoCountSubscribers)
+		mockRepoCountSubscribers.Return(int64(0), nil)
+		mockRepoFindSubscribers = mocker.NewMockCall(callRepoFindSubscribers)
+		mockRepoFindSubscribers.Return([]entity.Subscribers{}, nil)
+		mockFieldsGetValues = mocker.NewMockCall(callFieldsGetValues)
+		mockFieldsGetValues.Return(map[int64]map[string]interface{}{}, nil)
+	}
+
+	t.Run("should apply default page size and sort when filter is empty", func(t *testing.T) {
+		beforeEachGetSubscribers()
+
+		res, err := service.GetSubscribers(subscribers.Filter{})
+
+		expectedFilter := subscribers.Filter{
+			Page:      1,
+			PageSize:  subscribers.DefaultPageSize,
+			SortBy:    subscribers.DefaultSortBy,
+			SortOrder: subscribers.SortAsc,
+		}
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "CountSubscribers", expectedFilter)
+		repository.AssertCalled(t, "FindSubscribers", expectedFilter)
+		assert.Equal(t, &subscribers.Page{Items: []entity.Subscribers{}, Page: 1, PageSize: subscribers.DefaultPageSize}, res)
+	})
+
+	after := int64(10)
+	from := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	invalidFilters := map[string]subscribers.Filter{
+		"page is negative":             {Page: -1},
+		"page size is above maximum":   {PageSize: subscribers.MaxPageSize + 1},
+		"sort column is unknown":       {SortBy: "confirmToken"},
+		"sort order is unknown":        {SortOrder: "up"},
+		"cursor is used with sort":     {After: &after, SortBy: "name"},
+		"cursor is used with page":     {After: &after, Page: 2},
+		"subscribed range is reversed": {SubscribedFrom: &from, SubscribedTo: &to},
+	}
+	for name, filter := range invalidFilters {
+		filter := filter
+		t.Run("should response bad request when "+name, func(t *testing.T) {
+			beforeEachGetSubscribers()
+
+			res, err := service.GetSubscribers(filter)
+
+			assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+			assert.Nil(t, res)
+			repository.AssertNotCalled(t, "FindSubscribers", mock.Anything)
+		})
+	}
+
+	t.Run("should response internal server error when repository count failed", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoCountSubscribers.Return(int64(0), errors.New("Error"))
+
+		res, err := service.GetSubscribers(subscribers.Filter{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should response internal server error when repository find failed", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindSubscribers.Return(nil, errors.New("Error"))
+
+		res, err := service.GetSubscribers(subscribers.Filter{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should fill custom fields of every subscriber on page", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 3}, {ID: 4}}, nil)
+		mockFieldsGetValues.Return(map[int64]map[string]interface{}{3: {"plan": "pro"}}, nil)
+
+		res, err := service.GetSubscribers(subscribers.Filter{})
+
+		assert.Nil(t, err)
+		fieldsService.AssertCalled(t, "GetValues", []int64{3, 4})
+		assert.Equal(t, []entity.Subscribers{{ID: 3, Fields: map[string]interface{}{"plan": "pro"}}, {ID: 4}}, res.Items)
+	})
+
+	t.Run("should response internal server error when read custom fields failed", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockFieldsGetValues.Return(nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		res, err := service.GetSubscribers(subscribers.Filter{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return next cursor when page sorted by id is full", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoCountSubscribers.Return(int64(5), nil)
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 3}, {ID: 4}}, nil)
+
+		res, err := service.GetSubscribers(subscribers.Filter{PageSize: 2, After: convert.ValueToInt64Pointer(2)})
+
+		assert.Nil(t, err)
+		assert.Equal(t, &subscribers.Page{
+			Items:      []entity.Subscribers{{ID: 3}, {ID: 4}},
+			Total:      5,
+			Page:       1,
+			PageSize:   2,
+			NextCursor: convert.ValueToInt64Pointer(4),
+		}, res)
+	})
+
+	t.Run("should not return next cursor when sorted by other column", func(t *testing.T) {
+		beforeEachGetSubscribers()
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 3}, {ID: 4}}, nil)
+
+		res, err := service.GetSubscribers(subscribers.Filter{PageSize: 2, SortBy: "name"})
+
+		assert.Nil(t, err)
+		assert.Nil(t, res.NextCursor)
+	})
+}
+
 func TestService_FindByEmail(t *testing.T) {
 	beforeEachFindByEmail := func() {
 		beforeEach()
@@ -306,10 +580,37 @@ func TestService_Subscribe(t *testing.T) {
 		mockServiceFindByEmail.Return(nil, nil)
 		mockServiceInsert = mocker.NewMockCall(callServiceInsert)
 		mockServiceInsert.Return(nil)
-		mockServiceUpdateByEmail = mocker.NewMockCall(callServiceUpdateByEmail)
-		mockServiceUpdateByEmail.Return(nil)
+		mockServiceUpdatePendingByEmail = mocker.NewMockCall(callServiceUpdatePendingByEmail)
+		mockServiceUpdatePendingByEmail.Return(nil)
+		mockServiceSendConfirmation = mocker.NewMockCall(callServiceSendConfirmation)
+		mockServiceSendConfirmation.Return(nil)
+		mockFieldsSaveValues = mocker.NewMockCall(callFieldsSaveValues)
+		mockFieldsSaveValues.Return(nil)
 	}
 
+	isPendingSubscriber := func(subscriber entity.Subscribers) interface{} {
+		return mock.MatchedBy(func(pending entity.Subscribers) bool {
+			return pending.Email == subscriber.Email &&
+				pending.Name == subscriber.Name &&
+				!pending.IsSubscribed &&
+				pending.IsPending &&
+				pending.ConfirmToken != nil &&
+				pending.ConfirmExpiredDate != nil
+		})
+	}
+
+	t.Run("should not delete expired pending when call service subscribe", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:  "test",
+			Email: "ajistestmail@gmail.com",
+		}
+
+		service.Subscribe(mockSubscribers)
+
+		mockUseCase.AssertNotCalled(t, "DeleteExpiredPending")
+	})
+
 	t.Run("should call service subscribe when call service find by email", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
@@ -336,42 +637,86 @@ func TestService_Subscribe(t *testing.T) {
 		assert.Equal(t, expectedError, err)
 	})
 
-	t.Run("in case response not exist data should call service subscribe when call service insert", func(t *testing.T) {
+	t.Run("in case response not exist data should call service insert with pending subscriber", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
 			Email: "ajistestmail@gmail.com",
 		}
 
-		newSubscribe := entity.Subscribers{
-			Email:        mockSubscribers.Email,
-			Name:         mockSubscribers.Name,
-			IsSubscribed: true,
+		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
+
+		service.Subscribe(mockSubscribers)
+
+		mockUseCase.AssertCalled(t, "Insert", isPendingSubscriber(mockSubscribers))
+		mockUseCase.AssertNotCalled(t, "UpdatePendingByEmail", mock.Anything)
+	})
+
+	t.Run("in case service find by email return data not found should call service insert", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:  "test",
+			Email: "ajistestmail@gmail.com",
 		}
 
+		mockServiceFindByEmail.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		service.Subscribe(mockSubscribers)
+
+		mockUseCase.AssertCalled(t, "Insert", isPendingSubscriber(mockSubscribers))
+	})
+
+	t.Run("should keep api key of caller as source when call service insert", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:           "test",
+			Email:          "ajistestmail@gmail.com",
+			SourceAPIKeyID: convert.ValueToInt64Pointer(7),
+		}
 		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
 
 		service.Subscribe(mockSubscribers)
 
-		mockUseCase.AssertCalled(t, "Insert", newSubscribe)
+		mockUseCase.AssertCalled(t, "Insert", mock.MatchedBy(func(pending entity.Subscribers) bool {
+			return pending.SourceAPIKeyID != nil && *pending.SourceAPIKeyID == 7
+		}))
 	})
 
-	t.Run("should return internal server error when call service insert failed", func(t *testing.T) {
+	t.Run("should keep time zone of request when call service insert", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:     "test",
+			Email:    "ajistestmail@gmail.com",
+			TimeZone: convert.ValueToStringPointer("Asia/Bangkok"),
+		}
+		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
+
+		service.Subscribe(mockSubscribers)
+
+		mockUseCase.AssertCalled(t, "Insert", mock.MatchedBy(func(pending entity.Subscribers) bool {
+			return pending.TimeZone != nil && *pending.TimeZone == "Asia/Bangkok"
+		}))
+	})
+
+	t.Run("should keep time zone on file when resubscribe request has none", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
 			Email: "ajistestmail@gmail.com",
 		}
-		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
-		mockServiceInsert.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		mockServiceFindByEmail.Return([]entity.Subscribers{{
+			Email:    "ajistestmail@gmail.com",
+			TimeZone: convert.ValueToStringPointer("Europe/London"),
+		}}, nil)
 
-		err := service.Subscribe(mockSubscribers)
+		service.Subscribe(mockSubscribers)
 
-		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
-		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertCalled(t, "UpdatePendingByEmail", mock.MatchedBy(func(pending entity.Subscribers) bool {
+			return pending.TimeZone != nil && *pending.TimeZone == "Europe/London"
+		}))
 	})
 
-	t.Run("should return error nil when call subscribe service insert success", func(t *testing.T) {
+	t.Run("should return internal server error when call service insert failed", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
@@ -384,9 +729,10 @@ func TestService_Subscribe(t *testing.T) {
 
 		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
 		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "SendConfirmation", mock.Anything)
 	})
 
-	t.Run("in case response exist data should call service subscribe when call service update by email", func(t *testing.T) {
+	t.Run("in case response exist unsubscribed data should call service update pending by email", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
@@ -394,17 +740,16 @@ func TestService_Subscribe(t *testing.T) {
 		}
 
 		mockDataSubscribers := mockDataSubscribers()
-
+		mockDataSubscribers[0].IsSubscribed = false
 		mockServiceFindByEmail.Return(mockDataSubscribers, nil)
 
 		service.Subscribe(mockSubscribers)
 
-		mockSubscribers.IsSubscribed = true
-
-		mockUseCase.AssertCalled(t, "UpdateByEmail", mockSubscribers)
+		mockUseCase.AssertCalled(t, "UpdatePendingByEmail", isPendingSubscriber(mockSubscribers))
+		mockUseCase.AssertNotCalled(t, "Insert", mock.Anything)
 	})
 
-	t.Run("should return internal server error when call service update by email failed", func(t *testing.T) {
+	t.Run("should return internal server error when call service update pending by email failed", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
@@ -412,30 +757,180 @@ func TestService_Subscribe(t *testing.T) {
 		}
 
 		mockDataSubscribers := mockDataSubscribers()
+		mockDataSubscribers[0].IsSubscribed = false
 		mockServiceFindByEmail.Return(mockDataSubscribers, nil)
-		mockServiceUpdateByEmail.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		mockServiceUpdatePendingByEmail.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
 
 		err := service.Subscribe(mockSubscribers)
 
 		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
 		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "SendConfirmation", mock.Anything)
 	})
 
-	t.Run("should return error nil when call subscribe service update by email success", func(t *testing.T) {
+	t.Run("in case response exist subscribed data should not send confirmation again", func(t *testing.T) {
 		beforeEachSubscribe()
 		mockSubscribers := entity.Subscribers{
 			Name:  "test",
 			Email: "ajistestmail@gmail.com",
 		}
-		mockDataSubscribers := mockDataSubscribers()
-		mockServiceFindByEmail.Return(mockDataSubscribers, nil)
-		mockServiceUpdateByEmail.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+		mockServiceFindByEmail.Return(mockDataSubscribers(), nil)
+
+		err := service.Subscribe(mockSubscribers)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertNotCalled(t, "Insert", mock.Anything)
+		mockUseCase.AssertNotCalled(t, "UpdatePendingByEmail", mock.Anything)
+		mockUseCase.AssertNotCalled(t, "SendConfirmation", mock.Anything)
+	})
+
+	t.Run("should call service send confirmation with pending subscriber when save pending success", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:  "test",
+			Email: "ajistestmail@gmail.com",
+		}
+		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
+
+		err := service.Subscribe(mockSubscribers)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "SendConfirmation", isPendingSubscriber(mockSubscribers))
+	})
+
+	t.Run("should return internal server error when call service send confirmation failed", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:  "test",
+			Email: "ajistestmail@gmail.com",
+		}
+		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
+		mockServiceSendConfirmation.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
 
 		err := service.Subscribe(mockSubscribers)
 
 		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
 		assert.Equal(t, expectedError, err)
 	})
+
+	t.Run("should not save custom fields when none were sent", func(t *testing.T) {
+		beforeEachSubscribe()
+
+		err := service.Subscribe(entity.Subscribers{Name: "test", Email: "ajistestmail@gmail.com"})
+
+		assert.Nil(t, err)
+		fieldsService.AssertNotCalled(t, "SaveValues", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should save custom fields of new subscriber under id read back after insert", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:   "test",
+			Email:  "ajistestmail@gmail.com",
+			Fields: map[string]interface{}{"plan": "pro"},
+		}
+		mockServiceFindByEmail.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)).Once()
+		mockServiceFindByEmail.NumberOfCallReturn(2, []entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, nil)
+
+		err := service.Subscribe(mockSubscribers)
+
+		assert.Nil(t, err)
+		fieldsService.AssertCalled(t, "SaveValues", int64(7), map[string]interface{}{"plan": "pro"})
+		mockUseCase.AssertCalled(t, "SendConfirmation", isPendingSubscriber(mockSubscribers))
+	})
+
+	t.Run("should not save custom fields of existing subscriber before confirmation", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:   "test",
+			Email:  "ajistestmail@gmail.com",
+			Fields: map[string]interface{}{"plan": "pro"},
+		}
+		mockServiceFindByEmail.Return([]entity.Subscribers{{ID: 5, Email: "ajistestmail@gmail.com", IsPending: true}}, nil)
+
+		err := service.Subscribe(mockSubscribers)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertNumberOfCalls(t, "FindByEmail", 1)
+		fieldsService.AssertNotCalled(t, "SaveValues", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should keep details on file when existing subscriber resubscribes", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockSubscribers := entity.Subscribers{
+			Name:           "attacker",
+			Email:          "ajistestmail@gmail.com",
+			SourceAPIKeyID: convert.ValueToInt64Pointer(9),
+			TimeZone:       convert.ValueToStringPointer("Asia/Bangkok"),
+		}
+		mockServiceFindByEmail.Return([]entity.Subscribers{{
+			ID:             5,
+			Name:           "test",
+			Email:          "ajistestmail@gmail.com",
+			SourceAPIKeyID: convert.ValueToInt64Pointer(7),
+			TimeZone:       convert.ValueToStringPointer("Europe/London"),
+		}}, nil)
+
+		err := service.Subscribe(mockSubscribers)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "UpdatePendingByEmail", mock.MatchedBy(func(pending entity.Subscribers) bool {
+			return pending.Name == "test" &&
+				*pending.SourceAPIKeyID == 7 &&
+				*pending.TimeZone == "Europe/London"
+		}))
+	})
+
+	t.Run("should not send confirmation when save custom fields failed", func(t *testing.T) {
+		beforeEachSubscribe()
+		mockServiceFindByEmail.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound)).Once()
+		mockServiceFindByEmail.NumberOfCallReturn(2, []entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, nil)
+		mockFieldsSaveValues.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		err := service.Subscribe(entity.Subscribers{Name: "test", Email: "ajistestmail@gmail.com", Fields: map[string]interface{}{"plan": "pro"}})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		mockUseCase.AssertNotCalled(t, "SendConfirmation", mock.Anything)
+	})
+}
+
+func TestService_SavePending(t *testing.T) {
+	beforeEachSavePending := func() {
+		beforeEach()
+
+		mockServiceFindByEmail = mocker.NewMockCall(callServiceFindByEmail)
+		mockServiceFindByEmail.Return([]entity.Subscribers{}, nil)
+		mockServiceInsert = mocker.NewMockCall(callServiceInsert)
+		mockServiceInsert.Return(nil)
+		mockServiceUpdatePendingByEmail = mocker.NewMockCall(callServiceUpdatePendingByEmail)
+		mockServiceUpdatePendingByEmail.Return(nil)
+		mockServiceSendConfirmation = mocker.NewMockCall(callServiceSendConfirmation)
+		mockServiceSendConfirmation.Return(nil)
+	}
+
+	t.Run("should return pending subscriber without sending confirmation", func(t *testing.T) {
+		beforeEachSavePending()
+
+		res, err := service.SavePending(entity.Subscribers{Name: "test", Email: "ajistestmail@gmail.com"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", res.Email)
+		assert.True(t, res.IsPending)
+		assert.NotNil(t, res.ConfirmToken)
+		mockUseCase.AssertCalled(t, "Insert", mock.Anything)
+		mockUseCase.AssertNotCalled(t, "SendConfirmation", mock.Anything)
+	})
+
+	t.Run("should return nothing when subscriber is already confirmed", func(t *testing.T) {
+		beforeEachSavePending()
+		mockServiceFindByEmail.Return(mockDataSubscribers(), nil)
+
+		res, err := service.SavePending(entity.Subscribers{Name: "test", Email: "ajistestmail@gmail.com"})
+
+		assert.Nil(t, err)
+		assert.Nil(t, res)
+		mockUseCase.AssertNotCalled(t, "UpdatePendingByEmail", mock.Anything)
+	})
 }
 
 func TestService_Unsubscribe(t *testing.T) {
@@ -554,3 +1049,686 @@ func mockDataSubscribers() []entity.Subscribers {
 		},
 	}
 }
+
+func TestService_FindByConfirmToken(t *testing.T) {
+	beforeEachFindByConfirmToken := func() {
+		beforeEach()
+
+		mockRepoFindByConfirmToken = mocker.NewMockCall(callRepoFindByConfirmToken)
+		mockRepoFindByConfirmToken.Return(nil, nil)
+	}
+
+	t.Run("should call repository find by confirm token when call service find by confirm token", func(t *testing.T) {
+		beforeEachFindByConfirmToken()
+
+		service.FindByConfirmToken("token")
+
+		repository.AssertCalled(t, "FindByConfirmToken", "token")
+	})
+
+	t.Run("should response internal server error when repository find by confirm token failed", func(t *testing.T) {
+		beforeEachFindByConfirmToken()
+		mockRepoFindByConfirmToken.Return(nil, errors.New("Error"))
+
+		res, err := service.FindByConfirmToken("token")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data not found when data not founded", func(t *testing.T) {
+		beforeEachFindByConfirmToken()
+		mockRepoFindByConfirmToken.Return([]entity.Subscribers{}, nil)
+
+		res, err := service.FindByConfirmToken("token")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+		assert.Nil(t, res)
+		assert.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return data when found data", func(t *testing.T) {
+		beforeEachFindByConfirmToken()
+		mockSubscribers := mockDataSubscribers()
+		mockRepoFindByConfirmToken.Return(mockSubscribers, nil)
+
+		res, err := service.FindByConfirmToken("token")
+
+		assert.Equal(t, mockSubscribers, res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_UpdatePendingByEmail(t *testing.T) {
+	beforeEachUpdatePendingByEmail := func() {
+		beforeEach()
+
+		mockRepoUpdatePendingByEmail = mocker.NewMockCall(callRepoUpdatePendingByEmail)
+		mockRepoUpdatePendingByEmail.Return(nil)
+	}
+
+	t.Run("should call repository update pending by email when call service update pending by email", func(t *testing.T) {
+		beforeEachUpdatePendingByEmail()
+		mockSubscribers := entity.Subscribers{
+			Name:      "test",
+			Email:     "ajistestmail@gmail.com",
+			IsPending: true,
+		}
+
+		service.UpdatePendingByEmail(mockSubscribers)
+
+		repository.AssertCalled(t, "UpdatePendingByEmail", mockSubscribers)
+	})
+
+	t.Run("should return internal server error when call repository update pending by email failed", func(t *testing.T) {
+		beforeEachUpdatePendingByEmail()
+		mockRepoUpdatePendingByEmail.Return(errors.New("Error"))
+
+		err := service.UpdatePendingByEmail(entity.Subscribers{})
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return error nil when call repository update pending by email success", func(t *testing.T) {
+		beforeEachUpdatePendingByEmail()
+
+		err := service.UpdatePendingByEmail(entity.Subscribers{})
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_ConfirmByEmail(t *testing.T) {
+	beforeEachConfirmByEmail := func() {
+		beforeEach()
+
+		mockRepoConfirmByEmail = mocker.NewMockCall(callRepoConfirmByEmail)
+		mockRepoConfirmByEmail.Return(nil)
+	}
+
+	t.Run("should call repository confirm by email when call service confirm by email", func(t *testing.T) {
+		beforeEachConfirmByEmail()
+
+		service.ConfirmByEmail("ajistestmail@gmail.com")
+
+		repository.AssertCalled(t, "ConfirmByEmail", "ajistestmail@gmail.com")
+	})
+
+	t.Run("should return internal server error when call repository confirm by email failed", func(t *testing.T) {
+		beforeEachConfirmByEmail()
+		mockRepoConfirmByEmail.Return(errors.New("Error"))
+
+		err := service.ConfirmByEmail("ajistestmail@gmail.com")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return error nil when call repository confirm by email success", func(t *testing.T) {
+		beforeEachConfirmByEmail()
+
+		err := service.ConfirmByEmail("ajistestmail@gmail.com")
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_DeleteExpiredPending(t *testing.T) {
+	beforeEachDeleteExpiredPending := func() {
+		beforeEach()
+
+		mockRepoDeleteExpiredPending = mocker.NewMockCall(callRepoDeleteExpiredPending)
+		mockRepoDeleteExpiredPending.Return(nil)
+	}
+
+	t.Run("should call repository delete expired pending when call service delete expired pending", func(t *testing.T) {
+		beforeEachDeleteExpiredPending()
+
+		service.DeleteExpiredPending()
+
+		repository.AssertCalled(t, "DeleteExpiredPending")
+	})
+
+	t.Run("should return internal server error when call repository delete expired pending failed", func(t *testing.T) {
+		beforeEachDeleteExpiredPending()
+		mockRepoDeleteExpiredPending.Return(errors.New("Error"))
+
+		err := service.DeleteExpiredPending()
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+	})
+
+	t.Run("should return error nil when call repository delete expired pending success", func(t *testing.T) {
+		beforeEachDeleteExpiredPending()
+
+		err := service.DeleteExpiredPending()
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_SendConfirmation(t *testing.T) {
+	beforeEachSendConfirmation := func() {
+		beforeEach()
+
+		mockEmailSend = mocker.NewMockCall(callEmailSend)
+		mockEmailSend.Return(nil)
+	}
+
+	mockPendingSubscriber := func() entity.Subscribers {
+		return entity.Subscribers{
+			Name:               "test",
+			Email:              "ajistestmail@gmail.com",
+			IsPending:          true,
+			ConfirmToken:       convert.ValueToStringPointer("token"),
+			ConfirmExpiredDate: convert.ValueToTimePointer(time.Now().Add(time.Hour)),
+		}
+	}
+
+	t.Run("should call email send with confirm link when call service send confirmation", func(t *testing.T) {
+		beforeEachSendConfirmation()
+
+		err := service.SendConfirmation(mockPendingSubscriber())
+
+		assert.Nil(t, err)
+		emailService.AssertCalled(t, "Send", mock.MatchedBy(func(content email.SentMailContent) bool {
+			return content.To == "ajistestmail@gmail.com" &&
+				strings.Contains(content.Body, config.ConfirmURL+"?token=token")
+		}))
+	})
+
+	t.Run("should return bad request when subscriber has no confirm token", func(t *testing.T) {
+		beforeEachSendConfirmation()
+
+		err := service.SendConfirmation(entity.Subscribers{Email: "ajistestmail@gmail.com"})
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.BadRequest)
+		assert.Equal(t, expectedError, err)
+		emailService.AssertNotCalled(t, "Send", mock.Anything)
+	})
+
+	t.Run("should return internal server error when email send failed", func(t *testing.T) {
+		beforeEachSendConfirmation()
+		mockEmailSend.Return(errors.New("Error"))
+
+		err := service.SendConfirmation(mockPendingSubscriber())
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+	})
+}
+
+func TestService_Confirm(t *testing.T) {
+	var confirmToken string
+
+	beforeEachConfirm := func() {
+		beforeEach()
+
+		expiredDate := time.Now().Add(time.Hour)
+		confirmToken = token.Generate(config.TokenSecret, subscribers.ConfirmTokenPurpose, "ajistestmail@gmail.com", &expiredDate)
+
+		mockServiceFindByConfirmToken = mocker.NewMockCall(callServiceFindByConfirmToken)
+		mockServiceFindByConfirmToken.Return(mockDataSubscribers(), nil)
+		mockServiceConfirmByEmail = mocker.NewMockCall(callServiceConfirmByEmail)
+		mockServiceConfirmByEmail.Return(nil)
+	}
+
+	t.Run("should return invalid token when token signature is invalid", func(t *testing.T) {
+		beforeEachConfirm()
+
+		err := service.Confirm(confirmToken + "invalid")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "FindByConfirmToken", mock.Anything)
+	})
+
+	t.Run("should return expired token when token is expired", func(t *testing.T) {
+		beforeEachConfirm()
+		expiredDate := time.Now().Add(-time.Hour)
+		expiredToken := token.Generate(config.TokenSecret, subscribers.ConfirmTokenPurpose, "ajistestmail@gmail.com", &expiredDate)
+
+		err := service.Confirm(expiredToken)
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.ExpiredToken)
+		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "FindByConfirmToken", mock.Anything)
+	})
+
+	t.Run("should call service find by confirm token when token is valid", func(t *testing.T) {
+		beforeEachConfirm()
+
+		service.Confirm(confirmToken)
+
+		mockUseCase.AssertCalled(t, "FindByConfirmToken", confirmToken)
+	})
+
+	t.Run("should return data not found when call service find by confirm token not found", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceFindByConfirmToken.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.Confirm(confirmToken)
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "ConfirmByEmail", mock.Anything)
+	})
+
+	t.Run("should call service confirm by email when found pending subscriber", func(t *testing.T) {
+		beforeEachConfirm()
+
+		err := service.Confirm(confirmToken)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "ConfirmByEmail", "ajistestmail@gmail.com")
+	})
+
+	t.Run("should return internal server error when call service confirm by email failed", func(t *testing.T) {
+		beforeEachConfirm()
+		mockServiceConfirmByEmail.Return(convert.ValueToErrorCodePointer(newsletterError.InternalServerError))
+
+		err := service.Confirm(confirmToken)
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+	})
+}
+
+func TestService_GenerateUnsubscribeURL(t *testing.T) {
+	t.Run("should return unsubscribe url with signed token of email", func(t *testing.T) {
+		beforeEach()
+
+		res := service.GenerateUnsubscribeURL("ajistestmail@gmail.com")
+
+		prefix := config.UnsubscribeURL + "/"
+		assert.True(t, strings.HasPrefix(res, prefix))
+		email, err := token.Verify(config.TokenSecret, subscribers.UnsubscribeTokenPurpose, strings.TrimPrefix(res, prefix))
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", email)
+	})
+}
+
+func TestService_VerifyUnsubscribeToken(t *testing.T) {
+	t.Run("should return email when unsubscribe token is valid", func(t *testing.T) {
+		beforeEach()
+		unsubscribeToken := token.Generate(config.TokenSecret, subscribers.UnsubscribeTokenPurpose, "ajistestmail@gmail.com", nil)
+
+		res, err := service.VerifyUnsubscribeToken(unsubscribeToken)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", res)
+	})
+
+	t.Run("should return invalid token when token is confirm token", func(t *testing.T) {
+		beforeEach()
+		confirmToken := token.Generate(config.TokenSecret, subscribers.ConfirmTokenPurpose, "ajistestmail@gmail.com", nil)
+
+		res, err := service.VerifyUnsubscribeToken(confirmToken)
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, "", res)
+	})
+}
+
+func TestService_GeneratePreferencesURL(t *testing.T) {
+	t.Run("should return preferences url with signed token of email", func(t *testing.T) {
+		beforeEach()
+
+		res := service.GeneratePreferencesURL("ajistestmail@gmail.com")
+
+		prefix := config.PreferencesURL + "/"
+		assert.True(t, strings.HasPrefix(res, prefix))
+		email, err := token.Verify(config.TokenSecret, subscribers.PreferencesTokenPurpose, strings.TrimPrefix(res, prefix))
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", email)
+	})
+}
+
+func TestService_VerifyPreferencesToken(t *testing.T) {
+	t.Run("should return email when preferences token is valid", func(t *testing.T) {
+		beforeEach()
+		preferencesToken := token.Generate(config.TokenSecret, subscribers.PreferencesTokenPurpose, "ajistestmail@gmail.com", nil)
+
+		res, err := service.VerifyPreferencesToken(preferencesToken)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", res)
+	})
+
+	t.Run("should return invalid token when token is unsubscribe token", func(t *testing.T) {
+		beforeEach()
+		unsubscribeToken := token.Generate(config.TokenSecret, subscribers.UnsubscribeTokenPurpose, "ajistestmail@gmail.com", nil)
+
+		res, err := service.VerifyPreferencesToken(unsubscribeToken)
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+		assert.Equal(t, expectedError, err)
+		assert.Equal(t, "", res)
+	})
+}
+
+func TestService_GetPreferences(t *testing.T) {
+	beforeEachGetPreferences := func() {
+		beforeEach()
+
+		mockServiceFindByEmail = mocker.NewMockCall(callServiceFindByEmail)
+		mockServiceFindByEmail.Return([]entity.Subscribers{{
+			ID:        7,
+			Email:     "ajistestmail@gmail.com",
+			Name:      "ajis",
+			Language:  convert.ValueToStringPointer("th"),
+			Frequency: subscribers.FrequencyWeekly,
+			TimeZone:  convert.ValueToStringPointer("Asia/Bangkok"),
+		}}, nil)
+		mockRepoFindListPreferences = mocker.NewMockCall(callRepoFindListPreferences)
+		mockRepoFindListPreferences.Return([]subscribers.ListPreference{{ID: 2, Name: "Weekly digest", IsSubscribed: true}}, nil)
+	}
+
+	t.Run("should return preferences with lists of subscriber", func(t *testing.T) {
+		beforeEachGetPreferences()
+
+		res, err := service.GetPreferences("ajistestmail@gmail.com")
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "FindListPreferences", int64(7))
+		assert.Equal(t, &subscribers.Preferences{
+			SubscriberID: 7,
+			Email:        "ajistestmail@gmail.com",
+			Name:         "ajis",
+			Language:     "th",
+			Frequency:    subscribers.FrequencyWeekly,
+			TimeZone:     "Asia/Bangkok",
+			Lists:        []subscribers.ListPreference{{ID: 2, Name: "Weekly digest", IsSubscribed: true}},
+		}, res)
+	})
+
+	t.Run("should leave language and time zone empty when subscriber has not picked one", func(t *testing.T) {
+		beforeEachGetPreferences()
+		mockServiceFindByEmail.Return([]entity.Subscribers{{ID: 7, Email: "ajistestmail@gmail.com"}}, nil)
+
+		res, err := service.GetPreferences("ajistestmail@gmail.com")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "", res.Language)
+		assert.Equal(t, "", res.TimeZone)
+	})
+
+	t.Run("should return data not found when subscriber does not exist", func(t *testing.T) {
+		beforeEachGetPreferences()
+		mockServiceFindByEmail.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		res, err := service.GetPreferences("ajistestmail@gmail.com")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when find list preferences failed", func(t *testing.T) {
+		beforeEachGetPreferences()
+		mockRepoFindListPreferences.Return(nil, errors.New("Error"))
+
+		res, err := service.GetPreferences("ajistestmail@gmail.com")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_UpdatePreferences(t *testing.T) {
+	current := subscribers.Preferences{
+		SubscriberID: 7,
+		Email:        "ajistestmail@gmail.com",
+		Name:         "ajis",
+		Frequency:    subscribers.FrequencyImmediate,
+		Lists: []subscribers.ListPreference{
+			{ID: 3, Name: "Promotions", IsSubscribed: true},
+			{ID: 2, Name: "Weekly digest"},
+		},
+	}
+	var logged chan mock.Arguments
+	beforeEachUpdatePreferences := func() {
+		beforeEach()
+		logged = make(chan mock.Arguments, 1)
+		logs.On("Info", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Run(func(args mock.Arguments) { logged <- args })
+
+		mockServiceGetPreferences = mocker.NewMockCall(callServiceGetPreferences)
+		mockServiceGetPreferences.Return(&current, nil)
+		mockRepoUpdatePreferencesByEmail = mocker.NewMockCall(callRepoUpdatePreferencesByEmail)
+		mockRepoUpdatePreferencesByEmail.Return(nil)
+		mockRepoUpdateListMemberships = mocker.NewMockCall(callRepoUpdateListMemberships)
+		mockRepoUpdateListMemberships.Return(nil)
+	}
+	updated := func() subscribers.Preferences {
+		return subscribers.Preferences{
+			Email:     "ajistestmail@gmail.com",
+			Name:      "ajis",
+			Language:  "th",
+			Frequency: subscribers.FrequencyWeekly,
+			TimeZone:  "Asia/Bangkok",
+			Lists: []subscribers.ListPreference{
+				{ID: 2, IsSubscribed: true},
+				{ID: 3},
+			},
+		}
+	}
+
+	t.Run("should save subscriber choices and chosen lists", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+
+		err := service.UpdatePreferences(updated())
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "UpdatePreferencesByEmail", entity.Subscribers{
+			Email:     "ajistestmail@gmail.com",
+			Name:      "ajis",
+			Language:  convert.ValueToStringPointer("th"),
+			Frequency: subscribers.FrequencyWeekly,
+			TimeZone:  convert.ValueToStringPointer("Asia/Bangkok"),
+		})
+		repository.AssertCalled(t, "UpdateListMemberships", int64(7), []int64{2})
+	})
+
+	t.Run("should log every changed field", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+
+		service.UpdatePreferences(updated())
+
+		select {
+		case args := <-logged:
+			assert.Equal(t, "subscribers_Service_UpdatePreferences", args.String(1))
+			assert.Equal(t, "ajistestmail@gmail.com", args.Get(2))
+			assert.Equal(t, []subscribers.PreferenceChange{
+				{Field: subscribers.LanguageField, From: "", To: "th"},
+				{Field: subscribers.FrequencyField, From: subscribers.FrequencyImmediate, To: subscribers.FrequencyWeekly},
+				{Field: subscribers.TimeZoneField, From: "", To: "Asia/Bangkok"},
+				{Field: subscribers.ListsField, From: []int64{3}, To: []int64{2}},
+			}, args.Get(3))
+		case <-time.After(time.Second):
+			t.Fatal("preference changes were not logged")
+		}
+	})
+
+	t.Run("should return error when get preferences failed", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		mockServiceGetPreferences.Return(nil, convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.UpdatePreferences(updated())
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+		repository.AssertNotCalled(t, "UpdatePreferencesByEmail", mock.Anything)
+	})
+
+	t.Run("should return internal server error when update preferences failed", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		mockRepoUpdatePreferencesByEmail.Return(errors.New("Error"))
+
+		err := service.UpdatePreferences(updated())
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		repository.AssertNotCalled(t, "UpdateListMemberships", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return internal server error when update list memberships failed", func(t *testing.T) {
+		beforeEachUpdatePreferences()
+		mockRepoUpdateListMemberships.Return(errors.New("Error"))
+
+		err := service.UpdatePreferences(updated())
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
+
+func TestService_UnsubscribeByToken(t *testing.T) {
+	beforeEachUnsubscribeByToken := func() {
+		beforeEach()
+
+		mockServiceVerifyUnsubscribeToken = mocker.NewMockCall(callServiceVerifyUnsubscribeToken)
+		mockServiceVerifyUnsubscribeToken.Return("ajistestmail@gmail.com", nil)
+		mockServiceUnsubscribe = mocker.NewMockCall(callServiceUnsubscribe)
+		mockServiceUnsubscribe.Return(nil)
+	}
+
+	t.Run("should return invalid token when call service verify unsubscribe token failed", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+		mockServiceVerifyUnsubscribeToken.Return("", convert.ValueToErrorCodePointer(newsletterError.InvalidToken))
+
+		err := service.UnsubscribeByToken("token")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.InvalidToken)
+		assert.Equal(t, expectedError, err)
+		mockUseCase.AssertNotCalled(t, "Unsubscribe", mock.Anything)
+	})
+
+	t.Run("should call service unsubscribe with email from token when token is valid", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+
+		err := service.UnsubscribeByToken("token")
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "Unsubscribe", entity.Subscribers{Email: "ajistestmail@gmail.com"})
+	})
+
+	t.Run("should return error when call service unsubscribe failed", func(t *testing.T) {
+		beforeEachUnsubscribeByToken()
+		mockServiceUnsubscribe.Return(convert.ValueToErrorCodePointer(newsletterError.DataNotFound))
+
+		err := service.UnsubscribeByToken("token")
+
+		expectedError := convert.ValueToErrorCodePointer(newsletterError.DataNotFound)
+		assert.Equal(t, expectedError, err)
+	})
+}
+
+func TestService_ExportSubscribers(t *testing.T) {
+	beforeEachExportSubscribers := func() {
+		beforeEach()
+
+		mockRepoFindSubscribers = mocker.NewMockCall(callRepoFindSubscribers)
+		mockRepoFindSubscribers.Return([]entity.Subscribers{{ID: 1}, {ID: 2}}, nil)
+	}
+
+	t.Run("should read by id cursor with filter conditions and ignore paging", func(t *testing.T) {
+		beforeEachExportSubscribers()
+
+		res, err := service.ExportSubscribers(subscribers.Filter{
+			Page:         3,
+			PageSize:     5,
+			IsSubscribed: convert.ValueToBoolPointer(true),
+			Name:         "som",
+			SortBy:       "name",
+			SortOrder:    subscribers.SortDesc,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{{ID: 1}, {ID: 2}}, res)
+		repository.AssertCalled(t, "FindSubscribers", subscribers.Filter{
+			Page:         1,
+			PageSize:     subscribers.ExportBatchSize,
+			IsSubscribed: convert.ValueToBoolPointer(true),
+			Name:         "som",
+			SortBy:       subscribers.DefaultSortBy,
+			SortOrder:    subscribers.SortAsc,
+		})
+	})
+
+	t.Run("should read next batch after last id when batch is full", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		fullBatch := make([]entity.Subscribers, subscribers.ExportBatchSize)
+		for i := range fullBatch {
+			fullBatch[i].ID = int64(i + 1)
+		}
+		mockRepoFindSubscribers.Return(fullBatch, nil).Once()
+		repository.On("FindSubscribers", mock.Anything).Return([]entity.Subscribers{{ID: 5000}}, nil)
+
+		res, err := service.ExportSubscribers(subscribers.Filter{})
+
+		assert.Nil(t, err)
+		assert.Len(t, res, subscribers.ExportBatchSize+1)
+		repository.AssertCalled(t, "FindSubscribers", mock.MatchedBy(func(filter subscribers.Filter) bool {
+			return filter.After != nil && *filter.After == int64(subscribers.ExportBatchSize)
+		}))
+	})
+
+	t.Run("should response bad request when subscribed range is reversed", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		from := time.Date(2023, 2, 1, 0, 0, 0, 0, time.UTC)
+		to := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+		res, err := service.ExportSubscribers(subscribers.Filter{SubscribedFrom: &from, SubscribedTo: &to})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.BadRequest), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "FindSubscribers", mock.Anything)
+	})
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachExportSubscribers()
+		mockRepoFindSubscribers.Return(nil, errors.New("Error"))
+
+		res, err := service.ExportSubscribers(subscribers.Filter{})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_DeleteByID(t *testing.T) {
+	beforeEachDeleteByID := func() {
+		beforeEach()
+
+		mockRepoDeleteByID = mocker.NewMockCall(callRepoDeleteByID)
+		mockRepoDeleteByID.Return(int64(1), nil)
+	}
+
+	t.Run("should delete subscriber by id", func(t *testing.T) {
+		beforeEachDeleteByID()
+
+		err := service.DeleteByID(1)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "DeleteByID", int64(1))
+	})
+
+	t.Run("should response data not found when nothing was deleted", func(t *testing.T) {
+		beforeEachDeleteByID()
+		mockRepoDeleteByID.Return(int64(0), nil)
+
+		err := service.DeleteByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.DataNotFound), err)
+	})
+
+	t.Run("should response internal server error when repository failed", func(t *testing.T) {
+		beforeEachDeleteByID()
+		mockRepoDeleteByID.Return(int64(0), errors.New("Error"))
+
+		err := service.DeleteByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.InternalServerError), err)
+	})
+}
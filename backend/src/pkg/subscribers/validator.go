@@ -0,0 +1,85 @@
+package subscribers
+
+import (
+	"newsletter/src/pkg/entity"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/validation"
+	"strings"
+
+	"github.com/thoas/go-funk"
+)
+
+const (
+	EmailField     = "email"
+	NameField      = "name"
+	LanguageField  = "language"
+	FrequencyField = "frequency"
+	TimeZoneField  = "timeZone"
+	ListsField     = "lists"
+)
+
+// ValidateSubscribe checks a subscribe payload and returns it with the email
+// normalized and the name trimmed, together with every field that failed.
+func ValidateSubscribe(subscriber entity.Subscribers) (entity.Subscribers, []newsletterError.FieldError) {
+	fieldErrors := []newsletterError.FieldError{}
+
+	email, errEmail := validation.Email(subscriber.Email)
+	if errEmail != nil {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: EmailField, Code: *errEmail})
+	}
+	subscriber.Email = email
+
+	name, errName := validation.RequiredText(subscriber.Name, validation.MaxLength)
+	if errName != nil {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: NameField, Code: *errName})
+	}
+	subscriber.Name = name
+
+	return subscriber, fieldErrors
+}
+
+// ValidateUnsubscribe checks an unsubscribe payload, which only needs an email.
+func ValidateUnsubscribe(subscriber entity.Subscribers) (entity.Subscribers, []newsletterError.FieldError) {
+	fieldErrors := []newsletterError.FieldError{}
+
+	email, errEmail := validation.Email(subscriber.Email)
+	if errEmail != nil {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: EmailField, Code: *errEmail})
+	}
+	subscriber.Email = email
+
+	return subscriber, fieldErrors
+}
+
+// ValidatePreferences checks the choices sent from the preference page and
+// returns them with the name trimmed and the language lowercased. The time
+// zone may be left empty, which clears it.
+func ValidatePreferences(preferences Preferences) (Preferences, []newsletterError.FieldError) {
+	fieldErrors := []newsletterError.FieldError{}
+
+	name, errName := validation.RequiredText(preferences.Name, validation.MaxLength)
+	if errName != nil {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: NameField, Code: *errName})
+	}
+	preferences.Name = name
+
+	preferences.Language = strings.ToLower(strings.TrimSpace(preferences.Language))
+	if !funk.ContainsString(newsletterError.Languages(), preferences.Language) {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: LanguageField, Code: newsletterError.FieldInvalidOption})
+	}
+
+	if !funk.ContainsString(Frequencies, preferences.Frequency) {
+		fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: FrequencyField, Code: newsletterError.FieldInvalidOption})
+	}
+
+	preferences.TimeZone = strings.TrimSpace(preferences.TimeZone)
+	if preferences.TimeZone != "" {
+		timeZone, errTimeZone := validation.TimeZone(preferences.TimeZone)
+		if errTimeZone != nil {
+			fieldErrors = append(fieldErrors, newsletterError.FieldError{Field: TimeZoneField, Code: *errTimeZone})
+		}
+		preferences.TimeZone = timeZone
+	}
+
+	return preferences, fieldErrors
+}
@@ -0,0 +1,81 @@
+package subscribers_test
+
+import (
+	"newsletter/src/pkg/entity"
+	subscribers "newsletter/src/pkg/subscribers"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidator_ValidateSubscribe(t *testing.T) {
+	t.Run("should normalize email and trim name when payload is valid", func(t *testing.T) {
+		res, fieldErrors := subscribers.ValidateSubscribe(entity.Subscribers{Email: " AjisTestMail@Gmail.com ", Name: " ajis "})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, entity.Subscribers{Email: "ajistestmail@gmail.com", Name: "ajis"}, res)
+	})
+
+	t.Run("should return error for every invalid field", func(t *testing.T) {
+		_, fieldErrors := subscribers.ValidateSubscribe(entity.Subscribers{Email: "ajistestmail", Name: strings.Repeat("a", 256)})
+
+		assert.Equal(t, []newsletterError.FieldError{
+			{Field: subscribers.EmailField, Code: newsletterError.FieldInvalidEmail},
+			{Field: subscribers.NameField, Code: newsletterError.FieldTooLong},
+		}, fieldErrors)
+	})
+
+	t.Run("should require name", func(t *testing.T) {
+		_, fieldErrors := subscribers.ValidateSubscribe(entity.Subscribers{Email: "ajistestmail@gmail.com"})
+
+		assert.Equal(t, []newsletterError.FieldError{
+			{Field: subscribers.NameField, Code: newsletterError.FieldRequired},
+		}, fieldErrors)
+	})
+}
+
+func TestValidator_ValidateUnsubscribe(t *testing.T) {
+	t.Run("should not require name when unsubscribe", func(t *testing.T) {
+		res, fieldErrors := subscribers.ValidateUnsubscribe(entity.Subscribers{Email: "AjisTestMail@Gmail.com"})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, "ajistestmail@gmail.com", res.Email)
+	})
+
+	t.Run("should require email when unsubscribe", func(t *testing.T) {
+		_, fieldErrors := subscribers.ValidateUnsubscribe(entity.Subscribers{})
+
+		assert.Equal(t, []newsletterError.FieldError{
+			{Field: subscribers.EmailField, Code: newsletterError.FieldRequired},
+		}, fieldErrors)
+	})
+}
+
+func TestValidator_ValidatePreferences(t *testing.T) {
+	t.Run("should trim name and lowercase language when preferences are valid", func(t *testing.T) {
+		res, fieldErrors := subscribers.ValidatePreferences(subscribers.Preferences{Name: " ajis ", Language: " TH ", Frequency: subscribers.FrequencyWeekly, TimeZone: " Asia/Bangkok "})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, subscribers.Preferences{Name: "ajis", Language: "th", Frequency: subscribers.FrequencyWeekly, TimeZone: "Asia/Bangkok"}, res)
+	})
+
+	t.Run("should allow time zone to be left empty", func(t *testing.T) {
+		res, fieldErrors := subscribers.ValidatePreferences(subscribers.Preferences{Name: "ajis", Language: "th", Frequency: subscribers.FrequencyWeekly, TimeZone: "  "})
+
+		assert.Empty(t, fieldErrors)
+		assert.Equal(t, "", res.TimeZone)
+	})
+
+	t.Run("should return error for every invalid field", func(t *testing.T) {
+		_, fieldErrors := subscribers.ValidatePreferences(subscribers.Preferences{Language: "xx", Frequency: "hourly", TimeZone: "Asia/Nowhere"})
+
+		assert.Equal(t, []newsletterError.FieldError{
+			{Field: subscribers.NameField, Code: newsletterError.FieldRequired},
+			{Field: subscribers.LanguageField, Code: newsletterError.FieldInvalidOption},
+			{Field: subscribers.FrequencyField, Code: newsletterError.FieldInvalidOption},
+			{Field: subscribers.TimeZoneField, Code: newsletterError.FieldInvalidTimeZone},
+		}, fieldErrors)
+	})
+}
@@ -0,0 +1,83 @@
+package blobstore_test
+
+import (
+	"newsletter/src/pkg/utils/blobstore"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBlobStore_NewKey(t *testing.T) {
+	t.Run("should return different hex keys", func(t *testing.T) {
+		first, errFirst := blobstore.NewKey()
+		second, errSecond := blobstore.NewKey()
+
+		assert.Nil(t, errFirst)
+		assert.Nil(t, errSecond)
+		assert.Len(t, first, 32)
+		assert.NotEqual(t, first, second)
+	})
+}
+
+func TestLocalStore_Put(t *testing.T) {
+	t.Run("should write blob into dir creating it when missing", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "blobs")
+		store := blobstore.NewLocalStore(dir)
+
+		err := store.Put("abc", []byte("content"))
+
+		assert.Nil(t, err)
+		data, _ := os.ReadFile(filepath.Join(dir, "abc"))
+		assert.Equal(t, []byte("content"), data)
+	})
+
+	t.Run("should return error when key leaves dir", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+
+		assert.NotNil(t, store.Put("../abc", []byte("content")))
+		assert.NotNil(t, store.Put(".hidden", []byte("content")))
+		assert.NotNil(t, store.Put("", []byte("content")))
+	})
+}
+
+func TestLocalStore_Get(t *testing.T) {
+	t.Run("should return blob put under key", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+		store.Put("abc", []byte("content"))
+
+		data, err := store.Get("abc")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("content"), data)
+	})
+
+	t.Run("should return not found when key holds nothing", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+
+		data, err := store.Get("abc")
+
+		assert.Equal(t, blobstore.ErrNotFound, err)
+		assert.Nil(t, data)
+	})
+}
+
+func TestLocalStore_Delete(t *testing.T) {
+	t.Run("should remove blob", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+		store.Put("abc", []byte("content"))
+
+		err := store.Delete("abc")
+
+		assert.Nil(t, err)
+		_, errGet := store.Get("abc")
+		assert.Equal(t, blobstore.ErrNotFound, errGet)
+	})
+
+	t.Run("should not return error when key holds nothing", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+
+		assert.Nil(t, store.Delete("abc"))
+	})
+}
@@ -0,0 +1,87 @@
+package blobstore
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// This file is kept identical, apart from the module name, in
+// backend/src/pkg/utils/blobstore and cmstool/src/pkg/utils/blobstore. Change
+// both copies together; a cmstool test fails when they drift apart.
+
+// LocalStore keeps each blob as a file in Dir. It suits a single instance;
+// several instances need Dir on a shared volume.
+type LocalStore struct {
+	Dir string
+}
+
+func NewLocalStore(dir string) *LocalStore {
+	return &LocalStore{
+		Dir: dir,
+	}
+}
+
+// Put writes to a temporary file first and renames it into place, so a
+// reader never sees half a blob.
+func (store *LocalStore) Put(key string, data []byte) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(store.Dir, 0o755); err != nil {
+		return err
+	}
+
+	file, err := os.CreateTemp(store.Dir, ".put-*")
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return os.Rename(file.Name(), path)
+}
+
+func (store *LocalStore) Get(key string) ([]byte, error) {
+	path, err := store.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	return data, err
+}
+
+// Delete removes the blob under key. A key that holds nothing is not an
+// error.
+func (store *LocalStore) Delete(key string) error {
+	path, err := store.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	return err
+}
+
+// path keeps every key inside Dir.
+func (store *LocalStore) path(key string) (string, error) {
+	if key == "" || strings.HasPrefix(key, ".") || strings.ContainsAny(key, `/\`) {
+		return "", errors.New("invalid blob key")
+	}
+	return filepath.Join(store.Dir, key), nil
+}
@@ -2,6 +2,7 @@ package error
 
 import (
 	"net/http"
+	"newsletter/src/pkg/utils/i18n"
 
 	"github.com/thoas/go-funk"
 )
@@ -61,6 +62,34 @@ const (
 	ExistUserName            ErrorCode = "EXIST_USER_NAME"
 	UserActiveFailed         ErrorCode = "USER_ACTIVE_FAILED"
 	SubPartnerHasAnInvoice   ErrorCode = "SUB_PARTNER_HAS_AN_INVOICE"
+	InvalidToken             ErrorCode = "INVALID_TOKEN"
+	ExpiredToken             ErrorCode = "EXPIRED_TOKEN"
+	InvalidCampaignStatus    ErrorCode = "INVALID_CAMPAIGN_STATUS"
+	CampaignAlreadySent      ErrorCode = "CAMPAIGN_ALREADY_SENT"
+	InvalidTemplate          ErrorCode = "INVALID_TEMPLATE"
+
+	CampaignDeliveryInProgress ErrorCode = "CAMPAIGN_DELIVERY_IN_PROGRESS"
+	ValidationFailed           ErrorCode = "VALIDATION_FAILED"
+
+	InvalidAPIKeyScope ErrorCode = "INVALID_API_KEY_SCOPE"
+	TooManyRequests    ErrorCode = "TOO_MANY_REQUESTS"
+	VerificationFailed ErrorCode = "VERIFICATION_FAILED"
+	InvalidList        ErrorCode = "INVALID_LIST"
+	InvalidSegment     ErrorCode = "INVALID_SEGMENT"
+	DuplicateFieldKey  ErrorCode = "DUPLICATE_FIELD_KEY"
+	AttachmentTooLarge ErrorCode = "ATTACHMENT_TOO_LARGE"
+	DuplicateContentID ErrorCode = "DUPLICATE_CONTENT_ID"
+
+	FieldRequired        ErrorCode = "FIELD_REQUIRED"
+	FieldTooLong         ErrorCode = "FIELD_TOO_LONG"
+	FieldInvalidEmail    ErrorCode = "FIELD_INVALID_EMAIL"
+	FieldInvalidOption   ErrorCode = "FIELD_INVALID_OPTION"
+	FieldInvalidType     ErrorCode = "FIELD_INVALID_TYPE"
+	FieldInvalidDate     ErrorCode = "FIELD_INVALID_DATE"
+	FieldInvalidTimeZone ErrorCode = "FIELD_INVALID_TIME_ZONE"
+	FieldUnknown         ErrorCode = "FIELD_UNKNOWN"
+	FieldInvalidRule     ErrorCode = "FIELD_INVALID_RULE"
+	FieldTooComplex      ErrorCode = "FIELD_TOO_COMPLEX"
 )
 
 var mappingMessage = map[ErrorCode]ErrorMessage{
@@ -214,11 +243,164 @@ var mappingMessage = map[ErrorCode]ErrorMessage{
 		EN:         "ไม่สามารถทำการลบช้อมูลลูกข่ายได้ เนื่องจากมีรายการคงค้างในระบบ",
 		TH:         "ไม่สามารถทำการลบช้อมูลลูกข่ายได้ เนื่องจากมีรายการคงค้างในระบบ",
 	},
+	InvalidToken: {
+		Code:       InvalidToken,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid token",
+		TH:         "โทเคนไม่ถูกต้อง",
+	},
+	ExpiredToken: {
+		Code:       ExpiredToken,
+		StatusCode: http.StatusGone,
+		EN:         "Token has expired",
+		TH:         "โทเคนหมดอายุแล้ว",
+	},
+	InvalidCampaignStatus: {
+		Code:       InvalidCampaignStatus,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid campaign status",
+		TH:         "สถานะแคมเปญไม่ถูกต้อง",
+	},
+	CampaignAlreadySent: {
+		Code:       CampaignAlreadySent,
+		StatusCode: http.StatusConflict,
+		EN:         "Campaign is already sending or sent",
+		TH:         "แคมเปญนี้กำลังส่งหรือส่งไปแล้ว",
+	},
+	InvalidTemplate: {
+		Code:       InvalidTemplate,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid template",
+		TH:         "เทมเพลตไม่ถูกต้อง",
+	},
+	CampaignDeliveryInProgress: {
+		Code:       CampaignDeliveryInProgress,
+		StatusCode: http.StatusConflict,
+		EN:         "Campaign delivery is in progress",
+		TH:         "แคมเปญกำลังอยู่ระหว่างการส่ง",
+	},
+	ValidationFailed: {
+		Code:       ValidationFailed,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Validation failed",
+		TH:         "ข้อมูลไม่ผ่านการตรวจสอบ",
+	},
+	InvalidAPIKeyScope: {
+		Code:       InvalidAPIKeyScope,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid API key scope",
+		TH:         "ขอบเขตสิทธิ์ของ API key ไม่ถูกต้อง",
+	},
+	TooManyRequests: {
+		Code:       TooManyRequests,
+		StatusCode: http.StatusTooManyRequests,
+		EN:         "Too many requests, please try again later",
+		TH:         "มีคำขอมากเกินไป กรุณาลองใหม่ภายหลัง",
+	},
+	VerificationFailed: {
+		Code:       VerificationFailed,
+		StatusCode: http.StatusForbidden,
+		EN:         "Verification failed, please try again",
+		TH:         "การยืนยันตัวตนไม่สำเร็จ กรุณาลองใหม่อีกครั้ง",
+	},
+	InvalidList: {
+		Code:       InvalidList,
+		StatusCode: http.StatusBadRequest,
+		EN:         "List does not exist",
+		TH:         "ไม่พบรายชื่อผู้รับที่เลือก",
+	},
+	InvalidSegment: {
+		Code:       InvalidSegment,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Segment does not exist",
+		TH:         "ไม่พบกลุ่มผู้รับที่เลือก",
+	},
+	DuplicateFieldKey: {
+		Code:       DuplicateFieldKey,
+		StatusCode: http.StatusConflict,
+		EN:         "A custom field with this key already exists",
+		TH:         "มีฟิลด์ที่ใช้คีย์นี้อยู่แล้ว",
+	},
+	AttachmentTooLarge: {
+		Code:       AttachmentTooLarge,
+		StatusCode: http.StatusRequestEntityTooLarge,
+		EN:         "Attachments are larger than allowed",
+		TH:         "ไฟล์แนบมีขนาดเกินกำหนด",
+	},
+	DuplicateContentID: {
+		Code:       DuplicateContentID,
+		StatusCode: http.StatusConflict,
+		EN:         "An inline image with this content ID already exists",
+		TH:         "มีรูปภาพในเนื้อหาที่ใช้ content ID นี้อยู่แล้ว",
+	},
+	FieldRequired: {
+		Code:       FieldRequired,
+		StatusCode: http.StatusBadRequest,
+		EN:         "This field is required",
+		TH:         "กรุณากรอกข้อมูลนี้",
+	},
+	FieldTooLong: {
+		Code:       FieldTooLong,
+		StatusCode: http.StatusBadRequest,
+		EN:         "This field is too long",
+		TH:         "ข้อมูลนี้ยาวเกินกำหนด",
+	},
+	FieldInvalidEmail: {
+		Code:       FieldInvalidEmail,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid email address",
+		TH:         "รูปแบบอีเมลไม่ถูกต้อง",
+	},
+	FieldInvalidOption: {
+		Code:       FieldInvalidOption,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Value is not one of the available options",
+		TH:         "ค่าที่เลือกไม่อยู่ในตัวเลือกที่กำหนด",
+	},
+	FieldInvalidType: {
+		Code:       FieldInvalidType,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Value has the wrong type for this field",
+		TH:         "ชนิดข้อมูลไม่ตรงกับที่ฟิลด์นี้กำหนด",
+	},
+	FieldInvalidDate: {
+		Code:       FieldInvalidDate,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Date must be in YYYY-MM-DD format",
+		TH:         "วันที่ต้องอยู่ในรูปแบบ YYYY-MM-DD",
+	},
+	FieldInvalidTimeZone: {
+		Code:       FieldInvalidTimeZone,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Time zone must be an IANA name like Asia/Bangkok",
+		TH:         "เขตเวลาต้องเป็นชื่อตาม IANA เช่น Asia/Bangkok",
+	},
+	FieldUnknown: {
+		Code:       FieldUnknown,
+		StatusCode: http.StatusBadRequest,
+		EN:         "This field is not defined",
+		TH:         "ไม่มีฟิลด์นี้ในระบบ",
+	},
+	FieldInvalidRule: {
+		Code:       FieldInvalidRule,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Rule must set exactly one of and, or, not, field or engagement, and groups cannot be empty",
+		TH:         "เงื่อนไขต้องระบุ and, or, not, field หรือ engagement เพียงอย่างเดียว และกลุ่มต้องไม่ว่าง",
+	},
+	FieldTooComplex: {
+		Code:       FieldTooComplex,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Rules are nested too deep or have too many conditions",
+		TH:         "เงื่อนไขซ้อนกันลึกเกินไปหรือมีจำนวนมากเกินไป",
+	},
 }
 
+// MapMessageError maps code to its status and a message in languageCode.
+// A message registered in an i18n catalog wins over the built-in text, and a
+// language without a message for code falls back to English.
 func MapMessageError(code ErrorCode, languageCode string) (statusCode int, e Error) {
 
-	if _, ok := mappingMessage[code]; !ok || !funk.ContainsString([]string{TH, EN}, languageCode) {
+	if _, ok := mappingMessage[code]; !ok {
 		e.Code = InternalServerError
 		statusCode = http.StatusInternalServerError
 		if languageCode == TH {
@@ -231,6 +413,11 @@ func MapMessageError(code ErrorCode, languageCode string) (statusCode int, e Err
 
 	statusCode = mappingMessage[code].StatusCode
 	e.Code = mappingMessage[code].Code
+	if message, ok := i18n.Lookup(languageCode, string(code)); ok {
+		e.Message = message
+		return
+	}
+
 	switch language := languageCode; language {
 	case TH:
 		e.Message = mappingMessage[code].TH
@@ -240,3 +427,38 @@ func MapMessageError(code ErrorCode, languageCode string) (statusCode int, e Err
 
 	return
 }
+
+// Languages returns the built-in languages followed by every language loaded
+// from an i18n catalog.
+func Languages() []string {
+	languages := []string{EN, TH}
+	for _, language := range i18n.Languages() {
+		if !funk.ContainsString(languages, language) {
+			languages = append(languages, language)
+		}
+	}
+	return languages
+}
+
+// FieldError describes why one field of a request payload was rejected.
+type FieldError struct {
+	Field   string    `json:"field"`
+	Code    ErrorCode `json:"code"`
+	Message string    `json:"message"`
+}
+
+// MapValidationError maps a ValidationFailed error and puts the field errors,
+// with their messages in the requested language, in Data.
+func MapValidationError(fieldErrors []FieldError, languageCode string) (statusCode int, e Error) {
+	statusCode, e = MapMessageError(ValidationFailed, languageCode)
+
+	data := make([]FieldError, 0, len(fieldErrors))
+	for _, fieldError := range fieldErrors {
+		_, message := MapMessageError(fieldError.Code, languageCode)
+		fieldError.Message = message.Message
+		data = append(data, fieldError)
+	}
+	e.Data = data
+
+	return
+}
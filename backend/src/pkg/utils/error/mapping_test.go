@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 
 	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/i18n"
 )
 
 func TestError_MappingErrorMessage(t *testing.T) {
@@ -43,3 +44,75 @@ func TestError_MappingErrorMessage(t *testing.T) {
 		assert.Equal(t, *expectedError, err)
 	})
 }
+
+func TestError_MappingErrorMessageCatalog(t *testing.T) {
+	t.Cleanup(i18n.Reset)
+
+	t.Run("should return message from catalog when language is loaded", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("ja", i18n.Catalog{string(newsletterError.Forbidden): "アクセスが拒否されました"})
+
+		statusCode, err := newsletterError.MapMessageError(newsletterError.Forbidden, "ja")
+
+		expectedError := newsletterError.NewError(newsletterError.Forbidden, "アクセスが拒否されました")
+		assert.Equal(t, http.StatusForbidden, statusCode)
+		assert.Equal(t, *expectedError, err)
+	})
+
+	t.Run("should prefer catalog message over built-in language", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("th", i18n.Catalog{string(newsletterError.Forbidden): "คุณไม่มีสิทธิ์"})
+
+		_, err := newsletterError.MapMessageError(newsletterError.Forbidden, "th")
+
+		assert.Equal(t, "คุณไม่มีสิทธิ์", err.Message)
+	})
+
+	t.Run("should fall back to en when catalog has no message for code", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("ja", i18n.Catalog{})
+
+		statusCode, err := newsletterError.MapMessageError(newsletterError.Forbidden, "ja")
+
+		expectedError := newsletterError.NewError(newsletterError.Forbidden, newsletterError.ForbiddenMessage)
+		assert.Equal(t, http.StatusForbidden, statusCode)
+		assert.Equal(t, *expectedError, err)
+	})
+
+	t.Run("should list built-in and loaded languages", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("ja", i18n.Catalog{})
+		i18n.Register("th", i18n.Catalog{})
+
+		assert.Equal(t, []string{"en", "th", "ja"}, newsletterError.Languages())
+	})
+}
+
+func TestError_MapValidationError(t *testing.T) {
+	fieldErrors := []newsletterError.FieldError{
+		{Field: "email", Code: newsletterError.FieldInvalidEmail},
+		{Field: "name", Code: newsletterError.FieldRequired},
+	}
+
+	t.Run("should return validation failed with field messages in en", func(t *testing.T) {
+		statusCode, err := newsletterError.MapValidationError(fieldErrors, "en")
+
+		expectedError := newsletterError.NewError(newsletterError.ValidationFailed, "Validation failed", []newsletterError.FieldError{
+			{Field: "email", Code: newsletterError.FieldInvalidEmail, Message: "Invalid email address"},
+			{Field: "name", Code: newsletterError.FieldRequired, Message: "This field is required"},
+		})
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, *expectedError, err)
+	})
+
+	t.Run("should return validation failed with field messages in th", func(t *testing.T) {
+		statusCode, err := newsletterError.MapValidationError(fieldErrors, "th")
+
+		expectedError := newsletterError.NewError(newsletterError.ValidationFailed, "ข้อมูลไม่ผ่านการตรวจสอบ", []newsletterError.FieldError{
+			{Field: "email", Code: newsletterError.FieldInvalidEmail, Message: "รูปแบบอีเมลไม่ถูกต้อง"},
+			{Field: "name", Code: newsletterError.FieldRequired, Message: "กรุณากรอกข้อมูลนี้"},
+		})
+		assert.Equal(t, http.StatusBadRequest, statusCode)
+		assert.Equal(t, *expectedError, err)
+	})
+}
@@ -0,0 +1,101 @@
+package i18n
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const CatalogPattern = "*.json"
+
+// Catalog maps a message key, such as an error code, to its text in one
+// language.
+type Catalog map[string]string
+
+var (
+	mutex    sync.RWMutex
+	catalogs = map[string]Catalog{}
+)
+
+// Register merges catalog into the messages of language. A key registered
+// again replaces the earlier text.
+func Register(language string, catalog Catalog) {
+	language = strings.ToLower(language)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := catalogs[language]; !ok {
+		catalogs[language] = Catalog{}
+	}
+	for key, message := range catalog {
+		catalogs[language][key] = message
+	}
+}
+
+// LoadDir registers every <language>.json file in dir, each holding a flat
+// object of key to message. A missing directory loads nothing.
+func LoadDir(dir string) error {
+	if dir == "" {
+		return nil
+	}
+
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		return nil
+	}
+
+	files, err := filepath.Glob(filepath.Join(dir, CatalogPattern))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return err
+		}
+
+		catalog := Catalog{}
+		if err := json.Unmarshal(content, &catalog); err != nil {
+			return err
+		}
+
+		Register(strings.TrimSuffix(filepath.Base(file), filepath.Ext(file)), catalog)
+	}
+
+	return nil
+}
+
+// Lookup returns the message registered for key in language.
+func Lookup(language, key string) (string, bool) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	message, ok := catalogs[strings.ToLower(language)][key]
+	return message, ok
+}
+
+// Languages returns the registered languages in alphabetical order.
+func Languages() []string {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	languages := make([]string, 0, len(catalogs))
+	for language := range catalogs {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+// Reset drops every registered catalog.
+func Reset() {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	catalogs = map[string]Catalog{}
+}
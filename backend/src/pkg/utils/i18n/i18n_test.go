@@ -0,0 +1,124 @@
+package i18n_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"newsletter/src/pkg/utils/i18n"
+)
+
+func TestI18n_Register(t *testing.T) {
+	t.Run("should look up registered message case insensitive on language", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("JA", i18n.Catalog{"FORBIDDEN": "アクセスが拒否されました"})
+
+		message, ok := i18n.Lookup("ja", "FORBIDDEN")
+
+		assert.True(t, ok)
+		assert.Equal(t, "アクセスが拒否されました", message)
+	})
+
+	t.Run("should merge catalogs registered for the same language", func(t *testing.T) {
+		i18n.Reset()
+		i18n.Register("ja", i18n.Catalog{"A": "a", "B": "b"})
+		i18n.Register("ja", i18n.Catalog{"B": "bb"})
+
+		a, _ := i18n.Lookup("ja", "A")
+		b, _ := i18n.Lookup("ja", "B")
+
+		assert.Equal(t, "a", a)
+		assert.Equal(t, "bb", b)
+	})
+
+	t.Run("should not find key of unregistered language", func(t *testing.T) {
+		i18n.Reset()
+
+		_, ok := i18n.Lookup("ja", "FORBIDDEN")
+
+		assert.False(t, ok)
+	})
+}
+
+func TestI18n_LoadDir(t *testing.T) {
+	t.Run("should register every json catalog by file name", func(t *testing.T) {
+		i18n.Reset()
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "ja.json"), []byte(`{"FORBIDDEN": "アクセスが拒否されました"}`), 0644)
+		os.WriteFile(filepath.Join(dir, "de.json"), []byte(`{"FORBIDDEN": "Verboten"}`), 0644)
+
+		err := i18n.LoadDir(dir)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{"de", "ja"}, i18n.Languages())
+		message, _ := i18n.Lookup("de", "FORBIDDEN")
+		assert.Equal(t, "Verboten", message)
+	})
+
+	t.Run("should load nothing when directory does not exist", func(t *testing.T) {
+		i18n.Reset()
+
+		err := i18n.LoadDir(filepath.Join(t.TempDir(), "missing"))
+
+		assert.Nil(t, err)
+		assert.Empty(t, i18n.Languages())
+	})
+
+	t.Run("should return error when catalog is not a flat json object", func(t *testing.T) {
+		i18n.Reset()
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "ja.json"), []byte(`["FORBIDDEN"]`), 0644)
+
+		err := i18n.LoadDir(dir)
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestI18n_ParseAcceptLanguage(t *testing.T) {
+	t.Run("should order ranges by quality and keep sent order on ties", func(t *testing.T) {
+		res := i18n.ParseAcceptLanguage("en;q=0.5, th-TH, ja;q=0.8, th")
+
+		assert.Equal(t, []string{"th-th", "th", "ja", "en"}, res)
+	})
+
+	t.Run("should drop ranges with zero or malformed quality", func(t *testing.T) {
+		res := i18n.ParseAcceptLanguage("th;q=0, ja;q=abc, de;q=1.5, en;q=0.1")
+
+		assert.Equal(t, []string{"en"}, res)
+	})
+
+	t.Run("should return empty list when header is empty", func(t *testing.T) {
+		assert.Empty(t, i18n.ParseAcceptLanguage(""))
+	})
+}
+
+func TestI18n_Negotiate(t *testing.T) {
+	supported := []string{"en", "th"}
+
+	t.Run("should return exact match of highest quality", func(t *testing.T) {
+		assert.Equal(t, "th", i18n.Negotiate("en;q=0.4, th;q=0.9", supported, "en"))
+	})
+
+	t.Run("should fall back from region to primary language", func(t *testing.T) {
+		assert.Equal(t, "th", i18n.Negotiate("th-TH", supported, "en"))
+	})
+
+	t.Run("should skip unsupported ranges", func(t *testing.T) {
+		assert.Equal(t, "th", i18n.Negotiate("ja, fr;q=0.9, th;q=0.1", supported, "en"))
+	})
+
+	t.Run("should return fallback when nothing matches", func(t *testing.T) {
+		assert.Equal(t, "en", i18n.Negotiate("ja, fr", supported, "en"))
+	})
+
+	t.Run("should return fallback for wildcard", func(t *testing.T) {
+		assert.Equal(t, "en", i18n.Negotiate("*", supported, "en"))
+	})
+
+	t.Run("should return fallback when header is empty", func(t *testing.T) {
+		assert.Equal(t, "en", i18n.Negotiate("", supported, "en"))
+	})
+}
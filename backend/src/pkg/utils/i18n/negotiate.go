@@ -0,0 +1,89 @@
+package i18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const wildcard = "*"
+
+type languageRange struct {
+	tag     string
+	quality float64
+}
+
+// ParseAcceptLanguage returns the language ranges of an Accept-Language
+// header, lowercased and ordered by quality. Ranges with the same quality
+// keep the order they were sent in, and ranges with q=0 or a malformed
+// q-value are dropped.
+func ParseAcceptLanguage(header string) []string {
+	ranges := []languageRange{}
+	for _, part := range strings.Split(header, ",") {
+		params := strings.Split(part, ";")
+		tag := strings.ToLower(strings.TrimSpace(params[0]))
+		if tag == "" {
+			continue
+		}
+
+		quality := 1.0
+		valid := true
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") {
+				continue
+			}
+			value, err := strconv.ParseFloat(strings.TrimPrefix(param, "q="), 64)
+			if err != nil || value < 0 || value > 1 {
+				valid = false
+				break
+			}
+			quality = value
+		}
+		if !valid || quality == 0 {
+			continue
+		}
+
+		ranges = append(ranges, languageRange{tag: tag, quality: quality})
+	}
+
+	sort.SliceStable(ranges, func(i, j int) bool {
+		return ranges[i].quality > ranges[j].quality
+	})
+
+	tags := make([]string, 0, len(ranges))
+	for _, languageRange := range ranges {
+		tags = append(tags, languageRange.tag)
+	}
+	return tags
+}
+
+// Negotiate picks the supported language that best matches an
+// Accept-Language header. A range matches a supported language exactly or
+// through its primary subtag, so "th-TH" falls back to "th". The wildcard
+// and a header matching nothing both resolve to fallback.
+func Negotiate(header string, supported []string, fallback string) string {
+	for _, tag := range ParseAcceptLanguage(header) {
+		if tag == wildcard {
+			return fallback
+		}
+
+		for candidate := tag; candidate != ""; candidate = parentTag(candidate) {
+			for _, language := range supported {
+				if strings.ToLower(language) == candidate {
+					return language
+				}
+			}
+		}
+	}
+
+	return fallback
+}
+
+func parentTag(tag string) string {
+	index := strings.LastIndex(tag, "-")
+	if index < 0 {
+		return ""
+	}
+	return tag[:index]
+}
@@ -132,7 +132,15 @@ func NewELK(config ELKConnect) (*ELK, error) {
 	}, nil
 }
 
+func (e *ELK) Info(endpoint, actionName string, request, message interface{}) {
+	e.write("Info", endpoint, actionName, request, message)
+}
+
 func (e *ELK) Error(endpoint, actionName string, request, message interface{}) {
+	e.write("Error", endpoint, actionName, request, message)
+}
+
+func (e *ELK) write(level, endpoint, actionName string, request, message interface{}) {
 	nowDate := time.Now().Format(DateFormat)
 	var stage string
 	if e.stage == "dev" {
@@ -172,7 +180,7 @@ func (e *ELK) Error(endpoint, actionName string, request, message interface{}) {
 		Timestamp:  time.Now(),
 		Endpoint:   endpoint,
 		ActionName: actionName,
-		Level:      "Error",
+		Level:      level,
 		Message:    strMessage,
 		Input:      strRequest,
 		Output:     "",
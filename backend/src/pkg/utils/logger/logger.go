@@ -1,5 +1,6 @@
 package logger
 
 type Logger interface {
+	Info(endpoint, actionName string, request, message interface{})
 	Error(endpoint, actionName string, request, message interface{})
 }
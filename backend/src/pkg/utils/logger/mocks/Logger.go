@@ -13,3 +13,8 @@ type Logger struct {
 func (_m *Logger) Error(endpoint string, actionName string, request interface{}, message interface{}) {
 	_m.Called(endpoint, actionName, request, message)
 }
+
+// Info provides a mock function with given fields: endpoint, actionName, request, message
+func (_m *Logger) Info(endpoint string, actionName string, request interface{}, message interface{}) {
+	_m.Called(endpoint, actionName, request, message)
+}
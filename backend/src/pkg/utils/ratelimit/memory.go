@@ -0,0 +1,82 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// sweepEvery is how many takes MemoryStore waits between dropping full
+// buckets, which keeps memory bounded by the keys seen in one period.
+const sweepEvery = 1000
+
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	fullAt  time.Time
+}
+
+// MemoryStore keeps buckets in process memory. Limits are per instance, so
+// running several instances multiplies the allowed rate.
+type MemoryStore struct {
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+	takes   int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: map[string]*bucket{},
+	}
+}
+
+func (store *MemoryStore) Take(key string, rate Rate, now time.Time) (bool, time.Duration, error) {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+
+	store.takes++
+	if store.takes%sweepEvery == 0 {
+		store.sweep(now)
+	}
+
+	burst := float64(rate.Requests)
+	interval := rate.interval()
+
+	current, ok := store.buckets[key]
+	if !ok {
+		current = &bucket{tokens: burst, updated: now}
+		store.buckets[key] = current
+	}
+
+	if elapsed := now.Sub(current.updated); elapsed > 0 {
+		current.tokens += float64(elapsed) / float64(interval)
+		if current.tokens > burst {
+			current.tokens = burst
+		}
+		current.updated = now
+	}
+
+	if current.tokens < 1 {
+		retryAfter := time.Duration((1 - current.tokens) * float64(interval))
+		return false, retryAfter, nil
+	}
+
+	current.tokens--
+	current.fullAt = now.Add(time.Duration((burst - current.tokens) * float64(interval)))
+	return true, 0, nil
+}
+
+// Len returns the number of buckets held.
+func (store *MemoryStore) Len() int {
+	store.mutex.Lock()
+	defer store.mutex.Unlock()
+	return len(store.buckets)
+}
+
+// sweep drops buckets that have refilled, as a missing bucket starts full.
+func (store *MemoryStore) sweep(now time.Time) {
+	for key, current := range store.buckets {
+		if !now.Before(current.fullAt) {
+			delete(store.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	ratelimit "newsletter/src/pkg/utils/ratelimit"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// Take provides a mock function with given fields: key, rate, now
+func (_m *Store) Take(key string, rate ratelimit.Rate, now time.Time) (bool, time.Duration, error) {
+	ret := _m.Called(key, rate, now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Take")
+	}
+
+	var r0 bool
+	var r1 time.Duration
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Rate, time.Time) (bool, time.Duration, error)); ok {
+		return rf(key, rate, now)
+	}
+	if rf, ok := ret.Get(0).(func(string, ratelimit.Rate, time.Time) bool); ok {
+		r0 = rf(key, rate, now)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string, ratelimit.Rate, time.Time) time.Duration); ok {
+		r1 = rf(key, rate, now)
+	} else {
+		r1 = ret.Get(1).(time.Duration)
+	}
+
+	if rf, ok := ret.Get(2).(func(string, ratelimit.Rate, time.Time) error); ok {
+		r2 = rf(key, rate, now)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
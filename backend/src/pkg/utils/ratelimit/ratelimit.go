@@ -0,0 +1,51 @@
+package ratelimit
+
+import "time"
+
+// Rate allows Requests per Period. Tokens refill evenly over Period, and a
+// caller that has been quiet for a whole Period may burst up to Requests.
+type Rate struct {
+	Requests int
+	Period   time.Duration
+}
+
+// interval is the time it takes to refill one token.
+func (rate Rate) interval() time.Duration {
+	return rate.Period / time.Duration(rate.Requests)
+}
+
+// Store keeps token buckets. Take must spend a token from the bucket for key
+// atomically, so a store shared between instances can replace MemoryStore
+// without changing the limiter.
+type Store interface {
+	// Take spends one token of the bucket for key at now. When the bucket is
+	// empty it returns false and how long until a token is available.
+	Take(key string, rate Rate, now time.Time) (allowed bool, retryAfter time.Duration, err error)
+}
+
+// Limiter applies one Rate to keys in its own namespace of Store, so limiters
+// for different routes can share a store.
+type Limiter struct {
+	Store  Store
+	Prefix string
+	Rate   Rate
+	Now    func() time.Time
+}
+
+func NewLimiter(store Store, prefix string, rate Rate) *Limiter {
+	return &Limiter{
+		Store:  store,
+		Prefix: prefix,
+		Rate:   rate,
+		Now:    time.Now,
+	}
+}
+
+// Allow spends a token for key. A limiter with no requests or period never
+// limits.
+func (limiter *Limiter) Allow(key string) (bool, time.Duration, error) {
+	if limiter.Rate.Requests <= 0 || limiter.Rate.Period <= 0 {
+		return true, 0, nil
+	}
+	return limiter.Store.Take(limiter.Prefix+":"+key, limiter.Rate, limiter.Now())
+}
@@ -0,0 +1,122 @@
+package ratelimit_test
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"newsletter/src/pkg/utils/ratelimit"
+	"newsletter/src/pkg/utils/ratelimit/mocks"
+)
+
+var start = time.Date(2022, 12, 15, 0, 0, 0, 0, time.UTC)
+
+func TestMemoryStore_Take(t *testing.T) {
+	rate := ratelimit.Rate{Requests: 3, Period: time.Minute}
+
+	t.Run("should allow a burst of requests then deny with time until next token", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+
+		for i := 0; i < 3; i++ {
+			allowed, _, _ := store.Take("ip", rate, start)
+			assert.True(t, allowed)
+		}
+		allowed, retryAfter, err := store.Take("ip", rate, start.Add(5*time.Second))
+
+		assert.Nil(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, 15*time.Second, retryAfter)
+	})
+
+	t.Run("should refill one token per interval", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		for i := 0; i < 3; i++ {
+			store.Take("ip", rate, start)
+		}
+
+		allowed, _, _ := store.Take("ip", rate, start.Add(20*time.Second))
+		assert.True(t, allowed)
+
+		allowed, _, _ = store.Take("ip", rate, start.Add(20*time.Second))
+		assert.False(t, allowed)
+	})
+
+	t.Run("should not refill above burst", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		store.Take("ip", rate, start)
+
+		later := start.Add(time.Hour)
+		for i := 0; i < 3; i++ {
+			allowed, _, _ := store.Take("ip", rate, later)
+			assert.True(t, allowed)
+		}
+		allowed, _, _ := store.Take("ip", rate, later)
+
+		assert.False(t, allowed)
+	})
+
+	t.Run("should keep separate bucket per key", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		for i := 0; i < 3; i++ {
+			store.Take("a", rate, start)
+		}
+
+		allowed, _, _ := store.Take("b", rate, start)
+
+		assert.True(t, allowed)
+	})
+
+	t.Run("should drop refilled buckets", func(t *testing.T) {
+		store := ratelimit.NewMemoryStore()
+		for i := 0; i < 999; i++ {
+			store.Take(strconv.Itoa(i), rate, start)
+		}
+		assert.Equal(t, 999, store.Len())
+
+		store.Take("last", rate, start.Add(time.Minute))
+
+		assert.Equal(t, 1, store.Len())
+	})
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	t.Run("should take from store under limiter prefix at limiter time", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.On("Take", mock.Anything, mock.Anything, mock.Anything).Return(false, time.Second, nil)
+		rate := ratelimit.Rate{Requests: 3, Period: time.Minute}
+		limiter := ratelimit.NewLimiter(store, "subscribe:ip", rate)
+		limiter.Now = func() time.Time { return start }
+
+		allowed, retryAfter, err := limiter.Allow("127.0.0.1")
+
+		store.AssertCalled(t, "Take", "subscribe:ip:127.0.0.1", rate, start)
+		assert.False(t, allowed)
+		assert.Equal(t, time.Second, retryAfter)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return store error", func(t *testing.T) {
+		store := &mocks.Store{}
+		store.On("Take", mock.Anything, mock.Anything, mock.Anything).Return(false, time.Duration(0), errors.New("Error"))
+		limiter := ratelimit.NewLimiter(store, "subscribe:ip", ratelimit.Rate{Requests: 3, Period: time.Minute})
+
+		_, _, err := limiter.Allow("127.0.0.1")
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should always allow when rate is not configured", func(t *testing.T) {
+		store := &mocks.Store{}
+		limiter := ratelimit.NewLimiter(store, "subscribe:ip", ratelimit.Rate{})
+
+		allowed, _, err := limiter.Allow("127.0.0.1")
+
+		assert.True(t, allowed)
+		assert.Nil(t, err)
+		store.AssertNotCalled(t, "Take", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
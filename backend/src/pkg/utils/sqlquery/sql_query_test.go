@@ -3,6 +3,7 @@ package sqlquery_test
 import (
 	"newsletter/src/pkg/utils/convert"
 	"newsletter/src/pkg/utils/sqlquery"
+	"strings"
 	"testing"
 	"time"
 
@@ -899,3 +900,198 @@ func TestSQLQuery_GenerateTransactionAndRollbackWithRepeatableRead(t *testing.T)
 		assert.Equal(t, expectedQuery, result)
 	})
 }
+
+func TestSQLQuery_Param(t *testing.T) {
+	t.Run("should return positional placeholder when input index", func(t *testing.T) {
+		assert.Equal(t, "@p1", sqlquery.Param(1))
+		assert.Equal(t, "@p12", sqlquery.Param(12))
+	})
+}
+
+func TestSQLQuery_ManipulateParams(t *testing.T) {
+	t.Run("should return empty args when input empty struct", func(t *testing.T) {
+		input := struct{}{}
+
+		result := sqlquery.ManipulateParams(input, []string{})
+
+		assert.Equal(t, []interface{}{}, result)
+	})
+
+	t.Run("should return values without ignore field and untagged field", func(t *testing.T) {
+		input := struct {
+			ID       int64  `sql:"id"`
+			Name     string `sql:"name"`
+			Skip     string `sql:"-"`
+			Untagged string
+		}{
+			ID:       1,
+			Name:     "Name",
+			Skip:     "Skip",
+			Untagged: "Untagged",
+		}
+
+		result := sqlquery.ManipulateParams(input, []string{"ID"})
+
+		assert.Equal(t, []interface{}{"Name"}, result)
+	})
+
+	t.Run("should return dereferenced value when input pointer and nil when pointer is nil", func(t *testing.T) {
+		dateTime, _ := time.Parse(time.RFC822, "01 Jan 21 10:00 UTC")
+		input := struct {
+			Name     *string    `sql:"name"`
+			LastName *string    `sql:"lastname"`
+			IsShow   *bool      `sql:"isShow"`
+			Date     *time.Time `sql:"date"`
+		}{
+			Name:   convert.ValueToStringPointer("Name"),
+			IsShow: convert.ValueToBoolPointer(true),
+			Date:   &dateTime,
+		}
+
+		result := sqlquery.ManipulateParams(input, []string{})
+
+		assert.Equal(t, []interface{}{"Name", nil, true, dateTime}, result)
+	})
+}
+
+func TestSQLQuery_GenerateQueryColumnParams(t *testing.T) {
+	t.Run("should return empty query when input empty struct", func(t *testing.T) {
+		input := struct{}{}
+
+		query, args := sqlquery.GenerateQueryColumnParams(input, []string{}, 0)
+
+		assert.Equal(t, "", query)
+		assert.Equal(t, []interface{}{}, args)
+	})
+
+	t.Run("should return placeholders and values when input struct multiple field", func(t *testing.T) {
+		input := struct {
+			ID       int64  `sql:"id"`
+			Name     string `sql:"name"`
+			LastName string `sql:"lastname"`
+			IsShow   bool   `sql:"isShow"`
+		}{
+			ID:       1,
+			Name:     "Name",
+			LastName: "LastName",
+			IsShow:   true,
+		}
+
+		query, args := sqlquery.GenerateQueryColumnParams(input, []string{"ID"}, 0)
+
+		assert.Equal(t, "@p1,@p2,@p3", query)
+		assert.Equal(t, []interface{}{"Name", "LastName", true}, args)
+	})
+
+	t.Run("should start placeholders after offset when input offset", func(t *testing.T) {
+		input := struct {
+			Name string `sql:"name"`
+		}{
+			Name: "Name",
+		}
+
+		query, args := sqlquery.GenerateQueryColumnParams(input, []string{}, 2)
+
+		assert.Equal(t, "@p3", query)
+		assert.Equal(t, []interface{}{"Name"}, args)
+	})
+
+	t.Run("should keep injection payload verbatim in args and out of query", func(t *testing.T) {
+		payloads := []string{
+			"x'); DROP TABLE TB_TRN_Subscribers;--",
+			"' OR '1'='1",
+			"O'Reilly",
+			"N'test'",
+			"\\'; SELECT 1; --",
+		}
+
+		for _, payload := range payloads {
+			input := struct {
+				Email string  `sql:"email"`
+				Name  *string `sql:"name"`
+			}{
+				Email: payload,
+				Name:  convert.ValueToStringPointer(payload),
+			}
+
+			query, args := sqlquery.GenerateQueryColumnParams(input, []string{}, 0)
+
+			assert.Equal(t, "@p1,@p2", query)
+			assert.Equal(t, []interface{}{payload, payload}, args)
+			assert.False(t, strings.Contains(query, payload))
+		}
+	})
+}
+
+func TestSQLQuery_GenerateQueryUpdateFieldParams(t *testing.T) {
+	t.Run("should return empty query when input empty struct", func(t *testing.T) {
+		input := struct{}{}
+
+		query, args := sqlquery.GenerateQueryUpdateFieldParams(input, []string{}, 0)
+
+		assert.Equal(t, "", query)
+		assert.Equal(t, []interface{}{}, args)
+	})
+
+	t.Run("should return assignments and values when input struct multiple field", func(t *testing.T) {
+		input := struct {
+			ID       int64   `sql:"id"`
+			Name     string  `sql:"name"`
+			LastName *string `sql:"lastname"`
+		}{
+			ID:   1,
+			Name: "Name",
+		}
+
+		query, args := sqlquery.GenerateQueryUpdateFieldParams(input, []string{"ID"}, 1)
+
+		assert.Equal(t, "[name] = @p2,[lastname] = @p3", query)
+		assert.Equal(t, []interface{}{"Name", nil}, args)
+	})
+
+	t.Run("should keep injection payload verbatim in args and out of query", func(t *testing.T) {
+		payload := "x', Delflag = 1 WHERE 1=1;--"
+		input := struct {
+			Name string `sql:"name"`
+		}{
+			Name: payload,
+		}
+
+		query, args := sqlquery.GenerateQueryUpdateFieldParams(input, []string{}, 0)
+
+		assert.Equal(t, "[name] = @p1", query)
+		assert.Equal(t, []interface{}{payload}, args)
+	})
+}
+
+func TestSQLQuery_GenerateQueryUpdateFieldIncludeParams(t *testing.T) {
+	t.Run("should return empty query when input include fields is empty", func(t *testing.T) {
+		input := struct {
+			Name string `sql:"name"`
+		}{
+			Name: "Name",
+		}
+
+		query, args := sqlquery.GenerateQueryUpdateFieldIncludeParams(input, []string{}, 0)
+
+		assert.Equal(t, "", query)
+		assert.Equal(t, []interface{}{}, args)
+	})
+
+	t.Run("should return assignments of include fields only", func(t *testing.T) {
+		input := struct {
+			ID       int64  `sql:"id"`
+			Name     string `sql:"name"`
+			LastName string `sql:"lastname"`
+		}{
+			ID:       1,
+			Name:     "Name",
+			LastName: "LastName",
+		}
+
+		query, args := sqlquery.GenerateQueryUpdateFieldIncludeParams(input, []string{"LastName"}, 0)
+
+		assert.Equal(t, "[lastname] = @p1", query)
+		assert.Equal(t, []interface{}{"LastName"}, args)
+	})
+}
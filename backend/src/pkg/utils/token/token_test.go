@@ -0,0 +1,73 @@
+package token_test
+
+import (
+	"newsletter/src/pkg/utils/convert"
+	"newsletter/src/pkg/utils/token"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	secret  = "secret"
+	purpose = "confirm"
+	subject = "ajistestmail@gmail.com"
+)
+
+func TestToken_Verify(t *testing.T) {
+
+	t.Run("should return subject when token is valid", func(t *testing.T) {
+		expiredDate := convert.ValueToTimePointer(time.Now().Add(time.Hour))
+		resToken := token.Generate(secret, purpose, subject, expiredDate)
+
+		res, err := token.Verify(secret, purpose, resToken)
+
+		assert.Nil(t, err)
+		assert.Equal(t, subject, res)
+	})
+
+	t.Run("should return subject when token has no expired date", func(t *testing.T) {
+		resToken := token.Generate(secret, purpose, subject, nil)
+
+		res, err := token.Verify(secret, purpose, resToken)
+
+		assert.Nil(t, err)
+		assert.Equal(t, subject, res)
+	})
+
+	t.Run("should return expired token when token is expired", func(t *testing.T) {
+		expiredDate := convert.ValueToTimePointer(time.Now().Add(-time.Hour))
+		resToken := token.Generate(secret, purpose, subject, expiredDate)
+
+		res, err := token.Verify(secret, purpose, resToken)
+
+		assert.Equal(t, token.ErrExpiredToken, err)
+		assert.Equal(t, "", res)
+	})
+
+	t.Run("should return invalid token when secret is different", func(t *testing.T) {
+		resToken := token.Generate("other", purpose, subject, nil)
+
+		res, err := token.Verify(secret, purpose, resToken)
+
+		assert.Equal(t, token.ErrInvalidToken, err)
+		assert.Equal(t, "", res)
+	})
+
+	t.Run("should return invalid token when purpose is different", func(t *testing.T) {
+		resToken := token.Generate(secret, "unsubscribe", subject, nil)
+
+		res, err := token.Verify(secret, purpose, resToken)
+
+		assert.Equal(t, token.ErrInvalidToken, err)
+		assert.Equal(t, "", res)
+	})
+
+	t.Run("should return invalid token when token is malformed", func(t *testing.T) {
+		res, err := token.Verify(secret, purpose, "malformed")
+
+		assert.Equal(t, token.ErrInvalidToken, err)
+		assert.Equal(t, "", res)
+	})
+}
@@ -0,0 +1,106 @@
+package validation
+
+import (
+	"net/mail"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	// The zone database is built in so time zones check the same on hosts
+	// without one installed.
+	_ "time/tzdata"
+
+	"golang.org/x/net/idna"
+)
+
+const (
+	// MaxLength matches the nvarchar(255) columns the payloads are stored in.
+	MaxLength = 255
+
+	// MaxEmailLength and MaxLocalPartLength are the SMTP limits from RFC 5321.
+	MaxEmailLength     = 254
+	MaxLocalPartLength = 64
+
+	// MaxTimeZoneLength fits the longest IANA zone names with room to spare.
+	MaxTimeZoneLength = 64
+)
+
+// RequiredText trims value and checks it is present and fits maxLength
+// characters.
+func RequiredText(value string, maxLength int) (string, *newsletterError.ErrorCode) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldRequired)
+	}
+
+	if utf8.RuneCountInString(value) > maxLength {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong)
+	}
+
+	return value, nil
+}
+
+// Email checks value is a bare RFC 5322 address and returns it trimmed and
+// lowercased. An internationalized domain is converted to its ASCII
+// (punycode) form without any DNS lookup, so the same mailbox is stored once
+// however it was typed.
+func Email(value string) (string, *newsletterError.ErrorCode) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldRequired)
+	}
+
+	if utf8.RuneCountInString(value) > MaxEmailLength {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong)
+	}
+
+	address, err := mail.ParseAddress(value)
+	if err != nil || address.Name != "" || strings.HasPrefix(value, "<") {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidEmail)
+	}
+
+	// String keeps the quotes a local part needs, which Address drops.
+	canonical := strings.TrimSuffix(strings.TrimPrefix(address.String(), "<"), ">")
+	at := strings.LastIndex(canonical, "@")
+	localPart, domain := canonical[:at], canonical[at+1:]
+	if len(localPart) > MaxLocalPartLength {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong)
+	}
+
+	asciiDomain, err := idna.Lookup.ToASCII(domain)
+	if err != nil || !strings.Contains(asciiDomain, ".") {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidEmail)
+	}
+
+	normalized := strings.ToLower(localPart) + "@" + strings.ToLower(asciiDomain)
+	if len(normalized) > MaxEmailLength {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong)
+	}
+
+	return normalized, nil
+}
+
+// TimeZone checks value names an IANA time zone, like Asia/Bangkok, and
+// returns it trimmed. "Local" is refused because it means the server's zone
+// rather than the subscriber's.
+func TimeZone(value string) (string, *newsletterError.ErrorCode) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldRequired)
+	}
+
+	if utf8.RuneCountInString(value) > MaxTimeZoneLength {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong)
+	}
+
+	if value == "Local" {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidTimeZone)
+	}
+	if _, err := time.LoadLocation(value); err != nil {
+		return value, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidTimeZone)
+	}
+
+	return value, nil
+}
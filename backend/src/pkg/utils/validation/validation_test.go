@@ -0,0 +1,118 @@
+package validation_test
+
+import (
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/validation"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidation_RequiredText(t *testing.T) {
+	t.Run("should return trimmed value when value is present", func(t *testing.T) {
+		value, err := validation.RequiredText("  ajis  ", validation.MaxLength)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "ajis", value)
+	})
+
+	t.Run("should return field required when value is blank", func(t *testing.T) {
+		_, err := validation.RequiredText(" \t ", validation.MaxLength)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldRequired), err)
+	})
+
+	t.Run("should count characters not bytes when check length", func(t *testing.T) {
+		_, errFit := validation.RequiredText(strings.Repeat("ก", 255), validation.MaxLength)
+		_, errLong := validation.RequiredText(strings.Repeat("ก", 256), validation.MaxLength)
+
+		assert.Nil(t, errFit)
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong), errLong)
+	})
+}
+
+func TestValidation_Email(t *testing.T) {
+	validEmails := map[string]string{
+		"ajistestmail@gmail.com":           "ajistestmail@gmail.com",
+		"  AjisTestMail@Gmail.COM  ":       "ajistestmail@gmail.com",
+		"first.last+tag@sub.example.co.th": "first.last+tag@sub.example.co.th",
+		`"john doe"@example.com`:           `"john doe"@example.com`,
+		"user@ตัวอย่าง.ไทย":                "user@xn--72c1a1bt4awk9o.xn--o3cw4h",
+		"user@BÜCHER.example":              "user@xn--bcher-kva.example",
+	}
+	for input, expected := range validEmails {
+		input, expected := input, expected
+		t.Run("should normalize valid email "+input, func(t *testing.T) {
+			value, err := validation.Email(input)
+
+			assert.Nil(t, err)
+			assert.Equal(t, expected, value)
+		})
+	}
+
+	invalidEmails := []string{
+		"plainaddress",
+		"@example.com",
+		"user@",
+		"user@@example.com",
+		"Ajis <ajistestmail@gmail.com>",
+		"<ajistestmail@gmail.com>",
+		"user@localhost",
+		"user@-example-.com",
+		"user name@example.com",
+		"user@exa mple.com",
+	}
+	for _, input := range invalidEmails {
+		input := input
+		t.Run("should return invalid email when email is "+input, func(t *testing.T) {
+			_, err := validation.Email(input)
+
+			assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidEmail), err)
+		})
+	}
+
+	t.Run("should return field required when email is blank", func(t *testing.T) {
+		_, err := validation.Email("  ")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldRequired), err)
+	})
+
+	t.Run("should return too long when local part is over 64 characters", func(t *testing.T) {
+		_, err := validation.Email(strings.Repeat("a", 65) + "@example.com")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong), err)
+	})
+
+	t.Run("should return too long when email is over 254 characters", func(t *testing.T) {
+		_, err := validation.Email("user@" + strings.Repeat("a", 60) + "." + strings.Repeat("b", 60) + "." + strings.Repeat("c", 60) + "." + strings.Repeat("d", 60) + "." + strings.Repeat("e", 60) + ".com")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldTooLong), err)
+	})
+}
+
+func TestValidation_TimeZone(t *testing.T) {
+	t.Run("should return trimmed zone when zone is an iana name", func(t *testing.T) {
+		value, err := validation.TimeZone(" Asia/Bangkok ")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "Asia/Bangkok", value)
+	})
+
+	t.Run("should return field required when zone is blank", func(t *testing.T) {
+		_, err := validation.TimeZone("  ")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldRequired), err)
+	})
+
+	invalidZones := []string{"Local", "Asia/Nowhere", "../etc/passwd", "+07:00"}
+	for _, input := range invalidZones {
+		input := input
+		t.Run("should return invalid time zone when zone is "+input, func(t *testing.T) {
+			_, err := validation.TimeZone(input)
+
+			assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.FieldInvalidTimeZone), err)
+		})
+	}
+}
@@ -0,0 +1,62 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	error "newsletter/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+
+	verification "newsletter/src/pkg/verification"
+)
+
+// Issuer is an autogenerated mock type for the Issuer type
+type Issuer struct {
+	mock.Mock
+}
+
+// Issue provides a mock function with no fields
+func (_m *Issuer) Issue() (*verification.Challenge, *error.ErrorCode) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Issue")
+	}
+
+	var r0 *verification.Challenge
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func() (*verification.Challenge, *error.ErrorCode)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *verification.Challenge); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*verification.Challenge)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() *error.ErrorCode); ok {
+		r1 = rf()
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewIssuer creates a new instance of Issuer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewIssuer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Issuer {
+	mock := &Issuer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,144 @@
+package verification
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"math/bits"
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/utils/token"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	ChallengePurpose = "challenge"
+	AlgorithmSHA256  = "sha256"
+
+	// maxNonceLength stops a client from making us hash a huge string.
+	maxNonceLength = 64
+	saltBytes      = 16
+)
+
+// Challenge is handed to a client, which must find a Nonce such that the
+// SHA-256 of Challenge followed by Nonce starts with Difficulty zero bits.
+type Challenge struct {
+	Challenge   string    `json:"challenge"`
+	Algorithm   string    `json:"algorithm"`
+	Difficulty  int       `json:"difficulty"`
+	ExpiredDate time.Time `json:"expiredDate"`
+}
+
+// Issuer hands out challenges.
+type Issuer interface {
+	Issue() (*Challenge, *newsletterError.ErrorCode)
+}
+
+// ProofOfWork issues self-contained challenges signed with Secret, so nothing
+// is stored until one is solved. A solved challenge is remembered until it
+// expires so it cannot be replayed for another submission.
+type ProofOfWork struct {
+	Secret     string
+	Difficulty int
+	TTL        time.Duration
+	Now        func() time.Time
+
+	mutex sync.Mutex
+	used  map[string]time.Time
+}
+
+func NewProofOfWork(secret string, difficulty int, ttl time.Duration) *ProofOfWork {
+	return &ProofOfWork{
+		Secret:     secret,
+		Difficulty: difficulty,
+		TTL:        ttl,
+		Now:        time.Now,
+		used:       map[string]time.Time{},
+	}
+}
+
+func (pow *ProofOfWork) Issue() (*Challenge, *newsletterError.ErrorCode) {
+	salt := make([]byte, saltBytes)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, convert.ValueToErrorCodePointer(newsletterError.InternalServerError)
+	}
+
+	expiredDate := pow.Now().Add(pow.TTL)
+	subject := hex.EncodeToString(salt) + ":" + strconv.Itoa(pow.Difficulty)
+
+	return &Challenge{
+		Challenge:   token.Generate(pow.Secret, ChallengePurpose, subject, &expiredDate),
+		Algorithm:   AlgorithmSHA256,
+		Difficulty:  pow.Difficulty,
+		ExpiredDate: expiredDate,
+	}, nil
+}
+
+// Verify checks the challenge is ours and unexpired, that the nonce solves it
+// at the difficulty it was issued with, and that it was not used before.
+// Challenges issued before the difficulty was raised are rejected.
+func (pow *ProofOfWork) Verify(submission Submission) *newsletterError.ErrorCode {
+	failed := convert.ValueToErrorCodePointer(newsletterError.VerificationFailed)
+
+	if submission.Nonce == "" || len(submission.Nonce) > maxNonceLength {
+		return failed
+	}
+
+	subject, err := token.Verify(pow.Secret, ChallengePurpose, submission.Challenge)
+	if err != nil {
+		return failed
+	}
+
+	separator := strings.LastIndex(subject, ":")
+	difficulty, err := strconv.Atoi(subject[separator+1:])
+	if separator < 0 || err != nil || difficulty < pow.Difficulty {
+		return failed
+	}
+
+	if LeadingZeroBits(submission.Challenge, submission.Nonce) < difficulty {
+		return failed
+	}
+
+	if !pow.markUsed(submission.Challenge) {
+		return failed
+	}
+
+	return nil
+}
+
+// LeadingZeroBits returns how many zero bits the SHA-256 of challenge
+// followed by nonce starts with.
+func LeadingZeroBits(challenge, nonce string) int {
+	sum := sha256.Sum256([]byte(challenge + nonce))
+	zeros := 0
+	for _, b := range sum {
+		if b != 0 {
+			return zeros + bits.LeadingZeros8(b)
+		}
+		zeros += 8
+	}
+	return zeros
+}
+
+// markUsed records challenge and reports whether it was new. Expired entries
+// are dropped on the way, as their token no longer verifies anyway.
+func (pow *ProofOfWork) markUsed(challenge string) bool {
+	pow.mutex.Lock()
+	defer pow.mutex.Unlock()
+
+	now := pow.Now()
+	for used, expiredDate := range pow.used {
+		if now.After(expiredDate) {
+			delete(pow.used, used)
+		}
+	}
+
+	if _, ok := pow.used[challenge]; ok {
+		return false
+	}
+	pow.used[challenge] = now.Add(pow.TTL)
+	return true
+}
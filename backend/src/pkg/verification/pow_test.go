@@ -0,0 +1,119 @@
+package verification_test
+
+import (
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/verification"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const secret = "secret"
+
+// solve brute forces a nonce, which is cheap at the difficulties used here.
+func solve(challenge string, difficulty int) string {
+	for i := 0; ; i++ {
+		nonce := strconv.Itoa(i)
+		if verification.LeadingZeroBits(challenge, nonce) >= difficulty {
+			return nonce
+		}
+	}
+}
+
+func TestProofOfWork_Issue(t *testing.T) {
+	t.Run("should issue a different challenge every time at configured difficulty", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 8, time.Minute)
+
+		first, err := pow.Issue()
+		second, _ := pow.Issue()
+
+		assert.Nil(t, err)
+		assert.NotEqual(t, first.Challenge, second.Challenge)
+		assert.Equal(t, verification.AlgorithmSHA256, first.Algorithm)
+		assert.Equal(t, 8, first.Difficulty)
+	})
+}
+
+func TestProofOfWork_Verify(t *testing.T) {
+	failed := convert.ValueToErrorCodePointer(newsletterError.VerificationFailed)
+
+	t.Run("should pass when nonce solves challenge", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 8, time.Minute)
+		challenge, _ := pow.Issue()
+
+		err := pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, 8)})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return verification failed when nonce does not solve challenge", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 8, time.Minute)
+		challenge, _ := pow.Issue()
+		nonce := "0"
+		for i := 0; verification.LeadingZeroBits(challenge.Challenge, nonce) >= 8; i++ {
+			nonce = strconv.Itoa(i)
+		}
+
+		err := pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: nonce})
+
+		assert.Equal(t, failed, err)
+	})
+
+	t.Run("should return verification failed when solved challenge is replayed", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 8, time.Minute)
+		challenge, _ := pow.Issue()
+		submission := verification.Submission{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, 8)}
+		pow.Verify(submission)
+
+		err := pow.Verify(submission)
+
+		assert.Equal(t, failed, err)
+	})
+
+	t.Run("should return verification failed when challenge was issued at lower difficulty", func(t *testing.T) {
+		easy := verification.NewProofOfWork(secret, 1, time.Minute)
+		challenge, _ := easy.Issue()
+		pow := verification.NewProofOfWork(secret, 8, time.Minute)
+
+		err := pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, 1)})
+
+		assert.Equal(t, failed, err)
+	})
+
+	t.Run("should return verification failed when challenge was signed with another secret", func(t *testing.T) {
+		other := verification.NewProofOfWork("other", 1, time.Minute)
+		challenge, _ := other.Issue()
+		pow := verification.NewProofOfWork(secret, 1, time.Minute)
+
+		err := pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, 1)})
+
+		assert.Equal(t, failed, err)
+	})
+
+	t.Run("should return verification failed when challenge expired", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 1, -time.Minute)
+		challenge, _ := pow.Issue()
+
+		err := pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: solve(challenge.Challenge, 1)})
+
+		assert.Equal(t, failed, err)
+	})
+
+	t.Run("should return verification failed when nonce is missing or too long", func(t *testing.T) {
+		pow := verification.NewProofOfWork(secret, 0, time.Minute)
+		challenge, _ := pow.Issue()
+
+		assert.Equal(t, failed, pow.Verify(verification.Submission{Challenge: challenge.Challenge}))
+		assert.Equal(t, failed, pow.Verify(verification.Submission{Challenge: challenge.Challenge, Nonce: string(make([]byte, 65))}))
+	})
+}
+
+func TestLeadingZeroBits(t *testing.T) {
+	t.Run("should count zero bits of sha256 of challenge and nonce", func(t *testing.T) {
+		// sha256("abc") starts with 0xba, so no leading zero bit.
+		assert.Equal(t, 0, verification.LeadingZeroBits("ab", "c"))
+	})
+}
@@ -0,0 +1,48 @@
+package verification
+
+import (
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"strings"
+)
+
+// Submission is what a public form sends besides its own fields to show it
+// was filled in by a person.
+type Submission struct {
+	// Challenge and Nonce answer a proof-of-work challenge.
+	Challenge string `json:"challenge"`
+	Nonce     string `json:"nonce"`
+
+	// Website is a honeypot: the form hides it from people, so only bots
+	// fill it in.
+	Website string `json:"website"`
+}
+
+// Verifier decides whether a submission came from a person. Implementations
+// return VerificationFailed to reject it.
+type Verifier interface {
+	Verify(submission Submission) *newsletterError.ErrorCode
+}
+
+// All passes a submission only when every verifier passes it, checking them
+// in order.
+type All []Verifier
+
+func (verifiers All) Verify(submission Submission) *newsletterError.ErrorCode {
+	for _, verifier := range verifiers {
+		if err := verifier.Verify(submission); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Honeypot rejects submissions that filled in the hidden Website field.
+type Honeypot struct{}
+
+func (Honeypot) Verify(submission Submission) *newsletterError.ErrorCode {
+	if strings.TrimSpace(submission.Website) != "" {
+		return convert.ValueToErrorCodePointer(newsletterError.VerificationFailed)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package verification_test
+
+import (
+	"newsletter/src/pkg/utils/convert"
+	newsletterError "newsletter/src/pkg/utils/error"
+	"newsletter/src/pkg/verification"
+	"newsletter/src/pkg/verification/mocks"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestHoneypot_Verify(t *testing.T) {
+	t.Run("should pass when website is empty", func(t *testing.T) {
+		err := verification.Honeypot{}.Verify(verification.Submission{Website: "  "})
+
+		assert.Nil(t, err)
+	})
+
+	t.Run("should return verification failed when website is filled in", func(t *testing.T) {
+		err := verification.Honeypot{}.Verify(verification.Submission{Website: "http://spam.example"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.VerificationFailed), err)
+	})
+}
+
+func TestAll_Verify(t *testing.T) {
+	submission := verification.Submission{Nonce: "1"}
+
+	t.Run("should pass when every verifier passes", func(t *testing.T) {
+		first := &mocks.Verifier{}
+		first.On("Verify", mock.Anything).Return(nil)
+		second := &mocks.Verifier{}
+		second.On("Verify", mock.Anything).Return(nil)
+
+		err := verification.All{first, second}.Verify(submission)
+
+		assert.Nil(t, err)
+		first.AssertCalled(t, "Verify", submission)
+		second.AssertCalled(t, "Verify", submission)
+	})
+
+	t.Run("should stop at first verifier that fails", func(t *testing.T) {
+		first := &mocks.Verifier{}
+		first.On("Verify", mock.Anything).Return(convert.ValueToErrorCodePointer(newsletterError.VerificationFailed))
+		second := &mocks.Verifier{}
+
+		err := verification.All{first, second}.Verify(submission)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(newsletterError.VerificationFailed), err)
+		second.AssertNotCalled(t, "Verify", mock.Anything)
+	})
+
+	t.Run("should pass when there is no verifier", func(t *testing.T) {
+		assert.Nil(t, verification.All{}.Verify(submission))
+	})
+}
@@ -3,18 +3,40 @@ package router
 import (
 	"database/sql"
 	"fmt"
+	"log"
 	"net/http"
 	"newsletter/src/api/middleware"
 	"os"
+	"strconv"
+	"time"
 
 	"newsletter/src/cmd/config"
 
+	"newsletter/src/pkg/apikeys"
+	"newsletter/src/pkg/attachments"
+	"newsletter/src/pkg/auth"
+	campaigns "newsletter/src/pkg/campaigns"
+	"newsletter/src/pkg/customfields"
+	deliveries "newsletter/src/pkg/deliveries"
+	"newsletter/src/pkg/email"
+	lists "newsletter/src/pkg/lists"
+	"newsletter/src/pkg/segments"
 	subscribers "newsletter/src/pkg/subscribers"
+	"newsletter/src/pkg/utils/blobstore"
+	"newsletter/src/pkg/utils/i18n"
 	"newsletter/src/pkg/utils/logger"
+	"newsletter/src/pkg/utils/ratelimit"
+	"newsletter/src/pkg/verification"
 
+	apiKeysHandler "newsletter/src/api/apikeys/handler"
+	campaignsHandler "newsletter/src/api/campaigns/handler"
+	customFieldsHandler "newsletter/src/api/customfields/handler"
+	listsHandler "newsletter/src/api/lists/handler"
+	segmentsHandler "newsletter/src/api/segments/handler"
 	subscribersHandler "newsletter/src/api/subscribers/handler"
 
 	"github.com/gorilla/mux"
+	"gopkg.in/gomail.v2"
 
 	httpSwagger "github.com/swaggo/http-swagger"
 )
@@ -22,40 +44,282 @@ import (
 const (
 	defaultPort   = "8000"
 	defaultAppEnv = "LOCAL"
+
+	defaultConfirmTTL     = 24 * time.Hour
+	defaultPendingCleanup = time.Hour
 )
 
+// RouterConfig holds what InitRouter wires up. Background jobs it starts run
+// until Stop is closed.
 type RouterConfig struct {
 	DB     *sql.DB
 	Logs   *logger.ELK
 	Config config.Configuration
+	Stop   <-chan struct{}
 }
 
 func InitRouter(routerConfig RouterConfig) http.Handler {
 
 	/* Repository */
-	subscribersRepository := subscribers.NewRepository("TB_TRN_Subscribers", routerConfig.DB, routerConfig.Logs)
+	subscribersRepository := subscribers.NewRepository("TB_TRN_Subscribers", "TB_MAS_Lists", "TB_TRN_SubscriberLists", routerConfig.DB, routerConfig.Logs)
+	campaignsRepository := campaigns.NewRepository("TB_TRN_Campaigns", routerConfig.DB, routerConfig.Logs)
+	deliveriesRepository := deliveries.NewRepository("TB_TRN_Deliveries", routerConfig.DB, routerConfig.Logs)
+	usersRepository := auth.NewRepository("TB_MAS_Users", routerConfig.DB, routerConfig.Logs)
+	apiKeysRepository := apikeys.NewRepository("TB_MAS_ApiKeys", routerConfig.DB, routerConfig.Logs)
+	listsRepository := lists.NewRepository("TB_MAS_Lists", "TB_TRN_SubscriberLists", "TB_TRN_Subscribers", routerConfig.DB, routerConfig.Logs)
+	customFieldsRepository := customfields.NewRepository("TB_MAS_CustomFields", "TB_TRN_SubscriberFieldValues", routerConfig.DB, routerConfig.Logs)
+	segmentsRepository := segments.NewRepository("TB_MAS_Segments", "TB_TRN_Subscribers", routerConfig.DB, routerConfig.Logs)
+	attachmentsRepository := attachments.NewRepository("TB_TRN_CampaignAttachments", routerConfig.DB, routerConfig.Logs)
 
 	/* Service */
-	subscribersService := subscribers.NewService(subscribersRepository, routerConfig.Logs)
+	mailPort, _ := strconv.Atoi(routerConfig.Config.MailPort)
+	dialerMailServer := gomail.NewDialer(
+		routerConfig.Config.MailHost,
+		mailPort,
+		routerConfig.Config.MailUsername,
+		routerConfig.Config.MailPassword,
+	)
+	mailMaxAttempts, _ := strconv.Atoi(routerConfig.Config.MailMaxAttempts)
+	mailRetryBaseDelay, _ := time.ParseDuration(routerConfig.Config.MailRetryBaseDelay)
+	mailRetryMaxDelay, _ := time.ParseDuration(routerConfig.Config.MailRetryMaxDelay)
+	mailRetryConfig := email.RetryConfig{
+		MaxAttempts: mailMaxAttempts,
+		BaseDelay:   mailRetryBaseDelay,
+		MaxDelay:    mailRetryMaxDelay,
+	}
+	emailService := email.NewService(dialerMailServer, routerConfig.Config.MailSender, mailRetryConfig, routerConfig.Logs)
+
+	confirmTTL, errConfirmTTL := time.ParseDuration(routerConfig.Config.ConfirmTTL)
+	if errConfirmTTL != nil {
+		confirmTTL = defaultConfirmTTL
+	}
+	subscribersServiceConfig := subscribers.ServiceConfig{
+		TokenSecret:    routerConfig.Config.TokenSecret,
+		ConfirmTTL:     confirmTTL,
+		ConfirmURL:     routerConfig.Config.APIURL + "/subscribers/confirm",
+		UnsubscribeURL: routerConfig.Config.APIURL + "/subscribers/unsubscribe",
+		PreferencesURL: routerConfig.Config.APIURL + "/preferences",
+	}
+	if err := subscribersServiceConfig.Validate(); err != nil {
+		log.Fatalf("subscribers config: %v, set TOKEN_SECRET", err)
+	}
+	customFieldsService := customfields.NewService(customFieldsRepository, routerConfig.Logs)
+	subscribersService := subscribers.NewService(subscribersRepository, customFieldsService, emailService, subscribersServiceConfig, routerConfig.Logs)
+	templateRenderer, errTemplateRenderer := email.NewRenderer(routerConfig.Config.TemplateDir)
+	if errTemplateRenderer != nil {
+		log.Fatalf("load email templates: %v", errTemplateRenderer)
+	}
+	listsService := lists.NewService(listsRepository, subscribersService, emailService, lists.ServiceConfig{
+		TokenSecret: routerConfig.Config.TokenSecret,
+		ConfirmTTL:  confirmTTL,
+		ConfirmURL:  routerConfig.Config.APIURL + "/lists/confirm",
+	}, routerConfig.Logs)
+	deliveriesService := deliveries.NewService(deliveriesRepository, routerConfig.Logs)
+	segmentsCompiler := segments.NewCompiler("TB_TRN_SubscriberFieldValues", "TB_TRN_Deliveries")
+	segmentsService := segments.NewService(segmentsRepository, customFieldsService, segmentsCompiler, routerConfig.Logs)
+	pendingCleanup, errPendingCleanup := time.ParseDuration(routerConfig.Config.PendingCleanupInterval)
+	if errPendingCleanup != nil || pendingCleanup <= 0 {
+		pendingCleanup = defaultPendingCleanup
+	}
+	go subscribers.NewCleanup(subscribersService, pendingCleanup, routerConfig.Logs).Run(routerConfig.Stop)
+	attachmentMaxSize, _ := strconv.ParseInt(routerConfig.Config.AttachmentMaxSize, 10, 64)
+	attachmentMaxTotalSize, _ := strconv.ParseInt(routerConfig.Config.AttachmentMaxTotalSize, 10, 64)
+	attachmentsLimits := attachments.Limits{
+		MaxFileSize:  attachmentMaxSize,
+		MaxTotalSize: attachmentMaxTotalSize,
+	}
+	blobStore := blobstore.NewLocalStore(routerConfig.Config.BlobDir)
+	attachmentsService := attachments.NewService(attachmentsRepository, blobStore, attachmentsLimits, routerConfig.Logs)
+	campaignsService := campaigns.NewService(campaignsRepository, listsService, segmentsService, deliveriesService, attachmentsService, templateRenderer, routerConfig.Logs)
+
+	authServiceConfig := auth.ServiceConfig{
+		Algorithm: routerConfig.Config.JWTAlgorithm,
+		Secret:    []byte(routerConfig.Config.JWTSecret),
+		Issuer:    routerConfig.Config.JWTIssuer,
+		Audience:  routerConfig.Config.JWTAudience,
+	}
+	if routerConfig.Config.JWTPublicKeyFile != "" {
+		publicKey, errPublicKey := auth.LoadPublicKey(routerConfig.Config.JWTPublicKeyFile)
+		if errPublicKey != nil {
+			log.Fatalf("load jwt public key: %v", errPublicKey)
+		}
+		authServiceConfig.PublicKey = publicKey
+	}
+	authService := auth.NewService(usersRepository, authServiceConfig, routerConfig.Logs)
+	apiKeysService := apikeys.NewService(apiKeysRepository, routerConfig.Logs)
+
+	if err := i18n.LoadDir(routerConfig.Config.LocaleDir); err != nil {
+		log.Fatalf("load message catalogs: %v", err)
+	}
+
+	/* Verification */
+	verifiers := verification.All{}
+	var challenges verification.Issuer
+	if honeypot, _ := strconv.ParseBool(routerConfig.Config.VerificationHoneypot); honeypot {
+		verifiers = append(verifiers, verification.Honeypot{})
+	}
+	if powDifficulty, _ := strconv.Atoi(routerConfig.Config.VerificationPoWBits); powDifficulty > 0 {
+		powTTL, _ := time.ParseDuration(routerConfig.Config.VerificationPoWTTL)
+		proofOfWork := verification.NewProofOfWork(routerConfig.Config.TokenSecret, powDifficulty, powTTL)
+		verifiers = append(verifiers, proofOfWork)
+		challenges = proofOfWork
+	}
 
 	/* Handler */
 	subscribersHandlerParam := subscribersHandler.HandlerParam{
-		Service: subscribersService,
-		Logs:    routerConfig.Logs,
+		Service:    subscribersService,
+		Fields:     customFieldsService,
+		Verifier:   verifiers,
+		Challenges: challenges,
+		Logs:       routerConfig.Logs,
 	}
 	subscribersHandler := subscribersHandler.MakeSubscribersHandler(subscribersHandlerParam)
 
+	campaignsHandlerParam := campaignsHandler.HandlerParam{
+		Service:           campaignsService,
+		MaxAttachmentSize: attachmentMaxSize,
+		Logs:              routerConfig.Logs,
+	}
+	campaignsHandler := campaignsHandler.MakeCampaignsHandler(campaignsHandlerParam)
+
+	apiKeysHandlerParam := apiKeysHandler.HandlerParam{
+		Service: apiKeysService,
+		Logs:    routerConfig.Logs,
+	}
+	apiKeysHandler := apiKeysHandler.MakeAPIKeysHandler(apiKeysHandlerParam)
+
+	listsHandlerParam := listsHandler.HandlerParam{
+		Service:  listsService,
+		Fields:   customFieldsService,
+		Verifier: verifiers,
+		Logs:     routerConfig.Logs,
+	}
+	listsHandler := listsHandler.MakeListsHandler(listsHandlerParam)
+
+	customFieldsHandlerParam := customFieldsHandler.HandlerParam{
+		Service: customFieldsService,
+		Logs:    routerConfig.Logs,
+	}
+	customFieldsHandler := customFieldsHandler.MakeCustomFieldsHandler(customFieldsHandlerParam)
+
+	segmentsHandlerParam := segmentsHandler.HandlerParam{
+		Service: segmentsService,
+		Logs:    routerConfig.Logs,
+	}
+	segmentsHandler := segmentsHandler.MakeSegmentsHandler(segmentsHandlerParam)
+
+	rateLimitStore := ratelimit.NewMemoryStore()
+	rateLimitIPRequests, _ := strconv.Atoi(routerConfig.Config.RateLimitIPRequests)
+	rateLimitIPPeriod, _ := time.ParseDuration(routerConfig.Config.RateLimitIPPeriod)
+	rateLimitIPRate := ratelimit.Rate{Requests: rateLimitIPRequests, Period: rateLimitIPPeriod}
+	rateLimitEmailRequests, _ := strconv.Atoi(routerConfig.Config.RateLimitEmailRequests)
+	rateLimitEmailPeriod, _ := time.ParseDuration(routerConfig.Config.RateLimitEmailPeriod)
+	rateLimitEmailRate := ratelimit.Rate{Requests: rateLimitEmailRequests, Period: rateLimitEmailPeriod}
+	rateLimitTrustProxy, _ := strconv.ParseBool(routerConfig.Config.RateLimitTrustProxy)
+	subscribeRateLimitConfig := middleware.RateLimitConfig{
+		ByIP:              ratelimit.NewLimiter(rateLimitStore, "subscribe:ip", rateLimitIPRate),
+		ByEmail:           ratelimit.NewLimiter(rateLimitStore, "subscribe:email", rateLimitEmailRate),
+		TrustForwardedFor: rateLimitTrustProxy,
+	}
+	unsubscribeRateLimitConfig := middleware.RateLimitConfig{
+		ByIP:              ratelimit.NewLimiter(rateLimitStore, "unsubscribe:ip", rateLimitIPRate),
+		ByEmail:           ratelimit.NewLimiter(rateLimitStore, "unsubscribe:email", rateLimitEmailRate),
+		TrustForwardedFor: rateLimitTrustProxy,
+		Subject:           middleware.TokenSubject(subscribersService.VerifyUnsubscribeToken),
+	}
+	unsubscribePageRateLimitConfig := middleware.RateLimitConfig{
+		ByIP:              ratelimit.NewLimiter(rateLimitStore, "unsubscribe-page:ip", rateLimitIPRate),
+		TrustForwardedFor: rateLimitTrustProxy,
+	}
+
 	/* Router */
-	middleware := middleware.NewMiddleware(routerConfig.Logs)
+	middleware := middleware.NewMiddleware(authService, apiKeysService, routerConfig.Logs)
 	router := mux.NewRouter()
-	router.Use(middleware.Recover)
+	router.Use(middleware.Language, middleware.Recover)
 	router.HandleFunc("/version", versionHandler)
 	router.PathPrefix("/swagger").Handler(httpSwagger.WrapHandler)
 
+	canReadSubscribers := middleware.RequirePermission(auth.PermissionSubscribersRead)
+	canExportSubscribers := middleware.RequirePermission(auth.PermissionSubscribersExport)
+	canDeleteSubscribers := middleware.RequirePermission(auth.PermissionSubscribersDelete)
+	canReadCampaigns := middleware.RequirePermission(auth.PermissionCampaignsRead)
+	canWriteCampaigns := middleware.RequirePermission(auth.PermissionCampaignsWrite)
+	canSendCampaigns := middleware.RequirePermission(auth.PermissionCampaignsSend)
+	canReadLists := middleware.RequirePermission(auth.PermissionListsRead)
+	canWriteLists := middleware.RequirePermission(auth.PermissionListsWrite)
+	canReadFields := middleware.RequirePermission(auth.PermissionFieldsRead)
+	canWriteFields := middleware.RequirePermission(auth.PermissionFieldsWrite)
+	canReadSegments := middleware.RequirePermission(auth.PermissionSegmentsRead)
+	canWriteSegments := middleware.RequirePermission(auth.PermissionSegmentsWrite)
+	canManageAPIKeys := middleware.RequirePermission(auth.PermissionAPIKeysManage)
+	subscribeAPIKey := middleware.APIKey(auth.PermissionSubscribersSubscribe)
+	subscribeRateLimit := middleware.RateLimit(subscribeRateLimitConfig)
+	unsubscribeRateLimit := middleware.RateLimit(unsubscribeRateLimitConfig)
+	unsubscribePageRateLimit := middleware.RateLimit(unsubscribePageRateLimitConfig)
+
 	subscribers := router.PathPrefix("/subscribers").Subrouter()
-	subscribers.HandleFunc("", http.HandlerFunc(subscribersHandler.GetAllSubscribers)).Methods("GET")
-	subscribers.HandleFunc("/subscribe", http.HandlerFunc(subscribersHandler.Subscribe)).Methods("POST")
-	subscribers.HandleFunc("/unsubscribe", http.HandlerFunc(subscribersHandler.Unsubscribe)).Methods("POST")
+	subscribers.Handle("", middleware.Authenticate(canReadSubscribers(http.HandlerFunc(subscribersHandler.GetAllSubscribers)))).Methods("GET")
+	subscribers.Handle("/export", middleware.Authenticate(canExportSubscribers(http.HandlerFunc(subscribersHandler.ExportSubscribers)))).Methods("GET")
+	subscribers.Handle("/subscribe", subscribeAPIKey(subscribeRateLimit(http.HandlerFunc(subscribersHandler.Subscribe)))).Methods("POST")
+	subscribers.HandleFunc("/challenge", http.HandlerFunc(subscribersHandler.Challenge)).Methods("GET")
+	subscribers.HandleFunc("/confirm", http.HandlerFunc(subscribersHandler.Confirm)).Methods("GET")
+	subscribers.Handle("/unsubscribe/{token}", unsubscribePageRateLimit(http.HandlerFunc(subscribersHandler.UnsubscribePage))).Methods("GET")
+	subscribers.Handle("/unsubscribe/{token}", unsubscribeRateLimit(http.HandlerFunc(subscribersHandler.UnsubscribeByToken))).Methods("POST")
+	subscribers.Handle("/{id}", middleware.Authenticate(canDeleteSubscribers(http.HandlerFunc(subscribersHandler.DeleteSubscriber)))).Methods("DELETE")
+
+	preferences := router.PathPrefix("/preferences").Subrouter()
+	preferences.HandleFunc("/{token}", http.HandlerFunc(subscribersHandler.PreferencesPage)).Methods("GET")
+	preferences.HandleFunc("/{token}", http.HandlerFunc(subscribersHandler.UpdatePreferences)).Methods("POST")
+
+	campaigns := router.PathPrefix("/campaigns").Subrouter()
+	campaigns.Use(middleware.Authenticate)
+	campaigns.Handle("", canReadCampaigns(http.HandlerFunc(campaignsHandler.GetAllCampaigns))).Methods("GET")
+	campaigns.Handle("", canWriteCampaigns(http.HandlerFunc(campaignsHandler.CreateCampaign))).Methods("POST")
+	campaigns.Handle("/{id}", canReadCampaigns(http.HandlerFunc(campaignsHandler.GetCampaign))).Methods("GET")
+	campaigns.Handle("/{id}", canWriteCampaigns(http.HandlerFunc(campaignsHandler.UpdateCampaign))).Methods("PUT")
+	campaigns.Handle("/{id}", canWriteCampaigns(http.HandlerFunc(campaignsHandler.DeleteCampaign))).Methods("DELETE")
+	campaigns.Handle("/{id}/send", canSendCampaigns(http.HandlerFunc(campaignsHandler.SendCampaign))).Methods("POST")
+	campaigns.Handle("/{id}/resume", canSendCampaigns(http.HandlerFunc(campaignsHandler.ResumeCampaign))).Methods("POST")
+	campaigns.Handle("/{id}/deliveries", canReadCampaigns(http.HandlerFunc(campaignsHandler.GetDeliveries))).Methods("GET")
+	campaigns.Handle("/{id}/dead-letters", canReadCampaigns(http.HandlerFunc(campaignsHandler.GetDeadLetters))).Methods("GET")
+	campaigns.Handle("/{id}/dead-letters/redrive", canSendCampaigns(http.HandlerFunc(campaignsHandler.RedriveDeadLetters))).Methods("POST")
+	campaigns.Handle("/{id}/attachments", canReadCampaigns(http.HandlerFunc(campaignsHandler.GetAttachments))).Methods("GET")
+	campaigns.Handle("/{id}/attachments", canWriteCampaigns(http.HandlerFunc(campaignsHandler.UploadAttachment))).Methods("POST")
+	campaigns.Handle("/{id}/attachments/{attachmentId}", canWriteCampaigns(http.HandlerFunc(campaignsHandler.DeleteAttachment))).Methods("DELETE")
+
+	lists := router.PathPrefix("/lists").Subrouter()
+	lists.HandleFunc("/confirm", http.HandlerFunc(listsHandler.Confirm)).Methods("GET")
+	lists.Handle("/unsubscribe-all/{token}", unsubscribeRateLimit(http.HandlerFunc(listsHandler.UnsubscribeAllByToken))).Methods("POST")
+	lists.Handle("", middleware.Authenticate(canReadLists(http.HandlerFunc(listsHandler.GetAllLists)))).Methods("GET")
+	lists.Handle("", middleware.Authenticate(canWriteLists(http.HandlerFunc(listsHandler.CreateList)))).Methods("POST")
+	lists.Handle("/{id}", middleware.Authenticate(canReadLists(http.HandlerFunc(listsHandler.GetList)))).Methods("GET")
+	lists.Handle("/{id}", middleware.Authenticate(canWriteLists(http.HandlerFunc(listsHandler.UpdateList)))).Methods("PUT")
+	lists.Handle("/{id}", middleware.Authenticate(canWriteLists(http.HandlerFunc(listsHandler.DeleteList)))).Methods("DELETE")
+	lists.Handle("/{id}/subscribe", subscribeAPIKey(subscribeRateLimit(http.HandlerFunc(listsHandler.Subscribe)))).Methods("POST")
+	lists.Handle("/{id}/unsubscribe/{token}", unsubscribeRateLimit(http.HandlerFunc(listsHandler.UnsubscribeByToken))).Methods("POST")
+
+	fields := router.PathPrefix("/fields").Subrouter()
+	fields.Use(middleware.Authenticate)
+	fields.Handle("", canReadFields(http.HandlerFunc(customFieldsHandler.GetAllFields))).Methods("GET")
+	fields.Handle("", canWriteFields(http.HandlerFunc(customFieldsHandler.CreateField))).Methods("POST")
+	fields.Handle("/{id}", canReadFields(http.HandlerFunc(customFieldsHandler.GetField))).Methods("GET")
+	fields.Handle("/{id}", canWriteFields(http.HandlerFunc(customFieldsHandler.UpdateField))).Methods("PUT")
+	fields.Handle("/{id}", canWriteFields(http.HandlerFunc(customFieldsHandler.DeleteField))).Methods("DELETE")
+
+	segments := router.PathPrefix("/segments").Subrouter()
+	segments.Use(middleware.Authenticate)
+	segments.Handle("", canReadSegments(http.HandlerFunc(segmentsHandler.GetAllSegments))).Methods("GET")
+	segments.Handle("", canWriteSegments(http.HandlerFunc(segmentsHandler.CreateSegment))).Methods("POST")
+	segments.Handle("/preview", canReadSegments(http.HandlerFunc(segmentsHandler.PreviewSegment))).Methods("POST")
+	segments.Handle("/{id}", canReadSegments(http.HandlerFunc(segmentsHandler.GetSegment))).Methods("GET")
+	segments.Handle("/{id}", canWriteSegments(http.HandlerFunc(segmentsHandler.UpdateSegment))).Methods("PUT")
+	segments.Handle("/{id}", canWriteSegments(http.HandlerFunc(segmentsHandler.DeleteSegment))).Methods("DELETE")
+
+	apiKeys := router.PathPrefix("/api-keys").Subrouter()
+	apiKeys.Use(middleware.Authenticate, canManageAPIKeys)
+	apiKeys.HandleFunc("", apiKeysHandler.GetAllAPIKeys).Methods("GET")
+	apiKeys.HandleFunc("", apiKeysHandler.IssueAPIKey).Methods("POST")
+	apiKeys.HandleFunc("/{id}", apiKeysHandler.RevokeAPIKey).Methods("DELETE")
 
 	return router
 }
@@ -3,11 +3,13 @@ module subscribetool
 go 1.17
 
 require (
+	github.com/DATA-DOG/go-sqlmock v1.5.0
 	github.com/denisenkom/go-mssqldb v0.12.3
 	github.com/kisielk/sqlstruct v0.0.0-20210630145711-dae28ed37023
 	github.com/olivere/elastic/v7 v7.0.32
 	github.com/stretchr/testify v1.8.1
 	github.com/thoas/go-funk v0.9.2
+	golang.org/x/net v0.3.0
 )
 
 require (
@@ -14,6 +14,7 @@ type Configuration struct {
 	MSSQL       MSSQL
 	Elastic     Elastic
 	EmailServer EmailServer
+	Newsletter  Newsletter
 }
 
 type MSSQL struct {
@@ -31,12 +32,43 @@ type Elastic struct {
 	Password string
 }
 
+// EmailServer is how mail goes out. EmailTransport is "smtp" (the default)
+// to send through the SMTP server, "file" to write .eml files to
+// EmailFileDir instead, or "memory" to only keep them in memory.
+// EmailAttachmentsMaxSize caps the bytes of attachments in one email, 0 does
+// not cap them. A send goes out over EmailWorkers connections at most
+// EmailMessagesPerSecond between them, 0 does not limit the rate. An email
+// that fails is tried up to EmailMaxAttempts times, waiting from
+// EmailRetryBaseDelay doubling up to EmailRetryMaxDelay, durations like 1s.
 type EmailServer struct {
-	EmailSMTPHost          string
-	EmailSMTPPort          int
-	EmailSMTPMailSender    string
-	EmailSMTPPassword      string
-	EmailSMTPTLSSkipVarify string
+	EmailTransport          string
+	EmailSMTPHost           string
+	EmailSMTPPort           int
+	EmailSMTPMailSender     string
+	EmailSMTPPassword       string
+	EmailSMTPTLSSkipVarify  string
+	EmailFileDir            string
+	EmailAttachmentsMaxSize int64
+	EmailWorkers            int
+	EmailMessagesPerSecond  int
+	EmailMaxAttempts        int
+	EmailRetryBaseDelay     string
+	EmailRetryMaxDelay      string
+}
+
+// Newsletter is what links in the emails need from the backend. TokenSecret
+// must be the backend's TOKEN_SECRET so it accepts the unsubscribe and
+// preferences links, UnsubscribeURL its /subscribers/unsubscribe address and
+// PreferencesURL its /preferences address. TemplateDir holds the shared
+// *.html and *.txt layouts and partials the emails can use. BlobDir must be
+// the backend's BLOB_DIR, or the same volume mounted elsewhere, to send the
+// files uploaded to campaigns; left empty, a campaign with files is not sent.
+type Newsletter struct {
+	TokenSecret    string
+	UnsubscribeURL string
+	PreferencesURL string
+	TemplateDir    string
+	BlobDir        string
 }
 
 func GetConfig(params ...string) Configuration {
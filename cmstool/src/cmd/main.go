@@ -1,22 +1,149 @@
 package main
 
 import (
-	"crypto/tls"
 	"database/sql"
+	"flag"
+	"log"
+	"mime"
+	"net/http"
 	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
 	configs "subscribetool/src/cmd/config"
+	campaignAttachments "subscribetool/src/pkg/attachments"
+	"subscribetool/src/pkg/campaigns"
+	"subscribetool/src/pkg/deliveries"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/jobs"
+	"subscribetool/src/pkg/segments"
 	"subscribetool/src/pkg/subscribers"
+	"subscribetool/src/pkg/utils/blobstore"
 	"subscribetool/src/pkg/utils/email"
 	"subscribetool/src/pkg/utils/logger"
+	"syscall"
+	"time"
+	// Subscriber zones load the same on hosts without a zone database.
+	_ "time/tzdata"
 
 	"fmt"
 
 	_ "github.com/denisenkom/go-mssqldb"
-	"gopkg.in/gomail.v2"
 )
 
+const (
+	modeSend        = "send"
+	modeSchedule    = "schedule"
+	modeServe       = "serve"
+	modeDeadLetters = "dead-letters"
+	modeRedrive     = "redrive"
+
+	defaultSubject = "Test sent mail for subscribers"
+	defaultBody    = "This email sent for notification. Test sent mail for subscribers"
+)
+
+// main sends once and exits by default, or with -local waits to send to each
+// time zone at the same local time. -campaign sends a campaign written in the
+// backend instead of -subject and -body, with the files uploaded to it, and
+// with -resume sends a campaign that stopped part way to the subscribers it missed. "schedule" stores the send as a job
+// for a later time instead, and "serve" keeps running the jobs that are due
+// and sending the campaigns the backend queued, taking over any another
+// instance stopped sending part way, until it gets SIGTERM. "dead-letters" lists the emails of a -campaign the
+// mail server rejected for good, and "redrive" sends them again.
 func main() {
+	mode := modeSend
+	args := os.Args[1:]
+	if len(args) > 0 && (args[0] == modeSend || args[0] == modeSchedule || args[0] == modeServe || args[0] == modeDeadLetters || args[0] == modeRedrive) {
+		mode, args = args[0], args[1:]
+	}
+
+	flags := flag.NewFlagSet(mode, flag.ExitOnError)
+	listID := flags.Int64("list", 0, "send only to subscribers on this list id, 0 sends to every subscriber")
+	segmentID := flags.Int64("segment", 0, "send only to subscribers matching this segment id, cannot be used with -list")
+	campaignID := flags.Int64("campaign", 0, "send: send this campaign from the backend, with its list or segment, subject and body, and mark it sent or failed; dead-letters, redrive: the campaign whose dead letters to list or send again")
+	resume := flags.Bool("resume", false, "send: with -campaign, send a campaign that stopped part way only to the subscribers it was not delivered to")
+	subject := flags.String("subject", defaultSubject, "subject of the email, a template like the body")
+	body := flags.String("body", defaultBody, "html body of the email, a template that can use {{.Name}}, {{.Email}}, {{.UnsubscribeURL}}, {{.PreferencesURL}}, {{.Fields.Get \"key\"}} and the shared layouts")
+	sendAt := flags.String("at", "", "schedule: when to send, as RFC 3339 like 2023-01-02T09:00:00+07:00")
+	interval := flags.Duration("interval", 30*time.Second, "serve: how often to look for due jobs and queued campaigns")
+	localAt := flags.String("local", "", "send: deliver at this time of day in each subscriber's time zone, like 09:00")
+	fallbackZone := flags.String("zone", "UTC", "send: time zone for subscribers who have not set one, used with -local")
+	attach := flags.String("attach", "", "send, redrive: comma separated files to attach")
+	embed := flags.String("embed", "", "send, redrive: comma separated images to show inline, the body refers to each as cid:<file name>")
+	flags.Parse(args)
+
+	if *listID > 0 && *segmentID > 0 {
+		fmt.Println("Use either -list or -segment, not both")
+		return
+	}
+
+	if *resume && (mode != modeSend || *campaignID == 0) {
+		fmt.Println("-resume only works when sending a -campaign")
+		return
+	}
+
+	if (mode == modeDeadLetters || mode == modeRedrive) && *campaignID == 0 {
+		fmt.Println(mode, "needs -campaign")
+		return
+	}
+
+	if *campaignID > 0 {
+		set := map[string]bool{}
+		flags.Visit(func(f *flag.Flag) { set[f.Name] = true })
+		if mode == modeSchedule || mode == modeServe || set["list"] || set["segment"] || set["subject"] || set["body"] || set["local"] {
+			fmt.Println("-campaign only works when sending, listing dead letters or re-driving them, and takes its list or segment, subject and body from the campaign")
+			return
+		}
+	}
+
+	var at subscribers.LocalTime
+	var fallback *time.Location
+	if *localAt != "" {
+		if mode != modeSend {
+			fmt.Println("-local only works when sending")
+			return
+		}
+		var errAt, errZone error
+		at, errAt = subscribers.ParseLocalTime(*localAt)
+		if errAt != nil {
+			fmt.Println("Invalid -local, use hh:mm like 09:00:", errAt)
+			return
+		}
+		fallback, errZone = time.LoadLocation(*fallbackZone)
+		if errZone != nil {
+			fmt.Println("Invalid -zone, use an IANA name like Asia/Bangkok:", errZone)
+			return
+		}
+	}
+
+	if (*attach != "" || *embed != "") && mode != modeSend && mode != modeRedrive {
+		fmt.Println("-attach and -embed only work when sending or re-driving")
+		return
+	}
+	attachments, errAttach := readAttachments(*attach, false)
+	if errAttach != nil {
+		fmt.Println("Invalid -attach:", errAttach)
+		return
+	}
+	inline, errEmbed := readAttachments(*embed, true)
+	if errEmbed != nil {
+		fmt.Println("Invalid -embed:", errEmbed)
+		return
+	}
+	attachments = append(inline, attachments...)
+
 	config := configs.GetConfig()
+	if config.Newsletter.TokenSecret == "" {
+		fmt.Println("Missing Newsletter.TokenSecret, set it to the backend's TOKEN_SECRET")
+		return
+	}
+
+	retry, errRetry := emailRetry(config.EmailServer)
+	if errRetry != nil {
+		fmt.Println("Invalid email retry config:", errRetry)
+		return
+	}
 
 	dbConnection, _ := connectDatabase(DBConnectURL{
 		UserName: config.MSSQL.MssqlUsername,
@@ -33,13 +160,21 @@ func main() {
 		emailServerTLSSkipVerify = false
 	}
 
-	dialerMailServer, _ := makeDialerMailServer(EmailSMTPConnect{
-		EmailSMTPHost:          config.EmailServer.EmailSMTPHost,
-		EmailSmtpPort:          config.EmailServer.EmailSMTPPort,
-		EmailSMTPEmailSender:   config.EmailServer.EmailSMTPMailSender,
-		EmailSMTPPassword:      config.EmailServer.EmailSMTPPassword,
-		EmailSMTPTLSSkipVerify: emailServerTLSSkipVerify,
+	emailTransport, errTransport := makeEmailTransport(EmailTransportConnect{
+		Transport: config.EmailServer.EmailTransport,
+		FileDir:   config.EmailServer.EmailFileDir,
+		SMTP: EmailSMTPConnect{
+			EmailSMTPHost:          config.EmailServer.EmailSMTPHost,
+			EmailSmtpPort:          config.EmailServer.EmailSMTPPort,
+			EmailSMTPEmailSender:   config.EmailServer.EmailSMTPMailSender,
+			EmailSMTPPassword:      config.EmailServer.EmailSMTPPassword,
+			EmailSMTPTLSSkipVerify: emailServerTLSSkipVerify,
+		},
 	})
+	if errTransport != nil {
+		fmt.Println("Make email transport fail:", errTransport)
+		return
+	}
 
 	logs, errLog := logger.NewELK(logger.ELKConnect{
 		URL:      config.Elastic.Host,
@@ -57,24 +192,294 @@ func main() {
 	}
 
 	//repository
-	subscribersRepository := subscribers.NewRepository("TB_TRN_Subscribers", dbConnection, logs)
+	subscribersRepository := subscribers.NewRepository("TB_TRN_Subscribers", "TB_TRN_SubscriberLists", "TB_MAS_CustomFields", "TB_TRN_SubscriberFieldValues", dbConnection, logs)
+	segmentsRepository := segments.NewRepository("TB_MAS_Segments", "TB_MAS_CustomFields", dbConnection, logs)
+	jobsRepository := jobs.NewRepository("TB_TRN_ScheduledJobs", dbConnection, logs)
+	campaignsRepository := campaigns.NewRepository("TB_TRN_Campaigns", dbConnection, logs)
+	deliveriesRepository := deliveries.NewRepository("TB_TRN_Deliveries", dbConnection, logs)
+	attachmentsRepository := campaignAttachments.NewRepository("TB_TRN_CampaignAttachments", dbConnection, logs)
+
+	templates, errTemplates := email.NewRenderer(config.Newsletter.TemplateDir)
+	if errTemplates != nil {
+		fmt.Println("Load templates fail:", errTemplates)
+		return
+	}
 
 	// Service
 	email.FromEMailSender = config.EmailServer.EmailSMTPMailSender
-	utilsEmailService := email.NewService(dialerMailServer)
+	utilsEmailService := email.NewService(emailTransport)
+	utilsEmailService.MaxAttachmentsSize = config.EmailServer.EmailAttachmentsMaxSize
+	utilsEmailService.Pool = email.PoolConfig{
+		Workers:           config.EmailServer.EmailWorkers,
+		MessagesPerSecond: config.EmailServer.EmailMessagesPerSecond,
+	}
+	utilsEmailService.Retry = retry
+	segmentsService := segments.NewService(segments.ServiceParam{
+		Repo:     segmentsRepository,
+		Compiler: segments.NewCompiler("TB_TRN_SubscriberFieldValues", "TB_TRN_Deliveries"),
+		Logs:     logs,
+	})
 	serviceParam := subscribers.ServiceParam{
 		UtilsEmailService: utilsEmailService,
 		Repo:              subscribersRepository,
-		Logs:              logs,
+		Segments:          segmentsService,
+		Templates:         templates,
+		Config: subscribers.ServiceConfig{
+			TokenSecret:    config.Newsletter.TokenSecret,
+			UnsubscribeURL: config.Newsletter.UnsubscribeURL,
+			PreferencesURL: config.Newsletter.PreferencesURL,
+		},
+		Logs: logs,
 	}
 
 	subscribersService := subscribers.NewService(serviceParam)
+	deliveriesService := deliveries.NewService(deliveries.ServiceParam{
+		Repo: deliveriesRepository,
+		Logs: logs,
+	})
+	attachmentsParam := campaignAttachments.ServiceParam{
+		Repo:   attachmentsRepository,
+		Limits: campaignAttachments.Limits{MaxTotalSize: config.EmailServer.EmailAttachmentsMaxSize},
+		Logs:   logs,
+	}
+	if config.Newsletter.BlobDir != "" {
+		attachmentsParam.Store = blobstore.NewLocalStore(config.Newsletter.BlobDir)
+	}
+	attachmentsService := campaignAttachments.NewService(attachmentsParam)
+	campaignsService := campaigns.NewService(campaigns.ServiceParam{
+		Repo:        campaignsRepository,
+		Subscribers: subscribersService,
+		Deliveries:  deliveriesService,
+		Attachments: attachmentsService,
+		Logs:        logs,
+	})
+	jobsService := jobs.NewService(jobs.ServiceParam{
+		Repo:        jobsRepository,
+		Subscribers: subscribersService,
+		Logs:        logs,
+	})
+
+	target := subscribers.Target{}
+	if *listID > 0 {
+		target.ListID = listID
+	}
+	if *segmentID > 0 {
+		target.SegmentID = segmentID
+	}
+	content := subscribers.Content{
+		Subject:     *subject,
+		Body:        *body,
+		Attachments: attachments,
+	}
+
+	switch mode {
+	case modeSchedule:
+		at, errAt := time.Parse(time.RFC3339, *sendAt)
+		if errAt != nil {
+			fmt.Println("Invalid -at, use RFC 3339 like 2023-01-02T09:00:00+07:00:", errAt)
+			return
+		}
+		id, errSchedule := jobsService.Schedule(entity.ScheduledJobs{
+			Subject:   content.Subject,
+			Body:      content.Body,
+			ListID:    target.ListID,
+			SegmentID: target.SegmentID,
+			SendAt:    &at,
+		})
+		if errSchedule != nil {
+			fmt.Println("Schedule job fail:", *errSchedule)
+			return
+		}
+		fmt.Println("Scheduled job", id, "at", at.Format(time.RFC3339))
+
+	case modeServe:
+		serve(jobs.NewScheduler(workerName(), *interval, jobsService, campaignsService))
+
+	case modeDeadLetters:
+		deadLetters, errDeadLetters := campaignsService.GetDeadLetters(*campaignID)
+		if errDeadLetters != nil {
+			fmt.Println("List dead letters fail:", *errDeadLetters)
+			return
+		}
+		printDeadLetters(deadLetters)
 
-	subscribersService.SentEmail()
+	case modeRedrive:
+		report, errRedrive := campaignsService.Redrive(*campaignID, workerName(), attachments)
+		if errRedrive != nil {
+			fmt.Println("Redrive campaign fail:", *errRedrive)
+		}
+		printReport(report)
 
+	default:
+		if *campaignID > 0 {
+			send := campaignsService.Send
+			if *resume {
+				send = campaignsService.Resume
+			}
+			report, errSend := send(*campaignID, workerName(), attachments)
+			if errSend != nil {
+				fmt.Println("Send campaign fail:", *errSend)
+			}
+			printReport(report)
+			break
+		}
+		if *localAt != "" {
+			deliverAtLocalTime(subscribers.NewLocalDelivery(subscribersService, fallback), target, content, at)
+			break
+		}
+		report, errSend := subscribersService.SentEmail(target, content)
+		if errSend != nil {
+			fmt.Println("Send fail:", *errSend)
+		}
+		printReport(report)
+	}
+
+	if memory, ok := emailTransport.(*email.MemoryTransport); ok {
+		fmt.Println("Kept", memory.Len(), "emails in memory, none were sent")
+	}
 	fmt.Println("End of Process")
 }
 
+func serve(scheduler *jobs.Scheduler) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		scheduler.Run(stop)
+		close(done)
+	}()
+	log.Println("The scheduler is running as", scheduler.Worker)
+
+	waitingForSignal(os.Interrupt, syscall.SIGTERM)
+
+	fmt.Println("The scheduler is shutting down...")
+
+	close(stop)
+	<-done
+
+	fmt.Println("terminated...")
+}
+
+// deliverAtLocalTime waits for each zone's turn and sends to it. SIGTERM
+// stops the waiting, and the zones left unsent are listed.
+func deliverAtLocalTime(delivery *subscribers.LocalDelivery, target subscribers.Target, content subscribers.Content, at subscribers.LocalTime) {
+	stop := make(chan struct{})
+	go func() {
+		waitingForSignal(os.Interrupt, syscall.SIGTERM)
+		close(stop)
+	}()
+
+	left, report, err := delivery.Run(target, content, at, stop)
+	if err != nil {
+		fmt.Println("Deliver at local time fail:", *err)
+	}
+	printReport(report)
+	for _, batch := range left {
+		fmt.Println("Not sent to", len(batch.Subscribers), "subscribers in", batch.TimeZone, "due at", batch.SendAt.Format(time.RFC3339))
+	}
+}
+
+// printReport lists who a send failed for, after how many it went to.
+func printReport(report *subscribers.Report) {
+	if report == nil {
+		return
+	}
+	fmt.Println("Sent to", len(report.Sent), "subscribers,", len(report.Failed), "failed")
+	for _, failure := range report.Failed {
+		if failure.Permanent {
+			fmt.Println("Rejected for", failure.Subscriber.Email+":", failure.Error)
+			continue
+		}
+		fmt.Println("Not sent to", failure.Subscriber.Email+":", failure.Error)
+	}
+}
+
+// printDeadLetters lists the emails of a campaign the mail server rejected
+// for good, with its reply.
+func printDeadLetters(deadLetters []entity.Deliveries) {
+	fmt.Println(len(deadLetters), "dead letters")
+	for _, deadLetter := range deadLetters {
+		replyCode, lastError := 0, ""
+		if deadLetter.ReplyCode != nil {
+			replyCode = *deadLetter.ReplyCode
+		}
+		if deadLetter.LastError != nil {
+			lastError = *deadLetter.LastError
+		}
+		fmt.Println("Rejected for", deadLetter.Email, "with", replyCode, "after", deadLetter.Attempts, "attempts:", lastError)
+	}
+}
+
+// emailRetry reads how often and how long apart a failed email is tried
+// again. The delays are durations like 1s, empty leaves them 0.
+func emailRetry(emailServer configs.EmailServer) (email.RetryConfig, error) {
+	retry := email.RetryConfig{MaxAttempts: emailServer.EmailMaxAttempts}
+	if emailServer.EmailRetryBaseDelay != "" {
+		baseDelay, err := time.ParseDuration(emailServer.EmailRetryBaseDelay)
+		if err != nil {
+			return retry, err
+		}
+		retry.BaseDelay = baseDelay
+	}
+	if emailServer.EmailRetryMaxDelay != "" {
+		maxDelay, err := time.ParseDuration(emailServer.EmailRetryMaxDelay)
+		if err != nil {
+			return retry, err
+		}
+		retry.MaxDelay = maxDelay
+	}
+	return retry, nil
+}
+
+func waitingForSignal(sig ...os.Signal) {
+	stop := make(chan os.Signal, 1)
+	signal.Notify(stop, sig...)
+
+	s := <-stop
+	log.Println("Got signal ", s.String())
+}
+
+// readAttachments reads the comma separated files in paths. Inline ones are
+// given their file name as content id, and must be images.
+func readAttachments(paths string, inline bool) ([]email.Attachment, error) {
+	attachments := []email.Attachment{}
+	for _, path := range strings.Split(paths, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		attachment := email.Attachment{
+			FileName:    filepath.Base(path),
+			ContentType: mime.TypeByExtension(filepath.Ext(path)),
+			Data:        data,
+		}
+		if attachment.ContentType == "" {
+			attachment.ContentType = http.DetectContentType(data)
+		}
+		if inline {
+			if !strings.HasPrefix(attachment.ContentType, "image/") {
+				return nil, fmt.Errorf("%s is not an image", path)
+			}
+			attachment.ContentID = attachment.FileName
+		}
+		attachments = append(attachments, attachment)
+	}
+	return attachments, nil
+}
+
+// workerName tells instances apart in ClaimedBy.
+func workerName() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "cmstool"
+	}
+	return fmt.Sprintf("%s-%d", host, os.Getpid())
+}
+
 type DBConnectURL struct {
 	UserName string
 	Password string
@@ -111,17 +516,35 @@ type EmailSMTPConnect struct {
 	EmailSMTPTLSSkipVerify bool
 }
 
-func makeDialerMailServer(connectionMailServer EmailSMTPConnect) (*gomail.Dialer, error) {
+type EmailTransportConnect struct {
+	Transport string
+	FileDir   string
+	SMTP      EmailSMTPConnect
+}
 
-	mailer := gomail.NewDialer(
-		connectionMailServer.EmailSMTPHost,
-		connectionMailServer.EmailSmtpPort,
-		connectionMailServer.EmailSMTPEmailSender,
-		connectionMailServer.EmailSMTPPassword,
-	)
-	mailer.TLSConfig = &tls.Config{InsecureSkipVerify: connectionMailServer.EmailSMTPTLSSkipVerify}
+func makeEmailTransport(connect EmailTransportConnect) (email.Transport, error) {
+	switch connect.Transport {
+	case "", email.TransportSMTP:
+		mailer := email.NewSMTPTransport(
+			connect.SMTP.EmailSMTPHost,
+			connect.SMTP.EmailSmtpPort,
+			connect.SMTP.EmailSMTPEmailSender,
+			connect.SMTP.EmailSMTPPassword,
+			connect.SMTP.EmailSMTPTLSSkipVerify,
+		)
+		fmt.Println("Make Dialer For Mail Server Success !!")
+		return mailer, nil
 
-	fmt.Println("Make Dialer For Mail Server Success !!")
+	case email.TransportFile:
+		if connect.FileDir == "" {
+			return nil, fmt.Errorf("EmailFileDir is needed by the %s transport", email.TransportFile)
+		}
+		fmt.Println("Emails are written to", connect.FileDir, "instead of being sent")
+		return email.NewFileTransport(connect.FileDir), nil
+
+	case email.TransportMemory:
+		return email.NewMemoryTransport(), nil
+	}
 
-	return mailer, nil
+	return nil, fmt.Errorf("unknown email transport %q, use %s, %s or %s", connect.Transport, email.TransportSMTP, email.TransportFile, email.TransportMemory)
 }
@@ -0,0 +1,10 @@
+package attachments
+
+// Limits bound the files of one campaign, in bytes, as the backend checks them
+// on upload. MaxFileSize applies to each file and MaxTotalSize to all of them
+// together, since every message carries them all. A limit of zero is not
+// enforced.
+type Limits struct {
+	MaxFileSize  int64
+	MaxTotalSize int64
+}
@@ -0,0 +1,69 @@
+package attachments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"subscribetool/src/pkg/entity"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByCampaignID(campaignID int64) ([]entity.Attachments, error)
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) FindByCampaignID(campaignID int64) ([]entity.Attachments, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	AND CampaignId = %[3]s
+	ORDER BY Id ASC
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Attachments{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, campaignID)
+	if err != nil {
+		go repo.Logs.Error("", "attachments_Repo_FindByCampaignID", campaignID, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Attachments{}
+	for rows.Next() {
+		var entity entity.Attachments
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
@@ -0,0 +1,62 @@
+package attachments_test
+
+import (
+	"errors"
+	"subscribetool/src/pkg/attachments"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	sqlMock  sqlmock.Sqlmock
+	repo     *attachments.SqlRepository
+	repoLogs *loggerMocks.Logger
+)
+
+func beforeEachRepository(t *testing.T) {
+	db, mockDB, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs = &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sqlMock = mockDB
+	repo = attachments.NewRepository("TB_TRN_CampaignAttachments", db, repoLogs)
+}
+
+func TestRepository_FindByCampaignID(t *testing.T) {
+	t.Run("should return attachments of campaign not deleted", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery(`FROM TB_TRN_CampaignAttachments\s+WHERE Delflag = 0\s+AND CampaignId = @p1`).
+			WithArgs(int64(1)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "campaignId", "fileName", "storageKey", "contentId"}).
+				AddRow(2, 1, "menu.pdf", "abc", nil).
+				AddRow(3, 1, "logo.png", "def", "logo"))
+
+		res, err := repo.FindByCampaignID(1)
+
+		assert.Nil(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "abc", res[0].StorageKey)
+		assert.Nil(t, res[0].ContentID)
+		assert.Equal(t, "logo", *res[1].ContentID)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when query failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("CampaignId = @p1").WillReturnError(errors.New("Error"))
+
+		res, err := repo.FindByCampaignID(1)
+
+		assert.NotNil(t, err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,90 @@
+package attachments
+
+import (
+	"subscribetool/src/pkg/utils/blobstore"
+	"subscribetool/src/pkg/utils/convert"
+	"subscribetool/src/pkg/utils/email"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+)
+
+type ServiceParam struct {
+	Repo   Repository
+	Store  blobstore.Store
+	Limits Limits
+	Logs   logger.Logger
+}
+
+type UseCase interface {
+	Load(campaignID int64) ([]email.Attachment, *subscribetoolError.ErrorCode)
+}
+
+// Service reads the files the backend stored for a campaign, recorded in
+// TB_TRN_CampaignAttachments with their content in the blob store. Store must
+// read the same blobs as the backend's; without one, a campaign that has
+// files cannot be loaded.
+type Service struct {
+	UseCase
+	Repo   Repository
+	Store  blobstore.Store
+	Limits Limits
+	Logs   logger.Logger
+}
+
+func NewService(serviceParam ServiceParam) *Service {
+	service := &Service{
+		Repo:   serviceParam.Repo,
+		Store:  serviceParam.Store,
+		Limits: serviceParam.Limits,
+		Logs:   serviceParam.Logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+// Load reads the files of a campaign ready to send, the same way the backend
+// does. The limits are checked again, as they may differ from the backend's.
+// A campaign without files loads none.
+func (service *Service) Load(campaignID int64) ([]email.Attachment, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignID(campaignID)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) > 0 && service.Store == nil {
+		go service.Logs.Error("", "attachments_Service_Load_Store", campaignID, subscribetoolError.NewError(subscribetoolError.AttachmentsUnavailable, "no blob store to read attachments from"))
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable)
+	}
+
+	total := int64(0)
+	for _, attachment := range res {
+		if service.Limits.MaxFileSize > 0 && attachment.Size > service.Limits.MaxFileSize {
+			return nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentTooLarge)
+		}
+		total += attachment.Size
+	}
+	if service.Limits.MaxTotalSize > 0 && total > service.Limits.MaxTotalSize {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentTooLarge)
+	}
+
+	list := []email.Attachment{}
+	for _, attachment := range res {
+		data, errGet := service.Store.Get(attachment.StorageKey)
+		if errGet != nil {
+			go service.Logs.Error("", "attachments_Service_Load_Get", attachment.ID, errGet.Error())
+			return nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable)
+		}
+
+		loaded := email.Attachment{
+			FileName:    attachment.FileName,
+			ContentType: attachment.ContentType,
+			Data:        data,
+		}
+		if attachment.ContentID != nil {
+			loaded.ContentID = *attachment.ContentID
+		}
+		list = append(list, loaded)
+	}
+
+	return list, nil
+}
@@ -0,0 +1,167 @@
+package attachments_test
+
+import (
+	"errors"
+	"subscribetool/src/pkg/attachments"
+	"subscribetool/src/pkg/attachments/mocks"
+	"subscribetool/src/pkg/entity"
+	blobstoreMocks "subscribetool/src/pkg/utils/blobstore/mocks"
+	"subscribetool/src/pkg/utils/convert"
+	"subscribetool/src/pkg/utils/email"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"subscribetool/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository *mocks.Repository
+	store      *blobstoreMocks.Store
+	service    *attachments.Service
+	logs       *loggerMocks.Logger
+
+	mockRepoFindByCampaignID *mocker.MockCall
+	mockStoreGet             *mocker.MockCall
+)
+
+func callRepoFindByCampaignID() *mock.Call {
+	return repository.On("FindByCampaignID", mock.Anything)
+}
+
+func callStoreGet() *mock.Call {
+	return store.On("Get", mock.Anything)
+}
+
+func beforeEach() {
+	repository = &mocks.Repository{}
+	store = &blobstoreMocks.Store{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &attachments.Service{
+		Repo:   repository,
+		Store:  store,
+		Limits: attachments.Limits{MaxFileSize: 10, MaxTotalSize: 15},
+		Logs:   logs,
+	}
+	service.UseCase = service
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct attachments service when call new service", func(t *testing.T) {
+		beforeEach()
+
+		resService := attachments.NewService(attachments.ServiceParam{
+			Repo:   repository,
+			Store:  store,
+			Limits: attachments.Limits{MaxTotalSize: 15},
+			Logs:   logs,
+		})
+
+		expectedService := &attachments.Service{
+			Repo:   repository,
+			Store:  store,
+			Limits: attachments.Limits{MaxTotalSize: 15},
+			Logs:   logs,
+		}
+		expectedService.UseCase = expectedService
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_Load(t *testing.T) {
+	contentID := "logo"
+
+	beforeEachLoad := func() {
+		beforeEach()
+
+		mockRepoFindByCampaignID = mocker.NewMockCall(callRepoFindByCampaignID)
+		mockRepoFindByCampaignID.Return([]entity.Attachments{
+			{ID: 2, CampaignID: 1, FileName: "menu.pdf", ContentType: "application/pdf", Size: 3, StorageKey: "abc"},
+			{ID: 3, CampaignID: 1, FileName: "logo.png", ContentType: "image/png", Size: 3, StorageKey: "def", ContentID: &contentID},
+		}, nil)
+		mockStoreGet = mocker.NewMockCall(callStoreGet)
+		mockStoreGet.Return([]byte("pdf"), nil)
+	}
+
+	t.Run("should read each file of campaign from the blob store", func(t *testing.T) {
+		beforeEachLoad()
+
+		res, err := service.Load(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []email.Attachment{
+			{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")},
+			{FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("pdf")},
+		}, res)
+		repository.AssertCalled(t, "FindByCampaignID", int64(1))
+		store.AssertCalled(t, "Get", "abc")
+		store.AssertCalled(t, "Get", "def")
+	})
+
+	t.Run("should load nothing without a blob store when campaign has no files", func(t *testing.T) {
+		beforeEachLoad()
+		service.Store = nil
+		mockRepoFindByCampaignID.Return([]entity.Attachments{}, nil)
+
+		res, err := service.Load(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []email.Attachment{}, res)
+	})
+
+	t.Run("should return attachments unavailable without a blob store when campaign has files", func(t *testing.T) {
+		beforeEachLoad()
+		service.Store = nil
+
+		res, err := service.Load(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return attachments unavailable when a blob cannot be read", func(t *testing.T) {
+		beforeEachLoad()
+		mockStoreGet.Return(nil, errors.New("blob not found"))
+
+		res, err := service.Load(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return attachment too large without reading blobs when a file is over the limit", func(t *testing.T) {
+		beforeEachLoad()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 2, Size: 11, StorageKey: "abc"}}, nil)
+
+		res, err := service.Load(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentTooLarge), err)
+		assert.Nil(t, res)
+		store.AssertNotCalled(t, "Get", mock.Anything)
+	})
+
+	t.Run("should return attachment too large when files together are over the limit", func(t *testing.T) {
+		beforeEachLoad()
+		mockRepoFindByCampaignID.Return([]entity.Attachments{{ID: 2, Size: 8, StorageKey: "abc"}, {ID: 3, Size: 8, StorageKey: "def"}}, nil)
+
+		res, err := service.Load(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentTooLarge), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachLoad()
+		mockRepoFindByCampaignID.Return(nil, errors.New("Error"))
+
+		res, err := service.Load(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
@@ -0,0 +1,178 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "subscribetool/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// ClaimByID provides a mock function with given fields: id, worker, from
+func (_m *Repository) ClaimByID(id int64, worker string, from []entity.CampaignStatus) (bool, error) {
+	ret := _m.Called(id, worker, from)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string, []entity.CampaignStatus) (bool, error)); ok {
+		return rf(id, worker, from)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, []entity.CampaignStatus) bool); ok {
+		r0 = rf(id, worker, from)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, []entity.CampaignStatus) error); ok {
+		r1 = rf(id, worker, from)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimQueued provides a mock function with given fields: worker, lease
+func (_m *Repository) ClaimQueued(worker string, lease time.Duration) ([]entity.Campaigns, error) {
+	ret := _m.Called(worker, lease)
+
+	var r0 []entity.Campaigns
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, time.Duration) ([]entity.Campaigns, error)); ok {
+		return rf(worker, lease)
+	}
+	if rf, ok := ret.Get(0).(func(string, time.Duration) []entity.Campaigns); ok {
+		r0 = rf(worker, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, time.Duration) error); ok {
+		r1 = rf(worker, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ClaimStaleByID provides a mock function with given fields: id, worker, lease
+func (_m *Repository) ClaimStaleByID(id int64, worker string, lease time.Duration) (bool, error) {
+	ret := _m.Called(id, worker, lease)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string, time.Duration) (bool, error)); ok {
+		return rf(id, worker, lease)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, time.Duration) bool); ok {
+		r0 = rf(id, worker, lease)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, time.Duration) error); ok {
+		r1 = rf(id, worker, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *Repository) FindByID(id int64) ([]entity.Campaigns, error) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Campaigns
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Campaigns, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Campaigns); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FinishByID provides a mock function with given fields: id, worker, status
+func (_m *Repository) FinishByID(id int64, worker string, status entity.CampaignStatus) (bool, error) {
+	ret := _m.Called(id, worker, status)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string, entity.CampaignStatus) (bool, error)); ok {
+		return rf(id, worker, status)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, entity.CampaignStatus) bool); ok {
+		r0 = rf(id, worker, status)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, entity.CampaignStatus) error); ok {
+		r1 = rf(id, worker, status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RenewClaimByID provides a mock function with given fields: id, worker
+func (_m *Repository) RenewClaimByID(id int64, worker string) (bool, error) {
+	ret := _m.Called(id, worker)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64, string) (bool, error)); ok {
+		return rf(id, worker)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string) bool); ok {
+		r0 = rf(id, worker)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string) error); ok {
+		r1 = rf(id, worker)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,243 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "subscribetool/src/pkg/entity"
+	email "subscribetool/src/pkg/utils/email"
+
+	error "subscribetool/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+
+	subscribers "subscribetool/src/pkg/subscribers"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// ClaimForSending provides a mock function with given fields: id, worker
+func (_m *UseCase) ClaimForSending(id int64, worker string) *error.ErrorCode {
+	ret := _m.Called(id, worker)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, string) *error.ErrorCode); ok {
+		r0 = rf(id, worker)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// Deliver provides a mock function with given fields: campaign, attachments
+func (_m *UseCase) Deliver(campaign entity.Campaigns, attachments []email.Attachment) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(campaign, attachments)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.Campaigns, []email.Attachment) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(campaign, attachments)
+	}
+	if rf, ok := ret.Get(0).(func(entity.Campaigns, []email.Attachment) *subscribers.Report); ok {
+		r0 = rf(campaign, attachments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.Campaigns, []email.Attachment) *error.ErrorCode); ok {
+		r1 = rf(campaign, attachments)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// FindByID provides a mock function with given fields: id
+func (_m *UseCase) FindByID(id int64) (*entity.Campaigns, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 *entity.Campaigns
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*entity.Campaigns, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *entity.Campaigns); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*entity.Campaigns)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: id
+func (_m *UseCase) GetDeadLetters(id int64) ([]entity.Deliveries, *error.ErrorCode) {
+	ret := _m.Called(id)
+
+	var r0 []entity.Deliveries
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Deliveries, *error.ErrorCode)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Deliveries); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(id)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Redrive provides a mock function with given fields: id, worker, attachments
+func (_m *UseCase) Redrive(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(id, worker, attachments)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(id, worker, attachments)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) *subscribers.Report); ok {
+		r0 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, []email.Attachment) *error.ErrorCode); ok {
+		r1 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Resume provides a mock function with given fields: id, worker, attachments
+func (_m *UseCase) Resume(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(id, worker, attachments)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(id, worker, attachments)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) *subscribers.Report); ok {
+		r0 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, []email.Attachment) *error.ErrorCode); ok {
+		r1 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// RunDue provides a mock function with given fields: worker
+func (_m *UseCase) RunDue(worker string) (bool, *error.ErrorCode) {
+	ret := _m.Called(worker)
+
+	var r0 bool
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (bool, *error.ErrorCode)); ok {
+		return rf(worker)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(worker)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(worker)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Send provides a mock function with given fields: id, worker, attachments
+func (_m *UseCase) Send(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(id, worker, attachments)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(id, worker, attachments)
+	}
+	if rf, ok := ret.Get(0).(func(int64, string, []email.Attachment) *subscribers.Report); ok {
+		r0 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64, string, []email.Attachment) *error.ErrorCode); ok {
+		r1 = rf(id, worker, attachments)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
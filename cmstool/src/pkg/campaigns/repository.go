@@ -0,0 +1,308 @@
+package campaigns
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"subscribetool/src/pkg/entity"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+	"time"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByID(id int64) ([]entity.Campaigns, error)
+	ClaimByID(id int64, worker string, from []entity.CampaignStatus) (bool, error)
+	ClaimStaleByID(id int64, worker string, lease time.Duration) (bool, error)
+	ClaimQueued(worker string, lease time.Duration) ([]entity.Campaigns, error)
+	RenewClaimByID(id int64, worker string) (bool, error)
+	FinishByID(id int64, worker string, status entity.CampaignStatus) (bool, error)
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Campaigns, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Campaigns{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_FindByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Campaigns{}
+	for rows.Next() {
+		var entity entity.Campaigns
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// ClaimByID moves the campaign to sending for worker only while its status
+// is one of from, and reports whether it did.
+func (repo *SqlRepository) ClaimByID(id int64, worker string, from []entity.CampaignStatus) (bool, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return false, sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{id, entity.CampaignStatusSending, worker}
+	params := []string{}
+	for _, status := range from {
+		args = append(args, status)
+		params = append(params, sqlQuery.Param(len(args)))
+	}
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[3]s,
+		ClaimedBy = %[4]s,
+		ClaimedDate = GETDATE(),
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	AND Status IN (%[5]s)
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		strings.Join(params, ","),
+	)
+
+	res, err := session.ExecContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_ClaimByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClaimStaleByID takes over a campaign another instance is sending for worker,
+// but only when that instance has not renewed its claim for lease, as when it
+// crashed part way. It reports whether it did.
+func (repo *SqlRepository) ClaimStaleByID(id int64, worker string, lease time.Duration) (bool, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return false, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		ClaimedBy = %[3]s,
+		ClaimedDate = GETDATE(),
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	AND Status = %[4]s
+	AND (ClaimedDate IS NULL OR ClaimedDate < DATEADD(SECOND, -%[5]s, GETDATE()))
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		sqlQuery.Param(4),
+	)
+
+	res, err := session.ExecContext(ctx, sql, id, worker, entity.CampaignStatusSending, int64(lease/time.Second))
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_ClaimStaleByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// ClaimQueued moves the campaign queued longest to sending for worker and
+// returns it, or nothing when no campaign is queued. A campaign whose sender
+// has not renewed its claim for lease is taken over the same way. Instances
+// claiming at the same time skip each other's rows.
+func (repo *SqlRepository) ClaimQueued(worker string, lease time.Duration) ([]entity.Campaigns, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	WITH queued AS (
+		SELECT TOP (1) *
+		FROM %[1]s WITH (ROWLOCK, UPDLOCK, READPAST)
+		WHERE Delflag = 0
+		AND (
+			Status = %[3]s
+			OR (Status = %[4]s AND (ClaimedDate IS NULL OR ClaimedDate < DATEADD(SECOND, -%[6]s, GETDATE())))
+		)
+		ORDER BY UpdatedDate ASC, Id ASC
+	)
+	UPDATE queued
+	SET
+		Status = %[4]s,
+		ClaimedBy = %[5]s,
+		ClaimedDate = GETDATE(),
+		UpdatedDate = GETDATE()
+	OUTPUT %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Campaigns{}, []string{}, "INSERTED"),
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		sqlQuery.Param(4),
+	)
+	rows, err := session.QueryContext(ctx, sql, entity.CampaignStatusQueued, entity.CampaignStatusSending, worker, int64(lease/time.Second))
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_ClaimQueued", worker, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Campaigns{}
+	for rows.Next() {
+		var entity entity.Campaigns
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// RenewClaimByID keeps the claim of worker on a campaign it is sending fresh,
+// so no other instance takes it over as stale. It reports false once another
+// instance has.
+func (repo *SqlRepository) RenewClaimByID(id int64, worker string) (bool, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return false, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		ClaimedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	AND Status = %[4]s
+	AND ClaimedBy = %[3]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+	)
+
+	res, err := session.ExecContext(ctx, sql, id, worker, entity.CampaignStatusSending)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_RenewClaimByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// FinishByID moves a campaign worker is sending to status, setting SentDate
+// when it is sent. It does nothing and reports false once another instance
+// has taken the campaign over.
+func (repo *SqlRepository) FinishByID(id int64, worker string, status entity.CampaignStatus) (bool, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return false, sessionErr
+	}
+	defer session.Close()
+
+	setSentDate := ""
+	if status == entity.CampaignStatusSent {
+		setSentDate = "SentDate = GETDATE(),"
+	}
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[4]s,
+		%[6]s
+		UpdatedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Delflag = 0
+	AND Status = %[5]s
+	AND ClaimedBy = %[3]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		sqlQuery.Param(4),
+		setSentDate,
+	)
+
+	res, err := session.ExecContext(ctx, sql, id, worker, status, entity.CampaignStatusSending)
+	if err != nil {
+		go repo.Logs.Error("", "campaigns_Repo_FinishByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return false, err
+	}
+
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
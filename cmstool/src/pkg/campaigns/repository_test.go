@@ -0,0 +1,130 @@
+package campaigns_test
+
+import (
+	"subscribetool/src/pkg/campaigns"
+	"subscribetool/src/pkg/entity"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func beforeEachRepository(t *testing.T) (sqlmock.Sqlmock, *campaigns.SqlRepository) {
+	db, sqlMock, err := sqlmock.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs := &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	return sqlMock, campaigns.NewRepository("TB_TRN_Campaigns", db, repoLogs)
+}
+
+func TestRepository_ClaimByID(t *testing.T) {
+	t.Run("should claim for worker only while status is one of from", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`ClaimedBy = @p3,(.|\n)*AND Status IN \(@p4,@p5\)`).
+			WithArgs(int64(1), entity.CampaignStatusSending, "host-1", entity.CampaignStatusDraft, entity.CampaignStatusScheduled).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		claimed, err := repo.ClaimByID(1, "host-1", entity.CampaignSendableStatuses)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should report not claimed when no row was in from", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`AND Status IN`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		claimed, err := repo.ClaimByID(1, "host-1", entity.CampaignSendableStatuses)
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestRepository_ClaimQueued(t *testing.T) {
+	t.Run("should claim one queued or stale campaign for worker skipping locked rows", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectQuery(`SELECT TOP \(1\) \*\s+FROM TB_TRN_Campaigns WITH \(ROWLOCK, UPDLOCK, READPAST\)(.|\n)*Status = @p1\s+OR \(Status = @p2 AND \(ClaimedDate IS NULL OR ClaimedDate < DATEADD\(SECOND, -@p4, GETDATE\(\)\)\)\)(.|\n)*OUTPUT INSERTED`).
+			WithArgs(entity.CampaignStatusQueued, entity.CampaignStatusSending, "host-1", int64(300)).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "status"}).AddRow(1, entity.CampaignStatusSending))
+
+		res, err := repo.ClaimQueued("host-1", 5*time.Minute)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Campaigns{{ID: 1, Status: entity.CampaignStatusSending}}, res)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_ClaimStaleByID(t *testing.T) {
+	t.Run("should take over sending campaign only when its claim is older than lease", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`ClaimedBy = @p2,(.|\n)*AND Status = @p3\s+AND \(ClaimedDate IS NULL OR ClaimedDate < DATEADD\(SECOND, -@p4, GETDATE\(\)\)\)`).
+			WithArgs(int64(1), "host-1", entity.CampaignStatusSending, int64(300)).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		claimed, err := repo.ClaimStaleByID(1, "host-1", 5*time.Minute)
+
+		assert.Nil(t, err)
+		assert.True(t, claimed)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should report not claimed when claim is still fresh", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`ClaimedDate < DATEADD`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		claimed, err := repo.ClaimStaleByID(1, "host-1", 5*time.Minute)
+
+		assert.Nil(t, err)
+		assert.False(t, claimed)
+	})
+}
+
+func TestRepository_RenewClaimByID(t *testing.T) {
+	t.Run("should renew claim only while worker holds it", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`SET\s+ClaimedDate = GETDATE\(\)(.|\n)*AND Status = @p3\s+AND ClaimedBy = @p2`).
+			WithArgs(int64(1), "host-1", entity.CampaignStatusSending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		renewed, err := repo.RenewClaimByID(1, "host-1")
+
+		assert.Nil(t, err)
+		assert.True(t, renewed)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
+
+func TestRepository_FinishByID(t *testing.T) {
+	t.Run("should set status and sent date only while worker holds the claim", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`Status = @p3,\s+SentDate = GETDATE\(\),(.|\n)*AND Status = @p4\s+AND ClaimedBy = @p2`).
+			WithArgs(int64(1), "host-1", entity.CampaignStatusSent, entity.CampaignStatusSending).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		finished, err := repo.FinishByID(1, "host-1", entity.CampaignStatusSent)
+
+		assert.Nil(t, err)
+		assert.True(t, finished)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should report not finished once another worker took the campaign over", func(t *testing.T) {
+		sqlMock, repo := beforeEachRepository(t)
+		sqlMock.ExpectExec(`AND ClaimedBy = @p2`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+		finished, err := repo.FinishByID(1, "host-1", entity.CampaignStatusFailed)
+
+		assert.Nil(t, err)
+		assert.False(t, finished)
+	})
+}
@@ -0,0 +1,373 @@
+package campaigns
+
+import (
+	"subscribetool/src/pkg/attachments"
+	"subscribetool/src/pkg/deliveries"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/subscribers"
+	"subscribetool/src/pkg/utils/convert"
+	"subscribetool/src/pkg/utils/email"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	"time"
+)
+
+// defaultLease is how long a campaign stays claimed by an instance that stops
+// renewing its claim before another may take it over.
+const defaultLease = 5 * time.Minute
+
+type ServiceParam struct {
+	Repo        Repository
+	Subscribers subscribers.UseCase
+	Deliveries  deliveries.UseCase
+	Attachments attachments.UseCase
+	Logs        logger.Logger
+	Lease       time.Duration
+}
+
+type UseCase interface {
+	FindByID(id int64) (*entity.Campaigns, *subscribetoolError.ErrorCode)
+	ClaimForSending(id int64, worker string) *subscribetoolError.ErrorCode
+	Send(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode)
+	Resume(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode)
+	RunDue(worker string) (bool, *subscribetoolError.ErrorCode)
+	Deliver(campaign entity.Campaigns, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode)
+	GetDeadLetters(id int64) ([]entity.Deliveries, *subscribetoolError.ErrorCode)
+	Redrive(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode)
+}
+
+// Service sends campaigns written in the backend, and is the only thing that
+// does: the backend's POST /campaigns/{id}/send only queues a campaign for
+// RunDue. Each delivery is logged in the TB_TRN_Deliveries rows the backend
+// reads. Every email carries the files uploaded to the campaign in the
+// backend, then any given to Send, Resume or Redrive.
+//
+// The instance sending a campaign names itself in ClaimedBy and renews
+// ClaimedDate every third of Lease while it sends. A campaign whose claim
+// was not renewed for Lease is stale, as when its sender crashed, and Resume
+// or RunDue may take it over.
+type Service struct {
+	UseCase
+	Repo        Repository
+	Subscribers subscribers.UseCase
+	Deliveries  deliveries.UseCase
+	Attachments attachments.UseCase
+	Logs        logger.Logger
+	Lease       time.Duration
+}
+
+func NewService(serviceParam ServiceParam) *Service {
+	service := &Service{
+		Repo:        serviceParam.Repo,
+		Subscribers: serviceParam.Subscribers,
+		Deliveries:  serviceParam.Deliveries,
+		Attachments: serviceParam.Attachments,
+		Logs:        serviceParam.Logs,
+		Lease:       serviceParam.Lease,
+	}
+	if service.Lease <= 0 {
+		service.Lease = defaultLease
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) FindByID(id int64) (*entity.Campaigns, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.FindByID(id)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	return &res[0], nil
+}
+
+// ClaimForSending moves the campaign to sending for worker if it is still
+// sendable or queued. Only one cmstool can claim a campaign.
+func (service *Service) ClaimForSending(id int64, worker string) *subscribetoolError.ErrorCode {
+	claimed, err := service.Repo.ClaimByID(id, worker, append([]entity.CampaignStatus{entity.CampaignStatusQueued}, entity.CampaignSendableStatuses...))
+	if err != nil {
+		return convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if !claimed {
+		return convert.ValueToErrorCodePointer(subscribetoolError.CampaignAlreadySent)
+	}
+
+	return nil
+}
+
+// Send claims the campaign and delivers it. A campaign whose template does
+// not parse or whose files cannot be loaded is left as it was.
+func (service *Service) Send(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode) {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	_, errTemplate := service.Subscribers.ParseContent(content(*resCampaign, attachments))
+	if errTemplate != nil {
+		return nil, errTemplate
+	}
+
+	resAttachments, errAttachments := service.loadAttachments(id, attachments)
+	if errAttachments != nil {
+		return nil, errAttachments
+	}
+
+	errClaim := service.UseCase.ClaimForSending(id, worker)
+	if errClaim != nil {
+		return nil, errClaim
+	}
+
+	return service.UseCase.Deliver(claimedBy(*resCampaign, worker), resAttachments)
+}
+
+// Resume delivers a campaign that stopped part way, for example after a
+// crash, to the subscribers it was not delivered to yet. Only a campaign
+// already sending, sent or failed can be resumed, and worker claims it first:
+// a sent or failed campaign as it is, a sending one only once its claim is
+// stale. Of instances resuming the same campaign at once only one claims it.
+func (service *Service) Resume(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode) {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isDelivered(resCampaign.Status) {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidCampaignStatus)
+	}
+
+	_, errTemplate := service.Subscribers.ParseContent(content(*resCampaign, attachments))
+	if errTemplate != nil {
+		return nil, errTemplate
+	}
+
+	resAttachments, errAttachments := service.loadAttachments(id, attachments)
+	if errAttachments != nil {
+		return nil, errAttachments
+	}
+
+	claimed, errClaim := service.Repo.ClaimByID(id, worker, entity.CampaignResumableStatuses)
+	if errClaim == nil && !claimed {
+		claimed, errClaim = service.Repo.ClaimStaleByID(id, worker, service.Lease)
+	}
+	if errClaim != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if !claimed {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.CampaignDeliveryInProgress)
+	}
+
+	return service.UseCase.Deliver(claimedBy(*resCampaign, worker), resAttachments)
+}
+
+// RunDue claims the campaign queued longest, or a stale one, for worker and
+// delivers it, reporting whether there was one. How the send went is recorded on the
+// campaign; the error returned is only about claiming it. A campaign whose
+// template no longer parses goes back to draft to be fixed, and one whose
+// files cannot be loaded is marked failed for Resume to retry.
+func (service *Service) RunDue(worker string) (bool, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.ClaimQueued(worker, service.Lease)
+	if err != nil {
+		return false, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return false, nil
+	}
+
+	campaign := res[0]
+	_, errTemplate := service.Subscribers.ParseContent(content(campaign, nil))
+	if errTemplate != nil {
+		go service.Logs.Error("", "campaigns_Service_RunDue_ParseContent", campaign.ID, subscribetoolError.NewError(*errTemplate, "campaign send failed"))
+		service.Repo.FinishByID(campaign.ID, worker, entity.CampaignStatusDraft)
+		return true, nil
+	}
+
+	resAttachments, errAttachments := service.loadAttachments(campaign.ID, nil)
+	if errAttachments != nil {
+		go service.Logs.Error("", "campaigns_Service_RunDue_LoadAttachments", campaign.ID, subscribetoolError.NewError(*errAttachments, "campaign send failed"))
+		service.Repo.FinishByID(campaign.ID, worker, entity.CampaignStatusFailed)
+		return true, nil
+	}
+
+	service.UseCase.Deliver(campaign, resAttachments)
+	return true, nil
+}
+
+// Deliver sends a campaign claimed by campaign.ClaimedBy to its list or
+// segment, or to every subscriber when it has neither, skipping the
+// subscribers it was already delivered to or dead-lettered. Each result is
+// logged as it comes back, and the claim is renewed until it returns. The
+// campaign is marked sent when every email went out, and failed otherwise:
+// Resume retries the emails that failed and Redrive the ones the mail server
+// rejected for good.
+func (service *Service) Deliver(campaign entity.Campaigns, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode) {
+	worker := ""
+	if campaign.ClaimedBy != nil {
+		worker = *campaign.ClaimedBy
+	}
+
+	stop := service.heartbeat(campaign.ID, worker)
+	defer close(stop)
+
+	handled, errHandled := service.Deliveries.FindHandledSubscriberIDs(campaign.ID)
+	if errHandled != nil {
+		go service.Logs.Error("", "campaigns_Service_Deliver_FindHandledSubscriberIDs", campaign.ID, subscribetoolError.NewError(*errHandled, "campaign send failed"))
+		service.Repo.FinishByID(campaign.ID, worker, entity.CampaignStatusFailed)
+		return nil, errHandled
+	}
+
+	resSubscribers, errSubscribers := service.Subscribers.GetRecipients(subscribers.Target{ListID: campaign.ListID, SegmentID: campaign.SegmentID})
+	if errSubscribers != nil && *errSubscribers != subscribetoolError.DataNotFound {
+		go service.Logs.Error("", "campaigns_Service_Deliver_GetRecipients", campaign.ID, subscribetoolError.NewError(*errSubscribers, "campaign send failed"))
+		service.Repo.FinishByID(campaign.ID, worker, entity.CampaignStatusFailed)
+		return nil, errSubscribers
+	}
+
+	remaining := []entity.Subscribers{}
+	for _, subscriber := range resSubscribers {
+		if !handled[subscriber.ID] {
+			remaining = append(remaining, subscriber)
+		}
+	}
+
+	resReport := &subscribers.Report{}
+	if len(remaining) > 0 {
+		var errSend *subscribetoolError.ErrorCode
+		resReport, errSend = service.Subscribers.SentEmailToRecorded(remaining, content(campaign, attachments), service.recorder(campaign.ID))
+		if errSend != nil {
+			go service.Logs.Error("", "campaigns_Service_Deliver_SentEmail", campaign.ID, subscribetoolError.NewError(*errSend, "campaign send failed"))
+			service.Repo.FinishByID(campaign.ID, worker, entity.CampaignStatusFailed)
+			return nil, errSend
+		}
+	}
+
+	status := entity.CampaignStatusSent
+	var errReport *subscribetoolError.ErrorCode
+	if len(resReport.Failed) > 0 {
+		go service.Logs.Error("", "campaigns_Service_Deliver_SentEmail", campaign.ID, subscribetoolError.NewError(subscribetoolError.EmailNotSent, "campaign send failed"))
+		status = entity.CampaignStatusFailed
+		errReport = convert.ValueToErrorCodePointer(subscribetoolError.EmailNotSent)
+	}
+
+	finished, errStatus := service.Repo.FinishByID(campaign.ID, worker, status)
+	if errStatus != nil {
+		return resReport, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if !finished {
+		go service.Logs.Error("", "campaigns_Service_Deliver_FinishByID", campaign.ID, subscribetoolError.NewError(subscribetoolError.CampaignDeliveryInProgress, "campaign taken over by another sender"))
+		return resReport, convert.ValueToErrorCodePointer(subscribetoolError.CampaignDeliveryInProgress)
+	}
+
+	return resReport, errReport
+}
+
+// heartbeat renews the claim of worker on a campaign every third of the lease
+// until stop is closed, so a long send is not taken over as stale. It gives up
+// once another instance has taken the campaign over.
+func (service *Service) heartbeat(id int64, worker string) chan struct{} {
+	stop := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(service.Lease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				renewed, err := service.Repo.RenewClaimByID(id, worker)
+				if err == nil && !renewed {
+					go service.Logs.Error("", "campaigns_Service_Deliver_RenewClaimByID", id, subscribetoolError.NewError(subscribetoolError.CampaignDeliveryInProgress, "campaign taken over by another sender"))
+					return
+				}
+			}
+		}
+	}()
+	return stop
+}
+
+func (service *Service) GetDeadLetters(id int64) ([]entity.Deliveries, *subscribetoolError.ErrorCode) {
+	_, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return service.Deliveries.GetDeadLetters(id)
+}
+
+// Redrive puts the dead-lettered deliveries of a campaign back in the failed
+// state and resumes the campaign, so those subscribers are tried again along
+// with any it had not reached yet.
+func (service *Service) Redrive(id int64, worker string, attachments []email.Attachment) (*subscribers.Report, *subscribetoolError.ErrorCode) {
+	resCampaign, err := service.UseCase.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !isDelivered(resCampaign.Status) {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidCampaignStatus)
+	}
+
+	_, errRequeue := service.Deliveries.RequeueDeadLetters(id)
+	if errRequeue != nil {
+		return nil, errRequeue
+	}
+
+	return service.UseCase.Resume(id, worker, attachments)
+}
+
+// recorder logs each result of a campaign send in its delivery log. An email
+// the mail server rejected for good becomes a dead letter.
+func (service *Service) recorder(campaignID int64) subscribers.Recorder {
+	return func(subscriber entity.Subscribers, result email.SendResult) {
+		if result.Error == nil {
+			service.Deliveries.RecordSent(campaignID, subscriber.ID, subscriber.Email, result.Attempts)
+			return
+		}
+		if result.Error.Permanent {
+			service.Deliveries.RecordDeadLetter(campaignID, subscriber.ID, subscriber.Email, result.Attempts, result.Error.Code, result.Error.Error())
+			return
+		}
+		service.Deliveries.RecordFailed(campaignID, subscriber.ID, subscriber.Email, result.Attempts, result.Error.Error())
+	}
+}
+
+// loadAttachments returns the files stored with a campaign followed by extra.
+func (service *Service) loadAttachments(campaignID int64, extra []email.Attachment) ([]email.Attachment, *subscribetoolError.ErrorCode) {
+	res, err := service.Attachments.Load(campaignID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(res, extra...), nil
+}
+
+// isDelivered reports whether a campaign with status has been claimed for
+// sending, so it can be resumed or re-driven.
+func isDelivered(status entity.CampaignStatus) bool {
+	return status == entity.CampaignStatusSending || status == entity.CampaignStatusSent || status == entity.CampaignStatusFailed
+}
+
+// claimedBy is campaign as worker claimed it.
+func claimedBy(campaign entity.Campaigns, worker string) entity.Campaigns {
+	campaign.ClaimedBy = &worker
+	return campaign
+}
+
+// content is what campaign puts in every email.
+func content(campaign entity.Campaigns, attachments []email.Attachment) subscribers.Content {
+	return subscribers.Content{
+		Sender:      campaign.Sender,
+		Subject:     campaign.Subject,
+		Body:        campaign.HTMLBody,
+		Text:        campaign.TextBody,
+		Attachments: attachments,
+	}
+}
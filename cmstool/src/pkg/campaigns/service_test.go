@@ -0,0 +1,883 @@
+package campaigns_test
+
+import (
+	"errors"
+	attachmentsMocks "subscribetool/src/pkg/attachments/mocks"
+	"subscribetool/src/pkg/campaigns"
+	"subscribetool/src/pkg/campaigns/mocks"
+	deliveriesMocks "subscribetool/src/pkg/deliveries/mocks"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/subscribers"
+	subscribersMocks "subscribetool/src/pkg/subscribers/mocks"
+	"subscribetool/src/pkg/utils/convert"
+	"subscribetool/src/pkg/utils/email"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"subscribetool/src/pkg/utils/mocker"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	mockUseCase        *mocks.UseCase
+	repository         *mocks.Repository
+	subscribersService *subscribersMocks.UseCase
+	deliveriesService  *deliveriesMocks.UseCase
+	attachmentsService *attachmentsMocks.UseCase
+	service            *campaigns.Service
+	logs               *loggerMocks.Logger
+
+	mockRepoFindByID             *mocker.MockCall
+	mockRepoFinishByID           *mocker.MockCall
+	mockRepoClaimByID            *mocker.MockCall
+	mockRepoClaimStaleByID       *mocker.MockCall
+	mockRepoClaimQueued          *mocker.MockCall
+	mockRepoRenewClaimByID       *mocker.MockCall
+	mockServiceFindByID          *mocker.MockCall
+	mockServiceClaimForSending   *mocker.MockCall
+	mockServiceDeliver           *mocker.MockCall
+	mockSubscribersGetRecipients *mocker.MockCall
+	mockSubscribersSentEmailTo   *mocker.MockCall
+	mockSubscribersParseContent  *mocker.MockCall
+	mockServiceResume            *mocker.MockCall
+	mockDeliveriesFindHandled    *mocker.MockCall
+	mockDeliveriesRecordSent     *mocker.MockCall
+	mockDeliveriesRecordFailed   *mocker.MockCall
+	mockDeliveriesRecordDead     *mocker.MockCall
+	mockDeliveriesGetDead        *mocker.MockCall
+	mockDeliveriesRequeueDead    *mocker.MockCall
+	mockAttachmentsLoad          *mocker.MockCall
+)
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoFinishByID() *mock.Call {
+	return repository.On("FinishByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callRepoClaimByID() *mock.Call {
+	return repository.On("ClaimByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callRepoClaimStaleByID() *mock.Call {
+	return repository.On("ClaimStaleByID", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callRepoClaimQueued() *mock.Call {
+	return repository.On("ClaimQueued", mock.Anything, mock.Anything)
+}
+
+func callRepoRenewClaimByID() *mock.Call {
+	return repository.On("RenewClaimByID", mock.Anything, mock.Anything)
+}
+
+func callServiceFindByID() *mock.Call {
+	return mockUseCase.On("FindByID", mock.Anything)
+}
+
+func callServiceClaimForSending() *mock.Call {
+	return mockUseCase.On("ClaimForSending", mock.Anything, mock.Anything)
+}
+
+func callServiceDeliver() *mock.Call {
+	return mockUseCase.On("Deliver", mock.Anything, mock.Anything)
+}
+
+func callSubscribersGetRecipients() *mock.Call {
+	return subscribersService.On("GetRecipients", mock.Anything)
+}
+
+func callSubscribersSentEmailTo() *mock.Call {
+	return subscribersService.On("SentEmailToRecorded", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callServiceResume() *mock.Call {
+	return mockUseCase.On("Resume", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callDeliveriesFindHandled() *mock.Call {
+	return deliveriesService.On("FindHandledSubscriberIDs", mock.Anything)
+}
+
+func callDeliveriesRecordSent() *mock.Call {
+	return deliveriesService.On("RecordSent", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callDeliveriesRecordFailed() *mock.Call {
+	return deliveriesService.On("RecordFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callDeliveriesRecordDead() *mock.Call {
+	return deliveriesService.On("RecordDeadLetter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callDeliveriesGetDead() *mock.Call {
+	return deliveriesService.On("GetDeadLetters", mock.Anything)
+}
+
+func callDeliveriesRequeueDead() *mock.Call {
+	return deliveriesService.On("RequeueDeadLetters", mock.Anything)
+}
+
+func callSubscribersParseContent() *mock.Call {
+	return subscribersService.On("ParseContent", mock.Anything)
+}
+
+func callAttachmentsLoad() *mock.Call {
+	return attachmentsService.On("Load", mock.Anything)
+}
+
+func beforeEach() {
+	mockUseCase = &mocks.UseCase{}
+	repository = &mocks.Repository{}
+	subscribersService = &subscribersMocks.UseCase{}
+	deliveriesService = &deliveriesMocks.UseCase{}
+	attachmentsService = &attachmentsMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &campaigns.Service{
+		Repo:        repository,
+		Subscribers: subscribersService,
+		Deliveries:  deliveriesService,
+		Attachments: attachmentsService,
+		Logs:        logs,
+		Lease:       time.Minute,
+	}
+	service.UseCase = mockUseCase
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct campaigns service when call new service", func(t *testing.T) {
+		beforeEach()
+
+		resService := campaigns.NewService(campaigns.ServiceParam{
+			Repo:        repository,
+			Subscribers: subscribersService,
+			Deliveries:  deliveriesService,
+			Attachments: attachmentsService,
+			Logs:        logs,
+			Lease:       time.Minute,
+		})
+
+		expectedService := &campaigns.Service{
+			Repo:        repository,
+			Subscribers: subscribersService,
+			Deliveries:  deliveriesService,
+			Attachments: attachmentsService,
+			Logs:        logs,
+			Lease:       time.Minute,
+		}
+		expectedService.UseCase = expectedService
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_FindByID(t *testing.T) {
+	beforeEachFindByID := func() {
+		beforeEach()
+
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.Campaigns{{ID: 1}}, nil)
+	}
+
+	t.Run("should return campaign when found", func(t *testing.T) {
+		beforeEachFindByID()
+
+		res, err := service.FindByID(1)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &entity.Campaigns{ID: 1}, res)
+	})
+
+	t.Run("should return data not found when campaign not found", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return([]entity.Campaigns{}, nil)
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachFindByID()
+		mockRepoFindByID.Return(nil, errors.New("Error"))
+
+		res, err := service.FindByID(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_ClaimForSending(t *testing.T) {
+	beforeEachClaimForSending := func() {
+		beforeEach()
+
+		mockRepoClaimByID = mocker.NewMockCall(callRepoClaimByID)
+		mockRepoClaimByID.Return(true, nil)
+	}
+
+	t.Run("should claim campaign for worker only from queued or sendable statuses", func(t *testing.T) {
+		beforeEachClaimForSending()
+
+		err := service.ClaimForSending(1, "host-1")
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "ClaimByID", int64(1), "host-1", []entity.CampaignStatus{entity.CampaignStatusQueued, entity.CampaignStatusDraft, entity.CampaignStatusScheduled})
+	})
+
+	t.Run("should return campaign already sent when another sender claimed it", func(t *testing.T) {
+		beforeEachClaimForSending()
+		mockRepoClaimByID.Return(false, nil)
+
+		err := service.ClaimForSending(1, "host-1")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.CampaignAlreadySent), err)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachClaimForSending()
+		mockRepoClaimByID.Return(false, errors.New("Error"))
+
+		err := service.ClaimForSending(1, "host-1")
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+}
+
+func TestService_Send(t *testing.T) {
+	listID := int64(9)
+	campaign := entity.Campaigns{
+		ID:       1,
+		Subject:  "subject",
+		HTMLBody: "<p>body</p>",
+		TextBody: "body",
+		Sender:   "campaign@newsletter.com",
+		Status:   entity.CampaignStatusDraft,
+		ListID:   &listID,
+	}
+	attachments := []email.Attachment{{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")}}
+	stored := []email.Attachment{{FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("png")}}
+	report := &subscribers.Report{Sent: []entity.Subscribers{{Email: "ajis@gmail.com"}}}
+	worker := "host-1"
+
+	beforeEachSend := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&campaign, nil)
+		mockServiceClaimForSending = mocker.NewMockCall(callServiceClaimForSending)
+		mockServiceClaimForSending.Return(nil)
+		mockServiceDeliver = mocker.NewMockCall(callServiceDeliver)
+		mockServiceDeliver.Return(report, nil)
+		mockSubscribersParseContent = mocker.NewMockCall(callSubscribersParseContent)
+		mockSubscribersParseContent.Return(nil, nil)
+		mockAttachmentsLoad = mocker.NewMockCall(callAttachmentsLoad)
+		mockAttachmentsLoad.Return([]email.Attachment{}, nil)
+	}
+
+	t.Run("should claim campaign and deliver it", func(t *testing.T) {
+		beforeEachSend()
+
+		res, err := service.Send(1, "host-1", attachments)
+
+		assert.Equal(t, report, res)
+		assert.Nil(t, err)
+		claimed := campaign
+		claimed.ClaimedBy = &worker
+		mockUseCase.AssertCalled(t, "ClaimForSending", int64(1), "host-1")
+		mockUseCase.AssertCalled(t, "Deliver", claimed, attachments)
+	})
+
+	t.Run("should deliver files stored with campaign before given ones", func(t *testing.T) {
+		beforeEachSend()
+		mockAttachmentsLoad.Return(append([]email.Attachment{}, stored...), nil)
+
+		_, err := service.Send(1, "host-1", attachments)
+
+		assert.Nil(t, err)
+		claimed := campaign
+		claimed.ClaimedBy = &worker
+		attachmentsService.AssertCalled(t, "Load", int64(1))
+		mockUseCase.AssertCalled(t, "Deliver", claimed, append(append([]email.Attachment{}, stored...), attachments...))
+	})
+
+	t.Run("should leave campaign unclaimed when its files cannot be loaded", func(t *testing.T) {
+		beforeEachSend()
+		mockAttachmentsLoad.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable))
+
+		_, err := service.Send(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable), err)
+		mockUseCase.AssertNotCalled(t, "ClaimForSending", mock.Anything, mock.Anything)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return error when campaign not found", func(t *testing.T) {
+		beforeEachSend()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		_, err := service.Send(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		mockUseCase.AssertNotCalled(t, "ClaimForSending", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should leave campaign unclaimed when its template does not parse", func(t *testing.T) {
+		beforeEachSend()
+		mockSubscribersParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		_, err := service.Send(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		mockUseCase.AssertNotCalled(t, "ClaimForSending", mock.Anything, mock.Anything)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not deliver when campaign cannot be claimed", func(t *testing.T) {
+		beforeEachSend()
+		mockServiceClaimForSending.Return(convert.ValueToErrorCodePointer(subscribetoolError.CampaignAlreadySent))
+
+		_, err := service.Send(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.CampaignAlreadySent), err)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Resume(t *testing.T) {
+	campaign := entity.Campaigns{ID: 1, Subject: "subject", HTMLBody: "<p>body</p>", Status: entity.CampaignStatusSending}
+	report := &subscribers.Report{Sent: []entity.Subscribers{{Email: "ajis@gmail.com"}}}
+	worker := "host-1"
+
+	beforeEachResume := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&campaign, nil)
+		mockServiceDeliver = mocker.NewMockCall(callServiceDeliver)
+		mockServiceDeliver.Return(report, nil)
+		mockSubscribersParseContent = mocker.NewMockCall(callSubscribersParseContent)
+		mockSubscribersParseContent.Return(nil, nil)
+		mockRepoClaimByID = mocker.NewMockCall(callRepoClaimByID)
+		mockRepoClaimByID.Return(true, nil)
+		mockRepoClaimStaleByID = mocker.NewMockCall(callRepoClaimStaleByID)
+		mockRepoClaimStaleByID.Return(false, nil)
+		mockAttachmentsLoad = mocker.NewMockCall(callAttachmentsLoad)
+		mockAttachmentsLoad.Return([]email.Attachment{}, nil)
+	}
+
+	t.Run("should claim sent or failed campaign for worker and deliver it", func(t *testing.T) {
+		beforeEachResume()
+		sent := campaign
+		sent.Status = entity.CampaignStatusSent
+		mockServiceFindByID.Return(&sent, nil)
+
+		res, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, report, res)
+		assert.Nil(t, err)
+		claimed := sent
+		claimed.ClaimedBy = &worker
+		repository.AssertCalled(t, "ClaimByID", int64(1), "host-1", []entity.CampaignStatus{entity.CampaignStatusSent, entity.CampaignStatusFailed})
+		repository.AssertNotCalled(t, "ClaimStaleByID", mock.Anything, mock.Anything, mock.Anything)
+		mockUseCase.AssertCalled(t, "Deliver", claimed, []email.Attachment{})
+		mockUseCase.AssertNotCalled(t, "ClaimForSending", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should take over sending campaign whose claim is stale", func(t *testing.T) {
+		beforeEachResume()
+		mockRepoClaimByID.Return(false, nil)
+		mockRepoClaimStaleByID.Return(true, nil)
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "ClaimStaleByID", int64(1), "host-1", time.Minute)
+		mockUseCase.AssertNumberOfCalls(t, "Deliver", 1)
+	})
+
+	t.Run("should return delivery in progress when another instance holds a fresh claim", func(t *testing.T) {
+		beforeEachResume()
+		mockRepoClaimByID.Return(false, nil)
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.CampaignDeliveryInProgress), err)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should deliver only once when two instances resume at the same time", func(t *testing.T) {
+		beforeEachResume()
+		mockRepoClaimByID.Return(true, nil).Once()
+		mockRepoClaimByID.NumberOfCallReturn(2, false, nil)
+
+		errs := make([]*subscribetoolError.ErrorCode, 2)
+		var wg sync.WaitGroup
+		for i, host := range []string{"host-1", "host-2"} {
+			wg.Add(1)
+			go func(i int, host string) {
+				defer wg.Done()
+				_, errs[i] = service.Resume(1, host, nil)
+			}(i, host)
+		}
+		wg.Wait()
+
+		won := 0
+		for _, err := range errs {
+			if err == nil {
+				won++
+				continue
+			}
+			assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.CampaignDeliveryInProgress), err)
+		}
+		assert.Equal(t, 1, won)
+		mockUseCase.AssertNumberOfCalls(t, "Deliver", 1)
+	})
+
+	t.Run("should return invalid campaign status when campaign was never sent", func(t *testing.T) {
+		beforeEachResume()
+		draft := campaign
+		draft.Status = entity.CampaignStatusDraft
+		mockServiceFindByID.Return(&draft, nil)
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidCampaignStatus), err)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not deliver when its template does not parse", func(t *testing.T) {
+		beforeEachResume()
+		mockSubscribersParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		repository.AssertNotCalled(t, "ClaimByID", mock.Anything, mock.Anything, mock.Anything)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should not claim campaign when its files cannot be loaded", func(t *testing.T) {
+		beforeEachResume()
+		mockAttachmentsLoad.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable))
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable), err)
+		repository.AssertNotCalled(t, "ClaimByID", mock.Anything, mock.Anything, mock.Anything)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return internal server error when claim failed", func(t *testing.T) {
+		beforeEachResume()
+		mockRepoClaimByID.Return(false, errors.New("Error"))
+
+		_, err := service.Resume(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+}
+
+func TestService_Deliver(t *testing.T) {
+	listID := int64(9)
+	worker := "host-1"
+	campaign := entity.Campaigns{
+		ID:        1,
+		Subject:   "subject",
+		HTMLBody:  "<p>body</p>",
+		TextBody:  "body",
+		Sender:    "campaign@newsletter.com",
+		Status:    entity.CampaignStatusSending,
+		ListID:    &listID,
+		ClaimedBy: &worker,
+	}
+	attachments := []email.Attachment{{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")}}
+	ajis := entity.Subscribers{ID: 1, Email: "ajis@gmail.com"}
+	mana := entity.Subscribers{ID: 2, Email: "mana@gmail.com"}
+
+	beforeEachDeliver := func() {
+		beforeEach()
+
+		mockDeliveriesFindHandled = mocker.NewMockCall(callDeliveriesFindHandled)
+		mockDeliveriesFindHandled.Return(map[int64]bool{}, nil)
+		mockDeliveriesRecordSent = mocker.NewMockCall(callDeliveriesRecordSent)
+		mockDeliveriesRecordSent.Return(nil)
+		mockDeliveriesRecordFailed = mocker.NewMockCall(callDeliveriesRecordFailed)
+		mockDeliveriesRecordFailed.Return(nil)
+		mockDeliveriesRecordDead = mocker.NewMockCall(callDeliveriesRecordDead)
+		mockDeliveriesRecordDead.Return(nil)
+		mockSubscribersGetRecipients = mocker.NewMockCall(callSubscribersGetRecipients)
+		mockSubscribersGetRecipients.Return([]entity.Subscribers{ajis, mana}, nil)
+		mockSubscribersSentEmailTo = mocker.NewMockCall(callSubscribersSentEmailTo)
+		mockSubscribersSentEmailTo.Return(&subscribers.Report{Sent: []entity.Subscribers{ajis, mana}}, nil)
+		mockRepoFinishByID = mocker.NewMockCall(callRepoFinishByID)
+		mockRepoFinishByID.Return(true, nil)
+	}
+
+	t.Run("should send campaign content to its list and mark it sent", func(t *testing.T) {
+		beforeEachDeliver()
+
+		res, err := service.Deliver(campaign, attachments)
+
+		assert.Nil(t, err)
+		assert.Equal(t, &subscribers.Report{Sent: []entity.Subscribers{ajis, mana}}, res)
+		subscribersService.AssertCalled(t, "GetRecipients", subscribers.Target{ListID: &listID})
+		subscribersService.AssertCalled(t, "SentEmailToRecorded", []entity.Subscribers{ajis, mana}, subscribers.Content{
+			Sender:      "campaign@newsletter.com",
+			Subject:     "subject",
+			Body:        "<p>body</p>",
+			Text:        "body",
+			Attachments: attachments,
+		}, mock.Anything)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusSent)
+	})
+
+	t.Run("should send campaign to subscribers its segment matches", func(t *testing.T) {
+		beforeEachDeliver()
+		segmentID := int64(4)
+		segmentCampaign := campaign
+		segmentCampaign.ListID = nil
+		segmentCampaign.SegmentID = &segmentID
+
+		_, err := service.Deliver(segmentCampaign, nil)
+
+		assert.Nil(t, err)
+		subscribersService.AssertCalled(t, "GetRecipients", subscribers.Target{SegmentID: &segmentID})
+	})
+
+	t.Run("should skip subscribers the campaign was already delivered to or dead-lettered", func(t *testing.T) {
+		beforeEachDeliver()
+		mockDeliveriesFindHandled.Return(map[int64]bool{ajis.ID: true}, nil)
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		subscribersService.AssertCalled(t, "SentEmailToRecorded", []entity.Subscribers{mana}, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should mark campaign sent without sending when every subscriber was delivered to", func(t *testing.T) {
+		beforeEachDeliver()
+		mockDeliveriesFindHandled.Return(map[int64]bool{ajis.ID: true, mana.ID: true}, nil)
+
+		res, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		assert.Empty(t, res.Sent)
+		subscribersService.AssertNotCalled(t, "SentEmailToRecorded", mock.Anything, mock.Anything, mock.Anything)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusSent)
+	})
+
+	t.Run("should record each result in the delivery log", func(t *testing.T) {
+		beforeEachDeliver()
+		mockSubscribersSentEmailTo.Return(&subscribers.Report{}, nil).Run(func(args mock.Arguments) {
+			record := args.Get(2).(subscribers.Recorder)
+			record(ajis, email.SendResult{Attempts: 2})
+			record(mana, email.SendResult{Attempts: 3, Error: email.NewSendError(errors.New("421 service not available"))})
+		})
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		deliveriesService.AssertCalled(t, "RecordSent", int64(1), ajis.ID, ajis.Email, 2)
+		deliveriesService.AssertCalled(t, "RecordFailed", int64(1), mana.ID, mana.Email, 3, "421 service not available")
+		deliveriesService.AssertNotCalled(t, "RecordDeadLetter", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should record permanently rejected email as dead letter with its reply code", func(t *testing.T) {
+		beforeEachDeliver()
+		mockSubscribersSentEmailTo.Return(&subscribers.Report{}, nil).Run(func(args mock.Arguments) {
+			record := args.Get(2).(subscribers.Recorder)
+			record(mana, email.SendResult{Attempts: 1, Error: email.NewSendError(errors.New("550 mailbox unavailable"))})
+		})
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		deliveriesService.AssertCalled(t, "RecordDeadLetter", int64(1), mana.ID, mana.Email, 1, 550, "550 mailbox unavailable")
+		deliveriesService.AssertNotCalled(t, "RecordFailed", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("should mark campaign failed and return report when some emails failed", func(t *testing.T) {
+		beforeEachDeliver()
+		failed := &subscribers.Report{
+			Sent:   []entity.Subscribers{ajis},
+			Failed: []subscribers.Failure{{Subscriber: mana, Error: "421 service not available"}},
+		}
+		mockSubscribersSentEmailTo.Return(failed, nil)
+
+		res, err := service.Deliver(campaign, nil)
+
+		assert.Equal(t, failed, res)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.EmailNotSent), err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusFailed)
+		repository.AssertNotCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusSent)
+	})
+
+	t.Run("should mark campaign failed and return report when only dead letters failed", func(t *testing.T) {
+		beforeEachDeliver()
+		failed := &subscribers.Report{
+			Sent:   []entity.Subscribers{ajis},
+			Failed: []subscribers.Failure{{Subscriber: mana, Error: "550 mailbox unavailable", Permanent: true}},
+		}
+		mockSubscribersSentEmailTo.Return(failed, nil)
+
+		res, err := service.Deliver(campaign, nil)
+
+		assert.Equal(t, failed, res)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.EmailNotSent), err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusFailed)
+	})
+
+	t.Run("should mark campaign failed when send failed", func(t *testing.T) {
+		beforeEachDeliver()
+		mockSubscribersSentEmailTo.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusFailed)
+	})
+
+	t.Run("should not send and mark campaign failed when delivery log cannot be read", func(t *testing.T) {
+		beforeEachDeliver()
+		mockDeliveriesFindHandled.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError))
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		subscribersService.AssertNotCalled(t, "SentEmailToRecorded", mock.Anything, mock.Anything, mock.Anything)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusFailed)
+	})
+
+	t.Run("should mark campaign sent when it has no recipients", func(t *testing.T) {
+		beforeEachDeliver()
+		mockSubscribersGetRecipients.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusSent)
+	})
+
+	t.Run("should return internal server error when mark sent failed", func(t *testing.T) {
+		beforeEachDeliver()
+		mockRepoFinishByID.Return(false, errors.New("Error"))
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+
+	t.Run("should return delivery in progress when another instance took the campaign over", func(t *testing.T) {
+		beforeEachDeliver()
+		mockRepoFinishByID.Return(false, nil)
+
+		res, err := service.Deliver(campaign, nil)
+
+		assert.NotNil(t, res)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.CampaignDeliveryInProgress), err)
+	})
+
+	t.Run("should renew claim of worker while sending", func(t *testing.T) {
+		beforeEachDeliver()
+		service.Lease = 30 * time.Millisecond
+		mockRepoRenewClaimByID = mocker.NewMockCall(callRepoRenewClaimByID)
+		mockRepoRenewClaimByID.Return(true, nil)
+		mockSubscribersSentEmailTo.Return(&subscribers.Report{Sent: []entity.Subscribers{ajis, mana}}, nil).After(50 * time.Millisecond)
+
+		_, err := service.Deliver(campaign, nil)
+
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "RenewClaimByID", int64(1), "host-1")
+	})
+}
+
+func TestService_RunDue(t *testing.T) {
+	worker := "host-1"
+	campaign := entity.Campaigns{ID: 1, Subject: "subject", HTMLBody: "<p>body</p>", Status: entity.CampaignStatusSending, ClaimedBy: &worker}
+
+	beforeEachRunDue := func() {
+		beforeEach()
+
+		mockRepoClaimQueued = mocker.NewMockCall(callRepoClaimQueued)
+		mockRepoClaimQueued.Return([]entity.Campaigns{campaign}, nil)
+		mockSubscribersParseContent = mocker.NewMockCall(callSubscribersParseContent)
+		mockSubscribersParseContent.Return(nil, nil)
+		mockServiceDeliver = mocker.NewMockCall(callServiceDeliver)
+		mockServiceDeliver.Return(&subscribers.Report{}, nil)
+		mockRepoFinishByID = mocker.NewMockCall(callRepoFinishByID)
+		mockRepoFinishByID.Return(true, nil)
+		mockAttachmentsLoad = mocker.NewMockCall(callAttachmentsLoad)
+		mockAttachmentsLoad.Return([]email.Attachment{}, nil)
+	}
+
+	t.Run("should claim queued campaign for worker and deliver it", func(t *testing.T) {
+		beforeEachRunDue()
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "ClaimQueued", "host-1", time.Minute)
+		mockUseCase.AssertCalled(t, "Deliver", campaign, []email.Attachment{})
+	})
+
+	t.Run("should deliver files stored with campaign", func(t *testing.T) {
+		beforeEachRunDue()
+		stored := []email.Attachment{{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")}}
+		mockAttachmentsLoad.Return(stored, nil)
+
+		_, err := service.RunDue("host-1")
+
+		assert.Nil(t, err)
+		attachmentsService.AssertCalled(t, "Load", int64(1))
+		mockUseCase.AssertCalled(t, "Deliver", campaign, stored)
+	})
+
+	t.Run("should mark campaign failed when its files cannot be loaded", func(t *testing.T) {
+		beforeEachRunDue()
+		mockAttachmentsLoad.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.AttachmentsUnavailable))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusFailed)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should report nothing ran when no campaign is queued", func(t *testing.T) {
+		beforeEachRunDue()
+		mockRepoClaimQueued.Return([]entity.Campaigns{}, nil)
+
+		ran, err := service.RunDue("host-1")
+
+		assert.False(t, ran)
+		assert.Nil(t, err)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should report ran without error when some emails failed", func(t *testing.T) {
+		beforeEachRunDue()
+		mockServiceDeliver.Return(&subscribers.Report{}, convert.ValueToErrorCodePointer(subscribetoolError.EmailNotSent))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should put campaign back to draft when its template does not parse", func(t *testing.T) {
+		beforeEachRunDue()
+		mockSubscribersParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "FinishByID", int64(1), "host-1", entity.CampaignStatusDraft)
+		mockUseCase.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return internal server error when claim failed", func(t *testing.T) {
+		beforeEachRunDue()
+		mockRepoClaimQueued.Return(nil, errors.New("Error"))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.False(t, ran)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+}
+
+func TestService_GetDeadLetters(t *testing.T) {
+	deadLetters := []entity.Deliveries{{CampaignID: 1, SubscriberID: 2, Status: entity.DeliveryStatusDead}}
+
+	beforeEachGetDeadLetters := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1}, nil)
+		mockDeliveriesGetDead = mocker.NewMockCall(callDeliveriesGetDead)
+		mockDeliveriesGetDead.Return(deadLetters, nil)
+	}
+
+	t.Run("should return dead letters of campaign", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Equal(t, deadLetters, res)
+		assert.Nil(t, err)
+		deliveriesService.AssertCalled(t, "GetDeadLetters", int64(1))
+	})
+
+	t.Run("should return data not found when campaign does not exist", func(t *testing.T) {
+		beforeEachGetDeadLetters()
+		mockServiceFindByID.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		res, err := service.GetDeadLetters(1)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		assert.Nil(t, res)
+		deliveriesService.AssertNotCalled(t, "GetDeadLetters", mock.Anything)
+	})
+}
+
+func TestService_Redrive(t *testing.T) {
+	campaign := entity.Campaigns{ID: 1, Status: entity.CampaignStatusSent}
+	report := &subscribers.Report{Sent: []entity.Subscribers{{Email: "ajis@gmail.com"}}}
+
+	beforeEachRedrive := func() {
+		beforeEach()
+
+		mockServiceFindByID = mocker.NewMockCall(callServiceFindByID)
+		mockServiceFindByID.Return(&campaign, nil)
+		mockDeliveriesRequeueDead = mocker.NewMockCall(callDeliveriesRequeueDead)
+		mockDeliveriesRequeueDead.Return(int64(1), nil)
+		mockServiceResume = mocker.NewMockCall(callServiceResume)
+		mockServiceResume.Return(report, nil)
+	}
+
+	t.Run("should requeue dead letters and resume campaign", func(t *testing.T) {
+		beforeEachRedrive()
+
+		res, err := service.Redrive(1, "host-1", nil)
+
+		assert.Equal(t, report, res)
+		assert.Nil(t, err)
+		deliveriesService.AssertCalled(t, "RequeueDeadLetters", int64(1))
+		mockUseCase.AssertCalled(t, "Resume", int64(1), "host-1", []email.Attachment(nil))
+	})
+
+	t.Run("should return invalid campaign status when campaign was never sent", func(t *testing.T) {
+		beforeEachRedrive()
+		mockServiceFindByID.Return(&entity.Campaigns{ID: 1, Status: entity.CampaignStatusDraft}, nil)
+
+		_, err := service.Redrive(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidCampaignStatus), err)
+		deliveriesService.AssertNotCalled(t, "RequeueDeadLetters", mock.Anything)
+	})
+
+	t.Run("should not resume when campaign has no dead letter", func(t *testing.T) {
+		beforeEachRedrive()
+		mockDeliveriesRequeueDead.Return(int64(0), convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		_, err := service.Redrive(1, "host-1", nil)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		mockUseCase.AssertNotCalled(t, "Resume", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
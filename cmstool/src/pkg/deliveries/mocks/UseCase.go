@@ -0,0 +1,159 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "subscribetool/src/pkg/entity"
+	error "subscribetool/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// FindHandledSubscriberIDs provides a mock function with given fields: campaignID
+func (_m *UseCase) FindHandledSubscriberIDs(campaignID int64) (map[int64]bool, *error.ErrorCode) {
+	ret := _m.Called(campaignID)
+
+	var r0 map[int64]bool
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (map[int64]bool, *error.ErrorCode)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) map[int64]bool); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(map[int64]bool)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(campaignID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetDeadLetters provides a mock function with given fields: campaignID
+func (_m *UseCase) GetDeadLetters(campaignID int64) ([]entity.Deliveries, *error.ErrorCode) {
+	ret := _m.Called(campaignID)
+
+	var r0 []entity.Deliveries
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Deliveries, *error.ErrorCode)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Deliveries); ok {
+		r0 = rf(campaignID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Deliveries)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(campaignID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// RecordDeadLetter provides a mock function with given fields: campaignID, subscriberID, email, attempts, replyCode, reason
+func (_m *UseCase) RecordDeadLetter(campaignID int64, subscriberID int64, email string, attempts int, replyCode int, reason string) *error.ErrorCode {
+	ret := _m.Called(campaignID, subscriberID, email, attempts, replyCode, reason)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, int64, string, int, int, string) *error.ErrorCode); ok {
+		r0 = rf(campaignID, subscriberID, email, attempts, replyCode, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// RecordFailed provides a mock function with given fields: campaignID, subscriberID, email, attempts, reason
+func (_m *UseCase) RecordFailed(campaignID int64, subscriberID int64, email string, attempts int, reason string) *error.ErrorCode {
+	ret := _m.Called(campaignID, subscriberID, email, attempts, reason)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, int64, string, int, string) *error.ErrorCode); ok {
+		r0 = rf(campaignID, subscriberID, email, attempts, reason)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// RecordSent provides a mock function with given fields: campaignID, subscriberID, email, attempts
+func (_m *UseCase) RecordSent(campaignID int64, subscriberID int64, email string, attempts int) *error.ErrorCode {
+	ret := _m.Called(campaignID, subscriberID, email, attempts)
+
+	var r0 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64, int64, string, int) *error.ErrorCode); ok {
+		r0 = rf(campaignID, subscriberID, email, attempts)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*error.ErrorCode)
+		}
+	}
+
+	return r0
+}
+
+// RequeueDeadLetters provides a mock function with given fields: campaignID
+func (_m *UseCase) RequeueDeadLetters(campaignID int64) (int64, *error.ErrorCode) {
+	ret := _m.Called(campaignID)
+
+	var r0 int64
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (int64, *error.ErrorCode)); ok {
+		return rf(campaignID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) int64); ok {
+		r0 = rf(campaignID)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(campaignID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
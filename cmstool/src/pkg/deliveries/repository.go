@@ -0,0 +1,168 @@
+package deliveries
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"subscribetool/src/pkg/entity"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByCampaignIDAndStatuses(campaignID int64, statuses []entity.DeliveryStatus) ([]entity.Deliveries, error)
+	Upsert(delivery entity.Deliveries) error
+	UpdateStatusByCampaignID(campaignID int64, fromStatus entity.DeliveryStatus, toStatus entity.DeliveryStatus) (int64, error)
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) FindByCampaignIDAndStatuses(campaignID int64, statuses []entity.DeliveryStatus) ([]entity.Deliveries, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	statusParams := []string{}
+	args := []interface{}{campaignID}
+	for i, status := range statuses {
+		statusParams = append(statusParams, sqlQuery.Param(i+2))
+		args = append(args, status)
+	}
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE CampaignId = %[3]s
+	AND Status IN (%[4]s)
+	ORDER BY Id
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Deliveries{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+		strings.Join(statusParams, ", "),
+	)
+	rows, err := session.QueryContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "deliveries_Repo_FindByCampaignIDAndStatuses", campaignID, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Deliveries{}
+	for rows.Next() {
+		var entity entity.Deliveries
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// Upsert keeps one row per campaign and subscriber, the same way the backend
+// does. Attempts on the given delivery is added to the stored count, so the
+// row reflects the latest hand-off to SMTP and every try made so far.
+func (repo *SqlRepository) Upsert(delivery entity.Deliveries) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	MERGE %[1]s WITH (HOLDLOCK) AS target
+	USING (SELECT %[2]s AS CampaignId, %[3]s AS SubscriberId) AS source
+	ON target.CampaignId = source.CampaignId
+	AND target.SubscriberId = source.SubscriberId
+	WHEN MATCHED THEN
+		UPDATE SET
+			Email = %[4]s,
+			Status = %[5]s,
+			Attempts = target.Attempts + %[8]s,
+			LastError = %[6]s,
+			ReplyCode = %[9]s,
+			SentDate = %[7]s,
+			UpdatedDate = GETDATE()
+	WHEN NOT MATCHED THEN
+		INSERT ([CampaignId], [SubscriberId], [Email], [Status], [Attempts], [LastError], [ReplyCode], [SentDate], [CreatedDate])
+		VALUES (source.CampaignId, source.SubscriberId, %[4]s, %[5]s, %[8]s, %[6]s, %[9]s, %[7]s, GETDATE());
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		sqlQuery.Param(4),
+		sqlQuery.Param(5),
+		sqlQuery.Param(6),
+		sqlQuery.Param(7),
+		sqlQuery.Param(8),
+	)
+
+	_, err := session.ExecContext(ctx, sql,
+		delivery.CampaignID,
+		delivery.SubscriberID,
+		delivery.Email,
+		delivery.Status,
+		delivery.LastError,
+		delivery.SentDate,
+		delivery.Attempts,
+		delivery.ReplyCode,
+	)
+	if err != nil {
+		go repo.Logs.Error("", "deliveries_Repo_Upsert", delivery, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
+
+func (repo *SqlRepository) UpdateStatusByCampaignID(campaignID int64, fromStatus entity.DeliveryStatus, toStatus entity.DeliveryStatus) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[4]s,
+		UpdatedDate = GETDATE()
+	WHERE CampaignId = %[2]s
+	AND Status = %[3]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+	)
+
+	res, err := session.ExecContext(ctx, sql, campaignID, fromStatus, toStatus)
+	if err != nil {
+		go repo.Logs.Error("", "deliveries_Repo_UpdateStatusByCampaignID", campaignID, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
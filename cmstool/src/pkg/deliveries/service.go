@@ -0,0 +1,146 @@
+package deliveries
+
+import (
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	"time"
+)
+
+const maxLastErrorLength = 1000
+
+type ServiceParam struct {
+	Repo Repository
+	Logs logger.Logger
+}
+
+type UseCase interface {
+	GetDeadLetters(campaignID int64) ([]entity.Deliveries, *subscribetoolError.ErrorCode)
+	FindHandledSubscriberIDs(campaignID int64) (map[int64]bool, *subscribetoolError.ErrorCode)
+	RecordSent(campaignID int64, subscriberID int64, email string, attempts int) *subscribetoolError.ErrorCode
+	RecordFailed(campaignID int64, subscriberID int64, email string, attempts int, reason string) *subscribetoolError.ErrorCode
+	RecordDeadLetter(campaignID int64, subscriberID int64, email string, attempts int, replyCode int, reason string) *subscribetoolError.ErrorCode
+	RequeueDeadLetters(campaignID int64) (int64, *subscribetoolError.ErrorCode)
+}
+
+// Service keeps the delivery log of campaigns in TB_TRN_Deliveries, one row
+// per campaign and subscriber.
+type Service struct {
+	UseCase
+	Repo Repository
+	Logs logger.Logger
+}
+
+func NewService(serviceParam ServiceParam) *Service {
+	service := &Service{
+		Repo: serviceParam.Repo,
+		Logs: serviceParam.Logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+func (service *Service) GetDeadLetters(campaignID int64) ([]entity.Deliveries, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignIDAndStatuses(campaignID, []entity.DeliveryStatus{entity.DeliveryStatusDead})
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	return res, nil
+}
+
+// FindHandledSubscriberIDs returns the subscribers a send should skip: those
+// the campaign was already delivered to and those dead-lettered, which only
+// come back through RequeueDeadLetters.
+func (service *Service) FindHandledSubscriberIDs(campaignID int64) (map[int64]bool, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.FindByCampaignIDAndStatuses(campaignID, []entity.DeliveryStatus{
+		entity.DeliveryStatusSent,
+		entity.DeliveryStatusDead,
+	})
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	handled := map[int64]bool{}
+	for _, delivery := range res {
+		handled[delivery.SubscriberID] = true
+	}
+
+	return handled, nil
+}
+
+func (service *Service) RecordSent(campaignID int64, subscriberID int64, email string, attempts int) *subscribetoolError.ErrorCode {
+	sentDate := time.Now()
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusSent,
+		Attempts:     attempts,
+		SentDate:     &sentDate,
+	})
+}
+
+func (service *Service) RecordFailed(campaignID int64, subscriberID int64, email string, attempts int, reason string) *subscribetoolError.ErrorCode {
+	reason = truncateReason(reason)
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusFailed,
+		Attempts:     attempts,
+		LastError:    &reason,
+	})
+}
+
+func (service *Service) RecordDeadLetter(campaignID int64, subscriberID int64, email string, attempts int, replyCode int, reason string) *subscribetoolError.ErrorCode {
+	reason = truncateReason(reason)
+
+	return service.upsert(entity.Deliveries{
+		CampaignID:   campaignID,
+		SubscriberID: subscriberID,
+		Email:        email,
+		Status:       entity.DeliveryStatusDead,
+		Attempts:     attempts,
+		LastError:    &reason,
+		ReplyCode:    &replyCode,
+	})
+}
+
+// RequeueDeadLetters moves the dead letters of a campaign back to failed, so
+// the next send of the campaign tries them again.
+func (service *Service) RequeueDeadLetters(campaignID int64) (int64, *subscribetoolError.ErrorCode) {
+	count, err := service.Repo.UpdateStatusByCampaignID(campaignID, entity.DeliveryStatusDead, entity.DeliveryStatusFailed)
+	if err != nil {
+		return 0, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if count == 0 {
+		return 0, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	return count, nil
+}
+
+func (service *Service) upsert(delivery entity.Deliveries) *subscribetoolError.ErrorCode {
+	err := service.Repo.Upsert(delivery)
+	if err != nil {
+		return convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	return nil
+}
+
+func truncateReason(reason string) string {
+	if runes := []rune(reason); len(runes) > maxLastErrorLength {
+		return string(runes[:maxLastErrorLength])
+	}
+	return reason
+}
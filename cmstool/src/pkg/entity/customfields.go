@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+type FieldType string
+
+const (
+	FieldTypeString FieldType = "string"
+	FieldTypeNumber FieldType = "number"
+	FieldTypeDate   FieldType = "date"
+	FieldTypeBool   FieldType = "bool"
+	FieldTypeEnum   FieldType = "enum"
+)
+
+// CustomFields is the part of a custom field definition segment rules need.
+// Options is a comma separated list of the values an enum field accepts.
+type CustomFields struct {
+	ID      int64     `json:"id" sql:"id"`
+	Key     string    `json:"key" sql:"key"`
+	Type    FieldType `json:"type" sql:"type"`
+	Options string    `json:"options" sql:"options"`
+}
+
+// SubscriberFieldValues is one stored value, as the backend writes it. Only
+// the column for the field type is set; Key and Type are read from the field
+// definition.
+type SubscriberFieldValues struct {
+	SubscriberID int64      `json:"subscriberId" sql:"subscriberId"`
+	FieldID      int64      `json:"fieldId" sql:"fieldId"`
+	Key          string     `json:"key" sql:"key"`
+	Type         FieldType  `json:"type" sql:"type"`
+	StringValue  *string    `json:"stringValue" sql:"stringValue"`
+	NumberValue  *float64   `json:"numberValue" sql:"numberValue"`
+	DateValue    *time.Time `json:"dateValue" sql:"dateValue"`
+	BoolValue    *bool      `json:"boolValue" sql:"boolValue"`
+}
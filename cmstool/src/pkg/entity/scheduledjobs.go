@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+type JobStatus string
+
+const (
+	JobStatusPending JobStatus = "pending"
+	JobStatusRunning JobStatus = "running"
+	JobStatusDone    JobStatus = "done"
+	JobStatusFailed  JobStatus = "failed"
+)
+
+// ScheduledJobs is a send waiting for SendAt. It goes to the subscribers on
+// ListID or matching SegmentID, or to every subscriber when both are nil.
+// ClaimedBy names the cmstool instance running it.
+type ScheduledJobs struct {
+	ID           int64      `json:"id" sql:"id"`
+	Subject      string     `json:"subject" sql:"subject"`
+	Body         string     `json:"body" sql:"body"`
+	ListID       *int64     `json:"listId" sql:"listId"`
+	SegmentID    *int64     `json:"segmentId" sql:"segmentId"`
+	SendAt       *time.Time `json:"sendAt" sql:"sendAt"`
+	Status       JobStatus  `json:"status" sql:"status"`
+	ClaimedBy    *string    `json:"claimedBy" sql:"claimedBy"`
+	ClaimedDate  *time.Time `json:"claimedDate" sql:"claimedDate"`
+	FinishedDate *time.Time `json:"finishedDate" sql:"finishedDate"`
+	ErrorCode    *string    `json:"errorCode" sql:"errorCode"`
+	CreatedDate  *time.Time `json:"createdDate" sql:"createdDate"`
+	DelFlag      *bool      `json:"delFlag" sql:"delFlag"`
+}
@@ -10,4 +10,5 @@ type Subscribers struct {
 	SubscribedDate   *time.Time `json:"subscribedDate" sql:"subscribedDate"`
 	UnsubscribedDate *time.Time `json:"unsubscribedDate" sql:"unsubscribedDate"`
 	DelFlag          *bool      `json:"delFlag" sql:"delFlag"`
+	TimeZone         *string    `json:"timeZone" sql:"timeZone"`
 }
@@ -0,0 +1,104 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "subscribetool/src/pkg/entity"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// ClaimDue provides a mock function with given fields: worker
+func (_m *Repository) ClaimDue(worker string) ([]entity.ScheduledJobs, error) {
+	ret := _m.Called(worker)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDue")
+	}
+
+	var r0 []entity.ScheduledJobs
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]entity.ScheduledJobs, error)); ok {
+		return rf(worker)
+	}
+	if rf, ok := ret.Get(0).(func(string) []entity.ScheduledJobs); ok {
+		r0 = rf(worker)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.ScheduledJobs)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(worker)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Finish provides a mock function with given fields: id, status, errorCode
+func (_m *Repository) Finish(id int64, status entity.JobStatus, errorCode *string) error {
+	ret := _m.Called(id, status, errorCode)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Finish")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(int64, entity.JobStatus, *string) error); ok {
+		r0 = rf(id, status, errorCode)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Insert provides a mock function with given fields: job
+func (_m *Repository) Insert(job entity.ScheduledJobs) (int64, error) {
+	ret := _m.Called(job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Insert")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(entity.ScheduledJobs) (int64, error)); ok {
+		return rf(job)
+	}
+	if rf, ok := ret.Get(0).(func(entity.ScheduledJobs) int64); ok {
+		r0 = rf(job)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.ScheduledJobs) error); ok {
+		r1 = rf(job)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
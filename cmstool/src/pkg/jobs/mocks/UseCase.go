@@ -0,0 +1,89 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	entity "subscribetool/src/pkg/entity"
+	error "subscribetool/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// RunDue provides a mock function with given fields: worker
+func (_m *UseCase) RunDue(worker string) (bool, *error.ErrorCode) {
+	ret := _m.Called(worker)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RunDue")
+	}
+
+	var r0 bool
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(string) (bool, *error.ErrorCode)); ok {
+		return rf(worker)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(worker)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) *error.ErrorCode); ok {
+		r1 = rf(worker)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// Schedule provides a mock function with given fields: job
+func (_m *UseCase) Schedule(job entity.ScheduledJobs) (int64, *error.ErrorCode) {
+	ret := _m.Called(job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Schedule")
+	}
+
+	var r0 int64
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(entity.ScheduledJobs) (int64, *error.ErrorCode)); ok {
+		return rf(job)
+	}
+	if rf, ok := ret.Get(0).(func(entity.ScheduledJobs) int64); ok {
+		r0 = rf(job)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(entity.ScheduledJobs) *error.ErrorCode); ok {
+		r1 = rf(job)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
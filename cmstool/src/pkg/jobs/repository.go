@@ -0,0 +1,160 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"subscribetool/src/pkg/entity"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	Insert(job entity.ScheduledJobs) (int64, error)
+	ClaimDue(worker string) ([]entity.ScheduledJobs, error)
+	Finish(id int64, status entity.JobStatus, errorCode *string) error
+}
+
+type SqlRepository struct {
+	Collection string
+	Session    *sql.DB
+	Logs       logger.Logger
+}
+
+func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection: collection,
+		Session:    session,
+		Logs:       logs,
+	}
+}
+
+func (repo *SqlRepository) Insert(job entity.ScheduledJobs) (int64, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return 0, sessionErr
+	}
+	defer session.Close()
+
+	job.Status = entity.JobStatusPending
+	ignoreFields := []string{"ID", "ClaimedBy", "ClaimedDate", "FinishedDate", "ErrorCode", "CreatedDate", "DelFlag"}
+	params, args := sqlQuery.GenerateQueryColumnParams(job, ignoreFields, 0)
+
+	sql := fmt.Sprintf(`
+	INSERT INTO %[1]s
+	(
+		%[2]s,
+		[CreatedDate],
+		[Delflag]
+	)
+	OUTPUT INSERTED.Id
+	VALUES
+	(
+		%[3]s,
+		GETDATE(),
+		0
+	)
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNames(entity.ScheduledJobs{}, ignoreFields),
+		params,
+	)
+
+	var id int64
+	err := session.QueryRowContext(ctx, sql, args...).Scan(&id)
+	if err != nil {
+		go repo.Logs.Error("", "jobs_Repo_Insert", job, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// ClaimDue marks the earliest due pending job as running by worker and
+// returns it, or nothing when no job is due. The select and update are one
+// statement: UPDLOCK holds the row until it is updated and READPAST lets
+// other instances skip it, so a job is only ever claimed once.
+func (repo *SqlRepository) ClaimDue(worker string) ([]entity.ScheduledJobs, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	WITH due AS (
+		SELECT TOP (1) *
+		FROM %[1]s WITH (ROWLOCK, UPDLOCK, READPAST)
+		WHERE Status = %[3]s
+		AND Delflag = 0
+		AND SendAt <= SYSDATETIMEOFFSET()
+		ORDER BY SendAt ASC, Id ASC
+	)
+	UPDATE due
+	SET
+		Status = %[4]s,
+		ClaimedBy = %[5]s,
+		ClaimedDate = GETDATE()
+	OUTPUT %[2]s
+	`,
+		repo.Collection,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.ScheduledJobs{}, []string{}, "INSERTED"),
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+	)
+	rows, err := session.QueryContext(ctx, sql, entity.JobStatusPending, entity.JobStatusRunning, worker)
+	if err != nil {
+		go repo.Logs.Error("", "jobs_Repo_ClaimDue", worker, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.ScheduledJobs{}
+	for rows.Next() {
+		var entity entity.ScheduledJobs
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) Finish(id int64, status entity.JobStatus, errorCode *string) error {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	UPDATE %[1]s
+	SET
+		Status = %[3]s,
+		ErrorCode = %[4]s,
+		FinishedDate = GETDATE()
+	WHERE Id = %[2]s
+	AND Status = %[5]s
+	`,
+		repo.Collection,
+		sqlQuery.Param(1),
+		sqlQuery.Param(2),
+		sqlQuery.Param(3),
+		sqlQuery.Param(4),
+	)
+
+	_, err := session.ExecContext(ctx, sql, id, status, errorCode, entity.JobStatusRunning)
+	if err != nil {
+		go repo.Logs.Error("", "jobs_Repo_Finish", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package jobs_test
+
+import (
+	"errors"
+	"strings"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/jobs"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const injectionPayload = "x'); DROP TABLE TB_TRN_ScheduledJobs;--"
+
+var (
+	sqlMock  sqlmock.Sqlmock
+	repo     *jobs.SqlRepository
+	repoLogs *loggerMocks.Logger
+)
+
+// payloadFreeQuery accepts a query only when it is parameterized and does not
+// contain the injection payload inline.
+var payloadFreeQuery = sqlmock.QueryMatcherFunc(func(expectedSQL, actualSQL string) error {
+	if strings.Contains(actualSQL, injectionPayload) {
+		return errors.New("query contains inlined payload")
+	}
+	if !strings.Contains(actualSQL, expectedSQL) {
+		return errors.New("query does not contain " + expectedSQL)
+	}
+	return nil
+})
+
+func beforeEachRepository(t *testing.T) {
+	db, mockDB, err := sqlmock.New(sqlmock.QueryMatcherOption(payloadFreeQuery))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	repoLogs = &loggerMocks.Logger{}
+	repoLogs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	sqlMock = mockDB
+	repo = jobs.NewRepository("TB_TRN_ScheduledJobs", db, repoLogs)
+}
+
+func TestRepository_Insert(t *testing.T) {
+	listID := int64(3)
+	sendAt := time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	t.Run("should pass subject and body verbatim as parameters when insert", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("VALUES\n\t(\n\t\t@p1,@p2,@p3,@p4,@p5,@p6,").
+			WithArgs(injectionPayload, injectionPayload, listID, nil, sendAt, entity.JobStatusPending).
+			WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+		id, err := repo.Insert(entity.ScheduledJobs{
+			Subject: injectionPayload,
+			Body:    injectionPayload,
+			ListID:  &listID,
+			SendAt:  &sendAt,
+		})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(7), id)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+
+	t.Run("should return error when insert failed", func(t *testing.T) {
+		beforeEachRepository(t)
+		sqlMock.ExpectQuery("INSERT INTO TB_TRN_ScheduledJobs").WillReturnError(errors.New("Error"))
+
+		id, err := repo.Insert(entity.ScheduledJobs{Subject: "subject", Body: "body", SendAt: &sendAt})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, int64(0), id)
+	})
+}
+
+func TestRepository_Finish(t *testing.T) {
+	t.Run("should pass error code verbatim as parameter when finish", func(t *testing.T) {
+		beforeEachRepository(t)
+		errorCode := injectionPayload
+		sqlMock.ExpectExec("WHERE Id = @p1").
+			WithArgs(int64(7), entity.JobStatusFailed, &errorCode, entity.JobStatusRunning).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+
+		err := repo.Finish(7, entity.JobStatusFailed, &errorCode)
+
+		assert.Nil(t, err)
+		assert.Nil(t, sqlMock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,69 @@
+package jobs
+
+import (
+	"log"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"time"
+)
+
+// Runner does one piece of due work as worker, reporting whether there was
+// any. Scheduled jobs and queued campaigns are both run this way.
+type Runner interface {
+	RunDue(worker string) (bool, *subscribetoolError.ErrorCode)
+}
+
+// Scheduler runs due work from Runners as Worker until it is stopped.
+type Scheduler struct {
+	Runners  []Runner
+	Worker   string
+	Interval time.Duration
+}
+
+func NewScheduler(worker string, interval time.Duration, runners ...Runner) *Scheduler {
+	return &Scheduler{
+		Runners:  runners,
+		Worker:   worker,
+		Interval: interval,
+	}
+}
+
+// Run asks each runner in turn for due work, going straight round again while
+// any had some and checking again every Interval once none do. It returns
+// when stop is closed, after the work in hand has finished, so a job or
+// campaign is never left half sent by a shutdown.
+func (scheduler *Scheduler) Run(stop <-chan struct{}) {
+	for {
+		busy := false
+		for _, runner := range scheduler.Runners {
+			if stopped(stop) {
+				return
+			}
+
+			ran, err := runner.RunDue(scheduler.Worker)
+			if err != nil {
+				log.Println("Run due work err : ", *err)
+			}
+			if ran && err == nil {
+				busy = true
+			}
+		}
+		if busy {
+			continue
+		}
+
+		select {
+		case <-stop:
+			return
+		case <-time.After(scheduler.Interval):
+		}
+	}
+}
+
+func stopped(stop <-chan struct{}) bool {
+	select {
+	case <-stop:
+		return true
+	default:
+		return false
+	}
+}
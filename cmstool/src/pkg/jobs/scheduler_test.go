@@ -0,0 +1,91 @@
+package jobs_test
+
+import (
+	"subscribetool/src/pkg/jobs"
+	"subscribetool/src/pkg/jobs/mocks"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestScheduler_NewScheduler(t *testing.T) {
+	t.Run("should return struct scheduler when call new scheduler", func(t *testing.T) {
+		jobsService := &mocks.UseCase{}
+
+		resScheduler := jobs.NewScheduler("host-1", time.Minute, jobsService)
+
+		assert.Equal(t, &jobs.Scheduler{Runners: []jobs.Runner{jobsService}, Worker: "host-1", Interval: time.Minute}, resScheduler)
+	})
+}
+
+func TestScheduler_Run(t *testing.T) {
+	t.Run("should run due jobs back to back then wait until stopped", func(t *testing.T) {
+		idle := make(chan struct{}, 1)
+		jobsService := &mocks.UseCase{}
+		jobsService.On("RunDue", "host-1").Return(true, nil).Twice()
+		jobsService.On("RunDue", "host-1").Return(false, nil).Run(func(mock.Arguments) { idle <- struct{}{} })
+		scheduler := jobs.NewScheduler("host-1", time.Hour, jobsService)
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			scheduler.Run(stop)
+			close(done)
+		}()
+
+		select {
+		case <-idle:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not run due jobs")
+		}
+		close(stop)
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not stop")
+		}
+		jobsService.AssertNumberOfCalls(t, "RunDue", 3)
+	})
+
+	t.Run("should keep asking every runner while any has due work", func(t *testing.T) {
+		idle := make(chan struct{}, 1)
+		jobsService := &mocks.UseCase{}
+		jobsService.On("RunDue", "host-1").Return(false, nil)
+		campaignsService := &mocks.UseCase{}
+		campaignsService.On("RunDue", "host-1").Return(true, nil).Once()
+		campaignsService.On("RunDue", "host-1").Return(false, nil).Run(func(mock.Arguments) { idle <- struct{}{} })
+		scheduler := jobs.NewScheduler("host-1", time.Hour, jobsService, campaignsService)
+
+		stop := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			scheduler.Run(stop)
+			close(done)
+		}()
+
+		select {
+		case <-idle:
+		case <-time.After(time.Second):
+			t.Fatal("scheduler did not run due work")
+		}
+		close(stop)
+		<-done
+
+		jobsService.AssertNumberOfCalls(t, "RunDue", 2)
+		campaignsService.AssertNumberOfCalls(t, "RunDue", 2)
+	})
+
+	t.Run("should return without running when already stopped", func(t *testing.T) {
+		jobsService := &mocks.UseCase{}
+		scheduler := jobs.NewScheduler("host-1", time.Hour, jobsService)
+		stop := make(chan struct{})
+		close(stop)
+
+		scheduler.Run(stop)
+
+		jobsService.AssertNotCalled(t, "RunDue", mock.Anything)
+	})
+}
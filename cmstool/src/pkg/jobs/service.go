@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"strings"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/subscribers"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+)
+
+type ServiceParam struct {
+	Repo        Repository
+	Subscribers subscribers.UseCase
+	Logs        logger.Logger
+}
+
+type UseCase interface {
+	Schedule(job entity.ScheduledJobs) (int64, *subscribetoolError.ErrorCode)
+	RunDue(worker string) (bool, *subscribetoolError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo        Repository
+	Subscribers subscribers.UseCase
+	Logs        logger.Logger
+}
+
+func NewService(serviceParam ServiceParam) *Service {
+	service := &Service{
+		Repo:        serviceParam.Repo,
+		Subscribers: serviceParam.Subscribers,
+		Logs:        serviceParam.Logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+// Schedule stores a pending job. A SendAt already past is due right away, and
+// a subject or body that does not parse as a template is refused.
+func (service *Service) Schedule(job entity.ScheduledJobs) (int64, *subscribetoolError.ErrorCode) {
+	job.Subject = strings.TrimSpace(job.Subject)
+	if job.Subject == "" || strings.TrimSpace(job.Body) == "" || job.SendAt == nil {
+		return 0, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest)
+	}
+
+	if job.ListID != nil && job.SegmentID != nil {
+		return 0, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest)
+	}
+
+	_, errTemplate := service.Subscribers.ParseContent(subscribers.Content{Subject: job.Subject, Body: job.Body})
+	if errTemplate != nil {
+		return 0, errTemplate
+	}
+
+	id, err := service.Repo.Insert(job)
+	if err != nil {
+		return 0, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	return id, nil
+}
+
+// RunDue claims one due job for worker and sends it, reporting whether there
+// was one. How the send went is recorded on the job, which fails when any of
+// its emails did; the error returned is only about claiming or recording it.
+func (service *Service) RunDue(worker string) (bool, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.ClaimDue(worker)
+	if err != nil {
+		return false, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return false, nil
+	}
+
+	job := res[0]
+	target := subscribers.Target{
+		ListID:    job.ListID,
+		SegmentID: job.SegmentID,
+	}
+	content := subscribers.Content{
+		Subject: job.Subject,
+		Body:    job.Body,
+	}
+
+	status := entity.JobStatusDone
+	var errorCode *string
+	resReport, errSend := service.Subscribers.SentEmail(target, content)
+	if errSend == nil && len(resReport.Failed) > 0 {
+		errSend = convert.ValueToErrorCodePointer(subscribetoolError.EmailNotSent)
+	}
+	if errSend != nil {
+		go service.Logs.Error("", "jobs_Service_RunDue", job.ID, subscribetoolError.NewError(*errSend, "scheduled job failed"))
+		status = entity.JobStatusFailed
+		code := string(*errSend)
+		errorCode = &code
+	}
+
+	errFinish := service.Repo.Finish(job.ID, status, errorCode)
+	if errFinish != nil {
+		return true, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	return true, nil
+}
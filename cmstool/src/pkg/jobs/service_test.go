@@ -0,0 +1,223 @@
+package jobs_test
+
+import (
+	"errors"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/jobs"
+	"subscribetool/src/pkg/jobs/mocks"
+	"subscribetool/src/pkg/subscribers"
+	subscribersMocks "subscribetool/src/pkg/subscribers/mocks"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"subscribetool/src/pkg/utils/mocker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository         *mocks.Repository
+	subscribersService *subscribersMocks.UseCase
+	service            *jobs.Service
+	logs               *loggerMocks.Logger
+
+	mockRepoInsert           *mocker.MockCall
+	mockRepoClaimDue         *mocker.MockCall
+	mockRepoFinish           *mocker.MockCall
+	mockSubscribersSentEmail *mocker.MockCall
+	mockSubscribersParse     *mocker.MockCall
+)
+
+func callRepoInsert() *mock.Call {
+	return repository.On("Insert", mock.Anything)
+}
+
+func callRepoClaimDue() *mock.Call {
+	return repository.On("ClaimDue", mock.Anything)
+}
+
+func callRepoFinish() *mock.Call {
+	return repository.On("Finish", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func callSubscribersSentEmail() *mock.Call {
+	return subscribersService.On("SentEmail", mock.Anything, mock.Anything)
+}
+
+func callSubscribersParseContent() *mock.Call {
+	return subscribersService.On("ParseContent", mock.Anything)
+}
+
+func beforeEach() {
+	repository = &mocks.Repository{}
+	subscribersService = &subscribersMocks.UseCase{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &jobs.Service{
+		Repo:        repository,
+		Subscribers: subscribersService,
+		Logs:        logs,
+	}
+	service.UseCase = service
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct jobs service when call new service", func(t *testing.T) {
+		beforeEach()
+		serviceParam := jobs.ServiceParam{
+			Repo:        repository,
+			Subscribers: subscribersService,
+			Logs:        logs,
+		}
+		resService := jobs.NewService(serviceParam)
+
+		expectedService := &jobs.Service{
+			Repo:        repository,
+			Subscribers: subscribersService,
+			Logs:        logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_Schedule(t *testing.T) {
+	sendAt := time.Date(2023, 1, 2, 9, 0, 0, 0, time.FixedZone("ICT", 7*60*60))
+	listID := int64(3)
+	segmentID := int64(5)
+
+	beforeEachSchedule := func() {
+		beforeEach()
+		mockRepoInsert = mocker.NewMockCall(callRepoInsert)
+		mockRepoInsert.Return(int64(9), nil)
+		mockSubscribersParse = mocker.NewMockCall(callSubscribersParseContent)
+		mockSubscribersParse.Return(nil, nil)
+	}
+
+	t.Run("should insert job with trimmed subject and return its id", func(t *testing.T) {
+		beforeEachSchedule()
+
+		id, err := service.Schedule(entity.ScheduledJobs{Subject: " news ", Body: "<p>hi</p>", ListID: &listID, SendAt: &sendAt})
+
+		assert.Nil(t, err)
+		assert.Equal(t, int64(9), id)
+		repository.AssertCalled(t, "Insert", entity.ScheduledJobs{Subject: "news", Body: "<p>hi</p>", ListID: &listID, SendAt: &sendAt})
+	})
+
+	t.Run("should return bad request when send time is missing", func(t *testing.T) {
+		beforeEachSchedule()
+
+		_, err := service.Schedule(entity.ScheduledJobs{Subject: "news", Body: "<p>hi</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest), err)
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return bad request when job targets both list and segment", func(t *testing.T) {
+		beforeEachSchedule()
+
+		_, err := service.Schedule(entity.ScheduledJobs{Subject: "news", Body: "<p>hi</p>", ListID: &listID, SegmentID: &segmentID, SendAt: &sendAt})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest), err)
+	})
+
+	t.Run("should return invalid template and not insert when subject or body does not parse", func(t *testing.T) {
+		beforeEachSchedule()
+		mockSubscribersParse.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		_, err := service.Schedule(entity.ScheduledJobs{Subject: "news", Body: "<p>{{.Unknown}}</p>", SendAt: &sendAt})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		subscribersService.AssertCalled(t, "ParseContent", subscribers.Content{Subject: "news", Body: "<p>{{.Unknown}}</p>"})
+		repository.AssertNotCalled(t, "Insert", mock.Anything)
+	})
+
+	t.Run("should return internal server error when insert failed", func(t *testing.T) {
+		beforeEachSchedule()
+		mockRepoInsert.Return(int64(0), errors.New("Error"))
+
+		_, err := service.Schedule(entity.ScheduledJobs{Subject: "news", Body: "<p>hi</p>", SendAt: &sendAt})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+}
+
+func TestService_RunDue(t *testing.T) {
+	segmentID := int64(5)
+
+	beforeEachRunDue := func() {
+		beforeEach()
+		mockRepoClaimDue = mocker.NewMockCall(callRepoClaimDue)
+		mockRepoClaimDue.Return([]entity.ScheduledJobs{{ID: 9, Subject: "news", Body: "<p>hi</p>", SegmentID: &segmentID}}, nil)
+		mockSubscribersSentEmail = mocker.NewMockCall(callSubscribersSentEmail)
+		mockSubscribersSentEmail.Return(&subscribers.Report{Sent: []entity.Subscribers{{Email: "ajis@gmail.com"}}}, nil)
+		mockRepoFinish = mocker.NewMockCall(callRepoFinish)
+		mockRepoFinish.Return(nil)
+	}
+
+	t.Run("should send claimed job to its target and mark it done", func(t *testing.T) {
+		beforeEachRunDue()
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		repository.AssertCalled(t, "ClaimDue", "host-1")
+		subscribersService.AssertCalled(t, "SentEmail", subscribers.Target{SegmentID: &segmentID}, subscribers.Content{Subject: "news", Body: "<p>hi</p>"})
+		repository.AssertCalled(t, "Finish", int64(9), entity.JobStatusDone, (*string)(nil))
+	})
+
+	t.Run("should return false without sending when no job is due", func(t *testing.T) {
+		beforeEachRunDue()
+		mockRepoClaimDue.Return([]entity.ScheduledJobs{}, nil)
+
+		ran, err := service.RunDue("host-1")
+
+		assert.False(t, ran)
+		assert.Nil(t, err)
+		subscribersService.AssertNotCalled(t, "SentEmail", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should mark job failed with error code when send failed", func(t *testing.T) {
+		beforeEachRunDue()
+		mockSubscribersSentEmail.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		code := string(subscribetoolError.DataNotFound)
+		repository.AssertCalled(t, "Finish", int64(9), entity.JobStatusFailed, &code)
+	})
+
+	t.Run("should mark job failed when some emails failed", func(t *testing.T) {
+		beforeEachRunDue()
+		mockSubscribersSentEmail.Return(&subscribers.Report{
+			Sent:   []entity.Subscribers{{Email: "ajis@gmail.com"}},
+			Failed: []subscribers.Failure{{Subscriber: entity.Subscribers{Email: "mana@gmail.com"}, Error: "550 mailbox unavailable"}},
+		}, nil)
+
+		ran, err := service.RunDue("host-1")
+
+		assert.True(t, ran)
+		assert.Nil(t, err)
+		code := string(subscribetoolError.EmailNotSent)
+		repository.AssertCalled(t, "Finish", int64(9), entity.JobStatusFailed, &code)
+	})
+
+	t.Run("should return internal server error when claim failed", func(t *testing.T) {
+		beforeEachRunDue()
+		mockRepoClaimDue.Return(nil, errors.New("Error"))
+
+		ran, err := service.RunDue("host-1")
+
+		assert.False(t, ran)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+}
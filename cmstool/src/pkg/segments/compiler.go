@@ -0,0 +1,353 @@
+package segments
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"subscribetool/src/pkg/entity"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thoas/go-funk"
+)
+
+// Compiler turns segment rules into SQL the same way the backend previews
+// them. Custom field values are read from FieldValuesCollection and
+// engagement from DeliveriesCollection.
+type Compiler struct {
+	FieldValuesCollection string
+	DeliveriesCollection  string
+}
+
+func NewCompiler(fieldValuesCollection string, deliveriesCollection string) *Compiler {
+	return &Compiler{
+		FieldValuesCollection: fieldValuesCollection,
+		DeliveriesCollection:  deliveriesCollection,
+	}
+}
+
+// Compile returns rule as a condition. Rules were checked when the segment
+// was saved, so an error here means a custom field it uses changed since.
+func (compiler *Compiler) Compile(rule entity.SegmentRule, definitions []entity.CustomFields) (*Condition, error) {
+	compilation := &compilation{
+		compiler:    compiler,
+		definitions: map[string]entity.CustomFields{},
+		args:        []interface{}{},
+	}
+	for _, definition := range definitions {
+		compilation.definitions[definition.Key] = definition
+	}
+
+	where := compilation.rule(rule, RulesField, 1)
+	if compilation.conditions > MaxConditions {
+		compilation.fail(RulesField, "too many conditions")
+	}
+
+	if len(compilation.problems) > 0 {
+		return nil, errors.New("invalid segment rules: " + strings.Join(compilation.problems, ", "))
+	}
+
+	return &Condition{Where: where, Args: compilation.args}, nil
+}
+
+type compilation struct {
+	compiler    *Compiler
+	definitions map[string]entity.CustomFields
+	args        []interface{}
+	conditions  int
+	problems    []string
+}
+
+func (compilation *compilation) fail(path string, problem string) string {
+	compilation.problems = append(compilation.problems, path+" "+problem)
+	return ""
+}
+
+func (compilation *compilation) param(value interface{}) string {
+	compilation.args = append(compilation.args, value)
+	return sqlQuery.Param(len(compilation.args))
+}
+
+func (compilation *compilation) rule(rule entity.SegmentRule, path string, depth int) string {
+	if depth > MaxDepth {
+		return compilation.fail(path, "nested too deep")
+	}
+
+	kinds := 0
+	for _, set := range []bool{rule.And != nil, rule.Or != nil, rule.Not != nil, rule.Field != "", rule.Engagement != ""} {
+		if set {
+			kinds++
+		}
+	}
+	if kinds != 1 {
+		return compilation.fail(path, "must set exactly one of and, or, not, field or engagement")
+	}
+
+	switch {
+	case rule.And != nil:
+		return compilation.group(rule.And, " AND ", path+".and", depth)
+	case rule.Or != nil:
+		return compilation.group(rule.Or, " OR ", path+".or", depth)
+	case rule.Not != nil:
+		return "NOT " + compilation.rule(*rule.Not, path+".not", depth+1)
+	case rule.Field != "":
+		return compilation.comparison(rule, path)
+	}
+	return compilation.engagement(rule, path)
+}
+
+func (compilation *compilation) group(rules []entity.SegmentRule, separator string, path string, depth int) string {
+	if len(rules) == 0 {
+		return compilation.fail(path, "is empty")
+	}
+
+	parts := []string{}
+	for i, rule := range rules {
+		parts = append(parts, compilation.rule(rule, fmt.Sprintf("%s.%d", path, i), depth+1))
+	}
+	return "(" + strings.Join(parts, separator) + ")"
+}
+
+// comparison matches a subscriber field. neq and notSet also match
+// subscribers who have no value, so they are written as the negation of eq
+// and isSet.
+func (compilation *compilation) comparison(rule entity.SegmentRule, path string) string {
+	compilation.conditions++
+
+	column, fieldType, options, fieldID, ok := compilation.field(rule.Field)
+	if !ok {
+		return compilation.fail(path+".field", "is unknown")
+	}
+
+	if !funk.Contains(TypeOps[fieldType], rule.Op) {
+		return compilation.fail(path+".op", "is not supported for the field type")
+	}
+
+	op := rule.Op
+	negated := false
+	switch op {
+	case entity.SegmentOpNeq:
+		op, negated = entity.SegmentOpEq, true
+	case entity.SegmentOpNotSet:
+		op, negated = entity.SegmentOpIsSet, true
+	}
+
+	var predicate string
+	if fieldID != nil {
+		fieldParam := compilation.param(*fieldID)
+		predicate = fmt.Sprintf("EXISTS (SELECT 1 FROM %s v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = %s AND %s)",
+			compilation.compiler.FieldValuesCollection,
+			fieldParam,
+			compilation.predicate(column, fieldType, options, op, rule.Value, path),
+		)
+	} else {
+		predicate = compilation.predicate(column, fieldType, options, op, rule.Value, path)
+	}
+
+	if !negated {
+		return predicate
+	}
+	if fieldID != nil {
+		return "NOT " + predicate
+	}
+	if op == entity.SegmentOpIsSet {
+		return fmt.Sprintf("(%s IS NULL)", column)
+	}
+	return fmt.Sprintf("(%s IS NULL OR NOT %s)", column, predicate)
+}
+
+// field resolves the column a rule compares. fieldID is set for custom
+// fields, whose column is on the values table aliased v.
+func (compilation *compilation) field(name string) (column string, fieldType entity.FieldType, options []string, fieldID *int64, ok bool) {
+	if builtIn, found := BuiltInFields[name]; found {
+		return builtIn.Column, builtIn.Type, builtIn.Options, nil, true
+	}
+
+	if !strings.HasPrefix(name, CustomFieldPrefix) {
+		return "", "", nil, nil, false
+	}
+
+	definition, found := compilation.definitions[strings.TrimPrefix(name, CustomFieldPrefix)]
+	if !found {
+		return "", "", nil, nil, false
+	}
+
+	column = "v.[StringValue]"
+	switch definition.Type {
+	case entity.FieldTypeNumber:
+		column = "v.[NumberValue]"
+	case entity.FieldTypeDate:
+		column = "v.[DateValue]"
+	case entity.FieldTypeBool:
+		column = "v.[BoolValue]"
+	}
+
+	options = []string{}
+	for _, option := range strings.Split(definition.Options, OptionSeparator) {
+		if option = strings.TrimSpace(option); option != "" {
+			options = append(options, option)
+		}
+	}
+	return column, definition.Type, options, &definition.ID, true
+}
+
+func (compilation *compilation) predicate(column string, fieldType entity.FieldType, options []string, op entity.SegmentOp, value interface{}, path string) string {
+	valuePath := path + ".value"
+
+	switch op {
+	case entity.SegmentOpIsSet:
+		return fmt.Sprintf("(%s IS NOT NULL)", column)
+
+	case entity.SegmentOpWithinDays, entity.SegmentOpOlderThanDays:
+		days, ok := wholeDays(value)
+		if !ok {
+			return compilation.fail(valuePath, "is not a whole number of days")
+		}
+		comparison := ">="
+		if op == entity.SegmentOpOlderThanDays {
+			comparison = "<"
+		}
+		return fmt.Sprintf("(%s %s DATEADD(DAY, -%s, GETDATE()))", column, comparison, compilation.param(days))
+
+	case entity.SegmentOpContains, entity.SegmentOpStartsWith:
+		text, problem := normalizeValue(fieldType, options, value)
+		if problem != "" {
+			return compilation.fail(valuePath, problem)
+		}
+		pattern := escapeLike(text.(string)) + "%"
+		if op == entity.SegmentOpContains {
+			pattern = "%" + pattern
+		}
+		return fmt.Sprintf(`(%s LIKE %s ESCAPE '\')`, column, compilation.param(pattern))
+
+	case entity.SegmentOpIn:
+		values, ok := value.([]interface{})
+		if !ok || len(values) == 0 || len(values) > MaxInValues {
+			return compilation.fail(valuePath, "is not a list of values")
+		}
+		params := []string{}
+		for i, item := range values {
+			normalized, problem := normalizeValue(fieldType, options, item)
+			if problem != "" {
+				return compilation.fail(fmt.Sprintf("%s.%d", valuePath, i), problem)
+			}
+			params = append(params, compilation.param(normalized))
+		}
+		return fmt.Sprintf("(%s IN (%s))", column, strings.Join(params, ","))
+	}
+
+	normalized, problem := normalizeValue(fieldType, options, value)
+	if problem != "" {
+		return compilation.fail(valuePath, problem)
+	}
+
+	if fieldType == entity.FieldTypeDate {
+		column = fmt.Sprintf("CAST(%s AS date)", column)
+	}
+	comparisons := map[entity.SegmentOp]string{
+		entity.SegmentOpEq:  "=",
+		entity.SegmentOpGt:  ">",
+		entity.SegmentOpGte: ">=",
+		entity.SegmentOpLt:  "<",
+		entity.SegmentOpLte: "<=",
+	}
+	return fmt.Sprintf("(%s %s %s)", column, comparisons[op], compilation.param(normalized))
+}
+
+// engagement matches subscribers with a delivery recording the event,
+// optionally for one campaign or within the last WithinDays days.
+func (compilation *compilation) engagement(rule entity.SegmentRule, path string) string {
+	compilation.conditions++
+
+	status, ok := EngagementStatuses[rule.Engagement]
+	if !ok {
+		return compilation.fail(path+".engagement", "is unknown")
+	}
+
+	conditions := []string{
+		"d.[SubscriberId] = s.[Id]",
+		"d.[Status] = " + compilation.param(status),
+	}
+	if rule.CampaignID != nil {
+		conditions = append(conditions, "d.[CampaignId] = "+compilation.param(*rule.CampaignID))
+	}
+	if rule.WithinDays != nil {
+		if *rule.WithinDays < 1 || *rule.WithinDays > MaxDays {
+			return compilation.fail(path+".withinDays", "is out of range")
+		}
+		conditions = append(conditions, fmt.Sprintf("d.[SentDate] >= DATEADD(DAY, -%s, GETDATE())", compilation.param(*rule.WithinDays)))
+	}
+
+	return fmt.Sprintf("EXISTS (SELECT 1 FROM %s d WHERE %s)",
+		compilation.compiler.DeliveriesCollection,
+		strings.Join(conditions, " AND "),
+	)
+}
+
+// normalizeValue checks a single compared value against the field type and
+// returns it as the argument to pass: text trimmed, dates as time.Time. The
+// problem is empty when the value is fine.
+func normalizeValue(fieldType entity.FieldType, options []string, value interface{}) (interface{}, string) {
+	if value == nil {
+		return nil, "is required"
+	}
+
+	switch fieldType {
+	case entity.FieldTypeNumber:
+		number, ok := value.(float64)
+		if !ok || math.IsNaN(number) || math.IsInf(number, 0) {
+			return nil, "is not a number"
+		}
+		return number, ""
+
+	case entity.FieldTypeBool:
+		boolean, ok := value.(bool)
+		if !ok {
+			return nil, "is not a boolean"
+		}
+		return boolean, ""
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, "is not text"
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, "is required"
+	}
+	if utf8.RuneCountInString(text) > MaxLength {
+		return nil, "is too long"
+	}
+
+	switch fieldType {
+	case entity.FieldTypeDate:
+		date, err := time.Parse(DateLayout, text)
+		if err != nil {
+			return nil, "is not a date"
+		}
+		return date, ""
+
+	case entity.FieldTypeEnum:
+		if !funk.ContainsString(options, text) {
+			return nil, "is not an option"
+		}
+	}
+	return text, ""
+}
+
+func wholeDays(value interface{}) (int, bool) {
+	days, ok := value.(float64)
+	if !ok || days != math.Trunc(days) || days < 1 || days > MaxDays {
+		return 0, false
+	}
+	return int(days), true
+}
+
+// escapeLike makes text match itself literally inside a LIKE pattern that
+// uses \ as its escape character.
+func escapeLike(text string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`, `[`, `\[`).Replace(text)
+}
@@ -0,0 +1,197 @@
+package segments_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	compiler    = segments.NewCompiler("TB_TRN_SubscriberFieldValues", "TB_TRN_Deliveries")
+	definitions = []entity.CustomFields{
+		{ID: 4, Key: "country", Type: entity.FieldTypeString},
+		{ID: 6, Key: "birthday", Type: entity.FieldTypeDate},
+		{ID: 7, Key: "plan", Type: entity.FieldTypeEnum, Options: "free, pro"},
+	}
+)
+
+func parseRule(t *testing.T, rules string) entity.SegmentRule {
+	var rule entity.SegmentRule
+	if err := json.Unmarshal([]byte(rules), &rule); err != nil {
+		t.Fatal(err)
+	}
+	return rule
+}
+
+// backendCases are the rules the backend compiler is tested against; cmstool
+// must compile them the same way so previews match what is sent.
+const backendCases = "../../../../backend/src/pkg/segments/testdata/compile.json"
+
+// problemPath finds the rule path each problem in a compile error starts with.
+var problemPath = regexp.MustCompile(`(?:: |, )(rules[^ ]*) `)
+
+type sharedCases struct {
+	Definitions []entity.CustomFields `json:"definitions"`
+	Cases       []struct {
+		Name    string          `json:"name"`
+		Rules   json.RawMessage `json:"rules"`
+		Where   string          `json:"where"`
+		Args    json.RawMessage `json:"args"`
+		Invalid []string        `json:"invalid"`
+	} `json:"cases"`
+}
+
+func TestCompiler_Compile(t *testing.T) {
+	t.Run("should compile the same condition as the backend", func(t *testing.T) {
+		rule := parseRule(t, `{"and":[
+			{"field":"fields.country","op":"eq","value":"TH"},
+			{"field":"subscribedDate","op":"withinDays","value":30},
+			{"engagement":"received"},
+			{"not":{"field":"language","op":"neq","value":"th"}},
+			{"field":"email","op":"contains","value":"50%_off"},
+			{"field":"fields.birthday","op":"lt","value":"2000-01-01"}
+		]}`)
+
+		condition, err := compiler.Compile(rule, definitions)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "("+
+			"EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p1 AND (v.[StringValue] = @p2))"+
+			" AND (s.[SubscribedDate] >= DATEADD(DAY, -@p3, GETDATE()))"+
+			" AND EXISTS (SELECT 1 FROM TB_TRN_Deliveries d WHERE d.[SubscriberId] = s.[Id] AND d.[Status] = @p4)"+
+			" AND NOT (s.[Language] IS NULL OR NOT (s.[Language] = @p5))"+
+			" AND (s.[Email] LIKE @p6 ESCAPE '\\')"+
+			" AND EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p7 AND (CAST(v.[DateValue] AS date) < @p8))"+
+			")", condition.Where)
+		assert.Equal(t, []interface{}{int64(4), "TH", 30, "sent", "th", `%50\%\_off%`, int64(6), time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}, condition.Args)
+	})
+
+	t.Run("should compile in against trimmed enum options", func(t *testing.T) {
+		condition, err := compiler.Compile(parseRule(t, `{"field":"fields.plan","op":"in","value":["free","pro"]}`), definitions)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "EXISTS (SELECT 1 FROM TB_TRN_SubscriberFieldValues v WHERE v.[SubscriberId] = s.[Id] AND v.[FieldId] = @p1 AND (v.[StringValue] IN (@p2,@p3)))", condition.Where)
+		assert.Equal(t, []interface{}{int64(7), "free", "pro"}, condition.Args)
+	})
+
+	t.Run("should return error naming rule paths when custom field was deleted", func(t *testing.T) {
+		condition, err := compiler.Compile(parseRule(t, `{"or":[{"field":"fields.team","op":"isSet"},{"field":"fields.plan","op":"eq","value":"gold"}]}`), definitions)
+
+		assert.Nil(t, condition)
+		assert.EqualError(t, err, "invalid segment rules: rules.or.0.field is unknown, rules.or.1.value is not an option")
+	})
+
+	t.Run("should compile time zone rules like the backend", func(t *testing.T) {
+		condition, err := compiler.Compile(parseRule(t, `{"field":"timeZone","op":"eq","value":"Asia/Bangkok"}`), definitions)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "(s.[TimeZone] = @p1)", condition.Where)
+		assert.Equal(t, []interface{}{"Asia/Bangkok"}, condition.Args)
+	})
+
+	t.Run("should return error when value is longer than the backend allows", func(t *testing.T) {
+		value := strings.Repeat("ก", segments.MaxLength)
+		_, err := compiler.Compile(parseRule(t, `{"field":"name","op":"eq","value":"`+value+`"}`), definitions)
+		assert.Nil(t, err)
+
+		condition, err := compiler.Compile(parseRule(t, `{"field":"name","op":"in","value":["a","`+value+`x"]}`), definitions)
+
+		assert.Nil(t, condition)
+		assert.EqualError(t, err, "invalid segment rules: rules.value.1 is too long")
+	})
+
+	t.Run("should return error when rule sets nothing", func(t *testing.T) {
+		condition, err := compiler.Compile(entity.SegmentRule{}, definitions)
+
+		assert.Nil(t, condition)
+		assert.NotNil(t, err)
+	})
+}
+
+func TestCompiler_MatchesBackend(t *testing.T) {
+	source, err := os.ReadFile(backendCases)
+	if os.IsNotExist(err) {
+		t.Skip("backend is not checked out next to cmstool")
+	}
+	assert.Nil(t, err)
+	var shared sharedCases
+	assert.Nil(t, json.Unmarshal(source, &shared))
+
+	for _, sharedCase := range shared.Cases {
+		sharedCase := sharedCase
+		t.Run("should compile like the backend "+sharedCase.Name, func(t *testing.T) {
+			condition, err := compiler.Compile(parseRule(t, string(sharedCase.Rules)), shared.Definitions)
+
+			if sharedCase.Invalid != nil {
+				assert.Nil(t, condition)
+				if assert.NotNil(t, err) {
+					fields := []string{}
+					for _, problem := range problemPath.FindAllStringSubmatch(err.Error(), -1) {
+						fields = append(fields, problem[1])
+					}
+					assert.Equal(t, sharedCase.Invalid, fields)
+				}
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, sharedCase.Where, condition.Where)
+			args, err := json.Marshal(condition.Args)
+			assert.Nil(t, err)
+			assert.JSONEq(t, string(sharedCase.Args), string(args))
+		})
+	}
+}
+
+func TestBuiltInFields(t *testing.T) {
+	t.Run("should match the fields the backend accepts", func(t *testing.T) {
+		source, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "backend", "src", "pkg", "segments", "model.go"))
+		if os.IsNotExist(err) {
+			t.Skip("backend source is not checked out next to cmstool")
+		}
+		assert.Nil(t, err)
+
+		fields := regexp.MustCompile(`"(\w+)":\s+\{Column: "([^"]+)", Type: entity\.FieldType(\w+)`).FindAllStringSubmatch(string(source), -1)
+		assert.NotEmpty(t, fields)
+		assert.Len(t, segments.BuiltInFields, len(fields))
+		for _, field := range fields {
+			builtIn, ok := segments.BuiltInFields[field[1]]
+			assert.True(t, ok, field[1])
+			assert.Equal(t, field[2], builtIn.Column, field[1])
+			assert.Equal(t, entity.FieldType(strings.ToLower(field[3])), builtIn.Type, field[1])
+		}
+	})
+
+	t.Run("should offer the frequencies the backend accepts", func(t *testing.T) {
+		source, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "backend", "src", "pkg", "subscribers", "model.go"))
+		if os.IsNotExist(err) {
+			t.Skip("backend source is not checked out next to cmstool")
+		}
+		assert.Nil(t, err)
+
+		frequencies := regexp.MustCompile(`Frequency\w+\s+= "(\w+)"`).FindAllStringSubmatch(string(source), -1)
+		options := []string{}
+		for _, frequency := range frequencies {
+			options = append(options, frequency[1])
+		}
+		assert.Equal(t, options, segments.BuiltInFields["frequency"].Options)
+	})
+
+	t.Run("should limit text like the backend", func(t *testing.T) {
+		source, err := os.ReadFile(filepath.Join("..", "..", "..", "..", "backend", "src", "pkg", "utils", "validation", "validation.go"))
+		if os.IsNotExist(err) {
+			t.Skip("backend source is not checked out next to cmstool")
+		}
+		assert.Nil(t, err)
+
+		assert.Regexp(t, regexp.MustCompile(`MaxLength = `+regexp.QuoteMeta(fmt.Sprint(segments.MaxLength))+`\n`), string(source))
+	})
+}
@@ -0,0 +1,62 @@
+// Code generated by mockery v2.53.7. DO NOT EDIT.
+
+package mocks
+
+import (
+	error "subscribetool/src/pkg/utils/error"
+
+	mock "github.com/stretchr/testify/mock"
+
+	segments "subscribetool/src/pkg/segments"
+)
+
+// UseCase is an autogenerated mock type for the UseCase type
+type UseCase struct {
+	mock.Mock
+}
+
+// Condition provides a mock function with given fields: segmentID
+func (_m *UseCase) Condition(segmentID int64) (*segments.Condition, *error.ErrorCode) {
+	ret := _m.Called(segmentID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Condition")
+	}
+
+	var r0 *segments.Condition
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) (*segments.Condition, *error.ErrorCode)); ok {
+		return rf(segmentID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) *segments.Condition); ok {
+		r0 = rf(segmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*segments.Condition)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(segmentID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewUseCase(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *UseCase {
+	mock := &UseCase{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,66 @@
+package segments
+
+import "subscribetool/src/pkg/entity"
+
+const (
+	// MaxDepth, MaxConditions, MaxInValues and MaxDays match the limits the
+	// backend checks when a segment is saved.
+	MaxDepth      = 5
+	MaxConditions = 50
+	MaxInValues   = 100
+	MaxDays       = 36500
+
+	// MaxLength matches the backend's validation.MaxLength, the nvarchar(255)
+	// columns rule values are compared against.
+	MaxLength = 255
+
+	RulesField        = "rules"
+	CustomFieldPrefix = "fields."
+	DateLayout        = "2006-01-02"
+	OptionSeparator   = ","
+
+	// DeliveryStatusSent is the delivery status that records a subscriber
+	// received a campaign.
+	DeliveryStatusSent = "sent"
+)
+
+// Condition is a compiled rule tree: a WHERE clause over subscribers aliased
+// s, with its arguments numbered from 1.
+type Condition struct {
+	Where string
+	Args  []interface{}
+}
+
+type BuiltInField struct {
+	Column  string
+	Type    entity.FieldType
+	Options []string
+}
+
+// BuiltInFields maps the json name of a subscriber field a rule can compare
+// to its column.
+var BuiltInFields = map[string]BuiltInField{
+	"email":          {Column: "s.[Email]", Type: entity.FieldTypeString},
+	"name":           {Column: "s.[Name]", Type: entity.FieldTypeString},
+	"language":       {Column: "s.[Language]", Type: entity.FieldTypeString},
+	"frequency":      {Column: "s.[Frequency]", Type: entity.FieldTypeEnum, Options: []string{"immediate", "weekly", "monthly"}},
+	"timeZone":       {Column: "s.[TimeZone]", Type: entity.FieldTypeString},
+	"subscribedDate": {Column: "s.[SubscribedDate]", Type: entity.FieldTypeDate},
+	"confirmedDate":  {Column: "s.[ConfirmedDate]", Type: entity.FieldTypeDate},
+	"sourceApiKeyId": {Column: "s.[SourceApiKeyId]", Type: entity.FieldTypeNumber},
+}
+
+// TypeOps lists the comparisons each field type supports.
+var TypeOps = map[entity.FieldType][]entity.SegmentOp{
+	entity.FieldTypeString: {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIn, entity.SegmentOpContains, entity.SegmentOpStartsWith, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeEnum:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIn, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeNumber: {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpGt, entity.SegmentOpGte, entity.SegmentOpLt, entity.SegmentOpLte, entity.SegmentOpIn, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeDate:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpGt, entity.SegmentOpGte, entity.SegmentOpLt, entity.SegmentOpLte, entity.SegmentOpWithinDays, entity.SegmentOpOlderThanDays, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+	entity.FieldTypeBool:   {entity.SegmentOpEq, entity.SegmentOpNeq, entity.SegmentOpIsSet, entity.SegmentOpNotSet},
+}
+
+// EngagementStatuses maps an engagement event to the delivery status that
+// records it.
+var EngagementStatuses = map[entity.EngagementEvent]string{
+	entity.EngagementReceived: DeliveryStatusSent,
+}
@@ -0,0 +1,105 @@
+package segments
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"subscribetool/src/pkg/entity"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
+
+	sqlStruct "github.com/kisielk/sqlstruct"
+)
+
+type Repository interface {
+	FindByID(id int64) ([]entity.Segments, error)
+	GetAllFields() ([]entity.CustomFields, error)
+}
+
+type SqlRepository struct {
+	Collection       string
+	FieldsCollection string
+	Session          *sql.DB
+	Logs             logger.Logger
+}
+
+func NewRepository(collection string, fieldsCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+	return &SqlRepository{
+		Collection:       collection,
+		FieldsCollection: fieldsCollection,
+		Session:          session,
+		Logs:             logs,
+	}
+}
+
+func (repo *SqlRepository) FindByID(id int64) ([]entity.Segments, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Id = %[3]s
+	AND Delflag = 0
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.Segments{}, []string{}),
+		repo.Collection,
+		sqlQuery.Param(1),
+	)
+	rows, err := session.QueryContext(ctx, sql, id)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_FindByID", id, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.Segments{}
+	for rows.Next() {
+		var entity entity.Segments
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+func (repo *SqlRepository) GetAllFields() ([]entity.CustomFields, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s
+	WHERE Delflag = 0
+	`,
+		sqlQuery.GenerateQueryColumnNames(entity.CustomFields{}, []string{}),
+		repo.FieldsCollection,
+	)
+	rows, err := session.QueryContext(ctx, sql)
+	if err != nil {
+		go repo.Logs.Error("", "segments_Repo_GetAllFields", "", subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.CustomFields{}
+	for rows.Next() {
+		var entity entity.CustomFields
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
@@ -0,0 +1,69 @@
+package segments
+
+import (
+	"encoding/json"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/logger"
+)
+
+type ServiceParam struct {
+	Repo     Repository
+	Compiler *Compiler
+	Logs     logger.Logger
+}
+
+type UseCase interface {
+	Condition(segmentID int64) (*Condition, *subscribetoolError.ErrorCode)
+}
+
+type Service struct {
+	UseCase
+	Repo     Repository
+	Compiler *Compiler
+	Logs     logger.Logger
+}
+
+func NewService(serviceParam ServiceParam) *Service {
+	service := &Service{
+		Repo:     serviceParam.Repo,
+		Compiler: serviceParam.Compiler,
+		Logs:     serviceParam.Logs,
+	}
+	service.UseCase = service
+	return service
+}
+
+// Condition compiles the rules of a segment against the custom fields
+// defined now. A segment that no longer compiles is a bad request rather
+// than a send to the wrong people.
+func (service *Service) Condition(segmentID int64) (*Condition, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.FindByID(segmentID)
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(res) == 0 {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	segment := res[0]
+	errRules := json.Unmarshal([]byte(segment.RulesJSON), &segment.Rules)
+	if errRules != nil {
+		go service.Logs.Error("", "segments_Service_Condition", segmentID, subscribetoolError.NewError(subscribetoolError.InternalServerError, errRules.Error()))
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	definitions, errFields := service.Repo.GetAllFields()
+	if errFields != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	condition, errCompile := service.Compiler.Compile(segment.Rules, definitions)
+	if errCompile != nil {
+		go service.Logs.Error("", "segments_Service_Condition", segmentID, subscribetoolError.NewError(subscribetoolError.BadRequest, errCompile.Error()))
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest)
+	}
+
+	return condition, nil
+}
@@ -0,0 +1,119 @@
+package segments_test
+
+import (
+	"errors"
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
+	"subscribetool/src/pkg/segments/mocks"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
+	"subscribetool/src/pkg/utils/mocker"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	repository *mocks.Repository
+	service    *segments.Service
+	logs       *loggerMocks.Logger
+
+	mockRepoFindByID     *mocker.MockCall
+	mockRepoGetAllFields *mocker.MockCall
+)
+
+func callRepoFindByID() *mock.Call {
+	return repository.On("FindByID", mock.Anything)
+}
+
+func callRepoGetAllFields() *mock.Call {
+	return repository.On("GetAllFields")
+}
+
+func beforeEach() {
+	repository = &mocks.Repository{}
+	logs = &loggerMocks.Logger{}
+
+	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+
+	service = &segments.Service{
+		Repo:     repository,
+		Compiler: compiler,
+		Logs:     logs,
+	}
+	service.UseCase = service
+}
+
+func TestService_NewService(t *testing.T) {
+	t.Run("should return struct segments service when call new service", func(t *testing.T) {
+		beforeEach()
+		serviceParam := segments.ServiceParam{
+			Repo:     repository,
+			Compiler: compiler,
+			Logs:     logs,
+		}
+		resService := segments.NewService(serviceParam)
+
+		expectedService := &segments.Service{
+			Repo:     repository,
+			Compiler: compiler,
+			Logs:     logs,
+		}
+		expectedService.UseCase = expectedService
+
+		assert.Equal(t, expectedService, resService)
+	})
+}
+
+func TestService_Condition(t *testing.T) {
+	beforeEachCondition := func() {
+		beforeEach()
+
+		mockRepoFindByID = mocker.NewMockCall(callRepoFindByID)
+		mockRepoFindByID.Return([]entity.Segments{{ID: 5, RulesJSON: `{"field":"fields.country","op":"eq","value":"TH"}`}}, nil)
+		mockRepoGetAllFields = mocker.NewMockCall(callRepoGetAllFields)
+		mockRepoGetAllFields.Return(definitions, nil)
+	}
+
+	t.Run("should compile stored rules against current custom fields", func(t *testing.T) {
+		beforeEachCondition()
+
+		res, err := service.Condition(5)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []interface{}{int64(4), "TH"}, res.Args)
+		repository.AssertCalled(t, "FindByID", int64(5))
+	})
+
+	t.Run("should return data not found when segment is missing or deleted", func(t *testing.T) {
+		beforeEachCondition()
+		mockRepoFindByID.Return([]entity.Segments{}, nil)
+
+		res, err := service.Condition(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return bad request when custom field used by rules was deleted", func(t *testing.T) {
+		beforeEachCondition()
+		mockRepoGetAllFields.Return([]entity.CustomFields{}, nil)
+
+		res, err := service.Condition(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest), err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return internal server error when repository get all fields failed", func(t *testing.T) {
+		beforeEachCondition()
+		mockRepoGetAllFields.Return(nil, errors.New("Error"))
+
+		res, err := service.Condition(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
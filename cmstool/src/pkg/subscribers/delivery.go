@@ -0,0 +1,65 @@
+package subscribers
+
+import (
+	"log"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"time"
+)
+
+// LocalDelivery sends at the same time of day in every subscriber's zone,
+// releasing each zone's batch when its clocks get there.
+type LocalDelivery struct {
+	Service  UseCase
+	Fallback *time.Location
+	Now      func() time.Time
+}
+
+func NewLocalDelivery(service UseCase, fallback *time.Location) *LocalDelivery {
+	return &LocalDelivery{
+		Service:  service,
+		Fallback: fallback,
+		Now:      time.Now,
+	}
+}
+
+// Run sends content to the subscribers target picks at the next at in their
+// zone and returns once every batch is sent, with what became of each
+// subscriber sent to. When stop is closed first, or a batch fails, it also
+// returns the batches not sent yet, the failed one first. Content that does
+// not parse fails before waiting for the first batch.
+func (delivery *LocalDelivery) Run(target Target, content Content, at LocalTime, stop <-chan struct{}) ([]Batch, *Report, *subscribetoolError.ErrorCode) {
+	_, errTemplate := delivery.Service.ParseContent(content)
+	if errTemplate != nil {
+		return nil, nil, errTemplate
+	}
+
+	resSubscribers, errSubscribers := delivery.Service.GetRecipients(target)
+	if errSubscribers != nil {
+		return nil, nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(resSubscribers) == 0 {
+		return nil, nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	report := &Report{}
+	batches := PlanLocalTime(resSubscribers, at, delivery.Now(), delivery.Fallback)
+	for i, batch := range batches {
+		log.Println("Sending to", len(batch.Subscribers), "subscribers in", batch.TimeZone, "at", batch.SendAt.Format(time.RFC3339))
+
+		select {
+		case <-stop:
+			return batches[i:], report, nil
+		case <-time.After(batch.SendAt.Sub(delivery.Now())):
+		}
+
+		resReport, errSend := delivery.Service.SentEmailTo(batch.Subscribers, content)
+		if errSend != nil {
+			return batches[i:], report, errSend
+		}
+		report.Add(resReport)
+	}
+
+	return nil, report, nil
+}
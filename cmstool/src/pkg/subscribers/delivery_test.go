@@ -0,0 +1,174 @@
+package subscribers_test
+
+import (
+	"subscribetool/src/pkg/entity"
+	subscribers "subscribetool/src/pkg/subscribers"
+	"subscribetool/src/pkg/subscribers/mocks"
+	"subscribetool/src/pkg/utils/convert"
+	subscribetoolError "subscribetool/src/pkg/utils/error"
+	"subscribetool/src/pkg/utils/mocker"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+var (
+	delivery        *subscribers.LocalDelivery
+	deliveryService *mocks.UseCase
+
+	mockDeliveryGetRecipients *mocker.MockCall
+	mockDeliverySentEmailTo   *mocker.MockCall
+	mockDeliveryParseContent  *mocker.MockCall
+)
+
+func callDeliveryGetRecipients() *mock.Call {
+	return deliveryService.On("GetRecipients", mock.Anything)
+}
+
+func callDeliveryParseContent() *mock.Call {
+	return deliveryService.On("ParseContent", mock.Anything)
+}
+
+func callDeliverySentEmailTo() *mock.Call {
+	return deliveryService.On("SentEmailTo", mock.Anything, mock.Anything)
+}
+
+// sentToEvery reports every subscriber of a batch sent.
+func sentToEvery(list []entity.Subscribers, content subscribers.Content) *subscribers.Report {
+	return &subscribers.Report{Sent: list}
+}
+
+func TestLocalDelivery_NewLocalDelivery(t *testing.T) {
+	t.Run("should return local delivery with service and fallback zone", func(t *testing.T) {
+		deliveryService = &mocks.UseCase{}
+
+		res := subscribers.NewLocalDelivery(deliveryService, time.UTC)
+
+		assert.Equal(t, deliveryService, res.Service)
+		assert.Equal(t, time.UTC, res.Fallback)
+		assert.NotNil(t, res.Now)
+	})
+}
+
+func TestLocalDelivery_Run(t *testing.T) {
+	start := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	at := subscribers.LocalTime{Hour: 9}
+	content := subscribers.Content{Subject: "subject", Body: "body"}
+	london := entity.Subscribers{ID: 1, Email: "london@gmail.com", TimeZone: convert.ValueToStringPointer("Europe/London")}
+	bangkok := entity.Subscribers{ID: 2, Email: "bangkok@gmail.com", TimeZone: convert.ValueToStringPointer("Asia/Bangkok")}
+
+	beforeEachRun := func() {
+		deliveryService = &mocks.UseCase{}
+		delivery = subscribers.NewLocalDelivery(deliveryService, time.UTC)
+
+		// The plan is made at start and every wait after it finds the send
+		// time already passed, so batches go out without sleeping.
+		calls := 0
+		delivery.Now = func() time.Time {
+			calls++
+			if calls == 1 {
+				return start
+			}
+			return start.Add(48 * time.Hour)
+		}
+
+		mockDeliveryGetRecipients = mocker.NewMockCall(callDeliveryGetRecipients)
+		mockDeliveryGetRecipients.Return([]entity.Subscribers{london, bangkok}, nil)
+		mockDeliverySentEmailTo = mocker.NewMockCall(callDeliverySentEmailTo)
+		mockDeliverySentEmailTo.Return(sentToEvery, nil)
+		mockDeliveryParseContent = mocker.NewMockCall(callDeliveryParseContent)
+		mockDeliveryParseContent.Return(nil, nil)
+	}
+
+	t.Run("should send each zone in order of its local time", func(t *testing.T) {
+		beforeEachRun()
+		var sent []entity.Subscribers
+		mockDeliverySentEmailTo.Return(sentToEvery, nil).Run(func(args mock.Arguments) {
+			sent = append(sent, args.Get(0).([]entity.Subscribers)...)
+		})
+
+		left, report, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Nil(t, err)
+		assert.Empty(t, left)
+		assert.Equal(t, []entity.Subscribers{bangkok, london}, sent)
+		assert.Equal(t, &subscribers.Report{Sent: []entity.Subscribers{bangkok, london}}, report)
+		deliveryService.AssertCalled(t, "SentEmailTo", []entity.Subscribers{bangkok}, content)
+	})
+
+	t.Run("should return every batch without sending when stopped before the first", func(t *testing.T) {
+		beforeEachRun()
+		delivery.Now = func() time.Time { return start }
+		stop := make(chan struct{})
+		close(stop)
+
+		left, report, err := delivery.Run(subscribers.Target{}, content, at, stop)
+
+		assert.Nil(t, err)
+		assert.Len(t, left, 2)
+		assert.Empty(t, report.Sent)
+		deliveryService.AssertNotCalled(t, "SentEmailTo", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should return invalid template before getting recipients when content does not parse", func(t *testing.T) {
+		beforeEachRun()
+		mockDeliveryParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		left, _, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		assert.Nil(t, left)
+		deliveryService.AssertNotCalled(t, "GetRecipients", mock.Anything)
+		deliveryService.AssertNotCalled(t, "SentEmailTo", mock.Anything, mock.Anything)
+	})
+
+	t.Run("should report failed emails and keep sending the next batch", func(t *testing.T) {
+		beforeEachRun()
+		mockDeliverySentEmailTo.Return(func(list []entity.Subscribers, content subscribers.Content) *subscribers.Report {
+			return &subscribers.Report{Failed: []subscribers.Failure{{Subscriber: list[0], Error: "550 mailbox unavailable"}}}
+		}, nil)
+
+		left, report, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Nil(t, err)
+		assert.Empty(t, left)
+		assert.Equal(t, []subscribers.Failure{
+			{Subscriber: bangkok, Error: "550 mailbox unavailable"},
+			{Subscriber: london, Error: "550 mailbox unavailable"},
+		}, report.Failed)
+		deliveryService.AssertNumberOfCalls(t, "SentEmailTo", 2)
+	})
+
+	t.Run("should return batches not sent when a batch fails", func(t *testing.T) {
+		beforeEachRun()
+		mockDeliverySentEmailTo.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError))
+
+		left, _, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Len(t, left, 2)
+		deliveryService.AssertNumberOfCalls(t, "SentEmailTo", 1)
+	})
+
+	t.Run("should return internal server error when get recipients failed", func(t *testing.T) {
+		beforeEachRun()
+		mockDeliveryGetRecipients.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound))
+
+		left, _, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Nil(t, left)
+	})
+
+	t.Run("should return data not found when there is no recipient", func(t *testing.T) {
+		beforeEachRun()
+		mockDeliveryGetRecipients.Return([]entity.Subscribers{}, nil)
+
+		left, _, err := delivery.Run(subscribers.Target{}, content, at, make(chan struct{}))
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound), err)
+		assert.Nil(t, left)
+	})
+}
@@ -0,0 +1,98 @@
+package subscribers
+
+import (
+	"sort"
+	"subscribetool/src/pkg/entity"
+	"time"
+)
+
+// LocalTimeLayout is how a time of day is written on the command line.
+const LocalTimeLayout = "15:04"
+
+// LocalTime is a time of day, like 09:00, read in each subscriber's own zone.
+type LocalTime struct {
+	Hour   int
+	Minute int
+}
+
+func ParseLocalTime(value string) (LocalTime, error) {
+	parsed, err := time.Parse(LocalTimeLayout, value)
+	if err != nil {
+		return LocalTime{}, err
+	}
+	return LocalTime{Hour: parsed.Hour(), Minute: parsed.Minute()}, nil
+}
+
+// Next returns the first moment at or after now when the clocks in location
+// show at. A time that does not exist on a daylight saving day is moved on
+// the way time.Date moves it.
+func (at LocalTime) Next(now time.Time, location *time.Location) time.Time {
+	local := now.In(location)
+	next := time.Date(local.Year(), local.Month(), local.Day(), at.Hour, at.Minute, 0, 0, location)
+	if next.Before(now) {
+		next = time.Date(local.Year(), local.Month(), local.Day()+1, at.Hour, at.Minute, 0, 0, location)
+	}
+	return next
+}
+
+// Batch is the subscribers in one time zone and when they are sent to.
+type Batch struct {
+	TimeZone    string
+	SendAt      time.Time
+	Subscribers []entity.Subscribers
+}
+
+// PlanLocalTime groups list by time zone and works out when at next comes
+// round in each. Subscribers without a zone, or with one that does not load,
+// go with fallback. Batches are ordered by SendAt, then zone name.
+func PlanLocalTime(list []entity.Subscribers, at LocalTime, now time.Time, fallback *time.Location) []Batch {
+	// loaded remembers each zone name seen, nil when it did not load, so the
+	// zone database is read once per zone rather than once per subscriber.
+	loaded := map[string]*time.Location{}
+	locations := map[string]*time.Location{}
+	groups := map[string][]entity.Subscribers{}
+	for _, subscriber := range list {
+		location := fallback
+		if subscriber.TimeZone != nil {
+			zone, ok := loaded[*subscriber.TimeZone]
+			if !ok {
+				zone = loadTimeZone(*subscriber.TimeZone)
+				loaded[*subscriber.TimeZone] = zone
+			}
+			if zone != nil {
+				location = zone
+			}
+		}
+		groups[location.String()] = append(groups[location.String()], subscriber)
+		locations[location.String()] = location
+	}
+
+	batches := []Batch{}
+	for name, subscribers := range groups {
+		batches = append(batches, Batch{
+			TimeZone:    name,
+			SendAt:      at.Next(now, locations[name]),
+			Subscribers: subscribers,
+		})
+	}
+	sort.Slice(batches, func(i, j int) bool {
+		if !batches[i].SendAt.Equal(batches[j].SendAt) {
+			return batches[i].SendAt.Before(batches[j].SendAt)
+		}
+		return batches[i].TimeZone < batches[j].TimeZone
+	})
+	return batches
+}
+
+// loadTimeZone returns the zone named name, or nil when there is none.
+// "Local" is the host's zone, never a subscriber's, so it is refused.
+func loadTimeZone(name string) *time.Location {
+	if name == "" || name == "Local" {
+		return nil
+	}
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return location
+}
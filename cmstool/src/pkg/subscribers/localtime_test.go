@@ -0,0 +1,107 @@
+package subscribers_test
+
+import (
+	"subscribetool/src/pkg/entity"
+	subscribers "subscribetool/src/pkg/subscribers"
+	"subscribetool/src/pkg/utils/convert"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLocalTime_ParseLocalTime(t *testing.T) {
+	t.Run("should return hour and minute when value is hh:mm", func(t *testing.T) {
+		at, err := subscribers.ParseLocalTime("09:30")
+
+		assert.Nil(t, err)
+		assert.Equal(t, subscribers.LocalTime{Hour: 9, Minute: 30}, at)
+	})
+
+	t.Run("should return error when value is not a time of day", func(t *testing.T) {
+		_, err := subscribers.ParseLocalTime("25:00")
+
+		assert.NotNil(t, err)
+	})
+}
+
+func TestLocalTime_Next(t *testing.T) {
+	bangkok, _ := time.LoadLocation("Asia/Bangkok")
+	at := subscribers.LocalTime{Hour: 9}
+
+	t.Run("should return today when local time has not come yet", func(t *testing.T) {
+		now := time.Date(2023, 1, 2, 0, 30, 0, 0, time.UTC)
+
+		next := at.Next(now, bangkok)
+
+		assert.Equal(t, time.Date(2023, 1, 2, 2, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("should return tomorrow when local time has passed", func(t *testing.T) {
+		now := time.Date(2023, 1, 2, 3, 0, 0, 0, time.UTC)
+
+		next := at.Next(now, bangkok)
+
+		assert.Equal(t, time.Date(2023, 1, 3, 2, 0, 0, 0, time.UTC), next.UTC())
+	})
+
+	t.Run("should return now when local time is now", func(t *testing.T) {
+		now := time.Date(2023, 1, 2, 2, 0, 0, 0, time.UTC)
+
+		next := at.Next(now, bangkok)
+
+		assert.True(t, now.Equal(next))
+	})
+
+	t.Run("should follow daylight saving time of zone", func(t *testing.T) {
+		london, _ := time.LoadLocation("Europe/London")
+		now := time.Date(2023, 7, 1, 0, 0, 0, 0, time.UTC)
+
+		next := at.Next(now, london)
+
+		assert.Equal(t, time.Date(2023, 7, 1, 8, 0, 0, 0, time.UTC), next.UTC())
+	})
+}
+
+func TestLocalTime_PlanLocalTime(t *testing.T) {
+	now := time.Date(2023, 1, 2, 0, 0, 0, 0, time.UTC)
+	at := subscribers.LocalTime{Hour: 9}
+
+	t.Run("should group subscribers by zone in order of send time", func(t *testing.T) {
+		list := []entity.Subscribers{
+			{ID: 1, TimeZone: convert.ValueToStringPointer("Europe/London")},
+			{ID: 2, TimeZone: convert.ValueToStringPointer("Asia/Bangkok")},
+			{ID: 3, TimeZone: convert.ValueToStringPointer("Europe/London")},
+		}
+
+		batches := subscribers.PlanLocalTime(list, at, now, time.UTC)
+
+		assert.Len(t, batches, 2)
+		assert.Equal(t, "Asia/Bangkok", batches[0].TimeZone)
+		assert.Equal(t, time.Date(2023, 1, 2, 2, 0, 0, 0, time.UTC), batches[0].SendAt.UTC())
+		assert.Equal(t, []entity.Subscribers{list[1]}, batches[0].Subscribers)
+		assert.Equal(t, "Europe/London", batches[1].TimeZone)
+		assert.Equal(t, time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC), batches[1].SendAt.UTC())
+		assert.Equal(t, []entity.Subscribers{list[0], list[2]}, batches[1].Subscribers)
+	})
+
+	t.Run("should put subscribers without a usable zone with fallback", func(t *testing.T) {
+		list := []entity.Subscribers{
+			{ID: 1},
+			{ID: 2, TimeZone: convert.ValueToStringPointer("Asia/Nowhere")},
+			{ID: 3, TimeZone: convert.ValueToStringPointer("Local")},
+		}
+
+		batches := subscribers.PlanLocalTime(list, at, now, time.UTC)
+
+		assert.Len(t, batches, 1)
+		assert.Equal(t, "UTC", batches[0].TimeZone)
+		assert.Equal(t, list, batches[0].Subscribers)
+	})
+
+	t.Run("should return no batch when list is empty", func(t *testing.T) {
+		batches := subscribers.PlanLocalTime([]entity.Subscribers{}, at, now, time.UTC)
+
+		assert.Empty(t, batches)
+	})
+}
@@ -1,4 +1,4 @@
-// Code generated by mockery v2.32.4. DO NOT EDIT.
+// Code generated by mockery v2.53.7. DO NOT EDIT.
 
 package mocks
 
@@ -6,6 +6,8 @@ import (
 	entity "subscribetool/src/pkg/entity"
 
 	mock "github.com/stretchr/testify/mock"
+
+	segments "subscribetool/src/pkg/segments"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -13,10 +15,14 @@ type Repository struct {
 	mock.Mock
 }
 
-// GetAllSubscribers provides a mock function with given fields:
+// GetAllSubscribers provides a mock function with no fields
 func (_m *Repository) GetAllSubscribers() ([]entity.Subscribers, error) {
 	ret := _m.Called()
 
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllSubscribers")
+	}
+
 	var r0 []entity.Subscribers
 	var r1 error
 	if rf, ok := ret.Get(0).(func() ([]entity.Subscribers, error)); ok {
@@ -39,6 +45,96 @@ func (_m *Repository) GetAllSubscribers() ([]entity.Subscribers, error) {
 	return r0, r1
 }
 
+// GetFieldValues provides a mock function with given fields: subscriberIDs
+func (_m *Repository) GetFieldValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error) {
+	ret := _m.Called(subscriberIDs)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetFieldValues")
+	}
+
+	var r0 []entity.SubscriberFieldValues
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]int64) ([]entity.SubscriberFieldValues, error)); ok {
+		return rf(subscriberIDs)
+	}
+	if rf, ok := ret.Get(0).(func([]int64) []entity.SubscriberFieldValues); ok {
+		r0 = rf(subscriberIDs)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.SubscriberFieldValues)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]int64) error); ok {
+		r1 = rf(subscriberIDs)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscribersByCondition provides a mock function with given fields: condition
+func (_m *Repository) GetSubscribersByCondition(condition segments.Condition) ([]entity.Subscribers, error) {
+	ret := _m.Called(condition)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscribersByCondition")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(segments.Condition) ([]entity.Subscribers, error)); ok {
+		return rf(condition)
+	}
+	if rf, ok := ret.Get(0).(func(segments.Condition) []entity.Subscribers); ok {
+		r0 = rf(condition)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(segments.Condition) error); ok {
+		r1 = rf(condition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetSubscribersByListID provides a mock function with given fields: listID
+func (_m *Repository) GetSubscribersByListID(listID int64) ([]entity.Subscribers, error) {
+	ret := _m.Called(listID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSubscribersByListID")
+	}
+
+	var r0 []entity.Subscribers
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, error)); ok {
+		return rf(listID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(listID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(listID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
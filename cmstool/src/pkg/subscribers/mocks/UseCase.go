@@ -4,9 +4,13 @@ package mocks
 
 import (
 	entity "subscribetool/src/pkg/entity"
+	email "subscribetool/src/pkg/utils/email"
+
 	error "subscribetool/src/pkg/utils/error"
 
 	mock "github.com/stretchr/testify/mock"
+
+	subscribers "subscribetool/src/pkg/subscribers"
 )
 
 // UseCase is an autogenerated mock type for the UseCase type
@@ -14,6 +18,34 @@ type UseCase struct {
 	mock.Mock
 }
 
+// GeneratePreferencesURL provides a mock function with given fields: _a0
+func (_m *UseCase) GeneratePreferencesURL(_a0 string) string {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
+// GenerateUnsubscribeURL provides a mock function with given fields: _a0
+func (_m *UseCase) GenerateUnsubscribeURL(_a0 string) string {
+	ret := _m.Called(_a0)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(_a0)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	return r0
+}
+
 // GetAllSubscribers provides a mock function with given fields:
 func (_m *UseCase) GetAllSubscribers() ([]entity.Subscribers, *error.ErrorCode) {
 	ret := _m.Called()
@@ -42,20 +74,228 @@ func (_m *UseCase) GetAllSubscribers() ([]entity.Subscribers, *error.ErrorCode)
 	return r0, r1
 }
 
-// SentEmail provides a mock function with given fields:
-func (_m *UseCase) SentEmail() *error.ErrorCode {
-	ret := _m.Called()
+// GetFields provides a mock function with given fields: list
+func (_m *UseCase) GetFields(list []entity.Subscribers) (map[int64]email.Fields, *error.ErrorCode) {
+	ret := _m.Called(list)
 
-	var r0 *error.ErrorCode
-	if rf, ok := ret.Get(0).(func() *error.ErrorCode); ok {
-		r0 = rf()
+	var r0 map[int64]email.Fields
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers) (map[int64]email.Fields, *error.ErrorCode)); ok {
+		return rf(list)
+	}
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers) map[int64]email.Fields); ok {
+		r0 = rf(list)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*error.ErrorCode)
+			r0 = ret.Get(0).(map[int64]email.Fields)
 		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func([]entity.Subscribers) *error.ErrorCode); ok {
+		r1 = rf(list)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetRecipients provides a mock function with given fields: target
+func (_m *UseCase) GetRecipients(target subscribers.Target) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(target)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Target) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(target)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Target) []entity.Subscribers); ok {
+		r0 = rf(target)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Target) *error.ErrorCode); ok {
+		r1 = rf(target)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSubscribersByListID provides a mock function with given fields: listID
+func (_m *UseCase) GetSubscribersByListID(listID int64) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(listID)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(listID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(listID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(listID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// GetSubscribersBySegmentID provides a mock function with given fields: segmentID
+func (_m *UseCase) GetSubscribersBySegmentID(segmentID int64) ([]entity.Subscribers, *error.ErrorCode) {
+	ret := _m.Called(segmentID)
+
+	var r0 []entity.Subscribers
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(int64) ([]entity.Subscribers, *error.ErrorCode)); ok {
+		return rf(segmentID)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []entity.Subscribers); ok {
+		r0 = rf(segmentID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]entity.Subscribers)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) *error.ErrorCode); ok {
+		r1 = rf(segmentID)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// ParseContent provides a mock function with given fields: content
+func (_m *UseCase) ParseContent(content subscribers.Content) (*email.Template, *error.ErrorCode) {
+	ret := _m.Called(content)
+
+	var r0 *email.Template
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Content) (*email.Template, *error.ErrorCode)); ok {
+		return rf(content)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Content) *email.Template); ok {
+		r0 = rf(content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*email.Template)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Content) *error.ErrorCode); ok {
+		r1 = rf(content)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// SentEmail provides a mock function with given fields: target, content
+func (_m *UseCase) SentEmail(target subscribers.Target, content subscribers.Content) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(target, content)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func(subscribers.Target, subscribers.Content) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(target, content)
+	}
+	if rf, ok := ret.Get(0).(func(subscribers.Target, subscribers.Content) *subscribers.Report); ok {
+		r0 = rf(target, content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(subscribers.Target, subscribers.Content) *error.ErrorCode); ok {
+		r1 = rf(target, content)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// SentEmailTo provides a mock function with given fields: list, content
+func (_m *UseCase) SentEmailTo(list []entity.Subscribers, content subscribers.Content) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(list, content)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers, subscribers.Content) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(list, content)
+	}
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers, subscribers.Content) *subscribers.Report); ok {
+		r0 = rf(list, content)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]entity.Subscribers, subscribers.Content) *error.ErrorCode); ok {
+		r1 = rf(list, content)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
+}
+
+// SentEmailToRecorded provides a mock function with given fields: list, content, record
+func (_m *UseCase) SentEmailToRecorded(list []entity.Subscribers, content subscribers.Content, record subscribers.Recorder) (*subscribers.Report, *error.ErrorCode) {
+	ret := _m.Called(list, content, record)
+
+	var r0 *subscribers.Report
+	var r1 *error.ErrorCode
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers, subscribers.Content, subscribers.Recorder) (*subscribers.Report, *error.ErrorCode)); ok {
+		return rf(list, content, record)
+	}
+	if rf, ok := ret.Get(0).(func([]entity.Subscribers, subscribers.Content, subscribers.Recorder) *subscribers.Report); ok {
+		r0 = rf(list, content, record)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*subscribers.Report)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]entity.Subscribers, subscribers.Content, subscribers.Recorder) *error.ErrorCode); ok {
+		r1 = rf(list, content, record)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*error.ErrorCode)
+		}
+	}
+
+	return r0, r1
 }
 
 // NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
@@ -1,6 +1,8 @@
 package subscribers
 
 import (
+	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
 	"subscribetool/src/pkg/utils/email"
 	"subscribetool/src/pkg/utils/logger"
 )
@@ -8,5 +10,78 @@ import (
 type ServiceParam struct {
 	UtilsEmailService email.UseCase
 	Repo              Repository
+	Segments          segments.UseCase
+	Templates         *email.Renderer
+	Config            ServiceConfig
 	Logs              logger.Logger
 }
+
+// ServiceConfig signs the unsubscribe and preferences links put in every
+// email. TokenSecret must be the backend's TOKEN_SECRET, UnsubscribeURL its
+// /subscribers/unsubscribe address and PreferencesURL its /preferences
+// address, for the backend to accept the links.
+type ServiceConfig struct {
+	TokenSecret    string
+	UnsubscribeURL string
+	PreferencesURL string
+}
+
+// Target picks who a send goes to: the subscribers on ListID or those
+// matching SegmentID. Leave both nil to send to every subscriber.
+type Target struct {
+	ListID    *int64
+	SegmentID *int64
+}
+
+// Content is what a send puts in every email. Subject, Body and Text are
+// templates rendered for each subscriber with email.TemplateData. Text is the
+// plain-text part, derived from Body when empty, and Sender overrides the
+// configured sender.
+type Content struct {
+	Sender      string
+	Subject     string
+	Body        string
+	Text        string
+	Attachments []email.Attachment
+}
+
+// Report is what became of each subscriber a send went to. A failed email
+// does not stop the others, so one send can have both.
+type Report struct {
+	Sent   []entity.Subscribers
+	Failed []Failure
+}
+
+// Recorder is told what became of each subscriber as soon as it is known,
+// before the rest of the batch is sent, so a send that stops part way still
+// leaves a record of who got the email. A subscriber whose email did not
+// render gets a result with only Error set.
+type Recorder func(subscriber entity.Subscribers, result email.SendResult)
+
+// Failure is a subscriber whose email was not sent, and why. Permanent is set
+// when the mail server rejected the email for good, so sending it again will
+// not help.
+type Failure struct {
+	Subscriber entity.Subscribers
+	Error      string
+	Permanent  bool
+}
+
+// Retryable reports whether any failure in report may succeed when sent again.
+func (report *Report) Retryable() bool {
+	for _, failure := range report.Failed {
+		if !failure.Permanent {
+			return true
+		}
+	}
+	return false
+}
+
+// Add appends what other reports to report.
+func (report *Report) Add(other *Report) {
+	if other == nil {
+		return
+	}
+	report.Sent = append(report.Sent, other.Sent...)
+	report.Failed = append(report.Failed, other.Failed...)
+}
@@ -5,7 +5,9 @@ import (
 	"database/sql"
 	"fmt"
 	"log"
+	"strings"
 	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
 	subscribetoolError "subscribetool/src/pkg/utils/error"
 	"subscribetool/src/pkg/utils/logger"
 	sqlQuery "subscribetool/src/pkg/utils/sqlquery"
@@ -15,19 +17,28 @@ import (
 
 type Repository interface {
 	GetAllSubscribers() ([]entity.Subscribers, error)
+	GetSubscribersByListID(listID int64) ([]entity.Subscribers, error)
+	GetSubscribersByCondition(condition segments.Condition) ([]entity.Subscribers, error)
+	GetFieldValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error)
 }
 
 type SqlRepository struct {
-	Collection string
-	Session    *sql.DB
-	Logs       logger.Logger
+	Collection            string
+	ListMembersCollection string
+	FieldsCollection      string
+	FieldValuesCollection string
+	Session               *sql.DB
+	Logs                  logger.Logger
 }
 
-func NewRepository(collection string, session *sql.DB, logs logger.Logger) *SqlRepository {
+func NewRepository(collection string, listMembersCollection string, fieldsCollection string, fieldValuesCollection string, session *sql.DB, logs logger.Logger) *SqlRepository {
 	return &SqlRepository{
-		Collection: collection,
-		Session:    session,
-		Logs:       logs,
+		Collection:            collection,
+		ListMembersCollection: listMembersCollection,
+		FieldsCollection:      fieldsCollection,
+		FieldValuesCollection: fieldValuesCollection,
+		Session:               session,
+		Logs:                  logs,
 	}
 }
 
@@ -64,3 +75,130 @@ func (repo *SqlRepository) GetAllSubscribers() ([]entity.Subscribers, error) {
 	}
 	return list, nil
 }
+
+func (repo *SqlRepository) GetSubscribersByListID(listID int64) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s s
+	INNER JOIN %[3]s m ON m.SubscriberId = s.Id
+	WHERE m.ListId = @p1
+	AND m.IsSubscribed = 1
+	AND s.Delflag = 0
+	AND s.IsSubscribed = 1
+	`,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Subscribers{}, []string{}, "s"),
+		repo.Collection,
+		repo.ListMembersCollection,
+	)
+	rows, err := session.QueryContext(ctx, sql, listID)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_GetSubscribersByListID", listID, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// GetSubscribersByCondition returns the confirmed subscribers matching a
+// compiled segment condition.
+func (repo *SqlRepository) GetSubscribersByCondition(condition segments.Condition) ([]entity.Subscribers, error) {
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	sql := fmt.Sprintf(`
+	SELECT %[1]s
+	FROM %[2]s s
+	WHERE s.Delflag = 0
+	AND s.IsSubscribed = 1
+	AND %[3]s
+	`,
+		sqlQuery.GenerateQueryColumnNamesWithAlias(entity.Subscribers{}, []string{}, "s"),
+		repo.Collection,
+		condition.Where,
+	)
+	rows, err := session.QueryContext(ctx, sql, condition.Args...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_GetSubscribersByCondition", condition.Args, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+
+	list := []entity.Subscribers{}
+	for rows.Next() {
+		var entity entity.Subscribers
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
+
+// GetFieldValues returns the custom field values of the subscribers, read the
+// way the backend's customfields repository reads them.
+func (repo *SqlRepository) GetFieldValues(subscriberIDs []int64) ([]entity.SubscriberFieldValues, error) {
+	if len(subscriberIDs) == 0 {
+		return []entity.SubscriberFieldValues{}, nil
+	}
+
+	ctx := context.Background()
+	session, sessionErr := repo.Session.Conn(ctx)
+	if sessionErr != nil {
+		return nil, sessionErr
+	}
+	defer session.Close()
+
+	args := []interface{}{}
+	params := []string{}
+	for _, subscriberID := range subscriberIDs {
+		args = append(args, subscriberID)
+		params = append(params, sqlQuery.Param(len(args)))
+	}
+
+	sql := fmt.Sprintf(`
+	SELECT v.[SubscriberId],v.[FieldId],f.[Key],f.[Type],v.[StringValue],v.[NumberValue],v.[DateValue],v.[BoolValue]
+	FROM %[1]s v
+	INNER JOIN %[2]s f ON f.Id = v.FieldId
+	WHERE f.Delflag = 0
+	AND v.SubscriberId IN (%[3]s)
+	ORDER BY v.SubscriberId ASC, f.Id ASC
+	`,
+		repo.FieldValuesCollection,
+		repo.FieldsCollection,
+		strings.Join(params, ","),
+	)
+	rows, err := session.QueryContext(ctx, sql, args...)
+	if err != nil {
+		go repo.Logs.Error("", "subscribers_Repo_GetFieldValues", subscriberIDs, subscribetoolError.NewError(subscribetoolError.TechnicalError, err.Error()))
+		return nil, err
+	}
+	defer rows.Close()
+
+	list := []entity.SubscriberFieldValues{}
+	for rows.Next() {
+		var entity entity.SubscriberFieldValues
+		if err := sqlStruct.Scan(&entity, rows); err != nil {
+			log.Println(err.Error())
+		}
+		list = append(list, entity)
+	}
+	return list, nil
+}
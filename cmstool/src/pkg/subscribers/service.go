@@ -2,22 +2,51 @@ package subscribers
 
 import (
 	"fmt"
+	"html"
+	"strings"
 	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
 	"subscribetool/src/pkg/utils/convert"
 	"subscribetool/src/pkg/utils/email"
 	subscribetoolError "subscribetool/src/pkg/utils/error"
 	"subscribetool/src/pkg/utils/logger"
+	"subscribetool/src/pkg/utils/token"
+)
+
+const (
+	// UnsubscribeTokenPurpose and PreferencesTokenPurpose match the backend,
+	// which verifies the links.
+	UnsubscribeTokenPurpose = "unsubscribe"
+	PreferencesTokenPurpose = "preferences"
+
+	// FieldValuesBatchSize is how many subscribers GetFields reads per query,
+	// well under the parameter limit of a single statement.
+	FieldValuesBatchSize = 1000
+
+	unsubscribeFooter = `<p style="font-size:12px;color:#666666">Don't want these emails? <a href="%[1]s">Unsubscribe</a></p>`
 )
 
 type UseCase interface {
 	GetAllSubscribers() ([]entity.Subscribers, *subscribetoolError.ErrorCode)
-	SentEmail() *subscribetoolError.ErrorCode
+	GetSubscribersByListID(listID int64) ([]entity.Subscribers, *subscribetoolError.ErrorCode)
+	GetSubscribersBySegmentID(segmentID int64) ([]entity.Subscribers, *subscribetoolError.ErrorCode)
+	GetRecipients(target Target) ([]entity.Subscribers, *subscribetoolError.ErrorCode)
+	SentEmail(target Target, content Content) (*Report, *subscribetoolError.ErrorCode)
+	SentEmailTo(list []entity.Subscribers, content Content) (*Report, *subscribetoolError.ErrorCode)
+	SentEmailToRecorded(list []entity.Subscribers, content Content, record Recorder) (*Report, *subscribetoolError.ErrorCode)
+	ParseContent(content Content) (*email.Template, *subscribetoolError.ErrorCode)
+	GetFields(list []entity.Subscribers) (map[int64]email.Fields, *subscribetoolError.ErrorCode)
+	GenerateUnsubscribeURL(email string) string
+	GeneratePreferencesURL(email string) string
 }
 
 type Service struct {
 	UseCase
 	UtilsEmailService email.UseCase
 	Repo              Repository
+	Segments          segments.UseCase
+	Templates         *email.Renderer
+	Config            ServiceConfig
 	Logs              logger.Logger
 }
 
@@ -25,6 +54,9 @@ func NewService(serviceParam ServiceParam) *Service {
 	service := &Service{
 		UtilsEmailService: serviceParam.UtilsEmailService,
 		Repo:              serviceParam.Repo,
+		Segments:          serviceParam.Segments,
+		Templates:         serviceParam.Templates,
+		Config:            serviceParam.Config,
 		Logs:              serviceParam.Logs,
 	}
 	service.UseCase = service
@@ -45,35 +77,251 @@ func (service *Service) GetAllSubscribers() ([]entity.Subscribers, *subscribetoo
 	return res, nil
 }
 
-func (service *Service) SentEmail() *subscribetoolError.ErrorCode {
+func (service *Service) GetSubscribersByListID(listID int64) ([]entity.Subscribers, *subscribetoolError.ErrorCode) {
+	res, err := service.Repo.GetSubscribersByListID(listID)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if res == nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	return res, nil
+}
 
-	resGetAllSubscribers, errGetAllSubscribers := service.UseCase.GetAllSubscribers()
-	if errGetAllSubscribers != nil {
-		return convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+func (service *Service) GetSubscribersBySegmentID(segmentID int64) ([]entity.Subscribers, *subscribetoolError.ErrorCode) {
+	condition, errCondition := service.Segments.Condition(segmentID)
+	if errCondition != nil {
+		return nil, errCondition
 	}
 
-	if len(resGetAllSubscribers) == 0 {
-		return convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	res, err := service.Repo.GetSubscribersByCondition(*condition)
+
+	if err != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
 	}
 
-	for _, value := range resGetAllSubscribers {
+	if res == nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
 
-		emailTarget := []string{value.Email}
-		fmt.Println("emailTarget : ", emailTarget)
-		mailInfo := email.SentMailContent{
-			To:      emailTarget,
-			Supject: "Test sent mail for subscribers",
-			Body:    "This email sent for notification. Test sent mail for subscribers",
+	return res, nil
+}
+
+func (service *Service) SentEmail(target Target, content Content) (*Report, *subscribetoolError.ErrorCode) {
+	_, errTemplate := service.UseCase.ParseContent(content)
+	if errTemplate != nil {
+		return nil, errTemplate
+	}
+
+	resSubscribers, errSubscribers := service.GetRecipients(target)
+	if errSubscribers != nil {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+	}
+
+	if len(resSubscribers) == 0 {
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
+	}
+
+	return service.SentEmailTo(resSubscribers, content)
+}
+
+// SentEmailTo renders content for each subscriber in list and sends the
+// emails as one batch. An email that fails is reported and the rest are
+// still sent; only a template that does not parse fails the whole send,
+// before anything is sent.
+func (service *Service) SentEmailTo(list []entity.Subscribers, content Content) (*Report, *subscribetoolError.ErrorCode) {
+	return service.SentEmailToRecorded(list, content, nil)
+}
+
+// SentEmailToRecorded is SentEmailTo telling record, when it is not nil,
+// about each subscriber as the send goes. Nothing is sent when the custom
+// field values of list cannot be read.
+func (service *Service) SentEmailToRecorded(list []entity.Subscribers, content Content, record Recorder) (*Report, *subscribetoolError.ErrorCode) {
+	template, errTemplate := service.UseCase.ParseContent(content)
+	if errTemplate != nil {
+		return nil, errTemplate
+	}
+
+	fields, errFields := service.UseCase.GetFields(list)
+	if errFields != nil {
+		return nil, errFields
+	}
+
+	report := &Report{}
+	recipients := map[string]entity.Subscribers{}
+	mailInfos := []email.SentMailContent{}
+	for _, value := range list {
+		unsubscribeURL := service.UseCase.GenerateUnsubscribeURL(value.Email)
+		rendered, errRender := template.Render(email.TemplateData{
+			Name:           value.Name,
+			Email:          value.Email,
+			UnsubscribeURL: unsubscribeURL,
+			PreferencesURL: service.UseCase.GeneratePreferencesURL(value.Email),
+			Fields:         fields[value.ID],
+		})
+		if errRender != nil {
+			go service.Logs.Error("", "subscribers_Service_SentEmailTo_Render", value.Email, subscribetoolError.NewError(subscribetoolError.InvalidTemplate, errRender.Error()))
+			report.Failed = append(report.Failed, Failure{Subscriber: value, Error: errRender.Error()})
+			if record != nil {
+				record(value, email.SendResult{Error: &email.SendError{Err: errRender}})
+			}
+			continue
 		}
 
-		errUtilsEmail := service.UtilsEmailService.Send(mailInfo)
-		if errUtilsEmail != nil {
-			return convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+		recipients[value.Email] = value
+		mailInfos = append(mailInfos, email.SentMailContent{
+			Sender:         content.Sender,
+			To:             []string{value.Email},
+			Supject:        rendered.Subject,
+			Body:           withUnsubscribeLink(rendered.HTMLBody, unsubscribeURL),
+			Text:           withUnsubscribeText(rendered.TextBody, unsubscribeURL),
+			UnsubscribeURL: unsubscribeURL,
+			Attachments:    content.Attachments,
+		})
+	}
+
+	for result := range service.UtilsEmailService.SendBatch(mailInfos) {
+		subscriber := recipients[result.Content.To[0]]
+		if record != nil {
+			record(subscriber, result)
+		}
+		if result.Error != nil {
+			go service.Logs.Error("", "subscribers_Service_SentEmailTo_Send", subscriber.Email, subscribetoolError.NewError(subscribetoolError.EmailNotSent, result.Error.Error()))
+			report.Failed = append(report.Failed, Failure{Subscriber: subscriber, Error: result.Error.Error(), Permanent: result.Error.Permanent})
+			continue
+		}
+		report.Sent = append(report.Sent, subscriber)
+	}
+
+	fmt.Println("sent mail to", len(report.Sent), "subscribers,", len(report.Failed), "failed")
+
+	return report, nil
+}
+
+// ParseContent compiles the subject and bodies of content against the shared
+// layouts and partials, failing on a template that references an unknown
+// field or partial.
+func (service *Service) ParseContent(content Content) (*email.Template, *subscribetoolError.ErrorCode) {
+	template, err := service.Templates.Parse(content.Subject, content.Body, content.Text)
+	if err != nil {
+		go service.Logs.Error("", "subscribers_Service_ParseContent", content.Subject, subscribetoolError.NewError(subscribetoolError.InvalidTemplate, err.Error()))
+		return nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate)
+	}
+
+	return template, nil
+}
+
+// withUnsubscribeText adds unsubscribeURL to a plain-text part given
+// separately. An empty one is derived from the body, which has the link.
+func withUnsubscribeText(text string, unsubscribeURL string) string {
+	if text == "" || strings.Contains(text, unsubscribeURL) {
+		return text
+	}
+	return text + "\n\nUnsubscribe: " + unsubscribeURL
+}
+
+// GenerateUnsubscribeURL returns the signed one-click unsubscribe link of
+// email, in the same form the backend issues.
+func (service *Service) GenerateUnsubscribeURL(email string) string {
+	unsubscribeToken := token.Generate(service.Config.TokenSecret, UnsubscribeTokenPurpose, email, nil)
+	return fmt.Sprintf("%s/%s", service.Config.UnsubscribeURL, unsubscribeToken)
+}
+
+// GeneratePreferencesURL returns the signed link to the preferences page of
+// email, in the same form the backend issues, or "" when no PreferencesURL is
+// configured.
+func (service *Service) GeneratePreferencesURL(email string) string {
+	if service.Config.PreferencesURL == "" {
+		return ""
+	}
+	preferencesToken := token.Generate(service.Config.TokenSecret, PreferencesTokenPurpose, email, nil)
+	return fmt.Sprintf("%s/%s", service.Config.PreferencesURL, preferencesToken)
+}
+
+// GetFields returns the custom field values of each subscriber in list by
+// field key, as templates read them with {{.Fields.Get "key"}}. Subscribers
+// without values are left out.
+func (service *Service) GetFields(list []entity.Subscribers) (map[int64]email.Fields, *subscribetoolError.ErrorCode) {
+	subscriberIDs := []int64{}
+	for _, subscriber := range list {
+		subscriberIDs = append(subscriberIDs, subscriber.ID)
+	}
+
+	fields := map[int64]email.Fields{}
+	for start := 0; start < len(subscriberIDs); start += FieldValuesBatchSize {
+		end := start + FieldValuesBatchSize
+		if end > len(subscriberIDs) {
+			end = len(subscriberIDs)
 		}
 
+		res, err := service.Repo.GetFieldValues(subscriberIDs[start:end])
+		if err != nil {
+			return nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+		}
+
+		for _, fieldValue := range res {
+			value := fromFieldValue(fieldValue)
+			if value == nil {
+				continue
+			}
+			if fields[fieldValue.SubscriberID] == nil {
+				fields[fieldValue.SubscriberID] = email.Fields{}
+			}
+			fields[fieldValue.SubscriberID][fieldValue.Key] = value
+		}
 	}
 
-	fmt.Println("sent mail to subscribers success")
+	return fields, nil
+}
+
+// fromFieldValue is the value a template sees for fieldValue, the same one
+// the backend returns for it.
+func fromFieldValue(fieldValue entity.SubscriberFieldValues) interface{} {
+	switch fieldValue.Type {
+	case entity.FieldTypeNumber:
+		if fieldValue.NumberValue != nil {
+			return *fieldValue.NumberValue
+		}
 
+	case entity.FieldTypeBool:
+		if fieldValue.BoolValue != nil {
+			return *fieldValue.BoolValue
+		}
+
+	case entity.FieldTypeDate:
+		if fieldValue.DateValue != nil {
+			return fieldValue.DateValue.Format(segments.DateLayout)
+		}
+
+	default:
+		if fieldValue.StringValue != nil {
+			return *fieldValue.StringValue
+		}
+	}
 	return nil
 }
+
+// withUnsubscribeLink adds a footer with unsubscribeURL to body unless body
+// already links to it.
+func withUnsubscribeLink(body string, unsubscribeURL string) string {
+	if strings.Contains(body, unsubscribeURL) {
+		return body
+	}
+	return body + fmt.Sprintf(unsubscribeFooter, html.EscapeString(unsubscribeURL))
+}
+
+// GetRecipients returns the subscribers target picks.
+func (service *Service) GetRecipients(target Target) ([]entity.Subscribers, *subscribetoolError.ErrorCode) {
+	if target.ListID != nil {
+		return service.UseCase.GetSubscribersByListID(*target.ListID)
+	}
+
+	if target.SegmentID != nil {
+		return service.UseCase.GetSubscribersBySegmentID(*target.SegmentID)
+	}
+
+	return service.UseCase.GetAllSubscribers()
+}
@@ -2,7 +2,10 @@ package subscribers_test
 
 import (
 	"errors"
+	"strings"
 	"subscribetool/src/pkg/entity"
+	"subscribetool/src/pkg/segments"
+	segmentsMocks "subscribetool/src/pkg/segments/mocks"
 	subscribers "subscribetool/src/pkg/subscribers"
 	"subscribetool/src/pkg/subscribers/mocks"
 	"subscribetool/src/pkg/utils/convert"
@@ -11,7 +14,9 @@ import (
 	subscribetoolError "subscribetool/src/pkg/utils/error"
 	loggerMocks "subscribetool/src/pkg/utils/logger/mocks"
 	"subscribetool/src/pkg/utils/mocker"
+	"subscribetool/src/pkg/utils/token"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -23,22 +28,121 @@ var (
 	service           *subscribers.Service
 	logs              *loggerMocks.Logger
 	utilsEmailService *emailMocks.UseCase
-
-	mockRepoGetAllSubscribers    *mocker.MockCall
-	mockServiceGetAllSubscribers *mocker.MockCall
-	mockUtilsEmailServiceSend    *mocker.MockCall
+	segmentsService   *segmentsMocks.UseCase
+
+	mockRepoGetAllSubscribers            *mocker.MockCall
+	mockRepoGetSubscribersByListID       *mocker.MockCall
+	mockRepoGetSubscribersByCondition    *mocker.MockCall
+	mockServiceGetAllSubscribers         *mocker.MockCall
+	mockServiceGetSubscribersByListID    *mocker.MockCall
+	mockServiceGetSubscribersBySegmentID *mocker.MockCall
+	mockSegmentsCondition                *mocker.MockCall
+	mockUtilsEmailServiceSendBatch       *mocker.MockCall
+	mockServiceGenerateUnsubscribeURL    *mocker.MockCall
+	mockServiceParseContent              *mocker.MockCall
+	mockServiceGetFields                 *mocker.MockCall
+	mockServiceGeneratePreferencesURL    *mocker.MockCall
+	mockRepoGetFieldValues               *mocker.MockCall
 )
 
 func callRepoGetAllSubscribers() *mock.Call {
 	return repository.On("GetAllSubscribers")
 }
 
+func callRepoGetSubscribersByListID() *mock.Call {
+	return repository.On("GetSubscribersByListID", mock.Anything)
+}
+
+func callRepoGetSubscribersByCondition() *mock.Call {
+	return repository.On("GetSubscribersByCondition", mock.Anything)
+}
+
 func callServiceGetAllSubscribers() *mock.Call {
 	return mockUseCase.On("GetAllSubscribers")
 }
 
-func callUtilsEmailServiceSend() *mock.Call {
-	return utilsEmailService.On("Send", mock.Anything)
+func callServiceGetSubscribersByListID() *mock.Call {
+	return mockUseCase.On("GetSubscribersByListID", mock.Anything)
+}
+
+func callServiceGetSubscribersBySegmentID() *mock.Call {
+	return mockUseCase.On("GetSubscribersBySegmentID", mock.Anything)
+}
+
+func callSegmentsCondition() *mock.Call {
+	return segmentsService.On("Condition", mock.Anything)
+}
+
+func callServiceGenerateUnsubscribeURL() *mock.Call {
+	return mockUseCase.On("GenerateUnsubscribeURL", mock.Anything)
+}
+
+func callServiceGetFields() *mock.Call {
+	return mockUseCase.On("GetFields", mock.Anything)
+}
+
+func callServiceGeneratePreferencesURL() *mock.Call {
+	return mockUseCase.On("GeneratePreferencesURL", mock.Anything)
+}
+
+func callRepoGetFieldValues() *mock.Call {
+	return repository.On("GetFieldValues", mock.Anything)
+}
+
+func callServiceParseContent() *mock.Call {
+	return mockUseCase.On("ParseContent", mock.Anything)
+}
+
+// parseContent parses content the way the service would, for ParseContent
+// mocks to return.
+func parseContent(content subscribers.Content) *email.Template {
+	template, err := templates.Parse(content.Subject, content.Body, content.Text)
+	if err != nil {
+		panic(err)
+	}
+	return template
+}
+
+func callUtilsEmailServiceSendBatch() *mock.Call {
+	return utilsEmailService.On("SendBatch", mock.Anything)
+}
+
+// sendBatch answers SendBatch the way the email service would, failing the
+// emails to failing.
+func sendBatch(failing ...string) func(contents []email.SentMailContent) <-chan email.SendResult {
+	return func(contents []email.SentMailContent) <-chan email.SendResult {
+		results := make(chan email.SendResult, len(contents))
+		for _, content := range contents {
+			result := email.SendResult{Content: content}
+			for _, to := range failing {
+				if content.To[0] == to {
+					result.Error = email.NewSendError(errors.New("550 mailbox unavailable"))
+				}
+			}
+			results <- result
+		}
+		close(results)
+		return results
+	}
+}
+
+// sentContents returns every content handed to SendBatch.
+func sentContents() []email.SentMailContent {
+	contents := []email.SentMailContent{}
+	for _, call := range utilsEmailService.Calls {
+		if call.Method == "SendBatch" {
+			contents = append(contents, call.Arguments.Get(0).([]email.SentMailContent)...)
+		}
+	}
+	return contents
+}
+
+var templates, _ = email.NewRenderer("")
+
+var serviceConfig = subscribers.ServiceConfig{
+	TokenSecret:    "secret",
+	UnsubscribeURL: "http://localhost:8000/subscribers/unsubscribe",
+	PreferencesURL: "http://localhost:8000/preferences",
 }
 
 func beforeEach() {
@@ -46,11 +150,15 @@ func beforeEach() {
 	repository = &mocks.Repository{}
 	logs = &loggerMocks.Logger{}
 	utilsEmailService = &emailMocks.UseCase{}
+	segmentsService = &segmentsMocks.UseCase{}
 
 	logs.On("Error", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
 
 	service = &subscribers.Service{
 		Repo:              repository,
+		Segments:          segmentsService,
+		Templates:         templates,
+		Config:            serviceConfig,
 		Logs:              logs,
 		UtilsEmailService: utilsEmailService,
 	}
@@ -62,6 +170,9 @@ func TestService_NewService(t *testing.T) {
 		beforeEach()
 		serviceParam := subscribers.ServiceParam{
 			Repo:              repository,
+			Segments:          segmentsService,
+			Templates:         templates,
+			Config:            serviceConfig,
 			Logs:              logs,
 			UtilsEmailService: utilsEmailService,
 		}
@@ -69,6 +180,9 @@ func TestService_NewService(t *testing.T) {
 
 		expectedService := &subscribers.Service{
 			Repo:              repository,
+			Segments:          segmentsService,
+			Templates:         templates,
+			Config:            serviceConfig,
 			Logs:              logs,
 			UtilsEmailService: utilsEmailService,
 		}
@@ -134,30 +248,158 @@ func TestService_GetAllSubscribers(t *testing.T) {
 
 }
 
+func TestService_GetSubscribersByListID(t *testing.T) {
+	beforeEachGetSubscribersByListID := func() {
+		beforeEach()
+
+		mockRepoGetSubscribersByListID = mocker.NewMockCall(callRepoGetSubscribersByListID)
+		mockRepoGetSubscribersByListID.Return(nil, nil)
+	}
+
+	t.Run("should call repository get subscribers by list id with list id", func(t *testing.T) {
+		beforeEachGetSubscribersByListID()
+
+		service.GetSubscribersByListID(3)
+
+		repository.AssertCalled(t, "GetSubscribersByListID", int64(3))
+	})
+
+	t.Run("should response internal server error when repository get subscribers by list id failed", func(t *testing.T) {
+		beforeEachGetSubscribersByListID()
+		mockRepoGetSubscribersByListID.Return(nil, errors.New("Error"))
+
+		res, err := service.GetSubscribersByListID(3)
+
+		expectedError := convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
+		assert.Equal(t, expectedError, err)
+		assert.Nil(t, res)
+	})
+
+	t.Run("should return data when found data", func(t *testing.T) {
+		beforeEachGetSubscribersByListID()
+		mockRepoGetSubscribersByListID.Return(mockDataSubscribers(), nil)
+
+		res, err := service.GetSubscribersByListID(3)
+
+		assert.Equal(t, mockDataSubscribers(), res)
+		assert.Nil(t, err)
+	})
+}
+
+func TestService_GetSubscribersBySegmentID(t *testing.T) {
+	condition := segments.Condition{Where: "(s.[Language] = @p1)", Args: []interface{}{"th"}}
+
+	beforeEachGetSubscribersBySegmentID := func() {
+		beforeEach()
+
+		mockSegmentsCondition = mocker.NewMockCall(callSegmentsCondition)
+		mockSegmentsCondition.Return(&condition, nil)
+		mockRepoGetSubscribersByCondition = mocker.NewMockCall(callRepoGetSubscribersByCondition)
+		mockRepoGetSubscribersByCondition.Return(mockDataSubscribers(), nil)
+	}
+
+	t.Run("should call repository get subscribers by condition with compiled segment", func(t *testing.T) {
+		beforeEachGetSubscribersBySegmentID()
+
+		res, err := service.GetSubscribersBySegmentID(5)
+
+		assert.Equal(t, mockDataSubscribers(), res)
+		assert.Nil(t, err)
+		segmentsService.AssertCalled(t, "Condition", int64(5))
+		repository.AssertCalled(t, "GetSubscribersByCondition", condition)
+	})
+
+	t.Run("should return error without query when segment does not compile", func(t *testing.T) {
+		beforeEachGetSubscribersBySegmentID()
+		mockSegmentsCondition.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest))
+
+		res, err := service.GetSubscribersBySegmentID(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.BadRequest), err)
+		assert.Nil(t, res)
+		repository.AssertNotCalled(t, "GetSubscribersByCondition", mock.Anything)
+	})
+
+	t.Run("should response internal server error when repository get subscribers by condition failed", func(t *testing.T) {
+		beforeEachGetSubscribersBySegmentID()
+		mockRepoGetSubscribersByCondition.Return(nil, errors.New("Error"))
+
+		res, err := service.GetSubscribersBySegmentID(5)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Nil(t, res)
+	})
+}
+
 func TestService_SentEmail(t *testing.T) {
+	unsubscribeURL := "http://localhost:8000/subscribers/unsubscribe/token"
+	bodyWithUnsubscribeLink := "This email sent for notification. Test sent mail for subscribers" +
+		`<p style="font-size:12px;color:#666666">Don't want these emails? <a href="http://localhost:8000/subscribers/unsubscribe/token">Unsubscribe</a></p>`
+	content := subscribers.Content{
+		Subject: "Test sent mail for subscribers",
+		Body:    "This email sent for notification. Test sent mail for subscribers",
+	}
+
 	beforeEachSentEmail := func() {
 		beforeEach()
 
 		mockServiceGetAllSubscribers = mocker.NewMockCall(callServiceGetAllSubscribers)
 		mockServiceGetAllSubscribers.Return(nil, nil)
-		mockUtilsEmailServiceSend = mocker.NewMockCall(callUtilsEmailServiceSend)
-		mockUtilsEmailServiceSend.Return(nil)
-
+		mockServiceGetSubscribersByListID = mocker.NewMockCall(callServiceGetSubscribersByListID)
+		mockServiceGetSubscribersByListID.Return(nil, nil)
+		mockServiceGetSubscribersBySegmentID = mocker.NewMockCall(callServiceGetSubscribersBySegmentID)
+		mockServiceGetSubscribersBySegmentID.Return(nil, nil)
+		mockUtilsEmailServiceSendBatch = mocker.NewMockCall(callUtilsEmailServiceSendBatch)
+		mockUtilsEmailServiceSendBatch.Return(sendBatch())
+		mockServiceGenerateUnsubscribeURL = mocker.NewMockCall(callServiceGenerateUnsubscribeURL)
+		mockServiceGenerateUnsubscribeURL.Return(unsubscribeURL)
+		mockServiceParseContent = mocker.NewMockCall(callServiceParseContent)
+		mockServiceParseContent.Return(parseContent(content), nil)
+		mockServiceGetFields = mocker.NewMockCall(callServiceGetFields)
+		mockServiceGetFields.Return(map[int64]email.Fields{}, nil)
+		mockServiceGeneratePreferencesURL = mocker.NewMockCall(callServiceGeneratePreferencesURL)
+		mockServiceGeneratePreferencesURL.Return("http://localhost:8000/preferences/token")
 	}
 
 	t.Run("should call service get all subscribers when call service sent email", func(t *testing.T) {
 		beforeEachSentEmail()
 
-		service.SentEmail()
+		service.SentEmail(subscribers.Target{}, content)
 
 		mockUseCase.AssertCalled(t, "GetAllSubscribers")
 	})
 
+	t.Run("should call service get subscribers by list id when target has list", func(t *testing.T) {
+		beforeEachSentEmail()
+		mockServiceGetSubscribersByListID.Return(mockDataSubscribers(), nil)
+		listID := int64(3)
+
+		_, err := service.SentEmail(subscribers.Target{ListID: &listID}, content)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "GetSubscribersByListID", int64(3))
+		mockUseCase.AssertNotCalled(t, "GetAllSubscribers")
+		assert.Len(t, sentContents(), 1)
+	})
+
+	t.Run("should call service get subscribers by segment id when target has segment", func(t *testing.T) {
+		beforeEachSentEmail()
+		mockServiceGetSubscribersBySegmentID.Return(mockDataSubscribers(), nil)
+		segmentID := int64(5)
+
+		_, err := service.SentEmail(subscribers.Target{SegmentID: &segmentID}, content)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "GetSubscribersBySegmentID", int64(5))
+		mockUseCase.AssertNotCalled(t, "GetAllSubscribers")
+		assert.Len(t, sentContents(), len(mockDataSubscribers()))
+	})
+
 	t.Run("should return internal server error when call service get all subscribers failed", func(t *testing.T) {
 		beforeEachSentEmail()
 		mockServiceGetAllSubscribers.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError))
 
-		err := service.SentEmail()
+		_, err := service.SentEmail(subscribers.Target{}, content)
 
 		expectedError := convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
 		assert.Equal(t, expectedError, err)
@@ -167,7 +409,7 @@ func TestService_SentEmail(t *testing.T) {
 		beforeEachSentEmail()
 		mockServiceGetAllSubscribers.Return([]entity.Subscribers{}, nil)
 
-		err := service.SentEmail()
+		_, err := service.SentEmail(subscribers.Target{}, content)
 
 		expectedError := convert.ValueToErrorCodePointer(subscribetoolError.DataNotFound)
 		assert.Equal(t, expectedError, err)
@@ -179,45 +421,359 @@ func TestService_SentEmail(t *testing.T) {
 		mockDataSubscribers := mockDataSubscribers()
 		mockServiceGetAllSubscribers.Return(mockDataSubscribers, nil)
 
-		service.SentEmail()
+		service.SentEmail(subscribers.Target{}, content)
 
 		emailTarget := []string{mockDataSubscribers[0].Email}
 
 		mailInfo := email.SentMailContent{
-			To:      emailTarget,
-			Supject: "Test sent mail for subscribers",
-			Body:    "This email sent for notification. Test sent mail for subscribers",
+			To:             emailTarget,
+			Supject:        content.Subject,
+			Body:           bodyWithUnsubscribeLink,
+			UnsubscribeURL: unsubscribeURL,
 		}
 
-		utilsEmailService.AssertCalled(t, "Send", mailInfo)
+		mockUseCase.AssertCalled(t, "GenerateUnsubscribeURL", mockDataSubscribers[0].Email)
+		assert.Contains(t, sentContents(), mailInfo)
 	})
 
-	t.Run("should return internal server error when call utils email service send failed", func(t *testing.T) {
+	t.Run("should not add unsubscribe footer when body already links to it", func(t *testing.T) {
 		beforeEachSentEmail()
+		mockDataSubscribers := mockDataSubscribers()
+		mockServiceGetAllSubscribers.Return(mockDataSubscribers[:1], nil)
+		mockServiceGenerateUnsubscribeURL.Return(unsubscribeURL)
+		withLink := content
+		withLink.Body = `<a href="` + unsubscribeURL + `">leave</a>`
+		mockServiceParseContent.Return(parseContent(withLink), nil)
+
+		_, err := service.SentEmail(subscribers.Target{}, withLink)
 
+		assert.Nil(t, err)
+		assert.Contains(t, sentContents(), email.SentMailContent{
+			To:             []string{mockDataSubscribers[0].Email},
+			Supject:        content.Subject,
+			Body:           withLink.Body,
+			UnsubscribeURL: unsubscribeURL,
+		})
+	})
+
+	t.Run("should send sender and text of content with unsubscribe link in text", func(t *testing.T) {
+		beforeEachSentEmail()
+		mockDataSubscribers := mockDataSubscribers()
+		mockServiceGetAllSubscribers.Return(mockDataSubscribers[:1], nil)
+		withText := content
+		withText.Sender = "campaign@newsletter.com"
+		withText.Text = "Hello"
+		mockServiceParseContent.Return(parseContent(withText), nil)
+
+		_, err := service.SentEmail(subscribers.Target{}, withText)
+
+		assert.Nil(t, err)
+		assert.Contains(t, sentContents(), email.SentMailContent{
+			Sender:         "campaign@newsletter.com",
+			To:             []string{mockDataSubscribers[0].Email},
+			Supject:        content.Subject,
+			Body:           bodyWithUnsubscribeLink,
+			Text:           "Hello\n\nUnsubscribe: " + unsubscribeURL,
+			UnsubscribeURL: unsubscribeURL,
+		})
+	})
+
+	t.Run("should render content for each subscriber", func(t *testing.T) {
+		beforeEachSentEmail()
+		mockDataSubscribers := mockDataSubscribers()
+		mockServiceGetAllSubscribers.Return(mockDataSubscribers[:1], nil)
+		templated := subscribers.Content{
+			Subject: "Hello {{.Name}}",
+			Body:    `<p>Hi {{.Name}}</p><a href="{{.UnsubscribeURL}}">leave</a>`,
+			Text:    "Hi {{.Name}} <{{.Email}}> {{.UnsubscribeURL}}",
+		}
+		mockServiceParseContent.Return(parseContent(templated), nil)
+
+		_, err := service.SentEmail(subscribers.Target{}, templated)
+
+		assert.Nil(t, err)
+		assert.Contains(t, sentContents(), email.SentMailContent{
+			To:             []string{mockDataSubscribers[0].Email},
+			Supject:        "Hello " + mockDataSubscribers[0].Name,
+			Body:           `<p>Hi ` + mockDataSubscribers[0].Name + `</p><a href="` + unsubscribeURL + `">leave</a>`,
+			Text:           "Hi " + mockDataSubscribers[0].Name + " <" + mockDataSubscribers[0].Email + "> " + unsubscribeURL,
+			UnsubscribeURL: unsubscribeURL,
+		})
+	})
+
+	t.Run("should return invalid template before getting subscribers when content does not parse", func(t *testing.T) {
+		beforeEachSentEmail()
+		mockServiceParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+
+		_, err := service.SentEmail(subscribers.Target{}, content)
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		mockUseCase.AssertNotCalled(t, "GetAllSubscribers")
+		utilsEmailService.AssertNotCalled(t, "SendBatch", mock.Anything)
+	})
+
+	t.Run("should send attachments of content to every subscriber", func(t *testing.T) {
+		beforeEachSentEmail()
 		mockDataSubscribers := mockDataSubscribers()
 		mockServiceGetAllSubscribers.Return(mockDataSubscribers, nil)
-		mockUtilsEmailServiceSend.Return(errors.New("error"))
+		withAttachments := content
+		withAttachments.Attachments = []email.Attachment{
+			{FileName: "logo.png", ContentType: "image/png", ContentID: "logo", Data: []byte("png")},
+			{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")},
+		}
 
-		err := service.SentEmail()
+		_, err := service.SentEmail(subscribers.Target{}, withAttachments)
 
-		expectedError := convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError)
-		assert.Equal(t, expectedError, err)
+		assert.Nil(t, err)
+		for _, subscriber := range mockDataSubscribers {
+			assert.Contains(t, sentContents(), email.SentMailContent{
+				To:             []string{subscriber.Email},
+				Supject:        content.Subject,
+				Body:           bodyWithUnsubscribeLink,
+				UnsubscribeURL: unsubscribeURL,
+				Attachments:    withAttachments.Attachments,
+			})
+		}
 	})
 
-	t.Run("should return error nil when call service sent mail success", func(t *testing.T) {
+	t.Run("should report failed email and still send to the others", func(t *testing.T) {
+		beforeEachSentEmail()
+		ajis := entity.Subscribers{ID: 1, Name: "ajis", Email: "ajis@gmail.com", IsSubscribed: true}
+		mana := entity.Subscribers{ID: 2, Name: "mana", Email: "mana@gmail.com", IsSubscribed: true}
+		mockServiceGetAllSubscribers.Return([]entity.Subscribers{ajis, mana}, nil)
+		mockUtilsEmailServiceSendBatch.Return(sendBatch(ajis.Email))
+
+		res, err := service.SentEmail(subscribers.Target{}, content)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []entity.Subscribers{mana}, res.Sent)
+		assert.Equal(t, []subscribers.Failure{{Subscriber: ajis, Error: "550 mailbox unavailable", Permanent: true}}, res.Failed)
+		assert.Len(t, sentContents(), 2)
+	})
+
+	t.Run("should return report of every subscriber sent when call service sent mail success", func(t *testing.T) {
 		beforeEachSentEmail()
 		mockDataSubscribers := mockDataSubscribers()
 		mockServiceGetAllSubscribers.Return(mockDataSubscribers, nil)
-		mockUtilsEmailServiceSend.Return(nil)
 
-		err := service.SentEmail()
+		res, err := service.SentEmail(subscribers.Target{}, content)
 
 		assert.Nil(t, err)
+		assert.Equal(t, &subscribers.Report{Sent: mockDataSubscribers}, res)
 	})
 
 }
 
+func TestService_SentEmailTo(t *testing.T) {
+	t.Run("should not send to anyone when content does not parse", func(t *testing.T) {
+		beforeEach()
+		mockServiceParseContent = mocker.NewMockCall(callServiceParseContent)
+		mockServiceParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+		mockUtilsEmailServiceSendBatch = mocker.NewMockCall(callUtilsEmailServiceSendBatch)
+		mockUtilsEmailServiceSendBatch.Return(sendBatch())
+
+		_, err := service.SentEmailTo(mockDataSubscribers(), subscribers.Content{Subject: "test", Body: "{{.Unknown}}"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		utilsEmailService.AssertNotCalled(t, "SendBatch", mock.Anything)
+	})
+}
+
+func TestService_SentEmailToRecorded(t *testing.T) {
+	content := subscribers.Content{Subject: "subject", Body: "body"}
+	ajis := entity.Subscribers{ID: 1, Name: "ajis", Email: "ajis@gmail.com", IsSubscribed: true}
+	mana := entity.Subscribers{ID: 2, Name: "mana", Email: "mana@gmail.com", IsSubscribed: true}
+
+	beforeEachSentEmailToRecorded := func() {
+		beforeEach()
+		mockServiceParseContent = mocker.NewMockCall(callServiceParseContent)
+		mockServiceParseContent.Return(parseContent(content), nil)
+		mockServiceGenerateUnsubscribeURL = mocker.NewMockCall(callServiceGenerateUnsubscribeURL)
+		mockServiceGenerateUnsubscribeURL.Return("http://localhost:8000/subscribers/unsubscribe/token")
+		mockUtilsEmailServiceSendBatch = mocker.NewMockCall(callUtilsEmailServiceSendBatch)
+		mockUtilsEmailServiceSendBatch.Return(sendBatch(mana.Email))
+		mockServiceGetFields = mocker.NewMockCall(callServiceGetFields)
+		mockServiceGetFields.Return(map[int64]email.Fields{}, nil)
+		mockServiceGeneratePreferencesURL = mocker.NewMockCall(callServiceGeneratePreferencesURL)
+		mockServiceGeneratePreferencesURL.Return("http://localhost:8000/preferences/token")
+	}
+
+	t.Run("should tell recorder what became of each subscriber", func(t *testing.T) {
+		beforeEachSentEmailToRecorded()
+		recorded := map[string]*email.SendError{}
+
+		res, err := service.SentEmailToRecorded([]entity.Subscribers{ajis, mana}, content, func(subscriber entity.Subscribers, result email.SendResult) {
+			recorded[subscriber.Email] = result.Error
+		})
+
+		assert.Nil(t, err)
+		assert.Nil(t, recorded[ajis.Email])
+		assert.EqualError(t, recorded[mana.Email], "550 mailbox unavailable")
+		assert.Equal(t, []entity.Subscribers{ajis}, res.Sent)
+	})
+
+	t.Run("should render template written in the backend with custom fields and preferences link", func(t *testing.T) {
+		beforeEachSentEmailToRecorded()
+		backendContent := subscribers.Content{
+			Subject: `{{with .Fields.Get "plan"}}[{{.}}] {{end}}News for {{.Name}}`,
+			Body:    `<p>Hi {{.Name}} from {{.Fields.Get "country"}}</p>{{if .Fields.Get "vip"}}<p>VIP</p>{{end}}<a href="{{.PreferencesURL}}">Preferences</a><a href="{{.UnsubscribeURL}}">Unsubscribe</a>`,
+			Text:    `Hi {{.Name}} {{if .Fields.Get "vip"}}(VIP) {{end}}{{.PreferencesURL}} {{.UnsubscribeURL}}`,
+		}
+		mockServiceParseContent.Return(parseContent(backendContent), nil)
+		mockServiceGetFields.Return(map[int64]email.Fields{ajis.ID: {"plan": "pro", "country": "TH", "vip": true}}, nil)
+
+		_, err := service.SentEmailToRecorded([]entity.Subscribers{ajis, mana}, backendContent, nil)
+
+		assert.Nil(t, err)
+		mockUseCase.AssertCalled(t, "GetFields", []entity.Subscribers{ajis, mana})
+		contents := sentContents()
+		assert.Equal(t, "[pro] News for ajis", contents[0].Supject)
+		assert.Equal(t, `<p>Hi ajis from TH</p><p>VIP</p><a href="http://localhost:8000/preferences/token">Preferences</a><a href="http://localhost:8000/subscribers/unsubscribe/token">Unsubscribe</a>`, contents[0].Body)
+		assert.Equal(t, "Hi ajis (VIP) http://localhost:8000/preferences/token http://localhost:8000/subscribers/unsubscribe/token", contents[0].Text)
+		assert.Equal(t, "News for mana", contents[1].Supject)
+		assert.Equal(t, `<p>Hi mana from </p><a href="http://localhost:8000/preferences/token">Preferences</a><a href="http://localhost:8000/subscribers/unsubscribe/token">Unsubscribe</a>`, contents[1].Body)
+	})
+
+	t.Run("should not send anything when custom fields cannot be read", func(t *testing.T) {
+		beforeEachSentEmailToRecorded()
+		mockServiceGetFields.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError))
+		recorded := 0
+
+		_, err := service.SentEmailToRecorded([]entity.Subscribers{ajis, mana}, content, func(subscriber entity.Subscribers, result email.SendResult) {
+			recorded++
+		})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+		assert.Equal(t, 0, recorded)
+		utilsEmailService.AssertNotCalled(t, "SendBatch", mock.Anything)
+	})
+
+	t.Run("should not tell recorder anything when content does not parse", func(t *testing.T) {
+		beforeEachSentEmailToRecorded()
+		mockServiceParseContent.Return(nil, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate))
+		recorded := 0
+
+		_, err := service.SentEmailToRecorded([]entity.Subscribers{ajis, mana}, content, func(subscriber entity.Subscribers, result email.SendResult) {
+			recorded++
+		})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		assert.Equal(t, 0, recorded)
+	})
+}
+
+func TestService_ParseContent(t *testing.T) {
+	t.Run("should return template when content parses", func(t *testing.T) {
+		beforeEach()
+
+		res, err := service.ParseContent(subscribers.Content{Subject: "Hello {{.Name}}", Body: "<p>{{.Email}}</p>"})
+
+		assert.Nil(t, err)
+		assert.NotNil(t, res)
+	})
+
+	t.Run("should return invalid template when content references unknown field", func(t *testing.T) {
+		beforeEach()
+
+		res, err := service.ParseContent(subscribers.Content{Subject: "test", Body: "<p>{{.Unknown}}</p>"})
+
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InvalidTemplate), err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestService_GenerateUnsubscribeURL(t *testing.T) {
+	t.Run("should return unsubscribe url with token the backend verifies", func(t *testing.T) {
+		beforeEach()
+
+		res := service.GenerateUnsubscribeURL("ajistestmail@gmail.com")
+
+		prefix := serviceConfig.UnsubscribeURL + "/"
+		assert.True(t, strings.HasPrefix(res, prefix))
+		subject, err := token.Verify(serviceConfig.TokenSecret, subscribers.UnsubscribeTokenPurpose, strings.TrimPrefix(res, prefix))
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", subject)
+	})
+}
+
+func TestService_GeneratePreferencesURL(t *testing.T) {
+	t.Run("should return preferences url with token the backend verifies", func(t *testing.T) {
+		beforeEach()
+
+		res := service.GeneratePreferencesURL("ajistestmail@gmail.com")
+
+		prefix := serviceConfig.PreferencesURL + "/"
+		assert.True(t, strings.HasPrefix(res, prefix))
+		subject, err := token.Verify(serviceConfig.TokenSecret, subscribers.PreferencesTokenPurpose, strings.TrimPrefix(res, prefix))
+		assert.Nil(t, err)
+		assert.Equal(t, "ajistestmail@gmail.com", subject)
+	})
+
+	t.Run("should return empty url when preferences url is not configured", func(t *testing.T) {
+		beforeEach()
+		service.Config.PreferencesURL = ""
+
+		res := service.GeneratePreferencesURL("ajistestmail@gmail.com")
+
+		assert.Equal(t, "", res)
+	})
+}
+
+func TestService_GetFields(t *testing.T) {
+	ajis := entity.Subscribers{ID: 1, Email: "ajis@gmail.com"}
+	mana := entity.Subscribers{ID: 2, Email: "mana@gmail.com"}
+
+	beforeEachGetFields := func() {
+		beforeEach()
+		mockRepoGetFieldValues = mocker.NewMockCall(callRepoGetFieldValues)
+		mockRepoGetFieldValues.Return([]entity.SubscriberFieldValues{}, nil)
+	}
+
+	t.Run("should return values of each subscriber by key as the backend does", func(t *testing.T) {
+		beforeEachGetFields()
+		plan := "pro"
+		score := 4.5
+		vip := true
+		birthday := time.Date(1990, 4, 13, 0, 0, 0, 0, time.UTC)
+		mockRepoGetFieldValues.Return([]entity.SubscriberFieldValues{
+			{SubscriberID: 1, Key: "plan", Type: entity.FieldTypeEnum, StringValue: &plan},
+			{SubscriberID: 1, Key: "score", Type: entity.FieldTypeNumber, NumberValue: &score},
+			{SubscriberID: 2, Key: "vip", Type: entity.FieldTypeBool, BoolValue: &vip},
+			{SubscriberID: 2, Key: "birthday", Type: entity.FieldTypeDate, DateValue: &birthday},
+			{SubscriberID: 2, Key: "empty", Type: entity.FieldTypeString},
+		}, nil)
+
+		res, err := service.GetFields([]entity.Subscribers{ajis, mana})
+
+		assert.Nil(t, err)
+		assert.Equal(t, map[int64]email.Fields{
+			1: {"plan": "pro", "score": 4.5},
+			2: {"vip": true, "birthday": "1990-04-13"},
+		}, res)
+		repository.AssertCalled(t, "GetFieldValues", []int64{1, 2})
+	})
+
+	t.Run("should read values in batches", func(t *testing.T) {
+		beforeEachGetFields()
+		list := make([]entity.Subscribers, subscribers.FieldValuesBatchSize+1)
+
+		_, err := service.GetFields(list)
+
+		assert.Nil(t, err)
+		repository.AssertNumberOfCalls(t, "GetFieldValues", 2)
+	})
+
+	t.Run("should return internal server error when repository failed", func(t *testing.T) {
+		beforeEachGetFields()
+		mockRepoGetFieldValues.Return(nil, errors.New("Error"))
+
+		res, err := service.GetFields([]entity.Subscribers{ajis})
+
+		assert.Nil(t, res)
+		assert.Equal(t, convert.ValueToErrorCodePointer(subscribetoolError.InternalServerError), err)
+	})
+}
+
 func mockDataSubscribers() []entity.Subscribers {
 	return []entity.Subscribers{
 		{
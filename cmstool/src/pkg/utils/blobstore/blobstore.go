@@ -0,0 +1,31 @@
+package blobstore
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// This file is kept identical, apart from the module name, in
+// backend/src/pkg/utils/blobstore and cmstool/src/pkg/utils/blobstore. Change
+// both copies together; a cmstool test fails when they drift apart.
+
+// ErrNotFound is returned by Get for a key that holds nothing.
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps file content under opaque keys. LocalStore is the default; a
+// store backed by object storage can replace it without changing callers.
+type Store interface {
+	Put(key string, data []byte) error
+	Get(key string) ([]byte, error)
+	Delete(key string) error
+}
+
+// NewKey returns a random key, so stored names never come from user input.
+func NewKey() (string, error) {
+	buffer := make([]byte, 16)
+	if _, err := rand.Read(buffer); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buffer), nil
+}
@@ -0,0 +1,63 @@
+package blobstore_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"subscribetool/src/pkg/utils/blobstore"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// backendBlobstoreDir holds the backend's copies of this package, found when
+// cmstool is checked out next to the backend.
+const backendBlobstoreDir = "../../../../../backend/src/pkg/utils/blobstore"
+
+func TestBlobStore_MatchesBackendCopy(t *testing.T) {
+	for _, file := range []string{"blobstore.go", "local.go"} {
+		file := file
+		t.Run("should be identical to "+file+" of backend", func(t *testing.T) {
+			backend, err := os.ReadFile(filepath.Join(backendBlobstoreDir, file))
+			if os.IsNotExist(err) {
+				t.Skip("backend is not checked out next to cmstool")
+			}
+			assert.Nil(t, err)
+
+			local, err := os.ReadFile(file)
+			assert.Nil(t, err)
+
+			assert.Equal(t, strings.ReplaceAll(string(backend), `"newsletter/`, `"subscribetool/`), string(local))
+		})
+	}
+}
+
+func TestLocalStore_Get(t *testing.T) {
+	t.Run("should return blob the backend put under key", func(t *testing.T) {
+		dir := t.TempDir()
+		os.WriteFile(filepath.Join(dir, "abc"), []byte("content"), 0o644)
+		store := blobstore.NewLocalStore(dir)
+
+		data, err := store.Get("abc")
+
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("content"), data)
+	})
+
+	t.Run("should return not found when key holds nothing", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+
+		data, err := store.Get("abc")
+
+		assert.Equal(t, blobstore.ErrNotFound, err)
+		assert.Nil(t, data)
+	})
+
+	t.Run("should return error when key leaves dir", func(t *testing.T) {
+		store := blobstore.NewLocalStore(t.TempDir())
+
+		_, err := store.Get("../abc")
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,32 @@
+package email_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// backendEmailDir holds the backend's copies of the shared email files,
+// found when cmstool is checked out next to the backend.
+const backendEmailDir = "../../../../../backend/src/pkg/email"
+
+func TestEmail_MatchesBackendCopy(t *testing.T) {
+	for _, file := range []string{"text.go", "template.go", "retry.go"} {
+		file := file
+		t.Run("should be identical to "+file+" of backend", func(t *testing.T) {
+			backend, err := os.ReadFile(filepath.Join(backendEmailDir, file))
+			if os.IsNotExist(err) {
+				t.Skip("backend is not checked out next to cmstool")
+			}
+			assert.Nil(t, err)
+
+			local, err := os.ReadFile(file)
+			assert.Nil(t, err)
+
+			assert.Equal(t, strings.ReplaceAll(string(backend), `"newsletter/`, `"subscribetool/`), string(local))
+		})
+	}
+}
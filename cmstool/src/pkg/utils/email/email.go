@@ -2,64 +2,265 @@ package email
 
 import (
 	"errors"
+	"io"
 	"log"
+	"mime"
+	"sync"
+	"time"
 
 	"gopkg.in/gomail.v2"
 )
 
+const (
+	ListUnsubscribe         = "List-Unsubscribe"
+	ListUnsubscribePost     = "List-Unsubscribe-Post"
+	ListUnsubscribeOneClick = "List-Unsubscribe=One-Click"
+
+	defaultWorkers = 1
+)
+
 var (
 	FromEMailSender string
 )
 
+// ErrAttachmentsTooLarge is returned by BuildMessage, before anything is sent, when
+// the attachments of a message add up to more than MaxAttachmentsSize.
+var ErrAttachmentsTooLarge = errors.New("attachments too large")
+
+// Service builds messages and sends them over connections from Transport,
+// retrying transient failures as Retry says. MaxAttachmentsSize caps the
+// bytes of attachments in one message; zero does not cap them.
 type Service struct {
-	Service          UseCase
-	DialerMailServer *gomail.Dialer
+	Service            UseCase
+	Transport          Transport
+	MaxAttachmentsSize int64
+	Pool               PoolConfig
+	Retry              RetryConfig
 }
 
 type UseCase interface {
 	Send(sentMailContent SentMailContent) error
+	SendBatch(contents []SentMailContent) <-chan SendResult
 }
 
 type ServiceParam struct {
-	DialerMailServer *gomail.Dialer
+	Transport Transport
 }
 
-func NewService(dialerMailServer *gomail.Dialer) *Service {
+func NewService(transport Transport) *Service {
 	return &Service{
-		DialerMailServer: dialerMailServer,
+		Transport: transport,
 	}
 }
 
+// Send delivers a single message on its own connection. Transient failures
+// are retried with backoff; the returned error is then a *SendError.
 func (service *Service) Send(sentMailContent SentMailContent) error {
+	message, err := service.BuildMessage(sentMailContent)
+	if err != nil {
+		return err
+	}
+
+	var sendCloser gomail.SendCloser
+	defer func() {
+		if sendCloser != nil {
+			sendCloser.Close()
+		}
+	}()
+
+	if _, err := service.sendWithRetry(&sendCloser, message, nil); err != nil {
+		log.Println("Email sent Transport err : ", err)
+		return err
+	}
+
+	log.Println("Email sent successfully!")
+	return nil
+}
+
+// SendBatch delivers contents through a bounded pool of workers. Each worker
+// keeps its connection open between messages and all workers share one
+// messages-per-second limit. Every content gets exactly one result on the
+// returned channel, which is closed once the batch is done.
+func (service *Service) SendBatch(contents []SentMailContent) <-chan SendResult {
+	workers := service.Pool.Workers
+	if workers < 1 {
+		workers = defaultWorkers
+	}
+
+	jobs := make(chan SentMailContent)
+	results := make(chan SendResult, workers)
+
+	var limiter <-chan time.Time
+	var ticker *time.Ticker
+	if service.Pool.MessagesPerSecond > 0 {
+		ticker = time.NewTicker(time.Second / time.Duration(service.Pool.MessagesPerSecond))
+		limiter = ticker.C
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			service.worker(jobs, limiter, results)
+		}()
+	}
+
+	go func() {
+		for _, content := range contents {
+			jobs <- content
+		}
+		close(jobs)
+		wg.Wait()
+		if ticker != nil {
+			ticker.Stop()
+		}
+		close(results)
+	}()
+
+	return results
+}
+
+func (service *Service) worker(jobs <-chan SentMailContent, limiter <-chan time.Time, results chan<- SendResult) {
+	var sendCloser gomail.SendCloser
+	defer func() {
+		if sendCloser != nil {
+			sendCloser.Close()
+		}
+	}()
+
+	for content := range jobs {
+		result := SendResult{Content: content}
+		message, err := service.BuildMessage(content)
+		if err != nil {
+			// A message that cannot be built is never tried, and trying
+			// again will not build it either.
+			result.Error = &SendError{Err: err}
+		} else {
+			result.Attempts, result.Error = service.sendWithRetry(&sendCloser, message, limiter)
+		}
+		if result.Error != nil {
+			log.Println("Email sent Transport err : ", content.To, result.Error)
+		}
+		results <- result
+	}
+}
+
+// sendWithRetry sends message over sendCloser and retries transient failures
+// up to Retry.MaxAttempts, waiting for limiter before every try.
+func (service *Service) sendWithRetry(sendCloser *gomail.SendCloser, message *gomail.Message, limiter <-chan time.Time) (int, *SendError) {
+	maxAttempts := service.Retry.attempts()
+
+	for attempt := 1; ; attempt++ {
+		if limiter != nil {
+			<-limiter
+		}
+
+		err := service.sendOnce(sendCloser, message)
+		if err == nil {
+			return attempt, nil
+		}
+
+		sendError := NewSendError(err)
+		if sendError.Permanent || attempt >= maxAttempts {
+			return attempt, sendError
+		}
+
+		time.Sleep(service.Retry.backoff(attempt))
+	}
+}
+
+// sendOnce sends message over sendCloser, dialing when it is nil. After a
+// failure the session state is unknown, so the connection is dropped and the
+// next message starts on a fresh one.
+func (service *Service) sendOnce(sendCloser *gomail.SendCloser, message *gomail.Message) error {
+	if *sendCloser == nil {
+		resSendCloser, err := service.Transport.Dial()
+		if err != nil {
+			return err
+		}
+		*sendCloser = resSendCloser
+	}
+
+	err := gomail.Send(*sendCloser, message)
+	if err != nil {
+		(*sendCloser).Close()
+		*sendCloser = nil
+	}
+	return err
+}
+
+// BuildMessage builds content as a multipart/alternative message with a
+// plain-text and an HTML part, followed by its inline images and attachments.
+// It fails, before anything is sent, when content has no recipient or its
+// attachments are too large.
+func (service *Service) BuildMessage(sentMailContent SentMailContent) (*gomail.Message, error) {
 	message := gomail.NewMessage()
 
 	var emails []string
-	if len(sentMailContent.To) != 0 && sentMailContent.To != nil {
-		for _, mailTo := range sentMailContent.To {
-			if mailTo != "" {
-				emails = append(emails, mailTo)
-			}
+	for _, mailTo := range sentMailContent.To {
+		if mailTo != "" {
+			emails = append(emails, mailTo)
 		}
-	} else {
-		errMailtoEmpty := errors.New("email to empty")
-		return errMailtoEmpty
 	}
-
-	if len(emails) == 0 || emails == nil {
-		errMailtoEmpty := errors.New("email to empty")
-		return errMailtoEmpty
+	if len(emails) == 0 {
+		return nil, errors.New("email to empty")
 	}
 
 	message.SetHeader("To", emails...)
 	message.SetHeader("Subject", sentMailContent.Supject)
-	message.SetBody("text/html", sentMailContent.Body)
-	message.SetHeader("From", FromEMailSender)
+	if sentMailContent.UnsubscribeURL != "" {
+		message.SetHeader(ListUnsubscribe, "<"+sentMailContent.UnsubscribeURL+">")
+		message.SetHeader(ListUnsubscribePost, ListUnsubscribeOneClick)
+	}
+	// Clients show the last alternative they support, so HTML goes last.
+	text := sentMailContent.Text
+	if text == "" {
+		text = HTMLToText(sentMailContent.Body)
+	}
+	message.SetBody("text/plain", text)
+	message.AddAlternative("text/html", sentMailContent.Body)
 
-	if err := service.DialerMailServer.DialAndSend(message); err != nil {
-		log.Println("Email sent DialAndSend err : ", err)
-		return err
+	size := int64(0)
+	for _, attachment := range sentMailContent.Attachments {
+		size += int64(len(attachment.Data))
+	}
+	if service.MaxAttachmentsSize > 0 && size > service.MaxAttachmentsSize {
+		return nil, ErrAttachmentsTooLarge
+	}
+	for _, attachment := range sentMailContent.Attachments {
+		if attachment.ContentID != "" {
+			message.Embed(attachment.FileName, attachmentSettings(attachment)...)
+			continue
+		}
+		message.Attach(attachment.FileName, attachmentSettings(attachment)...)
+	}
+	sender := FromEMailSender
+	if sentMailContent.Sender != "" {
+		sender = sentMailContent.Sender
 	}
+	message.SetHeader("From", sender)
 
-	log.Println("Email sent successfully!")
-	return nil
+	return message, nil
+}
+
+// attachmentSettings makes gomail write attachment from memory rather than
+// open a file named FileName.
+func attachmentSettings(attachment Attachment) []gomail.FileSetting {
+	header := map[string][]string{}
+	if attachment.ContentType != "" {
+		header["Content-Type"] = []string{mime.FormatMediaType(attachment.ContentType, map[string]string{"name": attachment.FileName})}
+	}
+	if attachment.ContentID != "" {
+		header["Content-ID"] = []string{"<" + attachment.ContentID + ">"}
+	}
+
+	return []gomail.FileSetting{
+		gomail.Rename(attachment.FileName),
+		gomail.SetHeader(header),
+		gomail.SetCopyFunc(func(writer io.Writer) error {
+			_, err := writer.Write(attachment.Data)
+			return err
+		}),
+	}
 }
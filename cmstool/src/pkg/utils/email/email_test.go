@@ -0,0 +1,377 @@
+package email_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"subscribetool/src/pkg/utils/email"
+	"subscribetool/src/pkg/utils/email/mocks"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/gomail.v2"
+)
+
+func TestService_Send(t *testing.T) {
+	var (
+		service   *email.Service
+		transport *email.MemoryTransport
+	)
+
+	beforeEachSend := func() {
+		email.FromEMailSender = "newsletter@gmail.com"
+		transport = email.NewMemoryTransport()
+		service = email.NewService(transport)
+	}
+
+	t.Run("should return error when to is empty", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(email.SentMailContent{Supject: "subject", Body: "body"})
+
+		assert.NotNil(t, err)
+		assert.Equal(t, 0, transport.Len())
+	})
+
+	t.Run("should hand message with text and html parts to transport", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(email.SentMailContent{
+			To:      []string{"ajis@gmail.com"},
+			Supject: "subject",
+			Body:    "<p>Hello ajis</p>",
+		})
+
+		assert.Nil(t, err)
+		sent, ok := transport.Last()
+		assert.True(t, ok)
+		assert.Equal(t, "newsletter@gmail.com", sent.From)
+		assert.Equal(t, []string{"ajis@gmail.com"}, sent.To)
+		assert.Equal(t, "subject", sent.Subject)
+		assert.Equal(t, "Hello ajis", sent.Text)
+		assert.Equal(t, "<p>Hello ajis</p>", sent.HTML)
+	})
+
+	t.Run("should send from sender of message when it has one", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(email.SentMailContent{
+			Sender:  "campaign@newsletter.com",
+			To:      []string{"ajis@gmail.com"},
+			Supject: "subject",
+			Body:    "body",
+		})
+
+		assert.Nil(t, err)
+		sent, _ := transport.Last()
+		assert.Equal(t, "campaign@newsletter.com", sent.From)
+	})
+
+	t.Run("should add one-click unsubscribe headers when message has unsubscribe url", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(email.SentMailContent{
+			To:             []string{"ajis@gmail.com"},
+			Supject:        "subject",
+			Body:           "<p>Hello ajis</p>",
+			UnsubscribeURL: "http://localhost:8000/subscribers/unsubscribe/token",
+		})
+
+		assert.Nil(t, err)
+		sent, _ := transport.Last()
+		message, _ := mail.ReadMessage(bytes.NewReader(sent.Raw))
+		assert.Equal(t, "<http://localhost:8000/subscribers/unsubscribe/token>", message.Header.Get(email.ListUnsubscribe))
+		assert.Equal(t, email.ListUnsubscribeOneClick, message.Header.Get(email.ListUnsubscribePost))
+	})
+
+	t.Run("should not add unsubscribe headers when message has no unsubscribe url", func(t *testing.T) {
+		beforeEachSend()
+
+		err := service.Send(email.SentMailContent{To: []string{"ajis@gmail.com"}, Supject: "subject", Body: "body"})
+
+		assert.Nil(t, err)
+		sent, _ := transport.Last()
+		message, _ := mail.ReadMessage(bytes.NewReader(sent.Raw))
+		assert.Empty(t, message.Header.Get(email.ListUnsubscribe))
+		assert.Empty(t, message.Header.Get(email.ListUnsubscribePost))
+	})
+
+	t.Run("should send inline images and files", func(t *testing.T) {
+		beforeEachSend()
+		attachments := []email.Attachment{
+			{FileName: "logo.png", ContentType: "image/png", ContentID: "logo.png", Data: []byte("png")},
+			{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")},
+		}
+
+		err := service.Send(email.SentMailContent{
+			To:          []string{"ajis@gmail.com"},
+			Supject:     "subject",
+			Body:        `<img src="cid:logo.png">`,
+			Attachments: attachments,
+		})
+
+		assert.Nil(t, err)
+		sent, _ := transport.Last()
+		assert.ElementsMatch(t, attachments, sent.Attachments)
+	})
+
+	t.Run("should return attachments too large before sending", func(t *testing.T) {
+		beforeEachSend()
+		service.MaxAttachmentsSize = 5
+
+		err := service.Send(email.SentMailContent{
+			To:      []string{"ajis@gmail.com"},
+			Supject: "subject",
+			Body:    `<img src="cid:logo.png">`,
+			Attachments: []email.Attachment{
+				{FileName: "logo.png", ContentType: "image/png", ContentID: "logo.png", Data: []byte("png")},
+				{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")},
+			},
+		})
+
+		assert.Equal(t, email.ErrAttachmentsTooLarge, err)
+		assert.Equal(t, 0, transport.Len())
+	})
+
+	t.Run("should return error when transport failed to connect", func(t *testing.T) {
+		beforeEachSend()
+		failing := &mocks.Transport{}
+		failing.On("Dial").Return(nil, errors.New("error"))
+		service = email.NewService(failing)
+
+		err := service.Send(email.SentMailContent{To: []string{"ajis@gmail.com"}, Supject: "subject", Body: "body"})
+
+		assert.EqualError(t, err, "error")
+		failing.AssertNumberOfCalls(t, "Dial", 1)
+	})
+
+	t.Run("should close connection after sending", func(t *testing.T) {
+		beforeEachSend()
+		fake := &fakeTransport{}
+		service = email.NewService(fake)
+
+		err := service.Send(email.SentMailContent{To: []string{"ajis@gmail.com"}, Supject: "subject", Body: "body"})
+
+		assert.Nil(t, err)
+		assert.Equal(t, 1, fake.dialed)
+		assert.Equal(t, 1, fake.closed)
+	})
+}
+
+type fakeSendCloser struct {
+	transport *fakeTransport
+}
+
+func (sendCloser *fakeSendCloser) Send(from string, to []string, msg io.WriterTo) error {
+	sendCloser.transport.mutex.Lock()
+	defer sendCloser.transport.mutex.Unlock()
+
+	sendCloser.transport.sent = append(sendCloser.transport.sent, to...)
+	failures := sendCloser.transport.failTo[to[0]]
+	if len(failures) == 0 {
+		return nil
+	}
+	sendCloser.transport.failTo[to[0]] = failures[1:]
+	return failures[0]
+}
+
+func (sendCloser *fakeSendCloser) Close() error {
+	sendCloser.transport.mutex.Lock()
+	defer sendCloser.transport.mutex.Unlock()
+
+	sendCloser.transport.closed++
+	return nil
+}
+
+type fakeTransport struct {
+	mutex   sync.Mutex
+	dialErr error
+	failTo  map[string][]error
+	dialed  int
+	closed  int
+	sent    []string
+}
+
+func (transport *fakeTransport) Dial() (gomail.SendCloser, error) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	transport.dialed++
+	if transport.dialErr != nil {
+		return nil, transport.dialErr
+	}
+	return &fakeSendCloser{transport: transport}, nil
+}
+
+func batchContents(recipients ...string) []email.SentMailContent {
+	contents := []email.SentMailContent{}
+	for _, recipient := range recipients {
+		contents = append(contents, email.SentMailContent{
+			To:      []string{recipient},
+			Supject: "test",
+			Body:    "test",
+		})
+	}
+	return contents
+}
+
+func collectResults(results <-chan email.SendResult) map[string]email.SendResult {
+	resultByRecipient := map[string]email.SendResult{}
+	for result := range results {
+		resultByRecipient[result.Content.To[0]] = result
+	}
+	return resultByRecipient
+}
+
+func TestService_SendBatch(t *testing.T) {
+	var (
+		service *email.Service
+		fake    *fakeTransport
+	)
+
+	beforeEachSendBatch := func() {
+		email.FromEMailSender = "newsletter@gmail.com"
+		fake = &fakeTransport{}
+		service = email.NewService(fake)
+		service.Pool = email.PoolConfig{Workers: 2}
+		service.Retry = email.RetryConfig{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+	}
+
+	t.Run("should reuse one connection per worker when send batch", func(t *testing.T) {
+		beforeEachSendBatch()
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com", "c@gmail.com", "d@gmail.com", "e@gmail.com")))
+
+		assert.Len(t, results, 5)
+		for _, result := range results {
+			assert.Nil(t, result.Error)
+			assert.Equal(t, 1, result.Attempts)
+		}
+		assert.LessOrEqual(t, fake.dialed, 2)
+		assert.Equal(t, fake.dialed, fake.closed)
+		assert.ElementsMatch(t, []string{"a@gmail.com", "b@gmail.com", "c@gmail.com", "d@gmail.com", "e@gmail.com"}, fake.sent)
+	})
+
+	t.Run("should keep sending to other recipients when one recipient failed", func(t *testing.T) {
+		beforeEachSendBatch()
+		errRecipient := errors.New("550 mailbox unavailable")
+		fake.failTo = map[string][]error{"b@gmail.com": {errRecipient}}
+		service.Pool = email.PoolConfig{Workers: 1}
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com", "c@gmail.com")))
+
+		assert.Len(t, results, 3)
+		assert.Nil(t, results["a@gmail.com"].Error)
+		assert.ErrorContains(t, results["b@gmail.com"].Error, errRecipient.Error())
+		assert.Nil(t, results["c@gmail.com"].Error)
+		assert.Equal(t, 2, fake.dialed)
+	})
+
+	t.Run("should return error for message that cannot be built without sending it", func(t *testing.T) {
+		beforeEachSendBatch()
+		service.MaxAttachmentsSize = 2
+		contents := batchContents("a@gmail.com", "b@gmail.com")
+		contents[0].Attachments = []email.Attachment{{FileName: "menu.pdf", ContentType: "application/pdf", Data: []byte("pdf")}}
+
+		results := collectResults(service.SendBatch(contents))
+
+		assert.ErrorIs(t, results["a@gmail.com"].Error, email.ErrAttachmentsTooLarge)
+		assert.Equal(t, 0, results["a@gmail.com"].Attempts)
+		assert.Nil(t, results["b@gmail.com"].Error)
+		assert.Equal(t, []string{"b@gmail.com"}, fake.sent)
+	})
+
+	t.Run("should dead letter with reply code when server rejects permanently", func(t *testing.T) {
+		beforeEachSendBatch()
+		fake.failTo = map[string][]error{
+			"b@gmail.com": {&textproto.Error{Code: 550, Msg: "5.1.1 user unknown"}},
+		}
+		service.Pool = email.PoolConfig{Workers: 1}
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com")))
+
+		assert.Equal(t, 1, results["b@gmail.com"].Attempts)
+		assert.Equal(t, 550, results["b@gmail.com"].Error.Code)
+		assert.True(t, results["b@gmail.com"].Error.Permanent)
+		assert.Nil(t, results["a@gmail.com"].Error)
+	})
+
+	t.Run("should retry transient failure with backoff until success", func(t *testing.T) {
+		beforeEachSendBatch()
+		fake.failTo = map[string][]error{
+			"a@gmail.com": {
+				&textproto.Error{Code: 451, Msg: "temporary local problem"},
+				&net.OpError{Op: "dial", Err: errors.New("i/o timeout")},
+			},
+		}
+		service.Pool = email.PoolConfig{Workers: 1}
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com")))
+
+		assert.Nil(t, results["a@gmail.com"].Error)
+		assert.Equal(t, 3, results["a@gmail.com"].Attempts)
+	})
+
+	t.Run("should return transient error when attempts are exhausted", func(t *testing.T) {
+		beforeEachSendBatch()
+		errBusy := &textproto.Error{Code: 450, Msg: "mailbox busy"}
+		fake.failTo = map[string][]error{
+			"a@gmail.com": {errBusy, errBusy, errBusy, errBusy},
+		}
+		service.Pool = email.PoolConfig{Workers: 1}
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com")))
+
+		assert.Equal(t, 3, results["a@gmail.com"].Attempts)
+		assert.Equal(t, 450, results["a@gmail.com"].Error.Code)
+		assert.False(t, results["a@gmail.com"].Error.Permanent)
+	})
+
+	t.Run("should return result for every recipient when dial failed", func(t *testing.T) {
+		beforeEachSendBatch()
+		errDial := errors.New("dial failed")
+		fake.dialErr = errDial
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com")))
+
+		assert.Len(t, results, 2)
+		assert.ErrorIs(t, results["a@gmail.com"].Error, errDial)
+		assert.ErrorIs(t, results["b@gmail.com"].Error, errDial)
+		assert.Equal(t, 3, results["a@gmail.com"].Attempts)
+	})
+
+	t.Run("should close results when batch is empty", func(t *testing.T) {
+		beforeEachSendBatch()
+
+		results := collectResults(service.SendBatch([]email.SentMailContent{}))
+
+		assert.Empty(t, results)
+	})
+
+	t.Run("should limit messages per second when rate limit is set", func(t *testing.T) {
+		beforeEachSendBatch()
+		service.Pool = email.PoolConfig{Workers: 4, MessagesPerSecond: 20}
+
+		start := time.Now()
+		collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com", "c@gmail.com", "d@gmail.com", "e@gmail.com")))
+
+		assert.GreaterOrEqual(t, time.Since(start), 200*time.Millisecond)
+	})
+
+	t.Run("should send batch through memory transport", func(t *testing.T) {
+		beforeEachSendBatch()
+		memory := email.NewMemoryTransport()
+		service.Transport = memory
+
+		results := collectResults(service.SendBatch(batchContents("a@gmail.com", "b@gmail.com")))
+
+		assert.Len(t, results, 2)
+		assert.Equal(t, 2, memory.Len())
+		assert.Len(t, memory.SentTo("b@gmail.com"), 1)
+	})
+}
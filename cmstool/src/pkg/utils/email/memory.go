@@ -0,0 +1,231 @@
+package email
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"sync"
+
+	"gopkg.in/gomail.v2"
+)
+
+// CapturedMessage is a message MemoryTransport kept, with its parts decoded.
+// Raw is the message as it would have gone over the wire.
+type CapturedMessage struct {
+	From        string
+	To          []string
+	Subject     string
+	Text        string
+	HTML        string
+	Attachments []Attachment
+	Raw         []byte
+}
+
+// MemoryTransport keeps every message instead of sending it, for tests that
+// run a whole send and then look at what went out.
+type MemoryTransport struct {
+	mutex    sync.Mutex
+	messages []CapturedMessage
+}
+
+func NewMemoryTransport() *MemoryTransport {
+	return &MemoryTransport{}
+}
+
+func (transport *MemoryTransport) Dial() (gomail.SendCloser, error) {
+	return sendCloser(transport.capture), nil
+}
+
+// Send keeps message on its own, as gomail.Send would.
+func (transport *MemoryTransport) Send(message *gomail.Message) error {
+	return gomail.Send(sendCloser(transport.capture), message)
+}
+
+func (transport *MemoryTransport) capture(from string, to []string, message io.WriterTo) error {
+	var raw bytes.Buffer
+	if _, err := message.WriteTo(&raw); err != nil {
+		return err
+	}
+	captured, err := captureMessage(raw.Bytes())
+	if err != nil {
+		return err
+	}
+	captured.To = to
+
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	transport.messages = append(transport.messages, captured)
+	return nil
+}
+
+// Messages returns the messages kept so far, oldest first.
+func (transport *MemoryTransport) Messages() []CapturedMessage {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return append([]CapturedMessage{}, transport.messages...)
+}
+
+func (transport *MemoryTransport) Len() int {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	return len(transport.messages)
+}
+
+// Last returns the newest message, and false when there is none.
+func (transport *MemoryTransport) Last() (CapturedMessage, bool) {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	if len(transport.messages) == 0 {
+		return CapturedMessage{}, false
+	}
+	return transport.messages[len(transport.messages)-1], true
+}
+
+// SentTo returns the messages addressed to address, compared ignoring case.
+func (transport *MemoryTransport) SentTo(address string) []CapturedMessage {
+	return transport.filter(func(captured CapturedMessage) bool {
+		for _, to := range captured.To {
+			if strings.EqualFold(to, address) {
+				return true
+			}
+		}
+		return false
+	})
+}
+
+// WithSubject returns the messages whose subject is subject.
+func (transport *MemoryTransport) WithSubject(subject string) []CapturedMessage {
+	return transport.filter(func(captured CapturedMessage) bool {
+		return captured.Subject == subject
+	})
+}
+
+// Reset forgets every message kept.
+func (transport *MemoryTransport) Reset() {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+	transport.messages = nil
+}
+
+func (transport *MemoryTransport) filter(match func(captured CapturedMessage) bool) []CapturedMessage {
+	transport.mutex.Lock()
+	defer transport.mutex.Unlock()
+
+	messages := []CapturedMessage{}
+	for _, captured := range transport.messages {
+		if match(captured) {
+			messages = append(messages, captured)
+		}
+	}
+	return messages
+}
+
+// messagePart is a leaf of a message, decoded.
+type messagePart struct {
+	contentType string
+	disposition string
+	fileName    string
+	contentID   string
+	data        []byte
+}
+
+// captureMessage reads the text, HTML and files back out of raw. A part sent
+// with a file name is a file; the first other text/plain and text/html parts
+// are the bodies.
+func captureMessage(raw []byte) (CapturedMessage, error) {
+	message, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return CapturedMessage{}, err
+	}
+	parts, err := readParts(textproto.MIMEHeader(message.Header), message.Body)
+	if err != nil {
+		return CapturedMessage{}, err
+	}
+
+	captured := CapturedMessage{Raw: raw}
+	decoder := mime.WordDecoder{}
+	if captured.From, err = decoder.DecodeHeader(message.Header.Get("From")); err != nil {
+		return CapturedMessage{}, err
+	}
+	if captured.Subject, err = decoder.DecodeHeader(message.Header.Get("Subject")); err != nil {
+		return CapturedMessage{}, err
+	}
+	for _, part := range parts {
+		switch {
+		case part.fileName != "":
+			captured.Attachments = append(captured.Attachments, Attachment{
+				FileName:    part.fileName,
+				ContentType: part.contentType,
+				ContentID:   part.contentID,
+				Data:        part.data,
+			})
+		case part.contentType == "text/plain" && captured.Text == "":
+			captured.Text = string(part.data)
+		case part.contentType == "text/html" && captured.HTML == "":
+			captured.HTML = string(part.data)
+		}
+	}
+	return captured, nil
+}
+
+func readParts(header textproto.MIMEHeader, body io.Reader) ([]messagePart, error) {
+	contentType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil {
+		// RFC 2045 reads a part without a usable type as plain text.
+		contentType, params = "text/plain", map[string]string{}
+	}
+
+	if strings.HasPrefix(contentType, "multipart/") {
+		reader := multipart.NewReader(body, params["boundary"])
+		parts := []messagePart{}
+		for {
+			// NextRawPart leaves the transfer encoding for readParts to undo.
+			part, err := reader.NextRawPart()
+			if err == io.EOF {
+				return parts, nil
+			}
+			if err != nil {
+				return nil, err
+			}
+			children, err := readParts(part.Header, part)
+			if err != nil {
+				return nil, err
+			}
+			parts = append(parts, children...)
+		}
+	}
+
+	data, err := io.ReadAll(decodeTransfer(header.Get("Content-Transfer-Encoding"), body))
+	if err != nil {
+		return nil, err
+	}
+	disposition, dispositionParams, _ := mime.ParseMediaType(header.Get("Content-Disposition"))
+	fileName := dispositionParams["filename"]
+	if fileName == "" && disposition != "" {
+		fileName = params["name"]
+	}
+
+	return []messagePart{{
+		contentType: contentType,
+		disposition: disposition,
+		fileName:    fileName,
+		contentID:   strings.Trim(header.Get("Content-ID"), "<>"),
+		data:        data,
+	}}, nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		return base64.NewDecoder(base64.StdEncoding, body)
+	}
+	return body
+}
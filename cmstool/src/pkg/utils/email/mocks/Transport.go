@@ -0,0 +1,53 @@
+// Code generated by mockery v2.32.4. DO NOT EDIT.
+
+package mocks
+
+import (
+	mock "github.com/stretchr/testify/mock"
+	gomail "gopkg.in/gomail.v2"
+)
+
+// Transport is an autogenerated mock type for the Transport type
+type Transport struct {
+	mock.Mock
+}
+
+// Dial provides a mock function with given fields:
+func (_m *Transport) Dial() (gomail.SendCloser, error) {
+	ret := _m.Called()
+
+	var r0 gomail.SendCloser
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (gomail.SendCloser, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() gomail.SendCloser); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(gomail.SendCloser)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewTransport creates a new instance of Transport. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewTransport(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Transport {
+	mock := &Transport{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -27,6 +27,22 @@ func (_m *UseCase) Send(sentMailContent email.SentMailContent) error {
 	return r0
 }
 
+// SendBatch provides a mock function with given fields: contents
+func (_m *UseCase) SendBatch(contents []email.SentMailContent) <-chan email.SendResult {
+	ret := _m.Called(contents)
+
+	var r0 <-chan email.SendResult
+	if rf, ok := ret.Get(0).(func([]email.SentMailContent) <-chan email.SendResult); ok {
+		r0 = rf(contents)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(<-chan email.SendResult)
+		}
+	}
+
+	return r0
+}
+
 // NewUseCase creates a new instance of UseCase. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewUseCase(t interface {
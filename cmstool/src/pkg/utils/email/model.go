@@ -1,7 +1,40 @@
 package email
 
+// SentMailContent is one message to send. Body is the HTML part and Text the
+// plain-text part; when Text is empty it is derived from Body. A message
+// with an UnsubscribeURL gets RFC 8058 one-click unsubscribe headers, and one
+// without a Sender is sent from FromEMailSender.
 type SentMailContent struct {
-	To      []string
-	Supject string
-	Body    string
+	Sender         string
+	To             []string
+	Supject        string
+	Body           string
+	Text           string
+	UnsubscribeURL string
+	Attachments    []Attachment
+}
+
+// Attachment is a file sent with a message. One with a ContentID is embedded
+// as an inline image the HTML part shows with src="cid:<ContentID>"; the
+// others are attached.
+type Attachment struct {
+	FileName    string
+	ContentType string
+	ContentID   string
+	Data        []byte
+}
+
+// SendResult is what became of one content of a batch after Attempts tries.
+// Error is nil when it was sent.
+type SendResult struct {
+	Content  SentMailContent
+	Attempts int
+	Error    *SendError
+}
+
+// PoolConfig bounds a batch to Workers connections sending at most
+// MessagesPerSecond between them; zero does not limit the rate.
+type PoolConfig struct {
+	Workers           int
+	MessagesPerSecond int
 }
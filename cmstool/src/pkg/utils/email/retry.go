@@ -0,0 +1,112 @@
+package email
+
+import (
+	"errors"
+	"math/rand"
+	"net"
+	"net/textproto"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+// This file is kept identical, apart from the module name, in
+// backend/src/pkg/email and cmstool/src/pkg/utils/email. Change
+// both copies together; a cmstool test fails when they drift apart.
+
+const (
+	defaultMaxAttempts = 1
+)
+
+var replyCodePattern = regexp.MustCompile(`(?:^|: )([2-5][0-9]{2})[ -]`)
+
+type RetryConfig struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+}
+
+// SendError is the error a failed Send returns. Code is the SMTP reply code
+// when the server sent one, and Permanent is set for 5xx replies that will not
+// succeed on retry.
+type SendError struct {
+	Code      int
+	Permanent bool
+	Err       error
+}
+
+func (e *SendError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+func NewSendError(err error) *SendError {
+	var sendError *SendError
+	if errors.As(err, &sendError) {
+		return sendError
+	}
+
+	code := replyCode(err)
+	if code == 0 {
+		// No reply from the server: dial timeouts, refused or dropped
+		// connections and the like are all worth another try.
+		var netError net.Error
+		if errors.As(err, &netError) {
+			return &SendError{Err: err}
+		}
+	}
+
+	return &SendError{
+		Code:      code,
+		Permanent: code >= 500,
+		Err:       err,
+	}
+}
+
+func replyCode(err error) int {
+	var protoError *textproto.Error
+	if errors.As(err, &protoError) {
+		return protoError.Code
+	}
+
+	// gomail flattens the SMTP error into its own message, so fall back to
+	// reading the reply code out of the text.
+	match := replyCodePattern.FindStringSubmatch(err.Error())
+	if match == nil {
+		return 0
+	}
+	code, _ := strconv.Atoi(match[1])
+	return code
+}
+
+func (retry RetryConfig) attempts() int {
+	if retry.MaxAttempts < 1 {
+		return defaultMaxAttempts
+	}
+	return retry.MaxAttempts
+}
+
+// backoff returns the wait before the given retry, doubling from BaseDelay up
+// to MaxDelay with full jitter.
+func (retry RetryConfig) backoff(retryNumber int) time.Duration {
+	if retry.BaseDelay <= 0 {
+		return 0
+	}
+
+	delay := retry.BaseDelay
+	for i := 1; i < retryNumber; i++ {
+		delay *= 2
+		if retry.MaxDelay > 0 && delay >= retry.MaxDelay {
+			delay = retry.MaxDelay
+			break
+		}
+	}
+	if retry.MaxDelay > 0 && delay > retry.MaxDelay {
+		delay = retry.MaxDelay
+	}
+
+	return time.Duration(rand.Int63n(int64(delay) + 1))
+}
@@ -0,0 +1,32 @@
+package email_test
+
+import (
+	"subscribetool/src/pkg/utils/email"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestText_HTMLToText(t *testing.T) {
+	t.Run("should return text as it is when body has no html", func(t *testing.T) {
+		assert.Equal(t, "This email sent for notification.", email.HTMLToText("This email sent for notification."))
+	})
+
+	t.Run("should put blocks on their own lines and mark list items", func(t *testing.T) {
+		text := email.HTMLToText("<h1>Title</h1><p>First<br>line</p><ul><li>One</li><li>Two</li></ul>")
+
+		assert.Equal(t, "Title\n\nFirst\nline\n\n- One\n- Two", text)
+	})
+
+	t.Run("should drop head script and style", func(t *testing.T) {
+		text := email.HTMLToText("<head><style>p{color:red}</style></head><script>alert(1)</script><p>Body</p>")
+
+		assert.Equal(t, "Body", text)
+	})
+
+	t.Run("should list links as footnotes numbered once per url", func(t *testing.T) {
+		text := email.HTMLToText(`<p>Read <a href="https://example.com/post">our post</a>.</p><p><a href="https://example.com/post">Again</a> <a href="https://example.com">https://example.com</a></p>`)
+
+		assert.Equal(t, "Read our post [1].\n\nAgain [1] https://example.com\n\n[1] https://example.com/post", text)
+	})
+}
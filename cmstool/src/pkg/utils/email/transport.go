@@ -0,0 +1,114 @@
+package email
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// Names of the transports EmailServer.EmailTransport picks from.
+const (
+	TransportSMTP   = "smtp"
+	TransportFile   = "file"
+	TransportMemory = "memory"
+)
+
+// Transport opens a connection messages are sent over. Service keeps one
+// open per worker while it sends a batch.
+type Transport interface {
+	Dial() (gomail.SendCloser, error)
+}
+
+// SMTPTransport sends through an SMTP server.
+type SMTPTransport struct {
+	Dialer *gomail.Dialer
+}
+
+func NewSMTPTransport(host string, port int, username string, password string, tlsSkipVerify bool) *SMTPTransport {
+	dialer := gomail.NewDialer(host, port, username, password)
+	dialer.TLSConfig = &tls.Config{InsecureSkipVerify: tlsSkipVerify}
+
+	return &SMTPTransport{
+		Dialer: dialer,
+	}
+}
+
+func (transport *SMTPTransport) Dial() (gomail.SendCloser, error) {
+	return transport.Dialer.Dial()
+}
+
+// FileTransport writes each message to Dir as an RFC 5322 .eml file rather
+// than sending it, so a send can be tried out and read in any mail client.
+type FileTransport struct {
+	Dir string
+	Now func() time.Time
+
+	mutex sync.Mutex
+	count int
+}
+
+func NewFileTransport(dir string) *FileTransport {
+	return &FileTransport{
+		Dir: dir,
+		Now: time.Now,
+	}
+}
+
+func (transport *FileTransport) Dial() (gomail.SendCloser, error) {
+	return sendCloser(transport.write), nil
+}
+
+// Send writes message on its own, as gomail.Send would.
+func (transport *FileTransport) Send(message *gomail.Message) error {
+	return gomail.Send(sendCloser(transport.write), message)
+}
+
+// write names the file after when it was written, so the files list in the
+// order they were sent. The message is written under a temporary name first,
+// so a reader never sees half of one.
+func (transport *FileTransport) write(from string, to []string, message io.WriterTo) error {
+	if err := os.MkdirAll(transport.Dir, 0o755); err != nil {
+		return err
+	}
+
+	transport.mutex.Lock()
+	transport.count++
+	name := fmt.Sprintf("%s-%d-%06d.eml", transport.Now().UTC().Format("20060102T150405.000000000"), os.Getpid(), transport.count)
+	transport.mutex.Unlock()
+
+	file, err := os.CreateTemp(transport.Dir, ".send-*")
+	if err != nil {
+		return err
+	}
+	if _, err = message.WriteTo(file); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return err
+	}
+	if err = file.Close(); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	if err = os.Rename(file.Name(), filepath.Join(transport.Dir, name)); err != nil {
+		os.Remove(file.Name())
+		return err
+	}
+	return nil
+}
+
+// sendCloser is a connection for a transport that has nothing to close.
+type sendCloser func(from string, to []string, message io.WriterTo) error
+
+func (send sendCloser) Send(from string, to []string, message io.WriterTo) error {
+	return send(from, to, message)
+}
+
+func (send sendCloser) Close() error {
+	return nil
+}
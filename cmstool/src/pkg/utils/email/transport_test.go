@@ -0,0 +1,98 @@
+package email_test
+
+import (
+	"net/mail"
+	"os"
+	"path/filepath"
+	"subscribetool/src/pkg/utils/email"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/gomail.v2"
+)
+
+func newMessage(to string, subject string) *gomail.Message {
+	message := gomail.NewMessage()
+	message.SetHeader("From", "newsletter@gmail.com")
+	message.SetHeader("To", to)
+	message.SetHeader("Subject", subject)
+	message.SetBody("text/html", "<p>body</p>")
+	return message
+}
+
+func TestTransport_FileTransport(t *testing.T) {
+	t.Run("should write each message to its own eml file in send order", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "outbox")
+		transport := email.NewFileTransport(dir)
+		transport.Now = func() time.Time { return time.Date(2023, 1, 2, 9, 0, 0, 0, time.UTC) }
+
+		assert.Nil(t, transport.Send(newMessage("ajis@gmail.com", "first")))
+		assert.Nil(t, transport.Send(newMessage("tom@gmail.com", "second")))
+
+		names, _ := filepath.Glob(filepath.Join(dir, "*"))
+		assert.Len(t, names, 2)
+		for i, subject := range []string{"first", "second"} {
+			assert.Equal(t, ".eml", filepath.Ext(names[i]))
+			file, err := os.Open(names[i])
+			assert.Nil(t, err)
+			message, err := mail.ReadMessage(file)
+			file.Close()
+			assert.Nil(t, err)
+			assert.Equal(t, subject, message.Header.Get("Subject"))
+		}
+	})
+}
+
+func TestTransport_MemoryTransport(t *testing.T) {
+	var transport *email.MemoryTransport
+
+	beforeEachMemory := func() {
+		transport = email.NewMemoryTransport()
+		transport.Send(newMessage("ajis@gmail.com", "first"))
+		transport.Send(newMessage("tom@gmail.com", "second"))
+		transport.Send(newMessage("Ajis@Gmail.com", "third"))
+	}
+
+	t.Run("should keep messages in send order", func(t *testing.T) {
+		beforeEachMemory()
+
+		messages := transport.Messages()
+
+		assert.Equal(t, 3, transport.Len())
+		assert.Equal(t, "first", messages[0].Subject)
+		assert.Equal(t, "<p>body</p>", messages[0].HTML)
+		last, ok := transport.Last()
+		assert.True(t, ok)
+		assert.Equal(t, "third", last.Subject)
+	})
+
+	t.Run("should find messages by recipient ignoring case", func(t *testing.T) {
+		beforeEachMemory()
+
+		messages := transport.SentTo("ajis@gmail.com")
+
+		assert.Len(t, messages, 2)
+		assert.Equal(t, "first", messages[0].Subject)
+		assert.Equal(t, "third", messages[1].Subject)
+	})
+
+	t.Run("should find messages by subject", func(t *testing.T) {
+		beforeEachMemory()
+
+		messages := transport.WithSubject("second")
+
+		assert.Len(t, messages, 1)
+		assert.Equal(t, []string{"tom@gmail.com"}, messages[0].To)
+	})
+
+	t.Run("should forget messages when reset", func(t *testing.T) {
+		beforeEachMemory()
+
+		transport.Reset()
+
+		_, ok := transport.Last()
+		assert.False(t, ok)
+		assert.Empty(t, transport.Messages())
+	})
+}
@@ -43,24 +43,31 @@ const (
 	DatabaseConnectionFailed ErrorCode = "DATABASE_CONNECTION_FAILED"
 	TechnicalError           ErrorCode = "TECHNICAL_ERROR"
 
-	InvalidLanguage          ErrorCode = "INVALID_LANGUAGE"
-	ValidateQuotaNotEnough   ErrorCode = "VALIDATE_QUOTA_NOTENOUGH"
-	DuplicateFileName        ErrorCode = "DUPLICATE_FILE_NAME"
-	DuplicateCardId          ErrorCode = "DUPLICATE_CARD_ID"
-	DuplicateTaxRegistration ErrorCode = "DUPLICATE_TAX_REGISTRATION"
-	DuplicateUserName        ErrorCode = "DUPLICATE_USER_NAME"
-	InvalidPassword          ErrorCode = "INVALID_PASSWORD"
-	DataQuotaNotFound        ErrorCode = "DATA_QUOTA_NOT_FOUND"
-	EmptyToken               ErrorCode = "EMPTY_TOKEN"
-	IncorrectUserName        ErrorCode = "INCORRECT_USERNAME"
-	QuotaAlreadyUsed         ErrorCode = "QUOTA_ALREADY_USED"
-	AmountUseMoreThanZero    ErrorCode = "AmountUse_More_than_Zero"
-	CanNotDeleteInvoice      ErrorCode = "CAN_NOT_DELETE_INVOICE"
-	CanNotDeleteDeliveryNote ErrorCode = "CAN_NOT_DELETE_DELIVERY_NOTE"
-	UploadFailed             ErrorCode = "UPLOAD_FAILED"
-	ExistUserName            ErrorCode = "EXIST_USER_NAME"
-	UserActiveFailed         ErrorCode = "USER_ACTIVE_FAILED"
-	SubPartnerHasAnInvoice   ErrorCode = "SUB_PARTNER_HAS_AN_INVOICE"
+	InvalidLanguage            ErrorCode = "INVALID_LANGUAGE"
+	ValidateQuotaNotEnough     ErrorCode = "VALIDATE_QUOTA_NOTENOUGH"
+	DuplicateFileName          ErrorCode = "DUPLICATE_FILE_NAME"
+	DuplicateCardId            ErrorCode = "DUPLICATE_CARD_ID"
+	DuplicateTaxRegistration   ErrorCode = "DUPLICATE_TAX_REGISTRATION"
+	DuplicateUserName          ErrorCode = "DUPLICATE_USER_NAME"
+	InvalidPassword            ErrorCode = "INVALID_PASSWORD"
+	DataQuotaNotFound          ErrorCode = "DATA_QUOTA_NOT_FOUND"
+	EmptyToken                 ErrorCode = "EMPTY_TOKEN"
+	IncorrectUserName          ErrorCode = "INCORRECT_USERNAME"
+	QuotaAlreadyUsed           ErrorCode = "QUOTA_ALREADY_USED"
+	AmountUseMoreThanZero      ErrorCode = "AmountUse_More_than_Zero"
+	CanNotDeleteInvoice        ErrorCode = "CAN_NOT_DELETE_INVOICE"
+	CanNotDeleteDeliveryNote   ErrorCode = "CAN_NOT_DELETE_DELIVERY_NOTE"
+	UploadFailed               ErrorCode = "UPLOAD_FAILED"
+	ExistUserName              ErrorCode = "EXIST_USER_NAME"
+	UserActiveFailed           ErrorCode = "USER_ACTIVE_FAILED"
+	SubPartnerHasAnInvoice     ErrorCode = "SUB_PARTNER_HAS_AN_INVOICE"
+	InvalidCampaignStatus      ErrorCode = "INVALID_CAMPAIGN_STATUS"
+	CampaignAlreadySent        ErrorCode = "CAMPAIGN_ALREADY_SENT"
+	InvalidTemplate            ErrorCode = "INVALID_TEMPLATE"
+	EmailNotSent               ErrorCode = "EMAIL_NOT_SENT"
+	CampaignDeliveryInProgress ErrorCode = "CAMPAIGN_DELIVERY_IN_PROGRESS"
+	AttachmentTooLarge         ErrorCode = "ATTACHMENT_TOO_LARGE"
+	AttachmentsUnavailable     ErrorCode = "ATTACHMENTS_UNAVAILABLE"
 )
 
 var mappingMessage = map[ErrorCode]ErrorMessage{
@@ -214,6 +221,48 @@ var mappingMessage = map[ErrorCode]ErrorMessage{
 		EN:         "ไม่สามารถทำการลบช้อมูลลูกข่ายได้ เนื่องจากมีรายการคงค้างในระบบ",
 		TH:         "ไม่สามารถทำการลบช้อมูลลูกข่ายได้ เนื่องจากมีรายการคงค้างในระบบ",
 	},
+	InvalidCampaignStatus: {
+		Code:       InvalidCampaignStatus,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid campaign status",
+		TH:         "สถานะแคมเปญไม่ถูกต้อง",
+	},
+	CampaignAlreadySent: {
+		Code:       CampaignAlreadySent,
+		StatusCode: http.StatusConflict,
+		EN:         "Campaign is already sending or sent",
+		TH:         "แคมเปญนี้กำลังส่งหรือส่งไปแล้ว",
+	},
+	InvalidTemplate: {
+		Code:       InvalidTemplate,
+		StatusCode: http.StatusBadRequest,
+		EN:         "Invalid template",
+		TH:         "เทมเพลตไม่ถูกต้อง",
+	},
+	EmailNotSent: {
+		Code:       EmailNotSent,
+		StatusCode: http.StatusBadGateway,
+		EN:         "Some emails could not be sent",
+		TH:         "ส่งอีเมลบางฉบับไม่สำเร็จ",
+	},
+	CampaignDeliveryInProgress: {
+		Code:       CampaignDeliveryInProgress,
+		StatusCode: http.StatusConflict,
+		EN:         "Campaign delivery is in progress",
+		TH:         "แคมเปญกำลังอยู่ระหว่างการส่ง",
+	},
+	AttachmentTooLarge: {
+		Code:       AttachmentTooLarge,
+		StatusCode: http.StatusRequestEntityTooLarge,
+		EN:         "Attachments are larger than allowed",
+		TH:         "ไฟล์แนบมีขนาดเกินกำหนด",
+	},
+	AttachmentsUnavailable: {
+		Code:       AttachmentsUnavailable,
+		StatusCode: http.StatusServiceUnavailable,
+		EN:         "Attachments cannot be read",
+		TH:         "ไม่สามารถอ่านไฟล์แนบได้",
+	},
 }
 
 func MapMessageError(code ErrorCode, languageCode string) (statusCode int, e Error) {
@@ -9,6 +9,10 @@ import (
 	"github.com/thoas/go-funk"
 )
 
+// This file is kept identical, apart from the module name, in
+// backend/src/pkg/utils/sqlquery and cmstool/src/pkg/utils/sqlquery. Change
+// both copies together; a cmstool test fails when they drift apart.
+
 const (
 	TimeType   = "time.Time"
 	StringType = "string"
@@ -17,6 +21,8 @@ const (
 	PointerTimeType   = "*time.Time"
 	PointerStringType = "*string"
 	PointerBoolType   = "*bool"
+
+	ParamPrefix = "@p"
 )
 
 func GenerateQueryColumnNameInclude(s interface{}, includeFields []string) string {
@@ -212,11 +218,13 @@ func ManipulateValues(s interface{}, ignoreFields []string) []string {
 	return strs
 }
 
+// Deprecated: inlining values escapes strings by hand, use GenerateQueryColumnParams instead.
 func GenerateQueryColumnValues(s interface{}, ignoreFields []string) string {
 	strs := ManipulateValues(s, ignoreFields)
 	return strings.Join(strs, ",")
 }
 
+// Deprecated: inlining values escapes strings by hand, use GenerateQueryUpdateFieldParams instead.
 func GenerateQueryUpdateFields(s interface{}, ignoreFields []string) string {
 	strs := []string{}
 
@@ -261,6 +269,84 @@ func GenerateQueryUpdateFieldInclude(s interface{}, includeFields []string) stri
 	return strings.Join(fields, ",")
 }
 
+func Param(index int) string {
+	return fmt.Sprintf("%s%d", ParamPrefix, index)
+}
+
+func ManipulateParams(s interface{}, ignoreFields []string) []interface{} {
+	v := reflect.ValueOf(s)
+	typeOfS := v.Type()
+	args := []interface{}{}
+
+	for i := 0; i < v.NumField(); i++ {
+		if funk.ContainsString(ignoreFields, typeOfS.Field(i).Name) {
+			continue
+		}
+
+		field := typeOfS.Field(i).Tag.Get("sql")
+		if field == "-" || field == "" {
+			continue
+		}
+
+		value := v.Field(i)
+		if value.Kind() == reflect.Ptr {
+			if value.IsNil() {
+				args = append(args, nil)
+				continue
+			}
+			value = value.Elem()
+		}
+
+		args = append(args, value.Interface())
+	}
+
+	return args
+}
+
+func GenerateQueryColumnParams(s interface{}, ignoreFields []string, offset int) (string, []interface{}) {
+	args := ManipulateParams(s, ignoreFields)
+	params := []string{}
+
+	for i := range args {
+		params = append(params, Param(offset+i+1))
+	}
+
+	return strings.Join(params, ","), args
+}
+
+func GenerateQueryUpdateFieldParams(s interface{}, ignoreFields []string, offset int) (string, []interface{}) {
+	fields := []string{}
+
+	columns := strings.Split(GenerateQueryColumnNames(s, ignoreFields), ",")
+
+	args := ManipulateParams(s, ignoreFields)
+
+	if columns[0] != "" {
+		for i := 0; i < len(columns); i++ {
+			fields = append(fields, fmt.Sprintf("%s = %s", columns[i], Param(offset+i+1)))
+		}
+	}
+
+	return strings.Join(fields, ","), args
+}
+
+func GenerateQueryUpdateFieldIncludeParams(s interface{}, includeFields []string, offset int) (string, []interface{}) {
+	t := reflect.TypeOf(s)
+	ignoreFields := []string{}
+
+	if len(includeFields) == 0 {
+		return "", []interface{}{}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if !funk.ContainsString(includeFields, t.Field(i).Name) {
+			ignoreFields = append(ignoreFields, t.Field(i).Name)
+		}
+	}
+
+	return GenerateQueryUpdateFieldParams(s, ignoreFields, offset)
+}
+
 func GenerateTransactionAndRollback(queries []string) string {
 
 	concatQuery := ""